@@ -0,0 +1,17 @@
+//go:build !headless
+
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/app"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// headlessBuild is true in builds without the Fyne drivers, which always run in container mode.
+const headlessBuild = false
+
+// newApp creates the Fyne application, with the desktop driver and the saved preferences.
+func newApp() fyne.App {
+	return app.NewWithID(config.AppID)
+}
@@ -0,0 +1,61 @@
+//go:build headless
+
+package main
+
+import (
+	"log/slog"
+	"net/url"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/prefs"
+)
+
+// headlessBuild is true in builds without the Fyne drivers, which always run in container mode.
+const headlessBuild = true
+
+// headlessApp is a fyne.App without driver, so that the binary needs neither cgo, X11 nor OpenGL.
+// The daemon only uses its preferences: it has no window, clipboard or notification to show.
+type headlessApp struct {
+	prefs fyne.Preferences
+	icon  fyne.Resource
+	quit  chan struct{}
+	once  sync.Once
+}
+
+// newApp creates an application without driver, with the preferences of the desktop application
+// when the user config directory is writable, and in memory otherwise: the configuration comes
+// from the environment anyway.
+func newApp() fyne.App {
+	var p *prefs.File
+	path, err := prefs.FilePath(config.AppID)
+	if err == nil {
+		p, err = prefs.NewFile(path)
+	}
+	if err != nil {
+		slog.Warn(config.ErrPrefsFile,
+			config.LogKeyComponent, config.CompMain,
+			config.LogKeyError, err)
+		p, _ = prefs.NewFile("")
+	}
+	return &headlessApp{prefs: p, quit: make(chan struct{})}
+}
+
+func (a *headlessApp) NewWindow(string) fyne.Window        { return nil }
+func (a *headlessApp) OpenURL(*url.URL) error              { return nil }
+func (a *headlessApp) Icon() fyne.Resource                 { return a.icon }
+func (a *headlessApp) SetIcon(icon fyne.Resource)          { a.icon = icon }
+func (a *headlessApp) Run()                                { <-a.quit }
+func (a *headlessApp) Quit()                               { a.once.Do(func() { close(a.quit) }) }
+func (a *headlessApp) Driver() fyne.Driver                 { return nil }
+func (a *headlessApp) UniqueID() string                    { return config.AppID }
+func (a *headlessApp) SendNotification(*fyne.Notification) {}
+func (a *headlessApp) Settings() fyne.Settings             { return nil }
+func (a *headlessApp) Preferences() fyne.Preferences       { return a.prefs }
+func (a *headlessApp) Storage() fyne.Storage               { return nil }
+func (a *headlessApp) Lifecycle() fyne.Lifecycle           { return nil }
+func (a *headlessApp) Metadata() fyne.AppMetadata          { return fyne.AppMetadata{ID: config.AppID} }
+func (a *headlessApp) CloudProvider() fyne.CloudProvider   { return nil }
+func (a *headlessApp) SetCloudProvider(fyne.CloudProvider) {}
+func (a *headlessApp) Clipboard() fyne.Clipboard           { return nil }
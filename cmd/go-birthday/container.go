@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/prefs"
+)
+
+// preferences returns the preferences of a with the command-line overrides. In container mode,
+// they are also overlaid with the file named by config.EnvPrefsFile and the config.EnvPrefPrefix
+// variables, and the secrets are read from the environment unless config.EnvSecretStore
+// selects another storage.
+func preferences(a fyne.App, container bool, flags overrides) (fyne.Preferences, error) {
+	var file string
+	var environ []string
+	if container {
+		if os.Getenv(config.EnvSecretStore) == "" {
+			_ = os.Setenv(config.EnvSecretStore, config.SecretStoreEnv)
+		}
+		file, environ = os.Getenv(config.EnvPrefsFile), os.Environ()
+	}
+	p, err := prefs.NewOverlay(a.Preferences(), file, environ)
+	if err != nil {
+		return nil, err
+	}
+	return p, flags.apply(p)
+}
+
+// bindAddresses returns the interfaces of the server: the comma-separated preference if set
+// (e.g., "127.0.0.1, ::1"), otherwise every interface in container mode, where the port is
+// published, and the loopback elsewhere.
+func bindAddresses(p fyne.Preferences, container bool) []string {
+	var hosts []string
+	for _, h := range strings.Split(p.String(config.PrefBindAddress), config.ListSeparator) {
+		if h = strings.TrimSpace(h); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	switch {
+	case len(hosts) > 0:
+		return hosts
+	case container:
+		return []string{config.ContainerBindAddr}
+	default:
+		return []string{config.LocalhostBindAddr}
+	}
+}
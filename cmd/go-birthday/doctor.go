@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+	"github.com/tartampluch/go-birthday/internal/server"
+	"github.com/tartampluch/go-birthday/internal/ui"
+)
+
+// runDoctor implements "go-birthday doctor" (or "check"): it validates the saved configuration
+// and prints a report, as JSON with -json. It returns config.ExitCodeError if any check failed,
+// so that it can be used in scripts and monitoring.
+func runDoctor(args []string) int {
+	flags := flag.NewFlagSet(config.CmdDoctor, flag.ContinueOnError)
+	asJSON := flags.Bool(config.FlagJSON, false, config.FlagDescJSON)
+	cards := flags.Int(config.FlagCards, config.DefaultDoctorCards, config.FlagDescCards)
+	containerMode := flags.Bool(config.FlagContainer, headlessBuild, config.FlagDescContainer)
+	overridden := overrideFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return config.ExitCodeError
+	}
+
+	// The report goes to stdout; only problems are logged, to stderr.
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	container := *containerMode || headlessBuild
+	a := newApp()
+	prefs, err := preferences(a, container, overridden)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return config.ExitCodeError
+	}
+	srv := server.NewCalendarServer(prefs.StringWithFallback(config.PrefServerPort, config.DefaultPort))
+	srv.Hosts = bindAddresses(prefs, container)
+	gui := ui.NewGoBirthdayApp(a, ctx, srv, engine.NewRetryFetcher(engine.NewHTTPFetcher()))
+	gui.Preferences = prefs
+	gui.SetupI18n() // Summaries and reminders of the source configuration are localized
+
+	report := gui.Doctor(ctx, *cards)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return config.ExitCodeError
+		}
+	} else {
+		for _, c := range report.Checks {
+			fmt.Printf(config.DoctorReportLine, c.Status, c.Name, c.Detail)
+		}
+		if report.OK {
+			fmt.Println(config.DoctorReportOK)
+		} else {
+			fmt.Println(config.DoctorReportFailed)
+		}
+	}
+
+	if !report.OK {
+		return config.ExitCodeError
+	}
+	return config.ExitCodeSuccess
+}
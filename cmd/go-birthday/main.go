@@ -11,12 +11,14 @@ import (
 	"path/filepath"
 	"runtime"
 	"syscall"
+	_ "time/tzdata" // Time zone preference on systems without a zoneinfo database (Windows)
 
-	"fyne.io/fyne/v2/app"
 	"github.com/tartampluch/go-birthday/internal/config"
 	"github.com/tartampluch/go-birthday/internal/engine"
 	"github.com/tartampluch/go-birthday/internal/server"
+	"github.com/tartampluch/go-birthday/internal/telemetry"
 	"github.com/tartampluch/go-birthday/internal/ui"
+	"github.com/tartampluch/go-birthday/internal/winsvc"
 )
 
 // main is the application entry point.
@@ -33,9 +35,36 @@ func runMain() int {
 	// -------------------------------------------------------------------------
 	// 1. CLI Argument Parsing
 	// -------------------------------------------------------------------------
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case config.CmdDoctor, config.CmdCheck:
+			return runDoctor(os.Args[2:])
+		case config.CmdInstallService:
+			return runInstallService(os.Args[2:])
+		case config.CmdUninstallService:
+			return runUninstallService(os.Args[2:])
+		}
+	}
+
 	showVersion := flag.Bool(config.FlagVersion, false, config.FlagDescVersion)
 	debugMode := flag.Bool(config.FlagDebug, false, config.FlagDescDebug)
+	windowMode := flag.Bool(config.FlagWindow, false, config.FlagDescWindow)
+	validate := flag.Bool(config.FlagValidate, false, config.FlagDescValidate)
+	syncOnce := flag.Bool(config.FlagSyncOnce, false, config.FlagDescSyncOnce)
+	daemon := flag.Bool(config.FlagDaemon, false, config.FlagDescDaemon)
+	containerMode := flag.Bool(config.FlagContainer, headlessBuild, config.FlagDescContainer)
+	overridden := overrideFlags(flag.CommandLine)
 	flag.Parse()
+	opts := runOptions{
+		windowMode: *windowMode,
+		debugMode:  *debugMode,
+		validate:   *validate,
+		syncOnce:   *syncOnce,
+		daemon:     *daemon,
+		container:  *containerMode || headlessBuild,
+		overridden: overridden,
+		logLevel:   new(slog.LevelVar),
+	}
 
 	if *showVersion {
 		printVersion()
@@ -46,7 +75,13 @@ func runMain() int {
 	// 2. Logging Initialization
 	// -------------------------------------------------------------------------
 	// We configure structured logging (slog) early to capture startup issues.
-	logCloser := setupLogging(*debugMode)
+	// A Windows service has no console: it logs to the Windows event log instead.
+	// In a container, stdout is collected by the runtime, and a log file would only fill the layer.
+	service := opts.daemon && winsvc.IsService()
+	var logCloser io.Closer
+	if !service || !setupEventLog(opts.debugMode, opts.logLevel) {
+		logCloser, opts.logPath = setupLogging(opts.debugMode, !opts.container, opts.logLevel)
+	}
 	if logCloser != nil {
 		defer func() {
 			_ = logCloser.Close() // Best effort close
@@ -62,10 +97,27 @@ func runMain() int {
 
 	logStartupInfo()
 
+	// OpenTelemetry export, only when OTEL_EXPORTER_OTLP_ENDPOINT is set.
+	shutdownTelemetry := telemetry.Start(ctx)
+	defer func() {
+		flushCtx, cancelFlush := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+		defer cancelFlush()
+		_ = shutdownTelemetry(flushCtx) // Best effort flush
+	}()
+
 	// -------------------------------------------------------------------------
 	// 4. Application Logic
 	// -------------------------------------------------------------------------
-	if err := run(ctx); err != nil {
+	runApp := func(ctx context.Context) error {
+		return run(ctx, opts)
+	}
+	var err error
+	if service {
+		err = winsvc.Run(ctx, runApp) // Stopped by the service control manager
+	} else {
+		err = runApp(ctx)
+	}
+	if err != nil {
 		slog.Error(config.ErrAppFailed,
 			config.LogKeyComponent, config.CompMain,
 			config.LogKeyError, err,
@@ -77,21 +129,58 @@ func runMain() int {
 	return config.ExitCodeSuccess
 }
 
+// runOptions are the options of run, from the command line.
+type runOptions struct {
+	windowMode bool           // Open a main window instead of the system tray
+	debugMode  bool           // Log at the debug level
+	validate   bool           // Check every calendar against RFC 5545
+	syncOnce   bool           // Synchronize once without any UI and return the error of the synchronization
+	daemon     bool           // Serve and synchronize without any UI until the context ends, reloading on SIGHUP
+	container  bool           // Same as daemon, with the configuration of the environment (see preferences)
+	overridden overrides      // Preferences of the command line, which are not saved
+	logLevel   *slog.LevelVar // Shared with the UI so that debug logging can be toggled at runtime
+	logPath    string         // Log file displayed by the UI, empty if file logging is unavailable
+}
+
 // run initializes the Fyne application, wires dependencies, and starts the UI loop.
-func run(ctx context.Context) error {
+func run(ctx context.Context, opts runOptions) error {
 	// Initialize Fyne App.
-	a := app.NewWithID(config.AppID)
-
-	// Record the version for potential migration logic in future updates.
-	a.Preferences().SetString(config.PrefLastRun, config.Version)
+	a := newApp()
+	prefs, err := preferences(a, opts.container, opts.overridden)
+	if err != nil {
+		return err
+	}
 
 	// Dependency Injection.
-	port := a.Preferences().StringWithFallback(config.PrefServerPort, config.DefaultPort)
+	port := prefs.StringWithFallback(config.PrefServerPort, config.DefaultPort)
 	srv := server.NewCalendarServer(port)
-	fetcher := engine.NewHTTPFetcher()
+	srv.Hosts = bindAddresses(prefs, opts.container)
+	fetcher := engine.NewRetryFetcher(engine.NewHTTPFetcher())
 
 	// Initialize the UI Controller (MVC pattern).
 	gui := ui.NewGoBirthdayApp(a, ctx, srv, fetcher)
+	gui.Preferences = prefs
+	gui.Migrate() // Upgrades the preferences of an older version, and records this one
+	gui.WindowMode = opts.windowMode
+	gui.DebugFlag = opts.debugMode
+	gui.Validate = opts.validate
+	gui.LogLevel = opts.logLevel
+	gui.LogPath = opts.logPath
+
+	if opts.syncOnce {
+		return gui.SyncOnce()
+	}
+
+	// SIGUSR1 toggles debug logging, SIGHUP restores the configured level (Unix only),
+	// and also synchronizes again in daemon mode, as "systemctl reload" expects.
+	if opts.container {
+		slog.Info(config.MsgContainerStart, config.LogKeyComponent, config.CompMain)
+	}
+	if opts.daemon || opts.container {
+		watchLogSignals(ctx, opts.logLevel, gui.Reload)
+		return gui.RunDaemon()
+	}
+	watchLogSignals(ctx, opts.logLevel, gui.ApplyLogLevel)
 
 	// Lifecycle Bridge:
 	// Watch for context cancellation to quit the UI gracefully.
@@ -134,30 +223,34 @@ func logStartupInfo() {
 	)
 }
 
-// setupLogging configures the default slog logger.
-func setupLogging(debugMode bool) io.Closer {
+// setupLogging configures the default slog logger, writing to stdout and, with toFile, to a log file.
+// The handler reads level on every record, so later changes apply without a restart.
+// It returns the log file (nil if unavailable) and its path.
+func setupLogging(debugMode, toFile bool, level *slog.LevelVar) (io.Closer, string) {
 	var writers []io.Writer
 	var logFile *os.File
+	var filePath string
 
 	// 1. Always write to Stdout.
 	writers = append(writers, os.Stdout)
 
 	// 2. Attempt to set up a file writer in the user's cache directory.
-	if logPath, err := getLogFilePath(); err == nil {
+	if logPath, err := getLogFilePath(); toFile && err == nil {
 		// O_TRUNC resets logs on restart to prevent indefinite growth.
 		// Use centralized permission constants for security.
 		f, err := os.OpenFile(logPath, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, config.FilePermUserRW)
 		if err == nil {
 			writers = append(writers, f)
 			logFile = f
+			filePath = logPath
 		} else {
 			fmt.Fprintf(os.Stderr, config.MsgLogWarning, config.ErrLogFile, logPath, err)
 		}
 	}
 
-	level := slog.LevelInfo
+	level.Set(slog.LevelInfo)
 	if debugMode {
-		level = slog.LevelDebug
+		level.Set(slog.LevelDebug)
 	}
 
 	opts := &slog.HandlerOptions{
@@ -169,9 +262,24 @@ func setupLogging(debugMode bool) io.Closer {
 	slog.SetDefault(logger)
 
 	if logFile == nil {
-		return nil
+		return nil, ""
+	}
+	return logFile, filePath
+}
+
+// setupEventLog configures the default slog logger to write to the Windows event log,
+// reporting whether it is available.
+func setupEventLog(debugMode bool, level *slog.LevelVar) bool {
+	level.Set(slog.LevelInfo)
+	if debugMode {
+		level.Set(slog.LevelDebug)
+	}
+	handler, err := winsvc.EventLogHandler(level)
+	if err != nil {
+		return false
 	}
-	return logFile
+	slog.SetDefault(slog.New(handler))
+	return true
 }
 
 // getLogFilePath determines the platform-specific cache directory for logs.
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+	"github.com/tartampluch/go-birthday/internal/prefs"
+)
+
+// overrides are the preferences given on the command line: they apply to the current run only,
+// e.g., to reproduce a bug with another address book without touching the saved settings.
+type overrides struct {
+	port, source, mode *string
+}
+
+// overrideFlags defines the flags of the overrides on fs.
+func overrideFlags(fs *flag.FlagSet) overrides {
+	return overrides{
+		port:   fs.String(config.FlagPort, "", config.FlagDescPort),
+		source: fs.String(config.FlagSource, "", config.FlagDescSource),
+		mode:   fs.String(config.FlagMode, "", config.FlagDescMode),
+	}
+}
+
+// apply checks the overrides and sets them on p. Without -mode, -source is a CardDAV URL
+// if it starts with http:// or https://, and a local file or directory otherwise.
+func (o overrides) apply(p *prefs.Overlay) error {
+	if *o.port != "" {
+		if n, err := strconv.Atoi(*o.port); err != nil || n < config.MinPort || n > config.MaxPort {
+			return errors.New(config.ErrPortRange)
+		}
+		p.Override(config.PrefServerPort, *o.port)
+	}
+
+	mode := *o.mode
+	if mode != "" && !slices.Contains(config.SourceModes, mode) && !slices.Contains(engine.Sources(), mode) {
+		return fmt.Errorf(config.ErrFlagMode, mode)
+	}
+	if *o.source != "" {
+		if mode == "" {
+			mode = config.SourceModeLocal
+			if u, err := url.Parse(*o.source); err == nil && (u.Scheme == config.SchemeHTTP || u.Scheme == config.SchemeHTTPS) {
+				mode = config.SourceModeWeb
+			}
+		}
+		key, ok := config.SourcePrefs[mode]
+		if !ok {
+			return fmt.Errorf(config.ErrFlagSource, mode)
+		}
+		p.Override(key, *o.source)
+		// The address books chosen in the settings belong to the saved URL.
+		p.Override(config.PrefAddressBooks, "")
+	}
+	if mode != "" {
+		p.Override(config.PrefSourceMode, mode)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+//go:build !windows
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/systemd"
+)
+
+// runInstallService implements "go-birthday install-service": it writes the systemd user unit
+// running the executable with --daemon, or prints it with -print (e.g., for a system-wide unit).
+func runInstallService(args []string) int {
+	flags := flag.NewFlagSet(config.CmdInstallService, flag.ContinueOnError)
+	printUnit := flags.Bool(config.FlagPrint, false, config.FlagDescPrint)
+	if err := flags.Parse(args); err != nil {
+		return config.ExitCodeError
+	}
+
+	if *printUnit {
+		exe, err := systemd.Executable()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return config.ExitCodeError
+		}
+		fmt.Print(systemd.Unit(exe))
+		return config.ExitCodeSuccess
+	}
+
+	path, err := systemd.Install()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return config.ExitCodeError
+	}
+	fmt.Printf(config.MsgServiceInstalled, path, config.SystemdUnitFile)
+	return config.ExitCodeSuccess
+}
+
+// runUninstallService implements "go-birthday uninstall-service": it removes the systemd user unit.
+func runUninstallService(_ []string) int {
+	path, err := systemd.Uninstall()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return config.ExitCodeError
+	}
+	fmt.Printf(config.MsgServiceRemoved, path, config.SystemdUnitFile)
+	return config.ExitCodeSuccess
+}
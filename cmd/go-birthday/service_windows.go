@@ -0,0 +1,40 @@
+//go:build windows
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/winsvc"
+)
+
+// runInstallService implements "go-birthday install-service": it registers the executable
+// with the service control manager, started at boot with --daemon. Run it as an administrator.
+func runInstallService(args []string) int {
+	flags := flag.NewFlagSet(config.CmdInstallService, flag.ContinueOnError)
+	user := flags.String(config.FlagUser, "", config.FlagDescUser)
+	password := flags.String(config.FlagPassword, "", config.FlagDescPassword)
+	if err := flags.Parse(args); err != nil {
+		return config.ExitCodeError
+	}
+
+	if err := winsvc.Install(*user, *password); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return config.ExitCodeError
+	}
+	fmt.Printf(config.MsgWinServiceDone, config.WinServiceName, config.WinServiceName)
+	return config.ExitCodeSuccess
+}
+
+// runUninstallService implements "go-birthday uninstall-service": it stops and removes the service.
+func runUninstallService(_ []string) int {
+	if err := winsvc.Uninstall(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return config.ExitCodeError
+	}
+	fmt.Printf(config.MsgWinServiceRemoved, config.WinServiceName)
+	return config.ExitCodeSuccess
+}
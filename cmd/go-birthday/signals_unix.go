@@ -0,0 +1,43 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// watchLogSignals changes the log level on signals, without restarting:
+// SIGUSR1 toggles between debug and info, SIGHUP calls reset (the configured level).
+func watchLogSignals(ctx context.Context, level *slog.LevelVar, reset func()) {
+	sigs := make(chan os.Signal, config.ChannelBufferSize)
+	signal.Notify(sigs, syscall.SIGUSR1, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(sigs)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-sigs:
+				if sig == syscall.SIGHUP {
+					reset()
+					continue
+				}
+				next := slog.LevelDebug
+				if level.Level() == slog.LevelDebug {
+					next = slog.LevelInfo
+				}
+				level.Set(next)
+				slog.Info(config.MsgLogLevel,
+					config.LogKeyLevel, next.String(),
+					config.LogKeyComponent, config.CompMain)
+			}
+		}
+	}()
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"log/slog"
+)
+
+// watchLogSignals is a no-op: Windows has no SIGUSR1/SIGHUP. Use the settings toggle instead.
+func watchLogSignals(_ context.Context, _ *slog.LevelVar, _ func()) {}
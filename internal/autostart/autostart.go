@@ -0,0 +1,41 @@
+// Package autostart installs or removes the entry that launches the application at login.
+// Each platform has its own mechanism: an XDG .desktop file (Linux, BSD),
+// a LaunchAgent (macOS) or a value under the "Run" registry key (Windows).
+package autostart
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Enable registers the running executable to start at login.
+// An existing entry is overwritten, which also repairs it after the binary has moved.
+func Enable() error {
+	exe, err := executable()
+	if err != nil {
+		return err
+	}
+	return enable(exe)
+}
+
+// Disable removes the autostart entry. Removing a missing entry is not an error.
+func Disable() error {
+	return disable()
+}
+
+// IsEnabled reports whether an autostart entry is installed.
+func IsEnabled() bool {
+	return isEnabled()
+}
+
+// executable returns the absolute path of the running binary, with symlinks resolved.
+func executable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return exe, nil
+}
@@ -0,0 +1,77 @@
+//go:build darwin
+
+package autostart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// launchAgent is the LaunchAgent property list; %s are the label and the escaped executable.
+const launchAgent = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+</dict>
+</plist>
+`
+
+// entryPath returns the location of the property list in the user's LaunchAgents directory.
+func entryPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, config.AutostartDirMac, config.AutostartFileMac), nil
+}
+
+func enable(exe string) error {
+	path, err := entryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), config.DirPermUserRWX); err != nil {
+		return err
+	}
+	var escaped bytes.Buffer
+	if err := xml.EscapeText(&escaped, []byte(exe)); err != nil {
+		return err
+	}
+	content := fmt.Sprintf(launchAgent, config.AppID, escaped.String())
+	return os.WriteFile(path, []byte(content), config.FilePermUserRW)
+}
+
+func disable() error {
+	path, err := entryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func isEnabled() bool {
+	path, err := entryPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,41 @@
+//go:build !windows && !darwin
+
+package autostart
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+func TestAutostart_XDG(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(config.EnvXDGConfigHome, dir)
+	path := filepath.Join(dir, config.AutostartDirXDG, config.AutostartFileXDG)
+
+	assert.False(t, IsEnabled())
+	require.NoError(t, Disable(), "Removing a missing entry must succeed")
+
+	require.NoError(t, enable(`/opt/my "apps"/go-birthday`))
+	assert.True(t, IsEnabled())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `Exec="/opt/my \"apps\"/go-birthday"`)
+	assert.Contains(t, string(content), "Name="+config.AppName)
+
+	// Enable overwrites the entry with the running executable.
+	require.NoError(t, Enable())
+	exe, err := executable()
+	require.NoError(t, err)
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), exe)
+
+	require.NoError(t, Disable())
+	assert.False(t, IsEnabled())
+}
@@ -0,0 +1,44 @@
+//go:build windows
+
+package autostart
+
+import (
+	"errors"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"golang.org/x/sys/windows/registry"
+)
+
+func enable(exe string) error {
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, config.AutostartRegRunKey, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+	return key.SetStringValue(config.AutostartRegValue, `"`+exe+`"`)
+}
+
+func disable() error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, config.AutostartRegRunKey, registry.SET_VALUE)
+	if errors.Is(err, registry.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+	if err := key.DeleteValue(config.AutostartRegValue); err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func isEnabled() bool {
+	key, err := registry.OpenKey(registry.CURRENT_USER, config.AutostartRegRunKey, registry.QUERY_VALUE)
+	if err != nil {
+		return false
+	}
+	defer key.Close()
+	_, _, err = key.GetStringValue(config.AutostartRegValue)
+	return err == nil
+}
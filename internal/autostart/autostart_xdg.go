@@ -0,0 +1,71 @@
+//go:build !windows && !darwin
+
+package autostart
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// desktopEntry is the XDG autostart file content; %s are the name and the quoted executable.
+const desktopEntry = `[Desktop Entry]
+Type=Application
+Name=%s
+Exec=%s
+Terminal=false
+X-GNOME-Autostart-enabled=true
+`
+
+// execEscaper escapes the characters reserved inside a quoted Exec argument.
+var execEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", `$`, `\$`)
+
+// entryPath returns the location of the .desktop file in the XDG autostart directory.
+func entryPath() (string, error) {
+	base := os.Getenv(config.EnvXDGConfigHome)
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, config.AutostartXDGDefault)
+	}
+	return filepath.Join(base, config.AutostartDirXDG, config.AutostartFileXDG), nil
+}
+
+func enable(exe string) error {
+	path, err := entryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), config.DirPermUserRWX); err != nil {
+		return err
+	}
+	content := fmt.Sprintf(desktopEntry, config.AppName, `"`+execEscaper.Replace(exe)+`"`)
+	return os.WriteFile(path, []byte(content), config.FilePermUserRW)
+}
+
+func disable() error {
+	path, err := entryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func isEnabled() bool {
+	path, err := entryPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
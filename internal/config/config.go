@@ -1,6 +1,7 @@
 package config
 
 import (
+	"image/color"
 	"io/fs"
 	"time"
 )
@@ -24,12 +25,31 @@ var UserAgent = "Go-Birthday/" + Version
 // -----------------------------------------------------------------------------
 
 const (
-	AppName           = "Go Birthday"
-	AppID             = "com.github.tartampluch.go-birthday"
-	KeyringService    = "com.github.tartampluch.go-birthday"
-	LocalhostBindAddr = "127.0.0.1"
-	LogFileName       = "app.log"
-	IconFile          = "Icon.png"
+	AppName             = "Go Birthday"
+	AppID               = "com.github.tartampluch.go-birthday"
+	KeyringService      = "com.github.tartampluch.go-birthday"
+	KeyringPushToken    = "push_token"           // Keyring account holding the push service token
+	KeyringProxyPass    = "proxy_password"       // Keyring account holding the proxy password
+	KeyringBearerToken  = "bearer_token"         // Keyring account holding the static bearer token
+	KeyringOAuth2Secret = "oauth2_client_secret" // Keyring account holding the OAuth2 client secret
+	KeyringOAuth2Token  = "oauth2_refresh_token" // Keyring account holding the OAuth2 refresh token
+	KeyringCalDAVPass   = "caldav_password"      // Keyring account holding the password of the CalDAV calendar
+	KeyringWebDAVPass   = "webdav_password"      // Keyring account holding the password of the WebDAV server
+	KeyringWebDAVToken  = "webdav_token"         // Keyring account holding the bearer token of the WebDAV server
+	KeyringProbe        = "probe"                // Looked up to check that the keyring answers
+	SecretsFile         = "secrets.enc"          // Encrypted secrets, when no keyring is available
+	LocalhostBindAddr   = "127.0.0.1"
+	LoopbackNetwork     = "127.0.0.0/8" // Allowlist of the server when the configured one is invalid
+	LogFileName         = "app.log"
+	CacheICSFile        = "calendar.ics"      // Last generated calendar, next to the log file
+	CacheContactsFile   = "contacts.json"     // Contacts of the last synchronization, without photos
+	CacheRevisionsFile  = "revisions.json"    // SEQUENCE and LAST-MODIFIED of the events, by UID
+	CalendarTempPattern = "go-birthday-*.ics" // Temporary file of a calendar too large for memory
+	IconFile            = "Icon.png"
+	UserConfigDirName   = "go-birthday" // Under the OS user config directory
+	LocalesDir          = "locales"     // Embedded and user translation files
+	LocalePrefix        = "active."
+	LocaleSuffix        = ".json"
 )
 
 // -----------------------------------------------------------------------------
@@ -54,6 +74,9 @@ const (
 	// Used for creating secure cache directories.
 	DirPermUserRWX fs.FileMode = 0700
 
+	// FilePermShared represents -rw-r--r--, for the calendar written to a user-chosen file.
+	FilePermShared fs.FileMode = 0644
+
 	// ChannelBufferSize defines the standard buffer size for internal signaling channels.
 	ChannelBufferSize = 1
 )
@@ -65,35 +88,178 @@ const (
 const (
 	FlagVersion      = "version"
 	FlagDebug        = "debug"
+	FlagWindow       = "window"
+	FlagValidate     = "validate"
+	FlagSyncOnce     = "sync-once"
+	FlagDaemon       = "daemon"
+	FlagPort         = "port"
+	FlagSource       = "source"
+	FlagMode         = "mode"
 	FlagDescVersion  = "Show application version and exit"
 	FlagDescDebug    = "Enable debug logging to stdout"
+	FlagDescWindow   = "Open a main window instead of relying on the system tray"
+	FlagDescValidate = "Check every generated calendar against RFC 5545 and log the problems found"
+	FlagDescSyncOnce = "Synchronize once, update the cache and publishing targets, then exit"
+	FlagDescDaemon   = "Run the server and the synchronizations without tray or window, e.g., as a systemd service"
+	FlagDescPort     = "Server port for this run, instead of the saved one"
+	FlagDescSource   = "Contacts for this run: a .vcf file or directory, a CardDAV URL, or the location used by -mode"
+	FlagDescMode     = "Source mode for this run (web, local, jmap, system, thunderbird, outlook, exec)"
+	ErrFlagMode      = "unknown source mode %q"
+	ErrFlagSource    = "-source cannot be used with source mode %q"
 	MsgVersionOutput = "%s version %s (%s/%s)\n"
 )
 
+// -----------------------------------------------------------------------------
+// Diagnostics ("go-birthday doctor")
+// -----------------------------------------------------------------------------
+
+const (
+	CmdDoctor          = "doctor"
+	CmdCheck           = "check" // Alias of CmdDoctor
+	FlagJSON           = "json"
+	FlagCards          = "cards"
+	FlagDescJSON       = "Print the report as JSON"
+	FlagDescCards      = "Number of vCards to parse (0 for all)"
+	DefaultDoctorCards = 50
+	DoctorTimeout      = 60 * time.Second
+	NetworkTCP         = "tcp"
+
+	// Status of a check; any DoctorFail makes the command exit with ExitCodeError.
+	DoctorOK   = "ok"
+	DoctorWarn = "warn"
+	DoctorFail = "fail"
+
+	DoctorCheckSource  = "source"
+	DoctorCheckVCards  = "vcards"
+	DoctorCheckPort    = "port"
+	DoctorCheckKeyring = "keyring"
+	DoctorCheckLocales = "locales"
+
+	DoctorMsgSource      = "%s: %d vCard(s) read"
+	DoctorMsgNoCards     = "%s: no vCard found"
+	DoctorMsgVCards      = "%d with a birthday, %d with an invalid date, %d malformed"
+	DoctorMsgNoBirthday  = "no vCard with a birthday"
+	DoctorMsgPortFree    = "%s is available"
+	DoctorMsgPortBusy    = "%s is in use (by another instance?): %v"
+	DoctorMsgServeOff    = "HTTP server disabled"
+	DoctorMsgKeyring     = "secrets stored in %s"
+	DoctorMsgLocales     = "%d language(s) complete"
+	DoctorMsgLocaleGap   = "%s: %d missing key(s) (%s)"
+	DoctorMsgLocaleBad   = "%s: %v"
+	DoctorMsgSkipped     = "skipped, no source"
+	DoctorReportLine     = "[%-4s] %-8s %s\n"
+	DoctorReportOK       = "All checks passed."
+	DoctorReportFailed   = "Some checks failed."
+	DoctorLocaleSample   = 3 // Missing keys listed per language
+	DoctorDetailSep      = "; "
+	DoctorLocaleKeysJoin = ", "
+)
+
 // -----------------------------------------------------------------------------
 // UI Constants & Preferences
 // -----------------------------------------------------------------------------
 
 const (
 	SettingsWindowWidth = 600
+	MainWindowWidth     = 380 // Fallback window used when there is no system tray
 
 	// Preference Keys
-	PrefCardDAVURL      = "carddav_url"
-	PrefUsername        = "username"
-	PrefLanguage        = "language"
-	PrefInterval        = "refresh_interval_min"
-	PrefServerPort      = "server_port"
-	PrefSourceMode      = "source_mode"
-	PrefLocalPath       = "local_path"
-	PrefReminderEnabled = "reminder_enabled"
-	PrefReminderValue   = "reminder_value"
-	PrefReminderUnit    = "reminder_unit"
-	PrefReminderDir     = "reminder_direction"
-	PrefLastRun         = "last_run_version"
+	PrefCardDAVURL        = "carddav_url"
+	PrefAddressBooks      = "carddav_address_books" // Address books merged instead of PrefCardDAVURL, empty for it alone
+	PrefUsername          = "username"
+	PrefAuthType          = "auth_type" // config.AuthBasic (default), config.AuthBearer or config.AuthOAuth2
+	PrefOAuth2TokenURL    = "oauth2_token_url"
+	PrefOAuth2ClientID    = "oauth2_client_id"
+	PrefProxyURL          = "proxy_url" // Explicit proxy of the web source, empty for the environment
+	PrefProxyUser         = "proxy_user"
+	PrefCAFile            = "ca_file"      // Extra PEM certificates trusted for the web source
+	PrefTLSInsecure       = "tls_insecure" // Skip TLS certificate verification of the web source
+	PrefClientCert        = "client_cert"  // PEM certificate for mutual TLS with the web source
+	PrefClientKey         = "client_key"
+	PrefLanguage          = "language"
+	PrefInterval          = "refresh_interval_min"    // Of the sources without their own interval
+	PrefIntervalOf        = "refresh_interval_min_%s" // Interval of a source mode
+	PrefServerPort        = "server_port"
+	PrefBindAddress       = "bind_address"     // Comma-separated interfaces of the server, LocalhostBindAddr (or ContainerBindAddr) if empty
+	PrefAllowedNetworks   = "allowed_networks" // Comma-separated CIDRs or addresses allowed to use the server, empty for any
+	PrefCORSOrigin        = "cors_origin"      // Origin allowed to read the server from a browser, CORSAnyOrigin for any, empty to disable
+	PrefSourceMode        = "source_mode"
+	PrefLocalPath         = "local_path"
+	PrefThunderbirdPath   = "thunderbird_path" // Empty to detect the default profile
+	PrefExecCommand       = "exec_command"     // Program of config.SourceModeExec
+	PrefReminderEnabled   = "reminder_enabled"
+	PrefReminderValue     = "reminder_value"
+	PrefReminderUnit      = "reminder_unit"
+	PrefReminderDir       = "reminder_direction"
+	PrefReminderTriggers  = "reminder_triggers" // List of ISO8601 durations
+	PrefLastRun           = "last_run_version"  // Version that last started, for prefs.Migrate
+	PrefPushBackend       = "push_backend"
+	PrefPushServer        = "push_server"
+	PrefPushTopic         = "push_topic"
+	PrefPushLastDate      = "push_last_date"  // Date (YYYY-MM-DD) of the last successful push
+	PrefNotifLastDate     = "notif_last_date" // Date (YYYY-MM-DD) of the last desktop notification of the birthdays
+	PrefTodayWindow       = "today_window"    // Lists today's birthdays on launch
+	PrefTodayDismissed    = "today_dismissed" // Date (YYYY-MM-DD) the list was dismissed for
+	PrefQuietStart        = "quiet_start"     // Start of the quiet hours (QuietTimeLayout), empty to disable
+	PrefQuietEnd          = "quiet_end"       // End of the quiet hours, when the held notifications are delivered
+	PrefPhotoMode         = "photo_mode"
+	PrefTheme             = "theme"             // Appearance: system, light or dark
+	PrefDateFormat        = "date_format"       // User pattern (e.g., "dd/MM/yyyy"), empty for the locale format
+	PrefTimeZone          = "time_zone"         // IANA name (e.g., "Europe/Paris"), empty for the system zone
+	PrefContactsShortcut  = "contacts_shortcut" // e.g., "Ctrl+B"
+	PrefDebugLogging      = "debug_logging"
+	PrefAccessLog         = "access_log"       // Log every request of the server under CompHTTPAccess
+	PrefCategories        = "event_categories" // Comma-separated list
+	PrefContactGroups     = "event_contact_groups"
+	PrefContactDetails    = "event_contact_details"
+	PrefPreferNickname    = "prefer_nickname"    // vCard NICKNAME instead of FN in the events and the contacts
+	PrefNameFormat        = "name_format"        // NameFormatFN, NameFormatGivenFamily or NameFormatFamilyGiven
+	PrefIncludeCategories = "filter_categories"  // Only sync contacts in these vCard CATEGORIES
+	PrefKnownCategories   = "known_categories"   // CATEGORIES discovered by the last sync
+	PrefCustomDates       = "custom_dates"       // Apple X-ABDATE anniversaries and other dates
+	PrefMemorials         = "memorials"          // Remembrance events on the date of death
+	PrefNameDays          = "name_days"          // Locale of the name-day table, empty to disable
+	PrefOverrides         = "birthday_overrides" // "uid=date" entries (vCard UID, or contact UID without one), date in vCard format
+	PrefHiddenContacts    = "hidden_contacts"    // UIDs left out of the calendar
+	PrefLunarContacts     = "lunar_contacts"     // UIDs celebrating their birthday by the Chinese lunar calendar
+	PrefOffsets           = "offsets"            // Offset anniversaries of every contact (e.g., "6m, 100d")
+	PrefContactOffsets    = "contact_offsets"    // "uid=6m,100d" entries, added to PrefOffsets
+	PrefEventColor        = "event_color"
+	PrefEventClass        = "event_class"      // ICalClassPublic, ICalClassPrivate or ICalClassConf
+	PrefEventTime         = "event_time"       // Start of timed events ("09:00"), empty for all-day events
+	PrefEventDuration     = "event_duration"   // Duration of timed events, in minutes
+	PrefCalendarName      = "calendar_name"    // X-WR-CALNAME and NAME, empty for ICalCalName
+	PrefCalendarDesc      = "calendar_desc"    // X-WR-CALDESC and DESCRIPTION, empty to omit
+	PrefCalendarColor     = "calendar_color"   // COLOR of the calendar (one of EventColors), empty to omit
+	PrefUIDDomain         = "uid_domain"       // Domain of the event UIDs, empty for ICalDomain
+	PrefTodoDays          = "todo_days"        // Days ahead served as to-dos under RouteTodos, 0 to disable
+	PrefSummaryTemplate   = "summary_template" // Go text/template, empty for localized defaults
+	PrefAgeDisplay        = "age_display"      // AgeDisplayTransition, AgeDisplayTurning or AgeDisplayNone
+	PrefZodiacColumn      = "zodiac_column"    // Zodiac sign column in the contacts list
+	PrefZodiacSummary     = "zodiac_summary"   // Zodiac sign emoji appended to birthday summaries
+	PrefMilestoneEnabled  = "milestone_enabled"
+	PrefMilestoneAges     = "milestone_ages" // Comma-separated list
+	PrefMilestoneEvery    = "milestone_every"
+	PrefMilestonePrefix   = "milestone_prefix"
+	PrefMilestoneReminder = "milestone_reminder_days"
+	PrefMaxDownloadMB     = "max_download_mb" // Synchronization limits, default when unset
+	PrefMaxContacts       = "max_contacts"
+	PrefMaxCardMB         = "max_card_mb"
+	PrefRequestSpacing    = "request_spacing_sec" // Between two requests to the source, 0 for none
+	PrefCalDAVEnabled     = "caldav_enabled"      // Publish the events to a CalDAV calendar
+	PrefCalDAVURL         = "caldav_url"
+	PrefCalDAVUser        = "caldav_user"
+	PrefWebDAVEnabled     = "webdav_enabled" // Upload the calendar file to a WebDAV server
+	PrefWebDAVURL         = "webdav_url"
+	PrefWebDAVUser        = "webdav_user"
+	PrefOutputFile        = "output_file"  // Calendar copy written after each sync, empty to disable
+	PrefServeHTTP         = "serve_http"   // Serve the calendar on the local port (default true)
+	PrefHookSuccess       = "hook_success" // Shell command run after each successful sync
+	PrefHookFailure       = "hook_failure" // Shell command run after each failed sync
 )
 
 // SupportedLanguages defines the list of available UI languages (ISO 639-1).
-var SupportedLanguages = []string{"en", "fr"}
+var SupportedLanguages = []string{"de", "en", "es", "fr", "it", "nl", "pt"}
 
 // -----------------------------------------------------------------------------
 // UI Contacts Window Constants
@@ -101,26 +267,99 @@ var SupportedLanguages = []string{"en", "fr"}
 
 const (
 	// Window Dimensions
-	ContactsWinWidth  = 550 // Slightly wider to accommodate "Age -> Age"
+	ContactsWinWidth  = 690 // Room for "Age -> Age" and the countdown column
 	ContactsWinHeight = 400
 
+	// Host window of the calendar export file dialog
+	ExportWinWidth  = 700
+	ExportWinHeight = 500
+
+	// Log viewer window
+	LogsWinWidth  = 800
+	LogsWinHeight = 500
+
+	// Today's birthdays, on launch
+	TodayWinWidth  = 360
+	TodayWinHeight = 240
+
+	// Calendar preview of the settings window
+	PreviewWinHeight = 500
+	PreviewMaxLines  = 50
+
 	// Table Column IDs
 	ColIDName = 0
 	ColIDDate = 1
 	ColIDAge  = 2
+	ColIDDays = 3
+	ColIDSign = 4 // Zodiac sign, hidden unless PrefZodiacColumn
+	ColCount  = 5
 
 	// Table Layout
 	ColWidthName = 250
 	ColWidthDate = 120
 	ColWidthAge  = 120 // Increased for transition format
+	ColWidthDays = 130
+	ColWidthSign = 150
+
+	// Countdown column: from this many days on, the delay is shown in months.
+	CountdownMonthDays = 45
+	AvgDaysPerMonth    = 30.44
 
 	// Display Formats & Placeholders
 	DateFormatDisplay = "2006-01-02"
 	TablePlaceholder  = "Cell Content"
 	AgeUnknown        = "-"
 	AgeBirth          = "(birth)"
-	LogMsgOpenWin     = "Opening Contacts Window"
-	LogMsgSorted      = "Contacts sorted"
+
+	// Age display (privacy), applied to summaries, the contacts table, the tray and notifications
+	AgeDisplayTransition = "transition" // "29 → 30" in the contacts table, the age turned elsewhere
+	AgeDisplayTurning    = "turning"    // "30"
+	AgeDisplayNone       = "none"
+	DefaultAgeDisplay    = AgeDisplayTransition
+	OverrideMarker       = " ✎" // Appended to the name of contacts with a corrected date
+	LunarMarker          = " ☾" // Appended to the name of contacts with a lunar birthday
+	ExportFileName       = "birthdays" + ExtCSV
+	ExportCalFileName    = "birthdays" + ExtICS
+	BackupFileName       = "go-birthday-settings" + ExtJSON
+
+	// Versions compared by the preference migrations ("v1.2.3-rc1")
+	VersionPrefix = "v"
+	VersionSep    = "."
+	VersionPreSep = "-"
+	BackupVersion = 1
+
+	// Tray icon badge (today's birthday count)
+	BadgeRadiusRatio = 0.3 // Badge radius relative to the icon size
+	BadgeTextRatio   = 70  // Label size, in percent of the badge diameter
+	BadgeMaxCount    = 9   // Larger counts are shown as "9+"
+	IconBadgeFile    = "IconBadge.png"
+
+	// Clickable notifications (freedesktop notification service, over D-Bus)
+	DBusNotifyIface   = "org.freedesktop.Notifications"
+	DBusNotifyPath    = "/org/freedesktop/Notifications"
+	DBusNotifyMethod  = DBusNotifyIface + ".Notify"
+	DBusActionInvoked = DBusNotifyIface + ".ActionInvoked"
+	DBusNotifyClosed  = DBusNotifyIface + ".NotificationClosed"
+	DBusDefaultAction = "default" // Action of a click on the notification itself
+	DBusSignalBuffer  = 16
+
+	// Tray snooze of the birthday notification
+	SnoozeHour       = "1h"
+	SnoozeHours      = "4h"
+	SnoozeTomorrow   = "tomorrow" // Same time on the next day
+	SnoozeHoursDelay = 4 * time.Hour
+
+	// Tray "Upcoming birthdays" submenu
+	UpcomingCount       = 5
+	DateFormatDay       = "Jan 2"
+	FormatUpcoming      = "%s – %s" // Date, Name
+	FormatUpcomingAge   = " (%s)"
+	FormatMegabytes     = "%.1f MB"
+	FormatAgeTransition = "%s → %d" // Previous age, or the word for birth; next age
+	OverrideSeparator   = "="
+	TitleSeparator      = " — "
+	LogMsgOpenWin       = "Opening Contacts Window"
+	LogMsgSorted        = "Contacts sorted"
 
 	// Sorting Indicators
 	SortIconAsc  = " ▲"
@@ -132,53 +371,358 @@ const (
 // -----------------------------------------------------------------------------
 
 const (
-	TKeyWinTitle        = "win_title"
-	TKeyWinContacts     = "win_contacts_title"
-	TKeyMenuRefresh     = "menu_refresh"
-	TKeyMenuSettings    = "menu_settings"
-	TKeyTrayStatus      = "tray_status"      // Requires Count > 0
-	TKeyTrayStatusZero  = "tray_status_zero" // Explicit key for 0
-	TKeyNotifStart      = "notif_sync_start"
-	TKeyNotifSuccess    = "notif_sync_success"
-	TKeyNotifError      = "notif_err_sync"
-	TKeyModeCardDAV     = "mode_carddav"
-	TKeyModeLocal       = "mode_local"
-	TKeyLblLanguage     = "lbl_language"
-	TKeyHelpLanguage    = "help_language"
-	TKeyLblMinutes      = "lbl_minutes_suffix"
-	TKeyLblRefresh      = "lbl_refresh_interval"
-	TKeyHelpInterval    = "help_interval"
-	TKeyLblPort         = "lbl_server_port"
-	TKeyHelpPort        = "help_port"
-	TKeyLblGeneral      = "lbl_general"
-	TKeyLblEnableRem    = "lbl_enable_reminders"
-	TKeyUnitDays        = "unit_days"
-	TKeyUnitHours       = "unit_hours"
-	TKeyUnitMinutes     = "unit_minutes"
-	TKeyDirBefore       = "dir_before"
-	TKeyDirAfter        = "dir_after"
-	TKeyLblNotif        = "lbl_notifications"
-	TKeyBtnSave         = "btn_save"
-	TKeyBtnCancel       = "btn_cancel"
-	TKeyLblFooter       = "lbl_footer"
-	TKeyBtnBrowse       = "btn_browse"
-	TKeyLblURL          = "lbl_url"
-	TKeyHelpURL         = "help_carddav_url"
-	TKeyLblUser         = "lbl_user"
-	TKeyLblPass         = "lbl_pass"
-	TKeyLblSource       = "lbl_source"
-	TKeyLblStartDay     = "lbl_start_of_day"
-	TKeyEvtSummary      = "event_summary"       // Requires Name
-	TKeyEvtSummaryAge   = "event_summary_age"   // Requires Name, Age
-	TKeyEvtSummaryBirth = "event_summary_birth" // Requires Name (For age 0)
+	TKeyWinTitle            = "win_title"
+	TKeyWinContacts         = "win_contacts_title"
+	TKeyMenuRefresh         = "menu_refresh"
+	TKeyMenuSettings        = "menu_settings"
+	TKeyMenuExport          = "menu_export"
+	TKeyMenuUpcoming        = "menu_upcoming"
+	TKeyMenuLastSync        = "menu_last_sync"        // Requires time
+	TKeyMenuLastSyncFailed  = "menu_last_sync_failed" // Requires time
+	TKeyMenuLastSyncNever   = "menu_last_sync_never"
+	TKeyMenuLastSyncOffline = "menu_last_sync_offline" // Requires time
+	TKeyMenuNextSync        = "menu_next_sync"         // Requires minutes
+	TKeyMenuNextSyncSoon    = "menu_next_sync_soon"
+	TKeyMenuSyncing         = "menu_syncing"
+	TKeyMenuSyncProgress    = "menu_sync_progress" // Requires contacts, size
+	TKeyMenuCancelSync      = "menu_cancel_sync"
+	TKeyBtnContacts         = "btn_contacts"
+	TKeyLblNoTray           = "lbl_no_tray"
+	TKeyTrayStatus          = "tray_status"      // Requires Count > 0
+	TKeyTrayStatusZero      = "tray_status_zero" // Explicit key for 0
+	TKeyNotifStart          = "notif_sync_start"
+	TKeyNotifSuccess        = "notif_sync_success"
+	TKeyNotifError          = "notif_err_sync"
+	TKeyNotifNoCal          = "notif_no_calendar"
+	TKeyNotifExported       = "notif_calendar_exported"
+	TKeyMenuCopyURL         = "menu_copy_url"
+	TKeyMenuOpenCalApp      = "menu_open_calendar_app"
+	TKeyNotifURLCopied      = "notif_url_copied"
+	TKeyNotifNoCalApp       = "notif_no_calendar_app"
+	TKeyWinToday            = "win_today"
+	TKeyLblTodayDismiss     = "lbl_today_dismiss"
+	TKeyLblTodayWindow      = "lbl_today_window"
+	TKeyMenuSnooze          = "menu_snooze"
+	TKeySnoozeHour          = "snooze_hour"
+	TKeySnoozeHours         = "snooze_hours"
+	TKeySnoozeTomorrow      = "snooze_tomorrow"
+	TKeyLblQuietHours       = "lbl_quiet_hours"
+	TKeyHelpQuietHours      = "help_quiet_hours"
+	TKeyErrQuietHours       = "err_quiet_hours"
+	TKeyModeCardDAV         = "mode_carddav"
+	TKeyModeLocal           = "mode_local"
+	TKeyModeJMAP            = "mode_jmap"
+	TKeyHelpJMAPURL         = "help_jmap_url"
+	TKeyModeSystem          = "mode_system"
+	TKeyNotifDenied         = "notif_contacts_denied"
+	TKeyModeThunderbird     = "mode_thunderbird"
+	TKeyModeOutlook         = "mode_outlook"
+	TKeyNotifOutlook        = "notif_outlook_unavailable"
+	TKeyLblThunderbird      = "lbl_thunderbird_profile"
+	TKeyHelpThunderbird     = "help_thunderbird_profile"
+	TKeyModeExec            = "mode_exec"
+	TKeyLblExec             = "lbl_exec_command"
+	TKeyHelpExec            = "help_exec_command"
+	TKeyPlaceholderDetected = "placeholder_detected"
+	TKeyLblLanguage         = "lbl_language"
+	TKeyHelpLanguage        = "help_language"
+	TKeyLblMinutes          = "lbl_minutes_suffix"
+	TKeyLblRefresh          = "lbl_refresh_interval"
+	TKeyHelpInterval        = "help_interval"
+	TKeyLblPort             = "lbl_server_port"
+	TKeyHelpPort            = "help_port"
+	TKeyLblGeneral          = "lbl_general"
+	TKeyLblEnableRem        = "lbl_enable_reminders"
+	TKeyUnitDays            = "unit_days"
+	TKeyUnitHours           = "unit_hours"
+	TKeyUnitMinutes         = "unit_minutes"
+	TKeyDirBefore           = "dir_before"
+	TKeyDirAfter            = "dir_after"
+	TKeyLblNotif            = "lbl_notifications"
+	TKeyBtnSave             = "btn_save"
+	TKeyBtnCancel           = "btn_cancel"
+	TKeyLblFooter           = "lbl_footer"
+	TKeyBtnBrowse           = "btn_browse"
+	TKeyBtnFolder           = "btn_browse_folder"
+	TKeyTitleDrop           = "title_drop_source"
+	TKeyConfirmDrop         = "confirm_drop_source" // Requires a %s (file path)
+	TKeyLblURL              = "lbl_url"
+	TKeyHelpURL             = "help_carddav_url"
+	TKeyLblUser             = "lbl_user"
+	TKeyLblPass             = "lbl_pass"
+	TKeyLblProxy            = "lbl_proxy"
+	TKeyHelpProxy           = "help_proxy"
+	TKeyLblProxyUser        = "lbl_proxy_user"
+	TKeyLblProxyPass        = "lbl_proxy_pass"
+	TKeyLblCAFile           = "lbl_ca_file"
+	TKeyHelpCAFile          = "help_ca_file"
+	TKeyLblTLSInsecure      = "lbl_tls_insecure"
+	TKeyLblClientCert       = "lbl_client_cert"
+	TKeyHelpClientCert      = "help_client_cert"
+	TKeyLblClientKey        = "lbl_client_key"
+	TKeyLblAuth             = "lbl_auth"
+	TKeyAuthBasic           = "auth_basic"
+	TKeyAuthBearer          = "auth_bearer"
+	TKeyLblToken            = "lbl_token"
+	TKeyLblTokenURL         = "lbl_token_url"
+	TKeyHelpTokenURL        = "help_token_url"
+	TKeyLblClientID         = "lbl_client_id"
+	TKeyLblClientSecret     = "lbl_client_secret"
+	TKeyLblRefreshToken     = "lbl_refresh_token"
+	TKeyHelpRefreshToken    = "help_refresh_token"
+	TKeyLblPublish          = "lbl_publish"
+	TKeyLblCalDAVEnable     = "lbl_caldav_enable"
+	TKeyLblCalDAVURL        = "lbl_caldav_url"
+	TKeyHelpCalDAVURL       = "help_caldav_url"
+	TKeyLblWebDAVEnable     = "lbl_webdav_enable"
+	TKeyLblWebDAVURL        = "lbl_webdav_url"
+	TKeyHelpWebDAVURL       = "help_webdav_url"
+	TKeyHelpWebDAVToken     = "help_webdav_token"
+	TKeyLblServeHTTP        = "lbl_serve_http"
+	TKeyLblOutputFile       = "lbl_output_file"
+	TKeyHelpOutputFile      = "help_output_file"
+	TKeyLblCORSOrigin       = "lbl_cors_origin"
+	TKeyHelpCORSOrigin      = "help_cors_origin"
+	TKeyErrCORSOrigin       = "err_cors_origin"
+	TKeyLblAllowedNets      = "lbl_allowed_networks"
+	TKeyHelpAllowedNets     = "help_allowed_networks"
+	TKeyErrAllowedNets      = "err_allowed_networks"
+	TKeyLandingUpdated      = "landing_updated"
+	TKeyLandingContacts     = "landing_contacts"
+	TKeyLandingSubscribe    = "landing_subscribe"
+	TKeyLblHookSuccess      = "lbl_hook_success"
+	TKeyLblHookFailure      = "lbl_hook_failure"
+	TKeyHelpHooks           = "help_hooks"
+	TKeyBtnExportSettings   = "btn_export_settings"
+	TKeyBtnImportSettings   = "btn_import_settings"
+	TKeyLblBackupPass       = "lbl_backup_passphrase"
+	TKeyHelpBackupExport    = "help_backup_export"
+	TKeyHelpBackupImport    = "help_backup_import"
+	TKeyMsgSettingsImported = "msg_settings_imported"
+	TKeyBtnPreviewCalendar  = "btn_preview_calendar"
+	TKeyWinPreviewCalendar  = "win_preview_calendar"
+	TKeyMsgPreviewCalendar  = "msg_preview_calendar" // Requires events, contacts and lines counts
+	TKeyLblSource           = "lbl_source"
+	TKeyLblStartDay         = "lbl_start_of_day"
+	TKeyBtnAddRem           = "btn_add_reminder"
+	TKeyEvtSummary          = "event_summary"                // Requires Name
+	TKeyEvtSummaryAge       = "event_summary_age"            // Requires Name, Age
+	TKeyEvtSummaryBirth     = "event_summary_birth"          // Requires Name (For age 0)
+	TKeyEvtDate             = "event_summary_date"           // Requires Label, Name
+	TKeyEvtDateYears        = "event_summary_date_years"     // Requires Label, Name, Years
+	TKeyEvtMemorial         = "event_summary_memorial"       // Requires Name
+	TKeyEvtMemorialYears    = "event_summary_memorial_years" // Requires Name, Years
+	TKeyLblMemorials        = "lbl_memorials"
+	TKeyEvtNameDay          = "event_summary_name_day" // Requires Name
+	TKeyLblNameDays         = "lbl_name_days"
+	TKeyHelpNameDays        = "help_name_days"
+	TKeyNameDaysNone        = "name_days_none"
+	TKeyEvtHalfBirthday     = "event_summary_half_birthday" // Requires Name
+	TKeyEvtOffsetMonths     = "event_summary_offset_months" // Requires Name, Months
+	TKeyEvtOffsetDays       = "event_summary_offset_days"   // Requires Name, Days
+	TKeyLblOffsets          = "lbl_offsets"
+	TKeyHelpOffsets         = "help_offsets"
+	TKeyErrOffsets          = "err_offsets"
+	TKeyErrUIDDomain        = "err_uid_domain"
+	TKeyBtnOffsets          = "btn_offsets"
+	TKeyWinOffsets          = "win_offsets_title"
+	TKeyHelpContactOffsets  = "help_contact_offsets"
+	TKeyLabelAnniv          = "label_anniversary"
+	TKeyLabelOther          = "label_other"
+
+	// Push Notifications
+	TKeyLblPush        = "lbl_push"
+	TKeyLblPushBackend = "lbl_push_backend"
+	TKeyPushNone       = "push_none"
+	TKeyLblPushServer  = "lbl_push_server"
+	TKeyHelpPushServer = "help_push_server"
+	TKeyLblPushTopic   = "lbl_push_topic"
+	TKeyLblPushToken   = "lbl_push_token"
+	TKeyPushTitle      = "push_title" // Requires Count
+	TKeyPushBody       = "push_body"  // Requires Names and Date
+
+	// Appearance
+	TKeyLblTheme    = "lbl_theme"
+	TKeyHelpTheme   = "help_theme"
+	TKeyThemeSystem = "theme_system"
+	TKeyThemeLight  = "theme_light"
+	TKeyThemeDark   = "theme_dark"
+
+	// Autostart
+	TKeyLblAutostart = "lbl_autostart"
+
+	// Diagnostics
+	TKeyLblDebugLog  = "lbl_debug_logging"
+	TKeyLblAccessLog = "lbl_access_log"
+	TKeyMenuLogs     = "menu_logs"
+	TKeyWinLogs      = "win_logs_title"
+	TKeyLblLogLevel  = "lbl_log_level"
+	TKeyLogsEmpty    = "logs_empty"
+
+	// Synchronization limits
+	TKeyLblLimits      = "lbl_limits"
+	TKeyLblCalendar    = "lbl_calendar"
+	TKeyLblCalName     = "lbl_calendar_name"
+	TKeyLblCalDesc     = "lbl_calendar_desc"
+	TKeyLblCalColor    = "lbl_calendar_color"
+	TKeyHelpCalendar   = "help_calendar"
+	TKeyLblUIDDomain   = "lbl_uid_domain"
+	TKeyHelpUIDDomain  = "help_uid_domain"
+	TKeyLblTodoDays    = "lbl_todo_days"
+	TKeyHelpTodoDays   = "help_todo_days"
+	TKeyHelpLimits     = "help_limits"
+	TKeyLblMaxDownload = "lbl_max_download"
+	TKeyLblMaxContacts = "lbl_max_contacts"
+	TKeyLblMaxCard     = "lbl_max_card"
+	TKeyUnitMB         = "unit_mb"
+	TKeyUnitSeconds    = "unit_seconds"
+	TKeyLblSpacing     = "lbl_request_spacing"
+	TKeyHelpSpacing    = "help_request_spacing"
+	TKeyNotifLimit     = "notif_err_limit"
+
+	// Keyboard Shortcuts & Search
+	TKeyLblShortcut  = "lbl_contacts_shortcut"
+	TKeyHelpShortcut = "help_contacts_shortcut"
+	TKeyErrShortcut  = "err_shortcut"
+	TKeyPhSearch     = "ph_search"
+
+	// Date Display
+	TKeyLblDateFormat  = "lbl_date_format"
+	TKeyHelpDateFormat = "help_date_format"
+	TKeyLblTimeZone    = "lbl_time_zone"
+	TKeyHelpTimeZone   = "help_time_zone"
+	TKeyErrTimeZone    = "err_time_zone"
+
+	// Contact Photos
+	TKeyLblPhotos   = "lbl_photos"
+	TKeyHelpPhotos  = "help_photos"
+	TKeyPhotoNone   = "photo_none"
+	TKeyPhotoInline = "photo_inline"
+	TKeyPhotoLink   = "photo_link"
+
+	// Event Properties
+	TKeyLblEvents      = "lbl_events"
+	TKeyLblCategories  = "lbl_categories"
+	TKeyHelpCategories = "help_categories"
+	TKeyLblGroups      = "lbl_contact_groups"
+	TKeyLblDetails     = "lbl_contact_details"
+	TKeyLblPreferNick  = "lbl_prefer_nickname"
+	TKeyLblNameFormat  = "lbl_name_format"
+	TKeyHelpNameFormat = "help_name_format"
+	TKeyNameFormatFN   = "name_format_fn"
+	TKeyNameFormatGF   = "name_format_given_family"
+	TKeyNameFormatFG   = "name_format_family_given"
+	TKeyLblFilter      = "lbl_filter_categories"
+	TKeyHelpFilter     = "help_filter_categories"
+	TKeyFilterEmpty    = "filter_categories_empty"
+	TKeyLblCustomDates = "lbl_custom_dates"
+	TKeyLblColor       = "lbl_color"
+	TKeyHelpColor      = "help_color"
+	TKeyColorNone      = "color_none"
+	TKeyLblClass       = "lbl_event_class"
+	TKeyLblEventTime   = "lbl_event_time"
+	TKeyHelpEventTime  = "help_event_time"
+	TKeyPhAllDay       = "ph_all_day"
+	TKeyLblEventDur    = "lbl_event_duration"
+	TKeyErrEventTime   = "err_event_time"
+	TKeyHelpClass      = "help_event_class"
+	TKeyClassPublic    = "event_class_public"
+	TKeyClassPrivate   = "event_class_private"
+	TKeyClassConf      = "event_class_confidential"
+	TKeyLblSummary     = "lbl_summary_template"
+	TKeyHelpSummary    = "help_summary_template"
+	TKeyLblAgeDisplay  = "lbl_age_display"
+	TKeyHelpAgeDisplay = "help_age_display"
+	TKeyAgeTransition  = "age_display_transition"
+	TKeyAgeTurning     = "age_display_turning"
+	TKeyAgeNone        = "age_display_none"
+	TKeyLblPreview     = "lbl_preview"
+	TKeyLblZodiacCol   = "lbl_zodiac_column"
+	TKeyLblZodiacSum   = "lbl_zodiac_summary"
+	TKeyErrTemplate    = "err_summary_template"
+
+	// Milestones
+	TKeyLblMilestones      = "lbl_milestones"
+	TKeyLblEnableMilestone = "lbl_enable_milestones"
+	TKeyLblMilestoneAges   = "lbl_milestone_ages"
+	TKeyHelpMilestoneAges  = "help_milestone_ages"
+	TKeyLblMilestoneEvery  = "lbl_milestone_every"
+	TKeyHelpMilestoneEvery = "help_milestone_every"
+	TKeyLblMilestonePrefix = "lbl_milestone_prefix"
+	TKeyLblMilestoneRem    = "lbl_milestone_reminder"
+	TKeyHelpMilestoneRem   = "help_milestone_reminder"
 
 	// Column Headers & Formats
 	TKeyColName    = "col_name"
 	TKeyColDate    = "col_date"
 	TKeyColAge     = "col_age"
+	TKeyColDays    = "col_days"
+	TKeyColSign    = "col_sign"
+	TKeyColDOB     = "col_dob"           // CSV export only
+	TKeyColNext    = "col_next"          // CSV export only
 	TKeyFormatDate = "format_date_short" // Date format pattern (e.g., "2006-01-02")
+	TKeyFormatDay  = "format_day_month"  // Day without year for the tray (e.g., "Jan 2")
 	TKeyAgeBirth   = "age_birth"         // Word for "Birth" / "Naissance" in list
 
+	// Birthday Overrides
+	TKeyWinOverride     = "win_override_title"
+	TKeyLblOverrideDate = "lbl_override_date"
+	TKeyHelpOverride    = "help_override_date"
+	TKeyLblYearUnknown  = "lbl_year_unknown"
+	TKeyErrOverride     = "err_override_date"
+	TKeyLblContactsHint = "lbl_contacts_hint"
+	TKeyBtnExportCSV    = "btn_export_csv"
+	TKeyLblShowHidden   = "lbl_show_hidden"
+	TKeyLblDOB          = "lbl_dob"
+	TKeyLblExactAge     = "lbl_exact_age"
+	TKeyLblDaysLeft     = "lbl_days_left"
+	TKeyLblContactSrc   = "lbl_contact_source"
+	TKeyLblUID          = "lbl_uid"
+	TKeyLblFullName     = "lbl_full_name"
+	TKeyLblNickname     = "lbl_nickname"
+	TKeyLblNote         = "lbl_note"
+	TKeyAgeExact        = "age_exact" // Requires Years, Months, Days
+	TKeyDaysLeft        = "days_left" // Requires Days
+	TKeyDaysToday       = "days_today"
+	TKeyCountdownDays   = "countdown_days"   // Requires Count (plural)
+	TKeyCountdownMonths = "countdown_months" // Requires Count (plural)
+	TKeyBtnHide         = "btn_hide"
+	TKeyBtnUnhide       = "btn_unhide"
+	TKeyBtnEditDate     = "btn_edit_date"
+	TKeyBtnCopy         = "btn_copy"
+	TKeyLblLunar        = "lbl_lunar_birthday"
+	TKeyBtnClose        = "btn_close"
+
+	// Nextcloud Setup
+	TKeyBtnNextcloud     = "btn_nextcloud"
+	TKeyWinNextcloud     = "win_nextcloud_title"
+	TKeyLblNextcloudURL  = "lbl_nextcloud_url"
+	TKeyHelpNextcloudURL = "help_nextcloud_url"
+	TKeyHelpAppPassword  = "help_app_password"
+	TKeyLblAddressBook   = "lbl_address_book"
+	TKeyBtnNext          = "btn_next"
+	TKeyBtnUse           = "btn_use"
+	TKeyBtnAddressBooks  = "btn_address_books"
+	TKeyWinAddressBooks  = "win_address_books"
+	TKeyHelpAddressBooks = "help_address_books"
+	TKeyLblBooksMerged   = "lbl_books_merged"
+
+	// Test Connection
+	TKeyBtnTestConn      = "btn_test_connection"
+	TKeyTestConnOK       = "test_conn_ok"
+	TKeyTestConnSkipped  = "test_conn_skipped"
+	TKeyTestConnEmpty    = "test_conn_empty"
+	TKeyTestConnParse    = "test_conn_parse"
+	TKeyTestConnAuth     = "test_conn_auth"
+	TKeyTestConnNotFound = "test_conn_not_found"
+	TKeyTestConnStatus   = "test_conn_status"
+	TKeyTestConnTLS      = "test_conn_tls"
+	TKeyTestConnNetwork  = "test_conn_network"
+	TKeyTestConnFailed   = "test_conn_failed"
+
+	// Home Assistant
+	TKeyHelpHomeAssistant = "help_homeassistant"
+
 	// Validation Errors (UI)
 	TKeyErrPortReq   = "err_port_required"
 	TKeyErrPortNum   = "err_port_number"
@@ -190,8 +734,17 @@ const (
 // -----------------------------------------------------------------------------
 
 const (
-	SourceModeWeb        = "web"
-	SourceModeLocal      = "local"
+	SourceModeWeb         = "web"
+	SourceModeLocal       = "local"
+	SourceModeJMAP        = "jmap"
+	SourceModeSystem      = "system" // Contacts.app, macOS only
+	SourceModeThunderbird = "thunderbird"
+	SourceModeOutlook     = "outlook"       // Windows only
+	SourceModeExec        = "exec"          // Program printing the contacts as JSON
+	ExecWaitDelay         = 5 * time.Second // Output still read after the program exited, if a background child keeps it open
+	GOOSWindows           = "windows"
+	GOOSMac               = "darwin"
+
 	DefaultPort          = "18080"
 	DefaultRefreshMin    = 60
 	DefaultLanguage      = "en"
@@ -199,6 +752,167 @@ const (
 	DefaultReminderValue = 1
 	UIDSalt              = "go-birthday-v1-" // Salt for deterministic UID generation
 	DisabledInterval     = 0
+
+	// Synchronization limits, adjustable in the settings
+	DefaultMaxDownloadMB = 256 // Address book size
+	DefaultMaxContacts   = 100000
+	DefaultMaxCardMB     = 10 // Single vCard, mostly its photo
+	BytesPerMB           = 1024 * 1024
+)
+
+// Event Categories & Colors
+const (
+	DefaultCategory = "Birthday"
+
+	// Sample contact used to preview the summary template in settings.
+	PreviewName        = "Jane Doe"
+	PreviewAge         = 30
+	SummaryTmpl        = "summary"
+	SummaryPlaceholder = "🎂 {{.Name}}{{if .YearKnown}} ({{.Age}}){{end}}"
+	ListSeparator      = ","
+)
+
+// SourceModes lists the built-in sources; registered sources use other modes.
+var SourceModes = []string{
+	SourceModeWeb, SourceModeLocal, SourceModeJMAP, SourceModeSystem, SourceModeThunderbird, SourceModeOutlook, SourceModeExec,
+}
+
+// BackupSkipPrefs are the preferences left out of settings files: the state of this installation,
+// and the shell commands, which a file of unknown origin must not be able to set (the import syncs).
+var BackupSkipPrefs = []string{
+	PrefLastRun, PrefPushLastDate, PrefNotifLastDate, PrefTodayDismissed, PrefKnownCategories,
+	PrefHookSuccess, PrefHookFailure, PrefExecCommand,
+}
+
+// SourcePrefs maps the built-in sources to the preference locating their contacts, set by FlagSource.
+var SourcePrefs = map[string]string{
+	SourceModeWeb:         PrefCardDAVURL,
+	SourceModeJMAP:        PrefCardDAVURL,
+	SourceModeLocal:       PrefLocalPath,
+	SourceModeThunderbird: PrefThunderbirdPath,
+	SourceModeExec:        PrefExecCommand,
+}
+
+// EventColors lists the CSS3 color names offered for the RFC 7986 COLOR property.
+var EventColors = []string{
+	"red", "orange", "gold", "green", "teal", "blue", "purple", "pink", "brown", "gray",
+}
+
+// Western zodiac signs, from Aries: emoji, first day (month*100 + day) and translation key.
+var (
+	ZodiacEmojis    = []string{"♈", "♉", "♊", "♋", "♌", "♍", "♎", "♏", "♐", "♑", "♒", "♓"}
+	ZodiacStarts    = []int{321, 420, 521, 621, 723, 823, 923, 1023, 1122, 1222, 120, 219}
+	TKeyZodiacSigns = []string{
+		"zodiac_aries", "zodiac_taurus", "zodiac_gemini", "zodiac_cancer", "zodiac_leo", "zodiac_virgo",
+		"zodiac_libra", "zodiac_scorpio", "zodiac_sagittarius", "zodiac_capricorn", "zodiac_aquarius", "zodiac_pisces",
+	}
+)
+
+// Tray badge colors
+var (
+	BadgeColor     = color.RGBA{R: 0xE5, G: 0x39, B: 0x35, A: 0xFF}
+	BadgeTextColor = color.White
+)
+
+// DateFormatPresets lists the date patterns suggested in the settings.
+// Letters follow the usual conventions: yyyy/yy year, MMMM/MMM/MM/M month, dd/d day, EEEE/EEE weekday.
+var DateFormatPresets = []string{
+	"dd/MM/yyyy", "MM/dd/yyyy", "yyyy-MM-dd", "d MMM yyyy", "MMM d", "EEE d MMM",
+}
+
+// TimeZonePresets lists the IANA time zones suggested in the settings; any other can be typed.
+var TimeZonePresets = []string{
+	"UTC", "Europe/London", "Europe/Paris", "Europe/Berlin", "America/New_York", "America/Chicago",
+	"America/Los_Angeles", "America/Sao_Paulo", "Asia/Tokyo", "Asia/Shanghai", "Australia/Sydney",
+}
+
+// DateTokens maps date pattern letters to Go layout elements, longest tokens first.
+var DateTokens = []string{
+	"yyyy", "2006", "yy", "06",
+	"MMMM", "January", "MMM", "Jan", "MM", "01", "M", "1",
+	"dd", "02", "d", "2",
+	"EEEE", "Monday", "EEE", "Mon",
+}
+
+// Milestone Defaults
+const (
+	DefaultMilestoneAges   = "18,21"
+	DefaultMilestoneEvery  = 10
+	DefaultMilestonePrefix = "🎉"
+	DefaultMilestoneDays   = 14 // Extra reminder two weeks ahead, time to buy a present
+)
+
+// Keyboard Shortcuts
+// "Ctrl" stands for the platform shortcut modifier (Cmd on macOS).
+const (
+	ShortcutKeyRefresh      = "R"
+	ShortcutKeySettings     = ","
+	ShortcutKeySearch       = "F"
+	ShortcutSeparator       = "+"
+	DefaultContactsShortcut = "Ctrl+B"
+)
+
+// Log Viewer
+// Levels match the "level" field written by the slog JSON handler.
+const (
+	LogViewerMaxLines = 500
+	LogViewerMaxBytes = 256 * 1024 // Only the end of the file is read
+	LogViewerRefresh  = 2 * time.Second
+	LogJSONLevelKey   = "level"
+)
+
+// LogViewerLevels lists the minimum levels offered by the log viewer filter.
+var LogViewerLevels = []string{"DEBUG", "INFO", "WARN", "ERROR"}
+
+// Appearance Themes
+const (
+	ThemeSystem       = "system" // Follow the OS light/dark setting
+	ThemeLight        = "light"
+	ThemeDark         = "dark"
+	ContactsTextScale = 1.15 // Slightly larger text in the contacts table
+)
+
+// Display Name Formats (SyncConfig.NameFormat)
+const (
+	NameFormatFN          = "fn"           // vCard FN as written
+	NameFormatGivenFamily = "given_family" // "Jane Doe", from the structured N
+	NameFormatFamilyGiven = "family_given" // "Doe, Jane", from the structured N
+	FormatFamilyGiven     = "%s, %s"       // Family name, given name
+)
+
+// Contact Photo Modes
+const (
+	PhotoModeNone   = "none"
+	PhotoModeInline = "inline" // Embedded as base64 binary in the ATTACH property
+	PhotoModeLink   = "link"   // Served by the local HTTP server under RoutePhotos
+)
+
+// Web Source Authentication
+const (
+	AuthBasic       = "basic"  // HTTP Basic Auth with the username and password
+	AuthBearer      = "bearer" // Static bearer token (e.g., an app-specific token)
+	AuthOAuth2      = "oauth2" // Access tokens obtained with an OAuth2 refresh token
+	AuthLabelOAuth2 = "OAuth2" // Protocol names are not translated
+
+	OAuth2ParamGrantType    = "grant_type"
+	OAuth2ParamRefreshToken = "refresh_token"
+	OAuth2ParamClientID     = "client_id"
+	OAuth2ParamClientSecret = "client_secret"
+	OAuth2GrantRefresh      = "refresh_token"
+	OAuth2ExpiryDelta       = time.Minute // Access tokens are renewed this long before they expire
+	OAuth2MaxResponse       = 1 << 20     // Bytes read from the token endpoint
+)
+
+// Push Notification Backends
+const (
+	PushBackendNone   = "none"
+	PushBackendNtfy   = "ntfy"
+	PushBackendGotify = "gotify"
+	GotifyPriority    = 5 // Gotify "normal" priority, high enough to ring on Android
+	NtfyTagBirthday   = "birthday"
+	PushNameSeparator = ", "
+	PushLabelNtfy     = "ntfy" // Brand names are not translated
+	PushLabelGotify   = "Gotify"
 )
 
 // ISO8601 Duration Components for Reminders
@@ -230,6 +944,7 @@ const (
 	PropSummary     = "SUMMARY"
 	PropDTStart     = "DTSTART"
 	PropDTStamp     = "DTSTAMP"
+	PropDTEnd       = "DTEND"
 	PropRefresh     = "REFRESH-INTERVAL"
 	PropAction      = "ACTION"
 	PropDescription = "DESCRIPTION"
@@ -237,12 +952,84 @@ const (
 	PropVersion     = "VERSION"
 	PropProdid      = "PRODID"
 	PropXWRCalName  = "X-WR-CALNAME"
+	PropXWRCalDesc  = "X-WR-CALDESC"
+	PropXWRTimeZone = "X-WR-TIMEZONE"
+	PropName        = "NAME"
 	PropCalScale    = "CALSCALE"
 	PropMethod      = "METHOD"
+	PropAttach      = "ATTACH"
+	PropCategories  = "CATEGORIES"
+	PropColor       = "COLOR"
+	PropTransp      = "TRANSP"
+	PropStatus      = "STATUS"
+	PropClass       = "CLASS"
+	PropSequence    = "SEQUENCE"
+	PropLastMod     = "LAST-MODIFIED"
+	PropDue         = "DUE"
+	PropURL         = "URL"
+
+	// Birthdays never make anyone busy (RFC 5545 TRANSP, STATUS and CLASS values)
+	ICalTransparent   = "TRANSPARENT"
+	ICalConfirmed     = "CONFIRMED"
+	ICalClassPublic   = "PUBLIC"
+	ICalClassPrivate  = "PRIVATE"
+	ICalClassConf     = "CONFIDENTIAL"
+	DefaultEventClass = ICalClassPrivate
+
+	// To-dos served under RouteTodos, for task apps (RFC 5545 VTODO)
+	ICalNeedsAction = "NEEDS-ACTION"
+	FormatTodoUID   = "todo-%s-%d@%s" // Contact UID, year, ICalDomain
 
 	VCardBDAY = "BDAY"
 	VCardFN   = "FN"
 	VCardN    = "N"
+	VCardUID  = "UID"
+
+	VCardNickname = "NICKNAME" // Used instead of FN with SyncConfig.PreferNickname, first of a list
+
+	// Contact details copied into the events (SyncConfig.ContactDetails)
+	VCardEmail      = "EMAIL"
+	VCardTel        = "TEL"
+	VCardNote       = "NOTE"
+	URISchemeMailto = "mailto"
+	URISchemeTel    = "tel"
+	DetailSeparator = "\n" // Between the lines of the event DESCRIPTION
+
+	VCardVersion4 = "4.0" // Of the vCards converted from JMAP
+
+	// Apple (iCloud, macOS Contacts) exports unknown birth years as
+	// BDAY;X-APPLE-OMIT-YEAR=1604:1604-02-11
+	VCardParamAppleOmitYear = "X-APPLE-OMIT-YEAR"
+	AppleOmitYear           = 1604
+
+	// Apple custom dates: item1.X-ABDATE + item1.X-ABLabel:_$!<Anniversary>!$_
+	VCardXABDate       = "X-ABDATE"
+	VCardDeathDate     = "DEATHDATE" // RFC 6474
+	VCardXDeathDate    = "X-DEATHDATE"
+	VCardXABLabel      = "X-ABLABEL"
+	ABLabelPrefix      = "_$!<"
+	ABLabelSuffix      = ">!$_"
+	ABLabelAnniversary = "Anniversary"
+	ABLabelOther       = "Other"
+
+	// Name days: bundled tables (engine/namedays/<locale>.json) of "MM-DD" -> first names
+	NameDayDir        = "namedays"
+	NameDayDateFormat = "01-02"
+	NameDaySeparator  = "-" // Compound first names (Jean-Pierre) fall back to their first part
+
+	// Offset anniversaries: "6m" after every birthday, "100d" after the birth (once)
+	OffsetUnitMonths   = "m"
+	OffsetUnitDays     = "d"
+	MaxOffsetDays      = 36500
+	HalfBirthdayMonths = 6
+	OffsetsPlaceholder = "6m, 100d"
+
+	// vCard PHOTO encodings
+	VCardParamEncoding  = "ENCODING"
+	VCardEncodingB      = "b"
+	VCardEncodingBase64 = "base64"
+	DataURIPrefix       = "data:"
+	DataURIBase64       = ";base64"
 
 	DefaultICalRefresh = 1 * time.Hour
 )
@@ -270,27 +1057,400 @@ const (
 	FormatUID       = "%s-%d@%s"
 
 	// File Extensions
-	ExtVCF   = ".vcf"
-	ExtVCard = ".vcard"
+	ExtVCF    = ".vcf"
+	ExtVCard  = ".vcard"
+	ExtPEM    = ".pem"
+	ExtCRT    = ".crt"
+	ExtKey    = ".key"
+	ExtJPG    = ".jpg"
+	ExtCSV    = ".csv"
+	ExtICS    = ".ics"
+	ExtLDIF   = ".ldif"
+	ExtSQLite = ".sqlite"
+	ExtMab    = ".mab"
+	ExtJSON   = ".json"
+
+	// Photo URLs: base URL, contact UID
+	FormatPhotoURL = "%s" + RoutePhotos + "%s" + ExtJPG
+)
+
+// -----------------------------------------------------------------------------
+// Autostart Entries
+// -----------------------------------------------------------------------------
+
+const (
+	AutostartDirXDG     = "autostart" // Under $XDG_CONFIG_HOME (Linux, BSD)
+	AutostartFileXDG    = "go-birthday.desktop"
+	AutostartDirMac     = "Library/LaunchAgents" // Under the home directory
+	AutostartFileMac    = AppID + ".plist"
+	AutostartRegRunKey  = `Software\Microsoft\Windows\CurrentVersion\Run` // Under HKEY_CURRENT_USER
+	AutostartRegValue   = AppName
+	AutostartXDGDefault = ".config" // Fallback when XDG_CONFIG_HOME is unset
+	EnvXDGConfigHome    = "XDG_CONFIG_HOME"
+)
+
+// -----------------------------------------------------------------------------
+// systemd Service (--daemon, "go-birthday install-service")
+// -----------------------------------------------------------------------------
+
+const (
+	CmdInstallService   = "install-service"
+	FlagPrint           = "print"
+	FlagDescPrint       = "Print the unit file instead of installing it"
+	GOOSLinux           = "linux"
+	EnvNotifySocket     = "NOTIFY_SOCKET"
+	NetworkUnixgram     = "unixgram"
+	SdReady             = "READY=1"
+	SdReloading         = "RELOADING=1"
+	SdStopping          = "STOPPING=1"
+	SdStatus            = "STATUS=%s"
+	SystemdUserDir      = "systemd/user" // Under $XDG_CONFIG_HOME
+	SystemdUnitFile     = "go-birthday.service"
+	MsgServiceInstalled = "Installed %s\nStart it with: systemctl --user daemon-reload && systemctl --user enable --now %s\n"
+	MsgDaemonStart      = "Running as a daemon"
+	MsgDaemonReload     = "Reloading the configuration"
+	MsgDaemonStatus     = "%d birthday(s) today"
+	ErrServiceOS        = "systemd services are only available on Linux"
+	ErrSdNotify         = "failed to notify systemd"
+	CmdUninstallService = "uninstall-service"
+	MsgServiceRemoved   = "Removed %s\nIf it is still running, stop it with: systemctl --user stop %s\n"
+)
+
+// -----------------------------------------------------------------------------
+// Windows Service (--daemon started by the service control manager)
+// -----------------------------------------------------------------------------
+
+const (
+	WinServiceName        = "GoBirthday"
+	WinServiceDesc        = "Serves the birthday calendar of your contacts on http://127.0.0.1"
+	WinServiceRestart     = 10 * time.Second // Delay before restarting a crashed service
+	WinServiceResetPeriod = 24 * 60 * 60     // Seconds without failure before the restart count resets
+	WinServiceStopWait    = 30 * time.Second // Time given to a running service to stop when uninstalled
+	WinEventID            = 1
+	FlagUser              = "user"
+	FlagPassword          = "password"
+	FlagDescUser          = `Account running the service (e.g., ".\jane"), LocalSystem if empty`
+	FlagDescPassword      = "Password of the account running the service"
+	MsgWinServiceDone     = "Installed the %s service\nStart it with: sc.exe start %s\n"
+	MsgWinServiceRemoved  = "Removed the %s service\n"
+	ErrWinServiceOS       = "Windows services are only available on Windows"
 )
 
+// -----------------------------------------------------------------------------
+// Container (--container, implied by builds with the "headless" tag)
+// -----------------------------------------------------------------------------
+
+const (
+	FlagContainer     = "container"
+	FlagDescContainer = "Run as a daemon configured by the environment, logging to stdout only, e.g., in Docker"
+	EnvPrefPrefix     = "GO_BIRTHDAY_"       // Followed by the preference in upper case, e.g., GO_BIRTHDAY_SERVER_PORT
+	EnvPrefsFile      = "GO_BIRTHDAY_CONFIG" // JSON preferences, in the format saved by the desktop application
+	ContainerBindAddr = "0.0.0.0"            // Reachable through the published port
+	MsgContainerStart = "Running in a container"
+	ErrPrefsFile      = "invalid preferences file"
+	ErrPrefsSave      = "failed to save the preferences"
+	FynePrefsDir      = "fyne"             // Under the OS user config directory, as the desktop application
+	FynePrefsFile     = "preferences.json" // Under FynePrefsDir and the application ID
+)
+
+// -----------------------------------------------------------------------------
+// Publishing (CalDAV)
+// -----------------------------------------------------------------------------
+
+const (
+	MethodPropfind   = "PROPFIND"
+	MethodReport     = "REPORT"
+	PropfindETag     = `<?xml version="1.0" encoding="utf-8"?><d:propfind xmlns:d="DAV:"><d:prop><d:getetag/></d:prop></d:propfind>`
+	CalDAVMaxListing = 64 << 20 // Bytes read from a PROPFIND answer
+)
+
+// -----------------------------------------------------------------------------
+// CardDAV Discovery (RFC 6764, RFC 6352)
+// -----------------------------------------------------------------------------
+
+const (
+	WellKnownCardDAV        = "/.well-known/carddav"
+	NextcloudDAVPath        = "remote.php/dav" // DAV root, relative to the Nextcloud base URL
+	NextcloudExportQuery    = "export"         // Query downloading a whole Nextcloud address book as vCards
+	NextcloudDefaultBook    = "contacts"       // Last path segment of the address book created with each account
+	FormatAddressBook       = "%s (%d)"        // Name and contacts of a discovered address book
+	PropfindPrincipal       = `<?xml version="1.0" encoding="utf-8"?><d:propfind xmlns:d="DAV:"><d:prop><d:current-user-principal/></d:prop></d:propfind>`
+	PropfindAddressBookHome = `<?xml version="1.0" encoding="utf-8"?><d:propfind xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav"><d:prop><card:addressbook-home-set/></d:prop></d:propfind>`
+	PropfindAddressBooks    = `<?xml version="1.0" encoding="utf-8"?><d:propfind xmlns:d="DAV:"><d:prop><d:resourcetype/><d:displayname/></d:prop></d:propfind>`
+	PropfindCards           = `<?xml version="1.0" encoding="utf-8"?><d:propfind xmlns:d="DAV:"><d:prop><d:getetag/></d:prop></d:propfind>`
+	DiscoveryMaxRedirects   = 5
+	DiscoveryMaxResponse    = 4 << 20 // Bytes read from a PROPFIND answer
+)
+
+// -----------------------------------------------------------------------------
+// JMAP Contacts Source (RFC 8620, RFC 9610, JSContact RFC 9553)
+// -----------------------------------------------------------------------------
+
+const (
+	WellKnownJMAP      = "/.well-known/jmap"
+	JMAPCapCore        = "urn:ietf:params:jmap:core"
+	JMAPCapContacts    = "urn:ietf:params:jmap:contacts"
+	JMAPMethodQuery    = "ContactCard/query"
+	JMAPMethodGet      = "ContactCard/get"
+	JMAPMethodError    = "error"
+	JMAPPageSize       = 500      // Cards per request, below the usual maxObjectsInGet
+	JMAPMaxResponse    = 64 << 20 // Bytes read from a JMAP answer
+	JSContactKindGroup = "group"
+	JSContactBirth     = "birth"
+	JSContactGiven     = "given"
+	JSContactSurname   = "surname"
+	JSContactTimestamp = "Timestamp"
+)
+
+// -----------------------------------------------------------------------------
+// Sync Hooks (shell commands run after each synchronization)
+// -----------------------------------------------------------------------------
+
+const (
+	HookShellUnix    = "/bin/sh"
+	HookShellFlag    = "-c"
+	HookShellWindows = "cmd.exe"
+	HookShellWinFlag = "/C"
+	HookTimeout      = 2 * time.Minute
+	HookMaxOutput    = 512             // Bytes of output kept in the log when a hook fails
+	HookWaitDelay    = 5 * time.Second // Output still read after the hook exited, if a background child keeps it open
+
+	// Environment of the hooks
+	HookEnvEvent     = "GO_BIRTHDAY_EVENT" // HookEventSuccess or HookEventFailure
+	HookEnvICS       = "GO_BIRTHDAY_ICS"   // Path of the generated calendar (a copy in the cache directory)
+	HookEnvURL       = "GO_BIRTHDAY_URL"   // Calendar URL of the local server
+	HookEnvContacts  = "GO_BIRTHDAY_CONTACTS"
+	HookEnvToday     = "GO_BIRTHDAY_TODAY"
+	HookEnvChanged   = "GO_BIRTHDAY_CHANGED" // "0" when the source had not changed
+	HookEnvError     = "GO_BIRTHDAY_ERROR"
+	HookEventSuccess = "success"
+	HookEventFailure = "failure"
+)
+
+// -----------------------------------------------------------------------------
+// System Address Book (macOS Contacts.app)
+// -----------------------------------------------------------------------------
+
+const (
+	OsascriptBin = "osascript"
+	// ScriptExportContacts prints the vCards of every person, joined by the empty delimiter.
+	ScriptExportContacts = `set AppleScript's text item delimiters to ""
+tell application "Contacts" to set cards to vcard of every person
+return cards as text`
+)
+
+// -----------------------------------------------------------------------------
+// Outlook Contacts (Windows, through the COM interface of Outlook)
+// -----------------------------------------------------------------------------
+
+const (
+	PowerShellBin     = "powershell.exe"
+	WinCreateNoWindow = 0x08000000   // CREATE_NO_WINDOW process creation flag
+	OutlookNoDate     = "4501-01-01" // Date Outlook uses for "None"
+	UTF8BOM           = "\ufeff"
+	// ScriptOutlookContacts prints the contacts of the default contacts folder (olFolderContacts, 10)
+	// and of its sub-folders as a JSON array. Distribution lists (other item classes than olContact, 40)
+	// are skipped.
+	ScriptOutlookContacts = `$ErrorActionPreference = 'Stop'
+[Console]::OutputEncoding = [Text.Encoding]::UTF8
+$outlook = New-Object -ComObject Outlook.Application
+function Read-Folder($folder) {
+	foreach ($item in $folder.Items) {
+		if ($item.Class -eq 40) {
+			[pscustomobject]@{
+				id = $item.EntryID; name = $item.FullName; first = $item.FirstName; last = $item.LastName
+				birthday = $item.Birthday.ToString('yyyy-MM-dd'); categories = $item.Categories
+			}
+		}
+	}
+	foreach ($sub in $folder.Folders) { Read-Folder $sub }
+}
+ConvertTo-Json -Compress -InputObject @(Read-Folder $outlook.GetNamespace('MAPI').GetDefaultFolder(10))`
+)
+
+// OutlookUnavailableCodes are the COM errors of an Outlook missing (class not registered,
+// 80040154), failing to start (server execution failed, 80080005) or whose programmatic access
+// was refused by the user or an administrator (operation aborted, 80004004).
+var OutlookUnavailableCodes = []string{"80040154", "80080005", "80004004"}
+
+// SystemDeniedCodes are the AppleScript errors of a refused access: not authorized to send
+// Apple events (-1743), or privacy violation (-10004).
+var SystemDeniedCodes = []string{"-1743", "-10004"}
+
+// -----------------------------------------------------------------------------
+// Thunderbird Address Books (abook.sqlite, LDIF exports)
+// -----------------------------------------------------------------------------
+
+const (
+	ThunderbirdProfilesINI  = "profiles.ini"
+	ThunderbirdPersonalBook = "abook.sqlite"
+	ThunderbirdBookGlob     = "abook*.sqlite" // Personal book and the ones added since (abook-1.sqlite...)
+	ThunderbirdTable        = "properties"    // (card, name, value)
+
+	ThunderbirdPropVCard       = "_vCard" // Whole vCard, since Thunderbird 102
+	ThunderbirdPropDisplayName = "DisplayName"
+	ThunderbirdPropFirstName   = "FirstName"
+	ThunderbirdPropLastName    = "LastName"
+	ThunderbirdPropBirthYear   = "BirthYear"
+	ThunderbirdPropBirthMonth  = "BirthMonth"
+	ThunderbirdPropBirthDay    = "BirthDay"
+
+	INISectionInstall = "Install"
+	INISectionProfile = "Profile"
+	INIKeyDefault     = "Default"
+	INIKeyPath        = "Path"
+	INIKeyIsRelative  = "IsRelative"
+
+	// LDIF attributes of a Thunderbird export, lowercase
+	LDIFDN          = "dn"
+	LDIFObjectClass = "objectclass"
+	LDIFClassList   = "groupofnames"
+	LDIFCN          = "cn"
+	LDIFGivenName   = "givenname"
+	LDIFSN          = "sn"
+	LDIFBirthYear   = "birthyear"
+	LDIFBirthMonth  = "birthmonth"
+	LDIFBirthDay    = "birthday"
+
+	// SQLite file format (https://www.sqlite.org/fileformat.html)
+	SQLiteMagic          = "SQLite format 3\x00"
+	SQLiteHeaderSize     = 100
+	SQLiteWALSuffix      = "-wal"
+	SQLiteWALMagic       = 0x377f0682
+	SQLiteWALHeaderSize  = 32
+	SQLiteWALFrameHeader = 24
+	SQLiteMaxDepth       = 20       // B-tree levels, far above what real databases reach
+	SQLiteMaxRecord      = 64 << 20 // Bytes of a single row
+)
+
+// ThunderbirdDirs are the data directories of Thunderbird, relative to the home directory:
+// Linux, Snap, Flatpak (old and new IDs), macOS and Windows.
+var ThunderbirdDirs = []string{
+	".thunderbird",
+	"snap/thunderbird/common/.thunderbird",
+	".var/app/org.mozilla.Thunderbird/.thunderbird",
+	".var/app/net.thunderbird.Thunderbird/.thunderbird",
+	"Library/Thunderbird",
+	"AppData/Roaming/Thunderbird",
+}
+
+// JMAPCardProperties are the JSContact properties requested: those the calendar needs.
+var JMAPCardProperties = []string{"id", "uid", "kind", "name", "anniversaries", "keywords"}
+
+// -----------------------------------------------------------------------------
+// Secret Storage (OS keyring, or an encrypted file without one)
+// -----------------------------------------------------------------------------
+
+const (
+	EnvSecretStore      = "GO_BIRTHDAY_SECRET_STORE"      // SecretStoreKeyring, SecretStoreFile or SecretStoreEnv, automatic when unset
+	EnvSecretPassphrase = "GO_BIRTHDAY_SECRET_PASSPHRASE" // Protects the file instead of the machine key
+	EnvSecretPrefix     = "GO_BIRTHDAY_SECRET_"           // Followed by the account, with SecretStoreEnv
+	EnvFileSuffix       = "_FILE"                         // Variable naming a file that holds the value (Docker secrets)
+	SecretStoreKeyring  = "keyring"
+	SecretStoreFile     = "file"
+	SecretStoreEnv      = "env" // Read-only environment, for containers
+
+	SecretsFileVersion   = 1
+	SecretsSaltSize      = 16
+	SecretsKeySize       = 32     // AES-256
+	SecretsKDFIterations = 600000 // PBKDF2-HMAC-SHA256, as recommended by OWASP
+)
+
+// MachineIDFiles hold the machine ID used by the machine key, in order of preference (Linux, BSD).
+var MachineIDFiles = []string{"/etc/machine-id", "/var/lib/dbus/machine-id", "/var/db/dbus/machine-id"}
+
+// -----------------------------------------------------------------------------
+// Telemetry (OpenTelemetry over OTLP/HTTP, JSON encoding)
+// -----------------------------------------------------------------------------
+
+const (
+	// Standard OpenTelemetry environment variables. Telemetry is off unless the endpoint is set.
+	EnvOTLPEndpoint = "OTEL_EXPORTER_OTLP_ENDPOINT" // e.g., http://localhost:4318
+	EnvOTLPHeaders  = "OTEL_EXPORTER_OTLP_HEADERS"  // e.g., "authorization=Bearer xyz,x-tenant=home"
+	EnvOTelService  = "OTEL_SERVICE_NAME"
+
+	OTLPTracesPath          = "/v1/traces"
+	OTLPMetricsPath         = "/v1/metrics"
+	TelemetryExportInterval = 15 * time.Second
+	TelemetryMaxSpans       = 2048 // Spans queued between exports, extra spans are dropped
+	TraceParentVersion      = "00"
+	TraceParentSampled      = "01"
+
+	// Span names
+	SpanSync  = "sync"
+	SpanFetch = "fetch"
+	SpanHTTP  = "http.request"
+
+	// Metric names and units
+	MetricSyncDuration  = "gobirthday.sync.duration"
+	MetricSyncCount     = "gobirthday.sync.count"
+	MetricFetchDuration = "gobirthday.fetch.duration"
+	MetricHTTPDuration  = "gobirthday.http.server.duration"
+	MetricHTTPCount     = "gobirthday.http.server.requests"
+	UnitMilliseconds    = "ms"
+	UnitRequests        = "{request}"
+	UnitSyncs           = "{sync}"
+
+	// Attribute keys (OpenTelemetry semantic conventions where they exist)
+	AttrServiceName     = "service.name"
+	AttrServiceVersion  = "service.version"
+	AttrHTTPMethod      = "http.request.method"
+	AttrHTTPStatus      = "http.response.status_code"
+	AttrHTTPRoute       = "http.route"
+	AttrURL             = "url.full"
+	AttrSyncMode        = "sync.mode"
+	AttrSyncResult      = "sync.result"
+	AttrBirthdays       = "sync.birthdays"
+	SyncResultOK        = "ok"
+	SyncResultError     = "error"
+	SyncResultUnchanged = "not_modified"
+)
+
+// TelemetryBuckets are the histogram bucket bounds, in milliseconds.
+var TelemetryBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000}
+
 // -----------------------------------------------------------------------------
 // Network & Timeouts
 // -----------------------------------------------------------------------------
 
 const (
 	HTTPTimeout         = 30 * time.Second
+	SourceWorkers       = 8                // Files of a vCard directory read in parallel
+	FetchRetryAttempts  = 3                // Total attempts of a vCard download, including the first one
+	FetchRetryBaseDelay = 2 * time.Second  // Doubled after each failure, with jitter
+	FetchRetryMaxDelay  = 30 * time.Second // Longer Retry-After requests are left to the next sync
+	WatchDebounce       = 2 * time.Second  // Delay after the last file change before resyncing
+	ProgressCards       = 500              // vCards read between two progress reports of a synchronization
+	OfflineRetryBase    = 30 * time.Second // First retry of a synchronization failed offline, doubled after each failure
+	OfflineRetryMax     = 5 * time.Minute  // Never later than the refresh interval either
+	RetryAfterMax       = 24 * time.Hour   // Longest Retry-After of a server honored by the scheduler
+	SyncInfoRefresh     = time.Minute      // Update of the next synchronization countdown in the tray
 	ShutdownTimeout     = 5 * time.Second
 	ServerReadTimeout   = 10 * time.Second
 	ServerWriteTimeout  = 30 * time.Second
 	ServerIdleTimeout   = 60 * time.Second
 	RetryAfterSeconds   = "10"
 	AllowedMethods      = "GET, HEAD"
-	MaxHTTPResponseSize = 256 * 1024 * 1024 // 256MB
+	CalendarMemoryLimit = 4 * 1024 * 1024 // 4MB, larger calendars are kept in a temporary file
 	SchemeHTTP          = "http"
 	SchemeHTTPS         = "https"
+	SchemeFile          = "file"
+	SchemeWebcal        = "webcal" // Opens the subscription dialog of calendar apps
 	RouteRoot           = "/"
 	AddrSeparator       = ":"
+	RouteGotifyMessage  = "/message"
+	RoutePhotos         = "/photos/"
+	RouteHomeAssistant  = "/api/homeassistant"
+	HomeAssistantDays   = 7 // Days listed as upcoming by the Home Assistant endpoint, after today
+	RouteTodos          = "/todos.ics"
+	RouteCalendarFile   = "/" + ExportCalFileName    // Alias of RouteRoot naming the file
+	DefaultTodoDays     = 14                         // Days ahead served as to-dos, after today
+	RouteCalDAV         = "/caldav/"                 // Principal and calendar home of the read-only CalDAV access
+	RouteCalDAVCalendar = RouteCalDAV + "birthdays/" // Calendar collection, one resource per event
+	RouteWellKnownDAV   = "/.well-known/caldav"      // Redirected to RouteCalDAV (RFC 6764)
+	AllowedMethodsDAV   = "OPTIONS, GET, HEAD, PROPFIND, REPORT"
+	CalDAVMaxReport     = 1 << 20 // Bytes read from a REPORT request
+	FormatBaseURL       = SchemeHTTP + "://" + LocalhostBindAddr + AddrSeparator + "%s"
 )
 
 // -----------------------------------------------------------------------------
@@ -299,19 +1459,65 @@ const (
 
 const (
 	HeaderContentType     = "Content-Type"
+	HeaderContentLength   = "Content-Length"
 	HeaderCacheControl    = "Cache-Control"
+	HeaderAcceptRanges    = "Accept-Ranges"
+	HeaderContentDisp     = "Content-Disposition"
 	HeaderETag            = "ETag"
 	HeaderLastModified    = "Last-Modified"
 	HeaderRetryAfter      = "Retry-After"
 	HeaderAllow           = "Allow"
 	HeaderXContentType    = "X-Content-Type-Options"
 	HeaderUserAgent       = "User-Agent"
+	HeaderTraceParent     = "traceparent" // W3C Trace Context
 	HeaderIfNoneMatch     = "If-None-Match"
 	HeaderIfModifiedSince = "If-Modified-Since"
-
-	MimeTextCalendar    = "text/calendar; charset=utf-8"
-	MimeNoSniff         = "nosniff"
-	CacheControlPrivate = "private, no-cache"
+	HeaderAuthorization   = "Authorization"
+	HeaderIfMatch         = "If-Match"
+	HeaderDepth           = "Depth" // WebDAV
+	HeaderDAV             = "DAV"   // WebDAV compliance classes
+	HeaderLocation        = "Location"
+	HeaderAccept          = "Accept"
+	HeaderNtfyTitle       = "Title"
+	HeaderNtfyTags        = "Tags"
+	HeaderGotifyKey       = "X-Gotify-Key"
+	HeaderOrigin          = "Origin"
+	HeaderVary            = "Vary"
+	HeaderACAllowOrigin   = "Access-Control-Allow-Origin"
+	HeaderACAllowMethods  = "Access-Control-Allow-Methods"
+	HeaderACAllowHeaders  = "Access-Control-Allow-Headers"
+	HeaderACExposeHeaders = "Access-Control-Expose-Headers"
+	HeaderACMaxAge        = "Access-Control-Max-Age"
+	HeaderACRequestMethod = "Access-Control-Request-Method" // Only sent by the preflight requests
+
+	MimeTextCalendar      = "text/calendar; charset=utf-8"
+	MimeNoSniff           = "nosniff"
+	CacheControlPrivate   = "private, no-cache"
+	AcceptRangesNone      = "none" // Responses are always whole
+	CORSAnyOrigin         = "*"
+	CORSAllowedHeaders    = "If-None-Match, If-Modified-Since, Range"                 // Conditional and partial requests
+	CORSAllowedHeadersDAV = CORSAllowedHeaders + ", Depth, Content-Type"              // Plus the PROPFIND and REPORT ones
+	CORSExposedHeaders    = "ETag, Last-Modified, Content-Disposition, Content-Range" // Readable by the scripts
+	CORSMaxAge            = "86400"                                                   // Seconds a preflight answer is cached
+	FormatDisposition     = `inline; filename="%s"`
+	MimeTextPlain         = "text/plain; charset=utf-8"
+	MimeTextHTML          = "text/html; charset=utf-8"
+	MimeHTMLType          = "text/html" // Accepted by browsers, not by calendar clients
+	MimeJSON              = "application/json"
+	MimeForm              = "application/x-www-form-urlencoded"
+	MimeXML               = "application/xml; charset=utf-8"
+	MimeImageJPEG         = "image/jpeg"
+	MimeImagePrefix       = "image/"
+	AuthBearerPrefix      = "Bearer "
+	DAVCompliance         = "1, calendar-access"
+	DAVDepthZero          = "0" // Depth of a PROPFIND on the resource alone; any other depth lists the members too
+	DAVStatusOK           = "HTTP/1.1 200 OK"
+	NSDAV                 = "DAV:"
+	NSCalDAV              = "urn:ietf:params:xml:ns:caldav"
+	NSCalendarServer      = "http://calendarserver.org/ns/" // getctag, read by most clients to skip unchanged collections
+	DAVCalendarQuery      = "calendar-query"
+	DAVCalendarMultiget   = "calendar-multiget"
+	DAVTimeRangeFormat    = "20060102T150405Z" // Bounds of a calendar-query time-range, in UTC (RFC 4791, section 9.9)
 
 	// FormatETag expects a string argument.
 	FormatETag = `"%s"`
@@ -322,30 +1528,120 @@ const (
 // -----------------------------------------------------------------------------
 
 const (
-	ErrLocalPathEmpty   = "configuration error: local path is empty"
-	ErrWebURLEmpty      = "configuration error: web URL is empty"
-	ErrFetcherMissing   = "internal error: network fetcher is not initialized"
-	ErrModeUnsupport    = "configuration error: unsupported source mode"
-	ErrServerStartup    = "server startup failed"
-	ErrServerShutdown   = "server shutdown failed"
-	ErrPortRequired     = "server port is required"
-	ErrPortNumber       = "server port must be a number"
-	ErrPortRange        = "server port must be between 1 and 65535"
-	ErrInvalidURL       = "invalid URL structure"
-	ErrProtocol         = "unsupported protocol scheme (http/https only)"
-	ErrCtxCancelled     = "operation cancelled by context"
-	ErrVCardParse       = "failed to parse vCard stream"
-	ErrICalEncode       = "failed to encode iCalendar data"
-	ErrDateParse        = "unable to parse date"
-	ErrLogFile          = "failed to open log file"
-	ErrCacheDir         = "could not determine user cache dir"
-	ErrCreateDir        = "could not create app cache dir"
-	ErrAppFailed        = "application failed unexpectedly"
-	ErrWriteResp        = "failed to write response body"
-	ErrLocalesAccess    = "failed to access embedded locales"
-	ErrLocaleLoad       = "failed to load locale file"
-	ErrTrayNotSupported = "system tray not supported on this platform/driver"
-	ErrLocNotInit       = "localizer not initialized"
+	ErrLocalPathEmpty       = "configuration error: local path is empty"
+	ErrWatcher              = "filesystem watcher error"
+	ErrWebURLEmpty          = "configuration error: web URL is empty"
+	ErrFetcherMissing       = "internal error: network fetcher is not initialized"
+	ErrModeUnsupport        = "configuration error: unsupported source mode"
+	ErrServerStartup        = "server startup failed"
+	ErrServerShutdown       = "server shutdown failed"
+	ErrPortRequired         = "server port is required"
+	ErrServerRunning        = "server already running"
+	ErrPortNumber           = "server port must be a number"
+	ErrPortRange            = "server port must be between 1 and 65535"
+	ErrNetwork              = "invalid network of the allowlist"
+	ErrInvalidURL           = "invalid URL structure"
+	ErrProtocol             = "unsupported protocol scheme (http/https only)"
+	ErrProxyURL             = "invalid proxy URL"
+	ErrCAFile               = "no valid PEM certificate in CA file"
+	ErrClientCert           = "failed to load client certificate"
+	ErrOAuth2Missing        = "configuration error: OAuth2 is not configured"
+	ErrOAuth2Refresh        = "failed to refresh the OAuth2 access token"
+	ErrOAuth2NoToken        = "no refresh token"
+	ErrCalDAVURL            = "invalid CalDAV calendar URL"
+	ErrCalDAVStatus         = "CalDAV server returned unexpected status"
+	ErrCalDAVListing        = "failed to read CalDAV calendar listing"
+	ErrCalDAVEncode         = "failed to split calendar into CalDAV events"
+	ErrOpenURL              = "failed to open URL with the system handler"
+	ErrNotifyClickable      = "clickable notification unavailable, sent as a plain one"
+	ErrCalDAVConflict       = "CalDAV event modified on the server, left untouched until the next synchronization"
+	ErrWebDAVURL            = "invalid WebDAV URL"
+	ErrWebDAVStatus         = "WebDAV server returned unexpected status"
+	ErrWebDAVConflict       = "calendar file modified on the WebDAV server, left untouched until the next synchronization"
+	ErrDiscovery            = "CardDAV discovery failed"
+	ErrDiscoveryProp        = "unexpected CardDAV discovery answer"
+	ErrDiscoveryRedirects   = "too many redirects"
+	ErrDiscoveryCount       = "failed to count the vCards of the address book"
+	ErrNoAddressBook        = "no address book found"
+	ErrNoDiscovery          = "the fetcher does not support CardDAV discovery"
+	ErrConnTest             = "Source connection test failed"
+	ErrJMAPResponse         = "unexpected JMAP response"
+	ErrJMAPMethod           = "JMAP method failed"
+	ErrJMAPNoContacts       = "the JMAP account has no contacts"
+	ErrNoJMAP               = "the fetcher does not support JMAP"
+	ErrSystemDenied         = "access to Contacts denied (System Settings > Privacy & Security > Automation)"
+	ErrSystemExport         = "failed to export the system contacts"
+	ErrSystemUnsupported    = "configuration error: system contacts are only available on macOS"
+	ErrOutlookUnavailable   = "Outlook is not installed or refused the access to its contacts"
+	ErrOutlookExport        = "failed to export the Outlook contacts"
+	ErrOutlookUnsupported   = "configuration error: Outlook contacts are only available on Windows"
+	ErrExecEmpty            = "configuration error: no program set for the program source"
+	ErrExecSource           = "failed to list the contacts of the program source"
+	ErrSourceRegister       = "engine: invalid or duplicate source registration"
+	ErrThunderbirdRead      = "failed to read Thunderbird address book"
+	ErrNameDayLocale        = "no name-day table for this language"
+	ErrOffsetFormat         = "invalid anniversary offset (e.g., 6m or 100d)"
+	ErrThunderbirdNoBook    = "no Thunderbird address book (abook.sqlite) in the directory"
+	ErrThunderbirdNoProfile = "no Thunderbird profile with an address book found"
+	ErrThunderbirdMab       = "Thunderbird address books in the Mork format (.mab) are not supported: open them once in Thunderbird 78 or later, or export them as LDIF"
+	ErrSQLiteFormat         = "not an SQLite database"
+	ErrSQLiteEncoding       = "unsupported SQLite text encoding (UTF-16)"
+	ErrSQLiteCorrupt        = "malformed SQLite database"
+	ErrSQLiteNoTable        = "missing SQLite table"
+	ErrPublish              = "failed to publish calendar"
+	ErrOutputFile           = "failed to write calendar file"
+	ErrHook                 = "sync hook failed"
+	ErrSecretsRead          = "failed to read secrets file"
+	ErrSecretsWrite         = "failed to write secrets file"
+	ErrSecretsDecrypt       = "failed to decrypt secrets file (wrong passphrase or machine)"
+	ErrBackupPrefs          = "preferences cannot be listed"
+	ErrBackupFile           = "not a settings file of " + AppName
+	ErrBackupVersion        = "unsupported settings file version %d"
+	ErrNetworkFetch         = "network error during fetch"
+	ErrStatusFetch          = "server returned unexpected status"
+	ErrNotModified          = "address book not modified"
+	ErrCtxCancelled         = "operation cancelled by context"
+	ErrVCardParse           = "failed to parse vCard stream"
+	ErrLimitExceeded        = "synchronization limit exceeded (see the settings)"
+	ErrSourceTooLarge       = "address book larger than %d bytes"
+	ErrTooManyContacts      = "more than %d contacts"
+	ErrICalEncode           = "failed to encode iCalendar data"
+	ErrICSValidate          = "failed to validate the generated calendar"
+	ErrTimeZone             = "unknown time zone"
+	ErrEventTime            = "invalid event time, expected HH:MM"
+	ErrICSLineBreak         = "line not ended by CRLF"
+	ErrICSLineLength        = "line longer than 75 octets"
+	ErrICSUTF8              = "invalid UTF-8 (folded inside a character?)"
+	ErrICSSyntax            = "malformed content line"
+	ErrICSNesting           = "unbalanced BEGIN/END"
+	ErrICSMissingProp       = "missing mandatory property"
+	ErrICSDuplicateProp     = "property allowed only once"
+	ErrICSUnescaped         = "unescaped comma or semicolon in text"
+	ErrICSBadEscape         = "invalid escape sequence in text"
+	ErrCalendarSpool        = "failed to write calendar to temporary file"
+	ErrNoCalendar           = "no calendar generated yet"
+	ErrDateParse            = "unable to parse date"
+	ErrLogFile              = "failed to open log file"
+	ErrLogRead              = "failed to read log file"
+	ErrTelemetryExport      = "telemetry export failed"
+	ErrCacheWrite           = "failed to write cache file"
+	ErrCacheRead            = "failed to read cache file"
+	ErrCacheDir             = "could not determine user cache dir"
+	ErrCreateDir            = "could not create app cache dir"
+	ErrAppFailed            = "application failed unexpectedly"
+	ErrWriteResp            = "failed to write response body"
+	ErrLocalesAccess        = "failed to access embedded locales"
+	ErrLocaleLoad           = "failed to load locale file"
+	ErrTrayNotSupported     = "system tray not supported on this platform/driver"
+	ErrLocNotInit           = "localizer not initialized"
+	ErrPushBackend          = "configuration error: unsupported push backend"
+	ErrPushServerEmpty      = "configuration error: push server URL is empty"
+	ErrPushTopicEmpty       = "configuration error: ntfy topic is empty"
+	ErrPushTokenEmpty       = "configuration error: Gotify token is empty"
+	ErrPushFailed           = "push notification failed"
+	ErrPushStatus           = "push server returned unexpected status"
+	ErrSummaryTemplate      = "invalid summary template, using default"
+	ErrAutostart            = "failed to update the autostart entry"
 )
 
 // -----------------------------------------------------------------------------
@@ -356,6 +1652,10 @@ const (
 	HTTPMsgInitializing = "Calendar initializing, please try again shortly."
 	HTTPMsgMethodNotAll = "Method Not Allowed"
 	HTTPMsgInternalErr  = "Internal Server Error"
+	HTTPMsgNotFound     = "Not Found"
+	HTTPMsgForbidden    = "Forbidden"
+	HTTPMsgBadRequest   = "Bad Request"
+	HTTPMsgNotImpl      = "Not Implemented"
 )
 
 // -----------------------------------------------------------------------------
@@ -363,47 +1663,167 @@ const (
 // -----------------------------------------------------------------------------
 
 const (
-	FallbackSummary      = "Birthday: %s"
-	FallbackSummaryAge   = "Birthday: %s (%d)"
-	FallbackSummaryBirth = "Birthday: %s (birth)" // Lowercase fallback too
-	FallbackTrayError    = "Go Birthday: Sync Error"
-	FallbackTrayDefault  = "Go Birthday (%d today)"
-	FallbackTrayLabel    = "Go Birthday"
-	FallbackName         = "Unknown"
+	FallbackSummary          = "Birthday: %s"
+	FallbackSummaryAge       = "Birthday: %s (%d)"
+	FallbackSummaryBirth     = "Birthday: %s (birth)" // Lowercase fallback too
+	FallbackDateSummary      = "%s: %s"               // Label, Name
+	FallbackDateSummaryYears = "%s: %s (%d)"          // Label, Name, Years
+	FallbackMemorial         = "In memory of %s"
+	FallbackMemorialYears    = "In memory of %s (%d)" // Name, Years since the death
+	FallbackNameDay          = "Name day: %s"
+	FallbackHalfBirthday     = "Half birthday: %s"
+	FallbackOffsetMonths     = "%s: birthday + %d months"
+	FallbackOffsetDays       = "%s: %d days old"
+	FallbackTrayError        = "Go Birthday: Sync Error"
+	FallbackTrayDefault      = "Go Birthday (%d today)"
+	FallbackTrayLabel        = "Go Birthday"
+	FallbackName             = "Unknown"
+	FallbackPushTitle        = "Birthdays today (%d)"
 
 	// StubVCalendar is the minimal valid iCalendar object used when no events are found.
 	// Using a constant avoids hardcoded magic strings in the engine logic.
 	StubVCalendar = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:" + ICalProdid + "\r\nEND:VCALENDAR\r\n"
 
+	// Lines delimiting the events in a calendar streamed by the engine.
+	ICalLineBreak   = "\r\n"
+	ICalEventBegin  = ICalLineBreak + "BEGIN:VEVENT" + ICalLineBreak
+	ICalCalendarEnd = "END:VCALENDAR" + ICalLineBreak
+
+	// RFC 5545, section 3.1: content lines are folded after 75 octets, continuation lines start with a space.
+	ICalMaxLineOctets = 75
+	ICalFoldPrefix    = " "
+	ICalBegin         = "BEGIN"
+	ICalFloatingTime  = "20060102T150405" // DATE-TIME without zone (RFC 5545, section 3.3.5)
+	EventTimeFormat   = "15:04"           // Start of timed events
+	QuietTimeLayout   = "15:04"           // Bounds of the quiet hours
+	DefaultEventMins  = 30
+	ICalEnd           = "END"
+	ICSMaxIssuesLog   = 20 // Validation problems logged per calendar, the others are only counted
+
 	TitleStartupError = "Startup Error"
 	TitleSyncError    = "Sync Error"
 
-	MsgPortBusy      = "Port %s is busy or unavailable."
-	MsgSyncSuccess   = "Synchronization completed successfully."
-	MsgSyncStarted   = "Synchronization started..."
-	MsgSyncFailed    = "Synchronization failed. Check logs."
-	MsgSyncReq       = "Sync requested"
-	MsgWorkerStart   = "Background worker started"
-	MsgWorkerStop    = "Worker stopping due to context cancellation"
-	MsgUpdateSync    = "Updating sync interval"
-	MsgAppStop       = "Application stopped gracefully"
-	MsgCtxCancel     = "Context cancelled, shutting down UI"
-	MsgSkippedCard   = "Skipping malformed vCard"
-	MsgSkippedDate   = "Skipping invalid date format"
-	MsgGenSuccess    = "Calendar generation successful"
-	MsgAppStarting   = "Starting application"
-	MsgServerListen  = "HTTP server listening"
-	MsgServerStop    = "Shutting down HTTP server..."
-	MsgCacheUpdated  = "Calendar cache updated"
-	MsgLocaleSkip    = "Skipping non-locale file"
-	MsgLocaleBadName = "Skipping malformed locale filename"
-	MsgLocaleLoaded  = "Locale loaded successfully"
-	MsgTransMissing  = "Missing translation key"
-	MsgPassFail      = "Password retrieval failed (might be empty)"
-	MsgLogWarning    = "Warning: %s at %s: %v\n"
-	MsgBdayToday     = "Birthday found today"
-
-	PlaceholderURL = "https://..."
+	MsgPortBusy        = "Port %s is busy or unavailable."
+	MsgSyncSuccess     = "Synchronization completed successfully."
+	MsgSyncStarted     = "Synchronization started..."
+	MsgSyncFailed      = "Synchronization failed. Check logs."
+	MsgSyncReq         = "Sync requested"
+	MsgSyncUnchanged   = "Address book unchanged, keeping the current calendar"
+	MsgSyncCanceled    = "Synchronization canceled, keeping the current calendar"
+	MsgServeStale      = "Serving the cached calendar of a previous run until a synchronization succeeds"
+	MsgHTTPAccess      = "HTTP request"
+	MsgCacheLoaded     = "Cached calendar loaded"
+	MsgContactsLoaded  = "Cached contacts loaded"
+	MsgWorkerStart     = "Background worker started"
+	MsgWorkerStop      = "Worker stopping due to context cancellation"
+	MsgUpdateSync      = "Updating sync interval"
+	MsgAppStop         = "Application stopped gracefully"
+	MsgCtxCancel       = "Context cancelled, shutting down UI"
+	MsgLogLevel        = "Log level changed"
+	MsgTelemetryOn     = "OpenTelemetry export enabled"
+	MsgFetchRetry      = "vCard download failed, retrying"
+	MsgTLSInsecure     = "TLS certificate verification disabled for the CardDAV server"
+	MsgOAuth2Refreshed = "OAuth2 access token refreshed"
+	MsgCalDAVPublished = "Calendar published to CalDAV"
+	MsgWebDAVPublished = "Calendar uploaded to WebDAV"
+	MsgDiscovered      = "CardDAV address books discovered"
+	MsgConnTested      = "Source connection tested"
+	MsgJMAPFetched     = "JMAP contacts downloaded"
+	MsgThunderbirdRead = "Thunderbird address book read"
+	MsgFileWritten     = "Calendar written to file"
+	MsgHookDone        = "Sync hook finished"
+	MsgServerDisabled  = "Local HTTP server disabled in the settings"
+	MsgSecretStore     = "Secret storage selected"
+	MsgSecretMoved     = "Secret moved to the selected storage"
+	MsgPrefsMigrated   = "Preferences migrated"
+	MsgSkippedCard     = "Skipping malformed vCard"
+	MsgSkippedFile     = "Skipping unreadable vCard file"
+	MsgSkippedLarge    = "Skipping vCard larger than the maximum contact size"
+	MsgDirScanned      = "vCard directory scanned"
+	MsgLocalChanged    = "Local source changed, resynchronizing"
+	MsgOffline         = "Network unreachable, retrying the synchronization soon"
+	MsgOnline          = "Network reachable again, resuming the normal schedule"
+	MsgSyncDeferred    = "The server asked to wait, deferring the next synchronization"
+	MsgSyncHeld        = "Scheduled synchronization skipped, the server asked to wait"
+	MsgCSVExported     = "Contacts exported to CSV"
+	MsgICSExported     = "Calendar exported to file"
+	MsgSettingsSaved   = "Settings exported to file"
+	MsgSettingsLoaded  = "Settings imported from file"
+	MsgSourceDropped   = "vCard file dropped, using it as local source"
+	MsgSkippedDate     = "Skipping invalid date format"
+	MsgGenSuccess      = "Calendar generation successful"
+	MsgAppStarting     = "Starting application"
+	MsgServerListen    = "HTTP server listening"
+	MsgServerRestart   = "HTTP server moving to another port"
+	MsgServerStop      = "Shutting down HTTP server..."
+	MsgCacheUpdated    = "Calendar cache updated"
+	MsgICSInvalid      = "Generated calendar is not RFC 5545 compliant"
+	MsgICSValid        = "Generated calendar is RFC 5545 compliant"
+	MsgPhotosUpdated   = "Photo cache updated"
+	MsgLocaleSkip      = "Skipping non-locale file"
+	MsgLocaleBadName   = "Skipping malformed locale filename"
+	MsgLocaleLoaded    = "Locale loaded successfully"
+	MsgLocaleNoUserDir = "No user locales directory"
+	MsgTransMissing    = "Missing translation key"
+	MsgPassFail        = "Password retrieval failed (might be empty)"
+	MsgLogWarning      = "Warning: %s at %s: %v\n"
+	MsgBdayToday       = "Birthday found today"
+	MsgPushSent        = "Push notification sent"
+	MsgNotifQueued     = "Notification held until the end of the quiet hours"
+	MsgNotifSnoozed    = "Birthday notification snoozed"
+	MsgContactMerged   = "Duplicate contact merged"
+
+	PlaceholderProxy    = "http://proxy.example.com:3128"
+	PlaceholderTokenURL = "https://oauth2.googleapis.com/token"
+	PlaceholderURL      = "https://..."
+	PlaceholderCloud    = "https://cloud.example.com"
+	PlaceholderNetworks = "192.168.1.0/24"
+	PlaceholderQuiet    = "22:00"
+	PlaceholderQuietEnd = "08:00"
+
+	// LandingHTML is the page shown to browsers under RouteRoot (html/template of server.landingPage).
+	LandingHTML = `<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Name}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 40em; margin: 2em auto; padding: 0 1em; }
+input { width: 100%; box-sizing: border-box; font-family: monospace; padding: .4em; }
+.link { display: flex; gap: .5em; margin: .5em 0; }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+{{if .Time}}<p>{{.Updated}} {{.Time}}</p>{{end}}
+{{if ge .Count 0}}<p>{{.Contacts}} {{.Count}}</p>{{end}}
+<p>{{.Subscribe}}</p>
+<div class="link"><input readonly value="{{.Webcal}}" onfocus="this.select()"><button data-link="{{.Webcal}}">{{.Copy}}</button></div>
+<div class="link"><input readonly value="{{.HTTPLink}}" onfocus="this.select()"><button data-link="{{.HTTPLink}}">{{.Copy}}</button></div>
+<script>
+document.querySelectorAll("button[data-link]").forEach(function (b) {
+  b.onclick = function () { navigator.clipboard.writeText(b.dataset.link); };
+});
+</script>
+</body>
+</html>
+`
+
+	TitleHomeAssistant = "Home Assistant" // Product name, not translated
+	// FormatHomeAssistantYAML is a REST sensor reading RouteHomeAssistant. Requires the port.
+	FormatHomeAssistantYAML = `rest:
+  - resource: ` + FormatBaseURL + RouteHomeAssistant + `
+    scan_interval: 900
+    sensor:
+      - name: Birthdays today
+        value_template: "{{ value_json.today }}"
+        json_attributes:
+          - next
+          - upcoming
+      - name: Next birthday
+        value_template: "{{ value_json.next.name if value_json.next else 'none' }}"
+`
 )
 
 // -----------------------------------------------------------------------------
@@ -428,7 +1848,11 @@ const (
 	LogKeyURL       = "url"
 	LogKeyStatus    = "status_code"
 	LogKeyFile      = "file"
+	LogKeyCommand   = "command"
+	LogKeyOutput    = "output"
+	LogKeyPath      = "path"
 	LogKeyLang      = "lang"
+	LogKeyLevel     = "level"
 	LogKeyKey       = "key"
 	LogKeyPort      = "port"
 	LogKeyMode      = "mode"
@@ -440,7 +1864,11 @@ const (
 	LogKeyFound     = "birthdays_found"
 	LogKeyToday     = "birthdays_today"
 	LogKeySizeBytes = "size_bytes"
+	LogKeyMax       = "max"
 	LogKeyETag      = "etag"
+	LogKeyStale     = "stale"
+	LogKeyModified  = "last_modified"
+	LogKeyUntil     = "until"
 	LogKeyManual    = "manual"
 	LogKeyValue     = "value"
 	LogKeyStats     = "stats"
@@ -450,6 +1878,20 @@ const (
 	LogKeyName      = "name"
 	LogKeyDOB       = "date_of_birth"
 	LogKeyDuration  = "duration_ms"
+	LogKeyAttempt   = "attempt"
+	LogKeyDelay     = "delay"
+	LogKeyBackend   = "backend"
+	LogKeyMerged    = "duplicates_merged"
+	LogKeyDropped   = "dropped"
+	LogKeyUID       = "uid"
+	LogKeyExpiry    = "expiry"
+	LogKeyLine      = "line"
+	LogKeyReason    = "reason"
+	LogKeyMethod    = "method"
+	LogKeyAddress   = "address"
+	LogKeyBytes     = "bytes"
+	LogKeyRemote    = "remote_addr"
+	LogKeyCondHit   = "conditional_hit" // 304 Not Modified
 
 	// Startup Info Keys
 	LogKeyBuild   = "build"
@@ -467,14 +1909,24 @@ const (
 // -----------------------------------------------------------------------------
 
 const (
-	CompUI      = "ui"
-	CompUISet   = "ui_settings"
-	CompEngine  = "engine"
-	CompServer  = "server"
-	CompFetcher = "fetcher"
-	CompWorker  = "worker"
-	CompMain    = "main"
-	CompI18n    = "i18n"
+	CompUI         = "ui"
+	CompUISet      = "ui_settings"
+	CompEngine     = "engine"
+	CompServer     = "server"
+	CompFetcher    = "fetcher"
+	CompWorker     = "worker"
+	CompWatcher    = "watcher"
+	CompMain       = "main"
+	CompI18n       = "i18n"
+	CompNotify     = "notify"
+	CompStartup    = "autostart"
+	CompTelemetry  = "telemetry"
+	CompSecrets    = "secrets"
+	CompPublish    = "publish"
+	CompHook       = "hook"
+	CompSystemd    = "systemd"
+	CompPrefs      = "prefs"
+	CompHTTPAccess = "http_access"
 )
 
 // -----------------------------------------------------------------------------
@@ -483,4 +1935,5 @@ const (
 
 const (
 	LayoutColumnsDouble = 2
+	LayoutColumnsTriple = 3
 )
@@ -56,9 +56,11 @@ func TestTimeoutsAndLimits(t *testing.T) {
 	assert.Greater(t, config.ShutdownTimeout, 0*time.Second, "ShutdownTimeout must be positive")
 
 	// Limits
-	assert.Greater(t, config.MaxHTTPResponseSize, 0, "MaxHTTPResponseSize must be positive")
+	assert.Greater(t, config.DefaultMaxDownloadMB, 0, "DefaultMaxDownloadMB must be positive")
 	// The limit should be generous enough for photos but prevent infinite streams.
 	// 256MB is our target, ensuring it's not set back to a low value like 10MB unintentionally.
-	assert.GreaterOrEqual(t, int64(config.MaxHTTPResponseSize), int64(50*1024*1024), "MaxHTTPResponseSize should be at least 50MB for real-world usage")
-	assert.Less(t, int64(config.MaxHTTPResponseSize), int64(1*1024*1024*1024), "MaxHTTPResponseSize should stay under 1GB to protect RAM")
+	assert.GreaterOrEqual(t, config.DefaultMaxDownloadMB, 50, "DefaultMaxDownloadMB should be at least 50MB for real-world usage")
+	assert.Less(t, config.DefaultMaxDownloadMB, 1024, "DefaultMaxDownloadMB should stay under 1GB to protect RAM")
+	assert.Less(t, config.DefaultMaxCardMB, config.DefaultMaxDownloadMB, "A single vCard must fit in the address book")
+	assert.Greater(t, config.DefaultMaxContacts, 0, "DefaultMaxContacts must be positive")
 }
@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/emersion/go-vcard"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// customDate is an extra date stored by Apple Contacts, e.g.:
+//
+//	item1.X-ABDATE;type=pref:2010-06-20
+//	item1.X-ABLabel:_$!<Anniversary>!$_
+type customDate struct {
+	label     string
+	date      time.Time
+	yearKnown bool
+}
+
+// extractCustomDates returns the X-ABDATE dates of a card with their X-ABLabel.
+// Dates that cannot be parsed are skipped.
+func extractCustomDates(card vcard.Card) []customDate {
+	var dates []customDate
+	for _, field := range card[config.VCardXABDate] {
+		t, yearKnown, err := ParseDate(strings.TrimSpace(field.Value))
+		if err != nil {
+			continue
+		}
+		if omit := field.Params.Get(config.VCardParamAppleOmitYear); yearKnown && omit == strconv.Itoa(t.Year()) {
+			t, yearKnown = withoutYear(t), false
+		}
+		dates = append(dates, customDate{
+			label:     abLabel(card, field.Group),
+			date:      t,
+			yearKnown: yearKnown,
+		})
+	}
+	return dates
+}
+
+// abLabel finds the X-ABLabel sharing the property group (e.g., "item1") and strips
+// Apple's markers around built-in labels ("_$!<Anniversary>!$_" -> "Anniversary").
+func abLabel(card vcard.Card, group string) string {
+	if group != "" {
+		for _, field := range card[config.VCardXABLabel] {
+			if strings.EqualFold(field.Group, group) {
+				label := strings.TrimPrefix(strings.TrimSpace(field.Value), config.ABLabelPrefix)
+				label = strings.TrimSuffix(label, config.ABLabelSuffix)
+				if label != "" {
+					return label
+				}
+			}
+		}
+	}
+	return config.ABLabelOther
+}
+
+// createCustomEvents generates the yearly events of a custom date.
+// Custom dates never count as "today" birthdays.
+func (g *Generator) createCustomEvents(name string, d customDate, cfg SyncConfig, now time.Time) []*ical.Event {
+	input := fmt.Sprintf(config.FormatHashInput, name+"|"+d.label, d.date.Format(time.RFC3339), config.UIDSalt)
+	hash := sha256.Sum256([]byte(input))
+	uidBase := fmt.Sprintf("%x", hash[:config.UIDHashLength])
+
+	events, _ := yearlyEvents(d.date, d.yearKnown, 0, now, uidBase, func(years int) (string, []string) {
+		if g.FormatDateSummary != nil {
+			return g.FormatDateSummary(d.label, name, years, d.yearKnown && years > 0), cfg.ReminderTriggers
+		}
+		if d.yearKnown && years > 0 {
+			return fmt.Sprintf(config.FallbackDateSummaryYears, d.label, name, years), cfg.ReminderTriggers
+		}
+		return fmt.Sprintf(config.FallbackDateSummary, d.label, name), cfg.ReminderTriggers
+	})
+	return events
+}
+
+// extractDeathDate returns the date of death of a card (DEATHDATE, RFC 6474, or the older
+// X-DEATHDATE), or nil if there is none or it cannot be parsed (e.g., a text value).
+func extractDeathDate(card vcard.Card) *customDate {
+	for _, name := range []string{config.VCardDeathDate, config.VCardXDeathDate} {
+		field := card.Get(name)
+		if field == nil {
+			continue
+		}
+		t, yearKnown, err := ParseDate(strings.TrimSpace(field.Value))
+		if err != nil {
+			continue
+		}
+		return &customDate{date: t, yearKnown: yearKnown}
+	}
+	return nil
+}
+
+// createMemorialEvents generates the yearly remembrance events of a date of death.
+// They never count as "today" birthdays.
+func (g *Generator) createMemorialEvents(name string, d customDate, cfg SyncConfig, now time.Time) []*ical.Event {
+	input := fmt.Sprintf(config.FormatHashInput, name+"|"+config.VCardDeathDate, d.date.Format(time.RFC3339), config.UIDSalt)
+	hash := sha256.Sum256([]byte(input))
+	uidBase := fmt.Sprintf("%x", hash[:config.UIDHashLength])
+
+	events, _ := yearlyEvents(d.date, d.yearKnown, 0, now, uidBase, func(years int) (string, []string) {
+		if g.FormatMemorialSummary != nil {
+			return g.FormatMemorialSummary(name, years, d.yearKnown && years > 0), cfg.ReminderTriggers
+		}
+		if d.yearKnown && years > 0 {
+			return fmt.Sprintf(config.FallbackMemorialYears, name, years), cfg.ReminderTriggers
+		}
+		return fmt.Sprintf(config.FallbackMemorial, name), cfg.ReminderTriggers
+	})
+	return events
+}
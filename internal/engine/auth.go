@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// Auth selects how requests to the web source are authenticated.
+// The zero value uses HTTP Basic Auth with the user and password of the source, if any.
+type Auth struct {
+	Type   string       // config.AuthBasic, config.AuthBearer or config.AuthOAuth2
+	Token  string       // Static token of config.AuthBearer
+	OAuth2 *TokenSource // Access tokens of config.AuthOAuth2
+}
+
+type authKey struct{}
+
+// withAuth passes the authentication of a synchronization down to the fetcher.
+func withAuth(ctx context.Context, a Auth) context.Context {
+	return context.WithValue(ctx, authKey{}, a)
+}
+
+func authFrom(ctx context.Context) Auth {
+	a, _ := ctx.Value(authKey{}).(Auth)
+	return a
+}
+
+// authorize sets the credentials of req, obtaining an OAuth2 access token through client if needed.
+func (a Auth) authorize(ctx context.Context, client *http.Client, req *http.Request, user, pass string) error {
+	switch a.Type {
+	case config.AuthBearer:
+		req.Header.Set(config.HeaderAuthorization, config.AuthBearerPrefix+a.Token)
+	case config.AuthOAuth2:
+		if a.OAuth2 == nil {
+			return errors.New(config.ErrOAuth2Missing)
+		}
+		token, err := a.OAuth2.Token(ctx, client)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(config.HeaderAuthorization, config.AuthBearerPrefix+token)
+	default:
+		if user != "" || pass != "" {
+			req.SetBasicAuth(user, pass)
+		}
+	}
+	return nil
+}
+
+// OAuth2Config identifies an OAuth2 client at the token endpoint of its provider.
+type OAuth2Config struct {
+	TokenURL     string // e.g., "https://oauth2.googleapis.com/token"
+	ClientID     string
+	ClientSecret string // Empty for public clients
+}
+
+// TokenSource obtains OAuth2 access tokens with a refresh token (RFC 6749, section 6),
+// and reuses each one until shortly before it expires. It is safe for concurrent use.
+type TokenSource struct {
+	Config OAuth2Config
+	Clock  Clock
+
+	// OnRotate is called when the provider issues a new refresh token, so that it can be saved.
+	OnRotate func(refreshToken string)
+
+	mu      sync.Mutex
+	refresh string
+	access  string
+	expiry  time.Time // Zero if the provider gave no lifetime
+}
+
+// NewTokenSource returns a TokenSource starting from refreshToken.
+func NewTokenSource(conf OAuth2Config, refreshToken string) *TokenSource {
+	return &TokenSource{Config: conf, Clock: RealClock{}, refresh: refreshToken}
+}
+
+// RefreshToken returns the current refresh token, which the provider may have rotated.
+func (ts *TokenSource) RefreshToken() string {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.refresh
+}
+
+// Invalidate drops the cached access token, e.g., after the server rejected it.
+func (ts *TokenSource) Invalidate() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.access = ""
+}
+
+// Token returns a valid access token, refreshing it through client when it is missing or about to expire.
+func (ts *TokenSource) Token(ctx context.Context, client *http.Client) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.access != "" && (ts.expiry.IsZero() || ts.Clock.Now().Before(ts.expiry.Add(-config.OAuth2ExpiryDelta))) {
+		return ts.access, nil
+	}
+	if ts.refresh == "" {
+		return "", fmt.Errorf("%s: %s", config.ErrOAuth2Refresh, config.ErrOAuth2NoToken)
+	}
+
+	form := url.Values{
+		config.OAuth2ParamGrantType:    {config.OAuth2GrantRefresh},
+		config.OAuth2ParamRefreshToken: {ts.refresh},
+		config.OAuth2ParamClientID:     {ts.Config.ClientID},
+	}
+	if ts.Config.ClientSecret != "" {
+		form.Set(config.OAuth2ParamClientSecret, ts.Config.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ts.Config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", config.ErrOAuth2Refresh, err)
+	}
+	req.Header.Set(config.HeaderContentType, config.MimeForm)
+	req.Header.Set(config.HeaderUserAgent, config.UserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrNetwork, config.ErrOAuth2Refresh, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var body struct {
+		AccessToken  string `json:"access_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+		RefreshToken string `json:"refresh_token"`
+		Error        string `json:"error"`
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, config.OAuth2MaxResponse))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrNetwork, config.ErrOAuth2Refresh, err)
+	}
+	_ = json.Unmarshal(data, &body) // Errors are reported through the status and the missing token
+	if resp.StatusCode != http.StatusOK || body.AccessToken == "" {
+		return "", fmt.Errorf("%s: %s %s", config.ErrOAuth2Refresh, resp.Status, body.Error)
+	}
+
+	ts.access = body.AccessToken
+	ts.expiry = time.Time{}
+	if body.ExpiresIn > 0 {
+		ts.expiry = ts.Clock.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	if body.RefreshToken != "" && body.RefreshToken != ts.refresh {
+		ts.refresh = body.RefreshToken
+		if ts.OnRotate != nil {
+			ts.OnRotate(body.RefreshToken)
+		}
+	}
+	slog.Debug(config.MsgOAuth2Refreshed,
+		config.LogKeyComponent, config.CompFetcher,
+		config.LogKeyExpiry, ts.expiry)
+	return ts.access, nil
+}
@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// books chains the address books of a web source, read one after the other.
+type books struct {
+	io.Reader
+	bodies []io.ReadCloser
+}
+
+func (b *books) Close() error {
+	var errs []error
+	for _, body := range b.bodies {
+		errs = append(errs, body.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// fetchBooks downloads every address book of cfg.WebURLs and merges them. They are always
+// downloaded in full: conditional requests only apply to a single address book.
+func (g *Generator) fetchBooks(ctx context.Context, cfg SyncConfig) (io.ReadCloser, error) {
+	g.Validators = Validators{}
+	merged := &books{}
+	var readers []io.Reader
+	for _, u := range cfg.WebURLs {
+		body, err := g.Fetcher.Fetch(ctx, u, cfg.WebUser, cfg.WebPass)
+		if err != nil {
+			_ = merged.Close()
+			return nil, err
+		}
+		merged.bodies = append(merged.bodies, body)
+		// Address books do not always end with a line break; keep cards apart.
+		readers = append(readers, body, strings.NewReader("\r\n"))
+	}
+	merged.Reader = io.MultiReader(readers...)
+	return merged, nil
+}
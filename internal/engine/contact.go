@@ -8,9 +8,22 @@ type BirthdayEntry struct {
 	// UID is a unique identifier (hash) used for stability in lists.
 	UID string
 
-	// Name is the display name (Formatted Name or Structured Name).
+	// OverrideKey identifies the contact in SyncConfig.Overrides: its vCard UID, or UID without one.
+	OverrideKey string
+
+	// Name is the display name (Formatted Name or Structured Name in SyncConfig.NameFormat,
+	// or the nickname with SyncConfig.PreferNickname).
 	Name string
 
+	// FullName is the Formatted Name (or Structured Name) of the contact, as written, whatever Name is.
+	FullName string
+
+	// Nickname is the first vCard NICKNAME, empty without one.
+	Nickname string
+
+	// Note is the vCard NOTE, empty without one.
+	Note string
+
 	// DateOfBirth is the original parsed date.
 	DateOfBirth time.Time
 
@@ -24,4 +37,27 @@ type BirthdayEntry struct {
 	// AgeNext is the age the person will turn at NextOccurrence.
 	// Only valid if YearKnown is true.
 	AgeNext int
+
+	// Photo is the contact picture, if any. Only extracted when photos are enabled.
+	Photo *Photo
+
+	// Categories lists the vCard CATEGORIES (groups) the contact belongs to.
+	Categories []string
+
+	// Milestone indicates that AgeNext is a milestone age (e.g., 18 or 40).
+	Milestone bool
+
+	// Overridden indicates that DateOfBirth comes from a local user correction.
+	Overridden bool
+
+	// Hidden indicates that the user excluded the contact from the calendar.
+	Hidden bool
+
+	// Deceased indicates a known year of death, with remembrance events enabled:
+	// the calendar has no birthday after it.
+	Deceased bool
+
+	// Lunar indicates that the birthday follows the Chinese lunar calendar:
+	// NextOccurrence and the events fall on the lunar anniversary of DateOfBirth.
+	Lunar bool
 }
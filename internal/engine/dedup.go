@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-vcard"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// cardRecord is a parsed vCard with a valid birthday, waiting to be turned into events.
+type cardRecord struct {
+	card      vcard.Card
+	name      string
+	birthDate time.Time
+	yearKnown bool
+	groups    []string
+}
+
+// dedupRecords merges records describing the same person.
+// Two records match when they share a vCard UID, or when their normalized names and
+// birthdays are equal (a missing birth year is compatible with any year).
+// The richest record of each group is kept and the contact groups are merged.
+// It returns the remaining records, in their original order, and the number of merged entries.
+func dedupRecords(records []cardRecord) ([]cardRecord, int) {
+	var kept []cardRecord
+	byUID := make(map[string]int)
+	byName := make(map[string][]int)
+	merged := 0
+
+	for _, rec := range records {
+		uid := cardUID(rec.card)
+		key := dedupKey(rec)
+
+		idx := -1
+		if i, ok := byUID[uid]; ok && uid != "" {
+			idx = i
+		} else {
+			for _, i := range byName[key] {
+				if yearsCompatible(kept[i], rec) {
+					idx = i
+					break
+				}
+			}
+		}
+
+		if idx < 0 {
+			kept = append(kept, rec)
+			idx = len(kept) - 1
+			byName[key] = append(byName[key], idx)
+		} else {
+			dropped := rec
+			if richer(rec, kept[idx]) {
+				dropped = kept[idx]
+				rec.groups = mergeGroups(rec.groups, kept[idx].groups)
+				kept[idx] = rec
+			} else {
+				kept[idx].groups = mergeGroups(kept[idx].groups, rec.groups)
+			}
+			merged++
+			slog.Info(config.MsgContactMerged,
+				config.LogKeyComponent, config.CompEngine,
+				config.LogKeyName, kept[idx].name,
+				config.LogKeyDropped, dropped.name,
+				config.LogKeyUID, uid)
+		}
+
+		if uid != "" {
+			byUID[uid] = idx
+		}
+	}
+
+	return kept, merged
+}
+
+// cardUID returns the vCard UID property, or an empty string.
+func cardUID(card vcard.Card) string {
+	if f := card.Get(config.VCardUID); f != nil {
+		return strings.TrimSpace(f.Value)
+	}
+	return ""
+}
+
+// dedupKey normalizes the name (case and whitespace) and keeps the month and day of birth.
+// The year is checked separately by yearsCompatible.
+func dedupKey(rec cardRecord) string {
+	name := strings.ToLower(strings.Join(strings.Fields(rec.name), " "))
+	return name + "|" + rec.birthDate.Format(config.DateFormatNoYearB)
+}
+
+// yearsCompatible reports whether two records can describe the same birth.
+func yearsCompatible(a, b cardRecord) bool {
+	if !a.yearKnown || !b.yearKnown {
+		return true
+	}
+	return a.birthDate.Year() == b.birthDate.Year()
+}
+
+// richer reports whether a is more complete than b.
+// A known birth year wins over everything else, then the number of vCard fields decides.
+func richer(a, b cardRecord) bool {
+	if a.yearKnown != b.yearKnown {
+		return a.yearKnown
+	}
+	return fieldCount(a.card) > fieldCount(b.card)
+}
+
+// fieldCount returns the total number of property values in a vCard.
+func fieldCount(card vcard.Card) int {
+	n := 0
+	for _, fields := range card {
+		n += len(fields)
+	}
+	return n
+}
+
+// mergeGroups appends the groups of b missing from a (case-insensitive).
+func mergeGroups(a, b []string) []string {
+	out := append([]string(nil), a...)
+	for _, g := range b {
+		found := false
+		for _, existing := range out {
+			if strings.EqualFold(existing, g) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, g)
+		}
+	}
+	return out
+}
@@ -0,0 +1,236 @@
+package engine
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// AddressBook is a CardDAV address book found by Discover.
+type AddressBook struct {
+	Name     string // Display name, or the last segment of the URL without one
+	URL      string // Collection URL
+	Contacts int    // vCards in the collection, -1 if the server did not list them
+}
+
+// Discoverer is implemented by fetchers able to find the address books of a CardDAV account.
+type Discoverer interface {
+	Discover(ctx context.Context, baseURL, user, pass string) ([]AddressBook, error)
+}
+
+// Discover finds the address books of user on the server at baseURL (RFC 6764 and RFC 6352):
+// the well-known URL leads to the principal, whose address book home lists the collections.
+// Servers without the well-known URL are tried at baseURL itself, then at the Nextcloud DAV root.
+func (f *HTTPFetcher) Discover(ctx context.Context, baseURL, user, pass string) ([]AddressBook, error) {
+	base, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrInvalidURL, err)
+	}
+	if base.Scheme != config.SchemeHTTP && base.Scheme != config.SchemeHTTPS {
+		return nil, fmt.Errorf("%s: %s", config.ErrProtocol, base.Scheme)
+	}
+
+	client, err := f.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Redirects are followed by propfind, as the client would turn PROPFIND into GET.
+	noRedirect := *client
+	noRedirect.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	d := davSession{client: &noRedirect, user: user, pass: pass}
+
+	var principal *url.URL
+	starts := []*url.URL{
+		base.ResolveReference(&url.URL{Path: config.WellKnownCardDAV}),
+		base,
+		base.JoinPath(config.NextcloudDAVPath),
+	}
+	for _, start := range starts {
+		if principal, err = d.href(ctx, start, config.PropfindPrincipal, func(p davProp) string { return p.Principal.Href }); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrDiscovery, err)
+	}
+
+	home, err := d.href(ctx, principal, config.PropfindAddressBookHome, func(p davProp) string { return p.Home.Href })
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrDiscovery, err)
+	}
+
+	where, ms, err := d.propfind(ctx, home, "1", config.PropfindAddressBooks)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrDiscovery, err)
+	}
+	var books []AddressBook
+	for _, r := range ms.Responses {
+		p := r.prop()
+		if p.ResourceType.AddressBook == nil {
+			continue
+		}
+		href, err := url.Parse(r.Href)
+		if err != nil {
+			continue
+		}
+		u := where.ResolveReference(href)
+		name := p.Name
+		if name == "" {
+			name = path.Base(u.Path)
+		}
+		books = append(books, AddressBook{Name: name, URL: u.String()})
+	}
+	if len(books) == 0 {
+		return nil, errors.New(config.ErrNoAddressBook)
+	}
+	sort.Slice(books, func(i, j int) bool { return books[i].Name < books[j].Name })
+	for i := range books {
+		books[i].Contacts = d.count(ctx, books[i].URL)
+	}
+	return books, nil
+}
+
+// davSession sends the PROPFIND requests of a discovery.
+type davSession struct {
+	client     *http.Client
+	user, pass string
+}
+
+// davMultistatus is the answer to PROPFIND (RFC 4918, section 14.16).
+type davMultistatus struct {
+	Responses []davResponse `xml:"DAV: response"`
+}
+
+type davResponse struct {
+	Href     string `xml:"DAV: href"`
+	Propstat []struct {
+		Prop davProp `xml:"DAV: prop"`
+	} `xml:"DAV: propstat"`
+}
+
+type davProp struct {
+	Principal    davHref `xml:"DAV: current-user-principal"`
+	Home         davHref `xml:"urn:ietf:params:xml:ns:carddav addressbook-home-set"`
+	Name         string  `xml:"DAV: displayname"`
+	ResourceType struct {
+		AddressBook *struct{} `xml:"urn:ietf:params:xml:ns:carddav addressbook"`
+	} `xml:"DAV: resourcetype"`
+}
+
+// davHref is a property holding a URL. Its namespace differs from the one of the property.
+type davHref struct {
+	Href string `xml:"DAV: href"`
+}
+
+// prop merges the properties of every propstat; those not found come in a propstat of their own.
+func (r davResponse) prop() davProp {
+	var p davProp
+	for _, ps := range r.Propstat {
+		if ps.Prop.Principal.Href != "" {
+			p.Principal = ps.Prop.Principal
+		}
+		if ps.Prop.Home.Href != "" {
+			p.Home = ps.Prop.Home
+		}
+		if ps.Prop.Name != "" {
+			p.Name = ps.Prop.Name
+		}
+		if ps.Prop.ResourceType.AddressBook != nil {
+			p.ResourceType = ps.Prop.ResourceType
+		}
+	}
+	return p
+}
+
+// href requests a property holding a URL at u, and resolves it.
+func (d davSession) href(ctx context.Context, u *url.URL, body string, get func(davProp) string) (*url.URL, error) {
+	where, ms, err := d.propfind(ctx, u, "0", body)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range ms.Responses {
+		if v := get(r.prop()); v != "" {
+			href, err := url.Parse(v)
+			if err != nil {
+				return nil, err
+			}
+			return where.ResolveReference(href), nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %s", config.ErrDiscoveryProp, u.Redacted())
+}
+
+// count lists the members of the collection at book, -1 if it cannot.
+func (d davSession) count(ctx context.Context, book string) int {
+	u, err := url.Parse(book)
+	if err != nil {
+		return -1
+	}
+	where, ms, err := d.propfind(ctx, u, "1", config.PropfindCards)
+	if err != nil {
+		slog.Debug(config.ErrDiscoveryCount, config.LogKeyURL, u.Redacted(), config.LogKeyError, err, config.LogKeyComponent, config.CompFetcher)
+		return -1
+	}
+	n := 0
+	for _, r := range ms.Responses {
+		href, err := url.Parse(r.Href)
+		if err != nil {
+			continue
+		}
+		// The collection answers for itself too.
+		if strings.TrimSuffix(where.ResolveReference(href).Path, "/") != strings.TrimSuffix(where.Path, "/") {
+			n++
+		}
+	}
+	return n
+}
+
+// propfind sends a PROPFIND request to u, following redirects, and returns the final URL with the answer.
+func (d davSession) propfind(ctx context.Context, u *url.URL, depth, body string) (*url.URL, davMultistatus, error) {
+	var ms davMultistatus
+	for range config.DiscoveryMaxRedirects {
+		req, err := http.NewRequestWithContext(ctx, config.MethodPropfind, u.String(), strings.NewReader(body))
+		if err != nil {
+			return nil, ms, err
+		}
+		req.Header.Set(config.HeaderUserAgent, config.UserAgent)
+		req.Header.Set(config.HeaderContentType, config.MimeXML)
+		req.Header.Set(config.HeaderDepth, depth)
+		req.SetBasicAuth(d.user, d.pass)
+
+		resp, err := d.client.Do(req)
+		if err != nil {
+			return nil, ms, fmt.Errorf("%w: %w", ErrNetwork, err)
+		}
+		switch {
+		case resp.StatusCode/100 == 3 && resp.Header.Get(config.HeaderLocation) != "":
+			_ = resp.Body.Close()
+			next, err := u.Parse(resp.Header.Get(config.HeaderLocation))
+			if err != nil {
+				return nil, ms, err
+			}
+			u = next
+			continue
+		case resp.StatusCode != http.StatusMultiStatus:
+			_ = resp.Body.Close()
+			return nil, ms, &StatusError{Code: resp.StatusCode, Status: resp.Status}
+		}
+		err = xml.NewDecoder(io.LimitReader(resp.Body, config.DiscoveryMaxResponse)).Decode(&ms)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, ms, fmt.Errorf("%s: %w", config.ErrDiscoveryProp, err)
+		}
+		return u, ms, nil
+	}
+	return nil, ms, errors.New(config.ErrDiscoveryRedirects)
+}
@@ -8,22 +8,62 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
-	"os"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/emersion/go-ical"
 	"github.com/emersion/go-vcard"
 	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/telemetry"
 )
 
 // SyncConfig contains all parameters required to perform a synchronization.
 type SyncConfig struct {
-	Mode            string // config.SourceModeLocal or config.SourceModeWeb
-	LocalPath       string // Absolute path to the .vcf file
-	WebURL          string // CardDAV or WebDAV URL
-	WebUser         string // HTTP Basic Auth Username
-	WebPass         string // HTTP Basic Auth Password
-	ReminderTrigger string // ISO8601 duration string (e.g., "-P1D")
+	Mode              string              // config.SourceModeLocal, SourceModeWeb, SourceModeJMAP, SourceModeSystem, SourceModeThunderbird, SourceModeOutlook, SourceModeExec or a registered Source
+	LocalPath         string              // Absolute path to a .vcf file or to a directory of vCard files
+	ThunderbirdPath   string              // Thunderbird profile, address book or LDIF export; empty to detect the profile
+	ExecCommand       string              // Program printing the contacts as JSON, for config.SourceModeExec
+	WebURL            string              // CardDAV or WebDAV URL, or JMAP session URL
+	WebURLs           []string            // Address books of the web source downloaded instead of WebURL, merged
+	WebUser           string              // HTTP Basic Auth Username
+	WebPass           string              // HTTP Basic Auth Password
+	Auth              Auth                // Bearer or OAuth2 authentication, instead of WebUser and WebPass
+	Transport         TransportConfig     // Proxy settings of the web source
+	ReminderTriggers  []string            // ISO8601 duration strings (e.g., "-P7D", "-P1D"), one VALARM each
+	PhotoMode         string              // config.PhotoModeNone, config.PhotoModeInline or config.PhotoModeLink
+	PhotoBaseURL      string              // Base URL of the local server, used by config.PhotoModeLink
+	Categories        []string            // CATEGORIES set on every event (e.g., "Birthday")
+	ContactGroups     bool                // Also copy each contact's vCard CATEGORIES onto its events
+	ContactDetails    bool                // Contact email, phone and NOTE in the event DESCRIPTION, with a tel: or mailto: URL
+	PreferNickname    bool                // Name the events and the contacts after the vCard NICKNAME, when set, instead of FN
+	NameFormat        string              // config.NameFormatGivenFamily or NameFormatFamilyGiven to build the names from N, empty or config.NameFormatFN for FN
+	Color             string              // RFC 7986 COLOR (CSS3 color name), empty to omit
+	Class             string              // CLASS of every event (config.ICalClassPublic...), empty for config.DefaultEventClass
+	CalendarName      string              // X-WR-CALNAME and RFC 7986 NAME, empty for config.ICalCalName
+	CalendarDesc      string              // X-WR-CALDESC and RFC 7986 DESCRIPTION of the calendar, empty to omit
+	CalendarColor     string              // RFC 7986 COLOR of the calendar, empty to omit
+	UIDDomain         string              // Domain of the event UIDs (e.g., "work"), empty for config.ICalDomain
+	TimeZone          string              // IANA time zone of "today" and of the event dates, empty for the clock's
+	EventTime         string              // Start of timed events ("09:00", config.EventTimeFormat), empty for all-day events
+	EventDuration     time.Duration       // Duration of timed events, 0 for config.DefaultEventMins
+	Milestones        []int               // Explicit milestone ages (e.g., 18, 21)
+	MilestoneEvery    int                 // Every multiple of N is a milestone (e.g., 10), 0 to disable
+	MilestonePrefix   string              // Prepended to the summary of milestone events (e.g., "🎉")
+	MilestoneTrigger  string              // Extra ISO8601 reminder added to milestone events only
+	IncludeCategories []string            // Only process contacts in one of these vCard CATEGORIES, empty for all
+	CustomDates       bool                // Also create events for Apple X-ABDATE dates (anniversaries, ...)
+	Memorials         bool                // Remembrance events on DEATHDATE (RFC 6474) or X-DEATHDATE, no birthdays after the death
+	ZodiacSummary     bool                // Append the zodiac sign emoji to birthday summaries
+	NameDays          string              // Language of the name-day table (NameDayLocales), empty to disable
+	Overrides         map[string]string   // vCard UID (or contact UID without one) -> corrected vCard date (e.g., "1990-06-11", "--06-11")
+	Hidden            map[string]bool     // Contact UIDs listed in the UI but left out of the calendar
+	Lunar             map[string]bool     // Contact UIDs celebrating their birthday by the Chinese lunar calendar
+	Offsets           []Offset            // Offset anniversaries of every contact (half birthdays, ...)
+	ContactOffsets    map[string][]Offset // Contact UID -> offset anniversaries added to Offsets
+	Limits            Limits              // Resource limits, zero values for the defaults
 }
 
 // Generator is the core service responsible for fetching and converting data.
@@ -33,12 +73,76 @@ type Generator struct {
 
 	// FormatSummary allows the UI to inject localized strings into the logic layer.
 	FormatSummary func(name string, age int, yearKnown bool) string
+
+	// FormatDateSummary does the same for custom dates (e.g., "Anniversary: Jane Doe (10)").
+	FormatDateSummary func(label, name string, years int, yearKnown bool) string
+
+	// FormatMemorialSummary does the same for remembrance events (e.g., "In memory of Jane Doe (5 years)").
+	FormatMemorialSummary func(name string, years int, yearKnown bool) string
+
+	// FormatNameDaySummary does the same for name days (e.g., "Name day: Jane Doe").
+	FormatNameDaySummary func(name string) string
+
+	// FormatOffsetSummary does the same for offset anniversaries (e.g., "Half birthday: Jane Doe").
+	FormatOffsetSummary func(name string, o Offset) string
+
+	// Validators of the previous download, sent as a conditional request when the fetcher supports it.
+	// RunSync returns ErrNotModified if the address book is unchanged, and otherwise replaces them.
+	Validators Validators
+
+	// Revisions of the events of the previous calendar, by UID. RunSync increments the
+	// SEQUENCE of the events whose content changed, and replaces them.
+	Revisions Revisions
+
+	// Groups is filled by RunSync with every vCard CATEGORIES value found in the source,
+	// sorted and including contacts excluded by SyncConfig.IncludeCategories.
+	Groups []string
+
+	// Progress, if set, is called by RunSync every config.ProgressCards vCards read, on its
+	// goroutine, so that long synchronizations can be followed.
+	Progress func(Progress)
+}
+
+// Progress tells how far a synchronization has read its source.
+type Progress struct {
+	Bytes int64 // Read from the source (downloaded for a web source)
+	Cards int   // vCards parsed
 }
 
 // RunSync executes the fetching, parsing, and generation pipeline.
 // It returns the ICS data, the list of contacts, the count of birthdays today, and any error.
 func (g *Generator) RunSync(ctx context.Context, cfg SyncConfig) ([]byte, []BirthdayEntry, int, error) {
+	var buf bytes.Buffer
+	contacts, count, err := g.RunSyncTo(ctx, cfg, &buf)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return buf.Bytes(), contacts, count, nil
+}
+
+// RunSyncTo is RunSync writing the ICS data to w one event at a time, as it is generated,
+// so that huge address books never hold the whole calendar in memory.
+// On error, w may have received a partial calendar that must be discarded.
+func (g *Generator) RunSyncTo(ctx context.Context, cfg SyncConfig, w io.Writer) (contacts []BirthdayEntry, count int, err error) {
 	start := time.Now()
+	ctx, span := telemetry.StartSpan(ctx, config.SpanSync, telemetry.KindInternal,
+		slog.String(config.AttrSyncMode, cfg.Mode))
+	defer func() {
+		result := config.SyncResultOK
+		switch {
+		case errors.Is(err, ErrNotModified):
+			result = config.SyncResultUnchanged
+		case err != nil:
+			result = config.SyncResultError
+			span.SetError(err)
+		}
+		span.SetAttributes(slog.Int(config.AttrBirthdays, len(contacts)))
+		span.End()
+		attrs := []slog.Attr{slog.String(config.AttrSyncMode, cfg.Mode), slog.String(config.AttrSyncResult, result)}
+		telemetry.AddCount(config.MetricSyncCount, config.UnitSyncs, 1, attrs...)
+		telemetry.RecordDuration(config.MetricSyncDuration, time.Since(start), attrs...)
+	}()
+
 	log := slog.With(
 		config.LogKeyComponent, config.CompEngine,
 		config.LogKeyMode, cfg.Mode,
@@ -50,26 +154,37 @@ func (g *Generator) RunSync(ctx context.Context, cfg SyncConfig) ([]byte, []Birt
 	if err != nil {
 		// If context error occurred during acquisition, return it directly.
 		if ctx.Err() != nil {
-			return nil, nil, 0, ctx.Err()
+			return nil, 0, ctx.Err()
+		}
+		// Nothing to regenerate: the caller keeps its previous results.
+		if errors.Is(err, ErrNotModified) {
+			return nil, 0, err
 		}
-		return nil, nil, 0, fmt.Errorf("%s: %w", config.ErrVCardParse, err)
+		return nil, 0, fmt.Errorf("%s: %w", config.ErrVCardParse, err)
 	}
 	// Best effort close. Errors in Close() for read-only files are rarely actionable here.
 	defer func() { _ = reader.Close() }()
+	reader = newLimitReader(reader, cfg.Limits.withDefaults().MaxDownloadBytes)
 
 	// Check for early cancellation before processing
 	if err := ctx.Err(); err != nil {
-		return nil, nil, 0, err
+		return nil, 0, err
 	}
 
 	// 2. Process Data
-	ics, contacts, count, err := g.generateCalendar(ctx, reader, cfg.ReminderTrigger)
+	contacts, count, err = g.generateCalendar(ctx, reader, cfg, w)
 
 	// Log performance metric
 	if err == nil {
 		log.Debug("Sync finished", config.LogKeyDuration, time.Since(start).Milliseconds())
 	}
-	return ics, contacts, count, err
+	return contacts, count, err
+}
+
+// Generate is RunSyncTo on an already open vCard stream (e.g., a file or an HTTP body),
+// without the Fetcher: cfg.Mode and the source settings are ignored.
+func (g *Generator) Generate(ctx context.Context, r io.Reader, cfg SyncConfig, w io.Writer) ([]BirthdayEntry, int, error) {
+	return g.generateCalendar(ctx, newLimitReader(io.NopCloser(r), cfg.Limits.withDefaults().MaxDownloadBytes), cfg, w)
 }
 
 // acquireStream opens the appropriate data source based on configuration.
@@ -79,29 +194,74 @@ func (g *Generator) acquireStream(ctx context.Context, cfg SyncConfig) (io.ReadC
 		if cfg.LocalPath == "" {
 			return nil, errors.New(config.ErrLocalPathEmpty)
 		}
-		return os.Open(cfg.LocalPath)
+		return openLocal(ctx, cfg.LocalPath)
 	case config.SourceModeWeb:
-		if cfg.WebURL == "" {
+		if cfg.WebURL == "" && len(cfg.WebURLs) == 0 {
 			return nil, errors.New(config.ErrWebURLEmpty)
 		}
 		if g.Fetcher == nil {
 			return nil, errors.New(config.ErrFetcherMissing)
 		}
-		return g.Fetcher.Fetch(ctx, cfg.WebURL, cfg.WebUser, cfg.WebPass)
+		ctx = withAuth(withTransport(withSpacing(ctx, cfg.Limits.RequestSpacing), cfg.Transport), cfg.Auth)
+		if len(cfg.WebURLs) > 0 {
+			return g.fetchBooks(ctx, cfg)
+		}
+		cf, ok := g.Fetcher.(ConditionalFetcher)
+		if !ok {
+			return g.Fetcher.Fetch(ctx, cfg.WebURL, cfg.WebUser, cfg.WebPass)
+		}
+		rc, next, err := cf.FetchIfModified(ctx, cfg.WebURL, cfg.WebUser, cfg.WebPass, g.Validators)
+		if err == nil {
+			g.Validators = next
+		}
+		return rc, err
+	case config.SourceModeSystem:
+		return openSystem(ctx)
+	case config.SourceModeThunderbird:
+		return openThunderbird(ctx, cfg.ThunderbirdPath)
+	case config.SourceModeOutlook:
+		return openOutlook(ctx)
+	case config.SourceModeJMAP:
+		if cfg.WebURL == "" {
+			return nil, errors.New(config.ErrWebURLEmpty)
+		}
+		jf, ok := g.Fetcher.(JMAPFetcher)
+		if !ok {
+			return nil, errors.New(config.ErrNoJMAP)
+		}
+		ctx = withAuth(withTransport(withSpacing(ctx, cfg.Limits.RequestSpacing), cfg.Transport), cfg.Auth)
+		return jf.FetchJMAP(ctx, cfg.WebURL, cfg.WebUser, cfg.WebPass)
+	case config.SourceModeExec:
+		return openSource(ctx, ExecSource{Command: cfg.ExecCommand}, cfg)
 	default:
+		if s, ok := registeredSource(cfg.Mode); ok {
+			return openSource(ctx, s, cfg)
+		}
 		return nil, fmt.Errorf("%s: %q", config.ErrModeUnsupport, cfg.Mode)
 	}
 }
 
-// generateCalendar parses the vCard stream and constructs the iCalendar object.
+// generateCalendar parses the vCard stream and writes the iCalendar object to w.
 // It also builds the BirthdayEntry list for the UI.
-func (g *Generator) generateCalendar(ctx context.Context, r io.Reader, reminderTrigger string) ([]byte, []BirthdayEntry, int, error) {
+func (g *Generator) generateCalendar(ctx context.Context, r io.Reader, cfg SyncConfig, w io.Writer) ([]BirthdayEntry, int, error) {
 	cal := ical.NewCalendar()
 
 	// Set standard iCalendar headers
 	cal.Props.SetText(config.PropVersion, config.ICalVersion)
 	cal.Props.SetText(config.PropProdid, config.ICalProdid)
-	cal.Props.SetText(config.PropXWRCalName, config.ICalCalName)
+	calName := cfg.CalendarName
+	if calName == "" {
+		calName = config.ICalCalName
+	}
+	cal.Props.SetText(config.PropXWRCalName, calName)
+	cal.Props.SetText(config.PropName, calName)
+	if cfg.CalendarDesc != "" {
+		cal.Props.SetText(config.PropXWRCalDesc, cfg.CalendarDesc)
+		cal.Props.SetText(config.PropDescription, cfg.CalendarDesc)
+	}
+	if cfg.CalendarColor != "" {
+		cal.Props.SetText(config.PropColor, cfg.CalendarColor)
+	}
 	cal.Props.SetText(config.PropCalScale, config.ICalScale)
 	cal.Props.SetText(config.PropMethod, config.ICalMethod)
 
@@ -114,16 +274,50 @@ func (g *Generator) generateCalendar(ctx context.Context, r io.Reader, reminderT
 	// Birthdays are defined by the local calendar date of the person, not an absolute UTC timestamp.
 	// If it is June 15th in Tokyo, it is the user's birthday, even if it is still June 14th in UTC.
 	now := g.Clock.Now()
+	var zone *time.Location // Only set by the configuration, timed events are floating without it
+	if cfg.TimeZone != "" {
+		loc, err := time.LoadLocation(cfg.TimeZone)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: %q", config.ErrTimeZone, cfg.TimeZone)
+		}
+		now, zone = now.In(loc), loc
+		// All-day dates are floating by nature (RFC 5545, section 3.3.4) and timed events are
+		// written in UTC: no TZID refers to a VTIMEZONE. The zone is only advertised for clients.
+		cal.Props.SetText(config.PropXWRTimeZone, cfg.TimeZone)
+	}
+	var eventTime time.Time
+	if cfg.EventTime != "" {
+		t, err := time.Parse(config.EventTimeFormat, cfg.EventTime)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: %q", config.ErrEventTime, cfg.EventTime)
+		}
+		eventTime = t
+	}
+	eventDuration := cfg.EventDuration
+	if eventDuration <= 0 {
+		eventDuration = config.DefaultEventMins * time.Minute
+	}
 	dtStampProp := ical.NewProp(config.PropDTStamp)
 	dtStampProp.SetDateTime(now.UTC())
 
 	decoder := vcard.NewDecoder(r)
-	stats := struct{ processed, withBday, today int }{0, 0, 0}
+	limits := cfg.Limits.withDefaults()
+	stats := syncStats{}
+	var records []cardRecord
 	var contacts []BirthdayEntry
+	discovered := make(map[string]string) // lowercase -> first spelling seen
+
+	var days nameDays
+	if cfg.NameDays != "" {
+		var err error
+		if days, err = loadNameDays(cfg.NameDays); err != nil {
+			return nil, 0, err
+		}
+	}
 
 	for {
 		if ctx.Err() != nil {
-			return nil, nil, 0, ctx.Err()
+			return nil, 0, ctx.Err()
 		}
 
 		card, err := decoder.Decode()
@@ -131,6 +325,10 @@ func (g *Generator) generateCalendar(ctx context.Context, r io.Reader, reminderT
 			break
 		}
 		if err != nil {
+			// A source over the size limit is rejected rather than processed partially.
+			if errors.Is(err, ErrLimitExceeded) {
+				return nil, 0, err
+			}
 			// Log error but continue to next card to maximize data recovery
 			slog.Warn(config.MsgSkippedCard,
 				config.LogKeyComponent, config.CompEngine,
@@ -139,12 +337,36 @@ func (g *Generator) generateCalendar(ctx context.Context, r io.Reader, reminderT
 		}
 
 		stats.processed++
+		if stats.processed > limits.MaxContacts {
+			return nil, 0, fmt.Errorf("%w: "+config.ErrTooManyContacts, ErrLimitExceeded, limits.MaxContacts)
+		}
+		if g.Progress != nil && stats.processed%config.ProgressCards == 0 {
+			g.Progress(Progress{Bytes: bytesRead(r), Cards: stats.processed})
+		}
+		if size := cardSize(card); size > limits.MaxCardBytes {
+			slog.Warn(config.MsgSkippedLarge,
+				config.LogKeyComponent, config.CompEngine,
+				config.LogKeySizeBytes, size,
+				config.LogKeyMax, limits.MaxCardBytes)
+			continue
+		}
+
+		groups := cardGroups(card)
+		for _, grp := range groups {
+			if key := strings.ToLower(grp); discovered[key] == "" {
+				discovered[key] = grp
+			}
+		}
+		if !inCategories(groups, cfg.IncludeCategories) {
+			continue
+		}
+
 		bday := card.Get(config.VCardBDAY)
 		if bday == nil || bday.Value == "" {
 			continue
 		}
 
-		birthDate, yearKnown, err := parseDate(bday.Value)
+		birthDate, yearKnown, err := parseBirthday(bday)
 		if err != nil {
 			slog.Debug(config.MsgSkippedDate,
 				config.LogKeyComponent, config.CompEngine,
@@ -161,6 +383,28 @@ func (g *Generator) generateCalendar(ctx context.Context, r io.Reader, reminderT
 			name = n.Value
 		}
 
+		records = append(records, cardRecord{
+			card:      card,
+			name:      name,
+			birthDate: birthDate,
+			yearKnown: yearKnown,
+			groups:    groups,
+		})
+	}
+
+	// The same person may appear several times (e.g., a sloppy address book).
+	records, stats.merged = dedupRecords(records)
+
+	out := &calendarWriter{w: w, cal: cal}
+	revisions := make(Revisions)
+	class := cfg.Class
+	if class == "" {
+		class = config.DefaultEventClass
+	}
+
+	for _, rec := range records {
+		card, name, birthDate, yearKnown, groups := rec.card, rec.name, rec.birthDate, rec.yearKnown, rec.groups
+
 		// --- Logic 1: Prepare UI Data (Contact List) ---
 
 		// Deterministic UID generation for stability across refreshes
@@ -168,71 +412,293 @@ func (g *Generator) generateCalendar(ctx context.Context, r io.Reader, reminderT
 		hash := sha256.Sum256([]byte(input))
 		uidBase := fmt.Sprintf("%x", hash[:config.UIDHashLength])
 
+		// User corrections are keyed by the vCard UID, which survives a change of name or date,
+		// and by the hash without one. Event UIDs stay those of the original record.
+		overrideKey := cardUID(card)
+		if overrideKey == "" {
+			overrideKey = uidBase
+		}
+		raw, ok := cfg.Overrides[overrideKey]
+		if !ok {
+			raw, ok = cfg.Overrides[uidBase] // Saved by the hash before the vCard UID was used
+		}
+		overridden := false
+		if ok {
+			if d, yk, err := ParseDate(raw); err == nil {
+				birthDate, yearKnown, overridden = d, yk, true
+			} else {
+				slog.Debug(config.MsgSkippedDate,
+					config.LogKeyComponent, config.CompEngine,
+					config.LogKeyValue, raw)
+			}
+		}
+
+		// The UID, the duplicates and the name days keep the formal name.
+		display := name
+		if formatted := formatName(card, cfg.NameFormat); formatted != "" {
+			display = formatted
+		}
+		nickname := extractNickname(card)
+		if cfg.PreferNickname && nickname != "" {
+			display = nickname
+		}
+
 		// Calculate when the birthday occurs next (for sorting purposes)
-		nextOcc, ageNext := calculateNextOccurrence(now, birthDate, yearKnown)
+		nextOcc, ageNext := CalculateNextOccurrence(now, birthDate, yearKnown)
+
+		var photo *Photo
+		if cfg.PhotoMode != "" && cfg.PhotoMode != config.PhotoModeNone {
+			photo = extractPhoto(card)
+		}
+
+		// Birthdays stop after the year of death, when remembrance events replace them.
+		var death *customDate
+		lastYear := 0
+		if cfg.Memorials {
+			if death = extractDeathDate(card); death != nil && death.yearKnown {
+				lastYear = death.date.Year()
+			}
+		}
+
+		// A lunar birthday needs the year of birth to be converted.
+		occur := gregorian(birthDate, yearKnown, lastYear, now.Location())
+		lunarBirth, isLunar := toLunar(birthDate)
+		isLunar = isLunar && yearKnown && cfg.Lunar[uidBase]
+		if isLunar {
+			occur = lunar(lunarBirth, lastYear, now.Location())
+			if next, age, ok := nextOccurrence(now, occur); ok {
+				nextOcc, ageNext = next, age
+			}
+		}
 
 		contacts = append(contacts, BirthdayEntry{
 			UID:            uidBase,
-			Name:           name,
+			OverrideKey:    overrideKey,
+			Name:           display,
+			FullName:       name,
+			Nickname:       nickname,
+			Note:           strings.TrimSpace(card.PreferredValue(config.VCardNote)),
 			DateOfBirth:    birthDate,
 			YearKnown:      yearKnown,
 			NextOccurrence: nextOcc,
 			AgeNext:        ageNext,
+			Photo:          photo,
+			Categories:     groups,
+			Milestone:      yearKnown && isMilestone(ageNext, cfg),
+			Overridden:     overridden,
+			Hidden:         cfg.Hidden[uidBase],
+			Deceased:       lastYear != 0,
+			Lunar:          isLunar,
 		})
 
+		if cfg.Hidden[uidBase] {
+			continue
+		}
+
 		// --- Logic 2: Prepare ICS Events (Calendar) ---
 
-		events, isToday := g.createEvents(name, birthDate, yearKnown, reminderTrigger, now, uidBase)
+		events, isToday := g.createEvents(display, birthDate, yearKnown, occur, cfg, now, uidBase)
 		if isToday {
 			stats.today++
 			// DEBUG: Log explicitly WHO is triggering "today" for verification
 			slog.Info(config.MsgBdayToday,
 				config.LogKeyComponent, config.CompEngine,
-				config.LogKeyName, name,
+				config.LogKeyName, display,
 				config.LogKeyDOB, birthDate.Format(config.DateFormatFullDash))
 		}
 
+		if cfg.CustomDates {
+			for _, d := range extractCustomDates(card) {
+				events = append(events, g.createCustomEvents(display, d, cfg, now)...)
+			}
+		}
+		if death != nil {
+			events = append(events, g.createMemorialEvents(display, *death, cfg, now)...)
+		}
+		for _, o := range contactOffsets(cfg, uidBase) {
+			events = append(events, g.createOffsetEvents(display, birthDate, yearKnown, lastYear, o, cfg, now)...)
+		}
+		if days != nil && lastYear == 0 {
+			for _, d := range days.lookup(card, name) {
+				events = append(events, g.createNameDayEvents(display, d, cfg, now)...)
+			}
+		}
+
+		attachProp := buildAttachProp(photo, cfg.PhotoMode, cfg.PhotoBaseURL, uidBase)
+		var details string
+		var detailsURL *url.URL
+		if cfg.ContactDetails && lastYear == 0 {
+			details, detailsURL = contactDetails(card)
+		}
+		categoriesProp := buildCategoriesProp(cfg, groups)
+
 		for _, e := range events {
+			if cfg.UIDDomain != "" {
+				setUIDDomain(e, cfg.UIDDomain)
+			}
+			if cfg.EventTime != "" {
+				setEventTime(e, eventTime, eventDuration, zone)
+			}
 			e.Props.Set(dtStampProp)
-			cal.Children = append(cal.Children, e.Component)
+			if attachProp != nil {
+				e.Props.Set(attachProp)
+			}
+			if categoriesProp != nil {
+				e.Props.Set(categoriesProp)
+			}
+			if cfg.Color != "" {
+				e.Props.SetText(config.PropColor, cfg.Color)
+			}
+			if details != "" {
+				e.Props.SetText(config.PropDescription, details)
+			}
+			if detailsURL != nil {
+				e.Props.SetURI(config.PropURL, detailsURL)
+			}
+			// Birthdays must not show as busy time in scheduling tools.
+			e.Props.SetText(config.PropTransp, config.ICalTransparent)
+			e.Props.SetText(config.PropStatus, config.ICalConfirmed)
+			e.Props.SetText(config.PropClass, class)
+			g.Revisions.setRevision(e, now, revisions)
+			if err := out.writeEvent(e); err != nil {
+				return nil, 0, fmt.Errorf("%s: %w", config.ErrICalEncode, err)
+			}
 		}
 	}
 
-	// Handle case where no events are found.
-	if len(cal.Children) == 0 {
-		var buf bytes.Buffer
-		// Use the constant stub to ensure a valid VCALENDAR is returned even if empty.
-		// This prevents clients from flagging the feed as invalid.
-		fmt.Fprintf(&buf, config.StubVCalendar)
-
-		g.logSuccess(stats)
-		return buf.Bytes(), contacts, 0, nil
+	g.Groups = make([]string, 0, len(discovered))
+	for _, grp := range discovered {
+		g.Groups = append(g.Groups, grp)
 	}
+	sort.Slice(g.Groups, func(i, j int) bool {
+		return strings.ToLower(g.Groups[i]) < strings.ToLower(g.Groups[j])
+	})
 
-	var buf bytes.Buffer
-	if err := ical.NewEncoder(&buf).Encode(cal); err != nil {
-		return nil, nil, 0, fmt.Errorf("%s: %w", config.ErrICalEncode, err)
+	if err := out.close(); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", config.ErrICalEncode, err)
 	}
 
+	g.Revisions = revisions
 	g.logSuccess(stats)
-	return buf.Bytes(), contacts, stats.today, nil
+	return contacts, stats.today, nil
+}
+
+// setUIDDomain replaces the default domain of the UID of e, so that the calendars of
+// several instances (e.g., family and work) never share events.
+func setUIDDomain(e *ical.Event, domain string) {
+	if uid, err := e.Props.Text(config.PropUID); err == nil {
+		base, _ := strings.CutSuffix(uid, "@"+config.ICalDomain)
+		e.Props.SetText(config.PropUID, base+"@"+domain)
+	}
+}
+
+// setEventTime turns the all-day event e into a timed one starting at the time of day of start
+// and lasting d, so that phone reminders fire at a known hour. Without zone, times are floating
+// (the same wall clock time wherever the reader is, RFC 5545, section 3.3.5); with one, they
+// are written in UTC so that no VTIMEZONE is needed.
+func setEventTime(e *ical.Event, start time.Time, d time.Duration, zone *time.Location) {
+	prop := e.Props.Get(config.PropDTStart)
+	if prop == nil {
+		return
+	}
+	day, err := prop.DateTime(time.UTC)
+	if err != nil {
+		return
+	}
+	loc := zone
+	if loc == nil {
+		loc = time.UTC // Only used for the arithmetic, the zone is left out below
+	}
+	begin := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+	end := begin.Add(d)
+
+	set := func(name string, t time.Time) {
+		p := ical.NewProp(name)
+		if zone != nil {
+			p.SetDateTime(t.UTC())
+		} else {
+			p.SetValueType(ical.ValueDateTime)
+			p.Value = t.Format(config.ICalFloatingTime)
+		}
+		e.Props.Set(p)
+	}
+	set(config.PropDTStart, begin)
+	set(config.PropDTEnd, end)
 }
 
+// ValidUIDDomain reports whether s can be the domain of event UIDs: letters, digits,
+// dots and hyphens, starting and ending with a letter or a digit.
+func ValidUIDDomain(s string) bool {
+	if s == "" || strings.ContainsAny(s[:1]+s[len(s)-1:], ".-") {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// calendarWriter streams a VCALENDAR one event at a time.
+// go-ical only encodes whole calendars, so each event is encoded inside cal on its own
+// and only its lines are kept, after the calendar header for the first one.
+type calendarWriter struct {
+	w      io.Writer
+	cal    *ical.Calendar // Header properties, without children
+	buf    bytes.Buffer   // Reused for every event
+	events int
+}
+
+func (cw *calendarWriter) writeEvent(e *ical.Event) error {
+	cw.cal.Children = []*ical.Component{e.Component}
+	defer func() { cw.cal.Children = nil }()
+
+	cw.buf.Reset()
+	if err := ical.NewEncoder(&cw.buf).Encode(cw.cal); err != nil {
+		return err
+	}
+	data := cw.buf.Bytes()
+	data = data[:len(data)-len(config.ICalCalendarEnd)]
+	if cw.events > 0 {
+		// Header property values cannot contain line breaks, so the first match is the event.
+		data = data[bytes.Index(data, []byte(config.ICalEventBegin))+len(config.ICalLineBreak):]
+	}
+	cw.events++
+	_, err := cw.w.Write(FoldLines(data))
+	return err
+}
+
+// close ends the calendar. Without events, the constant stub keeps the feed valid,
+// which prevents clients from flagging it as invalid.
+func (cw *calendarWriter) close() error {
+	end := config.ICalCalendarEnd
+	if cw.events == 0 {
+		end = config.StubVCalendar
+	}
+	_, err := io.WriteString(cw.w, end)
+	return err
+}
+
+// syncStats counts the cards seen at each stage of the generation process.
+type syncStats struct{ processed, withBday, merged, today int }
+
 // logSuccess logs the final statistics of the generation process.
-func (g *Generator) logSuccess(stats struct{ processed, withBday, today int }) {
+func (g *Generator) logSuccess(stats syncStats) {
 	slog.Info(config.MsgGenSuccess,
 		config.LogKeyComponent, config.CompEngine,
 		slog.Group(config.LogKeyStats,
 			slog.Int(config.LogKeyTotal, stats.processed),
 			slog.Int(config.LogKeyFound, stats.withBday),
+			slog.Int(config.LogKeyMerged, stats.merged),
 			slog.Int(config.LogKeyToday, stats.today),
 		),
 	)
 }
 
-// calculateNextOccurrence determines the next birthday date relative to 'now'.
+// CalculateNextOccurrence determines the next birthday date relative to 'now', and the age turned then.
 // This is used primarily for sorting the contact list.
-func calculateNextOccurrence(now time.Time, birthDate time.Time, yearKnown bool) (time.Time, int) {
+func CalculateNextOccurrence(now time.Time, birthDate time.Time, yearKnown bool) (time.Time, int) {
 	currentYear := now.Year()
 	// Fix: Use the location of 'now' to ensure timezone consistency
 	loc := now.Location()
@@ -257,15 +723,72 @@ func calculateNextOccurrence(now time.Time, birthDate time.Time, yearKnown bool)
 	return candidate, ageNext
 }
 
-// createEvents generates calendar events for CurrentYear-1, CurrentYear, and CurrentYear+1.
-// It ensures no events are created before the person is born.
-func (g *Generator) createEvents(name string, birthDate time.Time, yearKnown bool, reminderTrigger string, now time.Time, uidBase string) ([]*ical.Event, bool) {
+// createEvents generates the birthday events of the anniversaries of occur (see gregorian and lunar)
+// for CurrentYear-1, CurrentYear, and CurrentYear+1.
+func (g *Generator) createEvents(name string, birthDate time.Time, yearKnown bool, occur occurrence, cfg SyncConfig, now time.Time, uidBase string) ([]*ical.Event, bool) {
+	return anniversaryEvents(occur, now, uidBase, func(age int) (string, []string) {
+		// Generate localized summary
+		summary := fmt.Sprintf(config.FallbackSummary, name)
+		if g.FormatSummary != nil {
+			// Pass 'age' to formatter. If age is 0 and year is known, formatter should handle "(Birth)".
+			summary = g.FormatSummary(name, age, yearKnown && age >= 0)
+		}
+
+		triggers := cfg.ReminderTriggers
+		if yearKnown && isMilestone(age, cfg) {
+			if cfg.MilestonePrefix != "" {
+				summary = cfg.MilestonePrefix + " " + summary
+			}
+			if cfg.MilestoneTrigger != "" {
+				triggers = append(append([]string{}, triggers...), cfg.MilestoneTrigger)
+			}
+		}
+		if cfg.ZodiacSummary {
+			summary += " " + config.ZodiacEmojis[ZodiacSign(birthDate)]
+		}
+		return summary, triggers
+	})
+}
+
+// occurrence returns the date of the anniversary of year y and the number of years elapsed
+// since the original date (0 if unknown), or false if there is no anniversary that year.
+type occurrence func(y int) (time.Time, int, bool)
+
+// gregorian returns the anniversaries of date in the Gregorian calendar, in loc.
+// There are none before the original date (e.g., person not born yet), nor after lastYear unless it is 0.
+func gregorian(date time.Time, yearKnown bool, lastYear int, loc *time.Location) occurrence {
+	return func(y int) (time.Time, int, bool) {
+		if yearKnown && y < date.Year() {
+			return time.Time{}, 0, false
+		}
+		if lastYear != 0 && y > lastYear {
+			return time.Time{}, 0, false
+		}
+		years := 0
+		if yearKnown {
+			years = y - date.Year()
+		}
+		// Date Normalization
+		return time.Date(y, date.Month(), date.Day(), 0, 0, 0, 0, loc), years, true
+	}
+}
+
+// yearlyEvents generates one all-day event per year for CurrentYear-1, CurrentYear, and CurrentYear+1
+// on the Gregorian anniversaries of date, up to lastYear unless it is 0.
+// The details callback receives the number of years elapsed since 'date' (0 if unknown)
+// and returns the event summary and its alarm triggers.
+// It also reports whether one of the events falls today.
+func yearlyEvents(date time.Time, yearKnown bool, lastYear int, now time.Time, uidBase string, details func(years int) (string, []string)) ([]*ical.Event, bool) {
+	return anniversaryEvents(gregorian(date, yearKnown, lastYear, now.Location()), now, uidBase, details)
+}
+
+// anniversaryEvents is yearlyEvents for the anniversaries given by occur.
+func anniversaryEvents(occur occurrence, now time.Time, uidBase string, details func(years int) (string, []string)) ([]*ical.Event, bool) {
 	currentYear := now.Year()
 	// Requirement: Generate for Previous Year, Current Year, Next Year (3 years total)
 	// This ensures that when a user scrolls back or forward in their calendar app,
 	// the events are present without needing an immediate re-sync.
 	targetYears := []int{currentYear - 1, currentYear, currentYear + 1}
-	loc := now.Location()
 
 	var events []*ical.Event
 	isToday := false
@@ -273,31 +796,18 @@ func (g *Generator) createEvents(name string, birthDate time.Time, yearKnown boo
 	todayYear, todayMonth, todayDay := now.Date()
 
 	for _, y := range targetYears {
-		// Guard: Do not generate an event if the person is not born yet in year 'y'.
-		if yearKnown && y < birthDate.Year() {
+		eventDate, years, ok := occur(y)
+		if !ok {
 			continue
 		}
 
 		event := ical.NewEvent()
 		event.Props.SetText(config.PropUID, fmt.Sprintf(config.FormatUID, uidBase, y, config.ICalDomain))
 
-		age := 0
-		if yearKnown {
-			age = y - birthDate.Year()
-		}
-
-		// Generate localized summary
-		summary := fmt.Sprintf(config.FallbackSummary, name)
-		if g.FormatSummary != nil {
-			// Pass 'age' to formatter. If age is 0 and year is known, formatter should handle "(Birth)".
-			summary = g.FormatSummary(name, age, yearKnown && age >= 0)
-		}
+		summary, triggers := details(years)
 		event.Props.SetText(config.PropSummary, summary)
 
-		// Date Normalization
-		eventDate := time.Date(y, birthDate.Month(), birthDate.Day(), 0, 0, 0, 0, loc)
-
-		if y == todayYear && eventDate.Month() == todayMonth && eventDate.Day() == todayDay {
+		if eventDate.Year() == todayYear && eventDate.Month() == todayMonth && eventDate.Day() == todayDay {
 			isToday = true
 		}
 
@@ -306,8 +816,10 @@ func (g *Generator) createEvents(name string, birthDate time.Time, yearKnown boo
 		dtStartProp.SetDate(eventDate)
 		event.Props.Set(dtStartProp)
 
-		if reminderTrigger != "" {
-			addAlarm(event, reminderTrigger, summary)
+		for _, trigger := range triggers {
+			if trigger != "" {
+				addAlarm(event, trigger, summary)
+			}
 		}
 
 		events = append(events, event)
@@ -315,6 +827,134 @@ func (g *Generator) createEvents(name string, birthDate time.Time, yearKnown boo
 	return events, isToday
 }
 
+// isMilestone reports whether turning 'age' deserves special emphasis:
+// either an explicitly listed age or a multiple of MilestoneEvery.
+// The birth itself (age 0) is never a milestone.
+func isMilestone(age int, cfg SyncConfig) bool {
+	if age <= 0 {
+		return false
+	}
+	if cfg.MilestoneEvery > 0 && age%cfg.MilestoneEvery == 0 {
+		return true
+	}
+	for _, m := range cfg.Milestones {
+		if m == age {
+			return true
+		}
+	}
+	return false
+}
+
+// buildCategoriesProp merges the configured categories with the contact's own
+// vCard groups (when enabled), dropping blanks and case-insensitive duplicates.
+// It returns nil if there is nothing to emit.
+func buildCategoriesProp(cfg SyncConfig, groups []string) *ical.Prop {
+	candidates := cfg.Categories
+	if cfg.ContactGroups {
+		candidates = append(append([]string{}, cfg.Categories...), groups...)
+	}
+
+	seen := make(map[string]bool)
+	var categories []string
+	for _, c := range candidates {
+		c = strings.TrimSpace(c)
+		key := strings.ToLower(c)
+		if c == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		categories = append(categories, c)
+	}
+
+	if len(categories) == 0 {
+		return nil
+	}
+
+	prop := ical.NewProp(config.PropCategories)
+	prop.SetTextList(categories)
+	return prop
+}
+
+// cardGroups returns the trimmed, non-empty vCard CATEGORIES of a card.
+// go-vcard yields a single empty value when the property is absent.
+func cardGroups(card vcard.Card) []string {
+	var groups []string
+	for _, grp := range card.Categories() {
+		if grp = strings.TrimSpace(grp); grp != "" {
+			groups = append(groups, grp)
+		}
+	}
+	return groups
+}
+
+// contactDetails returns the preferred email, phone number and NOTE of a card, one per line,
+// and a URL to call (tel:) or else to write to (mailto:) the contact. Both are empty without details.
+func contactDetails(card vcard.Card) (string, *url.URL) {
+	email := strings.TrimSpace(card.PreferredValue(config.VCardEmail))
+	// vCard 4 phone numbers may already be tel: URIs.
+	tel := strings.TrimSpace(strings.TrimPrefix(card.PreferredValue(config.VCardTel), config.URISchemeTel+":"))
+	note := strings.TrimSpace(card.PreferredValue(config.VCardNote))
+
+	var lines []string
+	for _, v := range []string{email, tel, note} {
+		if v != "" {
+			lines = append(lines, v)
+		}
+	}
+
+	var u *url.URL
+	switch {
+	case tel != "":
+		u = &url.URL{Scheme: config.URISchemeTel, Opaque: strings.ReplaceAll(tel, " ", "")}
+	case email != "":
+		u = &url.URL{Scheme: config.URISchemeMailto, Opaque: email}
+	}
+	return strings.Join(lines, config.DetailSeparator), u
+}
+
+// extractNickname returns the first of the vCard NICKNAME values (e.g., "Bob" of "Bob,Bobby"), or "".
+func extractNickname(card vcard.Card) string {
+	nickname, _, _ := strings.Cut(card.PreferredValue(config.VCardNickname), ",")
+	return strings.TrimSpace(nickname)
+}
+
+// formatName builds the name of a contact from its structured N in the given config.NameFormat
+// (e.g., "Doe, Jane"), or returns "" for config.NameFormatFN or without N.
+func formatName(card vcard.Card, format string) string {
+	if format != config.NameFormatGivenFamily && format != config.NameFormatFamilyGiven {
+		return ""
+	}
+	n := card.Name()
+	if n == nil {
+		return ""
+	}
+	given, family := strings.TrimSpace(n.GivenName), strings.TrimSpace(n.FamilyName)
+	switch {
+	case given == "" || family == "":
+		return given + family // A single name reads the same in both orders
+	case format == config.NameFormatFamilyGiven:
+		return fmt.Sprintf(config.FormatFamilyGiven, family, given)
+	default:
+		return given + " " + family
+	}
+}
+
+// inCategories reports whether a contact belonging to groups passes the include filter.
+// An empty filter accepts every contact. Matching is case-insensitive.
+func inCategories(groups, include []string) bool {
+	if len(include) == 0 {
+		return true
+	}
+	for _, grp := range groups {
+		for _, inc := range include {
+			if strings.EqualFold(strings.TrimSpace(grp), strings.TrimSpace(inc)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // addAlarm appends a DISPLAY alarm (notification) to the event.
 func addAlarm(event *ical.Event, trigger, description string) {
 	alarm := ical.NewComponent(config.ICalComponent)
@@ -329,8 +969,28 @@ func addAlarm(event *ical.Event, trigger, description string) {
 	event.Children = append(event.Children, alarm)
 }
 
-// parseDate handles various vCard date formats.
-func parseDate(value string) (time.Time, bool, error) {
+// parseBirthday parses a BDAY field, honoring Apple's X-APPLE-OMIT-YEAR parameter:
+// when it matches the year of the date, the year is a placeholder and is dropped.
+func parseBirthday(field *vcard.Field) (time.Time, bool, error) {
+	t, yearKnown, err := ParseDate(field.Value)
+	if err != nil || !yearKnown {
+		return t, yearKnown, err
+	}
+
+	if omit := field.Params.Get(config.VCardParamAppleOmitYear); omit != "" && omit == strconv.Itoa(t.Year()) {
+		return withoutYear(t), false, nil
+	}
+	return t, true, nil
+}
+
+// withoutYear moves a date to the leap year fallback, for birthdays with an unknown year.
+func withoutYear(t time.Time) time.Time {
+	return time.Date(config.DefaultLeapYear, t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ParseDate handles various vCard date formats, reporting whether the year is known.
+// Apple's sentinel year (1604) is treated as an unknown year even without the parameter.
+func ParseDate(value string) (time.Time, bool, error) {
 	// Full dates (Year known)
 	formatsWithYear := []string{
 		config.DateFormatFullDash,
@@ -341,6 +1001,9 @@ func parseDate(value string) (time.Time, bool, error) {
 
 	for _, f := range formatsWithYear {
 		if t, err := time.Parse(f, value); err == nil {
+			if t.Year() == config.AppleOmitYear {
+				return withoutYear(t), false, nil
+			}
 			return t, true, nil
 		}
 	}
@@ -350,8 +1013,7 @@ func parseDate(value string) (time.Time, bool, error) {
 	formatsWithoutYear := []string{config.DateFormatNoYearD, config.DateFormatNoYearB}
 	for _, f := range formatsWithoutYear {
 		if t, err := time.Parse(f, value); err == nil {
-			safeDate := time.Date(config.DefaultLeapYear, t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
-			return safeDate, false, nil
+			return withoutYear(t), false, nil
 		}
 	}
 
@@ -1,17 +1,21 @@
 package engine_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/emersion/go-ical"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/tartampluch/go-birthday/internal/config"
 	"github.com/tartampluch/go-birthday/internal/engine"
 )
@@ -231,11 +235,11 @@ func TestRunSync_WithReminders(t *testing.T) {
 		Fetcher: mockFetcher,
 	}
 
-	// ReminderTrigger "-P1D" means 1 day before
+	// "-P7D" means 1 week before, "-P1D" means 1 day before
 	cfg := engine.SyncConfig{
-		Mode:            config.SourceModeWeb,
-		WebURL:          "http://test.local",
-		ReminderTrigger: "-P1D",
+		Mode:             config.SourceModeWeb,
+		WebURL:           "http://test.local",
+		ReminderTriggers: []string{"-P7D", "-P1D"},
 	}
 
 	icsData, _, _, err := gen.RunSync(context.Background(), cfg)
@@ -243,8 +247,12 @@ func TestRunSync_WithReminders(t *testing.T) {
 
 	icsStr := string(icsData)
 	assert.Contains(t, icsStr, "BEGIN:VALARM", "ICS should contain an alarm component")
-	assert.Contains(t, icsStr, "TRIGGER:-P1D", "Alarm trigger should match configuration")
+	assert.Contains(t, icsStr, "TRIGGER:-P7D", "First alarm trigger should match configuration")
+	assert.Contains(t, icsStr, "TRIGGER:-P1D", "Second alarm trigger should match configuration")
 	assert.Contains(t, icsStr, "ACTION:DISPLAY", "Alarm action should be DISPLAY")
+
+	// One VALARM per trigger, for each of the 3 generated years.
+	assert.Equal(t, 6, strings.Count(icsStr, "BEGIN:VALARM"))
 }
 
 func TestRunSync_GeneratesYearRange(t *testing.T) {
@@ -409,3 +417,1236 @@ func TestRunSync_ContextCancellation(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, context.Canceled, err, "Should return context canceled error")
 }
+
+// TestRunSync_ValidICS verifies that long Unicode names, text to escape and inline photos
+// produce a calendar strict clients accept: folded lines, escaped text, mandatory properties.
+func TestRunSync_ValidICS(t *testing.T) {
+	photo := "/9j/4AAA" + strings.Repeat("AAAA", 60)
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nUID:a\nFN:Zoë-Élodie 🎂🎉🎈 de la Fontaine\\, Jr.; la Troisième du Nom\nBDAY:1990-06-01\n" +
+		"PHOTO;ENCODING=b;TYPE=JPEG:" + photo + "\nEND:VCARD"
+	mockFetcher := new(MockFetcher)
+	mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+
+	gen := &engine.Generator{
+		Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Fetcher: mockFetcher,
+	}
+	cfg := engine.SyncConfig{
+		Mode:             config.SourceModeWeb,
+		WebURL:           "http://x",
+		PhotoMode:        config.PhotoModeInline,
+		ReminderTriggers: []string{"-P1D"},
+	}
+	icsData, _, _, err := gen.RunSync(context.Background(), cfg)
+	require.NoError(t, err)
+
+	issues, err := engine.ValidateICS(bytes.NewReader(icsData))
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+	assert.Contains(t, string(icsData), "\r\n ", "Long lines are folded")
+
+	// Folding is transparent to parsers.
+	cal, err := ical.NewDecoder(bytes.NewReader(icsData)).Decode()
+	require.NoError(t, err)
+	summary, err := cal.Events()[0].Props.Text(config.PropSummary)
+	require.NoError(t, err)
+	assert.Contains(t, summary, "Zoë-Élodie 🎂🎉🎈 de la Fontaine, Jr.; la Troisième du Nom")
+	attach := cal.Events()[0].Props.Get(config.PropAttach)
+	require.NotNil(t, attach)
+	assert.Equal(t, photo, attach.Value)
+}
+
+// TestValidateICS verifies that the problems breaking strict clients are reported.
+func TestValidateICS(t *testing.T) {
+	event := func(lines ...string) string {
+		return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//x//y//EN\r\nBEGIN:VEVENT\r\n" +
+			strings.Join(lines, "\r\n") + "\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	}
+	valid := []string{"UID:1", "DTSTAMP:20250101T000000Z", "DTSTART;VALUE=DATE:20250601"}
+
+	tests := []struct {
+		name   string
+		ics    string
+		reason string
+	}{
+		{"Valid", event(append(valid, "SUMMARY:Jane\\, Jr.", "DESCRIPTION;LANGUAGE=\"a:b\":x\\nMore\\;")...), ""},
+		{"Folded", event(append(valid, "SUMMARY:"+strings.Repeat("é", 30)+"\r\n "+strings.Repeat("é", 10))...), ""},
+		{"Bare LF", strings.ReplaceAll(event(valid...), "\r\n", "\n"), config.ErrICSLineBreak},
+		{"Too long", event(append(valid, "SUMMARY:"+strings.Repeat("x", 70))...), config.ErrICSLineLength},
+		{"Folded inside a character", event(append(valid, "SUMMARY:é\xc3\r\n \xa9")...), config.ErrICSUTF8},
+		{"Missing UID", event(valid[1:]...), config.ErrICSMissingProp},
+		{"Duplicate DTSTART", event(append(valid, valid[2])...), config.ErrICSDuplicateProp},
+		{"Unescaped comma", event(append(valid, "SUMMARY:Jane, Jr.")...), config.ErrICSUnescaped},
+		{"Bad escape", event(append(valid, "SUMMARY:C:\\Users")...), config.ErrICSBadEscape},
+		{"Alarm without description", event(append(valid, "BEGIN:VALARM", "ACTION:DISPLAY", "TRIGGER:-P1D", "END:VALARM")...), config.ErrICSMissingProp},
+		{"Unbalanced", strings.TrimSuffix(event(valid...), "END:VCALENDAR\r\n"), config.ErrICSNesting},
+		{"Malformed", event(append(valid, "SUMMARY")...), config.ErrICSSyntax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := engine.ValidateICS(strings.NewReader(tt.ics))
+			require.NoError(t, err)
+			if tt.reason == "" {
+				assert.Empty(t, issues)
+				return
+			}
+			require.NotEmpty(t, issues)
+			assert.Contains(t, issues[0].Reason, tt.reason)
+		})
+	}
+}
+
+func TestRunSync_Photos(t *testing.T) {
+	// Scenario: vCard 3.0 inline JPEG and vCard 4.0 data URI photos.
+	// "/9j/4A==" decodes to the JPEG magic bytes FF D8 FF E0.
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nUID:a\nFN:Photo Three\nBDAY:1990-06-01\nPHOTO;ENCODING=b;TYPE=JPEG:/9j/4A==\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:4.0\nUID:b\nFN:Photo Four\nBDAY:1990-06-02\nPHOTO:data:image/png;base64,iVBORw==\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:4.0\nUID:c\nFN:No Photo\nBDAY:1990-06-03\nEND:VCARD"
+
+	tests := []struct {
+		name     string
+		mode     string
+		contains []string
+		absent   []string
+	}{
+		{
+			name:   "Disabled",
+			mode:   config.PhotoModeNone,
+			absent: []string{"ATTACH"},
+		},
+		{
+			name: "Inline",
+			mode: config.PhotoModeInline,
+			contains: []string{
+				"ATTACH;ENCODING=BASE64;FMTTYPE=image/jpeg;VALUE=BINARY:/9j/4A==",
+				"ATTACH;ENCODING=BASE64;FMTTYPE=image/png;VALUE=BINARY:iVBORw==",
+			},
+		},
+		{
+			name:     "Link",
+			mode:     config.PhotoModeLink,
+			contains: []string{"ATTACH;FMTTYPE=image/jpeg:http://127.0.0.1:18080/photos/"},
+			absent:   []string{"VALUE=BINARY"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFetcher := new(MockFetcher)
+			mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+
+			gen := &engine.Generator{
+				Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+				Fetcher: mockFetcher,
+			}
+
+			icsData, contacts, _, err := gen.RunSync(context.Background(), engine.SyncConfig{
+				Mode:         config.SourceModeWeb,
+				WebURL:       "http://test.local",
+				PhotoMode:    tt.mode,
+				PhotoBaseURL: "http://127.0.0.1:18080",
+			})
+			assert.NoError(t, err)
+			assert.Len(t, contacts, 3)
+
+			icsStr := string(icsData)
+			for _, c := range tt.contains {
+				assert.Contains(t, icsStr, c)
+			}
+			for _, a := range tt.absent {
+				assert.NotContains(t, icsStr, a)
+			}
+			if tt.mode != config.PhotoModeNone {
+				// 2 contacts with photos x 3 years
+				assert.Equal(t, 6, strings.Count(icsStr, "ATTACH"))
+			}
+		})
+	}
+}
+
+func TestRunSync_CategoriesAndColor(t *testing.T) {
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nFN:Grouped\nBDAY:1990-06-01\nCATEGORIES:Family,birthday\nEND:VCARD"
+
+	tests := []struct {
+		name     string
+		cfg      engine.SyncConfig
+		contains []string
+		absent   []string
+	}{
+		{
+			name:     "Static Category Only",
+			cfg:      engine.SyncConfig{Categories: []string{"Birthday"}},
+			contains: []string{"CATEGORIES:Birthday\r\n"},
+			absent:   []string{"COLOR", "Family"},
+		},
+		{
+			name:     "Merged Contact Groups",
+			cfg:      engine.SyncConfig{Categories: []string{"Birthday"}, ContactGroups: true, Color: "teal"},
+			contains: []string{"CATEGORIES:Birthday,Family\r\n", "COLOR:teal"},
+		},
+		{
+			name:     "Nothing Configured",
+			cfg:      engine.SyncConfig{},
+			contains: []string{"TRANSP:TRANSPARENT", "STATUS:CONFIRMED", "CLASS:PRIVATE"},
+			absent:   []string{"CATEGORIES", "COLOR"},
+		},
+		{
+			name:   "Contact Details Off",
+			cfg:    engine.SyncConfig{},
+			absent: []string{"DESCRIPTION:", "URL"},
+		},
+		{
+			name:     "Public Class",
+			cfg:      engine.SyncConfig{Class: config.ICalClassPublic},
+			contains: []string{"TRANSP:TRANSPARENT", "CLASS:PUBLIC"},
+			absent:   []string{"CLASS:PRIVATE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFetcher := new(MockFetcher)
+			mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+
+			gen := &engine.Generator{
+				Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+				Fetcher: mockFetcher,
+			}
+
+			tt.cfg.Mode = config.SourceModeWeb
+			tt.cfg.WebURL = "http://test.local"
+			icsData, contacts, _, err := gen.RunSync(context.Background(), tt.cfg)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"Family", "birthday"}, contacts[0].Categories)
+
+			icsStr := string(icsData)
+			for _, c := range tt.contains {
+				assert.Contains(t, icsStr, c)
+			}
+			for _, a := range tt.absent {
+				assert.NotContains(t, icsStr, a)
+			}
+		})
+	}
+}
+
+// TestRunSync_Revisions verifies that SEQUENCE and LAST-MODIFIED only move when an event changes.
+func TestRunSync_Revisions(t *testing.T) {
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nFN:Jane\nBDAY:1990-06-01\nEND:VCARD"
+	first := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	gen := &engine.Generator{Clock: MockClock{CurrentTime: first}}
+	cfg := engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://x"}
+	sync := func() string {
+		t.Helper()
+		mockFetcher := new(MockFetcher)
+		mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+		gen.Fetcher = mockFetcher
+		icsData, _, _, err := gen.RunSync(context.Background(), cfg)
+		require.NoError(t, err)
+		return string(icsData)
+	}
+
+	ics := sync()
+	assert.Equal(t, strings.Count(ics, "BEGIN:VEVENT"), strings.Count(ics, "SEQUENCE:0\r\n"))
+	assert.Contains(t, ics, "LAST-MODIFIED:20250101T000000Z")
+	require.NotEmpty(t, gen.Revisions)
+
+	// Unchanged: same revision, even on another day.
+	gen.Clock = MockClock{CurrentTime: first.Add(time.Hour)}
+	ics = sync()
+	assert.NotContains(t, ics, "SEQUENCE:1")
+	assert.NotContains(t, ics, "LAST-MODIFIED:20250101T010000Z")
+
+	// Changed: the UID stays, the revision moves.
+	gen.Clock = MockClock{CurrentTime: first.Add(2 * time.Hour)}
+	cfg.Color = "teal"
+	ics = sync()
+	assert.Equal(t, strings.Count(ics, "BEGIN:VEVENT"), strings.Count(ics, "SEQUENCE:1\r\n"))
+	assert.Contains(t, ics, "LAST-MODIFIED:20250101T020000Z")
+	assert.NotContains(t, ics, "LAST-MODIFIED:20250101T000000Z")
+}
+
+// TestRunSync_CalendarMetadata verifies the calendar name, description, color and UID domain.
+func TestRunSync_CalendarMetadata(t *testing.T) {
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nFN:Jane\nBDAY:1990-06-01\nEND:VCARD"
+
+	tests := []struct {
+		name     string
+		cfg      engine.SyncConfig
+		contains []string
+		absent   []string
+	}{
+		{
+			name:     "Defaults",
+			contains: []string{"X-WR-CALNAME;VALUE=TEXT:Birthdays\r\n", "NAME:Birthdays\r\n", "@gobirthday\r\n"},
+			absent:   []string{"X-WR-CALDESC", "COLOR"},
+		},
+		{
+			name: "Custom",
+			cfg:  engine.SyncConfig{CalendarName: "Work birthdays", CalendarDesc: "Team, HR", CalendarColor: "teal", UIDDomain: "work.example"},
+			contains: []string{"X-WR-CALNAME;VALUE=TEXT:Work birthdays\r\n", "NAME:Work birthdays\r\n",
+				"X-WR-CALDESC;VALUE=TEXT:Team\\, HR\r\n", "DESCRIPTION:Team\\, HR\r\n", "COLOR:teal\r\n", "@work.example\r\n"},
+			absent: []string{"@gobirthday"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFetcher := new(MockFetcher)
+			mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+
+			gen := &engine.Generator{
+				Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+				Fetcher: mockFetcher,
+			}
+			tt.cfg.Mode = config.SourceModeWeb
+			tt.cfg.WebURL = "http://x"
+			icsData, _, _, err := gen.RunSync(context.Background(), tt.cfg)
+			require.NoError(t, err)
+
+			icsStr := string(icsData)
+			for _, c := range tt.contains {
+				assert.Contains(t, icsStr, c)
+			}
+			for _, a := range tt.absent {
+				assert.NotContains(t, icsStr, a)
+			}
+		})
+	}
+
+	assert.True(t, engine.ValidUIDDomain("family-2.example"))
+	for _, s := range []string{"", "-x", "x.", "a b", "a@b", "é"} {
+		assert.False(t, engine.ValidUIDDomain(s), s)
+	}
+}
+
+// TestRunSync_TimeZone verifies that "today" follows the configured zone rather than the clock's.
+func TestRunSync_TimeZone(t *testing.T) {
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nFN:Jane\nBDAY:1990-06-01\nEND:VCARD"
+	// Still May 31 in UTC, already June 1 in Tokyo.
+	utc := time.Date(2025, 5, 31, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		zone      string
+		wantToday int
+		wantErr   bool
+	}{
+		{"Clock Zone", "", 0, false},
+		{"Tokyo", "Asia/Tokyo", 1, false},
+		{"Unknown", "Mars/Olympus", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFetcher := new(MockFetcher)
+			mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+
+			gen := &engine.Generator{Clock: MockClock{CurrentTime: utc}, Fetcher: mockFetcher}
+			cfg := engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://x", TimeZone: tt.zone}
+			icsData, contacts, count, err := gen.RunSync(context.Background(), cfg)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, config.ErrTimeZone)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantToday, count)
+			require.Len(t, contacts, 1)
+			assert.Equal(t, 2025, contacts[0].NextOccurrence.Year(), "The birthday is not over yet")
+			if tt.zone != "" {
+				assert.Contains(t, string(icsData), "X-WR-TIMEZONE;VALUE=TEXT:"+tt.zone)
+			} else {
+				assert.NotContains(t, string(icsData), "X-WR-TIMEZONE")
+			}
+		})
+	}
+}
+
+// TestRunSync_TimedEvents verifies floating and zoned timed events instead of all-day dates.
+func TestRunSync_TimedEvents(t *testing.T) {
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nFN:Jane\nBDAY:1990-06-01\nEND:VCARD"
+
+	tests := []struct {
+		name     string
+		cfg      engine.SyncConfig
+		contains []string
+		absent   []string
+		wantErr  bool
+	}{
+		{
+			name:     "All Day",
+			contains: []string{"DTSTART;VALUE=DATE:20250601\r\n"},
+			absent:   []string{"DTEND"},
+		},
+		{
+			name:     "Floating",
+			cfg:      engine.SyncConfig{EventTime: "09:00"},
+			contains: []string{"DTSTART:20250601T090000\r\n", "DTEND:20250601T093000\r\n"},
+			absent:   []string{"VALUE=DATE", "TZID"},
+		},
+		{
+			// Paris is UTC+2 in summer.
+			name:     "Zoned",
+			cfg:      engine.SyncConfig{EventTime: "23:30", EventDuration: time.Hour, TimeZone: "Europe/Paris"},
+			contains: []string{"DTSTART:20250601T213000Z\r\n", "DTEND:20250601T223000Z\r\n"},
+			absent:   []string{"TZID"},
+		},
+		{
+			name:    "Invalid",
+			cfg:     engine.SyncConfig{EventTime: "9h"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFetcher := new(MockFetcher)
+			mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+
+			gen := &engine.Generator{
+				Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+				Fetcher: mockFetcher,
+			}
+			tt.cfg.Mode = config.SourceModeWeb
+			tt.cfg.WebURL = "http://x"
+			icsData, _, _, err := gen.RunSync(context.Background(), tt.cfg)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, config.ErrEventTime)
+				return
+			}
+			require.NoError(t, err)
+
+			icsStr := string(icsData)
+			for _, c := range tt.contains {
+				assert.Contains(t, icsStr, c)
+			}
+			for _, a := range tt.absent {
+				assert.NotContains(t, icsStr, a)
+			}
+			issues, err := engine.ValidateICS(strings.NewReader(icsStr))
+			require.NoError(t, err)
+			assert.Empty(t, issues)
+		})
+	}
+}
+
+func TestRunSync_Milestones(t *testing.T) {
+	// Scenario: Born 1995-06-01, current date 2025-01-01.
+	// Generated ages are 29 (2024), 30 (2025) and 31 (2026); only 30 is a milestone.
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nFN:Round\nBDAY:1995-06-01\nEND:VCARD"
+
+	mockFetcher := new(MockFetcher)
+	mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+
+	gen := &engine.Generator{
+		Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Fetcher: mockFetcher,
+	}
+
+	cfg := engine.SyncConfig{
+		Mode:             config.SourceModeWeb,
+		WebURL:           "http://test.local",
+		Milestones:       []int{18, 21},
+		MilestoneEvery:   10,
+		MilestonePrefix:  "🎉",
+		MilestoneTrigger: "-P14D",
+	}
+
+	icsData, contacts, _, err := gen.RunSync(context.Background(), cfg)
+	assert.NoError(t, err)
+	assert.True(t, contacts[0].Milestone, "Upcoming 30th birthday should be flagged")
+
+	icsStr := string(icsData)
+	assert.Equal(t, 1, strings.Count(icsStr, "SUMMARY:🎉 "), "Only the 30th birthday gets the prefix")
+	assert.Equal(t, 1, strings.Count(icsStr, "TRIGGER:-P14D"), "Only the 30th birthday gets the early reminder")
+}
+
+func TestRunSync_IncludeCategories(t *testing.T) {
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nFN:Sister\nBDAY:1990-06-01\nCATEGORIES:Family\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Colleague\nBDAY:1985-03-02\nCATEGORIES:work\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Stranger\nBDAY:1970-01-01\nEND:VCARD"
+
+	tests := []struct {
+		name    string
+		include []string
+		want    []string
+	}{
+		{"No Filter", nil, []string{"Sister", "Colleague", "Stranger"}},
+		{"Single Group", []string{"Family"}, []string{"Sister"}},
+		{"Case Insensitive", []string{"WORK", "friends"}, []string{"Colleague"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFetcher := new(MockFetcher)
+			mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+
+			gen := &engine.Generator{
+				Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+				Fetcher: mockFetcher,
+			}
+
+			cfg := engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://test.local", IncludeCategories: tt.include}
+			_, contacts, _, err := gen.RunSync(context.Background(), cfg)
+			assert.NoError(t, err)
+
+			var names []string
+			for _, c := range contacts {
+				names = append(names, c.Name)
+			}
+			assert.Equal(t, tt.want, names)
+
+			// Discovery is independent of the filter.
+			assert.Equal(t, []string{"Family", "work"}, gen.Groups)
+		})
+	}
+}
+
+func TestRunSync_Deduplication(t *testing.T) {
+	tests := []struct {
+		name      string
+		vcards    string
+		wantNames []string
+		wantYear  []bool
+		wantCats  [][]string
+	}{
+		{
+			name: "Same Name And Date, Richest Kept",
+			vcards: "BEGIN:VCARD\nVERSION:3.0\nFN:Jane  Doe\nBDAY:--0611\nCATEGORIES:Friends\nEND:VCARD\n" +
+				"BEGIN:VCARD\nVERSION:3.0\nFN:jane doe\nBDAY:1990-06-11\nCATEGORIES:Family\nEND:VCARD",
+			wantNames: []string{"jane doe"},
+			wantYear:  []bool{true},
+			wantCats:  [][]string{{"Family", "Friends"}},
+		},
+		{
+			name: "Same UID, Different Spelling",
+			vcards: "BEGIN:VCARD\nVERSION:3.0\nUID:abc-1\nFN:Bob\nBDAY:1980-01-02\nTEL:123\nEND:VCARD\n" +
+				"BEGIN:VCARD\nVERSION:3.0\nUID:abc-1\nFN:Robert\nBDAY:1980-01-02\nEND:VCARD",
+			wantNames: []string{"Bob"},
+			wantYear:  []bool{true},
+			wantCats:  [][]string{nil},
+		},
+		{
+			name: "Namesakes Born In Different Years",
+			vcards: "BEGIN:VCARD\nVERSION:3.0\nFN:Sam\nBDAY:1970-03-03\nEND:VCARD\n" +
+				"BEGIN:VCARD\nVERSION:3.0\nFN:Sam\nBDAY:2000-03-03\nEND:VCARD",
+			wantNames: []string{"Sam", "Sam"},
+			wantYear:  []bool{true, true},
+			wantCats:  [][]string{nil, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFetcher := new(MockFetcher)
+			mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(io.NopCloser(strings.NewReader(tt.vcards)), nil)
+
+			gen := &engine.Generator{
+				Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+				Fetcher: mockFetcher,
+			}
+
+			icsData, contacts, _, err := gen.RunSync(context.Background(), engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://test.local"})
+			assert.NoError(t, err)
+			require.Len(t, contacts, len(tt.wantNames))
+
+			for i, c := range contacts {
+				assert.Equal(t, tt.wantNames[i], c.Name)
+				assert.Equal(t, tt.wantYear[i], c.YearKnown)
+				assert.Equal(t, tt.wantCats[i], c.Categories)
+			}
+
+			// 3 events (previous, current, next year) per remaining contact.
+			assert.Equal(t, 3*len(tt.wantNames), strings.Count(string(icsData), "BEGIN:VEVENT"))
+		})
+	}
+}
+
+func TestRunSync_AppleOmitYear(t *testing.T) {
+	tests := []struct {
+		name      string
+		bdayLine  string
+		yearKnown bool
+		wantDOB   time.Time
+	}{
+		{"Omit Parameter", "BDAY;X-APPLE-OMIT-YEAR=1604:1604-02-11", false, time.Date(2000, 2, 11, 0, 0, 0, 0, time.UTC)},
+		{"Omit Parameter With Value Type", "BDAY;X-APPLE-OMIT-YEAR=1604;VALUE=date:1604-02-29", false, time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"Sentinel Year Only", "BDAY:16040211", false, time.Date(2000, 2, 11, 0, 0, 0, 0, time.UTC)},
+		{"Parameter Not Matching", "BDAY;X-APPLE-OMIT-YEAR=1604:1990-02-11", true, time.Date(1990, 2, 11, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "BEGIN:VCARD\nVERSION:3.0\nFN:Apple User\n" + tt.bdayLine + "\nEND:VCARD"
+
+			mockFetcher := new(MockFetcher)
+			mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(io.NopCloser(strings.NewReader(content)), nil)
+
+			gen := &engine.Generator{
+				Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+				Fetcher: mockFetcher,
+			}
+
+			_, contacts, _, err := gen.RunSync(context.Background(), engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://x"})
+			assert.NoError(t, err)
+			require.Len(t, contacts, 1)
+			assert.Equal(t, tt.yearKnown, contacts[0].YearKnown)
+			assert.Equal(t, tt.wantDOB, contacts[0].DateOfBirth)
+		})
+	}
+}
+
+func TestRunSync_CustomDates(t *testing.T) {
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nFN:Jane\nBDAY:1990-06-01\n" +
+		"item1.X-ABDATE;type=pref:2015-09-12\nitem1.X-ABLabel:_$!<Anniversary>!$_\n" +
+		"item2.X-ABDATE:--03-04\nitem2.X-ABLabel:Adoption\n" +
+		"item3.X-ABDATE:garbage\nitem3.X-ABLabel:Broken\nEND:VCARD"
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		wantEvents int
+		contains   []string
+	}{
+		{"Disabled", false, 3, nil},
+		{"Enabled", true, 9, []string{"SUMMARY:Anniversary: Jane (10)", "SUMMARY:Adoption: Jane\r\n", "DTSTART;VALUE=DATE:20250304"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFetcher := new(MockFetcher)
+			mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+
+			gen := &engine.Generator{
+				Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+				Fetcher: mockFetcher,
+			}
+
+			cfg := engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://x", CustomDates: tt.enabled}
+			icsData, contacts, _, err := gen.RunSync(context.Background(), cfg)
+			assert.NoError(t, err)
+			assert.Len(t, contacts, 1, "Custom dates must not add entries to the contact list")
+
+			icsStr := string(icsData)
+			assert.Equal(t, tt.wantEvents, strings.Count(icsStr, "BEGIN:VEVENT"))
+			for _, c := range tt.contains {
+				assert.Contains(t, icsStr, c)
+			}
+		})
+	}
+}
+
+func TestRunSync_Memorials(t *testing.T) {
+	// Died in 2024: the 2025 and 2026 birthdays are replaced by remembrance events.
+	vcardContent := "BEGIN:VCARD\nVERSION:4.0\nFN:Grandpa\nBDAY:19300601\nDEATHDATE:20240310\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Aunt\nBDAY:1950-07-01\nX-DEATHDATE:--11-02\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:4.0\nFN:Unknown\nBDAY:19600101\nDEATHDATE;VALUE=text:circa 1990\nEND:VCARD"
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		wantEvents int
+		contains   []string
+		absent     []string
+	}{
+		{"Disabled", false, 9, []string{"DTSTART;VALUE=DATE:20260601"}, []string{"In memory"}},
+		// Grandpa: 1 birthday (2024) + 3 memorials; Aunt: 3 birthdays + 3 memorials; Unknown: 3 birthdays.
+		{"Enabled", true, 13, []string{"SUMMARY:In memory of Grandpa (1)", "SUMMARY:In memory of Aunt\r\n", "DTSTART;VALUE=DATE:20240601"}, []string{"DTSTART;VALUE=DATE:20250601"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFetcher := new(MockFetcher)
+			mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+
+			gen := &engine.Generator{
+				Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+				Fetcher: mockFetcher,
+			}
+
+			cfg := engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://x", Memorials: tt.enabled}
+			icsData, contacts, _, err := gen.RunSync(context.Background(), cfg)
+			assert.NoError(t, err)
+			assert.Len(t, contacts, 3)
+			for _, c := range contacts {
+				assert.Equal(t, tt.enabled && c.Name == "Grandpa", c.Deceased, c.Name)
+			}
+
+			icsStr := string(icsData)
+			assert.Equal(t, tt.wantEvents, strings.Count(icsStr, "BEGIN:VEVENT"))
+			for _, c := range tt.contains {
+				assert.Contains(t, icsStr, c)
+			}
+			for _, a := range tt.absent {
+				assert.NotContains(t, icsStr, a)
+			}
+		})
+	}
+}
+
+// TestRunSync_Lunar verifies that flagged contacts get their events on the lunar anniversary.
+func TestRunSync_Lunar(t *testing.T) {
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nFN:Mei\nBDAY:1990-10-03\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Lin\nBDAY:--10-03\nEND:VCARD"
+
+	run := func(lunar map[string]bool) (string, []engine.BirthdayEntry) {
+		mockFetcher := new(MockFetcher)
+		mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+		gen := &engine.Generator{
+			Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			Fetcher: mockFetcher,
+		}
+		icsData, contacts, _, err := gen.RunSync(context.Background(), engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://x", Lunar: lunar})
+		require.NoError(t, err)
+		return string(icsData), contacts
+	}
+
+	_, contacts := run(nil)
+	lunar := make(map[string]bool)
+	for _, c := range contacts {
+		lunar[c.UID] = true
+	}
+
+	icsStr, contacts := run(lunar)
+	// Mid-Autumn day: October 3 in 1990, September 17 in 2024, October 6 in 2025, September 25 in 2026.
+	for _, date := range []string{"20240917", "20251006", "20260925"} {
+		assert.Contains(t, icsStr, "DTSTART;VALUE=DATE:"+date)
+	}
+	for _, c := range contacts {
+		if c.Name == "Mei" {
+			assert.True(t, c.Lunar)
+			assert.Equal(t, time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC), c.NextOccurrence)
+			assert.Equal(t, 35, c.AgeNext)
+		} else {
+			assert.False(t, c.Lunar, "Without a year of birth, the lunar date is unknown")
+			assert.Equal(t, time.Date(2025, 10, 3, 0, 0, 0, 0, time.UTC), c.NextOccurrence)
+		}
+	}
+}
+
+// TestRunSync_Offsets verifies half birthdays and days counted from the birth.
+func TestRunSync_Offsets(t *testing.T) {
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nFN:Baby\nBDAY:2024-10-05\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Ann\nBDAY:--08-31\nEND:VCARD"
+
+	half, err := engine.ParseOffset(" 6M ")
+	require.NoError(t, err)
+	assert.Equal(t, engine.Offset{Months: 6}, half)
+	hundred, err := engine.ParseOffset("100d")
+	require.NoError(t, err)
+	for _, bad := range []string{"12m", "0d", "6w", "m"} {
+		_, err := engine.ParseOffset(bad)
+		assert.ErrorContains(t, err, config.ErrOffsetFormat, bad)
+	}
+
+	run := func(cfg engine.SyncConfig) (string, []engine.BirthdayEntry) {
+		mockFetcher := new(MockFetcher)
+		mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+		gen := &engine.Generator{
+			Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			Fetcher: mockFetcher,
+		}
+		cfg.Mode, cfg.WebURL = config.SourceModeWeb, "http://x"
+		icsData, contacts, _, err := gen.RunSync(context.Background(), cfg)
+		require.NoError(t, err)
+		return string(icsData), contacts
+	}
+
+	_, contacts := run(engine.SyncConfig{})
+	baby := contacts[0].UID
+	if contacts[0].Name != "Baby" {
+		baby = contacts[1].UID
+	}
+
+	icsStr, _ := run(engine.SyncConfig{Offsets: []engine.Offset{half}, ContactOffsets: map[string][]engine.Offset{baby: {hundred, half}}})
+	// Baby: 3 birthdays (birth included), 2 half birthdays, 100 days old; Ann: 3 birthdays, 3 half birthdays.
+	assert.Equal(t, 12, strings.Count(icsStr, "BEGIN:VEVENT"))
+	assert.Contains(t, icsStr, "SUMMARY:Half birthday: Baby")
+	assert.Contains(t, icsStr, "DTSTART;VALUE=DATE:20250405")
+	assert.NotContains(t, icsStr, "DTSTART;VALUE=DATE:20240405")
+	assert.Contains(t, icsStr, "SUMMARY:Baby: 100 days old")
+	assert.Contains(t, icsStr, "DTSTART;VALUE=DATE:20250113")
+	assert.Contains(t, icsStr, "DTSTART;VALUE=DATE:20250228", "August 31 + 6 months is the end of February")
+	assert.NotContains(t, icsStr, "Ann: 100 days old")
+}
+
+// TestRunSync_NameDays verifies the matching of first names against a bundled table.
+func TestRunSync_NameDays(t *testing.T) {
+	assert.Subset(t, engine.NameDayLocales(), []string{"de", "fr", "it", "pl"})
+
+	// Élodie: given name (N); Jean-Pierre: first part of a compound name; Zoltan: no name day.
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nFN:Dr Martin\nN:Martin;Élodie;;Dr;\nBDAY:1990-05-01\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Jean-Pierre Durand\nBDAY:1980-02-01\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Zoltan\nBDAY:1970-03-01\nEND:VCARD"
+
+	run := func(locale string) (string, error) {
+		mockFetcher := new(MockFetcher)
+		mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+		gen := &engine.Generator{
+			Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			Fetcher: mockFetcher,
+		}
+		icsData, _, _, err := gen.RunSync(context.Background(), engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://x", NameDays: locale})
+		return string(icsData), err
+	}
+
+	icsStr, err := run("fr")
+	require.NoError(t, err)
+	// 3 x 3 birthdays + 3 name days for Élodie and Jean.
+	assert.Equal(t, 15, strings.Count(icsStr, "BEGIN:VEVENT"))
+	assert.Contains(t, icsStr, "SUMMARY:Name day: Dr Martin")
+	assert.Contains(t, icsStr, "DTSTART;VALUE=DATE:20251022")
+	assert.Contains(t, icsStr, "SUMMARY:Name day: Jean-Pierre Durand")
+	assert.Contains(t, icsStr, "DTSTART;VALUE=DATE:20251227")
+	assert.NotContains(t, icsStr, "Name day: Zoltan")
+
+	_, err = run("xx")
+	assert.ErrorContains(t, err, config.ErrNameDayLocale)
+}
+
+// countingWriter records how many writes a streamed calendar took.
+type countingWriter struct {
+	buf    strings.Builder
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.buf.Write(p)
+}
+
+func TestRunSyncTo_Streaming(t *testing.T) {
+	mockFetcher := new(MockFetcher)
+	mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(io.NopCloser(strings.NewReader(
+			"BEGIN:VCARD\nVERSION:3.0\nFN:Alice\nBDAY:1990-01-01\nEND:VCARD\n"+
+				"BEGIN:VCARD\nVERSION:3.0\nFN:Bob\nBDAY:1985-05-05\nEND:VCARD\n")), nil)
+	gen := &engine.Generator{Clock: MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, Fetcher: mockFetcher}
+	cfg := engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://x", ReminderTriggers: []string{"-P1D"}}
+
+	var w countingWriter
+	contacts, count, err := gen.RunSyncTo(context.Background(), cfg, &w)
+	require.NoError(t, err)
+	assert.Len(t, contacts, 2)
+	assert.Equal(t, 1, count)
+
+	// The streamed output is a single valid calendar.
+	cal, err := ical.NewDecoder(strings.NewReader(w.buf.String())).Decode()
+	require.NoError(t, err)
+	assert.Equal(t, config.ICalProdid, cal.Props.Get(config.PropProdid).Value)
+	events := cal.Events()
+	require.NotEmpty(t, events)
+	assert.Equal(t, len(events)+1, w.writes, "One write per event, then the end of the calendar")
+	assert.Len(t, events[len(events)-1].Children, 1, "Alarms stay inside their event")
+	assert.Equal(t, 1, strings.Count(w.buf.String(), "BEGIN:VCALENDAR"))
+}
+func TestRunSync_Limits(t *testing.T) {
+	card := "BEGIN:VCARD\nVERSION:3.0\nFN:%s\nBDAY:1990-01-01\nNOTE:%s\nEND:VCARD\n"
+	source := fmt.Sprintf(card, "Small 1", "") + fmt.Sprintf(card, "Large", strings.Repeat("x", 500)) + fmt.Sprintf(card, "Small 2", "")
+
+	run := func(limits engine.Limits) ([]engine.BirthdayEntry, error) {
+		mockFetcher := new(MockFetcher)
+		mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(io.NopCloser(strings.NewReader(source)), nil)
+		gen := &engine.Generator{Clock: MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, Fetcher: mockFetcher}
+		_, contacts, _, err := gen.RunSync(context.Background(), engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://x", Limits: limits})
+		return contacts, err
+	}
+
+	// Defaults are generous.
+	contacts, err := run(engine.Limits{})
+	require.NoError(t, err)
+	assert.Len(t, contacts, 3)
+
+	// Exactly at the size limit is fine, one byte over fails instead of truncating the last card.
+	_, err = run(engine.Limits{MaxDownloadBytes: int64(len(source))})
+	require.NoError(t, err)
+	_, err = run(engine.Limits{MaxDownloadBytes: int64(len(source)) - 1})
+	assert.ErrorIs(t, err, engine.ErrLimitExceeded)
+
+	_, err = run(engine.Limits{MaxContacts: 2})
+	assert.ErrorIs(t, err, engine.ErrLimitExceeded)
+
+	// Oversized contacts are skipped, the others kept.
+	contacts, err = run(engine.Limits{MaxCardBytes: 200})
+	require.NoError(t, err)
+	require.Len(t, contacts, 2)
+	for _, c := range contacts {
+		assert.NotEqual(t, "Large", c.Name)
+	}
+}
+func TestRunSync_LocalDirectory(t *testing.T) {
+	// Scenario: vdirsyncer layout, one contact per file, nested folders and stray files.
+	dir := t.TempDir()
+	files := map[string]string{
+		"alice.vcf":        "BEGIN:VCARD\nVERSION:3.0\nFN:Alice\nBDAY:1990-01-01\nEND:VCARD", // No trailing newline
+		"nested/bob.VCARD": "BEGIN:VCARD\nVERSION:3.0\nFN:Bob\nBDAY:1985-05-05\nEND:VCARD\n",
+		"broken.vcf":       "BEGIN:VCARD\nVERSION:3.0\nFN:Broken\nBDAY:1970-01-01\n",
+		"notes.txt":        "BEGIN:VCARD\nVERSION:3.0\nFN:Ignored\nBDAY:1970-01-01\nEND:VCARD\n",
+		"nested/empty.vcf": "",
+		"nested/carol.vcf": "BEGIN:VCARD\nVERSION:3.0\nFN:Carol\nBDAY:--12-24\nEND:VCARD\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	gen := &engine.Generator{Clock: MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}}
+	_, contacts, _, err := gen.RunSync(context.Background(), engine.SyncConfig{Mode: config.SourceModeLocal, LocalPath: dir})
+	require.NoError(t, err)
+
+	var names []string
+	for _, c := range contacts {
+		names = append(names, c.Name)
+	}
+	assert.ElementsMatch(t, []string{"Alice", "Bob", "Carol"}, names, "Broken and non-vCard files must be skipped")
+}
+
+func TestRunSync_LocalDirectoryMany(t *testing.T) {
+	// Scenario: more files than workers, with broken ones spread among them.
+	dir := t.TempDir()
+	var want []string
+	for i := range 3 * config.SourceWorkers {
+		name := fmt.Sprintf("Contact %02d", i)
+		content := "BEGIN:VCARD\nVERSION:3.0\nFN:" + name + "\nBDAY:1990-01-01\nEND:VCARD\n"
+		if i%5 == 0 {
+			content = "BEGIN:VCARD\nFN:" + name + "\n" // Truncated
+		} else {
+			want = append(want, name)
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("%02d.vcf", i)), []byte(content), 0o600))
+	}
+
+	gen := &engine.Generator{Clock: MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}}
+	_, contacts, _, err := gen.RunSync(context.Background(), engine.SyncConfig{Mode: config.SourceModeLocal, LocalPath: dir})
+	require.NoError(t, err)
+
+	var names []string
+	for _, c := range contacts {
+		names = append(names, c.Name)
+	}
+	assert.ElementsMatch(t, want, names, "Only the broken files must be skipped")
+
+	// A cancelled context aborts the scan.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, _, err = gen.RunSync(ctx, engine.SyncConfig{Mode: config.SourceModeLocal, LocalPath: dir})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+func TestRunSync_Overrides(t *testing.T) {
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nFN:Wrong Date\nBDAY:1990-06-11\nEND:VCARD"
+
+	run := func(overrides map[string]string) ([]byte, []engine.BirthdayEntry) {
+		mockFetcher := new(MockFetcher)
+		mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+		gen := &engine.Generator{
+			Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			Fetcher: mockFetcher,
+		}
+		ics, contacts, _, err := gen.RunSync(context.Background(), engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://x", Overrides: overrides})
+		require.NoError(t, err)
+		require.Len(t, contacts, 1)
+		return ics, contacts
+	}
+
+	_, original := run(nil)
+	assert.False(t, original[0].Overridden)
+	uid := original[0].UID
+
+	ics, fixed := run(map[string]string{uid: "--07-12"})
+	assert.True(t, fixed[0].Overridden)
+	assert.False(t, fixed[0].YearKnown, "Override can mark the year as unknown")
+	assert.Equal(t, time.July, fixed[0].DateOfBirth.Month())
+	assert.Equal(t, uid, fixed[0].UID, "UID must stay stable so the override keeps matching")
+	assert.Contains(t, string(ics), "DTSTART;VALUE=DATE:20250712")
+	assert.NotContains(t, string(ics), "0611")
+
+	// Invalid overrides are ignored.
+	_, ignored := run(map[string]string{uid: "garbage"})
+	assert.False(t, ignored[0].Overridden)
+}
+
+// TestRunSync_OverridesByVCardUID verifies that corrections follow the vCard UID across renames.
+func TestRunSync_OverridesByVCardUID(t *testing.T) {
+	run := func(vcardContent string, overrides map[string]string) engine.BirthdayEntry {
+		mockFetcher := new(MockFetcher)
+		mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+		gen := &engine.Generator{
+			Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			Fetcher: mockFetcher,
+		}
+		_, contacts, _, err := gen.RunSync(context.Background(), engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://x", Overrides: overrides})
+		require.NoError(t, err)
+		require.Len(t, contacts, 1)
+		return contacts[0]
+	}
+	overrides := map[string]string{"urn:uuid:jane": "1991-07-12"}
+
+	before := run("BEGIN:VCARD\nVERSION:3.0\nUID:urn:uuid:jane\nFN:Jane Doe\nBDAY:1990-06-11\nEND:VCARD", overrides)
+	assert.Equal(t, "urn:uuid:jane", before.OverrideKey)
+	assert.True(t, before.Overridden)
+
+	renamed := run("BEGIN:VCARD\nVERSION:3.0\nUID:urn:uuid:jane\nFN:Jane Smith\nBDAY:1990-06-11\nEND:VCARD", overrides)
+	assert.NotEqual(t, before.UID, renamed.UID)
+	assert.True(t, renamed.Overridden, "The override must survive a change of name")
+	assert.Equal(t, time.July, renamed.DateOfBirth.Month())
+
+	// Without a vCard UID, the contact UID is the key.
+	noUID := run("BEGIN:VCARD\nVERSION:3.0\nFN:Jane Doe\nBDAY:1990-06-11\nEND:VCARD", nil)
+	assert.Equal(t, noUID.UID, noUID.OverrideKey)
+
+	// Corrections saved under the contact UID by earlier versions still apply.
+	legacy := run("BEGIN:VCARD\nVERSION:3.0\nUID:urn:uuid:jane\nFN:Jane Doe\nBDAY:1990-06-11\nEND:VCARD", map[string]string{before.UID: "--07-12"})
+	assert.True(t, legacy.Overridden)
+	assert.False(t, legacy.YearKnown)
+}
+
+func TestRunSync_HiddenContacts(t *testing.T) {
+	// Scenario: both contacts have their birthday today; one of them is hidden.
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nFN:Visible\nBDAY:1990-01-01\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Secret\nBDAY:1980-01-01\nEND:VCARD"
+
+	run := func(hidden map[string]bool) ([]byte, []engine.BirthdayEntry, int) {
+		mockFetcher := new(MockFetcher)
+		mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+		gen := &engine.Generator{
+			Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			Fetcher: mockFetcher,
+		}
+		ics, contacts, today, err := gen.RunSync(context.Background(), engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://x", Hidden: hidden})
+		require.NoError(t, err)
+		return ics, contacts, today
+	}
+
+	_, contacts, today := run(nil)
+	require.Len(t, contacts, 2)
+	assert.Equal(t, 2, today)
+
+	ics, contacts, today := run(map[string]bool{contacts[1].UID: true})
+	require.Len(t, contacts, 2, "Hidden contacts stay listed so they can be restored")
+	assert.True(t, contacts[1].Hidden)
+	assert.Equal(t, 1, today)
+	assert.NotContains(t, string(ics), "Secret")
+}
+
+// TestRunSync_ContactDetails verifies the DESCRIPTION and URL built from EMAIL, TEL and NOTE.
+func TestRunSync_ContactDetails(t *testing.T) {
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nFN:Jane\nBDAY:1990-06-01\nEMAIL:jane@example.com\n" +
+		"TEL;TYPE=cell:+33 6 12 34 56 78\nNOTE:Likes tea\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:4.0\nFN:Mail Only\nBDAY:1990-07-01\nEMAIL:mail@example.com\nEND:VCARD"
+	mockFetcher := new(MockFetcher)
+	mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+	gen := &engine.Generator{
+		Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Fetcher: mockFetcher,
+	}
+
+	cfg := engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://test.local", ContactDetails: true}
+	icsData, _, _, err := gen.RunSync(context.Background(), cfg)
+	require.NoError(t, err)
+
+	icsStr := string(icsData)
+	assert.Contains(t, icsStr, "DESCRIPTION:jane@example.com\\n+33 6 12 34 56 78\\nLikes tea\r\n")
+	assert.Contains(t, icsStr, "URL:tel:+33612345678\r\n")
+	assert.Contains(t, icsStr, "DESCRIPTION:mail@example.com\r\n")
+	assert.Contains(t, icsStr, "URL:mailto:mail@example.com\r\n")
+}
+
+// TestRunSync_PreferNickname verifies that the nickname names the events and the contact
+// while the UID keeps following FN, and that NOTE reaches the contact.
+func TestRunSync_PreferNickname(t *testing.T) {
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nFN:Robert Smith\nNICKNAME:Bob,Bobby\nBDAY:1990-06-01\nNOTE:Likes tea\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Jane Doe\nBDAY:1990-07-01\nEND:VCARD"
+	mockFetcher := new(MockFetcher)
+	for range 2 {
+		mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(io.NopCloser(strings.NewReader(vcardContent)), nil).Once()
+	}
+	gen := &engine.Generator{
+		Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Fetcher: mockFetcher,
+	}
+
+	cfg := engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://test.local"}
+	icsData, formal, _, err := gen.RunSync(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Len(t, formal, 2)
+	assert.Equal(t, "Robert Smith", formal[0].Name)
+	assert.Equal(t, "Bob", formal[0].Nickname)
+	assert.Equal(t, "Likes tea", formal[0].Note)
+	assert.NotContains(t, string(icsData), "Bob")
+
+	cfg.PreferNickname = true
+	icsData, nick, _, err := gen.RunSync(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Len(t, nick, 2)
+	assert.Equal(t, "Bob", nick[0].Name)
+	assert.Equal(t, "Robert Smith", nick[0].FullName)
+	assert.Equal(t, formal[0].UID, nick[0].UID, "The UID follows FN")
+	assert.Equal(t, "Jane Doe", nick[1].Name, "Without a nickname, FN")
+	assert.Contains(t, string(icsData), "Bob")
+	assert.NotContains(t, string(icsData), "Robert")
+}
+
+// TestRunSync_NameFormat verifies the names built from N in each format, with FN as the fallback.
+func TestRunSync_NameFormat(t *testing.T) {
+	vcardContent := "BEGIN:VCARD\nVERSION:3.0\nFN:Jane Doe\nN:Doe;Jane;;;\nBDAY:1990-06-01\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Cher\nN:;Cher;;;\nBDAY:1990-07-01\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:No Structure\nBDAY:1990-08-01\nEND:VCARD"
+
+	for format, want := range map[string][]string{
+		"":                           {"Jane Doe", "Cher", "No Structure"},
+		config.NameFormatFN:          {"Jane Doe", "Cher", "No Structure"},
+		config.NameFormatGivenFamily: {"Jane Doe", "Cher", "No Structure"},
+		config.NameFormatFamilyGiven: {"Doe, Jane", "Cher", "No Structure"},
+	} {
+		mockFetcher := new(MockFetcher)
+		mockFetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(io.NopCloser(strings.NewReader(vcardContent)), nil)
+		gen := &engine.Generator{
+			Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			Fetcher: mockFetcher,
+		}
+
+		cfg := engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: "http://test.local", NameFormat: format}
+		icsData, contacts, _, err := gen.RunSync(context.Background(), cfg)
+		require.NoError(t, err)
+		require.Len(t, contacts, 3)
+		for i, c := range contacts {
+			assert.Equal(t, want[i], c.Name, format)
+			assert.Equal(t, []string{"Jane Doe", "Cher", "No Structure"}[i], c.FullName, format)
+		}
+		summary := strings.ReplaceAll(want[0], ",", "\\,") // RFC 5545 TEXT escaping
+		assert.Contains(t, string(icsData), "SUMMARY:Birthday: "+summary+"\r\n", format)
+	}
+}
+
+// staticSource is a registered Source returning fixed contacts.
+type staticSource []engine.SourceContact
+
+func (s staticSource) List(context.Context, engine.SyncConfig) ([]engine.SourceContact, error) {
+	return s, nil
+}
+
+// TestRunSync_RegisteredSource verifies that a third-party Source feeds the calendar like a vCard source.
+func TestRunSync_RegisteredSource(t *testing.T) {
+	engine.RegisterSource("test-hr", staticSource{
+		{UID: "42", Name: "Jane Doe", Birthday: "1990-06-11", Categories: []string{"Work"}},
+		{Name: "No Date"},
+	})
+	assert.Contains(t, engine.Sources(), "test-hr")
+	assert.Panics(t, func() { engine.RegisterSource("test-hr", staticSource{}) }, "Duplicate mode")
+	assert.Panics(t, func() { engine.RegisterSource(config.SourceModeWeb, staticSource{}) }, "Built-in mode")
+
+	gen := &engine.Generator{Clock: MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}}
+	icsData, contacts, _, err := gen.RunSync(context.Background(), engine.SyncConfig{Mode: "test-hr", ContactGroups: true})
+	require.NoError(t, err)
+	require.Len(t, contacts, 1)
+	assert.Equal(t, "Jane Doe", contacts[0].Name)
+	assert.Equal(t, []string{"Work"}, contacts[0].Categories)
+	assert.Contains(t, string(icsData), "DTSTART;VALUE=DATE:20250611")
+
+	_, _, _, err = gen.RunSync(context.Background(), engine.SyncConfig{Mode: "test-unknown"})
+	assert.ErrorContains(t, err, config.ErrModeUnsupport)
+}
+
+// TestRunSync_ExecSource runs this test binary as the program of the program source.
+func TestRunSync_ExecSource(t *testing.T) {
+	if os.Getenv("GO_BIRTHDAY_EXEC_SOURCE") == "1" {
+		fmt.Print(`[{"uid": "7", "name": "Exec Person", "birthday": "--02-29"}]`)
+		os.Exit(0)
+	}
+	t.Setenv("GO_BIRTHDAY_EXEC_SOURCE", "1")
+
+	src := engine.ExecSource{Command: os.Args[0], Args: []string{"-test.run=^TestRunSync_ExecSource$"}}
+	contacts, err := src.List(context.Background(), engine.SyncConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, []engine.SourceContact{{UID: "7", Name: "Exec Person", Birthday: "--02-29"}}, contacts)
+
+	// The output is bounded like a downloaded address book.
+	_, err = src.List(context.Background(), engine.SyncConfig{Limits: engine.Limits{MaxDownloadBytes: 16}})
+	assert.ErrorIs(t, err, engine.ErrLimitExceeded)
+
+	gen := &engine.Generator{Clock: MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}}
+	_, _, _, err = gen.RunSync(context.Background(), engine.SyncConfig{Mode: config.SourceModeExec})
+	assert.ErrorContains(t, err, config.ErrExecEmpty)
+
+	_, err = engine.ExecSource{Command: filepath.Join(t.TempDir(), "missing")}.List(context.Background(), engine.SyncConfig{})
+	assert.ErrorContains(t, err, config.ErrExecSource)
+}
+
+func TestProbe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "contacts.vcf")
+	data := "BEGIN:VCARD\nVERSION:3.0\nFN:Alice\nBDAY:1990-01-01\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Bob\nBDAY:someday\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Carol\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Dave\nBDAY:--12-24\nEND:VCARD\n"
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+
+	gen := &engine.Generator{Clock: MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}}
+	cfg := engine.SyncConfig{Mode: config.SourceModeLocal, LocalPath: path}
+
+	res, err := gen.Probe(context.Background(), cfg, 0)
+	require.NoError(t, err)
+	assert.Equal(t, engine.ProbeResult{Cards: 4, Birthdays: 2, BadDates: 1, Bytes: int64(len(data))}, res)
+
+	res, err = gen.Probe(context.Background(), cfg, 2)
+	require.NoError(t, err)
+	assert.Positive(t, res.Bytes)
+	res.Bytes = 0
+	assert.Equal(t, engine.ProbeResult{Cards: 2, Birthdays: 1, BadDates: 1}, res, "Only the first n vCards are read")
+
+	_, err = gen.Probe(context.Background(), engine.SyncConfig{Mode: config.SourceModeLocal}, 0)
+	assert.ErrorContains(t, err, config.ErrLocalPathEmpty)
+}
+
+func TestGenerator_Progress(t *testing.T) {
+	var data strings.Builder
+	for i := range config.ProgressCards*2 + 1 {
+		fmt.Fprintf(&data, "BEGIN:VCARD\nVERSION:3.0\nFN:Contact %d\nBDAY:1990-01-01\nEND:VCARD\n", i)
+	}
+	path := filepath.Join(t.TempDir(), "contacts.vcf")
+	require.NoError(t, os.WriteFile(path, []byte(data.String()), 0o600))
+
+	var reports []engine.Progress
+	gen := &engine.Generator{
+		Clock:    MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Progress: func(p engine.Progress) { reports = append(reports, p) },
+	}
+	_, _, _, err := gen.RunSync(context.Background(), engine.SyncConfig{Mode: config.SourceModeLocal, LocalPath: path})
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	assert.Equal(t, config.ProgressCards, reports[0].Cards)
+	assert.Equal(t, config.ProgressCards*2, reports[1].Cards)
+	assert.Positive(t, reports[0].Bytes)
+	assert.Greater(t, reports[1].Bytes, reports[0].Bytes)
+	assert.LessOrEqual(t, reports[1].Bytes, int64(data.Len()))
+
+	// A canceled synchronization stops reading.
+	ctx, cancel := context.WithCancel(context.Background())
+	gen.Progress = func(engine.Progress) { cancel() }
+	_, _, _, err = gen.RunSync(ctx, engine.SyncConfig{Mode: config.SourceModeLocal, LocalPath: path})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -2,13 +2,19 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/telemetry"
 )
 
 // VCardFetcher defines the contract for retrieving vCard data.
@@ -17,34 +23,113 @@ type VCardFetcher interface {
 	Fetch(ctx context.Context, url, user, pass string) (io.ReadCloser, error)
 }
 
+// ErrNotModified is returned by conditional fetches when the address book is unchanged (HTTP 304).
+var ErrNotModified = errors.New(config.ErrNotModified)
+
+// Validators are the HTTP cache validators of a downloaded address book.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// ConditionalFetcher is implemented by fetchers able to skip the download of an unchanged address book.
+// FetchIfModified returns ErrNotModified if the source still matches prev, and the new validators otherwise.
+type ConditionalFetcher interface {
+	FetchIfModified(ctx context.Context, url, user, pass string, prev Validators) (io.ReadCloser, Validators, error)
+}
+
+// ErrNetwork wraps transport failures (DNS, connection refused, timeouts), which are worth retrying.
+var ErrNetwork = errors.New(config.ErrNetworkFetch)
+
+// StatusError reports a non-200 response of the vCard server.
+type StatusError struct {
+	Code       int
+	Status     string
+	RetryAfter time.Duration // Delay requested by the Retry-After header, 0 if absent
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %d %s", config.ErrStatusFetch, e.Code, e.Status)
+}
+
 // HTTPFetcher implements VCardFetcher using the standard net/http library.
 type HTTPFetcher struct {
-	Client *http.Client
+	Client *http.Client // Used when the synchronization sets no TransportConfig
+
+	// Client built for the last non-default TransportConfig, kept for its connections.
+	mu         sync.Mutex
+	custom     *http.Client
+	customConf TransportConfig
+	customMod  time.Time // Latest modification of the certificate files, to pick up replaced ones
 }
 
 // NewHTTPFetcher creates a new instance of HTTPFetcher with configured timeouts.
+// It honors the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
 func NewHTTPFetcher() *HTTPFetcher {
+	t, _ := newTransport(TransportConfig{}) // Cannot fail without an explicit proxy
 	return &HTTPFetcher{
 		Client: &http.Client{
-			Timeout: config.HTTPTimeout,
+			Timeout:   config.HTTPTimeout,
+			Transport: t,
 		},
 	}
 }
 
-// Fetch retrieves vCard data from a remote URL.
+// client returns the HTTP client matching the TransportConfig of ctx.
+func (f *HTTPFetcher) client(ctx context.Context) (*http.Client, error) {
+	tc := transportFrom(ctx)
+	if tc == (TransportConfig{}) {
+		return f.Client, nil
+	}
+
+	var mod time.Time
+	for _, path := range tc.files() {
+		if path == "" {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(mod) {
+			mod = info.ModTime()
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.custom != nil && f.customConf == tc && f.customMod.Equal(mod) {
+		return f.custom, nil
+	}
+	t, err := newTransport(tc)
+	if err != nil {
+		return nil, err
+	}
+	if f.custom != nil {
+		f.custom.CloseIdleConnections()
+	}
+	f.custom = &http.Client{Timeout: f.Client.Timeout, Transport: t}
+	f.customConf = tc
+	f.customMod = mod
+	return f.custom, nil
+}
+
+// Fetch retrieves vCard data from a remote URL, unconditionally.
+func (f *HTTPFetcher) Fetch(ctx context.Context, targetURL, user, pass string) (io.ReadCloser, error) {
+	rc, _, err := f.FetchIfModified(ctx, targetURL, user, pass, Validators{})
+	return rc, err
+}
+
+// FetchIfModified retrieves vCard data from a remote URL, sending If-None-Match/If-Modified-Since built from prev.
 // It sanitizes the URL for logging purposes to avoid leaking sensitive tokens.
 // It enforces a maximum response size limit.
-func (f *HTTPFetcher) Fetch(ctx context.Context, targetURL, user, pass string) (io.ReadCloser, error) {
+func (f *HTTPFetcher) FetchIfModified(ctx context.Context, targetURL, user, pass string, prev Validators) (rc io.ReadCloser, next Validators, err error) {
 	// Parse the URL to validate it and sanitize it for logs.
 	u, err := url.Parse(targetURL)
 	if err != nil {
 		// Use centralized error message for invalid URL structure.
-		return nil, fmt.Errorf("%s: %w", config.ErrInvalidURL, err)
+		return nil, Validators{}, fmt.Errorf("%s: %w", config.ErrInvalidURL, err)
 	}
 
 	// Security check: ensure strictly HTTP or HTTPS using config constants.
 	if u.Scheme != config.SchemeHTTP && u.Scheme != config.SchemeHTTPS {
-		return nil, fmt.Errorf("%s: %s", config.ErrProtocol, u.Scheme)
+		return nil, Validators{}, fmt.Errorf("%s: %s", config.ErrProtocol, u.Scheme)
 	}
 
 	// Construct a safe URL for logging (stripping query parameters which might contain tokens).
@@ -58,53 +143,109 @@ func (f *HTTPFetcher) Fetch(ctx context.Context, targetURL, user, pass string) (
 
 	log.Debug("Initiating vCard download")
 
+	// Time until the response headers; the body is read (and timed) by the sync span.
+	start := time.Now()
+	ctx, span := telemetry.StartSpan(ctx, config.SpanFetch, telemetry.KindClient,
+		slog.String(config.AttrHTTPMethod, http.MethodGet),
+		slog.String(config.AttrURL, safeURL))
+	defer func() {
+		if !errors.Is(err, ErrNotModified) {
+			span.SetError(err)
+		}
+		span.End()
+		telemetry.RecordDuration(config.MetricFetchDuration, time.Since(start))
+	}()
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, Validators{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Use the centralized User-Agent string from config to ensure consistency.
 	req.Header.Set(config.HeaderUserAgent, config.UserAgent)
+	if tp := telemetry.TraceParent(ctx); tp != "" {
+		req.Header.Set(config.HeaderTraceParent, tp)
+	}
 
-	if user != "" || pass != "" {
-		req.SetBasicAuth(user, pass)
+	if prev.ETag != "" {
+		req.Header.Set(config.HeaderIfNoneMatch, prev.ETag)
+	}
+	if prev.LastModified != "" {
+		req.Header.Set(config.HeaderIfModifiedSince, prev.LastModified)
 	}
 
-	resp, err := f.Client.Do(req)
+	client, err := f.client(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("network error during fetch: %w", err)
+		return nil, Validators{}, err
+	}
+	resp, err := send(ctx, client, req, user, pass)
+	if err != nil {
+		return nil, Validators{}, err
 	}
 
+	span.SetAttributes(slog.Int(config.AttrHTTPStatus, resp.StatusCode))
+	if resp.StatusCode == http.StatusNotModified {
+		_ = resp.Body.Close()
+		log.Info("vCards not modified")
+		return nil, prev, ErrNotModified
+	}
 	if resp.StatusCode != http.StatusOK {
 		_ = resp.Body.Close() // Ensure we don't leak resources on error.
 		log.Warn("Server returned error status",
 			slog.Int(config.LogKeyStatus, resp.StatusCode),
 		)
-		return nil, fmt.Errorf("server returned unexpected status: %d %s", resp.StatusCode, resp.Status)
+		return nil, Validators{}, &StatusError{
+			Code:       resp.StatusCode,
+			Status:     resp.Status,
+			RetryAfter: parseRetryAfter(resp.Header.Get(config.HeaderRetryAfter), time.Now()),
+		}
 	}
 
 	log.Info("vCards downloading",
 		slog.Int64("content_length", resp.ContentLength),
 	)
 
-	// Return a ReadCloser that limits the number of bytes read to protect against large payloads.
-	return &limitedReadCloser{
-		Reader: io.LimitReader(resp.Body, config.MaxHTTPResponseSize),
-		Closer: resp.Body,
-	}, nil
-}
-
-// limitedReadCloser wraps an io.Reader (Limited) and the original io.Closer.
-// This ensures we can close the network connection properly while limiting the read size.
-type limitedReadCloser struct {
-	io.Reader
-	io.Closer
+	// The size of the body is limited by the Generator (see Limits).
+	next = Validators{
+		ETag:         resp.Header.Get(config.HeaderETag),
+		LastModified: resp.Header.Get(config.HeaderLastModified),
+	}
+	return resp.Body, next, nil
 }
 
-func (l *limitedReadCloser) Read(p []byte) (n int, err error) {
-	return l.Reader.Read(p)
+// send authorizes and sends req. An OAuth2 access token rejected by the server is refreshed once,
+// in case it was revoked before its expiry.
+func send(ctx context.Context, client *http.Client, req *http.Request, user, pass string) (*http.Response, error) {
+	auth := authFrom(ctx)
+	for retry := auth.Type == config.AuthOAuth2; ; retry = false {
+		if err := auth.authorize(ctx, client, req, user, pass); err != nil {
+			return nil, err
+		}
+		if err := requests.wait(ctx, req.URL.Host, spacingFrom(ctx)); err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrNetwork, err)
+		}
+		if resp.StatusCode != http.StatusUnauthorized || !retry {
+			return resp, nil
+		}
+		_ = resp.Body.Close()
+		auth.OAuth2.Invalidate()
+	}
 }
 
-func (l *limitedReadCloser) Close() error {
-	return l.Closer.Close()
+// parseRetryAfter reads a Retry-After header, given in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
 }
@@ -2,9 +2,26 @@ package engine_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
 	"io"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -121,3 +138,567 @@ func TestHTTPFetcher_Fetch_ProtocolSecurity(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), config.ErrProtocol)
 }
+
+// TestHTTPFetcher_Fetch_StatusError exposes the status and Retry-After to the retry policy.
+func TestHTTPFetcher_Fetch_StatusError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(config.HeaderRetryAfter, "7")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	_, err := engine.NewHTTPFetcher().Fetch(context.Background(), ts.URL, "", "")
+
+	var statusErr *engine.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusServiceUnavailable, statusErr.Code)
+	assert.Equal(t, 7*time.Second, statusErr.RetryAfter)
+}
+
+// TestRetryFetcher retries transient failures only, up to the configured number of attempts.
+func TestRetryFetcher(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int // Returned in order, the last one repeats
+		wantCalls int32
+		wantErr   bool
+	}{
+		{"RecoversFrom503", []int{http.StatusServiceUnavailable, http.StatusOK}, 2, false},
+		{"RecoversFrom429", []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusOK}, 3, false},
+		{"GivesUp", []int{http.StatusInternalServerError}, 3, true},
+		{"NoRetryOnAuth", []int{http.StatusUnauthorized}, 1, true},
+		{"NoRetryOnNotFound", []int{http.StatusNotFound}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(calls.Add(1))
+				w.WriteHeader(tt.statuses[min(n, len(tt.statuses))-1])
+			}))
+			defer ts.Close()
+
+			fetcher := engine.NewRetryFetcher(engine.NewHTTPFetcher())
+			fetcher.BaseDelay = time.Millisecond
+
+			rc, err := fetcher.Fetch(context.Background(), ts.URL, "", "")
+			if rc != nil {
+				_ = rc.Close()
+			}
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.wantCalls, calls.Load())
+		})
+	}
+}
+
+// TestRetryFetcher_NetworkError retries when the server cannot be reached.
+func TestRetryFetcher_NetworkError(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	url := ts.URL
+	ts.Close() // Connection refused from now on
+
+	fetcher := engine.NewRetryFetcher(engine.NewHTTPFetcher())
+	fetcher.Attempts = 2
+	fetcher.BaseDelay = time.Millisecond
+
+	_, err := fetcher.Fetch(context.Background(), url, "", "")
+	assert.ErrorIs(t, err, engine.ErrNetwork)
+}
+
+// TestRetryFetcher_RetryAfter waits as requested by the server, unless it asks for too long.
+func TestRetryFetcher_RetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	retryAfter := "1"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set(config.HeaderRetryAfter, retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	fetcher := engine.NewRetryFetcher(engine.NewHTTPFetcher())
+	fetcher.BaseDelay = time.Millisecond
+
+	start := time.Now()
+	rc, err := fetcher.Fetch(context.Background(), ts.URL, "", "")
+	require.NoError(t, err)
+	_ = rc.Close()
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+
+	// A Retry-After beyond MaxDelay is left to the next synchronization.
+	calls.Store(0)
+	retryAfter = "3600"
+	_, err = fetcher.Fetch(context.Background(), ts.URL, "", "")
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+// TestRetryFetcher_Cancelled stops waiting when the context is cancelled.
+func TestRetryFetcher_Cancelled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	fetcher := engine.NewRetryFetcher(engine.NewHTTPFetcher())
+	fetcher.BaseDelay = time.Hour
+	fetcher.MaxDelay = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := fetcher.Fetch(ctx, ts.URL, "", "")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// TestHTTPFetcher_FetchIfModified sends the validators of the previous download.
+func TestHTTPFetcher_FetchIfModified(t *testing.T) {
+	const lastModified = "Wed, 01 Jan 2025 10:00:00 GMT"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(config.HeaderIfNoneMatch) == `"abc"` && r.Header.Get(config.HeaderIfModifiedSince) == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set(config.HeaderETag, `"abc"`)
+		w.Header().Set(config.HeaderLastModified, lastModified)
+		_, _ = io.WriteString(w, "BEGIN:VCARD\nVERSION:3.0\nFN:Jane\nBDAY:19900101\nEND:VCARD")
+	}))
+	defer ts.Close()
+
+	fetcher := engine.NewHTTPFetcher()
+	rc, v, err := fetcher.FetchIfModified(context.Background(), ts.URL, "", "", engine.Validators{})
+	require.NoError(t, err)
+	_ = rc.Close()
+	assert.Equal(t, engine.Validators{ETag: `"abc"`, LastModified: lastModified}, v)
+
+	rc, next, err := fetcher.FetchIfModified(context.Background(), ts.URL, "", "", v)
+	assert.ErrorIs(t, err, engine.ErrNotModified)
+	assert.Nil(t, rc)
+	assert.Equal(t, v, next)
+
+	// The retry wrapper forwards conditional requests, and RunSync reports the unchanged source.
+	gen := &engine.Generator{
+		Clock:      &MockClock{CurrentTime: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)},
+		Fetcher:    engine.NewRetryFetcher(fetcher),
+		Validators: v,
+	}
+	cfg := engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: ts.URL}
+	_, _, _, err = gen.RunSync(context.Background(), cfg)
+	assert.ErrorIs(t, err, engine.ErrNotModified)
+
+	gen.Validators = engine.Validators{}
+	ics, contacts, _, err := gen.RunSync(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.NotEmpty(t, ics)
+	assert.Len(t, contacts, 1)
+	assert.Equal(t, v, gen.Validators)
+}
+
+// TestHTTPFetcher_Proxy verifies that an explicit proxy from the sync configuration is used, with its credentials.
+func TestHTTPFetcher_Proxy(t *testing.T) {
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Add(1)
+		assert.Equal(t, "http://carddav.invalid/book.vcf", r.URL.String(), "Proxies receive the absolute URL")
+		assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("corp:secret")), r.Header.Get("Proxy-Authorization"))
+		_, _ = io.WriteString(w, "BEGIN:VCARD\nVERSION:3.0\nFN:Proxied\nBDAY:1990-01-01\nEND:VCARD\n")
+	}))
+	defer proxy.Close()
+
+	gen := &engine.Generator{Clock: MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, Fetcher: engine.NewHTTPFetcher()}
+	cfg := engine.SyncConfig{
+		Mode:      config.SourceModeWeb,
+		WebURL:    "http://carddav.invalid/book.vcf",
+		Transport: engine.TransportConfig{ProxyURL: proxy.URL, ProxyUser: "corp", ProxyPass: "secret"},
+	}
+	_, contacts, _, err := gen.RunSync(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Len(t, contacts, 1)
+	assert.Equal(t, "Proxied", contacts[0].Name)
+	assert.Equal(t, int32(1), proxied.Load())
+
+	cfg.Transport.ProxyURL = "not a proxy"
+	_, _, _, err = gen.RunSync(context.Background(), cfg)
+	assert.ErrorContains(t, err, config.ErrProxyURL)
+}
+
+// TestHTTPFetcher_TLS verifies the custom CA bundle and the explicit verification skip.
+func TestHTTPFetcher_TLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "BEGIN:VCARD\nVERSION:3.0\nFN:Internal\nBDAY:1990-01-01\nEND:VCARD\n")
+	}))
+	defer ts.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}), 0o600))
+
+	gen := &engine.Generator{Clock: MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, Fetcher: engine.NewHTTPFetcher()}
+	run := func(tc engine.TransportConfig) error {
+		_, _, _, err := gen.RunSync(context.Background(), engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: ts.URL, Transport: tc})
+		return err
+	}
+
+	assert.Error(t, run(engine.TransportConfig{}), "Unknown authority")
+	assert.NoError(t, run(engine.TransportConfig{CAFile: caFile}))
+	assert.NoError(t, run(engine.TransportConfig{InsecureSkipVerify: true}))
+
+	require.NoError(t, os.WriteFile(caFile, []byte("garbage"), 0o600))
+	assert.ErrorContains(t, run(engine.TransportConfig{CAFile: caFile + ".missing"}), config.ErrCAFile)
+	assert.ErrorContains(t, run(engine.TransportConfig{CAFile: caFile}), config.ErrCAFile)
+}
+
+// TestHTTPFetcher_ClientCertificate verifies mutual TLS with a client certificate and key pair.
+func TestHTTPFetcher_ClientCertificate(t *testing.T) {
+	// Self-signed client certificate, trusted by the server.
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "go-birthday"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"), filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "BEGIN:VCARD\nVERSION:3.0\nFN:Mutual\nBDAY:1990-01-01\nEND:VCARD\n")
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	ts.StartTLS()
+	defer ts.Close()
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}), 0o600))
+
+	gen := &engine.Generator{Clock: MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, Fetcher: engine.NewHTTPFetcher()}
+	run := func(tc engine.TransportConfig) error {
+		_, _, _, err := gen.RunSync(context.Background(), engine.SyncConfig{Mode: config.SourceModeWeb, WebURL: ts.URL, Transport: tc})
+		return err
+	}
+
+	assert.Error(t, run(engine.TransportConfig{CAFile: caFile}), "The server requires a client certificate")
+	assert.NoError(t, run(engine.TransportConfig{CAFile: caFile, ClientCert: certFile, ClientKey: keyFile}))
+	assert.ErrorContains(t, run(engine.TransportConfig{CAFile: caFile, ClientCert: certFile}), config.ErrClientCert)
+}
+
+// TestHTTPFetcher_Bearer verifies that a static bearer token replaces Basic Auth.
+func TestHTTPFetcher_Bearer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, basic := r.BasicAuth()
+		assert.False(t, basic, "Basic Auth must not be sent with a bearer token")
+		if r.Header.Get("Authorization") != "Bearer app-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, "BEGIN:VCARD\nVERSION:3.0\nFN:Bearer\nBDAY:1990-01-01\nEND:VCARD\n")
+	}))
+	defer server.Close()
+
+	gen := &engine.Generator{Clock: MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, Fetcher: engine.NewHTTPFetcher()}
+	cfg := engine.SyncConfig{
+		Mode:    config.SourceModeWeb,
+		WebURL:  server.URL,
+		WebUser: "ignored",
+		Auth:    engine.Auth{Type: config.AuthBearer, Token: "app-token"},
+	}
+	_, contacts, _, err := gen.RunSync(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Len(t, contacts, 1)
+	assert.Equal(t, "Bearer", contacts[0].Name)
+}
+
+// TestHTTPFetcher_OAuth2 verifies the refresh of access tokens: reuse until expiry,
+// rotation of the refresh token, and a single retry when the server revokes a token early.
+func TestHTTPFetcher_OAuth2(t *testing.T) {
+	var refreshes atomic.Int32
+	var valid atomic.Value
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "client", r.PostForm.Get("client_id"))
+		assert.Equal(t, "secret", r.PostForm.Get("client_secret"))
+		if r.PostForm.Get("refresh_token") == "revoked" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"error":"invalid_grant"}`)
+			return
+		}
+		n := refreshes.Add(1)
+		access := "access-" + string(rune('0'+n))
+		valid.Store(access)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"access_token":"`+access+`","token_type":"Bearer","expires_in":3600,"refresh_token":"rotated"}`)
+	}))
+	defer provider.Close()
+
+	carddav := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+valid.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, "BEGIN:VCARD\nVERSION:3.0\nFN:OAuth\nBDAY:1990-01-01\nEND:VCARD\n")
+	}))
+	defer carddav.Close()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts := engine.NewTokenSource(engine.OAuth2Config{TokenURL: provider.URL, ClientID: "client", ClientSecret: "secret"}, "initial")
+	ts.Clock = MockClock{CurrentTime: now}
+	var saved string
+	ts.OnRotate = func(token string) { saved = token }
+
+	gen := &engine.Generator{Clock: MockClock{CurrentTime: now}, Fetcher: engine.NewHTTPFetcher()}
+	cfg := engine.SyncConfig{
+		Mode:   config.SourceModeWeb,
+		WebURL: carddav.URL,
+		Auth:   engine.Auth{Type: config.AuthOAuth2, OAuth2: ts},
+	}
+	sync := func() {
+		t.Helper()
+		_, contacts, _, err := gen.RunSync(context.Background(), cfg)
+		require.NoError(t, err)
+		require.Len(t, contacts, 1)
+	}
+
+	sync()
+	assert.Equal(t, int32(1), refreshes.Load())
+	assert.Equal(t, "rotated", saved, "Rotated refresh tokens are handed over for storage")
+	assert.Equal(t, "rotated", ts.RefreshToken())
+
+	sync()
+	assert.Equal(t, int32(1), refreshes.Load(), "The access token is reused until it expires")
+
+	ts.Clock = MockClock{CurrentTime: now.Add(time.Hour)}
+	sync()
+	assert.Equal(t, int32(2), refreshes.Load(), "An expiring access token is refreshed")
+
+	valid.Store("revoked by the server")
+	_, contacts, _, err := gen.RunSync(context.Background(), cfg)
+	require.NoError(t, err, "A rejected access token is refreshed and the request retried")
+	assert.Len(t, contacts, 1)
+	assert.Equal(t, int32(3), refreshes.Load())
+
+	revoked := engine.NewTokenSource(ts.Config, "revoked")
+	cfg.Auth.OAuth2 = revoked
+	_, _, _, err = gen.RunSync(context.Background(), cfg)
+	require.ErrorContains(t, err, config.ErrOAuth2Refresh)
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+// TestHTTPFetcher_Discover verifies discovery through the well-known URL, as served by Nextcloud.
+func TestHTTPFetcher_Discover(t *testing.T) {
+	const ns = `xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav"`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/carddav", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/nextcloud/remote.php/dav/", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/nextcloud/remote.php/dav/", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if user != "alice" || pass != "app-password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, config.MethodPropfind, r.Method)
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusMultiStatus)
+		switch {
+		case strings.Contains(string(body), "current-user-principal"):
+			_, _ = io.WriteString(w, `<d:multistatus `+ns+`><d:response><d:href>/nextcloud/remote.php/dav/</d:href>
+				<d:propstat><d:prop><d:current-user-principal><d:href>/nextcloud/remote.php/dav/principals/users/alice/</d:href></d:current-user-principal></d:prop></d:propstat>
+				</d:response></d:multistatus>`)
+		case strings.Contains(string(body), "addressbook-home-set"):
+			_, _ = io.WriteString(w, `<d:multistatus `+ns+`><d:response><d:href>/nextcloud/remote.php/dav/principals/users/alice/</d:href>
+				<d:propstat><d:prop><card:addressbook-home-set><d:href>/nextcloud/remote.php/dav/addressbooks/users/alice/</d:href></card:addressbook-home-set></d:prop></d:propstat>
+				</d:response></d:multistatus>`)
+		case strings.Contains(string(body), "getetag") && strings.HasSuffix(r.URL.Path, "/contacts/"):
+			_, _ = io.WriteString(w, `<d:multistatus `+ns+`>
+				<d:response><d:href>/nextcloud/remote.php/dav/addressbooks/users/alice/contacts/</d:href><d:propstat><d:prop/></d:propstat></d:response>
+				<d:response><d:href>/nextcloud/remote.php/dav/addressbooks/users/alice/contacts/jane.vcf</d:href><d:propstat><d:prop><d:getetag>"1"</d:getetag></d:prop></d:propstat></d:response>
+				<d:response><d:href>/nextcloud/remote.php/dav/addressbooks/users/alice/contacts/john.vcf</d:href><d:propstat><d:prop><d:getetag>"2"</d:getetag></d:prop></d:propstat></d:response>
+				</d:multistatus>`)
+		case strings.Contains(string(body), "getetag"):
+			_, _ = io.WriteString(w, `not a multistatus`)
+		default:
+			assert.Equal(t, "1", r.Header.Get("Depth"))
+			_, _ = io.WriteString(w, `<d:multistatus `+ns+`>
+				<d:response><d:href>/nextcloud/remote.php/dav/addressbooks/users/alice/</d:href>
+					<d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype></d:prop></d:propstat></d:response>
+				<d:response><d:href>/nextcloud/remote.php/dav/addressbooks/users/alice/contacts/</d:href>
+					<d:propstat><d:prop><d:resourcetype><d:collection/><card:addressbook/></d:resourcetype><d:displayname>Contacts</d:displayname></d:prop></d:propstat></d:response>
+				<d:response><d:href>/nextcloud/remote.php/dav/addressbooks/users/alice/z-app-generated--contactsinteraction--recent/</d:href>
+					<d:propstat><d:prop><d:resourcetype><d:collection/><card:addressbook/></d:resourcetype></d:prop></d:propstat>
+					<d:propstat><d:prop><d:displayname/></d:prop><d:status>HTTP/1.1 404 Not Found</d:status></d:propstat></d:response>
+				</d:multistatus>`)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	books, err := engine.NewHTTPFetcher().Discover(context.Background(), srv.URL+"/nextcloud", "alice", "app-password")
+	require.NoError(t, err)
+	assert.Equal(t, []engine.AddressBook{
+		{Name: "Contacts", URL: srv.URL + "/nextcloud/remote.php/dav/addressbooks/users/alice/contacts/", Contacts: 2},
+		{Name: "z-app-generated--contactsinteraction--recent", URL: srv.URL + "/nextcloud/remote.php/dav/addressbooks/users/alice/z-app-generated--contactsinteraction--recent/", Contacts: -1},
+	}, books)
+
+	_, err = engine.NewHTTPFetcher().Discover(context.Background(), srv.URL+"/nextcloud", "alice", "wrong")
+	assert.ErrorContains(t, err, config.ErrDiscovery)
+	assert.ErrorContains(t, err, "401")
+
+	_, err = engine.NewRetryFetcher(engine.NewHTTPFetcher()).Discover(context.Background(), "ftp://cloud.example.com", "alice", "x")
+	assert.ErrorContains(t, err, config.ErrProtocol)
+}
+
+// TestGenerator_AddressBooks verifies that the address books chosen for the web source are merged.
+func TestGenerator_AddressBooks(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/family/":
+			_, _ = io.WriteString(w, "BEGIN:VCARD\nVERSION:3.0\nUID:1\nFN:Jane\nBDAY:19900101\nEND:VCARD")
+		case "/work/":
+			_, _ = io.WriteString(w, "BEGIN:VCARD\nVERSION:3.0\nUID:2\nFN:John\nBDAY:19850611\nEND:VCARD\n")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	gen := &engine.Generator{Clock: engine.RealClock{}, Fetcher: engine.NewHTTPFetcher()}
+	cfg := engine.SyncConfig{Mode: config.SourceModeWeb, WebURLs: []string{ts.URL + "/family/", ts.URL + "/work/"}}
+	res, err := gen.Probe(context.Background(), cfg, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Cards)
+	assert.Equal(t, 2, res.Birthdays)
+
+	cfg.WebURLs = append(cfg.WebURLs, ts.URL+"/deleted/")
+	_, err = gen.Probe(context.Background(), cfg, 0)
+	var status *engine.StatusError
+	require.ErrorAs(t, err, &status)
+	assert.Equal(t, http.StatusNotFound, status.Code)
+}
+
+// TestGenerator_JMAP verifies the JMAP source: session, paged ContactCard requests and the birthday mapping.
+func TestGenerator_JMAP(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/jmap", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer api-token", r.Header.Get("Authorization"))
+		_, _ = io.WriteString(w, `{"apiUrl": "/jmap/api/", "primaryAccounts": {"urn:ietf:params:jmap:contacts": "u1"}}`)
+	})
+	mux.HandleFunc("/jmap/api/", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Using       []string            `json:"using"`
+			MethodCalls [][]json.RawMessage `json:"methodCalls"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Contains(t, req.Using, config.JMAPCapContacts)
+		require.Len(t, req.MethodCalls, 2)
+		assert.JSONEq(t, `"ContactCard/get"`, string(req.MethodCalls[1][0]))
+
+		_, _ = io.WriteString(w, `{"methodResponses": [
+			["ContactCard/query", {"ids": ["a", "b", "c", "d"]}, "q"],
+			["ContactCard/get", {"list": [
+				{"id": "a", "uid": "urn:uuid:a", "name": {"full": "Ada Lovelace"},
+					"anniversaries": {"k1": {"kind": "birth", "date": {"year": 1815, "month": 12, "day": 10}}},
+					"keywords": {"family": true}},
+				{"id": "b", "name": {"components": [{"kind": "given", "value": "No"}, {"kind": "surname", "value": "Year"}]},
+					"anniversaries": {"k1": {"kind": "birth", "date": {"@type": "PartialDate", "month": 3, "day": 4}}}},
+				{"id": "c", "uid": "urn:uuid:c", "name": {"full": "Stamped"},
+					"anniversaries": {"k1": {"kind": "birth", "date": {"@type": "Timestamp", "utc": "1990-06-11T00:00:00Z"}}}},
+				{"id": "d", "kind": "group", "name": {"full": "Friends"}}
+			]}, "g"]
+		]}`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	gen := &engine.Generator{
+		Clock:   MockClock{CurrentTime: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)},
+		Fetcher: engine.NewRetryFetcher(engine.NewHTTPFetcher()),
+	}
+	cfg := engine.SyncConfig{
+		Mode:   config.SourceModeJMAP,
+		WebURL: srv.URL,
+		Auth:   engine.Auth{Type: config.AuthBearer, Token: "api-token"},
+	}
+	_, contacts, _, err := gen.RunSync(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Len(t, contacts, 3, "Groups are skipped")
+
+	byName := make(map[string]engine.BirthdayEntry)
+	for _, c := range contacts {
+		byName[c.Name] = c
+	}
+	assert.Equal(t, time.Date(1815, 12, 10, 0, 0, 0, 0, time.UTC), byName["Ada Lovelace"].DateOfBirth)
+	assert.Equal(t, []string{"family"}, byName["Ada Lovelace"].Categories)
+	assert.False(t, byName["No Year"].YearKnown)
+	assert.Equal(t, time.March, byName["No Year"].DateOfBirth.Month())
+	assert.True(t, byName["Stamped"].YearKnown)
+	assert.Equal(t, 11, byName["Stamped"].DateOfBirth.Day())
+}
+
+func TestIsOffline(t *testing.T) {
+	f := engine.NewHTTPFetcher()
+
+	// Nothing listens on a closed server: it is down, not the network.
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+	_, err := f.Fetch(context.Background(), ts.URL, "", "")
+	require.ErrorIs(t, err, engine.ErrNetwork)
+	assert.False(t, engine.IsOffline(err))
+
+	// A name that cannot be resolved, as without any network.
+	_, err = f.Fetch(context.Background(), "http://go-birthday.invalid/contacts.vcf", "", "")
+	require.ErrorIs(t, err, engine.ErrNetwork)
+	assert.True(t, engine.IsOffline(err))
+
+	dns := &net.DNSError{Err: "no such host", Name: "dav.example.com", IsNotFound: true}
+	assert.True(t, engine.IsOffline(fmt.Errorf("%w: %w", engine.ErrNetwork, dns)))
+	assert.False(t, engine.IsOffline(dns), "Only failures to reach the source")
+	assert.False(t, engine.IsOffline(&engine.StatusError{Code: http.StatusServiceUnavailable}))
+	assert.False(t, engine.IsOffline(nil))
+}
+
+func TestGenerator_RequestSpacing(t *testing.T) {
+	var mu sync.Mutex
+	var times []time.Time
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+		_, _ = fmt.Fprintf(w, "BEGIN:VCARD\nVERSION:3.0\nUID:%s\nFN:Jane\nBDAY:19900101\nEND:VCARD\n", r.URL.Path)
+	}))
+	defer ts.Close()
+
+	const spacing = 150 * time.Millisecond
+	gen := &engine.Generator{Clock: engine.RealClock{}, Fetcher: engine.NewHTTPFetcher()}
+	cfg := engine.SyncConfig{
+		Mode:    config.SourceModeWeb,
+		WebURLs: []string{ts.URL + "/family/", ts.URL + "/work/", ts.URL + "/friends/"},
+		Limits:  engine.Limits{RequestSpacing: spacing},
+	}
+	_, _, _, err := gen.RunSync(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Len(t, times, 3)
+	for i := 1; i < len(times); i++ {
+		assert.GreaterOrEqual(t, times[i].Sub(times[i-1]), spacing-10*time.Millisecond, "Request %d", i)
+	}
+
+	// Waiting for its turn ends with the synchronization.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	cfg.Limits.RequestSpacing = time.Hour
+	_, _, _, err = gen.RunSync(ctx, cfg)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,290 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-vcard"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// JMAPFetcher is implemented by fetchers able to read the contacts of a JMAP account (RFC 9610).
+// FetchJMAP returns them as a vCard stream, so that they go through the same pipeline as CardDAV.
+type JMAPFetcher interface {
+	FetchJMAP(ctx context.Context, sessionURL, user, pass string) (io.ReadCloser, error)
+}
+
+// jmapSession is the part of the JMAP session resource used here (RFC 8620, section 2).
+type jmapSession struct {
+	APIURL          string            `json:"apiUrl"`
+	PrimaryAccounts map[string]string `json:"primaryAccounts"`
+}
+
+// jmapCard is the part of a JSContact card used here (RFC 9553).
+type jmapCard struct {
+	ID   string `json:"id"`
+	UID  string `json:"uid"`
+	Kind string `json:"kind"` // "individual" if absent; groups are skipped
+	Name struct {
+		Full       string `json:"full"`
+		Components []struct {
+			Kind  string `json:"kind"`
+			Value string `json:"value"`
+		} `json:"components"`
+	} `json:"name"`
+	Anniversaries map[string]struct {
+		Kind string   `json:"kind"`
+		Date jmapDate `json:"date"`
+	} `json:"anniversaries"`
+	Keywords map[string]bool `json:"keywords"`
+}
+
+// jmapDate is a JSContact PartialDate, or a Timestamp.
+type jmapDate struct {
+	Type  string `json:"@type"` // "PartialDate" if absent
+	Year  int    `json:"year"`  // 0 if unknown
+	Month int    `json:"month"`
+	Day   int    `json:"day"`
+	UTC   string `json:"utc"` // Timestamp only
+}
+
+// vCardDate formats the date as a vCard BDAY, "--MMDD" without year. It returns "" for incomplete dates.
+func (d jmapDate) vCardDate() string {
+	if d.Type == config.JSContactTimestamp {
+		t, err := time.Parse(time.RFC3339, d.UTC)
+		if err != nil {
+			return ""
+		}
+		return t.UTC().Format(config.DateFormatFullBasic)
+	}
+	if d.Month < 1 || d.Month > 12 || d.Day < 1 || d.Day > 31 {
+		return ""
+	}
+	if d.Year == 0 {
+		return fmt.Sprintf("--%02d%02d", d.Month, d.Day)
+	}
+	return fmt.Sprintf("%04d%02d%02d", d.Year, d.Month, d.Day)
+}
+
+// jmapInvocation is a method call or response: [name, arguments, call id].
+type jmapInvocation struct {
+	Name   string
+	Args   json.RawMessage
+	CallID string
+}
+
+func (inv jmapInvocation) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{inv.Name, inv.Args, inv.CallID})
+}
+
+func (inv *jmapInvocation) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 3 {
+		return errors.New(config.ErrJMAPResponse)
+	}
+	inv.Args = raw[1]
+	if err := json.Unmarshal(raw[0], &inv.Name); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw[2], &inv.CallID)
+}
+
+// FetchJMAP downloads the contact cards of the primary contacts account, a page at a time.
+// A URL without path is completed with the well-known session URL (RFC 8620, section 2.2).
+func (f *HTTPFetcher) FetchJMAP(ctx context.Context, sessionURL, user, pass string) (io.ReadCloser, error) {
+	u, err := url.Parse(sessionURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrInvalidURL, err)
+	}
+	if u.Scheme != config.SchemeHTTP && u.Scheme != config.SchemeHTTPS {
+		return nil, fmt.Errorf("%s: %s", config.ErrProtocol, u.Scheme)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = config.WellKnownJMAP
+	}
+	client, err := f.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var session jmapSession
+	if err := jmapDo(ctx, client, http.MethodGet, u.String(), nil, user, pass, &session); err != nil {
+		return nil, err
+	}
+	account := session.PrimaryAccounts[config.JMAPCapContacts]
+	if account == "" {
+		return nil, errors.New(config.ErrJMAPNoContacts)
+	}
+	apiURL, err := u.Parse(session.APIURL)
+	if err != nil || session.APIURL == "" {
+		return nil, fmt.Errorf("%s: %q", config.ErrJMAPResponse, session.APIURL)
+	}
+
+	var buf bytes.Buffer
+	enc := vcard.NewEncoder(&buf)
+	for position := 0; ; {
+		cards, err := jmapPage(ctx, client, apiURL.String(), account, position, user, pass)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range cards {
+			if card := c.vCard(); card != nil {
+				if err := enc.Encode(card); err != nil {
+					return nil, err
+				}
+			}
+		}
+		position += len(cards)
+		if len(cards) < config.JMAPPageSize {
+			break
+		}
+	}
+
+	slog.Debug(config.MsgJMAPFetched,
+		config.LogKeyComponent, config.CompFetcher,
+		config.LogKeyURL, apiURL.Redacted())
+	return io.NopCloser(&buf), nil
+}
+
+// jmapPage queries a page of contact cards and gets them in the same request, with a back-reference.
+func jmapPage(ctx context.Context, client *http.Client, apiURL, account string, position int, user, pass string) ([]jmapCard, error) {
+	query, _ := json.Marshal(map[string]any{
+		"accountId": account,
+		"position":  position,
+		"limit":     config.JMAPPageSize,
+	})
+	get, _ := json.Marshal(map[string]any{
+		"accountId":  account,
+		"#ids":       map[string]string{"resultOf": "q", "name": config.JMAPMethodQuery, "path": "/ids"},
+		"properties": config.JMAPCardProperties,
+	})
+	req := map[string]any{
+		"using": []string{config.JMAPCapCore, config.JMAPCapContacts},
+		"methodCalls": []jmapInvocation{
+			{Name: config.JMAPMethodQuery, Args: query, CallID: "q"},
+			{Name: config.JMAPMethodGet, Args: get, CallID: "g"},
+		},
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		MethodResponses []jmapInvocation `json:"methodResponses"`
+	}
+	if err := jmapDo(ctx, client, http.MethodPost, apiURL, body, user, pass, &resp); err != nil {
+		return nil, err
+	}
+	for _, inv := range resp.MethodResponses {
+		switch inv.Name {
+		case config.JMAPMethodError:
+			var e struct {
+				Type string `json:"type"`
+			}
+			_ = json.Unmarshal(inv.Args, &e)
+			return nil, fmt.Errorf("%s: %s", config.ErrJMAPMethod, e.Type)
+		case config.JMAPMethodGet:
+			var got struct {
+				List []jmapCard `json:"list"`
+			}
+			if err := json.Unmarshal(inv.Args, &got); err != nil {
+				return nil, fmt.Errorf("%s: %w", config.ErrJMAPResponse, err)
+			}
+			return got.List, nil
+		}
+	}
+	return nil, errors.New(config.ErrJMAPResponse)
+}
+
+// jmapDo sends a JMAP request and decodes the JSON answer into out.
+func jmapDo(ctx context.Context, client *http.Client, method, target string, body []byte, user, pass string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(config.HeaderUserAgent, config.UserAgent)
+	req.Header.Set(config.HeaderAccept, config.MimeJSON)
+	if body != nil {
+		req.Header.Set(config.HeaderContentType, config.MimeJSON)
+	}
+	resp, err := send(ctx, client, req, user, pass)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{
+			Code:       resp.StatusCode,
+			Status:     resp.Status,
+			RetryAfter: parseRetryAfter(resp.Header.Get(config.HeaderRetryAfter), time.Now()),
+		}
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, config.JMAPMaxResponse)).Decode(out); err != nil {
+		return fmt.Errorf("%s: %w", config.ErrJMAPResponse, err)
+	}
+	return nil
+}
+
+// vCard converts the card, keeping what the calendar needs: name, birthday and keywords as CATEGORIES.
+// It returns nil for groups.
+func (c jmapCard) vCard() vcard.Card {
+	if c.Kind == config.JSContactKindGroup {
+		return nil
+	}
+	card := make(vcard.Card)
+	card.SetValue(config.PropVersion, config.VCardVersion4)
+
+	uid := c.UID
+	if uid == "" {
+		uid = c.ID
+	}
+	card.SetValue(config.VCardUID, uid)
+
+	name := c.Name.Full
+	if name == "" {
+		var parts []string
+		for _, comp := range c.Name.Components {
+			if comp.Value != "" && (comp.Kind == config.JSContactGiven || comp.Kind == config.JSContactSurname) {
+				parts = append(parts, comp.Value)
+			}
+		}
+		name = strings.Join(parts, " ")
+	}
+	card.SetValue(config.VCardFN, name)
+
+	for _, a := range c.Anniversaries {
+		if a.Kind != config.JSContactBirth {
+			continue
+		}
+		if bday := a.Date.vCardDate(); bday != "" {
+			card.SetValue(config.VCardBDAY, bday)
+			break
+		}
+	}
+
+	var categories []string
+	for k, set := range c.Keywords {
+		if set {
+			categories = append(categories, k)
+		}
+	}
+	if len(categories) > 0 {
+		sort.Strings(categories) // Map order is random
+		card.SetValue(config.PropCategories, strings.Join(categories, ","))
+	}
+	return card
+}
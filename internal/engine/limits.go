@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/emersion/go-vcard"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// ErrLimitExceeded is wrapped by the errors of a synchronization stopped by one of its Limits.
+var ErrLimitExceeded = errors.New(config.ErrLimitExceeded)
+
+// Limits bound the resources used by a synchronization. Zero values select the defaults.
+type Limits struct {
+	MaxDownloadBytes int64 // Size of the address book (download, file or directory)
+	MaxContacts      int   // vCards in the address book
+	MaxCardBytes     int64 // Size of a single vCard; larger ones are skipped
+
+	RequestSpacing time.Duration // Between two requests to the server of the source, none by default
+}
+
+// withDefaults replaces the zero (or negative) limits with the defaults.
+func (l Limits) withDefaults() Limits {
+	if l.MaxDownloadBytes <= 0 {
+		l.MaxDownloadBytes = config.DefaultMaxDownloadMB * config.BytesPerMB
+	}
+	if l.MaxContacts <= 0 {
+		l.MaxContacts = config.DefaultMaxContacts
+	}
+	if l.MaxCardBytes <= 0 {
+		l.MaxCardBytes = config.DefaultMaxCardMB * config.BytesPerMB
+	}
+	return l
+}
+
+// limitReader fails with ErrLimitExceeded once more than max bytes are available,
+// rather than ending the stream silently in the middle of a vCard.
+type limitReader struct {
+	io.ReadCloser
+	remaining int64
+	max       int64
+}
+
+func newLimitReader(rc io.ReadCloser, max int64) *limitReader {
+	return &limitReader{ReadCloser: rc, remaining: max, max: max}
+}
+
+func (r *limitReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		// Only fail if the source really goes on.
+		var probe [1]byte
+		if n, err := r.ReadCloser.Read(probe[:]); n == 0 {
+			return 0, err
+		}
+		return 0, fmt.Errorf("%w: "+config.ErrSourceTooLarge, ErrLimitExceeded, r.max)
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.remaining -= int64(n)
+	return n, err
+}
+
+// bytesRead returns the bytes read so far from r, if it is a limitReader.
+func bytesRead(r io.Reader) int64 {
+	if lr, ok := r.(*limitReader); ok {
+		return lr.max - lr.remaining
+	}
+	return 0
+}
+
+// cardSize approximates the encoded size of a vCard from its property names, parameters and values.
+func cardSize(card vcard.Card) int64 {
+	var n int64
+	for name, fields := range card {
+		for _, f := range fields {
+			n += int64(len(name) + len(f.Value))
+			for param, values := range f.Params {
+				n += int64(len(param))
+				for _, v := range values {
+					n += int64(len(v))
+				}
+			}
+		}
+	}
+	return n
+}
@@ -1,10 +1,12 @@
 package engine
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/tartampluch/go-birthday/internal/config"
 )
 
 // TestCalculateNextOccurrence verifies the core temporal logic of the application.
@@ -76,7 +78,7 @@ func TestCalculateNextOccurrence(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			next, age := calculateNextOccurrence(now, tt.birthDate, tt.yearKnown)
+			next, age := CalculateNextOccurrence(now, tt.birthDate, tt.yearKnown)
 			assert.Equal(t, tt.expectedDate, next, tt.desc)
 			assert.Equal(t, tt.expectedAge, age, "Age calculation mismatch")
 		})
@@ -89,9 +91,115 @@ func TestCalculateNextOccurrence_LeapYearContext(t *testing.T) {
 	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	birthDate := time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC) // Leapling
 
-	next, _ := calculateNextOccurrence(now, birthDate, true)
+	next, _ := CalculateNextOccurrence(now, birthDate, true)
 
 	// In 2024, Feb 29 exists. It should be preserved.
 	expected := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
 	assert.Equal(t, expected, next, "In a leap year, the birthday should be Feb 29, not Mar 1")
 }
+
+// TestSystemError verifies that a refused access to Contacts is told apart from other export failures.
+func TestSystemError(t *testing.T) {
+	err := systemError("execution error: Not authorized to send Apple events to Contacts. (-1743)\n", errors.New("exit status 1"))
+	assert.ErrorIs(t, err, ErrSystemDenied)
+
+	err = systemError("execution error: Contacts got an error: AppleEvent timed out. (-1712)", errors.New("exit status 1"))
+	assert.NotErrorIs(t, err, ErrSystemDenied)
+	assert.ErrorContains(t, err, config.ErrSystemExport)
+	assert.ErrorContains(t, err, "-1712")
+}
+
+// TestOutlookCards verifies the conversion of the contacts printed by the Outlook export script.
+func TestOutlookCards(t *testing.T) {
+	out := config.UTF8BOM + `[{"id":"00A1","name":"Alice Martin","first":"Alice","last":"Martin","birthday":"1990-03-15","categories":"Family; Friends"},` +
+		`{"id":"00B2","name":"","first":"Bob","last":"Durand","birthday":"4501-01-01","categories":""}]` + "\r\n"
+	cards, err := outlookCards([]byte(out))
+	assert.NoError(t, err)
+	assert.Len(t, cards, 2)
+	assert.Equal(t, "00A1", cards[0].Value(config.VCardUID))
+	assert.Equal(t, "1990-03-15", cards[0].Value(config.VCardBDAY))
+	assert.Equal(t, "Family,Friends", cards[0].Value(config.PropCategories))
+	assert.Equal(t, "Bob Durand", cards[1].Value(config.VCardFN), "The full name is rebuilt when missing")
+	assert.Empty(t, cards[1].Value(config.VCardBDAY), "4501-01-01 stands for no birthday")
+
+	cards, err = outlookCards(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, cards)
+
+	err = outlookError("Exception: Retrieving the COM class factory failed due to the following error: 80040154 Class not registered", errors.New("exit status 1"))
+	assert.ErrorIs(t, err, ErrOutlookUnavailable)
+}
+
+// TestZodiacSign verifies the sign boundaries and the decoration of birthday summaries.
+func TestZodiacSign(t *testing.T) {
+	tests := []struct {
+		month time.Month
+		day   int
+		want  string
+	}{
+		{time.January, 1, "♑"},
+		{time.January, 19, "♑"},
+		{time.January, 20, "♒"},
+		{time.February, 29, "♓"},
+		{time.March, 21, "♈"},
+		{time.July, 22, "♋"},
+		{time.July, 23, "♌"},
+		{time.December, 21, "♐"},
+		{time.December, 31, "♑"},
+	}
+	for _, tt := range tests {
+		date := time.Date(config.DefaultLeapYear, tt.month, tt.day, 0, 0, 0, 0, time.UTC)
+		assert.Equal(t, tt.want, config.ZodiacEmojis[ZodiacSign(date)], date.Format("01-02"))
+	}
+
+	g := &Generator{}
+	birth := time.Date(1990, 8, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	events, _ := g.createEvents("Jane", birth, true, gregorian(birth, true, 0, time.UTC), SyncConfig{ZodiacSummary: true}, now, "uid")
+	summary, err := events[0].Props.Text(config.PropSummary)
+	assert.NoError(t, err)
+	assert.Equal(t, "Birthday: Jane ♌", summary)
+}
+
+// TestLunarCalendar verifies the conversions to and from the Chinese calendar.
+func TestLunarCalendar(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		date time.Time
+		want lunarDate
+	}{
+		{day(1900, time.January, 31), lunarDate{year: 1900, month: 1, day: 1}},
+		{day(1990, time.October, 3), lunarDate{year: 1990, month: 8, day: 15}},   // Mid-Autumn
+		{day(2024, time.February, 9), lunarDate{year: 2023, month: 12, day: 30}}, // New Year's Eve
+		{day(2023, time.March, 22), lunarDate{year: 2023, month: 2, day: 1, leap: true}},
+		{day(2023, time.April, 20), lunarDate{year: 2023, month: 3, day: 1}},
+	}
+	for _, tt := range tests {
+		got, ok := toLunar(tt.date)
+		assert.True(t, ok)
+		assert.Equal(t, tt.want, got, tt.date.Format(time.DateOnly))
+	}
+	_, ok := toLunar(day(1900, time.January, 30))
+	assert.False(t, ok, "Before the table")
+
+	date, ok := fromLunar(2024, 8, 15, time.UTC)
+	assert.True(t, ok)
+	assert.Equal(t, day(2024, time.September, 17), date)
+	date, _ = fromLunar(2025, 12, 30, time.UTC)
+	assert.Equal(t, day(2026, time.February, 16), date, "The 30th of a short month is its last day")
+	_, ok = fromLunar(2101, 1, 1, time.UTC)
+	assert.False(t, ok, "After the table")
+
+	// Born on a Mid-Autumn day, celebrated on the next one.
+	birth, _ := toLunar(day(1990, time.October, 3))
+	next, age, ok := nextOccurrence(day(2025, time.January, 1), lunar(birth, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, day(2025, time.October, 6), next)
+	assert.Equal(t, 35, age)
+
+	// Born in a leap month: celebrated in the regular month of the same number.
+	birth, _ = toLunar(day(2023, time.March, 22))
+	date, _, _ = lunar(birth, 0, time.UTC)(2024)
+	assert.Equal(t, day(2024, time.March, 10), date)
+}
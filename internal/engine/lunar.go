@@ -0,0 +1,148 @@
+package engine
+
+import "time"
+
+// Chinese lunisolar calendar from 1900 to 2100, one entry per lunar year:
+// bits 15 to 4 flag the long months (30 days rather than 29) from the 1st to the 12th,
+// bits 3 to 0 give the leap month (0 for none) and bit 16 flags a long leap month.
+var lunarYears = [...]uint32{
+	0x04bd8, 0x04ae0, 0x0a570, 0x054d5, 0x0d260, 0x0d950, 0x16554, 0x056a0, 0x09ad0, 0x055d2, // 1900
+	0x04ae0, 0x0a5b6, 0x0a4d0, 0x0d250, 0x1d255, 0x0b540, 0x0d6a0, 0x0ada2, 0x095b0, 0x14977, // 1910
+	0x04970, 0x0a4b0, 0x0b4b5, 0x06a50, 0x06d40, 0x1ab54, 0x02b60, 0x09570, 0x052f2, 0x04970, // 1920
+	0x06566, 0x0d4a0, 0x0ea50, 0x16a95, 0x05ad0, 0x02b60, 0x186e3, 0x092e0, 0x1c8d7, 0x0c950, // 1930
+	0x0d4a0, 0x1d8a6, 0x0b550, 0x056a0, 0x1a5b4, 0x025d0, 0x092d0, 0x0d2b2, 0x0a950, 0x0b557, // 1940
+	0x06ca0, 0x0b550, 0x15355, 0x04da0, 0x0a5b0, 0x14573, 0x052b0, 0x0a9a8, 0x0e950, 0x06aa0, // 1950
+	0x0aea6, 0x0ab50, 0x04b60, 0x0aae4, 0x0a570, 0x05260, 0x0f263, 0x0d950, 0x05b57, 0x056a0, // 1960
+	0x096d0, 0x04dd5, 0x04ad0, 0x0a4d0, 0x0d4d4, 0x0d250, 0x0d558, 0x0b540, 0x0b6a0, 0x195a6, // 1970
+	0x095b0, 0x049b0, 0x0a974, 0x0a4b0, 0x0b27a, 0x06a50, 0x06d40, 0x0af46, 0x0ab60, 0x09570, // 1980
+	0x04af5, 0x04970, 0x064b0, 0x074a3, 0x0ea50, 0x06b58, 0x05ac0, 0x0ab60, 0x096d5, 0x092e0, // 1990
+	0x0c960, 0x0d954, 0x0d4a0, 0x0da50, 0x07552, 0x056a0, 0x0abb7, 0x025d0, 0x092d0, 0x0cab5, // 2000
+	0x0a950, 0x0b4a0, 0x0baa4, 0x0ad50, 0x055d9, 0x04ba0, 0x0a5b0, 0x15176, 0x052b0, 0x0a930, // 2010
+	0x07954, 0x06aa0, 0x0ad50, 0x05b52, 0x04b60, 0x0a6e6, 0x0a4e0, 0x0d260, 0x0ea65, 0x0d530, // 2020
+	0x05aa0, 0x076a3, 0x096d0, 0x04afb, 0x04ad0, 0x0a4d0, 0x1d0b6, 0x0d250, 0x0d520, 0x0dd45, // 2030
+	0x0b5a0, 0x056d0, 0x055b2, 0x049b0, 0x0a577, 0x0a4b0, 0x0aa50, 0x1b255, 0x06d20, 0x0ada0, // 2040
+	0x14b63, 0x09370, 0x049f8, 0x04970, 0x064b0, 0x168a6, 0x0ea50, 0x06b20, 0x1a6c4, 0x0aae0, // 2050
+	0x092e0, 0x0d2e3, 0x0c960, 0x0d557, 0x0d4a0, 0x0da50, 0x05d55, 0x056a0, 0x0a6d0, 0x055d4, // 2060
+	0x052d0, 0x0a9b8, 0x0a950, 0x0b4a0, 0x0b6a6, 0x0ad50, 0x055a0, 0x0aba4, 0x0a5b0, 0x052b0, // 2070
+	0x0b273, 0x06930, 0x07337, 0x06aa0, 0x0ad50, 0x14b55, 0x04b60, 0x0a570, 0x054e4, 0x0d160, // 2080
+	0x0e968, 0x0d520, 0x0daa0, 0x16aa6, 0x056d0, 0x04ae0, 0x0a9d4, 0x0a2d0, 0x0d150, 0x0f252, // 2090
+	0x0d520, // 2100
+}
+
+const lunarFirstYear = 1900
+
+// lunarEpoch is the 1st day of the 1st month of lunarFirstYear.
+var lunarEpoch = time.Date(lunarFirstYear, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+// lunarDate is a day of the Chinese calendar.
+type lunarDate struct {
+	year, month, day int
+	leap             bool // Day of the leap month following month
+}
+
+func lunarMonthDays(year, month int) int {
+	if lunarYears[year-lunarFirstYear]&(0x10000>>month) != 0 {
+		return 30
+	}
+	return 29
+}
+
+func lunarLeapMonth(year int) int {
+	return int(lunarYears[year-lunarFirstYear] & 0xf)
+}
+
+func lunarLeapDays(year int) int {
+	switch {
+	case lunarLeapMonth(year) == 0:
+		return 0
+	case lunarYears[year-lunarFirstYear]&0x10000 != 0:
+		return 30
+	default:
+		return 29
+	}
+}
+
+func lunarYearDays(year int) int {
+	days := lunarLeapDays(year)
+	for month := 1; month <= 12; month++ {
+		days += lunarMonthDays(year, month)
+	}
+	return days
+}
+
+// toLunar converts the day of t to the Chinese calendar. It returns false outside the table.
+func toLunar(t time.Time) (lunarDate, bool) {
+	days := int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Sub(lunarEpoch).Hours() / 24)
+	if days < 0 {
+		return lunarDate{}, false
+	}
+	for year := lunarFirstYear; year < lunarFirstYear+len(lunarYears); year++ {
+		if n := lunarYearDays(year); days >= n {
+			days -= n
+			continue
+		}
+		leap := lunarLeapMonth(year)
+		for month := 1; month <= 12; month++ {
+			n := lunarMonthDays(year, month)
+			if days < n {
+				return lunarDate{year: year, month: month, day: days + 1}, true
+			}
+			days -= n
+			if month == leap {
+				if n = lunarLeapDays(year); days < n {
+					return lunarDate{year: year, month: month, day: days + 1, leap: true}, true
+				}
+				days -= n
+			}
+		}
+	}
+	return lunarDate{}, false
+}
+
+// fromLunar returns the Gregorian date, in loc, of a day of a regular (not leap) month
+// of the Chinese calendar. The 30th of a month that only has 29 days that year becomes the 29th.
+// It returns false outside the table.
+func fromLunar(year, month, day int, loc *time.Location) (time.Time, bool) {
+	if year < lunarFirstYear || year >= lunarFirstYear+len(lunarYears) {
+		return time.Time{}, false
+	}
+	days := 0
+	for y := lunarFirstYear; y < year; y++ {
+		days += lunarYearDays(y)
+	}
+	leap := lunarLeapMonth(year)
+	for m := 1; m < month; m++ {
+		days += lunarMonthDays(year, m)
+		if m == leap {
+			days += lunarLeapDays(year)
+		}
+	}
+	days += min(day, lunarMonthDays(year, month)) - 1
+	t := lunarEpoch.AddDate(0, 0, days)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc), true
+}
+
+// lunar returns the anniversaries of a birth in the Chinese calendar: the event of year y
+// falls on the same day of the same month of the lunar year y, which may end in January or
+// February of the next Gregorian year. Births in a leap month are celebrated in the regular one.
+// There are none after lastYear unless it is 0.
+func lunar(birth lunarDate, lastYear int, loc *time.Location) occurrence {
+	return func(y int) (time.Time, int, bool) {
+		if y < birth.year || (lastYear != 0 && y > lastYear) {
+			return time.Time{}, 0, false
+		}
+		date, ok := fromLunar(y, birth.month, birth.day, loc)
+		return date, y - birth.year, ok
+	}
+}
+
+// nextOccurrence returns the first anniversary of occur from the day of now on, with its number of years.
+func nextOccurrence(now time.Time, occur occurrence) (time.Time, int, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for y := now.Year() - 1; y <= now.Year()+1; y++ {
+		if date, years, ok := occur(y); ok && !date.Before(today) {
+			return date, years, true
+		}
+	}
+	return time.Time{}, 0, false
+}
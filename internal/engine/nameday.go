@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/emersion/go-vcard"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// Name-day tables, one per language, mapping "MM-DD" to the first names celebrated that day.
+//
+//go:embed namedays/*.json
+var nameDayFS embed.FS
+
+// NameDayLocales returns the languages of the bundled name-day tables (e.g., "fr"), sorted.
+func NameDayLocales() []string {
+	entries, err := fs.ReadDir(nameDayFS, config.NameDayDir)
+	if err != nil {
+		return nil
+	}
+	locales := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if locale, ok := strings.CutSuffix(e.Name(), config.ExtJSON); ok {
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
+
+// nameDays maps lowercase first names to the days (in config.DefaultLeapYear) of their name day.
+type nameDays map[string][]time.Time
+
+// loadNameDays reads the name-day table of locale.
+func loadNameDays(locale string) (nameDays, error) {
+	data, err := nameDayFS.ReadFile(path.Join(config.NameDayDir, locale+config.ExtJSON))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %q", config.ErrNameDayLocale, locale)
+	}
+	var table map[string][]string
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, err
+	}
+
+	days := make(nameDays)
+	for day, names := range table {
+		t, err := time.Parse(config.NameDayDateFormat, day)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			key := strings.ToLower(name)
+			days[key] = append(days[key], withoutYear(t))
+		}
+	}
+	// Map iteration is random: keep the events of a name in a stable order.
+	for _, d := range days {
+		sort.Slice(d, func(i, j int) bool { return d[i].Before(d[j]) })
+	}
+	return days, nil
+}
+
+// lookup returns the name days of the first name of a card: its given name (N),
+// or the first word of name. A compound first name without a day of its own
+// (e.g., "Jean-Pierre") falls back to its first part.
+func (nd nameDays) lookup(card vcard.Card, name string) []time.Time {
+	first := ""
+	if n := card.Name(); n != nil {
+		first = n.GivenName
+	}
+	if first = strings.TrimSpace(first); first == "" {
+		if fields := strings.Fields(name); len(fields) > 0 {
+			first = fields[0]
+		}
+	}
+	// Several given names ("Marie Claire"): the first one counts.
+	if fields := strings.Fields(first); len(fields) > 0 {
+		first = fields[0]
+	}
+
+	first = strings.ToLower(first)
+	if days, ok := nd[first]; ok {
+		return days
+	}
+	if part, _, ok := strings.Cut(first, config.NameDaySeparator); ok {
+		return nd[part]
+	}
+	return nil
+}
+
+// createNameDayEvents generates the yearly events of a name day.
+// They never count as "today" birthdays.
+func (g *Generator) createNameDayEvents(name string, day time.Time, cfg SyncConfig, now time.Time) []*ical.Event {
+	input := fmt.Sprintf(config.FormatHashInput, name+"|"+config.NameDayDir+"|"+cfg.NameDays, day.Format(time.RFC3339), config.UIDSalt)
+	hash := sha256.Sum256([]byte(input))
+	uidBase := fmt.Sprintf("%x", hash[:config.UIDHashLength])
+
+	events, _ := yearlyEvents(day, false, 0, now, uidBase, func(int) (string, []string) {
+		if g.FormatNameDaySummary != nil {
+			return g.FormatNameDaySummary(name), cfg.ReminderTriggers
+		}
+		return fmt.Sprintf(config.FallbackNameDay, name), cfg.ReminderTriggers
+	})
+	return events
+}
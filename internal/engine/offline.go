@@ -0,0 +1,23 @@
+package engine
+
+import (
+	"errors"
+	"net"
+	"slices"
+	"syscall"
+)
+
+// IsOffline reports whether err comes from the machine having no network, as after waking from
+// sleep: the name of the server cannot be resolved, or no route leads to it. A server refusing
+// the connection or failing is not offline.
+func IsOffline(err error) bool {
+	if !errors.Is(err, ErrNetwork) {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var errno syscall.Errno
+	return errors.As(err, &errno) && slices.Contains(offlineErrnos, errno)
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package engine
+
+import "syscall"
+
+// offlineErrnos are the errors of connecting without any usable network.
+var offlineErrnos = []syscall.Errno{syscall.ENETUNREACH, syscall.EHOSTUNREACH, syscall.ENETDOWN, syscall.EADDRNOTAVAIL}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package engine
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// offlineErrnos are the errors of connecting without any usable network (Winsock codes).
+var offlineErrnos = []syscall.Errno{windows.WSAENETUNREACH, windows.WSAEHOSTUNREACH, windows.WSAENETDOWN, windows.WSAEADDRNOTAVAIL}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// Offset is an extra anniversary shifted from the birthday: Months after every birthday
+// (e.g., 6 for half birthdays), or Days after the birth, once (e.g., 100 days old).
+// Exactly one of them is set.
+type Offset struct {
+	Months int
+	Days   int
+}
+
+// ParseOffset reads an offset written as "6m" (months after every birthday) or "100d" (days after the birth).
+func ParseOffset(s string) (Offset, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if n, ok := strings.CutSuffix(s, config.OffsetUnitMonths); ok {
+		if months, err := strconv.Atoi(n); err == nil && months > 0 && months < 12 {
+			return Offset{Months: months}, nil
+		}
+	} else if n, ok := strings.CutSuffix(s, config.OffsetUnitDays); ok {
+		if days, err := strconv.Atoi(n); err == nil && days > 0 && days <= config.MaxOffsetDays {
+			return Offset{Days: days}, nil
+		}
+	}
+	return Offset{}, fmt.Errorf("%s: %q", config.ErrOffsetFormat, s)
+}
+
+// String returns the offset in the form read by ParseOffset.
+func (o Offset) String() string {
+	if o.Months > 0 {
+		return strconv.Itoa(o.Months) + config.OffsetUnitMonths
+	}
+	return strconv.Itoa(o.Days) + config.OffsetUnitDays
+}
+
+// offsetDate returns the date of birth shifted by the offset. Shifting by months clamps the day
+// to the end of shorter months (e.g., August 31 + 6 months is the last day of February).
+func (o Offset) offsetDate(birth time.Time) time.Time {
+	if o.Months == 0 {
+		return birth.AddDate(0, 0, o.Days)
+	}
+	first := time.Date(birth.Year(), birth.Month()+time.Month(o.Months), 1, 0, 0, 0, 0, birth.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(birth.Day(), last)-1)
+}
+
+// contactOffsets returns the offset anniversaries of a contact, without duplicates.
+func contactOffsets(cfg SyncConfig, uid string) []Offset {
+	var offsets []Offset
+	for _, o := range append(append([]Offset{}, cfg.Offsets...), cfg.ContactOffsets[uid]...) {
+		if !slices.Contains(offsets, o) {
+			offsets = append(offsets, o)
+		}
+	}
+	return offsets
+}
+
+// createOffsetEvents generates the events of an offset anniversary, up to lastYear unless it is 0.
+// Days counted from the birth need the year of birth. They never count as "today" birthdays.
+func (g *Generator) createOffsetEvents(name string, birthDate time.Time, yearKnown bool, lastYear int, o Offset, cfg SyncConfig, now time.Time) []*ical.Event {
+	if o.Days > 0 && !yearKnown {
+		return nil
+	}
+	input := fmt.Sprintf(config.FormatHashInput, name+"|"+o.String(), birthDate.Format(time.RFC3339), config.UIDSalt)
+	hash := sha256.Sum256([]byte(input))
+	uidBase := fmt.Sprintf("%x", hash[:config.UIDHashLength])
+
+	date := o.offsetDate(birthDate)
+	occur := gregorian(date, yearKnown, lastYear, now.Location())
+	if o.Days > 0 {
+		occur = func(y int) (time.Time, int, bool) {
+			if y != date.Year() || (lastYear != 0 && y > lastYear) {
+				return time.Time{}, 0, false
+			}
+			return time.Date(y, date.Month(), date.Day(), 0, 0, 0, 0, now.Location()), 0, true
+		}
+	}
+
+	events, _ := anniversaryEvents(occur, now, uidBase, func(int) (string, []string) {
+		if g.FormatOffsetSummary != nil {
+			return g.FormatOffsetSummary(name, o), cfg.ReminderTriggers
+		}
+		switch {
+		case o.Months == config.HalfBirthdayMonths:
+			return fmt.Sprintf(config.FallbackHalfBirthday, name), cfg.ReminderTriggers
+		case o.Months > 0:
+			return fmt.Sprintf(config.FallbackOffsetMonths, name, o.Months), cfg.ReminderTriggers
+		default:
+			return fmt.Sprintf(config.FallbackOffsetDays, name, o.Days), cfg.ReminderTriggers
+		}
+	})
+	return events
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/emersion/go-vcard"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// ErrOutlookUnavailable is returned when Outlook is not installed or refused the access to its contacts.
+var ErrOutlookUnavailable = errors.New(config.ErrOutlookUnavailable)
+
+// outlookContact is a contact item of Outlook, as printed by config.ScriptOutlookContacts.
+type outlookContact struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	First      string `json:"first"`
+	Last       string `json:"last"`
+	Birthday   string `json:"birthday"` // yyyy-MM-dd, config.OutlookNoDate when unset
+	Categories string `json:"categories"`
+}
+
+// outlookCards converts the JSON array printed by the export script to vCards.
+func outlookCards(data []byte) ([]vcard.Card, error) {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte(config.UTF8BOM))
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var contacts []outlookContact
+	if err := json.Unmarshal(data, &contacts); err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrOutlookExport, err)
+	}
+
+	cards := make([]vcard.Card, 0, len(contacts))
+	for _, c := range contacts {
+		card := make(vcard.Card)
+		card.SetValue(config.PropVersion, config.VCardVersion4)
+		card.SetValue(config.VCardUID, c.ID)
+		setNames(card, c.Name, c.First, c.Last)
+		if c.Birthday != "" && c.Birthday != config.OutlookNoDate {
+			card.SetValue(config.VCardBDAY, c.Birthday)
+		}
+		// The separator of categories follows the list separator of the Windows locale.
+		categories := strings.FieldsFunc(c.Categories, func(r rune) bool { return r == ',' || r == ';' })
+		for i := range categories {
+			categories[i] = strings.TrimSpace(categories[i])
+		}
+		if len(categories) > 0 {
+			card.SetValue(config.PropCategories, strings.Join(categories, ","))
+		}
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
+
+// outlookError explains a failed export of the Outlook contacts from the output of the script.
+func outlookError(stderr string, err error) error {
+	stderr = strings.TrimSpace(stderr)
+	for _, code := range config.OutlookUnavailableCodes {
+		if strings.Contains(stderr, code) {
+			return fmt.Errorf("%w: %s", ErrOutlookUnavailable, stderr)
+		}
+	}
+	if stderr != "" {
+		return fmt.Errorf("%s: %w: %s", config.ErrOutlookExport, err, stderr)
+	}
+	return fmt.Errorf("%s: %w", config.ErrOutlookExport, err)
+}
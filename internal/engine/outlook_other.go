@@ -0,0 +1,16 @@
+//go:build !windows
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// openOutlook is only available on Windows.
+func openOutlook(context.Context) (io.ReadCloser, error) {
+	return nil, errors.New(config.ErrOutlookUnsupported)
+}
@@ -0,0 +1,44 @@
+//go:build windows
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"syscall"
+
+	"github.com/emersion/go-vcard"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// openOutlook exports the contacts of the local Outlook profile through its COM interface,
+// with PowerShell, so that no Microsoft Graph consent is needed.
+func openOutlook(ctx context.Context) (io.ReadCloser, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, config.PowerShellBin, "-NoProfile", "-NonInteractive", "-Command", config.ScriptOutlookContacts)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// No console window flashing over the desktop at each synchronization.
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true, CreationFlags: config.WinCreateNoWindow}
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, outlookError(stderr.String(), err)
+	}
+
+	cards, err := outlookCards(stdout.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := vcard.NewEncoder(&buf)
+	for _, card := range cards {
+		if err := enc.Encode(card); err != nil {
+			return nil, err
+		}
+	}
+	return io.NopCloser(&buf), nil
+}
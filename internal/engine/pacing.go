@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// pacer spaces the requests sent to each server, so that shared servers are not hammered.
+type pacer struct {
+	mu   sync.Mutex
+	next map[string]time.Time // Host -> earliest time of its next request
+}
+
+// requests paces every request of the synchronizations, see Limits.RequestSpacing.
+var requests = &pacer{next: make(map[string]time.Time)}
+
+// wait blocks until a request may be sent to host, spacing after the previous one, and
+// reserves its turn. It returns early with the error of ctx.
+func (p *pacer) wait(ctx context.Context, host string, spacing time.Duration) error {
+	if spacing <= 0 {
+		return nil
+	}
+	p.mu.Lock()
+	now := time.Now()
+	at := p.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	p.next[host] = at.Add(spacing)
+	p.mu.Unlock()
+
+	if at.Equal(now) {
+		return nil
+	}
+	return sleepContext(ctx, at.Sub(now))
+}
+
+type spacingKey struct{}
+
+// withSpacing passes the request spacing of a synchronization down to the fetcher.
+func withSpacing(ctx context.Context, spacing time.Duration) context.Context {
+	return context.WithValue(ctx, spacingKey{}, spacing)
+}
+
+func spacingFrom(ctx context.Context) time.Duration {
+	d, _ := ctx.Value(spacingKey{}).(time.Duration)
+	return d
+}
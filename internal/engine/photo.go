@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/emersion/go-ical"
+	"github.com/emersion/go-vcard"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// Photo holds a contact picture extracted from a vCard PHOTO property.
+// Either Data (embedded picture) or URL (external reference) is set.
+type Photo struct {
+	Data     []byte
+	MimeType string // e.g., "image/jpeg"
+	URL      string
+}
+
+// extractPhoto decodes the PHOTO property of a vCard.
+// It supports vCard 3.0 inline binaries (ENCODING=b), vCard 4.0 data URIs
+// and plain http(s) references. It returns nil if no usable photo is found.
+func extractPhoto(card vcard.Card) *Photo {
+	field := card.Get(vcard.FieldPhoto)
+	if field == nil || field.Value == "" {
+		return nil
+	}
+	value := strings.TrimSpace(field.Value)
+
+	// vCard 4.0: PHOTO:data:image/jpeg;base64,....
+	if strings.HasPrefix(value, config.DataURIPrefix) {
+		meta, payload, ok := strings.Cut(strings.TrimPrefix(value, config.DataURIPrefix), ",")
+		if !ok || !strings.HasSuffix(meta, config.DataURIBase64) {
+			return nil
+		}
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil || len(data) == 0 {
+			return nil
+		}
+		return &Photo{Data: data, MimeType: strings.TrimSuffix(meta, config.DataURIBase64)}
+	}
+
+	// External reference: PHOTO;VALUE=uri:https://...
+	if u, err := url.Parse(value); err == nil && (u.Scheme == config.SchemeHTTP || u.Scheme == config.SchemeHTTPS) {
+		return &Photo{URL: value}
+	}
+
+	// vCard 3.0: PHOTO;ENCODING=b;TYPE=JPEG:....
+	enc := strings.ToLower(field.Params.Get(config.VCardParamEncoding))
+	if enc != config.VCardEncodingB && enc != config.VCardEncodingBase64 {
+		return nil
+	}
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+
+	mimeType := config.MimeImageJPEG
+	if t := field.Params.Get(vcard.ParamType); t != "" {
+		mimeType = config.MimeImagePrefix + strings.ToLower(t)
+	}
+	return &Photo{Data: data, MimeType: mimeType}
+}
+
+// buildAttachProp converts a photo into an iCalendar ATTACH property.
+// In PhotoModeInline the picture is embedded as base64 binary; in PhotoModeLink
+// it references the copy served by the local HTTP server.
+// It returns nil if the photo cannot be represented in the requested mode.
+func buildAttachProp(photo *Photo, mode, baseURL, uid string) *ical.Prop {
+	if photo == nil || mode == "" || mode == config.PhotoModeNone {
+		return nil
+	}
+
+	prop := ical.NewProp(config.PropAttach)
+
+	// External pictures cannot be embedded without downloading them; link them as-is.
+	if photo.URL != "" {
+		prop.Value = photo.URL
+		return prop
+	}
+
+	switch mode {
+	case config.PhotoModeInline:
+		prop.SetBinary(photo.Data)
+	case config.PhotoModeLink:
+		if baseURL == "" {
+			return nil
+		}
+		prop.Value = fmt.Sprintf(config.FormatPhotoURL, baseURL, uid)
+	default:
+		return nil
+	}
+
+	if photo.MimeType != "" {
+		prop.Params.Set(ical.ParamFormatType, photo.MimeType)
+	}
+	return prop
+}
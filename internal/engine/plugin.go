@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/emersion/go-vcard"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// SourceContact is a contact returned by a Source. It is also the JSON object printed by
+// the program of an ExecSource, in an array: [{"uid": "42", "name": "Jane Doe", "birthday": "1990-06-11"}].
+type SourceContact struct {
+	UID        string   `json:"uid,omitempty"`
+	Name       string   `json:"name"`
+	Birthday   string   `json:"birthday"` // vCard date: "1990-06-11", "19900611" or "--06-11" without the year
+	Categories []string `json:"categories,omitempty"`
+}
+
+// Source is a third-party contact source (e.g., a company HR system), selected by the
+// mode it is registered under. The contacts go through the same pipeline as vCards.
+type Source interface {
+	List(ctx context.Context, cfg SyncConfig) ([]SourceContact, error)
+}
+
+var (
+	sourcesMu sync.RWMutex
+	sources   = make(map[string]Source)
+)
+
+// RegisterSource makes a Source available as SyncConfig.Mode, typically from an init function.
+// It panics if the mode is empty, built in or already registered, as database/sql.Register does.
+func RegisterSource(mode string, s Source) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	if mode == "" || s == nil {
+		panic(config.ErrSourceRegister)
+	}
+	for _, builtin := range config.SourceModes {
+		if mode == builtin {
+			panic(fmt.Sprintf("%s: %q", config.ErrSourceRegister, mode))
+		}
+	}
+	if _, dup := sources[mode]; dup {
+		panic(fmt.Sprintf("%s: %q", config.ErrSourceRegister, mode))
+	}
+	sources[mode] = s
+}
+
+// Sources returns the registered modes, sorted.
+func Sources() []string {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	modes := make([]string, 0, len(sources))
+	for mode := range sources {
+		modes = append(modes, mode)
+	}
+	sort.Strings(modes)
+	return modes
+}
+
+// registeredSource returns the Source registered under mode, if any.
+func registeredSource(mode string) (Source, bool) {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	s, ok := sources[mode]
+	return s, ok
+}
+
+// ExecSource runs a program printing a JSON array of SourceContact on its standard output,
+// so that sources can be written in any language, without rebuilding the application.
+type ExecSource struct {
+	Command string   // Path of the program
+	Args    []string // Arguments of the program
+}
+
+// List runs the program and decodes its output, which may not exceed cfg.Limits.MaxDownloadBytes.
+func (s ExecSource) List(ctx context.Context, cfg SyncConfig) ([]SourceContact, error) {
+	if s.Command == "" {
+		return nil, errors.New(config.ErrExecEmpty)
+	}
+	var stderr bytes.Buffer
+	stdout := &cappedBuffer{max: cfg.Limits.withDefaults().MaxDownloadBytes}
+	cmd := exec.CommandContext(ctx, s.Command, s.Args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = config.ExecWaitDelay
+	err := cmd.Run()
+	if stdout.exceeded {
+		return nil, fmt.Errorf("%s: %w: "+config.ErrSourceTooLarge, config.ErrExecSource, ErrLimitExceeded, stdout.max)
+	}
+	// A background child keeping the output open is not waited for: the program itself succeeded.
+	if err != nil && !errors.Is(err, exec.ErrWaitDelay) {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", config.ErrExecSource, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", config.ErrExecSource, err)
+	}
+
+	var contacts []SourceContact
+	if err := json.Unmarshal(bytes.TrimSpace(stdout.buf.Bytes()), &contacts); err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrExecSource, err)
+	}
+	return contacts, nil
+}
+
+// cappedBuffer collects the output of a program, failing once more than max bytes are written,
+// which stops the copy and closes the pipe of the program. The buffer is not embedded:
+// its ReadFrom would bypass Write.
+type cappedBuffer struct {
+	buf      bytes.Buffer
+	max      int64
+	exceeded bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if int64(b.buf.Len()+len(p)) > b.max {
+		b.exceeded = true
+		return 0, ErrLimitExceeded
+	}
+	return b.buf.Write(p)
+}
+
+// openSource lists the contacts of a Source as a vCard stream.
+func openSource(ctx context.Context, s Source, cfg SyncConfig) (io.ReadCloser, error) {
+	contacts, err := s.List(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := vcard.NewEncoder(&buf)
+	for _, c := range contacts {
+		card := make(vcard.Card)
+		card.SetValue(config.PropVersion, config.VCardVersion4)
+		if c.UID != "" {
+			card.SetValue(config.VCardUID, c.UID)
+		}
+		setNames(card, c.Name, "", "")
+		if c.Birthday != "" {
+			card.SetValue(config.VCardBDAY, c.Birthday)
+		}
+		if len(c.Categories) > 0 {
+			card.SetCategories(c.Categories)
+		}
+		if err := enc.Encode(card); err != nil {
+			return nil, err
+		}
+	}
+	return io.NopCloser(&buf), nil
+}
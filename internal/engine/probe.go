@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/emersion/go-vcard"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// ProbeResult summarizes the first vCards of a source, as read by Probe.
+type ProbeResult struct {
+	Cards     int // vCards parsed
+	Malformed int // vCards that could not be parsed
+	Birthdays int // Parsed vCards with a valid birthday
+	BadDates  int // Parsed vCards whose birthday is not a vCard date
+
+	// Bytes read from the source: content without any vCard (an HTML login page, for
+	// instance) reads as no vCards at all.
+	Bytes int64
+}
+
+// Probe opens the source of cfg as RunSync does and parses its first n vCards (up to the
+// contact limit if n <= 0), without generating any event. It diagnoses a configuration:
+// the error is the one a synchronization would meet when reaching or authenticating to the source.
+func (g *Generator) Probe(ctx context.Context, cfg SyncConfig, n int) (ProbeResult, error) {
+	var res ProbeResult
+	limits := cfg.Limits.withDefaults()
+	if n <= 0 || n > limits.MaxContacts {
+		n = limits.MaxContacts
+	}
+
+	reader, err := g.acquireStream(ctx, cfg)
+	if err != nil {
+		if ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+		return res, fmt.Errorf("%s: %w", config.ErrVCardParse, err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	limited := newLimitReader(reader, limits.MaxDownloadBytes)
+	decoder := vcard.NewDecoder(limited)
+	for res.Cards+res.Malformed < n {
+		if ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+		card, err := decoder.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if errors.Is(err, ErrLimitExceeded) {
+				return res, err
+			}
+			res.Malformed++
+			continue
+		}
+
+		res.Cards++
+		if bday := card.Value(config.VCardBDAY); bday != "" {
+			if _, _, err := ParseDate(bday); err != nil {
+				res.BadDates++
+			} else {
+				res.Birthdays++
+			}
+		}
+	}
+	res.Bytes = bytesRead(limited)
+	return res, nil
+}
@@ -0,0 +1,161 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// RetryFetcher retries transient failures of another VCardFetcher (network errors, 429, 5xx)
+// with exponential backoff and jitter, so one DNS hiccup does not skip a whole sync interval.
+type RetryFetcher struct {
+	Fetcher   VCardFetcher
+	Attempts  int           // Total attempts, including the first one
+	BaseDelay time.Duration // Delay before the first retry, doubled after each failure
+	MaxDelay  time.Duration // Upper bound of a delay; a longer Retry-After ends the retries
+
+	// sleep waits between attempts; replaced in tests.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryFetcher wraps f with the default retry policy.
+func NewRetryFetcher(f VCardFetcher) *RetryFetcher {
+	return &RetryFetcher{
+		Fetcher:   f,
+		Attempts:  config.FetchRetryAttempts,
+		BaseDelay: config.FetchRetryBaseDelay,
+		MaxDelay:  config.FetchRetryMaxDelay,
+		sleep:     sleepContext,
+	}
+}
+
+// Fetch calls the wrapped fetcher until it succeeds, fails permanently or runs out of attempts.
+func (r *RetryFetcher) Fetch(ctx context.Context, url, user, pass string) (io.ReadCloser, error) {
+	var rc io.ReadCloser
+	err := r.retry(ctx, func() (err error) {
+		rc, err = r.Fetcher.Fetch(ctx, url, user, pass)
+		return err
+	})
+	return rc, err
+}
+
+// FetchIfModified retries a conditional fetch, falling back to Fetch if the wrapped fetcher does not support it.
+func (r *RetryFetcher) FetchIfModified(ctx context.Context, url, user, pass string, prev Validators) (io.ReadCloser, Validators, error) {
+	cf, ok := r.Fetcher.(ConditionalFetcher)
+	if !ok {
+		rc, err := r.Fetch(ctx, url, user, pass)
+		return rc, Validators{}, err
+	}
+
+	var rc io.ReadCloser
+	var next Validators
+	err := r.retry(ctx, func() (err error) {
+		rc, next, err = cf.FetchIfModified(ctx, url, user, pass, prev)
+		return err
+	})
+	return rc, next, err
+}
+
+// FetchJMAP retries a JMAP download with the wrapped fetcher.
+func (r *RetryFetcher) FetchJMAP(ctx context.Context, sessionURL, user, pass string) (io.ReadCloser, error) {
+	jf, ok := r.Fetcher.(JMAPFetcher)
+	if !ok {
+		return nil, errors.New(config.ErrNoJMAP)
+	}
+	var rc io.ReadCloser
+	err := r.retry(ctx, func() (err error) {
+		rc, err = jf.FetchJMAP(ctx, sessionURL, user, pass)
+		return err
+	})
+	return rc, err
+}
+
+// Discover finds the address books with the wrapped fetcher, without retrying: the user is waiting.
+func (r *RetryFetcher) Discover(ctx context.Context, baseURL, user, pass string) ([]AddressBook, error) {
+	d, ok := r.Fetcher.(Discoverer)
+	if !ok {
+		return nil, errors.New(config.ErrNoDiscovery)
+	}
+	return d.Discover(ctx, baseURL, user, pass)
+}
+
+// retry runs fetch until it succeeds, fails permanently or runs out of attempts.
+func (r *RetryFetcher) retry(ctx context.Context, fetch func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fetch()
+		if err == nil || attempt >= r.Attempts || ctx.Err() != nil || !retryable(err) {
+			return err
+		}
+
+		delay, ok := r.delay(attempt, err)
+		if !ok {
+			return err
+		}
+		slog.Warn(config.MsgFetchRetry,
+			config.LogKeyComponent, config.CompFetcher,
+			config.LogKeyAttempt, attempt,
+			config.LogKeyDelay, delay.String(),
+			config.LogKeyError, err)
+
+		if err := r.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// delay returns the wait before the next attempt: BaseDelay * 2^(attempt-1), randomized
+// between half and the full value, or the server's Retry-After if longer.
+// ok is false when the server asks to wait more than MaxDelay.
+func (r *RetryFetcher) delay(attempt int, err error) (d time.Duration, ok bool) {
+	d = min(r.BaseDelay<<(attempt-1), r.MaxDelay)
+	if d > 0 {
+		d = d/2 + rand.N(d/2+1)
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > d {
+		if statusErr.RetryAfter > r.MaxDelay {
+			return 0, false
+		}
+		d = statusErr.RetryAfter
+	}
+	return d, true
+}
+
+// retryable reports whether err is transient: a network failure or a status the server may recover from.
+// Cancellation of the caller's context is checked separately by Fetch.
+func retryable(err error) bool {
+	if errors.Is(err, ErrNetwork) {
+		return true
+	}
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	switch statusErr.Code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// sleepContext waits for d or until ctx is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// Revision is the version of an event kept between synchronizations, so that calendar
+// clients holding a copy under the same UID notice when its name or date changes.
+type Revision struct {
+	Sum      string    `json:"sum"`      // Hash of the content, see eventSum
+	Sequence int       `json:"sequence"` // SEQUENCE, incremented whenever Sum changes
+	Modified time.Time `json:"modified"` // LAST-MODIFIED, when Sum last changed
+}
+
+// Revisions maps event UIDs to their revision.
+type Revisions map[string]Revision
+
+// next returns the revision of an event with the given content, set to now if it is new or changed.
+func (r Revisions) next(uid, sum string, now time.Time) Revision {
+	prev, ok := r[uid]
+	switch {
+	case !ok:
+		return Revision{Sum: sum, Modified: now}
+	case prev.Sum != sum:
+		return Revision{Sum: sum, Sequence: prev.Sequence + 1, Modified: now}
+	}
+	return prev
+}
+
+// setRevision stamps e with SEQUENCE and LAST-MODIFIED and records its revision in next.
+func (r Revisions) setRevision(e *ical.Event, now time.Time, next Revisions) {
+	uid, err := e.Props.Text(config.PropUID)
+	if err != nil || uid == "" {
+		return
+	}
+	rev := r.next(uid, eventSum(e.Component), now)
+	next[uid] = rev
+
+	seq := ical.NewProp(config.PropSequence)
+	seq.SetValueType(ical.ValueInt)
+	seq.Value = strconv.Itoa(rev.Sequence)
+	e.Props.Set(seq)
+	e.Props.SetDateTime(config.PropLastMod, rev.Modified.UTC())
+}
+
+// eventSum hashes the properties of an event and its alarms, except those
+// changing with every run (DTSTAMP) or derived from the hash (SEQUENCE, LAST-MODIFIED).
+func eventSum(c *ical.Component) string {
+	h := sha256.New()
+	writeComponent(h, c)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeComponent writes the content of c to h in a stable order.
+func writeComponent(h hash.Hash, c *ical.Component) {
+	_, _ = fmt.Fprintf(h, "%s\n", c.Name)
+	for _, name := range slices.Sorted(maps.Keys(c.Props)) {
+		switch name {
+		case config.PropDTStamp, config.PropSequence, config.PropLastMod:
+			continue
+		}
+		for _, p := range c.Props[name] {
+			// Parameters are maps: fmt prints them with sorted keys.
+			_, _ = fmt.Fprintf(h, "%s;%v:%s\n", name, p.Params, p.Value)
+		}
+	}
+	for _, child := range c.Children {
+		writeComponent(h, child)
+	}
+}
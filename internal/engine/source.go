@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/emersion/go-vcard"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// openLocal opens a local source: either a single vCard file or a directory
+// holding one or more vCard files (e.g., one .vcf per contact, vdirsyncer layout).
+func openLocal(ctx context.Context, path string) (io.ReadCloser, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return os.Open(path)
+	}
+	return readVCardDir(ctx, path)
+}
+
+// readVCardDir recursively concatenates the .vcf/.vcard files found under dir.
+// Files are read and validated concurrently by a bounded pool of workers, each on its own:
+// unreadable or malformed files are logged and skipped so that one broken contact
+// does not abort the whole synchronization.
+func readVCardDir(ctx context.Context, dir string) (io.ReadCloser, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable sub-directory: report it and keep walking.
+			slog.Warn(config.MsgSkippedFile,
+				config.LogKeyComponent, config.CompEngine,
+				config.LogKeyFile, path,
+				config.LogKeyError, err)
+			if d != nil && d.IsDir() && path != dir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !d.IsDir() && isVCardFile(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Deterministic order, independent of the filesystem.
+	sort.Strings(files)
+
+	contents := readVCardFiles(ctx, files)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, data := range contents {
+		if data == nil {
+			continue // Skipped
+		}
+		buf.Write(data)
+		// Files do not always end with a line break; keep cards apart.
+		buf.WriteString("\r\n")
+	}
+
+	slog.Debug(config.MsgDirScanned,
+		config.LogKeyComponent, config.CompEngine,
+		config.LogKeyFile, dir,
+		config.LogKeyCount, len(files))
+
+	return io.NopCloser(&buf), nil
+}
+
+// readVCardFiles reads and validates files with at most config.SourceWorkers in parallel.
+// The result keeps the order of files, with nil for the files that were skipped.
+func readVCardFiles(ctx context.Context, files []string) [][]byte {
+	contents := make([][]byte, len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for range min(config.SourceWorkers, len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				data, err := os.ReadFile(files[i])
+				if err == nil {
+					err = validateVCards(data)
+				}
+				if err != nil {
+					slog.Warn(config.MsgSkippedFile,
+						config.LogKeyComponent, config.CompEngine,
+						config.LogKeyFile, files[i],
+						config.LogKeyError, err)
+					continue
+				}
+				contents[i] = data
+			}
+		}()
+	}
+
+	for i := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return contents
+}
+
+// isVCardFile reports whether the path has a vCard extension (case-insensitive).
+func isVCardFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == config.ExtVCF || ext == config.ExtVCard
+}
+
+// validateVCards checks that data decodes as a sequence of vCards.
+func validateVCards(data []byte) error {
+	dec := vcard.NewDecoder(bytes.NewReader(data))
+	for {
+		if _, err := dec.Decode(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// sqliteDB is a read-only view of an SQLite database file (https://www.sqlite.org/fileformat.html),
+// enough to scan the tables of an address book without a driver.
+// The committed frames of the write-ahead log are applied, so that changes a running
+// application has not checkpointed yet are seen.
+type sqliteDB struct {
+	data     []byte
+	wal      map[uint32][]byte // Page number -> latest committed content
+	pageSize int
+	usable   int // Page size minus the reserved bytes
+	pages    uint32
+}
+
+// SQLite b-tree page types.
+const (
+	sqliteInteriorTable = 0x05
+	sqliteLeafTable     = 0x0d
+)
+
+var errSQLiteCorrupt = errors.New(config.ErrSQLiteCorrupt)
+
+// openSQLite reads the database at path and its write-ahead log, if any.
+func openSQLite(path string) (*sqliteDB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < config.SQLiteHeaderSize || !bytes.HasPrefix(data, []byte(config.SQLiteMagic)) {
+		return nil, fmt.Errorf("%s: %s", config.ErrSQLiteFormat, path)
+	}
+	if enc := binary.BigEndian.Uint32(data[56:]); enc > 1 {
+		return nil, fmt.Errorf("%s: %s", config.ErrSQLiteEncoding, path)
+	}
+
+	db := &sqliteDB{data: data, pageSize: int(binary.BigEndian.Uint16(data[16:]))}
+	if db.pageSize == 1 {
+		db.pageSize = 1 << 16
+	}
+	if db.pageSize < 512 || db.pageSize&(db.pageSize-1) != 0 {
+		return nil, errSQLiteCorrupt
+	}
+	db.usable = db.pageSize - int(data[20])
+	db.pages = uint32(len(data) / db.pageSize)
+
+	wal, err := os.ReadFile(path + config.SQLiteWALSuffix)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(wal) > 0 {
+		db.applyWAL(wal)
+	}
+	return db, nil
+}
+
+// applyWAL keeps the pages of the transactions committed in wal. Frames from an earlier
+// generation of the log (other salts) or torn by a crash (bad checksum) end the log.
+func (db *sqliteDB) applyWAL(wal []byte) {
+	if len(wal) < config.SQLiteWALHeaderSize {
+		return
+	}
+	magic := binary.BigEndian.Uint32(wal)
+	if magic&^1 != config.SQLiteWALMagic || int(binary.BigEndian.Uint32(wal[8:])) != db.pageSize {
+		return
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if magic&1 == 1 {
+		order = binary.BigEndian
+	}
+	s0, s1 := walChecksum(order, wal[:24], 0, 0)
+	if s0 != binary.BigEndian.Uint32(wal[24:]) || s1 != binary.BigEndian.Uint32(wal[28:]) {
+		return
+	}
+	salt := wal[16:24]
+
+	pending := make(map[uint32][]byte)
+	frameSize := config.SQLiteWALFrameHeader + db.pageSize
+	for off := config.SQLiteWALHeaderSize; off+frameSize <= len(wal); off += frameSize {
+		frame := wal[off : off+frameSize]
+		if !bytes.Equal(frame[8:16], salt) {
+			break
+		}
+		s0, s1 = walChecksum(order, frame[:8], s0, s1)
+		s0, s1 = walChecksum(order, frame[config.SQLiteWALFrameHeader:], s0, s1)
+		if s0 != binary.BigEndian.Uint32(frame[16:]) || s1 != binary.BigEndian.Uint32(frame[20:]) {
+			break
+		}
+		pending[binary.BigEndian.Uint32(frame)] = frame[config.SQLiteWALFrameHeader:]
+		if size := binary.BigEndian.Uint32(frame[4:]); size != 0 { // Commit frame
+			if db.wal == nil {
+				db.wal = make(map[uint32][]byte)
+			}
+			for n, page := range pending {
+				db.wal[n] = page
+			}
+			clear(pending)
+			db.pages = size
+		}
+	}
+}
+
+// walChecksum continues the checksum of the write-ahead log over data.
+func walChecksum(order binary.ByteOrder, data []byte, s0, s1 uint32) (uint32, uint32) {
+	for i := 0; i+8 <= len(data); i += 8 {
+		s0 += order.Uint32(data[i:]) + s1
+		s1 += order.Uint32(data[i+4:]) + s0
+	}
+	return s0, s1
+}
+
+// page returns the content of page n (1-based).
+func (db *sqliteDB) page(n uint32) ([]byte, error) {
+	if n == 0 || n > db.pages {
+		return nil, errSQLiteCorrupt
+	}
+	if p, ok := db.wal[n]; ok {
+		return p, nil
+	}
+	off := int(n-1) * db.pageSize
+	if off+db.pageSize > len(db.data) {
+		return nil, errSQLiteCorrupt
+	}
+	return db.data[off : off+db.pageSize], nil
+}
+
+// rows calls fn with the columns of every row of table, in rowid order.
+// Values are nil, int64, float64, string or []byte.
+func (db *sqliteDB) rows(table string, fn func(row []any) error) error {
+	var root uint32
+	err := db.scan(1, 0, func(row []any) error {
+		if len(row) >= 4 && row[0] == "table" && row[1] == table {
+			if n, ok := row[3].(int64); ok {
+				root = uint32(n)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if root == 0 {
+		return fmt.Errorf("%s: %s", config.ErrSQLiteNoTable, table)
+	}
+	return db.scan(root, 0, fn)
+}
+
+// scan walks the table b-tree rooted at page n.
+func (db *sqliteDB) scan(n uint32, depth int, fn func(row []any) error) error {
+	if depth > config.SQLiteMaxDepth {
+		return errSQLiteCorrupt
+	}
+	p, err := db.page(n)
+	if err != nil {
+		return err
+	}
+	hdr := 0
+	if n == 1 {
+		hdr = config.SQLiteHeaderSize
+	}
+	if len(p) < hdr+12 {
+		return errSQLiteCorrupt
+	}
+	kind := p[hdr]
+	cells := int(binary.BigEndian.Uint16(p[hdr+3:]))
+	ptrs := hdr + 8
+	if kind == sqliteInteriorTable {
+		ptrs = hdr + 12
+	}
+	if ptrs+2*cells > len(p) {
+		return errSQLiteCorrupt
+	}
+
+	for i := range cells {
+		off := int(binary.BigEndian.Uint16(p[ptrs+2*i:]))
+		if off >= len(p) {
+			return errSQLiteCorrupt
+		}
+		switch kind {
+		case sqliteInteriorTable:
+			if off+4 > len(p) {
+				return errSQLiteCorrupt
+			}
+			if err := db.scan(binary.BigEndian.Uint32(p[off:]), depth+1, fn); err != nil {
+				return err
+			}
+		case sqliteLeafTable:
+			payload, err := db.payload(p, off)
+			if err != nil {
+				return err
+			}
+			row, err := decodeRecord(payload)
+			if err != nil {
+				return err
+			}
+			if err := fn(row); err != nil {
+				return err
+			}
+		default:
+			return errSQLiteCorrupt
+		}
+	}
+	if kind == sqliteInteriorTable {
+		return db.scan(binary.BigEndian.Uint32(p[hdr+8:]), depth+1, fn)
+	}
+	return nil
+}
+
+// payload returns the record of the leaf cell at off, following its overflow pages.
+func (db *sqliteDB) payload(p []byte, off int) ([]byte, error) {
+	size, n := sqliteVarint(p[off:])
+	if n == 0 {
+		return nil, errSQLiteCorrupt
+	}
+	off += n
+	if _, n = sqliteVarint(p[off:]); n == 0 { // Rowid
+		return nil, errSQLiteCorrupt
+	}
+	off += n
+	if size > uint64(config.SQLiteMaxRecord) {
+		return nil, errSQLiteCorrupt
+	}
+	total := int(size)
+
+	// Part of the payload stored in the cell itself (section 1.6 of the file format).
+	local := total
+	if maxLocal := db.usable - 35; total > maxLocal {
+		minLocal := (db.usable-12)*32/255 - 23
+		local = minLocal + (total-minLocal)%(db.usable-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+	if off+local > len(p) || (local < total && off+local+4 > len(p)) {
+		return nil, errSQLiteCorrupt
+	}
+	out := make([]byte, 0, total)
+	out = append(out, p[off:off+local]...)
+	if local == total {
+		return out, nil
+	}
+
+	next := binary.BigEndian.Uint32(p[off+local:])
+	for visited := 0; len(out) < total; visited++ {
+		if visited > int(db.pages) {
+			return nil, errSQLiteCorrupt
+		}
+		page, err := db.page(next)
+		if err != nil {
+			return nil, err
+		}
+		chunk := min(total-len(out), db.usable-4)
+		out = append(out, page[4:4+chunk]...)
+		next = binary.BigEndian.Uint32(page)
+	}
+	return out, nil
+}
+
+// decodeRecord splits a record into its values (section 2.1 of the file format).
+func decodeRecord(rec []byte) ([]any, error) {
+	hdrSize, n := sqliteVarint(rec)
+	if n == 0 || hdrSize > uint64(len(rec)) {
+		return nil, errSQLiteCorrupt
+	}
+	var types []uint64
+	for off := n; off < int(hdrSize); {
+		t, n := sqliteVarint(rec[off:int(hdrSize)])
+		if n == 0 {
+			return nil, errSQLiteCorrupt
+		}
+		types = append(types, t)
+		off += n
+	}
+
+	row := make([]any, 0, len(types))
+	body := rec[hdrSize:]
+	for _, t := range types {
+		var size int
+		switch {
+		case t <= 4:
+			size = int(t)
+		case t == 5:
+			size = 6
+		case t == 6, t == 7:
+			size = 8
+		case t >= 12:
+			size = int((t - 12) / 2)
+		}
+		if size > len(body) {
+			return nil, errSQLiteCorrupt
+		}
+		v := body[:size]
+		body = body[size:]
+
+		switch {
+		case t == 0:
+			row = append(row, nil)
+		case t <= 6:
+			i := int64(int8(v[0])) // Sign extension
+			for _, b := range v[1:] {
+				i = i<<8 | int64(b)
+			}
+			row = append(row, i)
+		case t == 7:
+			row = append(row, math.Float64frombits(binary.BigEndian.Uint64(v)))
+		case t == 8, t == 9:
+			row = append(row, int64(t-8))
+		case t >= 12 && t%2 == 0:
+			row = append(row, bytes.Clone(v))
+		case t >= 13:
+			row = append(row, string(v))
+		default: // Reserved types 10 and 11
+			return nil, errSQLiteCorrupt
+		}
+	}
+	return row, nil
+}
+
+// sqliteVarint decodes a big-endian variable-length integer of 1 to 9 bytes.
+// It returns the number of bytes read, 0 if b is too short.
+func sqliteVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9; i++ {
+		if i >= len(b) {
+			return 0, 0
+		}
+		if i == 8 {
+			return v<<8 | uint64(b[i]), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return v, 9
+}
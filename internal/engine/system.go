@@ -0,0 +1,26 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// ErrSystemDenied is returned when the user refused the access to the system address book.
+var ErrSystemDenied = errors.New(config.ErrSystemDenied)
+
+// systemError explains a failed export of the system address book from the output of the exporter.
+func systemError(stderr string, err error) error {
+	stderr = strings.TrimSpace(stderr)
+	for _, code := range config.SystemDeniedCodes {
+		if strings.Contains(stderr, code) {
+			return fmt.Errorf("%w: %s", ErrSystemDenied, stderr)
+		}
+	}
+	if stderr != "" {
+		return fmt.Errorf("%s: %w: %s", config.ErrSystemExport, err, stderr)
+	}
+	return fmt.Errorf("%s: %w", config.ErrSystemExport, err)
+}
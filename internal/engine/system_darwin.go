@@ -0,0 +1,28 @@
+//go:build darwin
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// openSystem exports every card of Contacts.app through AppleScript.
+// The first export shows the macOS prompt asking the user to let the application control Contacts.
+func openSystem(ctx context.Context) (io.ReadCloser, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, config.OsascriptBin, "-e", config.ScriptExportContacts)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, systemError(stderr.String(), err)
+	}
+	return io.NopCloser(&stdout), nil
+}
@@ -0,0 +1,16 @@
+//go:build !darwin
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// openSystem is only available on macOS.
+func openSystem(context.Context) (io.ReadCloser, error) {
+	return nil, errors.New(config.ErrSystemUnsupported)
+}
@@ -0,0 +1,371 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/emersion/go-vcard"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// openThunderbird converts the Thunderbird address books found at path to vCards.
+// path is a profile directory, an address book (abook.sqlite) or an LDIF export;
+// the default profile is detected when it is empty.
+func openThunderbird(ctx context.Context, path string) (io.ReadCloser, error) {
+	books, err := ThunderbirdBooks(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := vcard.NewEncoder(&buf)
+	for _, book := range books {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var cards []vcard.Card
+		if strings.EqualFold(filepath.Ext(book), config.ExtLDIF) {
+			cards, err = readLDIF(book)
+		} else {
+			cards, err = readAbook(book)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", config.ErrThunderbirdRead, book, err)
+		}
+		for _, card := range cards {
+			if err := enc.Encode(card); err != nil {
+				return nil, err
+			}
+		}
+		slog.Debug(config.MsgThunderbirdRead,
+			config.LogKeyComponent, config.CompEngine,
+			config.LogKeyFile, book,
+			config.LogKeyCount, len(cards))
+	}
+	return io.NopCloser(&buf), nil
+}
+
+// ThunderbirdBooks returns the address books to read for path (see openThunderbird),
+// the personal one first. Collected addresses (history.sqlite) are left out.
+func ThunderbirdBooks(path string) ([]string, error) {
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		if path, err = FindThunderbirdProfile(home); err != nil {
+			return nil, err
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		if strings.EqualFold(filepath.Ext(path), config.ExtMab) {
+			return nil, fmt.Errorf("%s: %s", config.ErrThunderbirdMab, path)
+		}
+		return []string{path}, nil
+	}
+
+	books, err := filepath.Glob(filepath.Join(path, config.ThunderbirdBookGlob))
+	if err != nil {
+		return nil, err
+	}
+	if len(books) == 0 {
+		if mabs, _ := filepath.Glob(filepath.Join(path, "*"+config.ExtMab)); len(mabs) > 0 {
+			return nil, fmt.Errorf("%s: %s", config.ErrThunderbirdMab, mabs[0])
+		}
+		return nil, fmt.Errorf("%s: %s", config.ErrThunderbirdNoBook, path)
+	}
+	// abook.sqlite, then abook-1.sqlite, abook-2.sqlite...
+	sort.Slice(books, func(i, j int) bool {
+		if a, b := filepath.Base(books[i]) == config.ThunderbirdPersonalBook, filepath.Base(books[j]) == config.ThunderbirdPersonalBook; a != b {
+			return a
+		}
+		return books[i] < books[j]
+	})
+	return books, nil
+}
+
+// FindThunderbirdProfile returns the default profile of the first Thunderbird installation
+// found under home (regular, Snap, Flatpak, macOS and Windows layouts) holding an address book.
+// The profile of the installation ([Install] section of profiles.ini) is preferred to
+// the one flagged Default, itself preferred to the first one listed.
+func FindThunderbirdProfile(home string) (string, error) {
+	for _, dir := range config.ThunderbirdDirs {
+		root := filepath.Join(home, filepath.FromSlash(dir))
+		data, err := os.ReadFile(filepath.Join(root, config.ThunderbirdProfilesINI))
+		if err != nil {
+			continue
+		}
+		for _, profile := range iniProfiles(root, data) {
+			if _, err := os.Stat(filepath.Join(profile, config.ThunderbirdPersonalBook)); err == nil {
+				return profile, nil
+			}
+		}
+	}
+	return "", errors.New(config.ErrThunderbirdNoProfile)
+}
+
+// iniProfiles lists the profile directories of a profiles.ini file, by preference.
+func iniProfiles(root string, data []byte) []string {
+	var installs, defaults, others []string
+	var section string
+	var path string
+	relative, isDefault := true, false
+	flush := func() {
+		if !strings.HasPrefix(section, config.INISectionProfile) || path == "" {
+			return
+		}
+		if relative {
+			path = filepath.Join(root, filepath.FromSlash(path))
+		}
+		if isDefault {
+			defaults = append(defaults, path)
+		} else {
+			others = append(others, path)
+		}
+	}
+
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			flush()
+			section = line[1 : len(line)-1]
+			path, relative, isDefault = "", true, false
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(section, config.INISectionInstall) && key == config.INIKeyDefault:
+			// Install sections always hold paths relative to the root, unless absolute.
+			if !filepath.IsAbs(value) {
+				value = filepath.Join(root, filepath.FromSlash(value))
+			}
+			installs = append(installs, value)
+		case key == config.INIKeyPath:
+			path = value
+		case key == config.INIKeyIsRelative:
+			relative = value == "1"
+		case key == config.INIKeyDefault:
+			isDefault = value == "1"
+		}
+	}
+	flush()
+	return append(append(installs, defaults...), others...)
+}
+
+// readAbook converts the cards of a Thunderbird address book (abook.sqlite).
+// Since Thunderbird 102, each card keeps its whole vCard in the _vCard property;
+// older cards only have separate properties (DisplayName, BirthYear...).
+func readAbook(path string) ([]vcard.Card, error) {
+	db, err := openSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var order []string
+	props := make(map[string]map[string]string)
+	err = db.rows(config.ThunderbirdTable, func(row []any) error {
+		if len(row) < 3 {
+			return nil
+		}
+		uid, _ := row[0].(string)
+		name, _ := row[1].(string)
+		value := sqliteText(row[2])
+		if uid == "" || name == "" {
+			return nil
+		}
+		if props[uid] == nil {
+			props[uid] = make(map[string]string)
+			order = append(order, uid)
+		}
+		props[uid][name] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cards := make([]vcard.Card, 0, len(order))
+	for _, uid := range order {
+		if card := abookCard(uid, props[uid]); card != nil {
+			cards = append(cards, card)
+		}
+	}
+	return cards, nil
+}
+
+// sqliteText returns a column value as text.
+func sqliteText(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// abookCard returns the vCard of a card of abook.sqlite, nil if it cannot be read.
+func abookCard(uid string, props map[string]string) vcard.Card {
+	if raw := props[config.ThunderbirdPropVCard]; raw != "" {
+		card, err := vcard.NewDecoder(strings.NewReader(raw)).Decode()
+		if err == nil {
+			if card.Value(config.VCardUID) == "" {
+				card.SetValue(config.VCardUID, uid)
+			}
+			return card
+		}
+		slog.Warn(config.MsgSkippedCard,
+			config.LogKeyComponent, config.CompEngine,
+			config.LogKeyError, err)
+		return nil
+	}
+
+	card := make(vcard.Card)
+	card.SetValue(config.PropVersion, config.VCardVersion4)
+	card.SetValue(config.VCardUID, uid)
+	setNames(card, props[config.ThunderbirdPropDisplayName], props[config.ThunderbirdPropFirstName], props[config.ThunderbirdPropLastName])
+	if bday := partialDate(props[config.ThunderbirdPropBirthYear], props[config.ThunderbirdPropBirthMonth], props[config.ThunderbirdPropBirthDay]); bday != "" {
+		card.SetValue(config.VCardBDAY, bday)
+	}
+	return card
+}
+
+// readLDIF converts the entries of an LDIF export of a Thunderbird address book (RFC 2849).
+// Mailing lists (groupOfNames entries) are skipped.
+func readLDIF(path string) ([]vcard.Card, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var cards []vcard.Card
+	entry := make(map[string]string)
+	emit := func() {
+		if card := ldifCard(entry); card != nil {
+			cards = append(cards, card)
+		}
+		clear(entry)
+	}
+
+	var last string // Attribute of the previous line, for continuation lines
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, config.DefaultMaxCardMB*config.BytesPerMB)
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		switch {
+		case line == "":
+			emit()
+			last = ""
+		case strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, " "):
+			if last != "" {
+				entry[last] += line[1:]
+			}
+		default:
+			attr, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			attr = strings.ToLower(attr)
+			if prev, seen := entry[attr]; seen {
+				// Only the first value of an attribute is kept, but every object class.
+				if attr == config.LDIFObjectClass {
+					entry[attr] = prev + "," + strings.TrimSpace(value)
+				}
+				last = ""
+				continue
+			}
+			entry[attr] = value
+			last = attr
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	emit()
+	return cards, nil
+}
+
+// ldifCard returns the vCard of an LDIF entry, nil if it is not a contact.
+func ldifCard(entry map[string]string) vcard.Card {
+	value := func(attr string) string {
+		v := entry[attr]
+		if b64, ok := strings.CutPrefix(v, ":"); ok { // "attr:: base64"
+			data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(b64))
+			if err != nil {
+				return ""
+			}
+			return string(data)
+		}
+		return strings.TrimSpace(v)
+	}
+	if value(config.LDIFDN) == "" {
+		return nil
+	}
+	for _, class := range strings.Split(strings.ToLower(entry[config.LDIFObjectClass]), ",") {
+		if strings.TrimSpace(class) == config.LDIFClassList {
+			return nil
+		}
+	}
+
+	card := make(vcard.Card)
+	card.SetValue(config.PropVersion, config.VCardVersion4)
+	setNames(card, value(config.LDIFCN), value(config.LDIFGivenName), value(config.LDIFSN))
+	if card.Value(config.VCardFN) == "" {
+		return nil
+	}
+	if bday := partialDate(value(config.LDIFBirthYear), value(config.LDIFBirthMonth), value(config.LDIFBirthDay)); bday != "" {
+		card.SetValue(config.VCardBDAY, bday)
+	}
+	return card
+}
+
+// setNames sets FN (the display name, or the given and family names) and N of card.
+func setNames(card vcard.Card, display, given, family string) {
+	fn := display
+	if fn == "" {
+		fn = strings.TrimSpace(given + " " + family)
+	}
+	card.SetValue(config.VCardFN, fn)
+	if given != "" || family != "" {
+		card.SetName(&vcard.Name{GivenName: given, FamilyName: family})
+	}
+}
+
+// partialDate formats a birthday as a vCard BDAY, "--MMDD" without year. It returns "" for incomplete dates.
+func partialDate(year, month, day string) string {
+	m, errM := strconv.Atoi(strings.TrimSpace(month))
+	d, errD := strconv.Atoi(strings.TrimSpace(day))
+	if errM != nil || errD != nil || m < 1 || m > 12 || d < 1 || d > 31 {
+		return ""
+	}
+	y, err := strconv.Atoi(strings.TrimSpace(year))
+	if err != nil || y <= 0 {
+		return fmt.Sprintf("--%02d%02d", m, d)
+	}
+	return fmt.Sprintf("%04d%02d%02d", y, m, d)
+}
@@ -0,0 +1,104 @@
+package engine_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+)
+
+// The profile of testdata/home has cards in every format of abook.sqlite: a whole vCard
+// with a note spilling over overflow pages, separate properties, and a card only present
+// in the write-ahead log. Filler cards spread the table over several b-tree pages.
+var thunderbirdProfile = filepath.Join("testdata", "home", ".thunderbird", "Profiles", "abcd1234.default-release")
+
+// TestFindThunderbirdProfile verifies that the profile of the installation wins over the Default flag.
+func TestFindThunderbirdProfile(t *testing.T) {
+	profile, err := engine.FindThunderbirdProfile(filepath.Join("testdata", "home"))
+	require.NoError(t, err)
+	assert.Equal(t, thunderbirdProfile, profile)
+
+	_, err = engine.FindThunderbirdProfile(t.TempDir())
+	assert.ErrorContains(t, err, config.ErrThunderbirdNoProfile)
+}
+
+// TestRunSync_Thunderbird verifies the conversion of abook.sqlite, write-ahead log included.
+func TestRunSync_Thunderbird(t *testing.T) {
+	books, err := engine.ThunderbirdBooks(thunderbirdProfile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(thunderbirdProfile, config.ThunderbirdPersonalBook)}, books, "Collected addresses are left out")
+
+	gen := &engine.Generator{Clock: MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}}
+	_, contacts, _, err := gen.RunSync(context.Background(), engine.SyncConfig{
+		Mode:            config.SourceModeThunderbird,
+		ThunderbirdPath: thunderbirdProfile,
+	})
+	require.NoError(t, err)
+
+	dates := make(map[string]string)
+	for _, c := range contacts {
+		dates[c.Name] = c.DateOfBirth.Format("--01-02")
+		if c.YearKnown {
+			dates[c.Name] = c.DateOfBirth.Format(time.DateOnly)
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"Alice Martin": "1990-03-15",
+		"Bob Durand":   "--07-04",
+		"Carol Petit":  "1985-12-01",
+	}, dates)
+}
+
+// TestRunSync_ThunderbirdLDIF verifies LDIF exports: base64 values, continuation lines and lists.
+func TestRunSync_ThunderbirdLDIF(t *testing.T) {
+	ldif := "dn: cn=Alice Martin,mail=alice@example.com\n" +
+		"objectclass: top\n" +
+		"objectclass: person\n" +
+		"givenName: Alice\n" +
+		"sn: Martin\n" +
+		"cn: Alice Martin\n" +
+		"birthyear: 1990\n" +
+		"birthmonth: 3\n" +
+		"birthday: 15\n" +
+		"\n" +
+		"dn:: Y249w4lsb2RpZQ==\n" +
+		"objectclass: person\n" +
+		"cn:: w4lsb2\n" +
+		" RpZQ==\n" +
+		"birthmonth: 7\n" +
+		"birthday: 4\n" +
+		"\n" +
+		"dn: cn=Family\n" +
+		"objectclass: top\n" +
+		"objectclass: groupOfNames\n" +
+		"cn: Family\n" +
+		"birthmonth: 1\n" +
+		"birthday: 1\n"
+	path := filepath.Join(t.TempDir(), "export.ldif")
+	require.NoError(t, os.WriteFile(path, []byte(ldif), 0o600))
+
+	gen := &engine.Generator{Clock: MockClock{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}}
+	_, contacts, _, err := gen.RunSync(context.Background(), engine.SyncConfig{
+		Mode:            config.SourceModeThunderbird,
+		ThunderbirdPath: path,
+	})
+	require.NoError(t, err)
+
+	var names []string
+	for _, c := range contacts {
+		names = append(names, c.Name)
+	}
+	assert.ElementsMatch(t, []string{"Alice Martin", "Élodie"}, names)
+}
+
+// TestThunderbirdBooks_Mab verifies that the former Mork format is reported rather than ignored.
+func TestThunderbirdBooks_Mab(t *testing.T) {
+	_, err := engine.ThunderbirdBooks(filepath.Join("testdata", "home", ".thunderbird", "Profiles", "old.default"))
+	assert.ErrorContains(t, err, config.ErrThunderbirdMab)
+}
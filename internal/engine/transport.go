@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// TransportConfig holds the connection settings of a web source.
+// The zero value connects directly, or through the proxy set by the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+type TransportConfig struct {
+	ProxyURL  string // Explicit proxy (e.g., "http://proxy.example.com:3128"), overrides the environment
+	ProxyUser string // Proxy Basic Auth Username
+	ProxyPass string // Proxy Basic Auth Password
+
+	CAFile             string // PEM certificates trusted in addition to the system ones (e.g., an internal CA)
+	InsecureSkipVerify bool   // Accept any server certificate. Insecure, for testing only.
+
+	ClientCert string // PEM certificate presented to servers requiring mutual TLS
+	ClientKey  string // PEM private key of ClientCert, unencrypted
+}
+
+// files returns the files read to build the transport.
+func (tc TransportConfig) files() []string {
+	return []string{tc.CAFile, tc.ClientCert, tc.ClientKey}
+}
+
+type transportKey struct{}
+
+// withTransport passes the transport settings of a synchronization down to the fetcher.
+func withTransport(ctx context.Context, tc TransportConfig) context.Context {
+	return context.WithValue(ctx, transportKey{}, tc)
+}
+
+func transportFrom(ctx context.Context) TransportConfig {
+	tc, _ := ctx.Value(transportKey{}).(TransportConfig)
+	return tc
+}
+
+// newTransport returns a copy of the default transport configured by tc.
+func newTransport(tc TransportConfig) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+
+	if tc.ProxyURL != "" {
+		proxy, err := url.Parse(tc.ProxyURL)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("%s: %q", config.ErrProxyURL, tc.ProxyURL)
+		}
+		if tc.ProxyUser != "" || tc.ProxyPass != "" {
+			proxy.User = url.UserPassword(tc.ProxyUser, tc.ProxyPass)
+		}
+		t.Proxy = http.ProxyURL(proxy)
+	}
+
+	if tc.CAFile != "" || tc.InsecureSkipVerify || tc.ClientCert != "" || tc.ClientKey != "" {
+		t.TLSClientConfig = &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: tc.InsecureSkipVerify, // Explicit user choice, warned in the settings and the logs
+		}
+	}
+	if tc.CAFile != "" {
+		pool, err := loadCertPool(tc.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		t.TLSClientConfig.RootCAs = pool
+	}
+	if tc.ClientCert != "" || tc.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(tc.ClientCert, tc.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", config.ErrClientCert, err)
+		}
+		t.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+	if tc.InsecureSkipVerify {
+		slog.Warn(config.MsgTLSInsecure, config.LogKeyComponent, config.CompFetcher)
+	}
+	return t, nil
+}
+
+// loadCertPool returns the system certificates plus those of the PEM file at path.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrCAFile, err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool() // Not available on every platform
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s: %s", config.ErrCAFile, path)
+	}
+	return pool, nil
+}
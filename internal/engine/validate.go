@@ -0,0 +1,231 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/emersion/go-ical"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// ICSIssue is a deviation from RFC 5545 found by ValidateICS.
+type ICSIssue struct {
+	Line   int    // Physical line (1-based) where the content line starts
+	Reason string // One of the config.ErrICS* messages, with details
+}
+
+func (i ICSIssue) String() string {
+	return fmt.Sprintf("line %d: %s", i.Line, i.Reason)
+}
+
+// Properties each component must have exactly once, and those it may have at most once.
+var (
+	icsRequired = map[string][]string{
+		ical.CompCalendar: {config.PropProdid, config.PropVersion},
+		ical.CompEvent:    {config.PropUID, config.PropDTStamp, config.PropDTStart},
+		ical.CompAlarm:    {config.PropAction, config.PropTrigger},
+	}
+	icsUnique = map[string][]string{
+		ical.CompCalendar: {config.PropCalScale, config.PropMethod},
+		ical.CompEvent: {config.PropDTEnd, config.PropSummary, config.PropDescription, config.PropClass, config.PropStatus,
+			config.PropTransp, config.PropSequence, config.PropLastMod, config.PropColor, config.PropURL},
+		ical.CompAlarm: {config.PropDescription},
+	}
+	// Properties of type TEXT whose commas and semicolons must be escaped (CATEGORIES is a list).
+	icsText = []string{config.PropSummary, config.PropDescription}
+)
+
+// icsComponent is a component being checked: where it starts and how often each property appears.
+type icsComponent struct {
+	name  string
+	line  int
+	props map[string]int
+	alarm string // ACTION of a VALARM
+}
+
+// ValidateICS checks calendar data the way strict clients (e.g., Outlook) read it:
+// CRLF line endings, lines folded at 75 octets without splitting UTF-8 characters,
+// balanced components with their mandatory properties, and escaped text values.
+func ValidateICS(r io.Reader) ([]ICSIssue, error) {
+	var issues []ICSIssue
+	report := func(line int, reason string, args ...any) {
+		if len(args) > 0 {
+			reason += ": " + fmt.Sprint(args...)
+		}
+		issues = append(issues, ICSIssue{Line: line, Reason: reason})
+	}
+
+	var stack []*icsComponent
+	check := func(start int, content string) {
+		name, value, ok := splitContentLine(content)
+		if !ok {
+			report(start, config.ErrICSSyntax)
+			return
+		}
+		switch name {
+		case config.ICalBegin:
+			stack = append(stack, &icsComponent{name: strings.ToUpper(value), line: start, props: make(map[string]int)})
+			return
+		case config.ICalEnd:
+			if len(stack) == 0 || stack[len(stack)-1].name != strings.ToUpper(value) {
+				report(start, config.ErrICSNesting, value)
+				return
+			}
+			c := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, p := range icsRequired[c.name] {
+				if c.props[p] == 0 {
+					report(c.line, config.ErrICSMissingProp, c.name+" "+p)
+				}
+			}
+			if c.name == ical.CompAlarm && c.alarm == config.ICalAction && c.props[config.PropDescription] == 0 {
+				report(c.line, config.ErrICSMissingProp, c.name+" "+config.PropDescription)
+			}
+			return
+		}
+
+		if len(stack) == 0 {
+			report(start, config.ErrICSNesting, name)
+			return
+		}
+		c := stack[len(stack)-1]
+		c.props[name]++
+		if c.props[name] == 2 && (slices.Contains(icsRequired[c.name], name) || slices.Contains(icsUnique[c.name], name)) {
+			report(start, config.ErrICSDuplicateProp, c.name+" "+name)
+		}
+		if c.name == ical.CompAlarm && name == config.PropAction {
+			c.alarm = strings.ToUpper(value)
+		}
+		if slices.Contains(icsText, name) {
+			if reason := checkText(value); reason != "" {
+				report(start, reason, name)
+			}
+		}
+	}
+
+	// Content lines are unfolded before being checked, physical lines are checked as read.
+	br := bufio.NewReader(r)
+	var content strings.Builder
+	start, n := 0, 0
+	for {
+		line, err := br.ReadString('\n')
+		if line == "" && err != nil {
+			if err != io.EOF {
+				return nil, err
+			}
+			break
+		}
+		n++
+		body, crlf := strings.CutSuffix(line, config.ICalLineBreak)
+		if !crlf {
+			body = strings.TrimSuffix(body, "\n")
+			report(n, config.ErrICSLineBreak)
+		}
+		if len(body) > config.ICalMaxLineOctets {
+			report(n, config.ErrICSLineLength, len(body))
+		}
+		if !utf8.ValidString(body) {
+			report(n, config.ErrICSUTF8)
+		}
+
+		if cont, ok := strings.CutPrefix(body, config.ICalFoldPrefix); ok && start > 0 {
+			content.WriteString(cont)
+		} else if cont, ok := strings.CutPrefix(body, "\t"); ok && start > 0 {
+			content.WriteString(cont)
+		} else {
+			if start > 0 {
+				check(start, content.String())
+			}
+			content.Reset()
+			content.WriteString(body)
+			start = n
+		}
+		if err != nil {
+			break
+		}
+	}
+	if start > 0 && content.Len() > 0 {
+		check(start, content.String())
+	}
+	for _, c := range stack {
+		report(c.line, config.ErrICSNesting, c.name)
+	}
+	return issues, nil
+}
+
+// splitContentLine returns the name and the value of a content line ("NAME;PARAM=x:value"),
+// skipping the colons and semicolons of quoted parameter values.
+func splitContentLine(line string) (name, value string, ok bool) {
+	quoted := false
+	nameEnd := -1
+	for i, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == ';' && nameEnd < 0:
+			nameEnd = i
+		case r == ':':
+			if nameEnd < 0 {
+				nameEnd = i
+			}
+			if nameEnd == 0 {
+				return "", "", false
+			}
+			return strings.ToUpper(line[:nameEnd]), line[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// checkText returns why a TEXT value is not escaped as RFC 5545 (section 3.3.11) requires, or "".
+func checkText(value string) string {
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+			if i == len(value) || !strings.ContainsRune(`\;,nN`, rune(value[i])) {
+				return config.ErrICSBadEscape
+			}
+		case ',', ';':
+			return config.ErrICSUnescaped
+		}
+	}
+	return ""
+}
+
+// FoldLines folds the content lines of data longer than 75 octets (RFC 5545, section 3.1),
+// never inside a UTF-8 character.
+func FoldLines(data []byte) []byte {
+	crlf := []byte(config.ICalLineBreak)
+	var out bytes.Buffer
+	out.Grow(len(data))
+	for _, line := range bytes.SplitAfter(data, crlf) {
+		body, ok := bytes.CutSuffix(line, crlf)
+		if len(body) <= config.ICalMaxLineOctets {
+			out.Write(line)
+			continue
+		}
+		limit := config.ICalMaxLineOctets
+		for len(body) > limit {
+			cut := limit
+			for cut > 0 && !utf8.RuneStart(body[cut]) {
+				cut--
+			}
+			out.Write(body[:cut])
+			out.WriteString(config.ICalLineBreak + config.ICalFoldPrefix)
+			body = body[cut:]
+			limit = config.ICalMaxLineOctets - len(config.ICalFoldPrefix)
+		}
+		out.Write(body)
+		if ok {
+			out.Write(crlf)
+		}
+	}
+	return out.Bytes()
+}
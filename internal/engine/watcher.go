@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// Watcher reports changes to a local vCard source (a file or a directory tree).
+// Bursts of filesystem events (e.g., an export rewriting many files) are debounced
+// into a single notification on C.
+type Watcher struct {
+	fsw      *fsnotify.Watcher
+	target   string // Watched file, empty when watching a directory
+	debounce time.Duration
+	changes  chan struct{}
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewWatcher starts watching path.
+// A single file is watched through its parent directory so that editors and export
+// tools replacing the file atomically (write + rename) are still detected.
+func NewWatcher(path string, debounce time.Duration) (*Watcher, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		fsw:      fsw,
+		debounce: debounce,
+		changes:  make(chan struct{}, config.ChannelBufferSize),
+		done:     make(chan struct{}),
+	}
+
+	if info.IsDir() {
+		err = w.addTree(path)
+	} else {
+		w.target = filepath.Clean(path)
+		err = fsw.Add(filepath.Dir(w.target))
+	}
+	if err != nil {
+		_ = fsw.Close()
+		return nil, err
+	}
+
+	w.wg.Add(1)
+	go w.loop()
+	return w, nil
+}
+
+// C returns the channel receiving one value per (debounced) change.
+func (w *Watcher) C() <-chan struct{} {
+	return w.changes
+}
+
+// Close stops the watcher and releases the underlying OS resources.
+func (w *Watcher) Close() error {
+	close(w.done)
+	err := w.fsw.Close()
+	w.wg.Wait()
+	return err
+}
+
+// addTree watches dir and all its sub-directories (fsnotify is not recursive).
+func (w *Watcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Best effort: skip unreadable entries
+		}
+		if d.IsDir() {
+			return w.fsw.Add(path)
+		}
+		return nil
+	})
+}
+
+// loop collects filesystem events and emits a notification once they settle.
+func (w *Watcher) loop() {
+	defer w.wg.Done()
+
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+
+		case ev, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if !w.relevant(ev) {
+				continue
+			}
+			// New sub-directories of a watched tree must be watched too.
+			if w.target == "" && ev.Has(fsnotify.Create) {
+				if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+					_ = w.addTree(ev.Name)
+				}
+			}
+			timer.Reset(w.debounce)
+
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn(config.ErrWatcher,
+				config.LogKeyComponent, config.CompWatcher,
+				config.LogKeyError, err)
+
+		case <-timer.C:
+			select {
+			case w.changes <- struct{}{}:
+			default: // A notification is already pending
+			}
+		}
+	}
+}
+
+// relevant filters out events unrelated to the source (other files in the parent
+// directory, non-vCard files, attribute-only changes).
+func (w *Watcher) relevant(ev fsnotify.Event) bool {
+	if ev.Op == fsnotify.Chmod {
+		return false
+	}
+	if w.target != "" {
+		// SQLite databases (Thunderbird address books) are first written to their log.
+		name := filepath.Clean(ev.Name)
+		return name == w.target || name == w.target+config.SQLiteWALSuffix
+	}
+	if isVCardFile(ev.Name) {
+		return true
+	}
+	// Directory creation or removal may add or remove many contacts at once.
+	info, err := os.Stat(ev.Name)
+	if err != nil {
+		return filepath.Ext(ev.Name) == "" // Gone: only directories are assumed to matter
+	}
+	return info.IsDir()
+}
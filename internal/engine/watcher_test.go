@@ -0,0 +1,86 @@
+package engine_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tartampluch/go-birthday/internal/engine"
+)
+
+const testDebounce = 50 * time.Millisecond
+
+// expectChange waits for one notification, then checks that the burst produced no other.
+func expectChange(t *testing.T, w *engine.Watcher) {
+	t.Helper()
+	select {
+	case <-w.C():
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a change notification")
+	}
+	select {
+	case <-w.C():
+		t.Fatal("burst of events should be debounced into one notification")
+	case <-time.After(4 * testDebounce):
+	}
+}
+
+func TestWatcher_File(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "contacts.vcf")
+	require.NoError(t, os.WriteFile(path, []byte("v1"), 0o600))
+
+	w, err := engine.NewWatcher(path, testDebounce)
+	require.NoError(t, err)
+	defer func() { _ = w.Close() }()
+
+	// Unrelated files in the same directory are ignored.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.vcf"), []byte("x"), 0o600))
+	select {
+	case <-w.C():
+		t.Fatal("unrelated file should not trigger a change")
+	case <-time.After(4 * testDebounce):
+	}
+
+	// Several writes in a row, then an atomic replace.
+	for i := 0; i < 3; i++ {
+		require.NoError(t, os.WriteFile(path, []byte("v2"), 0o600))
+	}
+	tmp := filepath.Join(dir, "contacts.tmp")
+	require.NoError(t, os.WriteFile(tmp, []byte("v3"), 0o600))
+	require.NoError(t, os.Rename(tmp, path))
+
+	expectChange(t, w)
+}
+
+func TestWatcher_DirectoryTree(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := engine.NewWatcher(dir, testDebounce)
+	require.NoError(t, err)
+	defer func() { _ = w.Close() }()
+
+	// A new sub-directory is picked up, then files created inside it are seen.
+	sub := filepath.Join(dir, "collection")
+	require.NoError(t, os.Mkdir(sub, 0o755))
+	expectChange(t, w)
+
+	require.NoError(t, os.WriteFile(filepath.Join(sub, "alice.vcf"), []byte("x"), 0o600))
+	expectChange(t, w)
+
+	// Non-vCard files do not trigger a resync.
+	require.NoError(t, os.WriteFile(filepath.Join(sub, "notes.txt"), []byte("x"), 0o600))
+	select {
+	case <-w.C():
+		t.Fatal("non-vCard file should not trigger a change")
+	case <-time.After(4 * testDebounce):
+	}
+}
+
+func TestWatcher_MissingPath(t *testing.T) {
+	_, err := engine.NewWatcher(filepath.Join(t.TempDir(), "missing.vcf"), testDebounce)
+	assert.Error(t, err)
+}
@@ -0,0 +1,28 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// ZodiacSign returns the western zodiac sign of a birth date, as an index in config.ZodiacEmojis.
+// Only the month and day are used, so dates without a year work too.
+func ZodiacSign(date time.Time) int {
+	day := int(date.Month())*100 + date.Day()
+	sign, start := -1, 0
+	for i, s := range config.ZodiacStarts {
+		if s <= day && s > start {
+			sign, start = i, s
+		}
+	}
+	if sign < 0 {
+		// Early January still belongs to the sign starting in late December (Capricorn).
+		for i, s := range config.ZodiacStarts {
+			if s > start {
+				sign, start = i, s
+			}
+		}
+	}
+	return sign
+}
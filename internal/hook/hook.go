@@ -0,0 +1,59 @@
+// Package hook runs the user's shell commands after each synchronization,
+// e.g., to commit the calendar to git or to ping a monitoring endpoint.
+package hook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// Run executes command with the system shell, its environment extended with env,
+// and waits for it up to config.HookTimeout. The output is only logged when the command fails.
+func Run(ctx context.Context, command string, env map[string]string) error {
+	ctx, cancel := context.WithTimeout(ctx, config.HookTimeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Env = os.Environ()
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		cmd.Env = append(cmd.Env, k+"="+env[k])
+	}
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.WaitDelay = config.HookWaitDelay
+
+	// A background child keeping the output open is not waited for: the hook itself succeeded.
+	if err := cmd.Run(); err != nil && !errors.Is(err, exec.ErrWaitDelay) {
+		out := strings.TrimSpace(output.String())
+		if len(out) > config.HookMaxOutput {
+			// The end of the output, without splitting a character.
+			i := len(out) - config.HookMaxOutput
+			for i < len(out) && !utf8.RuneStart(out[i]) {
+				i++
+			}
+			out = out[i:]
+		}
+		return fmt.Errorf("%s: %w: %s", config.ErrHook, err, out)
+	}
+
+	slog.Info(config.MsgHookDone,
+		config.LogKeyComponent, config.CompHook,
+		config.LogKeyCommand, command)
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package hook
+
+import (
+	"context"
+	"os/exec"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// shellCommand runs command with /bin/sh.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, config.HookShellUnix, config.HookShellFlag, command)
+}
@@ -0,0 +1,48 @@
+package hook_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/hook"
+)
+
+// TestRun verifies that the command sees the hook environment and that failures carry the output.
+func TestRun(t *testing.T) {
+	if runtime.GOOS == config.GOOSWindows {
+		t.Skip("POSIX shell syntax")
+	}
+	out := filepath.Join(t.TempDir(), "env.txt")
+
+	err := hook.Run(context.Background(), `printf '%s %s' "$GO_BIRTHDAY_EVENT" "$GO_BIRTHDAY_CONTACTS" > "$OUT"`, map[string]string{
+		config.HookEnvEvent:    config.HookEventSuccess,
+		config.HookEnvContacts: "12",
+		"OUT":                  out,
+	})
+	require.NoError(t, err)
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "success 12", string(data))
+
+	err = hook.Run(context.Background(), "echo broken >&2; exit 3", nil)
+	assert.ErrorContains(t, err, config.ErrHook)
+	assert.ErrorContains(t, err, "broken")
+
+	// The end of a long output is kept, without splitting a character.
+	err = hook.Run(context.Background(), `i=0; while [ $i -lt 600 ]; do printf 'é'; i=$((i+1)); done; printf x; exit 1`, nil)
+	require.Error(t, err)
+	assert.True(t, utf8.ValidString(err.Error()))
+	assert.Contains(t, err.Error(), strings.Repeat("é", config.HookMaxOutput/2-1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Error(t, hook.Run(ctx, "true", nil), "A cancelled context stops the hook")
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package hook
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// shellCommand runs command with cmd.exe, without a console window flashing over the desktop.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, config.HookShellWindows)
+	// cmd.exe parses its command line itself; quoting it as an argument would break the user's quotes.
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: config.WinCreateNoWindow,
+		CmdLine:       config.HookShellWindows + " " + config.HookShellWinFlag + " " + command,
+	}
+	return cmd
+}
@@ -0,0 +1,170 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// Notifier defines the contract for delivering a push message to a remote service.
+// This interface decouples the UI from the concrete push provider (ntfy, Gotify...).
+type Notifier interface {
+	Send(ctx context.Context, title, message string) error
+}
+
+// Config contains the parameters required to build a push Notifier.
+type Config struct {
+	Backend string // config.PushBackendNtfy, config.PushBackendGotify or config.PushBackendNone
+	Server  string // Base URL of the push server (e.g., "https://ntfy.sh")
+	Topic   string // ntfy topic name (ignored by Gotify)
+	Token   string // Access token (optional for ntfy, required for Gotify)
+}
+
+// New builds the Notifier matching the configured backend.
+// It returns (nil, nil) when push notifications are disabled.
+func New(cfg Config, client *http.Client) (Notifier, error) {
+	if cfg.Backend == "" || cfg.Backend == config.PushBackendNone {
+		return nil, nil
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: config.HTTPTimeout}
+	}
+
+	base, err := parseServerURL(cfg.Server)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Backend {
+	case config.PushBackendNtfy:
+		if cfg.Topic == "" {
+			return nil, errors.New(config.ErrPushTopicEmpty)
+		}
+		return &NtfyNotifier{Client: client, Server: base, Topic: cfg.Topic, Token: cfg.Token}, nil
+	case config.PushBackendGotify:
+		if cfg.Token == "" {
+			return nil, errors.New(config.ErrPushTokenEmpty)
+		}
+		return &GotifyNotifier{Client: client, Server: base, Token: cfg.Token}, nil
+	default:
+		return nil, fmt.Errorf("%s: %q", config.ErrPushBackend, cfg.Backend)
+	}
+}
+
+// parseServerURL validates the push server address and strips any trailing slash.
+func parseServerURL(raw string) (string, error) {
+	if raw == "" {
+		return "", errors.New(config.ErrPushServerEmpty)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", config.ErrInvalidURL, err)
+	}
+	if u.Scheme != config.SchemeHTTP && u.Scheme != config.SchemeHTTPS {
+		return "", fmt.Errorf("%s: %s", config.ErrProtocol, u.Scheme)
+	}
+	return strings.TrimSuffix(raw, config.RouteRoot), nil
+}
+
+// -----------------------------------------------------------------------------
+// ntfy
+// -----------------------------------------------------------------------------
+
+// NtfyNotifier publishes messages to an ntfy topic (https://ntfy.sh).
+type NtfyNotifier struct {
+	Client *http.Client
+	Server string
+	Topic  string
+	Token  string
+}
+
+// Send publishes the message as the request body; the title travels in a header.
+func (n *NtfyNotifier) Send(ctx context.Context, title, message string) error {
+	target := n.Server + config.RouteRoot + url.PathEscape(n.Topic)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, strings.NewReader(message))
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.ErrPushFailed, err)
+	}
+	req.Header.Set(config.HeaderUserAgent, config.UserAgent)
+	req.Header.Set(config.HeaderContentType, config.MimeTextPlain)
+	req.Header.Set(config.HeaderNtfyTitle, title)
+	req.Header.Set(config.HeaderNtfyTags, config.NtfyTagBirthday)
+	if n.Token != "" {
+		req.Header.Set(config.HeaderAuthorization, config.AuthBearerPrefix+n.Token)
+	}
+
+	return do(n.Client, req, config.PushBackendNtfy)
+}
+
+// -----------------------------------------------------------------------------
+// Gotify
+// -----------------------------------------------------------------------------
+
+// GotifyNotifier publishes messages to a Gotify server (https://gotify.net).
+type GotifyNotifier struct {
+	Client *http.Client
+	Server string
+	Token  string
+}
+
+// gotifyMessage mirrors the JSON payload expected by the Gotify /message endpoint.
+type gotifyMessage struct {
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Priority int    `json:"priority"`
+}
+
+// Send posts the message as JSON, authenticated with the application token.
+func (g *GotifyNotifier) Send(ctx context.Context, title, message string) error {
+	payload, err := json.Marshal(gotifyMessage{
+		Title:    title,
+		Message:  message,
+		Priority: config.GotifyPriority,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.ErrPushFailed, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.Server+config.RouteGotifyMessage, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.ErrPushFailed, err)
+	}
+	req.Header.Set(config.HeaderUserAgent, config.UserAgent)
+	req.Header.Set(config.HeaderContentType, config.MimeJSON)
+	req.Header.Set(config.HeaderGotifyKey, g.Token)
+
+	return do(g.Client, req, config.PushBackendGotify)
+}
+
+// do executes the request and maps non-2xx responses to errors.
+func do(client *http.Client, req *http.Request, backend string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.ErrPushFailed, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%s: %d %s", config.ErrPushStatus, resp.StatusCode, resp.Status)
+	}
+
+	slog.Debug(config.MsgPushSent,
+		config.LogKeyComponent, config.CompNotify,
+		config.LogKeyBackend, backend,
+	)
+	return nil
+}
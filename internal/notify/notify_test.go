@@ -0,0 +1,113 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/notify"
+)
+
+// TestNew_Validation verifies that misconfigured backends are rejected early.
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     notify.Config
+		wantNil bool
+		wantErr string
+	}{
+		{"Disabled", notify.Config{Backend: config.PushBackendNone}, true, ""},
+		{"Empty Backend", notify.Config{}, true, ""},
+		{"Missing Server", notify.Config{Backend: config.PushBackendNtfy, Topic: "t"}, true, config.ErrPushServerEmpty},
+		{"Bad Scheme", notify.Config{Backend: config.PushBackendNtfy, Server: "ftp://x", Topic: "t"}, true, config.ErrProtocol},
+		{"Ntfy Without Topic", notify.Config{Backend: config.PushBackendNtfy, Server: "https://ntfy.sh"}, true, config.ErrPushTopicEmpty},
+		{"Gotify Without Token", notify.Config{Backend: config.PushBackendGotify, Server: "https://push.local"}, true, config.ErrPushTokenEmpty},
+		{"Unknown Backend", notify.Config{Backend: "pigeon", Server: "https://x"}, true, config.ErrPushBackend},
+		{"Valid Ntfy", notify.Config{Backend: config.PushBackendNtfy, Server: "https://ntfy.sh/", Topic: "t"}, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := notify.New(tt.cfg, nil)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantNil, n == nil)
+		})
+	}
+}
+
+// TestNtfy_Send checks the request layout expected by ntfy (topic path, Title header, bearer token).
+func TestNtfy_Send(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/birthdays", r.URL.Path)
+		assert.Equal(t, "Title", r.Header.Get(config.HeaderNtfyTitle))
+		assert.Equal(t, "Bearer tk_secret", r.Header.Get(config.HeaderAuthorization))
+
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "Alice, Bob", string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	n, err := notify.New(notify.Config{
+		Backend: config.PushBackendNtfy,
+		Server:  ts.URL + "/",
+		Topic:   "birthdays",
+		Token:   "tk_secret",
+	}, ts.Client())
+	require.NoError(t, err)
+
+	assert.NoError(t, n.Send(context.Background(), "Title", "Alice, Bob"))
+}
+
+// TestGotify_Send checks the JSON payload and application token header expected by Gotify.
+func TestGotify_Send(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, config.RouteGotifyMessage, r.URL.Path)
+		assert.Equal(t, "app-token", r.Header.Get(config.HeaderGotifyKey))
+		assert.Equal(t, config.MimeJSON, r.Header.Get(config.HeaderContentType))
+
+		var payload map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "Title", payload["title"])
+		assert.Equal(t, "Body", payload["message"])
+		assert.EqualValues(t, config.GotifyPriority, payload["priority"])
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	n, err := notify.New(notify.Config{
+		Backend: config.PushBackendGotify,
+		Server:  ts.URL,
+		Token:   "app-token",
+	}, ts.Client())
+	require.NoError(t, err)
+
+	assert.NoError(t, n.Send(context.Background(), "Title", "Body"))
+}
+
+// TestSend_ErrorStatus ensures a rejected push is reported to the caller.
+func TestSend_ErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	n, err := notify.New(notify.Config{Backend: config.PushBackendNtfy, Server: ts.URL, Topic: "t"}, ts.Client())
+	require.NoError(t, err)
+
+	err = n.Send(context.Background(), "Title", "Body")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+}
@@ -0,0 +1,327 @@
+package prefs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// File is a fyne.Preferences saved as a JSON object, in the format of the desktop application,
+// for the builds without the Fyne drivers. Every change is written at once.
+type File struct {
+	mu        sync.RWMutex
+	path      string         // Empty to keep the values in memory
+	values    map[string]any // As decoded from JSON: bool, float64, string or []any
+	listeners []func()
+}
+
+// FilePath returns the preferences file of the desktop application appID, which a headless build
+// on the same machine shares.
+func FilePath(appID string) (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, config.FynePrefsDir, appID, config.FynePrefsFile), nil
+}
+
+// NewFile reads the preferences saved at path, if any. An empty path keeps them in memory.
+func NewFile(path string) (*File, error) {
+	f := &File{path: path, values: make(map[string]any)}
+	if path == "" {
+		return f, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &f.values); err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrPrefsFile, err)
+	}
+	return f, nil
+}
+
+// ReadValues lists the saved values.
+func (f *File) ReadValues(fn func(map[string]any)) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	fn(f.values)
+}
+
+// value returns the saved value of key, if any.
+func (f *File) value(key string) (any, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	v, ok := f.values[key]
+	return v, ok
+}
+
+// set saves value under key, or removes key if value is nil, then notifies the listeners.
+func (f *File) set(key string, value any) {
+	f.mu.Lock()
+	if value == nil {
+		delete(f.values, key)
+	} else {
+		f.values[key] = value
+	}
+	err := f.save()
+	listeners := append([]func(){}, f.listeners...)
+	f.mu.Unlock()
+
+	if err != nil {
+		slog.Error(config.ErrPrefsSave,
+			config.LogKeyComponent, config.CompPrefs,
+			config.LogKeyPath, f.path,
+			config.LogKeyError, err)
+	}
+	for _, fn := range listeners {
+		fn()
+	}
+}
+
+// save writes the values to the file, replacing it at once. The caller holds the lock.
+func (f *File) save() error {
+	if f.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(f.values)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, config.DirPermUserRWX); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(f.path)+".*") // Created with config.FilePermUserRW
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// number converts a saved value to a number, JSON having no integers.
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// list converts a saved list with conv, which rejects the items of another type.
+func list[T any](v any, conv func(any) (T, bool)) ([]T, bool) {
+	items, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+	out := make([]T, 0, len(items))
+	for _, item := range items {
+		t, ok := conv(item)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, t)
+	}
+	return out, true
+}
+
+// anyList stores a list as JSON decodes it, so that the values read back the same after a restart.
+func anyList[T any](values []T) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
+
+func asBool(v any) (bool, bool) {
+	b, ok := v.(bool)
+	return b, ok
+}
+
+func asString(v any) (string, bool) {
+	s, ok := v.(string)
+	return s, ok
+}
+
+func asInt(v any) (int, bool) {
+	f, ok := number(v)
+	return int(f), ok
+}
+
+func (f *File) Bool(key string) bool {
+	return f.BoolWithFallback(key, false)
+}
+
+func (f *File) BoolWithFallback(key string, fallback bool) bool {
+	if v, ok := f.value(key); ok {
+		if b, ok := asBool(v); ok {
+			return b
+		}
+	}
+	return fallback
+}
+
+func (f *File) SetBool(key string, value bool) {
+	f.set(key, value)
+}
+
+func (f *File) BoolList(key string) []bool {
+	return f.BoolListWithFallback(key, nil)
+}
+
+func (f *File) BoolListWithFallback(key string, fallback []bool) []bool {
+	if v, ok := f.value(key); ok {
+		if l, ok := list(v, asBool); ok {
+			return l
+		}
+	}
+	return fallback
+}
+
+func (f *File) SetBoolList(key string, value []bool) {
+	f.set(key, anyList(value))
+}
+
+func (f *File) Float(key string) float64 {
+	return f.FloatWithFallback(key, 0)
+}
+
+func (f *File) FloatWithFallback(key string, fallback float64) float64 {
+	if v, ok := f.value(key); ok {
+		if n, ok := number(v); ok {
+			return n
+		}
+	}
+	return fallback
+}
+
+func (f *File) SetFloat(key string, value float64) {
+	f.set(key, value)
+}
+
+func (f *File) FloatList(key string) []float64 {
+	return f.FloatListWithFallback(key, nil)
+}
+
+func (f *File) FloatListWithFallback(key string, fallback []float64) []float64 {
+	if v, ok := f.value(key); ok {
+		if l, ok := list(v, number); ok {
+			return l
+		}
+	}
+	return fallback
+}
+
+func (f *File) SetFloatList(key string, value []float64) {
+	f.set(key, anyList(value))
+}
+
+func (f *File) Int(key string) int {
+	return f.IntWithFallback(key, 0)
+}
+
+func (f *File) IntWithFallback(key string, fallback int) int {
+	if v, ok := f.value(key); ok {
+		if n, ok := asInt(v); ok {
+			return n
+		}
+	}
+	return fallback
+}
+
+func (f *File) SetInt(key string, value int) {
+	f.set(key, float64(value))
+}
+
+func (f *File) IntList(key string) []int {
+	return f.IntListWithFallback(key, nil)
+}
+
+func (f *File) IntListWithFallback(key string, fallback []int) []int {
+	if v, ok := f.value(key); ok {
+		if l, ok := list(v, asInt); ok {
+			return l
+		}
+	}
+	return fallback
+}
+
+func (f *File) SetIntList(key string, value []int) {
+	floats := make([]float64, len(value))
+	for i, n := range value {
+		floats[i] = float64(n)
+	}
+	f.set(key, anyList(floats))
+}
+
+func (f *File) String(key string) string {
+	return f.StringWithFallback(key, "")
+}
+
+func (f *File) StringWithFallback(key, fallback string) string {
+	if v, ok := f.value(key); ok {
+		if s, ok := asString(v); ok {
+			return s
+		}
+	}
+	return fallback
+}
+
+func (f *File) SetString(key string, value string) {
+	f.set(key, value)
+}
+
+func (f *File) StringList(key string) []string {
+	return f.StringListWithFallback(key, nil)
+}
+
+func (f *File) StringListWithFallback(key string, fallback []string) []string {
+	if v, ok := f.value(key); ok {
+		if l, ok := list(v, asString); ok {
+			return l
+		}
+	}
+	return fallback
+}
+
+func (f *File) SetStringList(key string, value []string) {
+	f.set(key, anyList(value))
+}
+
+func (f *File) RemoveValue(key string) {
+	f.set(key, nil)
+}
+
+func (f *File) AddChangeListener(fn func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.listeners = append(f.listeners, fn)
+}
+
+func (f *File) ChangeListeners() []func() {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return append([]func(){}, f.listeners...)
+}
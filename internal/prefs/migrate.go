@@ -0,0 +1,94 @@
+package prefs
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// Step upgrades the preferences saved by the versions before Version. Steps must be idempotent:
+// they also run for development builds and for installations that never recorded a version.
+type Step struct {
+	Version string // First release expecting the migrated preferences, e.g., "1.1.0"
+	Name    string // Logged when applied
+	Apply   func(p fyne.Preferences)
+}
+
+// Migrate applies, in order, the steps newer than the version that last ran (config.PrefLastRun),
+// up to current, then records current as the last-run version. Without a comparable version
+// (a first run, or a development build), every step newer than the known one applies.
+// It returns the names of the steps applied.
+func Migrate(p fyne.Preferences, current string, steps []Step) []string {
+	last, lastOK := parseVersion(p.String(config.PrefLastRun))
+	now, nowOK := parseVersion(current)
+
+	var applied []string
+	for _, step := range steps {
+		v, ok := parseVersion(step.Version)
+		if !ok || (lastOK && compareVersions(v, last) <= 0) || (nowOK && compareVersions(v, now) > 0) {
+			continue
+		}
+		step.Apply(p)
+		applied = append(applied, step.Name)
+		slog.Info(config.MsgPrefsMigrated,
+			config.LogKeyComponent, config.CompPrefs,
+			config.LogKeyVersion, step.Version,
+			config.LogKeyName, step.Name)
+	}
+	p.SetString(config.PrefLastRun, current)
+	return applied
+}
+
+// Rename moves the value of the key from to the key to, whatever its type. A value already
+// saved under to is kept, and from is removed in any case.
+func Rename(p fyne.Preferences, from, to string) {
+	if value, ok := Lookup(p, from); ok {
+		if _, taken := Lookup(p, to); !taken {
+			Set(p, to, value)
+		}
+	}
+	p.RemoveValue(from)
+}
+
+// parseVersion reads a "1.2.3" version, with an optional "v" prefix and "-rc1" suffix.
+func parseVersion(s string) ([]int, bool) {
+	s = strings.TrimPrefix(s, config.VersionPrefix)
+	s, _, _ = strings.Cut(s, config.VersionPreSep)
+	if s == "" {
+		return nil, false
+	}
+	parts := strings.Split(s, config.VersionSep)
+	v := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+// compareVersions returns -1, 0 or 1 as a is older than, equal to or newer than b;
+// missing components count as 0 ("1.1" is "1.1.0").
+func compareVersions(a, b []int) int {
+	for i := range max(len(a), len(b)) {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package prefs_test
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/prefs"
+)
+
+func TestMigrate(t *testing.T) {
+	var ran []string
+	step := func(version string) prefs.Step {
+		return prefs.Step{Version: version, Name: version, Apply: func(fyne.Preferences) { ran = append(ran, version) }}
+	}
+	steps := []prefs.Step{step("1.0.0"), step("1.1.0"), step("1.2"), step("2.0.0")}
+
+	tests := []struct {
+		name, last, current string
+		want                []string
+	}{
+		{"Upgrade", "1.0.0", "1.2.0", []string{"1.1.0", "1.2"}},
+		{"Prefixed and pre-release", "v1.1.0", "2.0.0-rc1", []string{"1.2", "2.0.0"}},
+		{"Same version", "1.2.0", "1.2.0", nil},
+		{"Downgrade", "2.0.0", "1.0.0", nil},
+		{"Never recorded", "", "1.1.0", []string{"1.0.0", "1.1.0"}},
+		{"Development build", "1.1.0", "dev", []string{"1.2", "2.0.0"}},
+		{"Development builds", "dev", "dev", []string{"1.0.0", "1.1.0", "1.2", "2.0.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := test.NewApp().Preferences()
+			if tt.last != "" {
+				p.SetString(config.PrefLastRun, tt.last)
+			}
+			ran = nil
+
+			applied := prefs.Migrate(p, tt.current, steps)
+			assert.Equal(t, tt.want, ran)
+			assert.Equal(t, tt.want, applied)
+			assert.Equal(t, tt.current, p.String(config.PrefLastRun), "The running version is recorded")
+		})
+	}
+}
+
+func TestRename(t *testing.T) {
+	p := test.NewApp().Preferences()
+	p.SetInt("old_interval", 30)
+	p.SetStringList("old_list", []string{"a", "b"})
+	p.SetString("old_taken", "old")
+	p.SetString("new_taken", "new")
+
+	prefs.Rename(p, "old_interval", "new_interval")
+	prefs.Rename(p, "old_list", "new_list")
+	prefs.Rename(p, "old_taken", "new_taken")
+	prefs.Rename(p, "missing", "new_missing")
+
+	assert.Equal(t, 30, p.Int("new_interval"))
+	assert.Equal(t, []string{"a", "b"}, p.StringList("new_list"))
+	assert.Equal(t, "new", p.String("new_taken"), "A value saved under the new key is kept")
+	for _, key := range []string{"old_interval", "old_list", "old_taken", "new_missing"} {
+		_, ok := prefs.Lookup(p, key)
+		assert.False(t, ok, key)
+	}
+}
@@ -0,0 +1,293 @@
+// Package prefs reads the preferences of the application from a file and the environment,
+// for the container profile where no settings window can write them, keeps drafts of the
+// settings being edited, and upgrades the preferences saved by older versions.
+package prefs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// Overlay serves preferences from a file, the environment or the command line over base. The values
+// are converted to the type asked for, since environment variables are untyped ("18080" reads as
+// a string or an int). A value written by the application replaces the one of the overlay, unless
+// equal to it: saving the settings does not persist the overlay.
+type Overlay struct {
+	fyne.Preferences
+
+	mu     sync.RWMutex
+	values map[string]any // string, bool, float64 or []any, as decoded from JSON, or removed
+	draft  bool           // Values written are kept in the overlay
+}
+
+// removed marks a value removed from a draft, which reads as missing.
+type removed struct{}
+
+// NewOverlay layers over base the preferences of file, when not empty, and those of environ
+// (as os.Environ), which take precedence. The file is in the format of the desktop application
+// (a JSON object of preferences), and a variable config.EnvPrefPrefix + "SERVER_PORT" sets "server_port".
+func NewOverlay(base fyne.Preferences, file string, environ []string) (*Overlay, error) {
+	values := make(map[string]any)
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("%s: %w", config.ErrPrefsFile, err)
+		}
+	}
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, config.EnvPrefPrefix) || name == config.EnvPrefsFile ||
+			strings.HasPrefix(name, config.EnvSecretPrefix) { // Read by the secret storage
+			continue
+		}
+		values[strings.ToLower(strings.TrimPrefix(name, config.EnvPrefPrefix))] = value
+	}
+	return &Overlay{Preferences: base, values: values}, nil
+}
+
+// NewDraft layers over base an overlay that keeps the values written, which never reach base:
+// the settings being edited can be tried before they are saved.
+func NewDraft(base fyne.Preferences) *Overlay {
+	return &Overlay{Preferences: base, values: make(map[string]any), draft: true}
+}
+
+// Override sets the overlay value of key, e.g., from a command-line flag.
+func (o *Overlay) Override(key, value string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.values[key] = value
+}
+
+// value returns the overlay value of key, if any.
+func (o *Overlay) value(key string) (any, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	v, ok := o.values[key]
+	return v, ok
+}
+
+// written drops the overlay value of key, replaced by the application. A draft keeps value
+// instead, and reports that it did, so that base is left untouched.
+func (o *Overlay) written(key string, value any) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.draft {
+		o.values[key] = value
+		return true
+	}
+	delete(o.values, key)
+	return false
+}
+
+// isRemoved reports whether key was removed from a draft.
+func (o *Overlay) isRemoved(key string) bool {
+	v, _ := o.value(key)
+	_, ok := v.(removed)
+	return ok
+}
+
+// ReadValues lists the values saved in base, without the overlay, which is not saved either.
+// It does nothing if base cannot list its values.
+func (o *Overlay) ReadValues(fn func(map[string]any)) {
+	if reader, ok := o.Preferences.(valueReader); ok {
+		reader.ReadValues(fn)
+	}
+}
+
+func (o *Overlay) Bool(key string) bool {
+	return o.BoolWithFallback(key, false)
+}
+
+func (o *Overlay) BoolWithFallback(key string, fallback bool) bool {
+	if o.isRemoved(key) {
+		return fallback
+	}
+	if v, ok := o.value(key); ok {
+		switch v := v.(type) {
+		case bool:
+			return v
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b
+			}
+		}
+	}
+	return o.Preferences.BoolWithFallback(key, fallback)
+}
+
+func (o *Overlay) Int(key string) int {
+	return o.IntWithFallback(key, 0)
+}
+
+func (o *Overlay) IntWithFallback(key string, fallback int) int {
+	if o.isRemoved(key) {
+		return fallback
+	}
+	if f, ok := o.float(key); ok {
+		return int(f)
+	}
+	return o.Preferences.IntWithFallback(key, fallback)
+}
+
+func (o *Overlay) Float(key string) float64 {
+	return o.FloatWithFallback(key, 0)
+}
+
+func (o *Overlay) FloatWithFallback(key string, fallback float64) float64 {
+	if o.isRemoved(key) {
+		return fallback
+	}
+	if f, ok := o.float(key); ok {
+		return f
+	}
+	return o.Preferences.FloatWithFallback(key, fallback)
+}
+
+// float converts the overlay value of key to a number.
+func (o *Overlay) float(key string) (float64, bool) {
+	v, ok := o.value(key)
+	if !ok {
+		return 0, false
+	}
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func (o *Overlay) String(key string) string {
+	return o.StringWithFallback(key, "")
+}
+
+func (o *Overlay) StringWithFallback(key, fallback string) string {
+	if o.isRemoved(key) {
+		return fallback
+	}
+	if v, ok := o.value(key); ok {
+		switch v := v.(type) {
+		case string:
+			return v
+		case bool:
+			return strconv.FormatBool(v)
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return o.Preferences.StringWithFallback(key, fallback)
+}
+
+func (o *Overlay) StringList(key string) []string {
+	return o.StringListWithFallback(key, nil)
+}
+
+// StringListWithFallback reads a JSON array, or a comma-separated list from the environment.
+func (o *Overlay) StringListWithFallback(key string, fallback []string) []string {
+	if o.isRemoved(key) {
+		return fallback
+	}
+	v, ok := o.value(key)
+	if !ok {
+		return o.Preferences.StringListWithFallback(key, fallback)
+	}
+	if s, isString := v.(string); isString {
+		var list []any
+		if json.Unmarshal([]byte(s), &list) == nil {
+			v = list
+		} else {
+			var out []string
+			for _, item := range strings.Split(s, config.ListSeparator) {
+				if item = strings.TrimSpace(item); item != "" {
+					out = append(out, item)
+				}
+			}
+			return out
+		}
+	}
+	list, isList := v.([]any)
+	if !isList {
+		return o.Preferences.StringListWithFallback(key, fallback)
+	}
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, isString := item.(string); isString {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// overlaid reports whether key has an overlay value, which equal reads as the written one.
+func (o *Overlay) overlaid(key string, equal func() bool) bool {
+	_, ok := o.value(key)
+	return ok && equal()
+}
+
+func (o *Overlay) SetBool(key string, value bool) {
+	if o.overlaid(key, func() bool { return o.Bool(key) == value }) {
+		return
+	}
+	if !o.written(key, value) {
+		o.Preferences.SetBool(key, value)
+	}
+}
+
+func (o *Overlay) SetInt(key string, value int) {
+	if o.overlaid(key, func() bool { return o.Int(key) == value }) {
+		return
+	}
+	if !o.written(key, float64(value)) {
+		o.Preferences.SetInt(key, value)
+	}
+}
+
+func (o *Overlay) SetFloat(key string, value float64) {
+	if o.overlaid(key, func() bool { return o.Float(key) == value }) {
+		return
+	}
+	if !o.written(key, value) {
+		o.Preferences.SetFloat(key, value)
+	}
+}
+
+func (o *Overlay) SetString(key string, value string) {
+	if o.overlaid(key, func() bool { return o.String(key) == value }) {
+		return
+	}
+	if !o.written(key, value) {
+		o.Preferences.SetString(key, value)
+	}
+}
+
+func (o *Overlay) SetStringList(key string, value []string) {
+	if o.overlaid(key, func() bool { return slices.Equal(o.StringList(key), value) }) {
+		return
+	}
+	list := make([]any, len(value))
+	for i, v := range value {
+		list[i] = v
+	}
+	if !o.written(key, list) {
+		o.Preferences.SetStringList(key, value)
+	}
+}
+
+func (o *Overlay) RemoveValue(key string) {
+	if !o.written(key, removed{}) {
+		o.Preferences.RemoveValue(key)
+	}
+}
@@ -0,0 +1,149 @@
+package prefs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/prefs"
+)
+
+func TestOverlay(t *testing.T) {
+	base := test.NewApp().Preferences()
+	base.SetString(config.PrefUsername, "base")
+	base.SetInt(config.PrefInterval, 60)
+
+	file := filepath.Join(t.TempDir(), "preferences.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"server_port":"18081","reminder_enabled":true,`+
+		`"reminder_value":2,"event_categories":["Family","Friends"],"source_mode":"local"}`), config.FilePermUserRW))
+
+	p, err := prefs.NewOverlay(base, file, []string{
+		"GO_BIRTHDAY_SOURCE_MODE=web",
+		"GO_BIRTHDAY_REFRESH_INTERVAL_MIN=15",
+		"GO_BIRTHDAY_FILTER_CATEGORIES=Family, Work",
+		"GO_BIRTHDAY_CONFIG=" + file,
+		"GO_BIRTHDAY_SECRET_JANE=p@ssw0rd",
+		"HOME=/data",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "18081", p.String(config.PrefServerPort))
+	assert.Equal(t, "web", p.String(config.PrefSourceMode), "The environment overrides the file")
+	assert.Equal(t, "base", p.String(config.PrefUsername), "Other preferences come from the base")
+	assert.True(t, p.Bool(config.PrefReminderEnabled))
+	assert.Equal(t, 2, p.Int(config.PrefReminderValue))
+	assert.Equal(t, "2", p.String(config.PrefReminderValue))
+	assert.Equal(t, 15, p.IntWithFallback(config.PrefInterval, 0), "Variables are converted to the type asked for")
+	assert.Equal(t, []string{"Family", "Friends"}, p.StringList(config.PrefCategories))
+	assert.Equal(t, []string{"Family", "Work"}, p.StringList(config.PrefIncludeCategories))
+	assert.Empty(t, p.String("config"))
+	assert.Empty(t, p.String("secret_jane"), "Secrets are not preferences")
+
+	p.SetString(config.PrefSourceMode, "jmap")
+	assert.Equal(t, "jmap", p.String(config.PrefSourceMode), "Written values replace the overlay")
+}
+
+func TestOverlay_Override(t *testing.T) {
+	base := test.NewApp().Preferences()
+	base.SetString(config.PrefServerPort, "18080")
+	base.SetInt(config.PrefInterval, 60)
+	p, err := prefs.NewOverlay(base, "", nil)
+	require.NoError(t, err)
+
+	p.Override(config.PrefServerPort, "9090")
+	p.Override(config.PrefInterval, "15")
+	assert.Equal(t, "9090", p.String(config.PrefServerPort))
+
+	p.SetString(config.PrefServerPort, "9090")
+	p.SetInt(config.PrefInterval, 15)
+	assert.Equal(t, "18080", base.String(config.PrefServerPort), "Saving the overridden values does not persist them")
+	assert.Equal(t, 60, base.Int(config.PrefInterval))
+
+	p.SetString(config.PrefServerPort, "9191")
+	assert.Equal(t, "9191", base.String(config.PrefServerPort), "Other values are saved")
+	p.SetString(config.PrefServerPort, "9090")
+	assert.Equal(t, "9090", base.String(config.PrefServerPort), "Once written, the override no longer applies")
+}
+
+func TestDraft(t *testing.T) {
+	base := test.NewApp().Preferences()
+	base.SetString(config.PrefServerPort, "18080")
+	base.SetInt(config.PrefInterval, 60)
+	base.SetString(config.PrefEventColor, "red")
+	p := prefs.NewDraft(base)
+
+	p.SetString(config.PrefServerPort, "9090")
+	p.SetInt(config.PrefInterval, 15)
+	p.SetBool(config.PrefMemorials, true)
+	p.SetStringList(config.PrefReminderTriggers, []string{"-P1D"})
+	p.RemoveValue(config.PrefEventColor)
+	assert.Equal(t, "9090", p.String(config.PrefServerPort))
+	assert.Equal(t, 15, p.Int(config.PrefInterval))
+	assert.True(t, p.Bool(config.PrefMemorials))
+	assert.Equal(t, []string{"-P1D"}, p.StringList(config.PrefReminderTriggers))
+	assert.Equal(t, "blue", p.StringWithFallback(config.PrefEventColor, "blue"), "Removed values read as missing")
+	assert.Equal(t, 60, p.IntWithFallback(config.PrefTodoDays, 60), "Values never written fall back")
+
+	assert.Equal(t, "18080", base.String(config.PrefServerPort), "Base is left untouched")
+	assert.Equal(t, 60, base.Int(config.PrefInterval))
+	assert.False(t, base.Bool(config.PrefMemorials))
+	assert.Empty(t, base.StringList(config.PrefReminderTriggers))
+	assert.Equal(t, "red", base.String(config.PrefEventColor))
+}
+
+func TestOverlay_BadFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "preferences.json")
+	require.NoError(t, os.WriteFile(file, []byte("{"), config.FilePermUserRW))
+	_, err := prefs.NewOverlay(test.NewApp().Preferences(), file, nil)
+	assert.ErrorContains(t, err, config.ErrPrefsFile)
+
+	_, err = prefs.NewOverlay(test.NewApp().Preferences(), filepath.Join(t.TempDir(), "missing.json"), nil)
+	assert.Error(t, err)
+}
+
+// TestFile verifies the preferences of the headless build, saved in the format of the desktop application.
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fyne", config.AppID, "preferences.json")
+	p, err := prefs.NewFile(path)
+	require.NoError(t, err)
+
+	changes := 0
+	p.AddChangeListener(func() { changes++ })
+	p.SetString(config.PrefServerPort, "9090")
+	p.SetInt(config.PrefInterval, 15)
+	p.SetBool(config.PrefMemorials, true)
+	p.SetStringList(config.PrefReminderTriggers, []string{"-P1D", "-PT9H"})
+	p.SetIntList("ages", []int{18, 21})
+	p.SetString(config.PrefEventColor, "red")
+	p.RemoveValue(config.PrefEventColor)
+	assert.Equal(t, 7, changes)
+	assert.Len(t, p.ChangeListeners(), 1)
+
+	// Read back after a restart, numbers and lists decoded from JSON.
+	p, err = prefs.NewFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "9090", p.String(config.PrefServerPort))
+	assert.Equal(t, 15, p.Int(config.PrefInterval))
+	assert.Equal(t, 15.0, p.Float(config.PrefInterval))
+	assert.True(t, p.Bool(config.PrefMemorials))
+	assert.Equal(t, []string{"-P1D", "-PT9H"}, p.StringList(config.PrefReminderTriggers))
+	assert.Equal(t, []int{18, 21}, p.IntList("ages"))
+	assert.Equal(t, "blue", p.StringWithFallback(config.PrefEventColor, "blue"), "Removed")
+	assert.Equal(t, 60, p.IntWithFallback(config.PrefServerPort, 60), "A value of another type falls back")
+	values, ok := prefs.Values(p)
+	require.True(t, ok)
+	assert.Len(t, values, 5)
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), config.FilePermUserRW))
+	_, err = prefs.NewFile(path)
+	assert.ErrorContains(t, err, config.ErrPrefsFile)
+
+	memory, err := prefs.NewFile("")
+	require.NoError(t, err)
+	memory.SetString(config.PrefServerPort, "9090")
+	assert.Equal(t, "9090", memory.String(config.PrefServerPort))
+}
@@ -0,0 +1,72 @@
+package prefs
+
+import (
+	"math"
+
+	"fyne.io/fyne/v2"
+)
+
+// valueReader is implemented by the preferences of Fyne and Overlay, which can be listed.
+type valueReader interface {
+	ReadValues(fn func(map[string]any))
+}
+
+// Values returns a copy of the saved values of p, or false if p cannot list them.
+func Values(p fyne.Preferences) (map[string]any, bool) {
+	reader, ok := p.(valueReader)
+	if !ok {
+		return nil, false
+	}
+	values := make(map[string]any)
+	reader.ReadValues(func(saved map[string]any) {
+		for key, value := range saved {
+			values[key] = value
+		}
+	})
+	return values, true
+}
+
+// Lookup returns the saved value of key, whatever its type.
+func Lookup(p fyne.Preferences, key string) (any, bool) {
+	var value any
+	var ok bool
+	if reader, isReader := p.(valueReader); isReader {
+		reader.ReadValues(func(saved map[string]any) { value, ok = saved[key] })
+	}
+	return value, ok
+}
+
+// Set saves value with the setter of its type, reporting whether the type is supported.
+// Values decoded from JSON are accepted: whole numbers are saved as integers, as Fyne reads them back,
+// and arrays of strings as string lists.
+func Set(p fyne.Preferences, key string, value any) bool {
+	switch v := value.(type) {
+	case bool:
+		p.SetBool(key, v)
+	case string:
+		p.SetString(key, v)
+	case int:
+		p.SetInt(key, v)
+	case float64:
+		if v == math.Trunc(v) {
+			p.SetInt(key, int(v))
+		} else {
+			p.SetFloat(key, v)
+		}
+	case []string:
+		p.SetStringList(key, v)
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return false
+			}
+			list = append(list, s)
+		}
+		p.SetStringList(key, list)
+	default:
+		return false
+	}
+	return true
+}
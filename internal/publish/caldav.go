@@ -0,0 +1,325 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// CalDAV publishes each event of the calendar as a resource of a CalDAV calendar collection
+// (RFC 4791), so that birthdays appear in a calendar the user already synchronizes.
+// Only resources named after the UIDs of generated events are updated or deleted:
+// other events of the collection are left alone.
+type CalDAV struct {
+	Client      *http.Client // Defaults to a client with config.HTTPTimeout
+	URL         string       // Calendar collection, e.g., "https://dav.example.com/calendars/me/birthdays/"
+	Credentials Credentials
+	UIDDomain   string // Domain of the event UIDs (engine.SyncConfig.UIDDomain), config.ICalDomain if empty
+
+	// Published resources, to skip unchanged events on the next run.
+	mu        sync.Mutex
+	published map[string]resource // By path
+}
+
+// resource is an event published by a previous run.
+type resource struct {
+	etag string   // Returned by the server, empty if it sent none
+	sum  [32]byte // Content, without DTSTAMP
+}
+
+// CalDAVStats summarizes a run.
+type CalDAVStats struct {
+	Created, Updated, Deleted, Unchanged, Conflicts int
+}
+
+// Publish uploads the events of calendar and deletes those of previous runs that are gone.
+// Every request is conditional: a resource modified on the server since it was listed is reported
+// as a conflict and left untouched, then overwritten by the next run if it is still outdated.
+func (c *CalDAV) Publish(ctx context.Context, calendar io.Reader) error {
+	_, err := c.publish(ctx, calendar)
+	return err
+}
+
+func (c *CalDAV) publish(ctx context.Context, calendar io.Reader) (CalDAVStats, error) {
+	var stats CalDAVStats
+	base, err := url.Parse(c.URL)
+	if err != nil || (base.Scheme != config.SchemeHTTP && base.Scheme != config.SchemeHTTPS) {
+		return stats, fmt.Errorf("%s: %q", config.ErrCalDAVURL, c.URL)
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+
+	local, err := splitEvents(calendar, base)
+	if err != nil {
+		return stats, err
+	}
+	remote, err := c.list(ctx, base)
+	if err != nil {
+		return stats, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.published == nil {
+		c.published = make(map[string]resource)
+	}
+
+	var errs []error
+	for path, ev := range local {
+		etag, exists := remote[path]
+		if prev, ok := c.published[path]; ok && exists && prev.sum == ev.sum && (prev.etag == "" || prev.etag == etag) {
+			c.published[path] = resource{etag: etag, sum: ev.sum}
+			stats.Unchanged++
+			continue
+		}
+
+		newTag, err := c.put(ctx, base, path, ev.data, etag, exists)
+		switch {
+		case errors.Is(err, errConflict):
+			delete(c.published, path)
+			stats.Conflicts++
+			c.logConflict(path)
+		case err != nil:
+			delete(c.published, path)
+			errs = append(errs, err)
+		default:
+			c.published[path] = resource{etag: newTag, sum: ev.sum}
+			if exists {
+				stats.Updated++
+			} else {
+				stats.Created++
+			}
+		}
+	}
+
+	for path, etag := range remote {
+		if _, ok := local[path]; ok {
+			continue
+		}
+		delete(c.published, path)
+		err := c.delete(ctx, base, path, etag)
+		switch {
+		case errors.Is(err, errConflict):
+			stats.Conflicts++
+			c.logConflict(path)
+		case err != nil:
+			errs = append(errs, err)
+		default:
+			stats.Deleted++
+		}
+	}
+	for path := range c.published {
+		if _, ok := local[path]; !ok {
+			delete(c.published, path)
+		}
+	}
+
+	slog.Info(config.MsgCalDAVPublished,
+		config.LogKeyComponent, config.CompPublish,
+		config.LogKeyStats, stats)
+	return stats, errors.Join(errs...)
+}
+
+// errConflict reports a failed If-Match or If-None-Match precondition.
+var errConflict = errors.New(config.ErrCalDAVConflict)
+
+// event is one event of the calendar, wrapped in its own VCALENDAR.
+type event struct {
+	data []byte
+	sum  [32]byte
+}
+
+// splitEvents returns the events of calendar, by the path of their resource in the collection.
+func splitEvents(calendar io.Reader, base *url.URL) (map[string]event, error) {
+	cal, err := ical.NewDecoder(calendar).Decode()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrCalDAVEncode, err)
+	}
+
+	events := make(map[string]event)
+	for _, child := range cal.Children {
+		if child.Name != ical.CompEvent {
+			continue
+		}
+		uid, err := child.Props.Text(config.PropUID)
+		if err != nil || uid == "" {
+			continue
+		}
+
+		// Calendar collections do not accept METHOD (RFC 4791, section 4.1).
+		single := ical.NewCalendar()
+		for name, props := range cal.Props {
+			if name != config.PropMethod {
+				single.Props[name] = props
+			}
+		}
+		single.Children = []*ical.Component{child}
+		var buf bytes.Buffer
+		if err := ical.NewEncoder(&buf).Encode(single); err != nil {
+			return nil, fmt.Errorf("%s: %w", config.ErrCalDAVEncode, err)
+		}
+
+		// DTSTAMP changes with every run: it is left out of the comparison.
+		stamp := child.Props[config.PropDTStamp]
+		child.Props.SetDateTime(config.PropDTStamp, time.Time{})
+		var unstamped bytes.Buffer
+		if err := ical.NewEncoder(&unstamped).Encode(single); err != nil {
+			return nil, fmt.Errorf("%s: %w", config.ErrCalDAVEncode, err)
+		}
+		if stamp != nil {
+			child.Props[config.PropDTStamp] = stamp
+		} else {
+			delete(child.Props, config.PropDTStamp)
+		}
+
+		path := base.ResolveReference(&url.URL{Path: url.PathEscape(uid) + config.ExtICS}).Path
+		events[path] = event{data: buf.Bytes(), sum: sha256.Sum256(unstamped.Bytes())}
+	}
+	return events, nil
+}
+
+// multistatus is the answer to PROPFIND (RFC 4918, section 14.16).
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			ETag string `xml:"DAV: prop>getetag"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+// list returns the etag of the resources of the collection created by this application, by path.
+func (c *CalDAV) list(ctx context.Context, base *url.URL) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, config.MethodPropfind, base.String(), strings.NewReader(config.PropfindETag))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(config.HeaderContentType, config.MimeXML)
+	req.Header.Set(config.HeaderDepth, "1")
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("%s: PROPFIND %s", config.ErrCalDAVStatus, resp.Status)
+	}
+
+	var ms multistatus
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, config.CalDAVMaxListing)).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrCalDAVListing, err)
+	}
+
+	// Resources named after the generated UIDs are the only ones managed.
+	domain := c.UIDDomain
+	if domain == "" {
+		domain = config.ICalDomain
+	}
+	own := "@" + domain + config.ExtICS
+
+	remote := make(map[string]string)
+	for _, r := range ms.Responses {
+		href, err := url.Parse(r.Href)
+		if err != nil {
+			continue
+		}
+		path := base.ResolveReference(href).Path
+		if !strings.HasSuffix(path, own) {
+			continue
+		}
+		var etag string
+		for _, ps := range r.Propstat {
+			if ps.ETag != "" {
+				etag = ps.ETag
+			}
+		}
+		remote[path] = etag
+	}
+	return remote, nil
+}
+
+// put uploads data to path, provided it still has etag (exists) or does not exist yet.
+// It returns the new etag, if the server sent one.
+func (c *CalDAV) put(ctx context.Context, base *url.URL, path string, data []byte, etag string, exists bool) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, base.ResolveReference(&url.URL{Path: path}).String(), bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(config.HeaderContentType, config.MimeTextCalendar)
+	setPrecondition(req, etag, exists)
+	resp, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed:
+		return "", errConflict
+	case resp.StatusCode/100 != 2:
+		return "", fmt.Errorf("%s: PUT %s: %s", config.ErrCalDAVStatus, path, resp.Status)
+	}
+	return resp.Header.Get(config.HeaderETag), nil
+}
+
+// delete removes path, provided it still has etag.
+func (c *CalDAV) delete(ctx context.Context, base *url.URL, path, etag string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, base.ResolveReference(&url.URL{Path: path}).String(), nil)
+	if err != nil {
+		return err
+	}
+	setPrecondition(req, etag, true)
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed:
+		return errConflict
+	case resp.StatusCode == http.StatusNotFound: // Already gone
+		return nil
+	case resp.StatusCode/100 != 2:
+		return fmt.Errorf("%s: DELETE %s: %s", config.ErrCalDAVStatus, path, resp.Status)
+	}
+	return nil
+}
+
+// setPrecondition makes req apply only to the resource version the server listed.
+func setPrecondition(req *http.Request, etag string, exists bool) {
+	switch {
+	case !exists:
+		req.Header.Set(config.HeaderIfNoneMatch, "*")
+	case etag != "":
+		req.Header.Set(config.HeaderIfMatch, etag)
+	}
+}
+
+func (c *CalDAV) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set(config.HeaderUserAgent, config.UserAgent)
+	c.Credentials.apply(req)
+	client := c.Client
+	if client == nil {
+		client = newClient()
+	}
+	return client.Do(req)
+}
+
+func (c *CalDAV) logConflict(path string) {
+	slog.Warn(config.ErrCalDAVConflict,
+		config.LogKeyComponent, config.CompPublish,
+		config.LogKeyPath, path)
+}
@@ -0,0 +1,188 @@
+package publish
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// davServer is an in-memory calendar collection honoring If-Match and If-None-Match.
+type davServer struct {
+	mu        sync.Mutex
+	resources map[string]string // Path -> content
+	versions  map[string]int    // Path -> version, the etag
+	puts      int
+	bump      bool // Modify every resource right after listing them, as another client would
+}
+
+func newDAVServer(t *testing.T) (*davServer, *httptest.Server) {
+	d := &davServer{resources: make(map[string]string), versions: make(map[string]int)}
+	srv := httptest.NewServer(http.HandlerFunc(d.serve))
+	t.Cleanup(srv.Close)
+	return d, srv
+}
+
+func (d *davServer) etag(path string) string {
+	return fmt.Sprintf(`"%d"`, d.versions[path])
+}
+
+func (d *davServer) serve(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	user, pass, _ := r.BasicAuth()
+	if user != "me" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := r.URL.Path
+	_, exists := d.resources[path]
+	switch r.Method {
+	case config.MethodPropfind:
+		var b strings.Builder
+		b.WriteString(`<?xml version="1.0"?><d:multistatus xmlns:d="DAV:">`)
+		b.WriteString(`<d:response><d:href>/cal/</d:href><d:propstat><d:prop/></d:propstat></d:response>`)
+		for p := range d.resources {
+			fmt.Fprintf(&b, `<d:response><d:href>%s</d:href><d:propstat><d:prop><d:getetag>%s</d:getetag></d:prop></d:propstat></d:response>`, p, d.etag(p))
+		}
+		b.WriteString(`</d:multistatus>`)
+		w.WriteHeader(http.StatusMultiStatus)
+		_, _ = io.WriteString(w, b.String())
+		if d.bump {
+			for p := range d.versions {
+				d.versions[p]++
+			}
+		}
+	case http.MethodPut:
+		if (r.Header.Get("If-None-Match") == "*" && exists) || (r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != d.etag(path)) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		d.resources[path] = string(body)
+		d.versions[path]++
+		d.puts++
+		w.Header().Set("ETag", d.etag(path))
+		if exists {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case http.MethodDelete:
+		if r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != d.etag(path) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		delete(d.resources, path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// calendar returns an iCalendar object with one event per UID, summaries as given.
+func calendar(stamp string, events map[string]string) io.Reader {
+	var b strings.Builder
+	b.WriteString("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Test//EN\r\nMETHOD:PUBLISH\r\n")
+	for uid, summary := range events {
+		fmt.Fprintf(&b, "BEGIN:VEVENT\r\nUID:%s\r\nDTSTAMP:%s\r\nDTSTART;VALUE=DATE:20250101\r\nSUMMARY:%s\r\nEND:VEVENT\r\n", uid, stamp, summary)
+	}
+	b.WriteString("END:VCALENDAR\r\n")
+	return strings.NewReader(b.String())
+}
+
+// TestCalDAV_Publish verifies creation, unchanged events, updates, deletions and foreign events.
+func TestCalDAV_Publish(t *testing.T) {
+	dav, srv := newDAVServer(t)
+	dav.resources["/cal/mine.ics"] = "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n" // Created by the user
+	c := &CalDAV{URL: srv.URL + "/cal", Credentials: Credentials{User: "me", Pass: "secret"}}
+
+	events := map[string]string{
+		"a-2025@gobirthday": "Alice",
+		"b-2025@gobirthday": "Bob",
+	}
+	stats, err := c.publish(context.Background(), calendar("20250101T000000Z", events))
+	require.NoError(t, err)
+	assert.Equal(t, CalDAVStats{Created: 2}, stats)
+	require.Contains(t, dav.resources, "/cal/a-2025@gobirthday.ics")
+	assert.Contains(t, dav.resources["/cal/a-2025@gobirthday.ics"], "SUMMARY:Alice")
+	assert.NotContains(t, dav.resources["/cal/a-2025@gobirthday.ics"], "METHOD", "Calendar collections reject METHOD")
+	assert.NotContains(t, dav.resources["/cal/a-2025@gobirthday.ics"], "Bob", "One event per resource")
+
+	// Only DTSTAMP changed: nothing is uploaded.
+	stats, err = c.publish(context.Background(), calendar("20250102T000000Z", events))
+	require.NoError(t, err)
+	assert.Equal(t, CalDAVStats{Unchanged: 2}, stats)
+	assert.Equal(t, 2, dav.puts)
+
+	delete(events, "b-2025@gobirthday")
+	events["a-2025@gobirthday"] = "Alice (30)"
+	stats, err = c.publish(context.Background(), calendar("20250103T000000Z", events))
+	require.NoError(t, err)
+	assert.Equal(t, CalDAVStats{Updated: 1, Deleted: 1}, stats)
+	assert.Contains(t, dav.resources["/cal/a-2025@gobirthday.ics"], "SUMMARY:Alice (30)")
+	assert.NotContains(t, dav.resources, "/cal/b-2025@gobirthday.ics")
+	assert.Contains(t, dav.resources, "/cal/mine.ics", "Events of other clients are left alone")
+}
+
+// TestCalDAV_UIDDomain verifies that the events of a custom UID domain are recognized on later runs.
+func TestCalDAV_UIDDomain(t *testing.T) {
+	dav, srv := newDAVServer(t)
+	c := &CalDAV{URL: srv.URL + "/cal/", Credentials: Credentials{User: "me", Pass: "secret"}, UIDDomain: "work"}
+
+	events := map[string]string{"a-2025@work": "Alice", "b-2025@work": "Bob"}
+	stats, err := c.publish(context.Background(), calendar("20250101T000000Z", events))
+	require.NoError(t, err)
+	assert.Equal(t, CalDAVStats{Created: 2}, stats)
+
+	events["a-2025@work"] = "Alice (30)"
+	stats, err = c.publish(context.Background(), calendar("20250102T000000Z", events))
+	require.NoError(t, err)
+	assert.Equal(t, CalDAVStats{Updated: 1, Unchanged: 1}, stats)
+
+	delete(events, "b-2025@work")
+	stats, err = c.publish(context.Background(), calendar("20250103T000000Z", events))
+	require.NoError(t, err)
+	assert.Equal(t, CalDAVStats{Deleted: 1, Unchanged: 1}, stats)
+	assert.NotContains(t, dav.resources, "/cal/b-2025@work.ics")
+	assert.Contains(t, dav.resources["/cal/a-2025@work.ics"], "SUMMARY:Alice (30)")
+}
+
+// TestCalDAV_Conflict verifies that a resource modified after the listing is left untouched.
+func TestCalDAV_Conflict(t *testing.T) {
+	dav, srv := newDAVServer(t)
+	c := &CalDAV{URL: srv.URL + "/cal/", Credentials: Credentials{User: "me", Pass: "secret"}}
+
+	_, err := c.publish(context.Background(), calendar("20250101T000000Z", map[string]string{"a-2025@gobirthday": "Alice"}))
+	require.NoError(t, err)
+
+	dav.bump = true
+	stats, err := c.publish(context.Background(), calendar("20250101T000000Z", map[string]string{"a-2025@gobirthday": "Alice (30)"}))
+	require.NoError(t, err)
+	assert.Equal(t, CalDAVStats{Conflicts: 1}, stats)
+	assert.Contains(t, dav.resources["/cal/a-2025@gobirthday.ics"], "SUMMARY:Alice\r\n")
+
+	// The next run overwrites the outdated event.
+	dav.bump = false
+	stats, err = c.publish(context.Background(), calendar("20250101T000000Z", map[string]string{"a-2025@gobirthday": "Alice (30)"}))
+	require.NoError(t, err)
+	assert.Equal(t, CalDAVStats{Updated: 1}, stats)
+}
+
+// TestCalDAV_Errors verifies the reporting of invalid URLs and server errors.
+func TestCalDAV_Errors(t *testing.T) {
+	c := &CalDAV{URL: "ftp://dav.example.com/cal/"}
+	assert.ErrorContains(t, c.Publish(context.Background(), calendar("20250101T000000Z", nil)), config.ErrCalDAVURL)
+
+	_, srv := newDAVServer(t)
+	c = &CalDAV{URL: srv.URL + "/cal/", Credentials: Credentials{User: "me", Pass: "wrong"}}
+	assert.ErrorContains(t, c.Publish(context.Background(), calendar("20250101T000000Z", nil)), "401")
+}
@@ -0,0 +1,53 @@
+package publish
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// File writes the calendar to a local file, e.g., in a folder shared with Syncthing or Dropbox,
+// or served by a web server the user already runs. The file is replaced atomically,
+// so readers never see a partial calendar.
+type File struct {
+	Path string
+}
+
+// Publish replaces the file with calendar.
+func (f *File) Publish(ctx context.Context, calendar io.Reader) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	dir := filepath.Dir(f.Path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(f.Path)+"-*")
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.ErrOutputFile, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }() // No-op once renamed
+
+	size, err := io.Copy(tmp, calendar)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		// Temporary files are private; the calendar is meant to be shared.
+		err = os.Chmod(tmp.Name(), config.FilePermShared)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), f.Path)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.ErrOutputFile, err)
+	}
+
+	slog.Info(config.MsgFileWritten,
+		config.LogKeyComponent, config.CompPublish,
+		config.LogKeyFile, f.Path,
+		config.LogKeySizeBytes, size)
+	return nil
+}
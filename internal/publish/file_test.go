@@ -0,0 +1,39 @@
+package publish
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// TestFile_Publish verifies that the file is replaced, readable by others, and that no temporary file is left.
+func TestFile_Publish(t *testing.T) {
+	dir := t.TempDir()
+	f := &File{Path: filepath.Join(dir, "birthdays.ics")}
+
+	require.NoError(t, f.Publish(context.Background(), strings.NewReader("first")))
+	require.NoError(t, f.Publish(context.Background(), strings.NewReader("second")))
+
+	data, err := os.ReadFile(f.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(f.Path)
+		require.NoError(t, err)
+		assert.Equal(t, config.FilePermShared, info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "No temporary file is left behind")
+
+	f.Path = filepath.Join(dir, "missing", "birthdays.ics")
+	assert.ErrorContains(t, f.Publish(context.Background(), strings.NewReader("x")), config.ErrOutputFile)
+}
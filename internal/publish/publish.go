@@ -0,0 +1,37 @@
+// Package publish sends the generated calendar to places other than the local HTTP server.
+package publish
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// Target receives the calendar after each successful synchronization.
+type Target interface {
+	Publish(ctx context.Context, calendar io.Reader) error
+}
+
+// Credentials authenticate the requests of a Target: a bearer token if set, HTTP Basic Auth otherwise.
+type Credentials struct {
+	User  string
+	Pass  string
+	Token string
+}
+
+// apply sets the credentials of req, if any.
+func (c Credentials) apply(req *http.Request) {
+	switch {
+	case c.Token != "":
+		req.Header.Set(config.HeaderAuthorization, config.AuthBearerPrefix+c.Token)
+	case c.User != "" || c.Pass != "":
+		req.SetBasicAuth(c.User, c.Pass)
+	}
+}
+
+// newClient returns the HTTP client used when a Target has none.
+func newClient() *http.Client {
+	return &http.Client{Timeout: config.HTTPTimeout}
+}
@@ -0,0 +1,128 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// WebDAV uploads the calendar as a single file to a WebDAV server (e.g., Nextcloud files),
+// so that phones can subscribe to it without reaching the local HTTP server.
+type WebDAV struct {
+	Client      *http.Client // Defaults to a client with config.HTTPTimeout
+	URL         string       // File, or folder ending with "/" to upload config.ExportCalFileName into
+	Credentials Credentials
+
+	// ETag of the last upload, to detect changes made by someone else.
+	mu   sync.Mutex
+	etag string
+}
+
+// Publish replaces the file with calendar, provided nobody else modified it since the last upload.
+// A conflicting file is left untouched once, then overwritten by the next run.
+func (d *WebDAV) Publish(ctx context.Context, calendar io.Reader) error {
+	target, err := url.Parse(d.URL)
+	if err != nil || (target.Scheme != config.SchemeHTTP && target.Scheme != config.SchemeHTTPS) {
+		return fmt.Errorf("%s: %q", config.ErrWebDAVURL, d.URL)
+	}
+	if strings.HasSuffix(target.Path, "/") {
+		target = target.JoinPath(config.ExportCalFileName)
+	}
+	data, err := io.ReadAll(calendar)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Without the ETag of our last upload, start from the current version of the file.
+	etag, exists := d.etag, d.etag != ""
+	if !exists {
+		if etag, exists, err = d.head(ctx, target); err != nil {
+			return err
+		}
+	}
+
+	newTag, err := d.put(ctx, target, data, etag, exists)
+	switch {
+	case errors.Is(err, errConflict):
+		d.etag = ""
+		slog.Warn(config.ErrWebDAVConflict,
+			config.LogKeyComponent, config.CompPublish,
+			config.LogKeyURL, target.Redacted())
+		return nil
+	case err != nil:
+		d.etag = ""
+		return err
+	}
+	d.etag = newTag
+
+	slog.Info(config.MsgWebDAVPublished,
+		config.LogKeyComponent, config.CompPublish,
+		config.LogKeyURL, target.Redacted(),
+		config.LogKeySizeBytes, len(data))
+	return nil
+}
+
+// head returns the ETag of the file, and whether it exists.
+func (d *WebDAV) head(ctx context.Context, target *url.URL) (string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target.String(), nil)
+	if err != nil {
+		return "", false, err
+	}
+	resp, err := d.do(req)
+	if err != nil {
+		return "", false, err
+	}
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", false, nil
+	case resp.StatusCode/100 != 2:
+		return "", false, fmt.Errorf("%s: HEAD %s", config.ErrWebDAVStatus, resp.Status)
+	}
+	return resp.Header.Get(config.HeaderETag), true, nil
+}
+
+// put uploads data, provided the file still has etag (exists) or does not exist yet.
+// It returns the new ETag, if the server sent one.
+func (d *WebDAV) put(ctx context.Context, target *url.URL, data []byte, etag string, exists bool) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(config.HeaderContentType, config.MimeTextCalendar)
+	setPrecondition(req, etag, exists)
+	resp, err := d.do(req)
+	if err != nil {
+		return "", err
+	}
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed:
+		return "", errConflict
+	case resp.StatusCode/100 != 2:
+		return "", fmt.Errorf("%s: PUT %s", config.ErrWebDAVStatus, resp.Status)
+	}
+	return resp.Header.Get(config.HeaderETag), nil
+}
+
+func (d *WebDAV) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set(config.HeaderUserAgent, config.UserAgent)
+	d.Credentials.apply(req)
+	client := d.Client
+	if client == nil {
+		client = newClient()
+	}
+	return client.Do(req)
+}
@@ -0,0 +1,94 @@
+package publish
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// fileServer is a single WebDAV file honoring If-Match and If-None-Match.
+type fileServer struct {
+	mu      sync.Mutex
+	path    string // Path of the last request
+	content string
+	version int // 0 while the file does not exist
+	puts    int
+}
+
+func (f *fileServer) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer tok" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	f.path = r.URL.Path
+	etag := fmt.Sprintf(`"%d"`, f.version)
+	switch r.Method {
+	case http.MethodHead:
+		if f.version == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", etag)
+	case http.MethodPut:
+		if (r.Header.Get("If-None-Match") == "*" && f.version > 0) || (r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != etag) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		f.content = string(body)
+		f.version++
+		f.puts++
+		w.Header().Set("ETag", fmt.Sprintf(`"%d"`, f.version))
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+// TestWebDAV_Publish verifies uploads, the folder URL shorthand and conflicts with other writers.
+func TestWebDAV_Publish(t *testing.T) {
+	fs := &fileServer{}
+	srv := httptest.NewServer(http.HandlerFunc(fs.serve))
+	defer srv.Close()
+	d := &WebDAV{URL: srv.URL + "/files/me/", Credentials: Credentials{User: "ignored", Token: "tok"}}
+
+	require.NoError(t, d.Publish(context.Background(), strings.NewReader("first")))
+	assert.Equal(t, "/files/me/"+config.ExportCalFileName, fs.path)
+	require.NoError(t, d.Publish(context.Background(), strings.NewReader("second")))
+	assert.Equal(t, "second", fs.content)
+
+	// Someone else modifies the file: it is kept once, then overwritten.
+	fs.version++
+	fs.content = "theirs"
+	require.NoError(t, d.Publish(context.Background(), strings.NewReader("third")))
+	assert.Equal(t, "theirs", fs.content)
+	require.NoError(t, d.Publish(context.Background(), strings.NewReader("third")))
+	assert.Equal(t, "third", fs.content)
+	assert.Equal(t, 3, fs.puts)
+
+	// A new instance starts from the version on the server.
+	d = &WebDAV{URL: srv.URL + "/files/me/" + config.ExportCalFileName, Credentials: Credentials{Token: "tok"}}
+	require.NoError(t, d.Publish(context.Background(), strings.NewReader("fourth")))
+	assert.Equal(t, "fourth", fs.content)
+}
+
+// TestWebDAV_Errors verifies the reporting of invalid URLs and server errors.
+func TestWebDAV_Errors(t *testing.T) {
+	d := &WebDAV{URL: "ftp://dav.example.com/birthdays.ics"}
+	assert.ErrorContains(t, d.Publish(context.Background(), strings.NewReader("x")), config.ErrWebDAVURL)
+
+	srv := httptest.NewServer(http.HandlerFunc((&fileServer{}).serve))
+	defer srv.Close()
+	d = &WebDAV{URL: srv.URL + "/birthdays.ics", Credentials: Credentials{Token: "wrong"}}
+	assert.ErrorContains(t, d.Publish(context.Background(), strings.NewReader("x")), "401")
+}
@@ -0,0 +1,76 @@
+package secrets
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// envStore reads the secrets from the environment, for containers: the secret of "push_token" is
+// GO_BIRTHDAY_SECRET_PUSH_TOKEN, or the content of the file named by GO_BIRTHDAY_SECRET_PUSH_TOKEN_FILE
+// (e.g., a Docker secret). Secrets saved at runtime, such as a rotated OAuth2 token, stay in memory.
+type envStore struct {
+	mu      sync.Mutex
+	saved   map[string]string
+	deleted map[string]bool
+}
+
+func newEnvStore() *envStore {
+	return &envStore{saved: make(map[string]string), deleted: make(map[string]bool)}
+}
+
+// envName returns the variable holding the secret of account; other characters than
+// ASCII letters and digits become underscores ("jane@example.com" is JANE_EXAMPLE_COM).
+func envName(account string) string {
+	return config.EnvSecretPrefix + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, account)
+}
+
+func (s *envStore) Get(account string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if secret, ok := s.saved[account]; ok {
+		return secret, nil
+	}
+	if s.deleted[account] {
+		return "", ErrNotFound
+	}
+
+	name := envName(account)
+	if secret := os.Getenv(name); secret != "" {
+		return secret, nil
+	}
+	if path := os.Getenv(name + config.EnvFileSuffix); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	return "", ErrNotFound
+}
+
+func (s *envStore) Set(account, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saved[account] = secret
+	delete(s.deleted, account)
+	return nil
+}
+
+func (s *envStore) Delete(account string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.saved, account)
+	s.deleted[account] = true
+	return nil
+}
@@ -0,0 +1,234 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// fileStore keeps the secrets in a JSON object encrypted with AES-256-GCM.
+// The key is derived with PBKDF2 from the passphrase if one is set, and otherwise from the
+// machine ID and the user name. The machine key keeps the file unreadable when copied
+// elsewhere, but not from other programs of the same user on the same machine.
+type fileStore struct {
+	path       string
+	passphrase string
+
+	mu      sync.Mutex
+	salt    []byte // Salt of the cached key
+	derived []byte
+}
+
+// envelope is the content of the secrets file.
+type envelope struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+func newFileStore(dir, passphrase string) *fileStore {
+	if passphrase == "" {
+		passphrase = machineKey()
+	}
+	return &fileStore{path: filepath.Join(dir, config.SecretsFile), passphrase: passphrase}
+}
+
+// machineKey identifies the machine and the user, as a passphrase of last resort.
+func machineKey() string {
+	var id string
+	for _, path := range config.MachineIDFiles {
+		if data, err := os.ReadFile(path); err == nil {
+			id = strings.TrimSpace(string(data))
+			break
+		}
+	}
+	if id == "" {
+		id, _ = os.Hostname()
+	}
+	var name string
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	return config.AppID + ":" + id + ":" + name
+}
+
+func (s *fileStore) Get(account string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	secret, ok := all[account]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (s *fileStore) Set(account, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.load()
+	if err != nil {
+		return err
+	}
+	all[account] = secret
+	return s.save(all)
+}
+
+func (s *fileStore) Delete(account string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := all[account]; !ok {
+		return ErrNotFound
+	}
+	delete(all, account)
+	return s.save(all)
+}
+
+// load decrypts the file, returning an empty map if it does not exist yet.
+func (s *fileStore) load() (map[string]string, error) {
+	all := make(map[string]string)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrSecretsRead, err)
+	}
+	return s.decrypt(data)
+}
+
+// decrypt returns the secrets of an envelope.
+func (s *fileStore) decrypt(data []byte) (map[string]string, error) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrSecretsRead, err)
+	}
+	gcm, err := s.cipher(env.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, env.Nonce, env.Data, nil)
+	if err != nil {
+		return nil, errors.New(config.ErrSecretsDecrypt)
+	}
+	all := make(map[string]string)
+	if err := json.Unmarshal(plain, &all); err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrSecretsRead, err)
+	}
+	return all, nil
+}
+
+// encrypt returns the envelope of all, with a new nonce.
+func (s *fileStore) encrypt(all map[string]string) ([]byte, error) {
+	salt := s.salt
+	if salt == nil {
+		salt = make([]byte, config.SecretsSaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("%s: %w", config.ErrSecretsWrite, err)
+		}
+	}
+	gcm, err := s.cipher(salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := json.Marshal(all)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrSecretsWrite, err)
+	}
+	env := envelope{Version: config.SecretsFileVersion, Salt: salt, Nonce: make([]byte, gcm.NonceSize())}
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrSecretsWrite, err)
+	}
+	env.Data = gcm.Seal(nil, env.Nonce, plain, nil)
+	data, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrSecretsWrite, err)
+	}
+	return data, nil
+}
+
+// Seal encrypts secrets with passphrase, in the format of the secrets file, e.g., for a backup
+// to be restored on another machine.
+func Seal(passphrase string, secrets map[string]string) ([]byte, error) {
+	s := &fileStore{passphrase: passphrase}
+	return s.encrypt(secrets)
+}
+
+// Unseal decrypts the result of Seal. A wrong passphrase fails with config.ErrSecretsDecrypt.
+func Unseal(passphrase string, data []byte) (map[string]string, error) {
+	s := &fileStore{passphrase: passphrase}
+	return s.decrypt(data)
+}
+
+// save encrypts all with a new nonce and replaces the file atomically.
+func (s *fileStore) save(all map[string]string) error {
+	data, err := s.encrypt(all)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), config.DirPermUserRWX); err != nil {
+		return fmt.Errorf("%s: %w", config.ErrSecretsWrite, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), config.SecretsFile+"-*")
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.ErrSecretsWrite, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }() // No-op once renamed
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.ErrSecretsWrite, err)
+	}
+	return nil
+}
+
+// remove deletes the file.
+func (s *fileStore) remove() error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s: %w", config.ErrSecretsWrite, err)
+	}
+	return nil
+}
+
+// cipher returns the AES-GCM cipher of salt, deriving the key only when the salt changes.
+func (s *fileStore) cipher(salt []byte) (cipher.AEAD, error) {
+	if s.derived == nil || !bytes.Equal(s.salt, salt) {
+		key, err := pbkdf2.Key(sha256.New, s.passphrase, salt, config.SecretsKDFIterations, config.SecretsKeySize)
+		if err != nil {
+			return nil, err
+		}
+		s.salt, s.derived = salt, key
+	}
+	block, err := aes.NewCipher(s.derived)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,158 @@
+// Package secrets keeps passwords and tokens in the OS keyring (Secret Service, Keychain, Credential Manager).
+// Where no keyring is available, e.g., on minimal Linux installs without a Secret Service,
+// they are kept in a file encrypted with AES-256-GCM instead.
+package secrets
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/zalando/go-keyring"
+)
+
+// ErrNotFound is returned by Get for an account without a secret.
+var ErrNotFound = keyring.ErrNotFound
+
+// Store is a storage of secrets, indexed by account.
+type Store interface {
+	Get(account string) (string, error)
+	Set(account, secret string) error
+	Delete(account string) error
+}
+
+// keyringStore uses the OS keyring, under config.KeyringService.
+type keyringStore struct{}
+
+func (keyringStore) Get(account string) (string, error) {
+	return keyring.Get(config.KeyringService, account)
+}
+
+func (keyringStore) Set(account, secret string) error {
+	return keyring.Set(config.KeyringService, account, secret)
+}
+
+func (keyringStore) Delete(account string) error {
+	return keyring.Delete(config.KeyringService, account)
+}
+
+var (
+	mu     sync.RWMutex
+	active Store = keyringStore{} // Until Open selects the backend
+)
+
+func current() Store {
+	mu.RLock()
+	defer mu.RUnlock()
+	return active
+}
+
+// Get returns the secret of account, or ErrNotFound.
+func Get(account string) (string, error) {
+	return current().Get(account)
+}
+
+// Set saves the secret of account.
+func Set(account, secret string) error {
+	return current().Set(account, secret)
+}
+
+// Delete removes the secret of account.
+func Delete(account string) error {
+	return current().Delete(account)
+}
+
+// Open selects the backend following config.EnvSecretStore: by default the keyring if it answers,
+// and otherwise a file encrypted in dir; the environment is only used when selected. The secrets
+// are moved to the selected backend: those of the file once a keyring is available, and those
+// of accounts when the file is forced.
+// It returns the name of the selected backend.
+func Open(dir string, accounts []string) (string, error) {
+	mode := os.Getenv(config.EnvSecretStore)
+	ring := keyringStore{}
+
+	var file *fileStore
+	if dir != "" {
+		file = newFileStore(dir, os.Getenv(config.EnvSecretPassphrase))
+	}
+
+	switch {
+	case mode == config.SecretStoreEnv:
+		use(newEnvStore())
+		return config.SecretStoreEnv, nil
+	case mode == config.SecretStoreKeyring || file == nil:
+		use(ring)
+		return config.SecretStoreKeyring, nil
+	case mode == config.SecretStoreFile:
+		use(file)
+		if keyringAvailable() {
+			return config.SecretStoreFile, moveAccounts(ring, file, accounts)
+		}
+		return config.SecretStoreFile, nil
+	case keyringAvailable():
+		use(ring)
+		return config.SecretStoreKeyring, moveFile(file, ring)
+	default:
+		use(file)
+		return config.SecretStoreFile, nil
+	}
+}
+
+func use(s Store) {
+	mu.Lock()
+	defer mu.Unlock()
+	active = s
+}
+
+// keyringAvailable reports whether the OS keyring answers, even without any secret.
+func keyringAvailable() bool {
+	_, err := keyring.Get(config.KeyringService, config.KeyringProbe)
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
+}
+
+// moveAccounts moves the secrets of accounts from one store to another.
+func moveAccounts(from, to Store, accounts []string) error {
+	var errs []error
+	for _, account := range accounts {
+		secret, err := from.Get(account)
+		if err != nil {
+			continue // Nothing to move
+		}
+		if err := to.Set(account, secret); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		_ = from.Delete(account)
+		logMoved(account)
+	}
+	return errors.Join(errs...)
+}
+
+// moveFile moves every secret of the file to another store, and removes the file once it is empty.
+func moveFile(file *fileStore, to Store) error {
+	all, err := file.load()
+	if err != nil || len(all) == 0 {
+		return err
+	}
+	var errs []error
+	for account, secret := range all {
+		if err := to.Set(account, secret); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(all, account)
+		logMoved(account)
+	}
+	if len(all) > 0 {
+		return errors.Join(append(errs, file.save(all))...)
+	}
+	return file.remove()
+}
+
+func logMoved(account string) {
+	slog.Info(config.MsgSecretMoved,
+		config.LogKeyComponent, config.CompSecrets,
+		config.LogKeyKey, account)
+}
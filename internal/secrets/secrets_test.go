@@ -0,0 +1,153 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/zalando/go-keyring"
+)
+
+// reset restores the default backend after a test calling Open.
+func reset(t *testing.T) {
+	t.Cleanup(func() { use(keyringStore{}) })
+}
+
+// TestFileStore verifies the encrypted file: round trip, encryption at rest and wrong passphrases.
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	s := newFileStore(dir, "passphrase")
+
+	_, err := s.Get("user")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, s.Set("user", "p@ssw0rd"))
+	require.NoError(t, s.Set("push_token", "tok"))
+	got, err := s.Get("user")
+	require.NoError(t, err)
+	assert.Equal(t, "p@ssw0rd", got)
+
+	path := filepath.Join(dir, config.SecretsFile)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "p@ssw0rd", "Secrets are encrypted at rest")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, config.FilePermUserRW, info.Mode().Perm())
+
+	// Another process with the same passphrase reads the same secrets.
+	got, err = newFileStore(dir, "passphrase").Get("push_token")
+	require.NoError(t, err)
+	assert.Equal(t, "tok", got)
+
+	_, err = newFileStore(dir, "wrong").Get("user")
+	assert.ErrorContains(t, err, config.ErrSecretsDecrypt)
+
+	require.NoError(t, s.Delete("user"))
+	_, err = s.Get("user")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, s.Delete("user"), ErrNotFound)
+}
+
+// TestOpen_Fallback verifies that the file is used when the keyring does not answer.
+func TestOpen_Fallback(t *testing.T) {
+	reset(t)
+	t.Setenv(config.EnvSecretPassphrase, "passphrase")
+	keyring.MockInitWithError(errors.New("no Secret Service"))
+	dir := t.TempDir()
+
+	backend, err := Open(dir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, config.SecretStoreFile, backend)
+
+	require.NoError(t, Set("user", "secret"))
+	got, err := Get("user")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", got)
+	assert.FileExists(t, filepath.Join(dir, config.SecretsFile))
+}
+
+// TestOpen_ToKeyring verifies that the secrets of the file move to the keyring once it is available.
+func TestOpen_ToKeyring(t *testing.T) {
+	reset(t)
+	t.Setenv(config.EnvSecretPassphrase, "passphrase")
+	dir := t.TempDir()
+	require.NoError(t, newFileStore(dir, "passphrase").Set("user", "secret"))
+
+	keyring.MockInit()
+	backend, err := Open(dir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, config.SecretStoreKeyring, backend)
+
+	got, err := keyring.Get(config.KeyringService, "user")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", got)
+	assert.NoFileExists(t, filepath.Join(dir, config.SecretsFile))
+}
+
+// TestOpen_ForcedFile verifies that forcing the file moves the listed accounts out of the keyring.
+func TestOpen_ForcedFile(t *testing.T) {
+	reset(t)
+	t.Setenv(config.EnvSecretPassphrase, "passphrase")
+	t.Setenv(config.EnvSecretStore, config.SecretStoreFile)
+	keyring.MockInit()
+	require.NoError(t, keyring.Set(config.KeyringService, "user", "secret"))
+
+	backend, err := Open(t.TempDir(), []string{"user", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, config.SecretStoreFile, backend)
+
+	got, err := Get("user")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", got)
+	_, err = keyring.Get(config.KeyringService, "user")
+	assert.ErrorIs(t, err, keyring.ErrNotFound, "Moved secrets are removed from the keyring")
+}
+
+// TestOpen_Env verifies the environment storage: variables, Docker secret files and runtime updates.
+func TestOpen_Env(t *testing.T) {
+	reset(t)
+	t.Setenv(config.EnvSecretStore, config.SecretStoreEnv)
+	t.Setenv("GO_BIRTHDAY_SECRET_JANE_EXAMPLE_COM", "p@ssw0rd")
+	file := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(file, []byte("tok\n"), config.FilePermUserRW))
+	t.Setenv("GO_BIRTHDAY_SECRET_PUSH_TOKEN_FILE", file)
+
+	backend, err := Open(t.TempDir(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, config.SecretStoreEnv, backend)
+
+	got, err := Get("jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "p@ssw0rd", got)
+	got, err = Get(config.KeyringPushToken)
+	require.NoError(t, err)
+	assert.Equal(t, "tok", got, "The trailing newline of a secret file is dropped")
+	_, err = Get(config.KeyringProxyPass)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, Set(config.KeyringOAuth2Token, "rotated"))
+	got, err = Get(config.KeyringOAuth2Token)
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", got, "Saved secrets are kept in memory")
+	require.NoError(t, Delete("jane@example.com"))
+	_, err = Get("jane@example.com")
+	assert.ErrorIs(t, err, ErrNotFound, "Deleted secrets hide the environment")
+}
+
+// TestSeal verifies the round trip of exported secrets and the rejection of a wrong passphrase.
+func TestSeal(t *testing.T) {
+	sealed, err := Seal("passphrase", map[string]string{"user": "p@ssw0rd"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "p@ssw0rd")
+
+	all, err := Unseal("passphrase", sealed)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"user": "p@ssw0rd"}, all)
+	_, err = Unseal("wrong", sealed)
+	assert.ErrorContains(t, err, config.ErrSecretsDecrypt)
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// accessRecorder remembers the status code and the size of a response.
+type accessRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *accessRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *accessRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer.
+func (r *accessRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// SetAccessLog turns the logging of every request on or off, to see which devices read the calendar.
+func (s *CalendarServer) SetAccessLog(enabled bool) {
+	s.accessLog.Store(enabled)
+}
+
+// withAccessLog logs each request handled by h under config.CompHTTPAccess, while enabled.
+func (s *CalendarServer) withAccessLog(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.accessLog.Load() {
+			h(w, r)
+			return
+		}
+
+		start := time.Now()
+		rec := &accessRecorder{ResponseWriter: w, status: http.StatusOK}
+		h(rec, r)
+
+		slog.Info(config.MsgHTTPAccess,
+			config.LogKeyComponent, config.CompHTTPAccess,
+			config.LogKeyMethod, r.Method,
+			config.LogKeyPath, r.URL.Path,
+			config.LogKeyStatus, rec.status,
+			config.LogKeyBytes, rec.bytes,
+			config.LogKeyDuration, time.Since(start).Milliseconds(),
+			config.LogKeyRemote, r.RemoteAddr,
+			config.LogKeyCondHit, rec.status == http.StatusNotModified,
+		)
+	}
+}
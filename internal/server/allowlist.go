@@ -0,0 +1,70 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// ParseNetworks parses an allowlist of networks in CIDR notation (e.g., "192.168.1.0/24")
+// or single addresses (e.g., "192.168.1.10", "fd00::1").
+func ParseNetworks(list []string) ([]netip.Prefix, error) {
+	networks := make([]netip.Prefix, 0, len(list))
+	for _, s := range list {
+		if addr, err := netip.ParseAddr(s); err == nil {
+			networks = append(networks, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", config.ErrNetwork, err)
+		}
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		networks = append(networks, prefix.Masked())
+	}
+	return networks, nil
+}
+
+// UpdateAllowlist restricts the clients of the server to the given networks, for a server
+// reachable beyond the loopback (e.g., the home LAN but not its guest network).
+// The loopback is always allowed; nil or empty allows every client.
+func (s *CalendarServer) UpdateAllowlist(networks []netip.Prefix) {
+	s.allowlist.Store(&networks)
+}
+
+// allowed reports whether the client at remoteAddr ("host:port") may use the server.
+func (s *CalendarServer) allowed(remoteAddr string) bool {
+	networks := s.allowlist.Load()
+	if networks == nil || len(*networks) == 0 {
+		return true
+	}
+	ap, err := netip.ParseAddrPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+	addr := ap.Addr().Unmap()
+	if addr.IsLoopback() {
+		return true
+	}
+	for _, n := range *networks {
+		if n.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// withAllowlist answers 403 Forbidden to the clients outside of the allowlist instead of calling h.
+func (s *CalendarServer) withAllowlist(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowed(r.RemoteAddr) {
+			http.Error(w, config.HTTPMsgForbidden, http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
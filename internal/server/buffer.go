@@ -0,0 +1,102 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"os"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// Buffer holds a calendar for the server: in memory up to config.CalendarMemoryLimit,
+// then in a temporary file, so that the calendar of a huge address book does not stay in RAM.
+// The content is hashed as it is written, for the ETag.
+// A Buffer is written once, by a single goroutine, before it is handed over to the server.
+type Buffer struct {
+	mem  []byte
+	file *os.File // Set once the content outgrows the memory limit
+	size int64
+	hash hash.Hash
+}
+
+// NewBuffer returns an empty Buffer.
+func NewBuffer() *Buffer {
+	return &Buffer{hash: sha256.New()}
+}
+
+// newBytesBuffer wraps data in a Buffer that stays in memory whatever its size.
+func newBytesBuffer(data []byte) *Buffer {
+	b := NewBuffer()
+	b.mem = data
+	b.size = int64(len(data))
+	b.hash.Write(data)
+	return b
+}
+
+// Write appends p, moving the content to a temporary file once it outgrows the memory limit.
+func (b *Buffer) Write(p []byte) (int, error) {
+	if b.file == nil && int64(len(b.mem)+len(p)) > config.CalendarMemoryLimit {
+		if err := b.spill(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := len(p)
+	var err error
+	if b.file != nil {
+		n, err = b.file.Write(p)
+	} else {
+		b.mem = append(b.mem, p...)
+	}
+	b.hash.Write(p[:n])
+	b.size += int64(n)
+	return n, err
+}
+
+// spill moves the content written so far to a temporary file.
+func (b *Buffer) spill() error {
+	f, err := os.CreateTemp("", config.CalendarTempPattern)
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.ErrCalendarSpool, err)
+	}
+	if _, err := f.Write(b.mem); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("%s: %w", config.ErrCalendarSpool, err)
+	}
+	b.file, b.mem = f, nil
+	return nil
+}
+
+// ReadAt implements io.ReaderAt. It is safe for concurrent use once writing is over.
+func (b *Buffer) ReadAt(p []byte, off int64) (int, error) {
+	if b.file != nil {
+		return b.file.ReadAt(p, off)
+	}
+	return bytes.NewReader(b.mem).ReadAt(p, off)
+}
+
+// Size returns the number of bytes written.
+func (b *Buffer) Size() int64 {
+	return b.size
+}
+
+// Close releases the content, removing the temporary file if any.
+func (b *Buffer) Close() error {
+	b.mem = nil
+	if b.file == nil {
+		return nil
+	}
+	err := b.file.Close()
+	if rmErr := os.Remove(b.file.Name()); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// sum returns the SHA-256 of the content.
+func (b *Buffer) sum() []byte {
+	return b.hash.Sum(nil)
+}
@@ -0,0 +1,450 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+)
+
+// davEvent is an event of the calendar, served as a resource of the CalDAV collection.
+type davEvent struct {
+	href       string // Escaped path of the resource
+	data       []byte // VCALENDAR holding the event alone
+	etag       string
+	start, end time.Time // Floating times read in UTC, for the time-range filters
+}
+
+// davCollection lists the events of a calendar by the name of their resource ("{uid}.ics").
+type davCollection struct {
+	names  []string // Sorted, for stable listings
+	events map[string]davEvent
+}
+
+// splitCalendar builds the CalDAV collection of the calendar of the item, the first time it is asked for.
+// The caller holds the item (acquire).
+func (it *cacheItem) splitCalendar() (*davCollection, error) {
+	it.davOnce.Do(func() {
+		it.dav, it.davErr = newDAVCollection(io.NewSectionReader(it.content, 0, it.size))
+	})
+	return it.dav, it.davErr
+}
+
+// newDAVCollection splits calendar into one resource per event, as calendar collections require (RFC 4791, section 4.1).
+func newDAVCollection(calendar io.Reader) (*davCollection, error) {
+	cal, err := ical.NewDecoder(calendar).Decode()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ErrCalDAVEncode, err)
+	}
+
+	c := &davCollection{events: make(map[string]davEvent)}
+	for _, child := range cal.Children {
+		if child.Name != ical.CompEvent {
+			continue
+		}
+		uid, err := child.Props.Text(config.PropUID)
+		if err != nil || uid == "" {
+			continue
+		}
+
+		// Calendar collections do not accept METHOD either.
+		single := ical.NewCalendar()
+		for name, props := range cal.Props {
+			if name != config.PropMethod {
+				single.Props[name] = props
+			}
+		}
+		single.Children = []*ical.Component{child}
+		var buf bytes.Buffer
+		if err := ical.NewEncoder(&buf).Encode(single); err != nil {
+			return nil, fmt.Errorf("%s: %w", config.ErrCalDAVEncode, err)
+		}
+
+		// DTSTAMP changes with every synchronization: it is left out of the ETag, so that
+		// clients only download the events that changed.
+		stamp := child.Props[config.PropDTStamp]
+		child.Props.SetDateTime(config.PropDTStamp, time.Time{})
+		var unstamped bytes.Buffer
+		if err := ical.NewEncoder(&unstamped).Encode(single); err != nil {
+			return nil, fmt.Errorf("%s: %w", config.ErrCalDAVEncode, err)
+		}
+		if stamp != nil {
+			child.Props[config.PropDTStamp] = stamp
+		} else {
+			delete(child.Props, config.PropDTStamp)
+		}
+		sum := sha256.Sum256(unstamped.Bytes())
+
+		event := ical.Event{Component: child}
+		start, _ := event.DateTimeStart(time.UTC)
+		end, _ := event.DateTimeEnd(time.UTC)
+		name := uid + config.ExtICS
+		c.names = append(c.names, name)
+		c.events[name] = davEvent{
+			href:  config.RouteCalDAVCalendar + url.PathEscape(uid) + config.ExtICS,
+			data:  engine.FoldLines(buf.Bytes()),
+			etag:  fmt.Sprintf(config.FormatETag, hex.EncodeToString(sum[:])),
+			start: start,
+			end:   end,
+		}
+	}
+	slices.Sort(c.names)
+	return c, nil
+}
+
+// davMultistatus is the answer to PROPFIND and REPORT (RFC 4918, section 13).
+// The prefixes are declared on the root, which keeps the answer readable by picky clients.
+type davMultistatus struct {
+	XMLName     xml.Name      `xml:"d:multistatus"`
+	NSDAV       string        `xml:"xmlns:d,attr"`
+	NSCalDAV    string        `xml:"xmlns:c,attr"`
+	NSCalServer string        `xml:"xmlns:cs,attr"`
+	Responses   []davResponse `xml:"d:response"`
+}
+
+type davResponse struct {
+	Href   string  `xml:"d:href"`
+	Prop   davProp `xml:"d:propstat>d:prop"`
+	Status string  `xml:"d:propstat>d:status"`
+}
+
+// davProp holds the properties known to the server; the empty ones are left out.
+type davProp struct {
+	ResourceType  *davResourceType `xml:"d:resourcetype,omitempty"`
+	DisplayName   string           `xml:"d:displayname,omitempty"`
+	Principal     *davHref         `xml:"d:current-user-principal,omitempty"`
+	CalendarHome  *davHref         `xml:"c:calendar-home-set,omitempty"`
+	Components    *davComponents   `xml:"c:supported-calendar-component-set,omitempty"`
+	CTag          string           `xml:"cs:getctag,omitempty"`
+	ETag          string           `xml:"d:getetag,omitempty"`
+	ContentType   string           `xml:"d:getcontenttype,omitempty"`
+	ContentLength int              `xml:"d:getcontentlength,omitempty"`
+	CalendarData  string           `xml:"c:calendar-data,omitempty"`
+}
+
+type davResourceType struct {
+	Collection *struct{} `xml:"d:collection,omitempty"`
+	Calendar   *struct{} `xml:"c:calendar,omitempty"`
+}
+
+type davHref struct {
+	Href string `xml:"d:href"`
+}
+
+type davComponents struct {
+	Comp struct {
+		Name string `xml:"name,attr"`
+	} `xml:"c:comp"`
+}
+
+// davReport is the body of a REPORT: its kind, the resources asked for by calendar-multiget,
+// and the filter of calendar-query.
+type davReport struct {
+	XMLName xml.Name
+	Hrefs   []string       `xml:"DAV: href"`
+	Filter  *davCompFilter `xml:"urn:ietf:params:xml:ns:caldav filter>comp-filter"`
+}
+
+// davCompFilter selects the components of a calendar-query (RFC 4791, section 9.7.1).
+// Only the component names and the time ranges are supported; the property filters are ignored.
+type davCompFilter struct {
+	Name      string          `xml:"name,attr"`
+	TimeRange *davTimeRange   `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	Comps     []davCompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// davTimeRange bounds the events of a calendar-query; a missing bound is open.
+type davTimeRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+// matcher returns whether an event matches the filter of a calendar-query, every event without one.
+func (f *davCompFilter) matcher() (func(e davEvent) bool, error) {
+	all := func(davEvent) bool { return true }
+	if f == nil {
+		return all, nil
+	}
+	if f.Name != ical.CompCalendar {
+		return func(davEvent) bool { return false }, nil
+	}
+	if len(f.Comps) == 0 {
+		return all, nil
+	}
+	var events *davCompFilter
+	for i := range f.Comps {
+		if f.Comps[i].Name == ical.CompEvent {
+			events = &f.Comps[i]
+		}
+	}
+	if events == nil {
+		return func(davEvent) bool { return false }, nil // Only events are served
+	}
+	if events.TimeRange == nil {
+		return all, nil
+	}
+
+	var start, end time.Time
+	var err error
+	if s := events.TimeRange.Start; s != "" {
+		if start, err = time.Parse(config.DAVTimeRangeFormat, s); err != nil {
+			return nil, err
+		}
+	}
+	if s := events.TimeRange.End; s != "" {
+		if end, err = time.Parse(config.DAVTimeRangeFormat, s); err != nil {
+			return nil, err
+		}
+	}
+	// An event overlaps the range if it starts before its end and ends after its start;
+	// an event without duration if it starts within it (RFC 4791, section 9.9).
+	return func(e davEvent) bool {
+		if !end.IsZero() && !e.start.Before(end) {
+			return false
+		}
+		if start.IsZero() {
+			return true
+		}
+		if e.end.Equal(e.start) {
+			return !e.start.Before(start)
+		}
+		return e.end.After(start)
+	}, nil
+}
+
+// handleCalDAV serves the calendar as a read-only CalDAV collection under RouteCalDAV, for clients
+// syncing accounts rather than subscriptions (e.g., DAVx⁵, iOS). The principal and the calendar home
+// are RouteCalDAV itself; the calendar is RouteCalDAVCalendar, with one "{uid}.ics" per event.
+func (s *CalendarServer) handleCalDAV(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(config.HeaderDAV, config.DAVCompliance)
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set(config.HeaderAllow, config.AllowedMethodsDAV)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case http.MethodGet, http.MethodHead, config.MethodPropfind, config.MethodReport:
+	default:
+		// Read-only: PUT, DELETE, MKCALENDAR, PROPPATCH...
+		w.Header().Set(config.HeaderAllow, config.AllowedMethodsDAV)
+		http.Error(w, config.HTTPMsgMethodNotAll, http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch p := r.URL.Path; {
+	case p == config.RouteCalDAV && r.Method == config.MethodPropfind:
+		s.propfindHome(w, r)
+	case p == config.RouteCalDAVCalendar && r.Method == config.MethodPropfind:
+		s.withCollection(w, func(item *cacheItem, c *davCollection) { s.propfindCalendar(w, r, item, c) })
+	case p == config.RouteCalDAVCalendar && r.Method == config.MethodReport:
+		s.withCollection(w, func(_ *cacheItem, c *davCollection) { reportCalendar(w, r, c) })
+	case strings.HasPrefix(p, config.RouteCalDAVCalendar) && !strings.Contains(p[len(config.RouteCalDAVCalendar):], "/"):
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set(config.HeaderAllow, config.AllowedMethods)
+			http.Error(w, config.HTTPMsgMethodNotAll, http.StatusMethodNotAllowed)
+			return
+		}
+		s.withCollection(w, func(item *cacheItem, c *davCollection) {
+			e, ok := c.events[p[len(config.RouteCalDAVCalendar):]]
+			if !ok {
+				http.Error(w, config.HTTPMsgNotFound, http.StatusNotFound)
+				return
+			}
+			w.Header().Set(config.HeaderContentType, config.MimeTextCalendar)
+			w.Header().Set(config.HeaderXContentType, config.MimeNoSniff)
+			w.Header().Set(config.HeaderCacheControl, config.CacheControlPrivate)
+			w.Header().Set(config.HeaderETag, e.etag)
+			http.ServeContent(w, r, path.Base(p), item.modified, bytes.NewReader(e.data))
+		})
+	case p == config.RouteCalDAV || p == config.RouteCalDAVCalendar:
+		w.Header().Set(config.HeaderAllow, config.AllowedMethodsDAV)
+		http.Error(w, config.HTTPMsgMethodNotAll, http.StatusMethodNotAllowed)
+	default:
+		http.Error(w, config.HTTPMsgNotFound, http.StatusNotFound)
+	}
+}
+
+// handleWellKnownCalDAV points the clients given the address of the server alone to RouteCalDAV (RFC 6764).
+func (s *CalendarServer) handleWellKnownCalDAV(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, config.RouteCalDAV, http.StatusMovedPermanently)
+}
+
+// withCollection calls f with the current calendar and its events, or answers 503 before the first synchronization.
+func (s *CalendarServer) withCollection(w http.ResponseWriter, f func(item *cacheItem, c *davCollection)) {
+	item := s.acquire()
+	if item == nil {
+		w.Header().Set(config.HeaderRetryAfter, config.RetryAfterSeconds)
+		http.Error(w, config.HTTPMsgInitializing, http.StatusServiceUnavailable)
+		return
+	}
+	defer item.release()
+
+	c, err := item.splitCalendar()
+	if err != nil {
+		slog.Error(config.ErrCalDAVEncode,
+			config.LogKeyComponent, config.CompServer,
+			config.LogKeyError, err,
+		)
+		http.Error(w, config.HTTPMsgInternalErr, http.StatusInternalServerError)
+		return
+	}
+	f(item, c)
+}
+
+// propfindHome describes the principal, which is its own calendar home, and lists the calendar below Depth 0.
+func (s *CalendarServer) propfindHome(w http.ResponseWriter, r *http.Request) {
+	home := davResponse{
+		Href: config.RouteCalDAV,
+		Prop: davProp{
+			ResourceType: &davResourceType{Collection: &struct{}{}},
+			Principal:    &davHref{Href: config.RouteCalDAV},
+			CalendarHome: &davHref{Href: config.RouteCalDAV},
+		},
+		Status: config.DAVStatusOK,
+	}
+	responses := []davResponse{home}
+	if r.Header.Get(config.HeaderDepth) != config.DAVDepthZero {
+		var ctag string
+		if item := s.cache.Load(); item != nil {
+			ctag = item.etag
+		}
+		responses = append(responses, s.calendarResponse(ctag))
+	}
+	writeMultistatus(w, responses)
+}
+
+// propfindCalendar describes the calendar, and lists its events below Depth 0.
+func (s *CalendarServer) propfindCalendar(w http.ResponseWriter, r *http.Request, item *cacheItem, c *davCollection) {
+	responses := []davResponse{s.calendarResponse(item.etag)}
+	if r.Header.Get(config.HeaderDepth) != config.DAVDepthZero {
+		for _, name := range c.names {
+			responses = append(responses, eventResponse(c.events[name], false))
+		}
+	}
+	writeMultistatus(w, responses)
+}
+
+// calendarResponse describes the calendar collection; ctag changes with the calendar.
+func (s *CalendarServer) calendarResponse(ctag string) davResponse {
+	name := config.ICalCalName
+	if l := s.landing.Load(); l != nil && l.Name != "" {
+		name = l.Name
+	}
+	comps := &davComponents{}
+	comps.Comp.Name = ical.CompEvent
+	return davResponse{
+		Href: config.RouteCalDAVCalendar,
+		Prop: davProp{
+			ResourceType: &davResourceType{Collection: &struct{}{}, Calendar: &struct{}{}},
+			DisplayName:  name,
+			Principal:    &davHref{Href: config.RouteCalDAV},
+			Components:   comps,
+			CTag:         ctag,
+		},
+		Status: config.DAVStatusOK,
+	}
+}
+
+// eventResponse describes an event, with its content for a REPORT.
+func eventResponse(e davEvent, withData bool) davResponse {
+	resp := davResponse{
+		Href: e.href,
+		Prop: davProp{
+			ETag:          e.etag,
+			ContentType:   config.MimeTextCalendar,
+			ContentLength: len(e.data),
+		},
+		Status: config.DAVStatusOK,
+	}
+	if withData {
+		resp.Prop.CalendarData = string(e.data)
+	}
+	return resp
+}
+
+// reportCalendar answers calendar-query with the events matching its filter (each year of a
+// birthday is an event of its own), and calendar-multiget with the events asked for.
+func reportCalendar(w http.ResponseWriter, r *http.Request, c *davCollection) {
+	var report davReport
+	if err := xml.NewDecoder(http.MaxBytesReader(w, r.Body, config.CalDAVMaxReport)).Decode(&report); err != nil {
+		http.Error(w, config.HTTPMsgBadRequest, http.StatusBadRequest)
+		return
+	}
+	if report.XMLName.Space != config.NSCalDAV {
+		http.Error(w, config.HTTPMsgNotImpl, http.StatusNotImplemented)
+		return
+	}
+
+	var responses []davResponse
+	switch report.XMLName.Local {
+	case config.DAVCalendarQuery:
+		match, err := report.Filter.matcher()
+		if err != nil {
+			http.Error(w, config.HTTPMsgBadRequest, http.StatusBadRequest)
+			return
+		}
+		for _, name := range c.names {
+			if e := c.events[name]; match(e) {
+				responses = append(responses, eventResponse(e, true))
+			}
+		}
+	case config.DAVCalendarMultiget:
+		for _, href := range report.Hrefs {
+			u, err := url.Parse(strings.TrimSpace(href))
+			if err != nil {
+				continue
+			}
+			if e, ok := c.events[path.Base(u.Path)]; ok && path.Dir(u.Path)+"/" == config.RouteCalDAVCalendar {
+				responses = append(responses, eventResponse(e, true))
+			}
+		}
+	default:
+		http.Error(w, config.HTTPMsgNotImpl, http.StatusNotImplemented)
+		return
+	}
+	writeMultistatus(w, responses)
+}
+
+// writeMultistatus sends the responses as a 207 Multi-Status.
+func writeMultistatus(w http.ResponseWriter, responses []davResponse) {
+	body, err := xml.Marshal(davMultistatus{
+		NSDAV:       config.NSDAV,
+		NSCalDAV:    config.NSCalDAV,
+		NSCalServer: config.NSCalendarServer,
+		Responses:   responses,
+	})
+	if err != nil {
+		slog.Error(config.ErrWriteResp,
+			config.LogKeyComponent, config.CompServer,
+			config.LogKeyError, err,
+		)
+		http.Error(w, config.HTTPMsgInternalErr, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(config.HeaderContentType, config.MimeXML)
+	w.Header().Set(config.HeaderCacheControl, config.CacheControlPrivate)
+	w.WriteHeader(http.StatusMultiStatus)
+	if _, err = io.WriteString(w, xml.Header); err == nil {
+		_, err = w.Write(body)
+	}
+	if err != nil {
+		slog.Error(config.ErrWriteResp,
+			config.LogKeyComponent, config.CompServer,
+			config.LogKeyError, err,
+		)
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// UpdateCORS sets the origin whose scripts may read the responses (e.g., "https://dash.example.org",
+// or "*" for any), so that web apps can fetch the endpoints from a browser; empty disables it.
+func (s *CalendarServer) UpdateCORS(origin string) {
+	s.cors.Store(&origin)
+}
+
+// withCORS adds the CORS headers of the allowed origin to the responses of h, and answers the
+// preflight requests itself. Other OPTIONS requests (e.g., CalDAV discovery) still reach h.
+// Without an allowed origin, h is called unchanged.
+func (s *CalendarServer) withCORS(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		origin := s.cors.Load()
+		if origin == nil || *origin == "" {
+			h(w, r)
+			return
+		}
+
+		w.Header().Set(config.HeaderACAllowOrigin, *origin)
+		if *origin != config.CORSAnyOrigin {
+			w.Header().Add(config.HeaderVary, config.HeaderOrigin)
+		}
+		if !isPreflight(r) {
+			// Scripts only see the safelisted headers unless told otherwise.
+			w.Header().Set(config.HeaderACExposeHeaders, config.CORSExposedHeaders)
+			h(w, r)
+			return
+		}
+
+		methods, headers := config.AllowedMethods, config.CORSAllowedHeaders
+		if strings.HasPrefix(r.URL.Path, config.RouteCalDAV) {
+			methods, headers = config.AllowedMethodsDAV, config.CORSAllowedHeadersDAV
+		}
+		w.Header().Set(config.HeaderAllow, methods)
+		w.Header().Set(config.HeaderACAllowMethods, methods)
+		w.Header().Set(config.HeaderACAllowHeaders, headers)
+		w.Header().Set(config.HeaderACMaxAge, config.CORSMaxAge)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// isPreflight reports whether r is a CORS preflight request, rather than a plain OPTIONS request.
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get(config.HeaderOrigin) != "" &&
+		r.Header.Get(config.HeaderACRequestMethod) != ""
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// Birthday is a contact listed by the Home Assistant and to-do endpoints.
+type Birthday struct {
+	UID       string // Contact UID, for the to-do UIDs
+	Name      string
+	Date      time.Time // Date of birth; the year is only meaningful if YearKnown
+	YearKnown bool
+}
+
+// haPayload is served under RouteHomeAssistant, shaped for a Home Assistant REST sensor:
+// the state reads "today", the other fields are attributes.
+type haPayload struct {
+	Today    int          `json:"today"`
+	Next     *haBirthday  `json:"next"` // Null without contacts
+	Upcoming []haBirthday `json:"upcoming"`
+}
+
+type haBirthday struct {
+	Name string `json:"name"`
+	Date string `json:"date"` // Next occurrence, YYYY-MM-DD
+	Days int    `json:"days"` // 0 for today
+	Age  *int   `json:"age,omitempty"`
+}
+
+// UpdateBirthdays replaces the contacts listed by the Home Assistant endpoint.
+func (s *CalendarServer) UpdateBirthdays(birthdays []Birthday) {
+	s.birthdays.Store(&birthdays)
+}
+
+// nextBirthday returns the next occurrence of a birthday on or after today (midnight), and the days left.
+func nextBirthday(b Birthday, today time.Time) (time.Time, int) {
+	// time.Date moves February 29 to March 1 outside leap years, as the calendar does.
+	next := time.Date(today.Year(), b.Date.Month(), b.Date.Day(), 0, 0, 0, 0, today.Location())
+	if next.Before(today) {
+		next = time.Date(today.Year()+1, b.Date.Month(), b.Date.Day(), 0, 0, 0, 0, today.Location())
+	}
+	return next, int(next.Sub(today).Hours()/24 + 0.5) // Rounded across daylight saving changes
+}
+
+// homeAssistant builds the payload on now: birthdays move closer every day, between synchronizations too.
+func homeAssistant(birthdays []Birthday, now time.Time) haPayload {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	list := make([]haBirthday, 0, len(birthdays))
+	for _, b := range birthdays {
+		next, days := nextBirthday(b, today)
+		hb := haBirthday{
+			Name: b.Name,
+			Date: next.Format(config.DateFormatFullDash),
+			Days: days,
+		}
+		if b.YearKnown {
+			age := next.Year() - b.Date.Year()
+			hb.Age = &age
+		}
+		list = append(list, hb)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Days == list[j].Days {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].Days < list[j].Days
+	})
+
+	p := haPayload{Upcoming: []haBirthday{}}
+	if len(list) > 0 {
+		p.Next = &list[0]
+	}
+	for _, hb := range list {
+		if hb.Days > config.HomeAssistantDays {
+			break
+		}
+		if hb.Days == 0 {
+			p.Today++
+		}
+		p.Upcoming = append(p.Upcoming, hb)
+	}
+	return p
+}
+
+// handleHomeAssistant serves the upcoming birthdays as JSON.
+func (s *CalendarServer) handleHomeAssistant(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set(config.HeaderAllow, config.AllowedMethods)
+		http.Error(w, config.HTTPMsgMethodNotAll, http.StatusMethodNotAllowed)
+		return
+	}
+
+	birthdays := s.birthdays.Load()
+	if birthdays == nil {
+		w.Header().Set(config.HeaderRetryAfter, config.RetryAfterSeconds)
+		http.Error(w, config.HTTPMsgInitializing, http.StatusServiceUnavailable)
+		return
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+
+	w.Header().Set(config.HeaderContentType, config.MimeJSON)
+	w.Header().Set(config.HeaderXContentType, config.MimeNoSniff)
+	w.Header().Set(config.HeaderCacheControl, config.CacheControlPrivate)
+	if r.Method == http.MethodGet {
+		if err := json.NewEncoder(w).Encode(homeAssistant(*birthdays, now())); err != nil {
+			slog.Error(config.ErrWriteResp,
+				config.LogKeyComponent, config.CompServer,
+				config.LogKeyError, err,
+			)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"bytes"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// Landing is the localized text of the page shown to browsers under RouteRoot.
+type Landing struct {
+	Lang       string // Language of the page (e.g., "fr")
+	Name       string // Calendar name
+	Updated    string // Label of the time of the calendar
+	Contacts   string // Label of the number of contacts
+	Subscribe  string // Explains the subscription links
+	Copy       string // Button copying a link
+	TimeLayout string // Go layout of the time of the calendar
+}
+
+// landingPage is the data of config.LandingHTML.
+type landingPage struct {
+	Landing
+	Time     string // Empty before the first synchronization
+	Count    int    // -1 before the first synchronization
+	Webcal   string
+	HTTPLink string
+}
+
+var landingTemplate = template.Must(template.New(config.RouteRoot).Parse(config.LandingHTML))
+
+// UpdateLanding sets the text of the page shown to browsers at the root; until then, they get the calendar.
+func (s *CalendarServer) UpdateLanding(l Landing) {
+	s.landing.Store(&l)
+}
+
+// wantsLanding reports whether a browser asks for the root: calendar clients subscribed to it
+// before the page existed do not accept HTML, and keep getting the calendar.
+func (s *CalendarServer) wantsLanding(r *http.Request) bool {
+	return r.URL.Path == config.RouteRoot && s.landing.Load() != nil &&
+		strings.Contains(r.Header.Get(config.HeaderAccept), config.MimeHTMLType)
+}
+
+// handleRoot serves the landing page to browsers and the calendar to anyone else.
+func (s *CalendarServer) handleRoot(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == config.RouteRoot && s.landing.Load() != nil {
+		w.Header().Add(config.HeaderVary, config.HeaderAccept) // Caches keep the page and the calendar apart
+	}
+	if !s.wantsLanding(r) {
+		s.handleCalendarRequest(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set(config.HeaderAllow, config.AllowedMethods)
+		http.Error(w, config.HTTPMsgMethodNotAll, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The links use the address the browser reached, which also works from another device.
+	page := landingPage{
+		Landing:  *s.landing.Load(),
+		Count:    -1,
+		Webcal:   config.SchemeWebcal + "://" + r.Host + config.RouteCalendarFile,
+		HTTPLink: config.SchemeHTTP + "://" + r.Host + config.RouteCalendarFile,
+	}
+	if item := s.cache.Load(); item != nil {
+		page.Time = item.modified.Local().Format(page.TimeLayout)
+	}
+	if birthdays := s.birthdays.Load(); birthdays != nil {
+		page.Count = len(*birthdays)
+	}
+
+	var buf bytes.Buffer
+	if err := landingTemplate.Execute(&buf, page); err != nil {
+		slog.Error(config.ErrWriteResp,
+			config.LogKeyComponent, config.CompServer,
+			config.LogKeyError, err,
+		)
+		http.Error(w, config.HTTPMsgInternalErr, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(config.HeaderContentType, config.MimeTextHTML)
+	w.Header().Set(config.HeaderXContentType, config.MimeNoSniff)
+	w.Header().Set(config.HeaderCacheControl, config.CacheControlPrivate)
+	w.Header().Set(config.HeaderContentLength, strconv.Itoa(buf.Len()))
+	if r.Method == http.MethodGet {
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			slog.Error(config.ErrWriteResp,
+				config.LogKeyComponent, config.CompServer,
+				config.LogKeyError, err,
+			)
+		}
+	}
+}
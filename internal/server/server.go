@@ -3,23 +3,76 @@ package server
 import (
 	"bytes"
 	"context"
-	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/telemetry"
 )
 
 // cacheItem stores the rendered calendar and its metadata for HTTP caching.
 type cacheItem struct {
-	data         []byte
-	etag         string
-	lastModified string // RFC1123 format required by HTTP headers
+	content  *Buffer
+	size     int64 // Of content, sent as Content-Length for GET and HEAD alike
+	etag     string
+	modified time.Time // Sent as Last-Modified, to the second
+	stale    bool      // Loaded from the disk cache, not yet confirmed by a synchronization
+
+	// The content is closed once the item is replaced and no request reads it anymore.
+	refs      atomic.Int64
+	retired   atomic.Bool
+	closeOnce sync.Once
+
+	// The events served over CalDAV, split on the first request (splitCalendar).
+	davOnce sync.Once
+	dav     *davCollection
+	davErr  error
+}
+
+// acquire reserves the content for reading. It fails if the item was replaced in the meantime.
+func (it *cacheItem) acquire() bool {
+	it.refs.Add(1)
+	if it.retired.Load() {
+		it.release()
+		return false
+	}
+	return true
+}
+
+// release ends a read started by acquire.
+func (it *cacheItem) release() {
+	if it.refs.Add(-1) == 0 && it.retired.Load() {
+		it.close()
+	}
+}
+
+// retire closes the content as soon as the last reader is done.
+func (it *cacheItem) retire() {
+	it.retired.Store(true)
+	if it.refs.Load() == 0 {
+		it.close()
+	}
+}
+
+func (it *cacheItem) close() {
+	it.closeOnce.Do(func() {
+		if err := it.content.Close(); err != nil {
+			slog.Warn(config.ErrCalendarSpool,
+				config.LogKeyComponent, config.CompServer,
+				config.LogKeyError, err)
+		}
+	})
 }
 
 // CalendarServer handles serving the generated ICS file via HTTP.
@@ -29,84 +82,360 @@ type CalendarServer struct {
 	// (only on sync), this provides better performance than a RWMutex
 	// by eliminating contention on the hot path (HTTP GET).
 	cache atomic.Pointer[cacheItem]
-	Port  string
+
+	// photos maps contact UIDs to their picture, using the same lock-free strategy.
+	photos atomic.Pointer[map[string][]byte]
+
+	// birthdays lists the contacts for the Home Assistant and to-do endpoints, nil before the first synchronization.
+	birthdays atomic.Pointer[[]Birthday]
+
+	// todos holds the settings of the to-do endpoint, nil until UpdateTodos.
+	todos atomic.Pointer[todoSettings]
+
+	// cors holds the origin allowed by UpdateCORS, nil or empty when browsers may not read the responses.
+	cors atomic.Pointer[string]
+
+	// landing holds the text of the page shown to browsers at the root, nil until UpdateLanding.
+	landing atomic.Pointer[Landing]
+
+	// allowlist holds the networks allowed by UpdateAllowlist, nil or empty for any client.
+	allowlist atomic.Pointer[[]netip.Prefix]
+
+	// accessLog logs every request while set (SetAccessLog).
+	accessLog atomic.Bool
+
+	// mu guards the port, changed by Restart, and running and restarts, set while Start runs.
+	mu       sync.Mutex
+	port     string
+	running  bool
+	restarts chan restartRequest
+	stopped  chan struct{} // Closed when Start returns
+
+	Hosts []string         // Interfaces to listen on (e.g., "127.0.0.1", "::1"), config.LocalhostBindAddr if empty
+	Now   func() time.Time // Clock of the Home Assistant and to-do endpoints, time.Now if nil
+	Ready func()           // Called by Start once the port is listening, if set
+}
+
+// ErrRunning is returned by Start when the server is already started.
+var ErrRunning = errors.New(config.ErrServerRunning)
+
+// restartRequest asks the running server to move to another port; done receives the outcome.
+type restartRequest struct {
+	port string
+	done chan error
 }
 
 // NewCalendarServer creates a new instance of the server.
 func NewCalendarServer(port string) *CalendarServer {
 	return &CalendarServer{
-		Port: port,
+		port: port,
 	}
 }
 
-// Start initializes the HTTP server and blocks until the context is cancelled.
-func (s *CalendarServer) Start(ctx context.Context) error {
-	if s.Port == "" {
-		return fmt.Errorf(config.ErrPortRequired)
+// Port returns the port the server listens on, or will once started.
+func (s *CalendarServer) Port() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.port
+}
+
+// Addrs returns the addresses the server listens on, one per interface.
+// IPv6 literals may be given with or without brackets (e.g., "[::1]" or "::1").
+func (s *CalendarServer) Addrs() []string {
+	return s.addrs(s.Port())
+}
+
+// addrs returns the addresses of the interfaces on port.
+func (s *CalendarServer) addrs(port string) []string {
+	hosts := s.Hosts
+	if len(hosts) == 0 {
+		hosts = []string{config.LocalhostBindAddr}
+	}
+	addrs := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+		addrs = append(addrs, net.JoinHostPort(host, port))
 	}
+	return addrs
+}
 
-	mux := http.NewServeMux()
-	mux.HandleFunc(config.RouteRoot, s.handleCalendarRequest)
+// listen opens a listener on every address. Every address must be available: a server
+// reachable on only some of them would go unnoticed.
+func listen(addrs []string) ([]net.Listener, error) {
+	var listeners []net.Listener
+	for _, addr := range addrs {
+		ln, err := net.Listen(config.NetworkTCP, addr)
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return nil, fmt.Errorf("%s: %w", config.ErrServerStartup, err)
+		}
+		listeners = append(listeners, ln)
+	}
+	return listeners, nil
+}
 
+// serve serves handler on the listeners with one http.Server, which shuts them down together.
+// The returned channel receives the errors of the listeners.
+func (s *CalendarServer) serve(handler http.Handler, port string, listeners []net.Listener) (*http.Server, <-chan error) {
 	srv := &http.Server{
-		// Use defined constant for separator
-		Addr:         config.LocalhostBindAddr + config.AddrSeparator + s.Port,
-		Handler:      mux,
+		Handler:      handler,
 		ReadTimeout:  config.ServerReadTimeout,
 		WriteTimeout: config.ServerWriteTimeout,
 		IdleTimeout:  config.ServerIdleTimeout,
 	}
+	serverError := make(chan error, len(listeners))
+	for _, ln := range listeners {
+		go func() {
+			slog.Info(config.MsgServerListen,
+				config.LogKeyComponent, config.CompServer,
+				config.LogKeyPort, port,
+				config.LogKeyAddress, ln.Addr().String(),
+			)
+			if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+				serverError <- err
+			}
+		}()
+	}
+	return srv, serverError
+}
 
-	serverError := make(chan error, config.ChannelBufferSize)
+// shutdown stops srv, letting the requests in progress finish for a while.
+func shutdown(srv *http.Server) error {
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("%s: %w", config.ErrServerShutdown, err)
+	}
+	return nil
+}
 
-	go func() {
-		slog.Info(config.MsgServerListen,
-			config.LogKeyComponent, config.CompServer,
-			config.LogKeyPort, s.Port,
-		)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			serverError <- err
-		}
+// Start initializes the HTTP server and blocks until the context is cancelled.
+// Restart moves it to another port meanwhile. It returns ErrRunning if the server is already started.
+func (s *CalendarServer) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return ErrRunning
+	}
+	s.running = true
+	port := s.port
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
 	}()
 
-	select {
-	case <-ctx.Done():
-		slog.Info(config.MsgServerStop, config.LogKeyComponent, config.CompServer)
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
-		defer cancel()
+	if port == "" {
+		return fmt.Errorf(config.ErrPortRequired)
+	}
+
+	mux := http.NewServeMux()
+	handle := func(route string, h http.HandlerFunc) {
+		mux.HandleFunc(route, telemetry.Handler(route, s.withAccessLog(s.withAllowlist(s.withCORS(h)))))
+	}
+	handle(config.RouteRoot, s.handleRoot)
+	handle(config.RouteCalendarFile, s.handleCalendarRequest)
+	handle(config.RoutePhotos, s.handlePhotoRequest)
+	handle(config.RouteHomeAssistant, s.handleHomeAssistant)
+	handle(config.RouteTodos, s.handleTodos)
+	handle(config.RouteCalDAV, s.handleCalDAV)
+	handle(config.RouteWellKnownDAV, s.handleWellKnownCalDAV)
+
+	// Listening before serving reports a busy port at once, and tells when the server is ready.
+	listeners, err := listen(s.addrs(port))
+	if err != nil {
+		return err
+	}
+	if s.Ready != nil {
+		s.Ready()
+	}
+	srv, serverError := s.serve(mux, port, listeners)
 
-		if err := srv.Shutdown(shutdownCtx); err != nil {
-			return fmt.Errorf("%s: %w", config.ErrServerShutdown, err)
+	restarts := make(chan restartRequest)
+	stopped := make(chan struct{})
+	s.mu.Lock()
+	s.restarts, s.stopped = restarts, stopped
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.restarts = nil
+		s.mu.Unlock()
+		close(stopped)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info(config.MsgServerStop, config.LogKeyComponent, config.CompServer)
+			err := shutdown(srv)
+			s.Close()
+			return err
+
+		case err := <-serverError:
+			_ = srv.Close() // The other listeners too
+			return fmt.Errorf("%s: %w", config.ErrServerStartup, err)
+
+		case req := <-restarts:
+			// The new port is opened first: if it is busy, the server stays where it is.
+			newListeners, err := listen(s.addrs(req.port))
+			if err != nil {
+				req.done <- err
+				continue
+			}
+			slog.Info(config.MsgServerRestart,
+				config.LogKeyComponent, config.CompServer,
+				config.LogKeyOld, port,
+				config.LogKeyNew, req.port)
+			if err := shutdown(srv); err != nil {
+				slog.Warn(config.ErrServerShutdown, config.LogKeyComponent, config.CompServer, config.LogKeyError, err)
+			}
+			port = req.port
+			s.mu.Lock()
+			s.port = port
+			s.mu.Unlock()
+			srv, serverError = s.serve(mux, port, newListeners)
+			req.done <- nil
 		}
+	}
+}
+
+// Restart moves the server to port without interrupting it: the new port is listened on
+// before the old one is closed, and the server keeps the old one if the new one is unavailable.
+// Before Start, or after it returned (e.g., on a busy port), it only sets the port of the next Start.
+func (s *CalendarServer) Restart(port string) error {
+	if port == "" {
+		return errors.New(config.ErrPortRequired)
+	}
+	s.mu.Lock()
+	restarts, stopped := s.restarts, s.stopped
+	if restarts == nil || s.port == port {
+		s.port = port
+		s.mu.Unlock()
 		return nil
+	}
+	s.mu.Unlock()
 
-	case err := <-serverError:
-		return fmt.Errorf("%s: %w", config.ErrServerStartup, err)
+	req := restartRequest{port: port, done: make(chan error, 1)}
+	select {
+	case restarts <- req:
+		return <-req.done
+	case <-stopped:
+		s.mu.Lock()
+		s.port = port
+		s.mu.Unlock()
+		return nil
+	}
+}
+
+// Running reports whether Start is running, so that a server which could not start (or is not
+// started yet) can be started again after Restart.
+func (s *CalendarServer) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// Close releases the served content, removing the temporary file of a large calendar.
+// Start calls it on shutdown; it is needed only when the server is never started.
+func (s *CalendarServer) Close() {
+	if item := s.cache.Swap(nil); item != nil {
+		item.retire()
 	}
 }
 
 // Update atomically replaces the served content.
 func (s *CalendarServer) Update(data []byte) {
-	hash := sha256.Sum256(data)
-	// Use centralized format string for ETag consistency.
-	etag := fmt.Sprintf(config.FormatETag, hex.EncodeToString(hash[:]))
+	s.store(newBytesBuffer(data), time.Now(), false)
+}
+
+// UpdateBuffer is Update for a calendar generated into b.
+// The server takes ownership of b and closes it once it is replaced and no longer read.
+func (s *CalendarServer) UpdateBuffer(b *Buffer) {
+	s.store(b, time.Now(), false)
+}
 
-	lastMod := time.Now().UTC().Format(http.TimeFormat)
+// UpdateStale serves a calendar saved by a previous run until the next Update,
+// so that calendar clients keep their events while the first synchronization is pending or failing.
+// modified is the time the calendar was generated. The server takes ownership of b.
+func (s *CalendarServer) UpdateStale(b *Buffer, modified time.Time) {
+	s.store(b, modified, true)
+}
+
+// store atomically replaces the served content.
+func (s *CalendarServer) store(b *Buffer, modified time.Time, stale bool) {
+	// Use centralized format string for ETag consistency.
+	etag := fmt.Sprintf(config.FormatETag, hex.EncodeToString(b.sum()))
 
 	item := &cacheItem{
-		data:         data,
-		etag:         etag,
-		lastModified: lastMod,
+		content:  b,
+		size:     b.Size(),
+		etag:     etag,
+		modified: modified.UTC().Truncate(time.Second),
+		stale:    stale,
 	}
 
-	// Atomic store ensures that any concurrent reader sees either the old or the new complete item,
-	// never a partial state.
-	s.cache.Store(item)
+	// Atomic swap ensures that any concurrent reader sees either the old or the new complete item,
+	// never a partial state. The old content is closed once its last reader is done.
+	if old := s.cache.Swap(item); old != nil {
+		old.retire()
+	}
 
 	slog.Debug(config.MsgCacheUpdated,
 		config.LogKeyComponent, config.CompServer,
-		config.LogKeySizeBytes, len(data),
+		config.LogKeySizeBytes, b.Size(),
 		config.LogKeyETag, etag,
+		config.LogKeyStale, stale,
+	)
+}
+
+// acquire returns the current item reserved for reading, or nil before the first synchronization.
+// Callers must release it.
+func (s *CalendarServer) acquire() *cacheItem {
+	for {
+		item := s.cache.Load()
+		if item == nil || item.acquire() {
+			return item
+		}
+		// Replaced while acquiring: the next load returns the new item.
+	}
+}
+
+// HasCalendar reports whether a calendar is served.
+func (s *CalendarServer) HasCalendar() bool {
+	return s.cache.Load() != nil
+}
+
+// CopyTo writes the calendar currently served to w, without loading it in memory.
+func (s *CalendarServer) CopyTo(w io.Writer) (int64, error) {
+	item := s.acquire()
+	if item == nil {
+		return 0, errors.New(config.ErrNoCalendar)
+	}
+	defer item.release()
+	return io.Copy(w, io.NewSectionReader(item.content, 0, item.content.Size()))
+}
+
+// Data returns the calendar currently served, or nil before the first synchronization.
+// It reads the whole calendar in memory: prefer CopyTo for large calendars.
+func (s *CalendarServer) Data() []byte {
+	var buf bytes.Buffer
+	if _, err := s.CopyTo(&buf); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// UpdatePhotos atomically replaces the served contact pictures (keyed by contact UID).
+// Passing nil clears the photo cache.
+func (s *CalendarServer) UpdatePhotos(photos map[string][]byte) {
+	s.photos.Store(&photos)
+
+	slog.Debug(config.MsgPhotosUpdated,
+		config.LogKeyComponent, config.CompServer,
+		config.LogKeyCount, len(photos),
 	)
 }
 
@@ -120,7 +449,7 @@ func (s *CalendarServer) handleCalendarRequest(w http.ResponseWriter, r *http.Re
 	}
 
 	// 2. Load Data (Atomic / Lock-Free)
-	item := s.cache.Load()
+	item := s.acquire()
 
 	// 3. Readiness Check
 	if item == nil {
@@ -128,35 +457,56 @@ func (s *CalendarServer) handleCalendarRequest(w http.ResponseWriter, r *http.Re
 		http.Error(w, config.HTTPMsgInitializing, http.StatusServiceUnavailable)
 		return
 	}
+	defer item.release()
+
+	if item.stale {
+		slog.Info(config.MsgServeStale,
+			config.LogKeyComponent, config.CompServer,
+			config.LogKeyModified, item.modified.Format(http.TimeFormat))
+	}
 
 	// 4. Set Response Headers
 	w.Header().Set(config.HeaderContentType, config.MimeTextCalendar)
 	w.Header().Set(config.HeaderXContentType, config.MimeNoSniff)
+	// Browsers and import dialogs save a named file, whatever the path.
+	w.Header().Set(config.HeaderContentDisp, fmt.Sprintf(config.FormatDisposition, config.ExportCalFileName))
 	w.Header().Set(config.HeaderCacheControl, config.CacheControlPrivate)
 	w.Header().Set(config.HeaderETag, item.etag)
-	w.Header().Set(config.HeaderLastModified, item.lastModified)
 
-	// 5. Check Conditional Headers (Browser Caching)
-	if match := r.Header.Get(config.HeaderIfNoneMatch); match == item.etag {
-		w.WriteHeader(http.StatusNotModified)
+	// 5. Serve Content, from memory or from the temporary file of a large calendar.
+	// ServeContent answers the conditional headers (304), HEAD with its Content-Length, and byte
+	// ranges (206), so that clients on flaky links resume a large calendar rather than restart.
+	http.ServeContent(w, r, config.ExportCalFileName, item.modified, io.NewSectionReader(item.content, 0, item.size))
+}
+
+// handlePhotoRequest serves a contact picture under RoutePhotos + "{uid}.jpg".
+func (s *CalendarServer) handlePhotoRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set(config.HeaderAllow, config.AllowedMethods)
+		http.Error(w, config.HTTPMsgMethodNotAll, http.StatusMethodNotAllowed)
 		return
 	}
 
-	if since := r.Header.Get(config.HeaderIfModifiedSince); since != "" {
-		if clientTime, err := time.Parse(http.TimeFormat, since); err == nil {
-			if serverTime, err := time.Parse(http.TimeFormat, item.lastModified); err == nil {
-				// If server content is not newer than client cache, return 304.
-				if !serverTime.After(clientTime) {
-					w.WriteHeader(http.StatusNotModified)
-					return
-				}
-			}
-		}
+	uid := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, config.RoutePhotos), config.ExtJPG)
+
+	var data []byte
+	if photos := s.photos.Load(); photos != nil {
+		data = (*photos)[uid]
+	}
+	if len(data) == 0 {
+		http.Error(w, config.HTTPMsgNotFound, http.StatusNotFound)
+		return
 	}
 
-	// 6. Serve Content
+	// The extension is fixed for client compatibility; the real type is sniffed.
+	w.Header().Set(config.HeaderContentType, http.DetectContentType(data))
+	w.Header().Set(config.HeaderXContentType, config.MimeNoSniff)
+	w.Header().Set(config.HeaderCacheControl, config.CacheControlPrivate)
+	w.Header().Set(config.HeaderAcceptRanges, config.AcceptRangesNone)
+	w.Header().Set(config.HeaderContentLength, strconv.Itoa(len(data)))
+
 	if r.Method == http.MethodGet {
-		if _, err := io.Copy(w, bytes.NewReader(item.data)); err != nil {
+		if _, err := w.Write(data); err != nil {
 			slog.Error(config.ErrWriteResp,
 				config.LogKeyComponent, config.CompServer,
 				config.LogKeyError, err,
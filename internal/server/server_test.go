@@ -1,11 +1,18 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -114,6 +121,101 @@ func TestHandler_Initializing(t *testing.T) {
 	assert.Equal(t, config.RetryAfterSeconds, resp.Header.Get(config.HeaderRetryAfter))
 }
 
+// TestHandler_Stale verifies that a cached calendar is served with its original date until the next update.
+func TestHandler_Stale(t *testing.T) {
+	srv := NewCalendarServer("0")
+	generated := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	srv.UpdateStale(newBytesBuffer([]byte("BEGIN:VCALENDAR\r\nOLD")), generated)
+
+	w := httptest.NewRecorder()
+	srv.handleCalendarRequest(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "BEGIN:VCALENDAR\r\nOLD", w.Body.String())
+	assert.Equal(t, generated.Format(http.TimeFormat), w.Header().Get(config.HeaderLastModified))
+	assert.True(t, srv.cache.Load().stale)
+
+	srv.Update([]byte("BEGIN:VCALENDAR\r\nNEW"))
+	assert.False(t, srv.cache.Load().stale)
+}
+
+// TestServer_Data verifies access to the cached calendar (used by the manual export).
+func TestServer_Data(t *testing.T) {
+	srv := NewCalendarServer("0")
+	assert.Nil(t, srv.Data(), "No calendar before the first sync")
+
+	srv.Update([]byte("BEGIN:VCALENDAR"))
+	assert.Equal(t, []byte("BEGIN:VCALENDAR"), srv.Data())
+}
+
+// TestServer_LargeCalendar verifies that a calendar outgrowing the memory limit is served
+// from a temporary file, removed once the calendar is replaced.
+func TestServer_LargeCalendar(t *testing.T) {
+	srv := NewCalendarServer("0")
+	line := []byte(strings.Repeat("X", 1023) + "\n")
+	var want bytes.Buffer
+	b := NewBuffer()
+	for want.Len() <= config.CalendarMemoryLimit {
+		_, err := b.Write(line)
+		require.NoError(t, err)
+		want.Write(line)
+	}
+	require.NotNil(t, b.file, "Content must be moved to a temporary file")
+	tmp := b.file.Name()
+	srv.UpdateBuffer(b)
+
+	w := httptest.NewRecorder()
+	srv.handleCalendarRequest(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, strconv.Itoa(want.Len()), w.Header().Get(config.HeaderContentLength))
+	assert.True(t, bytes.Equal(want.Bytes(), w.Body.Bytes()))
+
+	// Same content, same ETag, whatever the storage.
+	etag := w.Header().Get(config.HeaderETag)
+	srv.Update(want.Bytes())
+	assert.Equal(t, etag, srv.cache.Load().etag)
+
+	_, err := os.Stat(tmp)
+	assert.ErrorIs(t, err, os.ErrNotExist, "Temporary file must be removed once replaced")
+
+	// A calendar still being read is only closed by its last reader.
+	item := srv.acquire()
+	srv.Update([]byte("NEW"))
+	var buf bytes.Buffer
+	_, err = io.Copy(&buf, io.NewSectionReader(item.content, 0, item.content.Size()))
+	require.NoError(t, err)
+	assert.Equal(t, want.Len(), buf.Len())
+	item.release()
+	assert.Nil(t, item.content.mem, "Content must be released after the last reader")
+}
+
+// TestHandler_Photos verifies that contact pictures are served by UID and that
+// unknown UIDs return 404.
+func TestHandler_Photos(t *testing.T) {
+	srv := NewCalendarServer("0")
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10}
+	srv.UpdatePhotos(map[string][]byte{"abc123": jpeg})
+
+	req := httptest.NewRequest(http.MethodGet, "/photos/abc123.jpg", nil)
+	w := httptest.NewRecorder()
+	srv.handlePhotoRequest(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/jpeg", w.Header().Get(config.HeaderContentType))
+	assert.Equal(t, jpeg, w.Body.Bytes())
+
+	req = httptest.NewRequest(http.MethodGet, "/photos/unknown.jpg", nil)
+	w = httptest.NewRecorder()
+	srv.handlePhotoRequest(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Clearing the cache removes the photos.
+	srv.UpdatePhotos(nil)
+	req = httptest.NewRequest(http.MethodGet, "/photos/abc123.jpg", nil)
+	w = httptest.NewRecorder()
+	srv.handlePhotoRequest(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
 // -----------------------------------------------------------------------------
 // Concurrency Tests (Race Detection)
 // -----------------------------------------------------------------------------
@@ -236,3 +338,610 @@ func TestServer_Lifecycle(t *testing.T) {
 		t.Fatal("Server shutdown timed out")
 	}
 }
+
+// TestServer_Ready verifies that Ready is called once listening, and that a busy port fails Start at once.
+func TestServer_Ready(t *testing.T) {
+	const port = "18098"
+
+	ready := make(chan struct{})
+	srv := NewCalendarServer(port)
+	srv.Ready = func() { close(ready) }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- srv.Start(ctx)
+	}()
+
+	select {
+	case <-ready:
+	case err := <-errChan:
+		t.Fatalf("Start failed: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Ready was not called")
+	}
+
+	err := NewCalendarServer(port).Start(ctx)
+	assert.ErrorContains(t, err, config.ErrServerStartup, "The port is already in use")
+
+	cancel()
+	assert.NoError(t, <-errChan)
+}
+
+// TestHandler_HomeAssistant verifies the JSON payload: today's count, the next birthday and the coming week.
+func TestHandler_HomeAssistant(t *testing.T) {
+	srv := NewCalendarServer("0")
+	srv.Now = func() time.Time { return time.Date(2025, 2, 27, 15, 0, 0, 0, time.UTC) }
+
+	w := httptest.NewRecorder()
+	srv.handleHomeAssistant(w, httptest.NewRequest(http.MethodGet, config.RouteHomeAssistant, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "Not ready before the first synchronization")
+
+	srv.UpdateBirthdays([]Birthday{
+		{Name: "Later", Date: time.Date(1990, 6, 1, 0, 0, 0, 0, time.UTC), YearKnown: true},
+		{Name: "Leap", Date: time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC), YearKnown: true},
+		{Name: "Today", Date: time.Date(1, 2, 27, 0, 0, 0, 0, time.UTC)},
+		{Name: "Passed", Date: time.Date(1980, 2, 26, 0, 0, 0, 0, time.UTC), YearKnown: true},
+	})
+	w = httptest.NewRecorder()
+	srv.handleHomeAssistant(w, httptest.NewRequest(http.MethodGet, config.RouteHomeAssistant, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, config.MimeJSON, w.Header().Get(config.HeaderContentType))
+	assert.JSONEq(t, `{
+		"today": 1,
+		"next": {"name": "Today", "date": "2025-02-27", "days": 0},
+		"upcoming": [
+			{"name": "Today", "date": "2025-02-27", "days": 0},
+			{"name": "Leap", "date": "2025-03-01", "days": 2, "age": 25}
+		]
+	}`, w.Body.String())
+
+	srv.UpdateBirthdays(nil)
+	w = httptest.NewRecorder()
+	srv.handleHomeAssistant(w, httptest.NewRequest(http.MethodGet, config.RouteHomeAssistant, nil))
+	assert.JSONEq(t, `{"today": 0, "next": null, "upcoming": []}`, w.Body.String())
+}
+
+// TestHandler_Todos verifies the VTODO of each birthday due within the configured days.
+func TestHandler_Todos(t *testing.T) {
+	srv := NewCalendarServer("0")
+	srv.Now = func() time.Time { return time.Date(2025, 2, 27, 15, 0, 0, 0, time.UTC) }
+
+	w := httptest.NewRecorder()
+	srv.handleTodos(w, httptest.NewRequest(http.MethodGet, config.RouteTodos, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "Not ready before the first synchronization")
+
+	srv.UpdateBirthdays([]Birthday{
+		{UID: "c1", Name: "Leap", Date: time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC), YearKnown: true},
+		{UID: "c2", Name: "Later", Date: time.Date(1990, 6, 1, 0, 0, 0, 0, time.UTC), YearKnown: true},
+	})
+	srv.UpdateTodos(3, func(name string, age int, yearKnown bool) string {
+		return fmt.Sprintf("%s %d %t", name, age, yearKnown)
+	})
+	w = httptest.NewRecorder()
+	srv.handleTodos(w, httptest.NewRequest(http.MethodGet, config.RouteTodos, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, config.MimeTextCalendar, w.Header().Get(config.HeaderContentType))
+	body := w.Body.String()
+	assert.Equal(t, 1, strings.Count(body, "BEGIN:VTODO"), "Only the birthdays of the coming days")
+	assert.Contains(t, body, "UID:todo-c1-2025@"+config.ICalDomain+"\r\n")
+	assert.Contains(t, body, "DUE;VALUE=DATE:20250301\r\n")
+	assert.Contains(t, body, "SUMMARY:Leap 25 true\r\n")
+	assert.Contains(t, body, "STATUS:NEEDS-ACTION\r\n")
+	assert.NotContains(t, body, "Later")
+
+	srv.UpdateTodos(0, nil)
+	w = httptest.NewRecorder()
+	srv.handleTodos(w, httptest.NewRequest(http.MethodGet, config.RouteTodos, nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "0 days disables the to-dos")
+}
+
+// TestHandler_CalDAV verifies the read-only CalDAV collection: discovery, listing, reports and events.
+func TestHandler_CalDAV(t *testing.T) {
+	srv := NewCalendarServer("0")
+	dav := func(method, path, depth, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if depth != "" {
+			req.Header.Set(config.HeaderDepth, depth)
+		}
+		w := httptest.NewRecorder()
+		srv.handleCalDAV(w, req)
+		return w
+	}
+
+	w := dav(config.MethodPropfind, config.RouteCalDAVCalendar, "1", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "Not ready before the first synchronization")
+
+	srv.Update([]byte("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//test//EN\r\nMETHOD:PUBLISH\r\n" +
+		"BEGIN:VEVENT\r\nUID:b@test\r\nDTSTAMP:20250101T000000Z\r\nDTSTART;VALUE=DATE:20250301\r\nSUMMARY:Bob\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:a@test\r\nDTSTAMP:20250101T000000Z\r\nDTSTART;VALUE=DATE:20250201\r\nSUMMARY:Ann\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"))
+
+	w = dav(config.MethodPropfind, config.RouteCalDAV, "0", "")
+	require.Equal(t, http.StatusMultiStatus, w.Code)
+	assert.Equal(t, config.DAVCompliance, w.Header().Get(config.HeaderDAV))
+	assert.Contains(t, w.Body.String(), "<c:calendar-home-set><d:href>/caldav/</d:href></c:calendar-home-set>")
+	assert.NotContains(t, w.Body.String(), config.RouteCalDAVCalendar, "Depth 0 describes the home alone")
+
+	w = dav(config.MethodPropfind, config.RouteCalDAVCalendar, "1", "")
+	require.Equal(t, http.StatusMultiStatus, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "<c:calendar></c:calendar>")
+	assert.Contains(t, body, `<c:comp name="VEVENT"></c:comp>`)
+	assert.Contains(t, body, "<d:displayname>"+config.ICalCalName+"</d:displayname>")
+	assert.Less(t, strings.Index(body, "/caldav/birthdays/a@test.ics"), strings.Index(body, "/caldav/birthdays/b@test.ics"))
+	assert.NotContains(t, body, "calendar-data", "PROPFIND lists the events without their content")
+
+	w = dav(http.MethodGet, config.RouteCalDAVCalendar+"a@test.ics", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	event := w.Body.String()
+	etag := w.Header().Get(config.HeaderETag)
+	assert.Equal(t, 1, strings.Count(event, "BEGIN:VEVENT"), "One event per resource")
+	assert.Contains(t, event, "SUMMARY:Ann\r\n")
+	assert.NotContains(t, event, "METHOD:")
+	assert.Contains(t, body, "<d:getetag>"+strings.ReplaceAll(etag, `"`, "&#34;")+"</d:getetag>")
+
+	req := httptest.NewRequest(http.MethodGet, config.RouteCalDAVCalendar+"a@test.ics", nil)
+	req.Header.Set(config.HeaderIfNoneMatch, etag)
+	w = httptest.NewRecorder()
+	srv.handleCalDAV(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = dav(config.MethodReport, config.RouteCalDAVCalendar, "1", `<?xml version="1.0"?>`+
+		`<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:prop><d:getetag/><c:calendar-data/></d:prop>`+
+		`<c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VEVENT"/></c:comp-filter></c:filter></c:calendar-query>`)
+	require.Equal(t, http.StatusMultiStatus, w.Code)
+	assert.Equal(t, 2, strings.Count(w.Body.String(), "<c:calendar-data>"))
+
+	query := func(timeRange string) *httptest.ResponseRecorder {
+		return dav(config.MethodReport, config.RouteCalDAVCalendar, "1", `<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">`+
+			`<d:prop><d:getetag/></d:prop><c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VEVENT">`+timeRange+
+			`</c:comp-filter></c:comp-filter></c:filter></c:calendar-query>`)
+	}
+	w = query(`<c:time-range start="20250201T120000Z" end="20250301T000000Z"/>`)
+	require.Equal(t, http.StatusMultiStatus, w.Code)
+	assert.Equal(t, 1, strings.Count(w.Body.String(), "<d:response>"), "Only the events within the time range")
+	assert.Contains(t, w.Body.String(), "a@test.ics", "An all-day event lasts the whole day")
+	w = query(`<c:time-range start="20250302T000000Z"/>`)
+	assert.Empty(t, strings.Count(w.Body.String(), "<d:response>"))
+	w = query(`<c:time-range start="2025-03-01"/>`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = dav(config.MethodReport, config.RouteCalDAVCalendar, "1", `<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">`+
+		`<c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO"/></c:comp-filter></c:filter></c:calendar-query>`)
+	assert.Empty(t, strings.Count(w.Body.String(), "<d:response>"), "Only events are served")
+
+	w = dav(config.MethodReport, config.RouteCalDAVCalendar, "1", `<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">`+
+		`<d:prop><d:getetag/><c:calendar-data/></d:prop><d:href>/caldav/birthdays/b%40test.ics</d:href><d:href>/caldav/birthdays/x.ics</d:href></c:calendar-multiget>`)
+	require.Equal(t, http.StatusMultiStatus, w.Code)
+	assert.Equal(t, 1, strings.Count(w.Body.String(), "<d:response>"), "Unknown events are left out")
+	assert.Contains(t, w.Body.String(), "SUMMARY:Bob")
+
+	w = dav(http.MethodGet, config.RouteCalDAVCalendar+"x.ics", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = dav(http.MethodPut, config.RouteCalDAVCalendar+"a@test.ics", "", "BEGIN:VCALENDAR")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code, "Read-only")
+
+	// A new DTSTAMP alone keeps the ETag of the events, so that clients do not download them again.
+	srv.Update([]byte("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//test//EN\r\nMETHOD:PUBLISH\r\n" +
+		"BEGIN:VEVENT\r\nUID:a@test\r\nDTSTAMP:20250102T000000Z\r\nDTSTART;VALUE=DATE:20250201\r\nSUMMARY:Ann\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"))
+	w = dav(http.MethodGet, config.RouteCalDAVCalendar+"a@test.ics", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "DTSTAMP:20250102T000000Z")
+	assert.Equal(t, etag, w.Header().Get(config.HeaderETag))
+
+	w = httptest.NewRecorder()
+	srv.handleWellKnownCalDAV(w, httptest.NewRequest(config.MethodPropfind, config.RouteWellKnownDAV, nil))
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, config.RouteCalDAV, w.Header().Get(config.HeaderLocation))
+}
+
+// TestServer_Addrs verifies that the server listens on the loopback unless other hosts are set,
+// IPv6 literals included.
+func TestServer_Addrs(t *testing.T) {
+	srv := NewCalendarServer("18080")
+	assert.Equal(t, []string{"127.0.0.1:18080"}, srv.Addrs())
+	srv.Hosts = []string{config.ContainerBindAddr}
+	assert.Equal(t, []string{"0.0.0.0:18080"}, srv.Addrs())
+	srv.Hosts = []string{"::"}
+	assert.Equal(t, []string{"[::]:18080"}, srv.Addrs())
+	srv.Hosts = []string{"127.0.0.1", "[::1]"}
+	assert.Equal(t, []string{"127.0.0.1:18080", "[::1]:18080"}, srv.Addrs())
+}
+
+// TestServer_MultipleListeners verifies that every address is served under one lifecycle,
+// and that one unavailable address fails Start without leaving the others open.
+func TestServer_MultipleListeners(t *testing.T) {
+	const port = "18097"
+	if ln, err := net.Listen(config.NetworkTCP, "[::1]:"+port); err != nil {
+		t.Skip("IPv6 loopback unavailable:", err)
+	} else {
+		_ = ln.Close()
+	}
+
+	srv := NewCalendarServer(port)
+	srv.Hosts = []string{"127.0.0.1", "[::1]"}
+	srv.Update([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+	ready := make(chan struct{})
+	srv.Ready = func() { close(ready) }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- srv.Start(ctx)
+	}()
+	select {
+	case <-ready:
+	case err := <-errChan:
+		t.Fatalf("Start failed: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Ready was not called")
+	}
+
+	for _, url := range []string{"http://127.0.0.1:" + port + "/", "http://[::1]:" + port + "/"} {
+		resp, err := http.Get(url)
+		require.NoError(t, err, url)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, url)
+		_ = resp.Body.Close()
+	}
+
+	cancel()
+	require.NoError(t, <-errChan)
+
+	// The IPv4 address is busy: the IPv6 one must not stay open.
+	other := NewCalendarServer(port)
+	other.Hosts = []string{"[::1]", "127.0.0.1"}
+	busy, err := net.Listen(config.NetworkTCP, "127.0.0.1:"+port)
+	require.NoError(t, err)
+	defer func() { _ = busy.Close() }()
+	assert.ErrorContains(t, other.Start(context.Background()), config.ErrServerStartup)
+	ln, err := net.Listen(config.NetworkTCP, "[::1]:"+port)
+	require.NoError(t, err, "Closed after the failure")
+	_ = ln.Close()
+}
+
+// TestHandler_HeadContentLength verifies that HEAD announces the length of the calendar GET
+// returns, over a real connection, for clients probing freshness, and that the calendar is
+// named whatever its path.
+func TestHandler_HeadContentLength(t *testing.T) {
+	srv := NewCalendarServer("0")
+	body := []byte("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nEND:VCALENDAR\r\n")
+	srv.Update(body)
+	srv.UpdatePhotos(map[string][]byte{"abc123": {0xFF, 0xD8, 0xFF, 0xE0}})
+	mux := http.NewServeMux()
+	mux.HandleFunc(config.RouteRoot, srv.handleCalendarRequest)
+	mux.HandleFunc(config.RouteCalendarFile, srv.handleCalendarRequest)
+	mux.HandleFunc(config.RoutePhotos, srv.handlePhotoRequest)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	sizes := map[string]int{"/": len(body), config.RouteCalendarFile: len(body), config.RoutePhotos + "abc123.jpg": 4}
+	for path, size := range sizes {
+		for _, method := range []string{http.MethodGet, http.MethodHead} {
+			req, err := http.NewRequest(method, ts.URL+path, nil)
+			require.NoError(t, err)
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			data, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode, "%s %s", method, path)
+			assert.Equal(t, strconv.Itoa(size), resp.Header.Get(config.HeaderContentLength), "%s %s", method, path)
+			assert.Equal(t, int64(size), resp.ContentLength, "%s %s", method, path)
+			if path == config.RoutePhotos+"abc123.jpg" {
+				assert.Equal(t, config.AcceptRangesNone, resp.Header.Get(config.HeaderAcceptRanges), "%s %s", method, path)
+			} else {
+				assert.Equal(t, "bytes", resp.Header.Get(config.HeaderAcceptRanges), "%s %s", method, path)
+				assert.Equal(t, `inline; filename="birthdays.ics"`, resp.Header.Get(config.HeaderContentDisp), "%s %s", method, path)
+			}
+			if method == http.MethodHead {
+				assert.Empty(t, data)
+			} else {
+				assert.Len(t, data, size)
+			}
+		}
+	}
+}
+
+// TestHandler_Range verifies that an interrupted download of the calendar can be resumed,
+// unless the calendar changed in the meantime.
+func TestHandler_Range(t *testing.T) {
+	srv := NewCalendarServer("0")
+	body := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nEND:VCALENDAR\r\n"
+	srv.Update([]byte(body))
+	etag := srv.cache.Load().etag
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=15-")
+	req.Header.Set("If-Range", etag)
+	w := httptest.NewRecorder()
+	srv.handleCalendarRequest(w, req)
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, body[15:], w.Body.String())
+	assert.Equal(t, fmt.Sprintf("bytes 15-%d/%d", len(body)-1, len(body)), w.Header().Get("Content-Range"))
+	assert.Equal(t, strconv.Itoa(len(body)-15), w.Header().Get(config.HeaderContentLength))
+	assert.Equal(t, config.MimeTextCalendar, w.Header().Get(config.HeaderContentType))
+
+	// Another calendar is sent whole.
+	srv.Update([]byte(body + "X"))
+	w = httptest.NewRecorder()
+	srv.handleCalendarRequest(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, body+"X", w.Body.String())
+
+	req.Header.Set("Range", "bytes=1000-")
+	req.Header.Del("If-Range")
+	w = httptest.NewRecorder()
+	srv.handleCalendarRequest(w, req)
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+}
+
+// TestHandler_CORS verifies the headers of the allowed origin, the preflight answer,
+// and that nothing changes while CORS is disabled.
+func TestHandler_CORS(t *testing.T) {
+	srv := NewCalendarServer("0")
+	srv.UpdateBirthdays(nil)
+	h := srv.withCORS(srv.handleHomeAssistant)
+
+	preflight := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, config.RouteHomeAssistant, nil)
+		req.Header.Set(config.HeaderOrigin, "https://dash.example.org")
+		req.Header.Set(config.HeaderACRequestMethod, http.MethodGet)
+		w := httptest.NewRecorder()
+		h(w, req)
+		return w
+	}
+
+	w := preflight()
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code, "Disabled by default")
+	assert.Empty(t, w.Header().Get(config.HeaderACAllowOrigin))
+
+	srv.UpdateCORS("https://dash.example.org")
+	w = preflight()
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://dash.example.org", w.Header().Get(config.HeaderACAllowOrigin))
+	assert.Equal(t, config.AllowedMethods, w.Header().Get(config.HeaderACAllowMethods))
+	assert.Contains(t, w.Header().Get(config.HeaderACAllowHeaders), config.HeaderIfNoneMatch)
+	assert.Equal(t, config.HeaderOrigin, w.Header().Get(config.HeaderVary))
+
+	req := httptest.NewRequest(http.MethodGet, config.RouteHomeAssistant, nil)
+	req.Header.Set(config.HeaderOrigin, "https://dash.example.org")
+	w = httptest.NewRecorder()
+	h(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://dash.example.org", w.Header().Get(config.HeaderACAllowOrigin))
+	assert.Contains(t, w.Header().Get(config.HeaderACExposeHeaders), config.HeaderETag)
+	assert.Equal(t, config.MimeJSON, w.Header().Get(config.HeaderContentType))
+
+	srv.UpdateCORS(config.CORSAnyOrigin)
+	w = preflight()
+	assert.Equal(t, config.CORSAnyOrigin, w.Header().Get(config.HeaderACAllowOrigin))
+	assert.Empty(t, w.Header().Get(config.HeaderVary), "The same answer for every origin")
+
+	srv.UpdateCORS("")
+	w = preflight()
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+// TestHandler_CORSCalDAV verifies that CORS leaves the CalDAV discovery (OPTIONS without a
+// preflight) to the CalDAV handler, and allows the DAV methods to the scripts.
+func TestHandler_CORSCalDAV(t *testing.T) {
+	srv := NewCalendarServer("0")
+	srv.UpdateCORS(config.CORSAnyOrigin)
+	h := srv.withCORS(srv.handleCalDAV)
+
+	req := httptest.NewRequest(http.MethodOptions, config.RouteCalDAV, nil)
+	w := httptest.NewRecorder()
+	h(w, req)
+	assert.Equal(t, config.DAVCompliance, w.Header().Get(config.HeaderDAV), "Answered by the CalDAV handler")
+	assert.Equal(t, config.AllowedMethodsDAV, w.Header().Get(config.HeaderAllow))
+
+	req = httptest.NewRequest(http.MethodOptions, config.RouteCalDAV, nil)
+	req.Header.Set(config.HeaderOrigin, "https://dash.example.org")
+	req.Header.Set(config.HeaderACRequestMethod, config.MethodPropfind)
+	w = httptest.NewRecorder()
+	h(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, config.AllowedMethodsDAV, w.Header().Get(config.HeaderACAllowMethods))
+	assert.Contains(t, w.Header().Get(config.HeaderACAllowHeaders), config.HeaderDepth)
+}
+
+// TestHandler_Landing verifies the page shown to browsers at the root, while calendar clients
+// and the file route keep getting the calendar.
+func TestHandler_Landing(t *testing.T) {
+	srv := NewCalendarServer("0")
+	srv.Update([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+	browser := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(config.HeaderAccept, "text/html,application/xhtml+xml,*/*;q=0.8")
+		w := httptest.NewRecorder()
+		srv.handleRoot(w, req)
+		return w
+	}
+
+	w := browser(config.RouteRoot)
+	assert.Equal(t, config.MimeTextCalendar, w.Header().Get(config.HeaderContentType), "The calendar until the page is set")
+
+	srv.UpdateLanding(Landing{Lang: "en", Name: "<Family>", Contacts: "Contacts:", Copy: "Copy", TimeLayout: time.DateTime})
+	srv.UpdateBirthdays([]Birthday{{Name: "Ann"}, {Name: "Bob"}})
+	w = browser(config.RouteRoot)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, config.MimeTextHTML, w.Header().Get(config.HeaderContentType))
+	assert.Equal(t, config.HeaderAccept, w.Header().Get(config.HeaderVary))
+	body := w.Body.String()
+	assert.Contains(t, body, "<h1>&lt;Family&gt;</h1>")
+	assert.Contains(t, body, "Contacts: 2")
+	assert.Contains(t, body, `value="webcal://example.com/birthdays.ics"`)
+	assert.Contains(t, body, `value="http://example.com/birthdays.ics"`)
+	assert.Equal(t, strconv.Itoa(len(body)), w.Header().Get(config.HeaderContentLength))
+
+	w = browser(config.RouteCalendarFile)
+	assert.Equal(t, config.MimeTextCalendar, w.Header().Get(config.HeaderContentType), "The file route is always the calendar")
+
+	w = httptest.NewRecorder()
+	srv.handleRoot(w, httptest.NewRequest(http.MethodGet, config.RouteRoot, nil))
+	assert.Equal(t, config.MimeTextCalendar, w.Header().Get(config.HeaderContentType), "Calendar clients keep the calendar")
+	assert.Equal(t, config.HeaderAccept, w.Header().Get(config.HeaderVary))
+}
+
+// TestHandler_AccessLog verifies the fields logged for each request while access logging is on.
+func TestHandler_AccessLog(t *testing.T) {
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	srv := NewCalendarServer("0")
+	srv.Update([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+	h := srv.withAccessLog(srv.handleCalendarRequest)
+
+	h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, config.RouteCalendarFile, nil))
+	assert.Empty(t, logs.String(), "Off by default")
+
+	srv.SetAccessLog(true)
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, config.RouteCalendarFile, nil))
+	req := httptest.NewRequest(http.MethodGet, config.RouteCalendarFile, nil)
+	req.Header.Set(config.HeaderIfNoneMatch, w.Header().Get(config.HeaderETag))
+	h(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	require.Len(t, lines, 2)
+	var first, second map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, config.CompHTTPAccess, first[config.LogKeyComponent])
+	assert.Equal(t, http.MethodGet, first[config.LogKeyMethod])
+	assert.Equal(t, config.RouteCalendarFile, first[config.LogKeyPath])
+	assert.EqualValues(t, http.StatusOK, first[config.LogKeyStatus])
+	assert.EqualValues(t, 32, first[config.LogKeyBytes])
+	assert.Equal(t, "192.0.2.1:1234", first[config.LogKeyRemote])
+	assert.Equal(t, false, first[config.LogKeyCondHit])
+	assert.EqualValues(t, http.StatusNotModified, second[config.LogKeyStatus])
+	assert.Equal(t, true, second[config.LogKeyCondHit])
+}
+
+// TestHandler_Allowlist verifies that only the allowed networks and the loopback reach the handlers.
+func TestHandler_Allowlist(t *testing.T) {
+	srv := NewCalendarServer("0")
+	srv.Update([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+	h := srv.withAllowlist(srv.handleCalendarRequest)
+	status := func(remote string) int {
+		req := httptest.NewRequest(http.MethodGet, config.RouteCalendarFile, nil)
+		req.RemoteAddr = remote
+		w := httptest.NewRecorder()
+		h(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, status("10.0.0.7:5000"), "Any client without an allowlist")
+
+	networks, err := ParseNetworks([]string{"192.168.1.0/24", "10.0.0.5", "fd00::/8"})
+	require.NoError(t, err)
+	srv.UpdateAllowlist(networks)
+	assert.Equal(t, http.StatusOK, status("192.168.1.20:5000"))
+	assert.Equal(t, http.StatusOK, status("[::ffff:192.168.1.20]:5000"), "IPv4-mapped addresses of a dual-stack listener")
+	assert.Equal(t, http.StatusOK, status("10.0.0.5:5000"))
+	assert.Equal(t, http.StatusOK, status("[fd12::1]:5000"))
+	assert.Equal(t, http.StatusOK, status("127.0.0.1:5000"), "The loopback is always allowed")
+	assert.Equal(t, http.StatusOK, status("[::1]:5000"))
+	assert.Equal(t, http.StatusForbidden, status("10.0.0.7:5000"))
+	assert.Equal(t, http.StatusForbidden, status("192.168.2.1:5000"))
+	assert.Equal(t, http.StatusForbidden, status("garbage"))
+
+	_, err = ParseNetworks([]string{"192.168.1.0/33"})
+	assert.Error(t, err)
+	_, err = ParseNetworks([]string{"home"})
+	assert.Error(t, err)
+}
+
+// TestServer_Restart verifies that Restart moves a running server to another port, and keeps
+// the current one when the new port is busy.
+func TestServer_Restart(t *testing.T) {
+	srv := NewCalendarServer("18096")
+	require.NoError(t, srv.Restart("18095"), "Before Start, the port of the next Start")
+	assert.Equal(t, "18095", srv.Port())
+
+	srv.Update([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+	ready := make(chan struct{})
+	srv.Ready = func() { close(ready) }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- srv.Start(ctx)
+	}()
+	select {
+	case <-ready:
+	case err := <-errChan:
+		t.Fatalf("Start failed: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Ready was not called")
+	}
+	get := func(port string) error {
+		resp, err := http.Get("http://127.0.0.1:" + port + "/")
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+	require.NoError(t, get("18095"))
+
+	require.NoError(t, srv.Restart("18096"))
+	assert.Equal(t, "18096", srv.Port())
+	assert.NoError(t, get("18096"))
+	assert.Error(t, get("18095"), "The old port is closed")
+
+	busy, err := net.Listen(config.NetworkTCP, "127.0.0.1:18094")
+	require.NoError(t, err)
+	defer func() { _ = busy.Close() }()
+	assert.ErrorContains(t, srv.Restart("18094"), config.ErrServerStartup)
+	assert.Equal(t, "18096", srv.Port())
+	assert.NoError(t, get("18096"), "Still served on the previous port")
+
+	cancel()
+	require.NoError(t, <-errChan)
+	require.NoError(t, srv.Restart("18093"), "After Start returned, the port of the next Start")
+	assert.Equal(t, "18093", srv.Port())
+}
+
+// TestServer_RestartAfterFailedStart verifies that a server which could not start on a busy port
+// serves on the port given to Restart once started again.
+func TestServer_RestartAfterFailedStart(t *testing.T) {
+	busy, err := net.Listen(config.NetworkTCP, "127.0.0.1:18092")
+	require.NoError(t, err)
+	defer func() { _ = busy.Close() }()
+
+	srv := NewCalendarServer("18092")
+	srv.Update([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.Error(t, srv.Start(ctx))
+	assert.False(t, srv.Running())
+
+	require.NoError(t, srv.Restart("18091"))
+	ready := make(chan struct{})
+	srv.Ready = func() { close(ready) }
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- srv.Start(ctx)
+	}()
+	select {
+	case <-ready:
+	case err := <-errChan:
+		t.Fatalf("Start failed: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Ready was not called")
+	}
+	assert.True(t, srv.Running())
+	assert.ErrorIs(t, srv.Start(ctx), ErrRunning)
+
+	resp, err := http.Get("http://127.0.0.1:18091/")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cancel()
+	require.NoError(t, <-errChan)
+}
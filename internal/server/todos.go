@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/emersion/go-ical"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+)
+
+// todoSettings configures the to-dos served under RouteTodos.
+type todoSettings struct {
+	days    int                                               // Birthdays due within this many days after today, 0 to disable
+	summary func(name string, age int, yearKnown bool) string // Localized summary, the name alone if nil
+}
+
+// UpdateTodos sets the window and the summary of the to-do endpoint; days 0 disables it.
+func (s *CalendarServer) UpdateTodos(days int, summary func(name string, age int, yearKnown bool) string) {
+	s.todos.Store(&todoSettings{days: days, summary: summary})
+}
+
+// todoCalendar builds the to-dos on now, as homeAssistant does: one VTODO due on each birthday
+// of the coming days, for task apps (Tasks.org, Nextcloud Tasks) rather than calendars.
+func todoCalendar(birthdays []Birthday, settings todoSettings, now time.Time) *ical.Calendar {
+	cal := ical.NewCalendar()
+	cal.Props.SetText(config.PropVersion, config.ICalVersion)
+	cal.Props.SetText(config.PropProdid, config.ICalProdid)
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, b := range birthdays {
+		next, days := nextBirthday(b, today)
+		if days > settings.days {
+			continue
+		}
+		age := next.Year() - b.Date.Year()
+		summary := b.Name
+		if settings.summary != nil {
+			summary = settings.summary(b.Name, age, b.YearKnown)
+		}
+		uid := b.UID
+		if uid == "" {
+			uid = b.Name
+		}
+
+		todo := ical.NewComponent(ical.CompToDo)
+		todo.Props.SetText(config.PropUID, fmt.Sprintf(config.FormatTodoUID, uid, next.Year(), config.ICalDomain))
+		todo.Props.SetDateTime(config.PropDTStamp, now.UTC())
+		todo.Props.SetDate(config.PropDue, next)
+		todo.Props.SetText(config.PropSummary, summary)
+		todo.Props.SetText(config.PropStatus, config.ICalNeedsAction)
+		todo.Props.SetText(config.PropClass, config.DefaultEventClass)
+		cal.Children = append(cal.Children, todo)
+	}
+	return cal
+}
+
+// handleTodos serves the birthdays of the coming days as an iCalendar of to-dos.
+func (s *CalendarServer) handleTodos(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set(config.HeaderAllow, config.AllowedMethods)
+		http.Error(w, config.HTTPMsgMethodNotAll, http.StatusMethodNotAllowed)
+		return
+	}
+
+	settings := s.todos.Load()
+	if settings != nil && settings.days <= 0 {
+		http.Error(w, config.HTTPMsgNotFound, http.StatusNotFound)
+		return
+	}
+	birthdays := s.birthdays.Load()
+	if birthdays == nil || settings == nil {
+		w.Header().Set(config.HeaderRetryAfter, config.RetryAfterSeconds)
+		http.Error(w, config.HTTPMsgInitializing, http.StatusServiceUnavailable)
+		return
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+
+	var buf bytes.Buffer
+	if err := ical.NewEncoder(&buf).Encode(todoCalendar(*birthdays, *settings, now())); err != nil {
+		slog.Error(config.ErrWriteResp,
+			config.LogKeyComponent, config.CompServer,
+			config.LogKeyError, err,
+		)
+		http.Error(w, config.HTTPMsgInternalErr, http.StatusInternalServerError)
+		return
+	}
+	data := engine.FoldLines(buf.Bytes())
+
+	w.Header().Set(config.HeaderContentType, config.MimeTextCalendar)
+	w.Header().Set(config.HeaderXContentType, config.MimeNoSniff)
+	w.Header().Set(config.HeaderCacheControl, config.CacheControlPrivate)
+	w.Header().Set(config.HeaderContentLength, strconv.Itoa(len(data)))
+	if r.Method == http.MethodGet {
+		if _, err := w.Write(data); err != nil {
+			slog.Error(config.ErrWriteResp,
+				config.LogKeyComponent, config.CompServer,
+				config.LogKeyError, err,
+			)
+		}
+	}
+}
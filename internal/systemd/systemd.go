@@ -0,0 +1,122 @@
+// Package systemd integrates the daemon mode with systemd: readiness and reload notifications
+// (sd_notify, without linking libsystemd) and the unit file of a user service.
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// Notify sends a state (e.g., config.SdReady) to the service manager through $NOTIFY_SOCKET.
+// It does nothing when the process was not started by systemd with Type=notify.
+func Notify(state string) error {
+	socket := os.Getenv(config.EnvNotifySocket)
+	if socket == "" {
+		return nil
+	}
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:] // Abstract namespace
+	}
+
+	conn, err := net.DialUnix(config.NetworkUnixgram, nil, &net.UnixAddr{Name: socket, Net: config.NetworkUnixgram})
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.ErrSdNotify, err)
+	}
+	defer func() { _ = conn.Close() }()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("%s: %w", config.ErrSdNotify, err)
+	}
+	return nil
+}
+
+// unitTemplate is the user service; %s are the description, the quoted executable and the daemon flag.
+// systemd reads the readiness from Notify (Type=notify) and reloads by sending SIGHUP.
+// A user manager cannot order after network-online.target: a synchronization started before the
+// network is up is retried by the fetcher.
+const unitTemplate = `[Unit]
+Description=%s
+
+[Service]
+Type=notify
+ExecStart=%s --%s
+ExecReload=/bin/kill -HUP $MAINPID
+Restart=on-failure
+RestartSec=10
+
+[Install]
+WantedBy=default.target
+`
+
+// execEscaper escapes the characters reserved inside a quoted ExecStart argument,
+// including the "$" and "%" expansions of systemd.
+var execEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `$$`, `%`, `%%`)
+
+// Unit returns the unit file of a user service running exe as a daemon.
+func Unit(exe string) string {
+	return fmt.Sprintf(unitTemplate, config.AppName, `"`+execEscaper.Replace(exe)+`"`, config.FlagDaemon)
+}
+
+// UnitPath returns the location of the unit file among the user services.
+func UnitPath() (string, error) {
+	base := os.Getenv(config.EnvXDGConfigHome)
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, config.AutostartXDGDefault)
+	}
+	return filepath.Join(base, filepath.FromSlash(config.SystemdUserDir), config.SystemdUnitFile), nil
+}
+
+// Install writes the unit file of the running executable, overwriting an existing one,
+// and returns its path. The service still has to be enabled with systemctl.
+func Install() (string, error) {
+	if runtime.GOOS != config.GOOSLinux {
+		return "", errors.New(config.ErrServiceOS)
+	}
+	exe, err := Executable()
+	if err != nil {
+		return "", err
+	}
+	path, err := UnitPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), config.DirPermUserRWX); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, []byte(Unit(exe)), config.FilePermUserRW)
+}
+
+// Uninstall removes the unit file and returns its path. Removing a missing unit is not an error.
+func Uninstall() (string, error) {
+	path, err := UnitPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	return path, nil
+}
+
+// Executable returns the absolute path of the running binary, with symlinks resolved.
+func Executable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return exe, nil
+}
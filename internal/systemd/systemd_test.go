@@ -0,0 +1,71 @@
+package systemd_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/systemd"
+)
+
+func TestNotify(t *testing.T) {
+	if runtime.GOOS != config.GOOSLinux {
+		t.Skip("systemd only")
+	}
+	t.Setenv(config.EnvNotifySocket, "")
+	assert.NoError(t, systemd.Notify(config.SdReady), "Without systemd, notifying does nothing")
+
+	socket := filepath.Join(t.TempDir(), "notify")
+	conn, err := net.ListenUnixgram(config.NetworkUnixgram, &net.UnixAddr{Name: socket, Net: config.NetworkUnixgram})
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	t.Setenv(config.EnvNotifySocket, socket)
+	require.NoError(t, systemd.Notify(config.SdReady))
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, config.SdReady, string(buf[:n]))
+
+	t.Setenv(config.EnvNotifySocket, filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorContains(t, systemd.Notify(config.SdReady), config.ErrSdNotify)
+}
+
+func TestUnit(t *testing.T) {
+	unit := systemd.Unit(`/opt/my "apps"/100%/go-birthday`)
+	assert.Contains(t, unit, `ExecStart="/opt/my \"apps\"/100%%/go-birthday" --daemon`)
+	assert.Contains(t, unit, "Type=notify")
+	assert.Contains(t, unit, "ExecReload=/bin/kill -HUP $MAINPID")
+	assert.NotContains(t, unit, "network-online.target", "Invisible to the user manager")
+}
+
+func TestInstall(t *testing.T) {
+	if runtime.GOOS != config.GOOSLinux {
+		_, err := systemd.Install()
+		assert.ErrorContains(t, err, config.ErrServiceOS)
+		return
+	}
+	dir := t.TempDir()
+	t.Setenv(config.EnvXDGConfigHome, dir)
+
+	path, err := systemd.Install()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "systemd", "user", config.SystemdUnitFile), path)
+
+	exe, err := systemd.Executable()
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, systemd.Unit(exe), string(content))
+
+	_, err = systemd.Uninstall()
+	require.NoError(t, err)
+	assert.NoFileExists(t, path)
+	_, err = systemd.Uninstall()
+	assert.NoError(t, err, "Removing a missing unit must succeed")
+}
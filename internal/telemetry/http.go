@@ -0,0 +1,50 @@
+package telemetry
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// statusRecorder remembers the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Handler wraps h with a server span and request metrics per route.
+// route is the registered pattern (e.g., "/photos/"), not the raw path, to keep cardinality low.
+func Handler(route string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if active.Load() == nil {
+			h(w, r)
+			return
+		}
+
+		start := time.Now()
+		ctx, span := StartSpan(r.Context(), config.SpanHTTP, KindServer,
+			slog.String(config.AttrHTTPMethod, r.Method),
+			slog.String(config.AttrHTTPRoute, route))
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		h(rec, r.WithContext(ctx))
+
+		span.SetAttributes(slog.Int(config.AttrHTTPStatus, rec.status))
+		span.End()
+
+		attrs := []slog.Attr{
+			slog.String(config.AttrHTTPMethod, r.Method),
+			slog.String(config.AttrHTTPRoute, route),
+			slog.Int(config.AttrHTTPStatus, rec.status),
+		}
+		AddCount(config.MetricHTTPCount, config.UnitRequests, 1, attrs...)
+		RecordDuration(config.MetricHTTPDuration, time.Since(start), attrs...)
+	}
+}
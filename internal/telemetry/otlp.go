@@ -0,0 +1,123 @@
+package telemetry
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// OTLP JSON encoding: https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+// IDs are hex strings and 64-bit integers are decimal strings.
+
+const (
+	statusOK    = 1
+	statusError = 2
+
+	temporalityCumulative = 2
+)
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type otlpKeyValue struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID      string         `json:"traceId"`
+	SpanID       string         `json:"spanId"`
+	ParentSpanID string         `json:"parentSpanId,omitempty"`
+	Name         string         `json:"name"`
+	Kind         int            `json:"kind"`
+	Start        string         `json:"startTimeUnixNano"`
+	End          string         `json:"endTimeUnixNano"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+	Status       otlpStatus     `json:"status"`
+}
+
+type otlpDataPoint struct {
+	Attributes     []otlpKeyValue `json:"attributes,omitempty"`
+	Start          string         `json:"startTimeUnixNano"`
+	Time           string         `json:"timeUnixNano"`
+	AsInt          string         `json:"asInt,omitempty"`
+	Count          string         `json:"count,omitempty"`
+	Sum            *float64       `json:"sum,omitempty"`
+	BucketCounts   []string       `json:"bucketCounts,omitempty"`
+	ExplicitBounds []float64      `json:"explicitBounds,omitempty"`
+}
+
+type otlpSum struct {
+	DataPoints  []otlpDataPoint `json:"dataPoints"`
+	Temporality int             `json:"aggregationTemporality"`
+	IsMonotonic bool            `json:"isMonotonic"`
+}
+
+type otlpHistogram struct {
+	DataPoints  []otlpDataPoint `json:"dataPoints"`
+	Temporality int             `json:"aggregationTemporality"`
+}
+
+type otlpMetric struct {
+	Name      string         `json:"name"`
+	Unit      string         `json:"unit"`
+	Sum       *otlpSum       `json:"sum,omitempty"`
+	Histogram *otlpHistogram `json:"histogram,omitempty"`
+}
+
+// otlp converts the cumulative state of a metric point.
+func (p *point) otlp(start, now string) otlpMetric {
+	dp := otlpDataPoint{Attributes: otlpAttributes(p.attrs), Start: start, Time: now}
+	m := otlpMetric{Name: p.name, Unit: p.unit}
+
+	if !p.histogram {
+		dp.AsInt = fmt.Sprint(p.count)
+		m.Sum = &otlpSum{DataPoints: []otlpDataPoint{dp}, Temporality: temporalityCumulative, IsMonotonic: true}
+		return m
+	}
+
+	sum := p.sum
+	dp.Count = fmt.Sprint(p.count)
+	dp.Sum = &sum
+	dp.ExplicitBounds = config.TelemetryBuckets
+	for _, n := range p.buckets {
+		dp.BucketCounts = append(dp.BucketCounts, fmt.Sprint(n))
+	}
+	m.Histogram = &otlpHistogram{DataPoints: []otlpDataPoint{dp}, Temporality: temporalityCumulative}
+	return m
+}
+
+// otlpAttributes converts slog attributes to OTLP key/values.
+func otlpAttributes(attrs []slog.Attr) []otlpKeyValue {
+	out := make([]otlpKeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+		var value map[string]any
+		switch v.Kind() {
+		case slog.KindInt64:
+			value = map[string]any{"intValue": fmt.Sprint(v.Int64())}
+		case slog.KindUint64:
+			value = map[string]any{"intValue": fmt.Sprint(v.Uint64())}
+		case slog.KindFloat64:
+			value = map[string]any{"doubleValue": v.Float64()}
+		case slog.KindBool:
+			value = map[string]any{"boolValue": v.Bool()}
+		default:
+			value = map[string]any{"stringValue": v.String()}
+		}
+		out = append(out, otlpKeyValue{Key: a.Key, Value: value})
+	}
+	return out
+}
@@ -0,0 +1,338 @@
+// Package telemetry records OpenTelemetry traces and metrics and exports them over OTLP/HTTP
+// using the JSON encoding, which every OpenTelemetry Collector accepts on port 4318.
+// It stays disabled, and every function is a cheap no-op, unless OTEL_EXPORTER_OTLP_ENDPOINT is set.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// active is the running exporter, nil while telemetry is disabled.
+var active atomic.Pointer[exporter]
+
+// Start enables telemetry if OTEL_EXPORTER_OTLP_ENDPOINT is set and exports periodically until ctx is cancelled.
+// The returned function flushes pending data; it is safe to call when telemetry is disabled.
+func Start(ctx context.Context) func(context.Context) error {
+	endpoint := strings.TrimRight(os.Getenv(config.EnvOTLPEndpoint), "/")
+	if endpoint == "" {
+		return func(context.Context) error { return nil }
+	}
+
+	service := os.Getenv(config.EnvOTelService)
+	if service == "" {
+		service = config.AppName
+	}
+
+	e := newExporter(endpoint, service, parseHeaders(os.Getenv(config.EnvOTLPHeaders)))
+	active.Store(e)
+
+	slog.Info(config.MsgTelemetryOn,
+		config.LogKeyComponent, config.CompTelemetry,
+		config.LogKeyURL, endpoint)
+
+	go func() {
+		ticker := time.NewTicker(config.TelemetryExportInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := e.flush(ctx); err != nil {
+					slog.Debug(config.ErrTelemetryExport,
+						config.LogKeyComponent, config.CompTelemetry,
+						config.LogKeyError, err)
+				}
+			}
+		}
+	}()
+
+	return func(ctx context.Context) error {
+		active.CompareAndSwap(e, nil)
+		return e.flush(ctx)
+	}
+}
+
+// parseHeaders reads the "key=value,key2=value2" format of OTEL_EXPORTER_OTLP_HEADERS.
+func parseHeaders(s string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if ok && strings.TrimSpace(key) != "" {
+			headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return headers
+}
+
+// -----------------------------------------------------------------------------
+// Traces
+// -----------------------------------------------------------------------------
+
+// SpanKind values follow the OTLP enumeration.
+const (
+	KindInternal = 1
+	KindServer   = 2
+	KindClient   = 3
+)
+
+type spanKey struct{}
+
+// Span measures one operation. A nil *Span is valid and ignores every call.
+type Span struct {
+	exp      *exporter
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	kind     int
+	start    time.Time
+	attrs    []slog.Attr
+	err      error
+}
+
+// StartSpan starts a span, child of the span in ctx if any, and returns a context carrying it.
+func StartSpan(ctx context.Context, name string, kind int, attrs ...slog.Attr) (context.Context, *Span) {
+	e := active.Load()
+	if e == nil {
+		return ctx, nil
+	}
+
+	s := &Span{exp: e, name: name, kind: kind, start: time.Now(), attrs: attrs}
+	if parent, ok := ctx.Value(spanKey{}).(*Span); ok && parent != nil {
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		_, _ = rand.Read(s.traceID[:])
+	}
+	_, _ = rand.Read(s.spanID[:])
+
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...slog.Attr) {
+	if s != nil {
+		s.attrs = append(s.attrs, attrs...)
+	}
+}
+
+// SetError marks the span as failed.
+func (s *Span) SetError(err error) {
+	if s != nil {
+		s.err = err
+	}
+}
+
+// End records the span for the next export.
+func (s *Span) End() {
+	if s != nil {
+		s.exp.addSpan(s, time.Now())
+	}
+}
+
+// TraceParent returns the W3C traceparent header of the span in ctx, or "" without one.
+func TraceParent(ctx context.Context) string {
+	s, ok := ctx.Value(spanKey{}).(*Span)
+	if !ok || s == nil {
+		return ""
+	}
+	return config.TraceParentVersion + "-" + hex.EncodeToString(s.traceID[:]) + "-" +
+		hex.EncodeToString(s.spanID[:]) + "-" + config.TraceParentSampled
+}
+
+// -----------------------------------------------------------------------------
+// Metrics
+// -----------------------------------------------------------------------------
+
+// AddCount increments a monotonic counter.
+func AddCount(name, unit string, n int64, attrs ...slog.Attr) {
+	if e := active.Load(); e != nil {
+		e.update(name, unit, false, attrs, func(p *point) { p.count += n })
+	}
+}
+
+// RecordDuration adds a duration to a histogram, in milliseconds.
+func RecordDuration(name string, d time.Duration, attrs ...slog.Attr) {
+	e := active.Load()
+	if e == nil {
+		return
+	}
+	ms := float64(d) / float64(time.Millisecond)
+	e.update(name, config.UnitMilliseconds, true, attrs, func(p *point) {
+		p.count++
+		p.sum += ms
+		i := 0
+		for i < len(config.TelemetryBuckets) && ms > config.TelemetryBuckets[i] {
+			i++
+		}
+		p.buckets[i]++
+	})
+}
+
+// -----------------------------------------------------------------------------
+// Exporter
+// -----------------------------------------------------------------------------
+
+// point is the cumulative state of one metric for one set of attributes.
+type point struct {
+	name, unit string
+	histogram  bool
+	attrs      []slog.Attr
+	count      int64
+	sum        float64
+	buckets    []int64
+}
+
+type exporter struct {
+	endpoint string
+	service  string
+	headers  map[string]string
+	client   *http.Client
+	started  time.Time
+
+	mu     sync.Mutex
+	spans  []otlpSpan // Queued until the next export, at most config.TelemetryMaxSpans
+	points map[string]*point
+}
+
+func newExporter(endpoint, service string, headers map[string]string) *exporter {
+	return &exporter{
+		endpoint: endpoint,
+		service:  service,
+		headers:  headers,
+		client:   &http.Client{Timeout: config.HTTPTimeout},
+		started:  time.Now(),
+		points:   make(map[string]*point),
+	}
+}
+
+// update applies fn to the point of name and attrs, creating it on first use.
+func (e *exporter) update(name, unit string, histogram bool, attrs []slog.Attr, fn func(*point)) {
+	key := name
+	for _, a := range attrs {
+		key += "|" + a.String()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	p, ok := e.points[key]
+	if !ok {
+		p = &point{name: name, unit: unit, histogram: histogram, attrs: attrs}
+		if histogram {
+			p.buckets = make([]int64, len(config.TelemetryBuckets)+1)
+		}
+		e.points[key] = p
+	}
+	fn(p)
+}
+
+func (e *exporter) addSpan(s *Span, end time.Time) {
+	span := otlpSpan{
+		TraceID:    hex.EncodeToString(s.traceID[:]),
+		SpanID:     hex.EncodeToString(s.spanID[:]),
+		Name:       s.name,
+		Kind:       s.kind,
+		Start:      nanos(s.start),
+		End:        nanos(end),
+		Attributes: otlpAttributes(s.attrs),
+		Status:     otlpStatus{Code: statusOK},
+	}
+	if s.parentID != [8]byte{} {
+		span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	if s.err != nil {
+		span.Status = otlpStatus{Code: statusError, Message: s.err.Error()}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.spans) < config.TelemetryMaxSpans {
+		e.spans = append(e.spans, span)
+	}
+}
+
+// flush sends the queued spans and the current metric values.
+func (e *exporter) flush(ctx context.Context) error {
+	now := nanos(time.Now())
+	resource := otlpResource{Attributes: otlpAttributes([]slog.Attr{
+		slog.String(config.AttrServiceName, e.service),
+		slog.String(config.AttrServiceVersion, config.Version),
+	})}
+	scope := otlpScope{Name: config.AppID, Version: config.Version}
+
+	e.mu.Lock()
+	spans := e.spans
+	e.spans = nil
+	var metrics []otlpMetric
+	for _, p := range e.points {
+		metrics = append(metrics, p.otlp(nanos(e.started), now))
+	}
+	e.mu.Unlock()
+
+	var errs []error
+	if len(spans) > 0 {
+		body := map[string]any{"resourceSpans": []any{map[string]any{
+			"resource":   resource,
+			"scopeSpans": []any{map[string]any{"scope": scope, "spans": spans}},
+		}}}
+		errs = append(errs, e.post(ctx, config.OTLPTracesPath, body))
+	}
+	if len(metrics) > 0 {
+		body := map[string]any{"resourceMetrics": []any{map[string]any{
+			"resource":     resource,
+			"scopeMetrics": []any{map[string]any{"scope": scope, "metrics": metrics}},
+		}}}
+		errs = append(errs, e.post(ctx, config.OTLPMetricsPath, body))
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *exporter) post(ctx context.Context, path string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(config.HeaderContentType, config.MimeJSON)
+	req.Header.Set(config.HeaderUserAgent, config.UserAgent)
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: %s", path, resp.Status)
+	}
+	return nil
+}
+
+func nanos(t time.Time) string {
+	return fmt.Sprint(t.UnixNano())
+}
@@ -0,0 +1,134 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// collector records the OTLP payloads it receives, by path.
+type collector struct {
+	mu       sync.Mutex
+	payloads map[string][]string
+	headers  http.Header
+}
+
+func newCollector(t *testing.T) (*collector, *httptest.Server) {
+	c := &collector{payloads: make(map[string][]string)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		c.mu.Lock()
+		c.payloads[r.URL.Path] = append(c.payloads[r.URL.Path], string(body))
+		c.headers = r.Header.Clone()
+		c.mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return c, srv
+}
+
+func TestTelemetry_Disabled(t *testing.T) {
+	t.Setenv(config.EnvOTLPEndpoint, "")
+	shutdown := Start(context.Background())
+
+	ctx, span := StartSpan(context.Background(), config.SpanSync, KindInternal)
+	assert.Nil(t, span)
+	assert.Empty(t, TraceParent(ctx))
+	span.SetError(errors.New("ignored"))
+	span.End()
+	AddCount(config.MetricSyncCount, config.UnitSyncs, 1)
+	RecordDuration(config.MetricSyncDuration, time.Second)
+
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestTelemetry_Export(t *testing.T) {
+	c, srv := newCollector(t)
+	t.Setenv(config.EnvOTLPEndpoint, srv.URL+"/")
+	t.Setenv(config.EnvOTLPHeaders, "authorization=Bearer abc, x-tenant = home")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	shutdown := Start(ctx)
+
+	parentCtx, parent := StartSpan(context.Background(), config.SpanSync, KindInternal,
+		slog.String(config.AttrSyncMode, config.SourceModeWeb))
+	childCtx, child := StartSpan(parentCtx, config.SpanFetch, KindClient)
+	child.SetError(errors.New("boom"))
+	child.End()
+	parent.End()
+	assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`, TraceParent(childCtx))
+
+	AddCount(config.MetricSyncCount, config.UnitSyncs, 2, slog.String(config.AttrSyncResult, config.SyncResultOK))
+	RecordDuration(config.MetricSyncDuration, 30*time.Millisecond)
+
+	require.NoError(t, shutdown(context.Background()))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	assert.Equal(t, "Bearer abc", c.headers.Get("Authorization"))
+	assert.Equal(t, "home", c.headers.Get("X-Tenant"))
+	assert.Equal(t, config.MimeJSON, c.headers.Get(config.HeaderContentType))
+
+	require.Len(t, c.payloads[config.OTLPTracesPath], 1)
+	var traces struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []otlpSpan `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(c.payloads[config.OTLPTracesPath][0]), &traces))
+	spans := traces.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+	fetch, syncSpan := spans[0], spans[1]
+	assert.Equal(t, config.SpanFetch, fetch.Name)
+	assert.Equal(t, syncSpan.TraceID, fetch.TraceID)
+	assert.Equal(t, syncSpan.SpanID, fetch.ParentSpanID)
+	assert.Empty(t, syncSpan.ParentSpanID)
+	assert.Equal(t, statusError, fetch.Status.Code)
+	assert.Equal(t, "boom", fetch.Status.Message)
+	assert.Equal(t, config.AttrSyncMode, syncSpan.Attributes[0].Key)
+
+	require.Len(t, c.payloads[config.OTLPMetricsPath], 1)
+	metrics := c.payloads[config.OTLPMetricsPath][0]
+	assert.Contains(t, metrics, `"name":"`+config.MetricSyncCount+`"`)
+	assert.Contains(t, metrics, `"asInt":"2"`)
+	assert.Contains(t, metrics, `"name":"`+config.MetricSyncDuration+`"`)
+	// 30 ms falls in the (25, 50] bucket.
+	assert.True(t, strings.Contains(metrics, `"bucketCounts":["0","0","0","1",`), metrics)
+
+	// Telemetry is disabled after shutdown.
+	_, span := StartSpan(context.Background(), config.SpanSync, KindInternal)
+	assert.Nil(t, span)
+}
+
+func TestHandler_RecordsStatus(t *testing.T) {
+	c, srv := newCollector(t)
+	t.Setenv(config.EnvOTLPEndpoint, srv.URL)
+	shutdown := Start(context.Background())
+
+	h := Handler(config.RouteRoot, func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, TraceParent(r.Context()))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/go-birthday.ics", nil))
+	require.NoError(t, shutdown(context.Background()))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	require.Len(t, c.payloads[config.OTLPTracesPath], 1)
+	assert.Contains(t, c.payloads[config.OTLPTracesPath][0], `"key":"`+config.AttrHTTPStatus+`","value":{"intValue":"503"}`)
+	assert.Contains(t, c.payloads[config.OTLPMetricsPath][0], config.MetricHTTPCount)
+}
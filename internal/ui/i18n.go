@@ -3,7 +3,14 @@ package ui
 import (
 	"embed"
 	"encoding/json"
+	"errors"
+	"io/fs"
 	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/nicksnyder/go-i18n/v2/i18n"
@@ -15,11 +22,13 @@ import (
 var localeFS embed.FS
 
 // SetupI18n initializes the translation bundle and detects available languages.
+// Embedded locales are loaded first; files found in the user locales directory
+// (e.g., ~/.config/go-birthday/locales/active.de.json) then add or override translations.
 func (app *GoBirthdayApp) SetupI18n() {
 	bundle := i18n.NewBundle(language.English)
 	bundle.RegisterUnmarshalFunc("json", json.Unmarshal)
 
-	entries, err := localeFS.ReadDir("locales")
+	embedded, err := loadLocales(bundle, localeFS, config.LocalesDir)
 	if err != nil {
 		slog.Error(config.ErrLocalesAccess,
 			config.LogKeyComponent, config.CompI18n,
@@ -28,11 +37,53 @@ func (app *GoBirthdayApp) SetupI18n() {
 		return
 	}
 
-	var detectedLangs []string
+	detectedLangs := embedded
+	if dir := app.userLocalesDir(); dir != "" {
+		external, err := loadLocales(bundle, os.DirFS(dir), ".")
+		if err != nil {
+			// A missing directory is the normal case.
+			slog.Debug(config.MsgLocaleNoUserDir,
+				config.LogKeyComponent, config.CompI18n,
+				config.LogKeyPath, dir,
+			)
+		}
+		for _, lang := range external {
+			if !slices.Contains(detectedLangs, lang) {
+				detectedLangs = append(detectedLangs, lang)
+			}
+		}
+	}
+	sort.Strings(detectedLangs)
+
+	app.SupportedLanguages = detectedLangs
+	app.I18nBundle = bundle
+	app.UpdateLocalizer()
+}
 
+// userLocalesDir returns the directory holding user-provided translations.
+func (app *GoBirthdayApp) userLocalesDir() string {
+	if app.LocalesDir != "" {
+		return app.LocalesDir
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(base, config.UserConfigDirName, config.LocalesDir)
+}
+
+// loadLocales adds every "active.<lang>.json" file of dir to the bundle.
+// It returns the languages that were loaded successfully.
+func loadLocales(bundle *i18n.Bundle, fsys fs.FS, dir string) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var langs []string
 	for _, entry := range entries {
 		name := entry.Name()
-		if !strings.HasPrefix(name, "active.") || !strings.HasSuffix(name, ".json") {
+		if entry.IsDir() || !strings.HasPrefix(name, config.LocalePrefix) || !strings.HasSuffix(name, config.LocaleSuffix) {
 			slog.Debug(config.MsgLocaleSkip,
 				config.LogKeyComponent, config.CompI18n,
 				config.LogKeyFile, name,
@@ -40,8 +91,8 @@ func (app *GoBirthdayApp) SetupI18n() {
 			continue
 		}
 
-		trimmed := strings.TrimPrefix(name, "active.")
-		langCode := strings.TrimSuffix(trimmed, ".json")
+		trimmed := strings.TrimPrefix(name, config.LocalePrefix)
+		langCode := strings.TrimSuffix(trimmed, config.LocaleSuffix)
 
 		if langCode == "" {
 			slog.Warn(config.MsgLocaleBadName,
@@ -51,27 +102,23 @@ func (app *GoBirthdayApp) SetupI18n() {
 			continue
 		}
 
-		detectedLangs = append(detectedLangs, langCode)
-
-		path := "locales/" + name
-		if _, err := bundle.LoadMessageFileFS(localeFS, path); err != nil {
+		if _, err := bundle.LoadMessageFileFS(fsys, path.Join(dir, name)); err != nil {
 			slog.Error(config.ErrLocaleLoad,
 				config.LogKeyComponent, config.CompI18n,
 				config.LogKeyFile, name,
 				config.LogKeyError, err,
 			)
-		} else {
-			slog.Debug(config.MsgLocaleLoaded,
-				config.LogKeyComponent, config.CompI18n,
-				config.LogKeyLang, langCode,
-				config.LogKeyFile, name,
-			)
+			continue
 		}
-	}
 
-	app.SupportedLanguages = detectedLangs
-	app.I18nBundle = bundle
-	app.UpdateLocalizer()
+		slog.Debug(config.MsgLocaleLoaded,
+			config.LogKeyComponent, config.CompI18n,
+			config.LogKeyLang, langCode,
+			config.LogKeyFile, name,
+		)
+		langs = append(langs, langCode)
+	}
+	return langs, nil
 }
 
 // UpdateLocalizer refreshes the translator based on the user's language preference.
@@ -85,17 +132,40 @@ func (app *GoBirthdayApp) UpdateLocalizer() {
 
 // GetMsg is a helper to translate a key safely.
 func (app *GoBirthdayApp) GetMsg(key string) string {
+	return app.localize(&i18n.LocalizeConfig{MessageID: key})
+}
+
+// GetMsgData translates a key whose message uses template data (e.g., {{.Days}}).
+// It falls back to the key itself, like GetMsg.
+func (app *GoBirthdayApp) GetMsgData(key string, data map[string]interface{}) string {
+	return app.localize(&i18n.LocalizeConfig{MessageID: key, TemplateData: data})
+}
+
+// GetMsgCount translates a pluralized key whose message uses {{.Count}}.
+// It falls back to the key itself, like GetMsg.
+func (app *GoBirthdayApp) GetMsgCount(key string, count int) string {
+	return app.localize(&i18n.LocalizeConfig{
+		MessageID:    key,
+		TemplateData: map[string]interface{}{"Count": count},
+		PluralCount:  count,
+	})
+}
+
+// localize resolves a message, returning its ID when it cannot be translated.
+// Keys missing from a partial (e.g., user-provided) locale use the English text.
+func (app *GoBirthdayApp) localize(lc *i18n.LocalizeConfig) string {
 	if app.Localizer == nil {
-		return key
+		return lc.MessageID
 	}
-	msg, err := app.Localizer.Localize(&i18n.LocalizeConfig{MessageID: key})
-	if err != nil {
+	msg, err := app.Localizer.Localize(lc)
+	var notFound *i18n.MessageNotFoundErr
+	if err != nil && (msg == "" || !errors.As(err, &notFound)) {
 		slog.Debug(config.MsgTransMissing,
 			config.LogKeyComponent, config.CompI18n,
-			config.LogKeyKey, key,
+			config.LogKeyKey, lc.MessageID,
 			config.LogKeyError, err,
 		)
-		return key
+		return lc.MessageID
 	}
 	return msg
 }
@@ -66,7 +66,342 @@ func TestI18nIntegrity(t *testing.T) {
 		config.TKeyColAge,
 		config.TKeyFormatDate,
 		config.TKeyAgeBirth, // Correctly added
+		// Push Notifications
+		config.TKeyLblPush,
+		config.TKeyLblPushBackend,
+		config.TKeyPushNone,
+		config.TKeyLblPushServer,
+		config.TKeyHelpPushServer,
+		config.TKeyLblPushTopic,
+		config.TKeyLblPushToken,
+		config.TKeyPushTitle,
+		config.TKeyPushBody,
+		// Contact Photos
+		config.TKeyLblPhotos,
+		config.TKeyHelpPhotos,
+		config.TKeyPhotoNone,
+		config.TKeyPhotoInline,
+		config.TKeyPhotoLink,
+		// Event Properties
+		config.TKeyLblEvents,
+		config.TKeyLblCategories,
+		config.TKeyHelpCategories,
+		config.TKeyLblGroups,
+		config.TKeyLblColor,
+		config.TKeyHelpColor,
+		config.TKeyColorNone,
+		// Summary Template
+		config.TKeyLblSummary,
+		config.TKeyHelpSummary,
+		config.TKeyLblPreview,
+		config.TKeyErrTemplate,
+		// Multiple Reminders
+		config.TKeyBtnAddRem,
+		// Milestones
+		config.TKeyLblMilestones,
+		config.TKeyLblEnableMilestone,
+		config.TKeyLblMilestoneAges,
+		config.TKeyHelpMilestoneAges,
+		config.TKeyLblMilestoneEvery,
+		config.TKeyHelpMilestoneEvery,
+		config.TKeyLblMilestonePrefix,
+		config.TKeyLblMilestoneRem,
+		config.TKeyHelpMilestoneRem,
+		// Category filter
+		config.TKeyLblFilter,
+		config.TKeyHelpFilter,
+		config.TKeyFilterEmpty,
+		// Custom dates
+		config.TKeyEvtDate,
+		config.TKeyEvtDateYears,
+		config.TKeyLabelAnniv,
+		config.TKeyLabelOther,
+		config.TKeyLblCustomDates,
+		// Local directory source
+		config.TKeyBtnFolder,
+		// Birthday overrides
+		config.TKeyWinOverride,
+		config.TKeyLblOverrideDate,
+		config.TKeyHelpOverride,
+		config.TKeyLblYearUnknown,
+		config.TKeyErrOverride,
+		config.TKeyLblContactsHint,
+		// CSV export
+		config.TKeyColDOB,
+		config.TKeyColNext,
+		config.TKeyBtnExportCSV,
+		// Calendar export
+		config.TKeyMenuExport,
+		config.TKeyNotifNoCal,
+		config.TKeyNotifExported,
+		// Subscription URL
+		config.TKeyMenuCopyURL,
+		config.TKeyMenuOpenCalApp,
+		config.TKeyNotifURLCopied,
+		config.TKeyNotifNoCalApp,
+		// Today's birthdays on launch
+		config.TKeyWinToday,
+		config.TKeyLblTodayDismiss,
+		config.TKeyLblTodayWindow,
+		// Snooze
+		config.TKeyMenuSnooze,
+		config.TKeySnoozeHour,
+		config.TKeySnoozeHours,
+		config.TKeySnoozeTomorrow,
+		// Quiet hours
+		config.TKeyLblQuietHours,
+		config.TKeyHelpQuietHours,
+		config.TKeyErrQuietHours,
+		// Drag and drop
+		config.TKeyTitleDrop,
+		config.TKeyConfirmDrop,
+		// Contact details
+		config.TKeyLblShowHidden,
+		config.TKeyLblDOB,
+		config.TKeyLblExactAge,
+		config.TKeyLblDaysLeft,
+		config.TKeyLblContactSrc,
+		config.TKeyLblUID,
+		config.TKeyLblFullName,
+		config.TKeyLblNickname,
+		config.TKeyLblNote,
+		config.TKeyLblPreferNick,
+		config.TKeyLblNameFormat,
+		config.TKeyHelpNameFormat,
+		config.TKeyNameFormatFN,
+		config.TKeyNameFormatGF,
+		config.TKeyNameFormatFG,
+		config.TKeyAgeExact,
+		config.TKeyDaysLeft,
+		config.TKeyDaysToday,
+		config.TKeyBtnHide,
+		config.TKeyBtnUnhide,
+		config.TKeyBtnEditDate,
+		config.TKeyBtnCopy,
+		config.TKeyBtnClose,
+		// Upcoming submenu
+		config.TKeyMenuUpcoming,
+		config.TKeyMenuLastSync,
+		config.TKeyMenuLastSyncFailed,
+		config.TKeyMenuLastSyncNever,
+		config.TKeyMenuLastSyncOffline,
+		config.TKeyMenuNextSync,
+		config.TKeyMenuNextSyncSoon,
+		config.TKeyMenuSyncing,
+		config.TKeyMenuSyncProgress,
+		config.TKeyMenuCancelSync,
+		config.TKeyFormatDay,
+		// Appearance
+		config.TKeyLblTheme,
+		config.TKeyHelpTheme,
+		config.TKeyThemeSystem,
+		config.TKeyThemeLight,
+		config.TKeyThemeDark,
+		// Date Display
+		config.TKeyLblDateFormat,
+		config.TKeyHelpDateFormat,
+		// Countdown column
+		config.TKeyColDays,
+		config.TKeyCountdownDays,
+		config.TKeyCountdownMonths,
+		// Main window (no system tray)
+		config.TKeyBtnContacts,
+		config.TKeyLblNoTray,
+		// Autostart
+		config.TKeyLblAutostart,
+		// Keyboard Shortcuts & Search
+		config.TKeyLblShortcut,
+		config.TKeyHelpShortcut,
+		config.TKeyErrShortcut,
+		config.TKeyPhSearch,
+		// Diagnostics
+		config.TKeyLblDebugLog,
+		config.TKeyLblAccessLog,
+		// Log viewer
+		config.TKeyMenuLogs,
+		config.TKeyWinLogs,
+		config.TKeyLblLogLevel,
+		config.TKeyLogsEmpty,
+		// Synchronization limits
+		config.TKeyLblLimits,
+		config.TKeyHelpLimits,
+		config.TKeyLblMaxDownload,
+		config.TKeyLblMaxContacts,
+		config.TKeyLblMaxCard,
+		config.TKeyUnitMB,
+		config.TKeyUnitSeconds,
+		config.TKeyLblSpacing,
+		config.TKeyHelpSpacing,
+		config.TKeyNotifLimit,
+		// Proxy
+		config.TKeyLblProxy,
+		config.TKeyHelpProxy,
+		config.TKeyLblProxyUser,
+		config.TKeyLblProxyPass,
+		// TLS
+		config.TKeyLblCAFile,
+		config.TKeyHelpCAFile,
+		config.TKeyLblTLSInsecure,
+		// Mutual TLS
+		config.TKeyLblClientCert,
+		config.TKeyHelpClientCert,
+		config.TKeyLblClientKey,
+		// Bearer and OAuth2 authentication
+		config.TKeyLblAuth,
+		config.TKeyAuthBasic,
+		config.TKeyAuthBearer,
+		config.TKeyLblToken,
+		config.TKeyLblTokenURL,
+		config.TKeyHelpTokenURL,
+		config.TKeyLblClientID,
+		config.TKeyLblClientSecret,
+		config.TKeyLblRefreshToken,
+		config.TKeyHelpRefreshToken,
+		// Publishing
+		config.TKeyLblPublish,
+		config.TKeyLblCalDAVEnable,
+		config.TKeyLblCalDAVURL,
+		config.TKeyHelpCalDAVURL,
+		// Output file
+		config.TKeyLblServeHTTP,
+		config.TKeyLblOutputFile,
+		config.TKeyHelpOutputFile,
+		config.TKeyLblCORSOrigin,
+		config.TKeyHelpCORSOrigin,
+		config.TKeyErrCORSOrigin,
+		config.TKeyLblAllowedNets,
+		config.TKeyHelpAllowedNets,
+		config.TKeyErrAllowedNets,
+		// Landing page
+		config.TKeyLandingUpdated,
+		config.TKeyLandingContacts,
+		config.TKeyLandingSubscribe,
+		// WebDAV
+		config.TKeyLblWebDAVEnable,
+		config.TKeyLblWebDAVURL,
+		config.TKeyHelpWebDAVURL,
+		config.TKeyHelpWebDAVToken,
+		// Nextcloud setup
+		config.TKeyBtnNextcloud,
+		config.TKeyWinNextcloud,
+		config.TKeyLblNextcloudURL,
+		config.TKeyHelpNextcloudURL,
+		config.TKeyHelpAppPassword,
+		config.TKeyLblAddressBook,
+		config.TKeyBtnNext,
+		config.TKeyBtnUse,
+		config.TKeyBtnAddressBooks,
+		config.TKeyWinAddressBooks,
+		config.TKeyHelpAddressBooks,
+		config.TKeyLblBooksMerged,
+		// Test connection
+		config.TKeyBtnTestConn,
+		config.TKeyTestConnOK,
+		config.TKeyTestConnSkipped,
+		config.TKeyTestConnEmpty,
+		config.TKeyTestConnParse,
+		config.TKeyTestConnAuth,
+		config.TKeyTestConnNotFound,
+		config.TKeyTestConnStatus,
+		config.TKeyTestConnTLS,
+		config.TKeyTestConnNetwork,
+		config.TKeyTestConnFailed,
+		// Home Assistant
+		config.TKeyHelpHomeAssistant,
+		// JMAP source
+		config.TKeyModeJMAP,
+		config.TKeyHelpJMAPURL,
+		// System contacts
+		config.TKeyModeSystem,
+		config.TKeyNotifDenied,
+		// Thunderbird source
+		config.TKeyModeThunderbird,
+		config.TKeyLblThunderbird,
+		config.TKeyHelpThunderbird,
+		config.TKeyPlaceholderDetected,
+		// Outlook source
+		config.TKeyModeOutlook,
+		config.TKeyNotifOutlook,
+		// Age display
+		config.TKeyLblAgeDisplay,
+		config.TKeyHelpAgeDisplay,
+		config.TKeyAgeTransition,
+		config.TKeyAgeTurning,
+		config.TKeyAgeNone,
+		// Memorials
+		config.TKeyEvtMemorial,
+		config.TKeyEvtMemorialYears,
+		config.TKeyLblMemorials,
+		// Name days
+		config.TKeyEvtNameDay,
+		config.TKeyLblNameDays,
+		config.TKeyHelpNameDays,
+		config.TKeyNameDaysNone,
+		// Zodiac
+		config.TKeyColSign,
+		config.TKeyLblZodiacCol,
+		config.TKeyLblZodiacSum,
+		// Lunar birthdays
+		config.TKeyLblLunar,
+		// Offset anniversaries
+		config.TKeyEvtHalfBirthday,
+		config.TKeyEvtOffsetMonths,
+		config.TKeyEvtOffsetDays,
+		config.TKeyLblOffsets,
+		config.TKeyHelpOffsets,
+		config.TKeyErrOffsets,
+		config.TKeyBtnOffsets,
+		config.TKeyWinOffsets,
+		config.TKeyHelpContactOffsets,
+		// Event class
+		config.TKeyLblClass,
+		config.TKeyHelpClass,
+		config.TKeyClassPublic,
+		config.TKeyClassPrivate,
+		config.TKeyClassConf,
+		// Calendar metadata
+		config.TKeyLblCalendar,
+		config.TKeyLblCalName,
+		config.TKeyLblCalDesc,
+		config.TKeyLblCalColor,
+		config.TKeyHelpCalendar,
+		config.TKeyLblUIDDomain,
+		config.TKeyHelpUIDDomain,
+		config.TKeyErrUIDDomain,
+		// Time zone
+		config.TKeyLblTimeZone,
+		config.TKeyHelpTimeZone,
+		config.TKeyErrTimeZone,
+		// Timed events
+		config.TKeyLblEventTime,
+		config.TKeyHelpEventTime,
+		config.TKeyPhAllDay,
+		config.TKeyLblEventDur,
+		config.TKeyErrEventTime,
+		// To-dos
+		config.TKeyLblTodoDays,
+		config.TKeyHelpTodoDays,
+		// Contact details
+		config.TKeyLblDetails,
+		// Program source
+		config.TKeyModeExec,
+		config.TKeyLblExec,
+		config.TKeyHelpExec,
+		// Sync hooks
+		config.TKeyLblHookSuccess,
+		config.TKeyLblHookFailure,
+		config.TKeyHelpHooks,
+		config.TKeyBtnExportSettings,
+		config.TKeyBtnImportSettings,
+		config.TKeyLblBackupPass,
+		config.TKeyHelpBackupExport,
+		config.TKeyHelpBackupImport,
+		config.TKeyMsgSettingsImported,
+		config.TKeyBtnPreviewCalendar,
+		config.TKeyWinPreviewCalendar,
+		config.TKeyMsgPreviewCalendar,
 	}
+	keysToCheck = append(keysToCheck, config.TKeyZodiacSigns...)
 
 	for _, k := range keysToCheck {
 		definedKeys[k] = true
@@ -103,3 +438,29 @@ func TestI18nIntegrity(t *testing.T) {
 		}
 	}
 }
+
+// TestI18nLocalesComplete ensures that every embedded locale translates every English key.
+func TestI18nLocalesComplete(t *testing.T) {
+	dir := "locales"
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		dir = filepath.Join("..", "..", "internal", "ui", "locales")
+	}
+
+	load := func(lang string) map[string]interface{} {
+		content, err := os.ReadFile(filepath.Join(dir, "active."+lang+".json"))
+		require.NoError(t, err, "Must load locale %s", lang)
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal(content, &m), "JSON must be valid for %s", lang)
+		return m
+	}
+
+	english := load("en")
+	for _, lang := range config.SupportedLanguages {
+		messages := load(lang)
+		for key := range english {
+			_, exists := messages[key]
+			assert.Truef(t, exists, "Key '%s' is missing in active.%s.json", key, lang)
+		}
+		assert.Len(t, messages, len(english), "active.%s.json has extra keys", lang)
+	}
+}
@@ -1,11 +1,20 @@
 package ui
 
 import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
 )
 
 // TestApp_LoadSyncConfig_Reminders tests the conversion of UI preferences to Engine config.
@@ -69,7 +78,342 @@ func TestApp_LoadSyncConfig_Reminders(t *testing.T) {
 			cfg := app.loadSyncConfig()
 
 			// Verify
-			assert.Equal(t, tt.wantTrigger, cfg.ReminderTrigger)
+			if tt.wantTrigger == "" {
+				assert.Empty(t, cfg.ReminderTriggers)
+			} else {
+				assert.Equal(t, []string{tt.wantTrigger}, cfg.ReminderTriggers)
+			}
 		})
 	}
 }
+
+// TestApp_LoadSyncConfig_MultipleReminders verifies that the trigger list takes
+// precedence over the legacy single-reminder preferences.
+func TestApp_LoadSyncConfig_MultipleReminders(t *testing.T) {
+	a := test.NewApp()
+	app := &GoBirthdayApp{
+		App:         a,
+		Preferences: a.Preferences(),
+	}
+
+	app.Preferences.SetBool(config.PrefReminderEnabled, true)
+	app.Preferences.SetInt(config.PrefReminderValue, 3)
+	app.Preferences.SetStringList(config.PrefReminderTriggers, []string{"-P7D", "-P1D"})
+
+	cfg := app.loadSyncConfig()
+	assert.Equal(t, []string{"-P7D", "-P1D"}, cfg.ReminderTriggers)
+}
+
+// TestTrigger_RoundTrip verifies that reminder rows survive a save/load cycle.
+func TestTrigger_RoundTrip(t *testing.T) {
+	tests := []struct {
+		val       int
+		unit, dir string
+		want      string
+	}{
+		{7, config.UnitDays, config.DirBefore, "-P7D"},
+		{2, config.UnitHours, config.DirAfter, "P2H"},
+		{30, config.UnitMinutes, config.DirBefore, "-P30M"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			trigger := formatTrigger(tt.val, tt.unit, tt.dir)
+			assert.Equal(t, tt.want, trigger)
+
+			val, unit, dir, ok := parseTrigger(trigger)
+			assert.True(t, ok)
+			assert.Equal(t, tt.val, val)
+			assert.Equal(t, tt.unit, unit)
+			assert.Equal(t, tt.dir, dir)
+		})
+	}
+
+	_, _, _, ok := parseTrigger("garbage")
+	assert.False(t, ok)
+}
+
+// TestApp_LoadSyncConfig_EventProperties verifies categories and color mapping.
+func TestApp_LoadSyncConfig_EventProperties(t *testing.T) {
+	a := test.NewApp()
+	app := &GoBirthdayApp{
+		App:         a,
+		Preferences: a.Preferences(),
+	}
+
+	// Default: the "Birthday" category, no color.
+	cfg := app.loadSyncConfig()
+	assert.Equal(t, []string{config.DefaultCategory}, cfg.Categories)
+	assert.Empty(t, cfg.Color)
+
+	app.Preferences.SetString(config.PrefCategories, " Family , ,Friends")
+	app.Preferences.SetBool(config.PrefContactGroups, true)
+	app.Preferences.SetString(config.PrefEventColor, "teal")
+
+	cfg = app.loadSyncConfig()
+	assert.Equal(t, []string{"Family", "Friends"}, cfg.Categories)
+	assert.True(t, cfg.ContactGroups)
+	assert.Equal(t, "teal", cfg.Color)
+
+	// An explicitly empty list disables categories.
+	app.Preferences.SetString(config.PrefCategories, "")
+	assert.Empty(t, app.loadSyncConfig().Categories)
+}
+
+// TestApp_LoadSyncConfig_Milestones verifies milestone settings mapping.
+func TestApp_LoadSyncConfig_Milestones(t *testing.T) {
+	a := test.NewApp()
+	app := &GoBirthdayApp{
+		App:         a,
+		Preferences: a.Preferences(),
+	}
+
+	// Disabled by default: no emphasis at all.
+	cfg := app.loadSyncConfig()
+	assert.Empty(t, cfg.Milestones)
+	assert.Zero(t, cfg.MilestoneEvery)
+
+	app.Preferences.SetBool(config.PrefMilestoneEnabled, true)
+	cfg = app.loadSyncConfig()
+	assert.Equal(t, []int{18, 21}, cfg.Milestones)
+	assert.Equal(t, config.DefaultMilestoneEvery, cfg.MilestoneEvery)
+	assert.Equal(t, config.DefaultMilestonePrefix, cfg.MilestonePrefix)
+	assert.Equal(t, "-P14D", cfg.MilestoneTrigger)
+
+	// Invalid ages are skipped, a zero delay disables the early reminder.
+	app.Preferences.SetString(config.PrefMilestoneAges, "16, abc, -3, 65")
+	app.Preferences.SetInt(config.PrefMilestoneReminder, 0)
+	cfg = app.loadSyncConfig()
+	assert.Equal(t, []int{16, 65}, cfg.Milestones)
+	assert.Empty(t, cfg.MilestoneTrigger)
+}
+
+// TestApp_Overrides verifies persistence of birthday corrections.
+func TestApp_Overrides(t *testing.T) {
+	a := test.NewApp()
+	app := &GoBirthdayApp{
+		App:         a,
+		Preferences: a.Preferences(),
+	}
+
+	assert.Empty(t, app.loadSyncConfig().Overrides)
+
+	one := engine.BirthdayEntry{UID: "uid-1", OverrideKey: "uid-1"}
+	two := engine.BirthdayEntry{UID: "uid-2", OverrideKey: "urn:uuid:a=b"}
+	app.setOverride(one, formatOverride(time.Date(1990, 6, 11, 0, 0, 0, 0, time.UTC), true))
+	app.setOverride(two, formatOverride(time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC), false))
+	app.setOverride(one, "1991-06-11") // Replaces the previous value
+
+	assert.Equal(t, map[string]string{"uid-1": "1991-06-11", "urn:uuid:a=b": "--02-29"}, app.loadSyncConfig().Overrides)
+
+	// An empty date removes the correction.
+	app.setOverride(one, "")
+	assert.Equal(t, map[string]string{"urn:uuid:a=b": "--02-29"}, app.overrides())
+
+	// A correction saved under the contact UID by earlier versions moves to the vCard UID.
+	app.Preferences.SetStringList(config.PrefOverrides, []string{"uid-3=1980-01-01"})
+	app.setOverride(engine.BirthdayEntry{UID: "uid-3", OverrideKey: "vcard-3"}, "1980-01-02")
+	assert.Equal(t, map[string]string{"vcard-3": "1980-01-02"}, app.overrides())
+}
+
+// TestApp_Offsets verifies the offset anniversaries of every contact and of single contacts.
+func TestApp_Offsets(t *testing.T) {
+	a := test.NewApp()
+	app := &GoBirthdayApp{
+		App:         a,
+		Preferences: a.Preferences(),
+	}
+
+	app.Preferences.SetString(config.PrefOffsets, "6m, bogus")
+	app.setContactOffsets("uid-1", []engine.Offset{{Days: 100}, {Days: 1000}})
+	app.setContactOffsets("uid-2", []engine.Offset{{Days: 100}})
+	app.setContactOffsets("uid-2", nil) // Removes them
+
+	cfg := app.loadSyncConfig()
+	assert.Equal(t, []engine.Offset{{Months: 6}}, cfg.Offsets, "Invalid offsets are skipped")
+	assert.Equal(t, map[string][]engine.Offset{"uid-1": {{Days: 100}, {Days: 1000}}}, cfg.ContactOffsets)
+	assert.Equal(t, []string{"uid-1=100d,1000d"}, app.Preferences.StringList(config.PrefContactOffsets))
+
+	assert.NoError(t, app.validateOffsets(" 6m,100D "))
+	assert.Error(t, app.validateOffsets("6m, 2y"))
+}
+
+// TestDroppedVCard verifies which dropped items are accepted as a source.
+func TestDroppedVCard(t *testing.T) {
+	_, ok := droppedVCard([]fyne.URI{storage.NewFileURI("/tmp/notes.txt")})
+	assert.False(t, ok)
+
+	_, ok = droppedVCard(nil)
+	assert.False(t, ok)
+
+	path, ok := droppedVCard([]fyne.URI{
+		storage.NewFileURI("/tmp/photo.jpg"),
+		storage.NewFileURI("/tmp/Contacts.VCF"),
+		storage.NewFileURI("/tmp/other.vcf"),
+	})
+	assert.True(t, ok)
+	assert.Equal(t, "/tmp/Contacts.VCF", path, "The first vCard wins")
+}
+
+// TestExactAge verifies month/day borrowing, including leap days.
+func TestExactAge(t *testing.T) {
+	tests := []struct {
+		name         string
+		birth, now   time.Time
+		wantY, wantM int
+		wantD        int
+	}{
+		{"Birthday Today", time.Date(1990, 6, 11, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 11, 9, 0, 0, 0, time.UTC), 35, 0, 0},
+		{"Day Before Birthday", time.Date(1990, 6, 11, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC), 34, 11, 30},
+		{"Borrow February", time.Date(2000, 1, 31, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), 25, 1, 1},
+		{"Leap Day", time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), 25, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			y, m, d := exactAge(tt.birth, tt.now)
+			assert.Equal(t, []int{tt.wantY, tt.wantM, tt.wantD}, []int{y, m, d})
+		})
+	}
+
+	now := time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, 0, daysUntil(time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), now))
+	assert.Equal(t, 1, daysUntil(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), now))
+}
+
+// TestApp_HiddenContacts verifies the hidden list persistence and its mapping to the engine.
+func TestApp_HiddenContacts(t *testing.T) {
+	a := test.NewApp()
+	app := &GoBirthdayApp{
+		App:         a,
+		Preferences: a.Preferences(),
+	}
+
+	app.setHidden("uid-1", true)
+	app.setHidden("uid-2", true)
+	app.setHidden("uid-1", false)
+	assert.Equal(t, map[string]bool{"uid-2": true}, app.loadSyncConfig().Hidden)
+}
+
+func TestDateLayout(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.Local)
+	tests := map[string]string{
+		"dd/MM/yyyy": "05/03/2024",
+		"MM/dd/yyyy": "03/05/2024",
+		"yyyy-MM-dd": "2024-03-05",
+		"d MMM yy":   "5 Mar 24",
+		"MMMM d":     "March 5",
+		"EEE d MMM":  "Tue 5 Mar",
+		"EEEE":       "Tuesday",
+	}
+	for pattern, want := range tests {
+		assert.Equal(t, want, date.Format(goDateLayout(pattern)), pattern)
+	}
+
+	// The preference overrides the localized format; empty falls back to it.
+	a := test.NewApp()
+	app := &GoBirthdayApp{App: a, Preferences: a.Preferences()}
+	assert.Equal(t, config.DateFormatDisplay, app.dateLayout(config.TKeyFormatDate, config.DateFormatDisplay))
+	a.Preferences().SetString(config.PrefDateFormat, "dd/MM/yyyy")
+	assert.Equal(t, "05/03/2024", app.formatDate(date))
+	assert.Equal(t, "02/01/2006", app.dateLayout(config.TKeyFormatDay, config.DateFormatDay))
+}
+
+func TestParseShortcut(t *testing.T) {
+	s, ok := parseShortcut("Ctrl+Shift+b")
+	assert.True(t, ok)
+	assert.Equal(t, fyne.KeyB, s.KeyName)
+	assert.Equal(t, fyne.KeyModifierShortcutDefault|fyne.KeyModifierShift, s.Modifier)
+
+	s, ok = parseShortcut("alt + F5")
+	assert.True(t, ok)
+	assert.Equal(t, fyne.KeyF5, s.KeyName)
+	assert.Equal(t, fyne.KeyModifierAlt, s.Modifier)
+
+	for _, invalid := range []string{"", "B", "Ctrl+", "Hyper+B", "Ctrl+Space", "Ctrl+F13"} {
+		_, ok := parseShortcut(invalid)
+		assert.False(t, ok, invalid)
+	}
+
+	// An invalid preference falls back to the default shortcut.
+	a := test.NewApp()
+	app := &GoBirthdayApp{App: a, Preferences: a.Preferences()}
+	a.Preferences().SetString(config.PrefContactsShortcut, "nonsense")
+	assert.Equal(t, fyne.KeyB, app.contactsShortcut().KeyName)
+}
+
+func TestReadLogTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), config.LogFileName)
+	lines := []string{
+		`{"time":"2024-03-05T10:00:00Z","level":"DEBUG","msg":"d"}`,
+		`{"time":"2024-03-05T10:00:01Z","level":"INFO","msg":"i"}`,
+		`panic: not json`,
+		`{"time":"2024-03-05T10:00:02Z","level":"WARN","msg":"w"}`,
+		`{"time":"2024-03-05T10:00:03Z","level":"ERROR","msg":"e"}`,
+	}
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), config.FilePermUserRW))
+
+	got, err := readLogTail(path, slog.LevelDebug, config.LogViewerMaxLines)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join(lines, "\n"), got)
+
+	// Filtering keeps non-JSON lines, the limit keeps the most recent ones.
+	got, err = readLogTail(path, slog.LevelWarn, config.LogViewerMaxLines)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join(lines[2:], "\n"), got)
+
+	got, err = readLogTail(path, slog.LevelDebug, 1)
+	require.NoError(t, err)
+	assert.Equal(t, lines[4], got)
+
+	_, err = readLogTail("", slog.LevelInfo, config.LogViewerMaxLines)
+	assert.Error(t, err)
+}
+
+// TestValidateCORSOrigin verifies the origins accepted for browser-based clients.
+func TestValidateCORSOrigin(t *testing.T) {
+	app := &GoBirthdayApp{}
+	for _, s := range []string{"", " * ", "https://dash.example.org", "http://192.168.1.10:8123"} {
+		assert.NoError(t, app.validateCORSOrigin(s), s)
+	}
+	for _, s := range []string{"dash.example.org", "ftp://example.org", "https://example.org/app", "https://user@example.org", "https://"} {
+		assert.Error(t, app.validateCORSOrigin(s), s)
+	}
+}
+
+func TestSubscriptionURL(t *testing.T) {
+	assert.Equal(t, "webcal://127.0.0.1:18080/birthdays.ics", subscriptionURL("127.0.0.1:18080").String())
+	assert.Equal(t, "webcal://127.0.0.1:18080/birthdays.ics", subscriptionURL("0.0.0.0:18080").String(), "Every interface, through the loopback")
+	assert.Equal(t, "webcal://[fd00::1]:18080/birthdays.ics", subscriptionURL("[fd00::1]:18080").String())
+	assert.Equal(t, "webcal://127.0.0.1:18080/birthdays.ics", subscriptionURL("[::]:18080").String())
+	assert.Equal(t, "webcal://127.0.0.1:18080/birthdays.ics", subscriptionURL("18080").String(), "A port alone")
+}
+
+func TestQuietHours(t *testing.T) {
+	_, ok := parseQuietHours("", "08:00")
+	assert.False(t, ok, "Disabled without a start")
+	_, ok = parseQuietHours("22:00", "22:00")
+	assert.False(t, ok, "Disabled when empty")
+
+	at := func(h, m int) time.Time { return time.Date(2025, 3, 10, h, m, 0, 0, time.UTC) }
+
+	night, ok := parseQuietHours("22:00", " 08:00 ")
+	require.True(t, ok)
+	end, quiet := night.until(at(23, 30))
+	assert.True(t, quiet)
+	assert.Equal(t, time.Date(2025, 3, 11, 8, 0, 0, 0, time.UTC), end, "Over midnight, it ends tomorrow")
+	end, quiet = night.until(at(7, 59))
+	assert.True(t, quiet)
+	assert.Equal(t, at(8, 0), end)
+	_, quiet = night.until(at(8, 0))
+	assert.False(t, quiet, "The end is not quiet anymore")
+	_, quiet = night.until(at(12, 0))
+	assert.False(t, quiet)
+
+	lunch, ok := parseQuietHours("12:00", "13:30")
+	require.True(t, ok)
+	end, quiet = lunch.until(at(12, 0))
+	assert.True(t, quiet)
+	assert.Equal(t, at(13, 30), end)
+	_, quiet = lunch.until(at(23, 0))
+	assert.False(t, quiet)
+}
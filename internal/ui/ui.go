@@ -1,20 +1,33 @@
 package ui
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/tartampluch/go-birthday/internal/config"
 	"github.com/tartampluch/go-birthday/internal/engine"
+	"github.com/tartampluch/go-birthday/internal/publish"
+	"github.com/tartampluch/go-birthday/internal/secrets"
 	"github.com/tartampluch/go-birthday/internal/server"
-	"github.com/zalando/go-keyring"
 )
 
 //go:embed Icon.png
@@ -39,14 +52,81 @@ type GoBirthdayApp struct {
 	TrayStatusItem   *fyne.MenuItem
 	TrayRefreshItem  *fyne.MenuItem
 	TraySettingsItem *fyne.MenuItem
+	TrayExportItem   *fyne.MenuItem
+	TrayUpcomingItem *fyne.MenuItem
+	TrayLogsItem     *fyne.MenuItem
+	TrayCopyURLItem  *fyne.MenuItem // Copies the webcal:// address of the calendar
+	TrayOpenCalItem  *fyne.MenuItem // Subscribes through the calendar app of the system
+	TraySnoozeItem   *fyne.MenuItem // Shows the birthday notification again later
+	TrayLastSyncItem *fyne.MenuItem // Time and outcome of the last synchronization
+	TrayNextSyncItem *fyne.MenuItem // Countdown to the next scheduled synchronization
+	TrayCancelItem   *fyne.MenuItem // Cancels the synchronization in progress
+	trayIconCount    int            // Count currently drawn on the tray icon badge
+
+	// WindowMode replaces the system tray with a main window (--window flag).
+	WindowMode bool
+	mainWindow fyne.Window
+	mainStatus *widget.Label
+	// mainShortcut is the contacts shortcut registered on the main window.
+	mainShortcut *desktop.CustomShortcut
+
+	// Daemon runs without tray or window (--daemon), reporting to systemd.
+	Daemon bool
 
 	SupportedLanguages []string
-	configChan         chan string
+	LocalesDir         string // User translations, defaults to <config dir>/go-birthday/locales
+	CacheDir           string // Files kept between runs, defaults to the log directory
+	SecretsDir         string // Encrypted secrets without a keyring, defaults to <config dir>/go-birthday
+
+	// Logging: the level can be changed at runtime from the settings.
+	LogLevel   *slog.LevelVar
+	DebugFlag  bool   // --debug forces the debug level
+	Validate   bool   // --validate checks every generated calendar against RFC 5545
+	LogPath    string // Current log file, shown by the log viewer
+	logsWindow fyne.Window
+
+	configChan chan string
+
+	// Last successful synchronization, for conditional requests.
+	syncMut  sync.Mutex
+	lastSync syncState
+	syncedAt time.Time // End of the last synchronization, successful or not
+	syncOK   bool
+	offline  bool                // The last synchronization failed for lack of network
+	nextSync time.Time           // Next tick of the worker, zero before it is scheduled
+	running  *syncRun            // Synchronization in progress, nil when idle
+	oauth2   *engine.TokenSource // Kept between synchronizations for its access token
+	caldav   *publish.CalDAV     // Kept between synchronizations for the events already published
+	webdav   *publish.WebDAV     // Kept between synchronizations for the ETag of the last upload
+	hooks    sync.WaitGroup      // Sync hooks still running
+
+	// Notifications held during the quiet hours, delivered by quietTimer at their end.
+	quietMu    sync.Mutex
+	quietQueue []func()
+	quietTimer *time.Timer
+
+	// sendClickable shows the notifications opening a view when clicked, nil where unsupported.
+	sendClickable clickSender
+
+	// Last birthday notification, shown again by snoozeTimer when snoozed from the tray.
+	snoozeMu    sync.Mutex
+	snoozeDay   string
+	snoozeNames []string
+	snoozeUID   string // Contact shown when the notification is clicked
+	snoozeTimer *time.Timer
+
+	// todayShown is set once today's birthdays were listed on launch (showTodayWindow).
+	todayShown atomic.Bool
+
+	// serverFailed is set while the HTTP server is down after failing to start (e.g., a busy port).
+	serverFailed atomic.Bool
 
 	// Contacts State
 	ContactsMut    sync.RWMutex
 	Contacts       []engine.BirthdayEntry
 	contactsWindow fyne.Window
+	contactsFocus  func(uid string)         // Set while the contacts window is open
+	contactsThemed *container.ThemeOverride // Contacts table with larger text, while open
 }
 
 // NewGoBirthdayApp constructs the application and wires dependencies.
@@ -63,46 +143,75 @@ func NewGoBirthdayApp(a fyne.App, ctx context.Context, srv *server.CalendarServe
 		SupportedLanguages: config.SupportedLanguages,
 		configChan:         make(chan string, config.ChannelBufferSize),
 		Contacts:           make([]engine.BirthdayEntry, 0),
+		sendClickable:      newClickSender(),
 	}
 }
 
 // Run launches the application services and the main UI loop.
 func (app *GoBirthdayApp) Run() {
 	app.SetupI18n()
+	app.ApplyLogLevel()
+	_, _ = app.openSecrets() // Logged; secrets are then unavailable
+	app.applyTheme()
 	app.watchPreferences()
+	app.loadCalendarCache()
+	app.loadRevisionsCache()
 
 	go func() {
-		slog.Info(config.MsgServerListen,
-			config.LogKeyPort, app.Server.Port,
-			config.LogKeyComponent, config.CompUI)
-
-		if err := app.Server.Start(app.Ctx); err != nil {
-			slog.Error(config.ErrServerStartup,
-				config.LogKeyError, err,
-				config.LogKeyComponent, config.CompUI)
-
-			app.App.SendNotification(fyne.NewNotification(
-				config.TitleStartupError,
-				fmt.Sprintf(config.MsgPortBusy, app.Server.Port)))
+		if !app.Preferences.BoolWithFallback(config.PrefServeHTTP, true) {
+			slog.Info(config.MsgServerDisabled, config.LogKeyComponent, config.CompUI)
+			<-app.Ctx.Done()
+			app.Server.Close()
+			return
 		}
+
+		app.startServer()
 	}()
 
-	if desk, ok := app.App.(desktop.App); ok {
+	if desk, ok := app.App.(desktop.App); ok && !app.WindowMode {
 		app.Tray = desk
 		app.Tray.SetSystemTrayIcon(app.App.Icon())
 		app.setupTrayMenu()
 	} else {
-		slog.Warn(config.ErrTrayNotSupported,
-			config.LogKeyComponent, config.CompUI)
+		if !ok {
+			slog.Warn(config.ErrTrayNotSupported,
+				config.LogKeyComponent, config.CompUI)
+		}
+		// Without a tray, the same actions are offered in a main window.
+		app.setupTrayMenu()
+		app.showMainWindow()
 	}
 
+	// Populate the contacts and the tray before the first synchronization completes.
+	app.loadContactsCache()
+	app.ContactsMut.RLock()
+	app.showTodayWindow(app.Contacts)
+	app.ContactsMut.RUnlock()
+
 	go app.backgroundWorker()
 	app.App.Run()
 }
 
+// SyncOnce performs a single synchronization without any window or server (--sync-once),
+// for scripted refreshes: the cache, the publishing targets, the push notification and the
+// hooks are updated as by the running application. It returns once the hooks have finished.
+func (app *GoBirthdayApp) SyncOnce() error {
+	app.SetupI18n()
+	app.ApplyLogLevel()
+	_, _ = app.openSecrets() // Logged; secrets are then unavailable
+	app.loadRevisionsCache()
+
+	err := app.performSync(false)
+	app.hooks.Wait()
+	return err
+}
+
 // watchPreferences monitors changes to settings to trigger immediate updates.
+// The settings of the server apply at once.
 func (app *GoBirthdayApp) watchPreferences() {
+	app.applyServerSettings()
 	app.Preferences.AddChangeListener(func() {
+		app.applyServerSettings()
 		select {
 		case app.configChan <- config.PrefInterval:
 		default:
@@ -123,17 +232,54 @@ func (app *GoBirthdayApp) setupTrayMenu() {
 		go app.performSync(true)
 	})
 
+	app.TrayCancelItem = fyne.NewMenuItem(app.GetMsg(config.TKeyMenuCancelSync), app.CancelSync)
+	app.TrayCancelItem.Disabled = true
+
 	app.TraySettingsItem = fyne.NewMenuItem(app.GetMsg(config.TKeyMenuSettings), func() {
 		app.ShowSettingsWindow()
 	})
 
+	app.TrayExportItem = fyne.NewMenuItem(app.GetMsg(config.TKeyMenuExport), func() {
+		app.ShowExportCalendar()
+	})
+
+	app.TrayLogsItem = fyne.NewMenuItem(app.GetMsg(config.TKeyMenuLogs), func() {
+		app.ShowLogsWindow()
+	})
+
+	app.TrayCopyURLItem = fyne.NewMenuItem(app.GetMsg(config.TKeyMenuCopyURL), app.CopyCalendarURL)
+	app.TrayOpenCalItem = fyne.NewMenuItem(app.GetMsg(config.TKeyMenuOpenCalApp), app.OpenCalendarApp)
+
+	// Filled after each synchronization by updateUpcomingMenu.
+	app.TrayUpcomingItem = fyne.NewMenuItem(app.GetMsg(config.TKeyMenuUpcoming), nil)
+	app.TrayUpcomingItem.ChildMenu = fyne.NewMenu("")
+	app.TrayUpcomingItem.Disabled = true
+
+	app.TraySnoozeItem = app.newSnoozeItem()
+
+	// Information only, labeled by updateSyncItems.
+	app.TrayLastSyncItem = fyne.NewMenuItem("", nil)
+	app.TrayLastSyncItem.Disabled = true
+	app.TrayNextSyncItem = fyne.NewMenuItem("", nil)
+	app.TrayNextSyncItem.Disabled = true
+
 	app.Menu = fyne.NewMenu(config.AppName,
 		app.TrayStatusItem,
+		app.TrayLastSyncItem,
+		app.TrayNextSyncItem,
+		app.TrayUpcomingItem,
+		app.TraySnoozeItem,
 		fyne.NewMenuItemSeparator(),
 		app.TrayRefreshItem,
+		app.TrayCancelItem,
+		app.TrayExportItem,
+		app.TrayCopyURLItem,
+		app.TrayOpenCalItem,
 		app.TraySettingsItem,
+		app.TrayLogsItem,
 	)
 
+	app.updateSyncItems()
 	if app.Tray != nil {
 		app.Tray.SetSystemTrayMenu(app.Menu)
 	}
@@ -145,18 +291,28 @@ func (app *GoBirthdayApp) RefreshTrayMenu() {
 		return
 	}
 	app.TrayRefreshItem.Label = app.GetMsg(config.TKeyMenuRefresh)
+	app.TrayCancelItem.Label = app.GetMsg(config.TKeyMenuCancelSync)
 	app.TraySettingsItem.Label = app.GetMsg(config.TKeyMenuSettings)
-	app.Menu.Refresh()
+	app.TrayExportItem.Label = app.GetMsg(config.TKeyMenuExport)
+	app.TrayUpcomingItem.Label = app.GetMsg(config.TKeyMenuUpcoming)
+	app.TrayLogsItem.Label = app.GetMsg(config.TKeyMenuLogs)
+	app.TrayCopyURLItem.Label = app.GetMsg(config.TKeyMenuCopyURL)
+	app.TrayOpenCalItem.Label = app.GetMsg(config.TKeyMenuOpenCalApp)
+	app.refreshSnoozeLabels()
+	app.updateSyncItems() // Refreshes the menu
+
+	if app.mainWindow != nil {
+		app.mainWindow.SetContent(app.buildMainContent())
+		app.updateContactsShortcut()
+	}
 }
 
 // backgroundWorker manages the periodic synchronization schedule.
 func (app *GoBirthdayApp) backgroundWorker() {
 	log := slog.With(config.LogKeyComponent, config.CompWorker)
 
-	app.performSync(false)
-
 	getInterval := func() time.Duration {
-		val := app.Preferences.IntWithFallback(config.PrefInterval, config.DefaultRefreshMin)
+		val := app.refreshInterval(app.Preferences.String(config.PrefSourceMode))
 		if val <= 0 {
 			val = config.DefaultRefreshMin
 		}
@@ -166,6 +322,91 @@ func (app *GoBirthdayApp) backgroundWorker() {
 	currentDuration := getInterval()
 	ticker := time.NewTicker(currentDuration)
 	defer ticker.Stop()
+	tickAt := time.Now().Add(currentDuration)
+
+	// Offline (e.g., just after waking from sleep), the synchronization is retried sooner, with
+	// a growing delay, until the network is back; the ticker then goes on as usual.
+	retry := time.NewTimer(config.OfflineRetryBase)
+	retry.Stop()
+	defer retry.Stop()
+	var retryDelay time.Duration // 0 when online
+	// A server answering with Retry-After is not contacted again before, by the ticker either.
+	var holdUntil time.Time
+	syncNow := func() {
+		err := app.performSync(false)
+		var status *engine.StatusError
+		switch {
+		case errors.As(err, &status) && status.RetryAfter > 0:
+			wait := min(status.RetryAfter, config.RetryAfterMax)
+			log.Info(config.MsgSyncDeferred, config.LogKeyDelay, wait)
+			holdUntil = time.Now().Add(wait)
+			retry.Reset(wait)
+			app.scheduleSync(wait)
+			return
+		case app.isOffline():
+			retryDelay = min(max(2*retryDelay, config.OfflineRetryBase), config.OfflineRetryMax, currentDuration)
+			log.Info(config.MsgOffline, config.LogKeyDelay, retryDelay)
+			retry.Reset(retryDelay)
+			app.scheduleSync(retryDelay)
+			return
+		}
+		if retryDelay > 0 {
+			log.Info(config.MsgOnline)
+			retryDelay = 0
+		}
+		retry.Stop()
+		app.scheduleSync(time.Until(tickAt))
+	}
+	syncNow()
+
+	// Keeps the countdown of the tray current.
+	countdown := time.NewTicker(config.SyncInfoRefresh)
+	defer countdown.Stop()
+
+	// Local sources are also resynchronized as soon as they change on disk.
+	var watcher *engine.Watcher
+	var watchedPath string
+	defer func() {
+		if watcher != nil {
+			_ = watcher.Close()
+		}
+	}()
+	updateWatcher := func() {
+		path := ""
+		switch app.Preferences.String(config.PrefSourceMode) {
+		case config.SourceModeLocal:
+			path = app.Preferences.String(config.PrefLocalPath)
+		case config.SourceModeThunderbird:
+			// The personal address book, where contacts are usually edited.
+			if books, err := engine.ThunderbirdBooks(app.Preferences.String(config.PrefThunderbirdPath)); err == nil {
+				path = books[0]
+			}
+		}
+		if path == watchedPath && (watcher != nil || path == "") {
+			return
+		}
+		if watcher != nil {
+			_ = watcher.Close()
+			watcher = nil
+		}
+		watchedPath = path
+		if path == "" {
+			return
+		}
+		w, err := engine.NewWatcher(path, config.WatchDebounce)
+		if err != nil {
+			log.Warn(config.ErrWatcher, config.LogKeyFile, path, config.LogKeyError, err)
+			return
+		}
+		watcher = w
+	}
+	watchChan := func() <-chan struct{} {
+		if watcher == nil {
+			return nil // Blocks forever in select
+		}
+		return watcher.C()
+	}
+	updateWatcher()
 
 	log.Info(config.MsgWorkerStart, config.LogKeyInterval, currentDuration)
 
@@ -181,58 +422,292 @@ func (app *GoBirthdayApp) backgroundWorker() {
 				log.Info(config.MsgUpdateSync, config.LogKeyOld, currentDuration, config.LogKeyNew, newDuration)
 				currentDuration = newDuration
 				ticker.Reset(currentDuration)
+				tickAt = time.Now().Add(currentDuration)
+				if retryDelay == 0 && time.Now().After(holdUntil) {
+					app.scheduleSync(currentDuration)
+				}
 			}
+			updateWatcher()
+
+		case <-watchChan():
+			log.Info(config.MsgLocalChanged, config.LogKeyFile, watchedPath)
+			syncNow()
 
 		case <-ticker.C:
-			app.performSync(false)
+			tickAt = time.Now().Add(currentDuration)
+			if time.Now().Before(holdUntil) {
+				log.Debug(config.MsgSyncHeld)
+				continue
+			}
+			syncNow()
+
+		case <-retry.C:
+			syncNow()
+
+		case <-countdown.C:
+			app.updateSyncItems()
 		}
 	}
 }
 
+// refreshInterval returns the minutes between two synchronizations of the source mode, or of
+// any source when it has none of its own (0 when disabled).
+func (app *GoBirthdayApp) refreshInterval(mode string) int {
+	return app.Preferences.IntWithFallback(fmt.Sprintf(config.PrefIntervalOf, mode),
+		app.Preferences.IntWithFallback(config.PrefInterval, config.DefaultRefreshMin))
+}
+
+// generator returns a generator of the calendar, with the summaries of the preferences.
+func (app *GoBirthdayApp) generator() *engine.Generator {
+	// Use the app's injected clock (Real or Mock)
+	return &engine.Generator{
+		Clock:                 app.Clock,
+		Fetcher:               app.Fetcher,
+		FormatSummary:         app.buildSummaryFormatter(),
+		FormatDateSummary:     app.dateSummaryFormatter,
+		FormatMemorialSummary: app.memorialSummaryFormatter,
+		FormatNameDaySummary:  app.nameDaySummaryFormatter,
+		FormatOffsetSummary:   app.offsetSummaryFormatter,
+	}
+}
+
 // performSync executes the business logic pipeline (Fetch -> Parse -> Generate).
-func (app *GoBirthdayApp) performSync(manual bool) {
+// It returns the error of the synchronization or of the publishing, already logged.
+func (app *GoBirthdayApp) performSync(manual bool) error {
 	slog.Info(config.MsgSyncReq,
 		config.LogKeyComponent, config.CompUI,
 		config.LogKeyManual, manual)
 
 	if manual {
-		app.App.SendNotification(fyne.NewNotification(config.AppName, app.GetMsg(config.TKeyNotifStart)))
+		app.notify(config.AppName, app.GetMsg(config.TKeyNotifStart), nil)
 	}
 
 	cfg := app.loadSyncConfig()
+	key := app.syncKey(cfg)
 
-	// Use the app's injected clock (Real or Mock)
-	gen := &engine.Generator{
-		Clock:         app.Clock,
-		Fetcher:       app.Fetcher,
-		FormatSummary: app.buildSummaryFormatter(),
+	// Canceled from the tray, without stopping the application.
+	ctx, run := app.startSync()
+	defer app.endSync(run)
+
+	gen := app.generator()
+	gen.Progress = func(p engine.Progress) { app.syncProgress(run, p) }
+
+	// Only ask "has it changed?" if the previous calendar was built from the same inputs.
+	app.syncMut.Lock()
+	if app.lastSync.key == key {
+		gen.Validators = app.lastSync.validators
 	}
+	gen.Revisions = app.lastSync.revisions
+	app.syncMut.Unlock()
 
-	icsData, contacts, countToday, err := gen.RunSync(app.Ctx, cfg)
+	// Large calendars are written to a temporary file rather than kept in memory.
+	ics := server.NewBuffer()
+	contacts, countToday, err := gen.RunSyncTo(ctx, cfg, ics)
+	if err != nil {
+		_ = ics.Close()
+	}
+	if errors.Is(err, context.Canceled) && app.Ctx.Err() == nil {
+		slog.Info(config.MsgSyncCanceled, config.LogKeyComponent, config.CompUI)
+		return err
+	}
+	if errors.Is(err, engine.ErrNotModified) {
+		slog.Info(config.MsgSyncUnchanged, config.LogKeyComponent, config.CompUI)
+		app.syncMut.Lock()
+		countToday = app.lastSync.count
+		app.syncMut.Unlock()
+		app.updateTrayStatus(countToday) // Clears a previous error status
+		app.recordSync(nil)
+		app.ContactsMut.RLock()
+		app.runSyncHook(nil, false, len(app.Contacts), countToday)
+		app.ContactsMut.RUnlock()
+		if manual {
+			app.notify(config.AppName, app.GetMsg(config.TKeyNotifSuccess), app.ShowContactsWindow)
+		}
+		return nil
+	}
 	if err != nil {
 		slog.Error(config.MsgSyncFailed, config.LogKeyError, err, config.LogKeyComponent, config.CompUI)
 		if manual {
-			app.App.SendNotification(fyne.NewNotification(config.TitleSyncError, app.GetMsg(config.TKeyNotifError)))
+			msg := app.GetMsg(config.TKeyNotifError)
+			switch {
+			case errors.Is(err, engine.ErrLimitExceeded):
+				msg = app.GetMsg(config.TKeyNotifLimit)
+			case errors.Is(err, engine.ErrSystemDenied):
+				msg = app.GetMsg(config.TKeyNotifDenied)
+			case errors.Is(err, engine.ErrOutlookUnavailable):
+				msg = app.GetMsg(config.TKeyNotifOutlook)
+			}
+			app.notify(config.TitleSyncError, msg, app.ShowLogsWindow)
 		}
 		app.updateTrayStatus(-1)
-		return
+		app.recordSync(err)
+		app.runSyncHook(err, false, 0, 0)
+		return err
 	}
 
+	app.syncMut.Lock()
+	app.lastSync = syncState{key: key, validators: gen.Validators, count: countToday, revisions: gen.Revisions}
+	app.syncMut.Unlock()
+
 	// Thread-safe update of contacts
 	app.ContactsMut.Lock()
 	app.Contacts = contacts
 	app.ContactsMut.Unlock()
 
-	app.Server.Update(icsData)
+	// Remember the groups seen in the source to populate the filter in settings.
+	app.Preferences.SetStringList(config.PrefKnownCategories, gen.Groups)
+
+	if app.Validate {
+		app.validateCalendar(io.NewSectionReader(ics, 0, ics.Size()))
+	}
+
+	// Saved before the server takes ownership of the buffer.
+	app.saveSyncCache(io.NewSectionReader(ics, 0, ics.Size()), contacts, gen.Revisions)
+	app.Server.UpdateBuffer(ics)
+	app.publishPhotos(cfg.PhotoMode, contacts)
+	app.publishBirthdays(contacts)
 	app.updateTrayStatus(countToday)
+	app.recordSync(nil)
+	app.updateUpcomingMenu(contacts)
+	publishErr := app.publishCalendar()
+	app.sendBirthdayPush(contacts)
+	app.notifyBirthdays(contacts)
+	app.showTodayWindow(contacts)
+	app.runSyncHook(nil, true, len(contacts), countToday)
 
 	if manual {
-		app.App.SendNotification(fyne.NewNotification(config.AppName, app.GetMsg(config.TKeyNotifSuccess)))
+		app.notify(config.AppName, app.GetMsg(config.TKeyNotifSuccess), app.ShowContactsWindow)
+	}
+	return publishErr
+}
+
+// validateCalendar logs the RFC 5545 problems of a generated calendar as warnings,
+// the first config.ICSMaxIssuesLog of them and how many were found.
+func (app *GoBirthdayApp) validateCalendar(r io.Reader) {
+	issues, err := engine.ValidateICS(r)
+	if err != nil {
+		slog.Warn(config.ErrICSValidate,
+			config.LogKeyComponent, config.CompUI,
+			config.LogKeyError, err)
+		return
+	}
+	if len(issues) == 0 {
+		slog.Info(config.MsgICSValid, config.LogKeyComponent, config.CompUI)
+		return
+	}
+	for _, issue := range issues[:min(len(issues), config.ICSMaxIssuesLog)] {
+		slog.Warn(config.MsgICSInvalid,
+			config.LogKeyComponent, config.CompUI,
+			config.LogKeyLine, issue.Line,
+			config.LogKeyReason, issue.Reason)
 	}
+	slog.Warn(config.MsgICSInvalid,
+		config.LogKeyComponent, config.CompUI,
+		config.LogKeyCount, len(issues))
 }
 
-// updateTrayStatus updates the top menu item to show how many birthdays are today.
+// publishPhotos hands the embedded contact pictures over to the HTTP server.
+// Photos are only kept in memory when the calendar links to them.
+func (app *GoBirthdayApp) publishPhotos(mode string, contacts []engine.BirthdayEntry) {
+	if mode != config.PhotoModeLink {
+		app.Server.UpdatePhotos(nil)
+		return
+	}
+
+	photos := make(map[string][]byte)
+	for _, c := range contacts {
+		if c.Photo != nil && len(c.Photo.Data) > 0 {
+			photos[c.UID] = c.Photo.Data
+		}
+	}
+	app.Server.UpdatePhotos(photos)
+}
+
+// publishBirthdays hands the visible contacts over to the Home Assistant and to-do endpoints of the HTTP server.
+func (app *GoBirthdayApp) publishBirthdays(contacts []engine.BirthdayEntry) {
+	birthdays := make([]server.Birthday, 0, len(contacts))
+	for _, c := range contacts {
+		if !c.Hidden && !c.Deceased {
+			yearKnown := c.YearKnown && app.ageDisplay() != config.AgeDisplayNone
+			birthdays = append(birthdays, server.Birthday{UID: c.UID, Name: c.Name, Date: c.DateOfBirth, YearKnown: yearKnown})
+		}
+	}
+	app.Server.UpdateBirthdays(birthdays)
+	app.Server.UpdateTodos(app.Preferences.IntWithFallback(config.PrefTodoDays, config.DefaultTodoDays), app.buildSummaryFormatter())
+}
+
+// startServer serves the calendar until the application stops, sending the user to the settings
+// if the port is unavailable.
+func (app *GoBirthdayApp) startServer() {
+	slog.Info(config.MsgServerListen,
+		config.LogKeyPort, app.Server.Port(),
+		config.LogKeyComponent, config.CompUI)
+
+	app.serverFailed.Store(false)
+	if err := app.Server.Start(app.Ctx); err != nil && !errors.Is(err, server.ErrRunning) {
+		app.serverFailed.Store(true)
+		slog.Error(config.ErrServerStartup,
+			config.LogKeyError, err,
+			config.LogKeyComponent, config.CompUI)
+
+		app.sendNotification(fyne.NewNotification(
+			config.TitleStartupError,
+			fmt.Sprintf(config.MsgPortBusy, app.Server.Port())), app.ShowSettingsWindow)
+	}
+}
+
+// applyPort moves the HTTP server to the saved port without restarting the application.
+// The server keeps its current port if the new one is unavailable, and starts on it if it
+// could not start before (e.g., the port was busy at launch).
+func (app *GoBirthdayApp) applyPort() {
+	port := app.Preferences.StringWithFallback(config.PrefServerPort, config.DefaultPort)
+	if err := app.Server.Restart(port); err != nil {
+		slog.Error(config.ErrServerStartup,
+			config.LogKeyError, err,
+			config.LogKeyComponent, config.CompUI)
+		if !app.Daemon {
+			app.sendNotification(fyne.NewNotification(config.TitleStartupError, fmt.Sprintf(config.MsgPortBusy, port)), app.ShowSettingsWindow)
+		}
+		return
+	}
+	if app.serverFailed.Load() && !app.Server.Running() && app.Ctx.Err() == nil {
+		go app.startServer()
+	}
+}
+
+// applyServerSettings hands the allowlist, the allowed CORS origin, the access logging and the
+// localized landing page over to the HTTP server.
+func (app *GoBirthdayApp) applyServerSettings() {
+	networks, err := server.ParseNetworks(splitList(app.Preferences.String(config.PrefAllowedNetworks)))
+	if err != nil {
+		// Rather than opening the server to everyone.
+		slog.Error(config.ErrNetwork, config.LogKeyError, err, config.LogKeyComponent, config.CompUI)
+		networks = []netip.Prefix{netip.MustParsePrefix(config.LoopbackNetwork)}
+	}
+	app.Server.UpdateAllowlist(networks)
+	app.Server.UpdateCORS(app.Preferences.String(config.PrefCORSOrigin))
+	app.Server.SetAccessLog(app.Preferences.Bool(config.PrefAccessLog))
+
+	name := app.Preferences.String(config.PrefCalendarName)
+	if name == "" {
+		name = config.ICalCalName
+	}
+	app.Server.UpdateLanding(server.Landing{
+		Lang:       app.Preferences.StringWithFallback(config.PrefLanguage, config.DefaultLanguage),
+		Name:       name,
+		Updated:    app.GetMsg(config.TKeyLandingUpdated),
+		Contacts:   app.GetMsg(config.TKeyLandingContacts),
+		Subscribe:  app.GetMsg(config.TKeyLandingSubscribe),
+		Copy:       app.GetMsg(config.TKeyBtnCopy),
+		TimeLayout: app.dateLayout(config.TKeyFormatDate, config.DateFormatDisplay) + " " + config.EventTimeFormat,
+	})
+}
+
+// updateTrayStatus updates the top menu item and the tray icon badge to show how many birthdays are today.
 func (app *GoBirthdayApp) updateTrayStatus(count int) {
+	app.updateTrayIcon(count)
+	app.sdStatus(count)
+
 	if app.Menu == nil || app.TrayStatusItem == nil {
 		return
 	}
@@ -266,19 +741,110 @@ func (app *GoBirthdayApp) updateTrayStatus(count int) {
 
 	app.TrayStatusItem.Label = label
 	app.Menu.Refresh()
+	app.updateMainStatus(label)
+}
+
+// openSecrets selects where passwords and tokens are kept: the OS keyring, or an encrypted file without one.
+// Secrets left in the other storage are moved to the selected one. It returns the name of the storage.
+func (app *GoBirthdayApp) openSecrets() (string, error) {
+	dir := app.SecretsDir
+	if dir == "" {
+		if base, err := os.UserConfigDir(); err == nil {
+			dir = filepath.Join(base, config.UserConfigDirName)
+		}
+	}
+
+	backend, err := secrets.Open(dir, app.secretAccounts())
+	if err != nil {
+		slog.Error(config.MsgSecretStore,
+			config.LogKeyBackend, backend,
+			config.LogKeyError, err,
+			config.LogKeyComponent, config.CompUI)
+		return backend, err
+	}
+	slog.Info(config.MsgSecretStore,
+		config.LogKeyBackend, backend,
+		config.LogKeyComponent, config.CompUI)
+	return backend, nil
+}
+
+// loadAuth reads the bearer or OAuth2 authentication of the web source from preferences and Keyring.
+// The OAuth2 token source, and so its access token, is kept while the provider settings are unchanged.
+func (app *GoBirthdayApp) loadAuth() engine.Auth {
+	auth := engine.Auth{Type: app.Preferences.StringWithFallback(config.PrefAuthType, config.AuthBasic)}
+	switch auth.Type {
+	case config.AuthBearer:
+		auth.Token, _ = secrets.Get(config.KeyringBearerToken)
+	case config.AuthOAuth2:
+		conf := engine.OAuth2Config{
+			TokenURL: app.Preferences.String(config.PrefOAuth2TokenURL),
+			ClientID: app.Preferences.String(config.PrefOAuth2ClientID),
+		}
+		conf.ClientSecret, _ = secrets.Get(config.KeyringOAuth2Secret)
+		refresh, _ := secrets.Get(config.KeyringOAuth2Token)
+
+		app.syncMut.Lock()
+		defer app.syncMut.Unlock()
+		if app.oauth2 == nil || app.oauth2.Config != conf || app.oauth2.RefreshToken() != refresh {
+			app.oauth2 = engine.NewTokenSource(conf, refresh)
+			// Providers may issue a new refresh token with each access token.
+			app.oauth2.OnRotate = func(token string) {
+				if err := secrets.Set(config.KeyringOAuth2Token, token); err != nil {
+					slog.Error("Failed to save token to secure storage", config.LogKeyError, err, config.LogKeyComponent, config.CompUI)
+				}
+			}
+		}
+		auth.OAuth2 = app.oauth2
+	}
+	return auth
+}
+
+// now returns the current time in the time zone of the preferences, the system one by default.
+func (app *GoBirthdayApp) now() time.Time {
+	now := app.Clock.Now()
+	if tz := app.Preferences.String(config.PrefTimeZone); tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			return now.In(loc)
+		}
+	}
+	return now
 }
 
 // loadSyncConfig assembles the engine configuration from UI preferences and Keyring.
 func (app *GoBirthdayApp) loadSyncConfig() engine.SyncConfig {
 	cfg := engine.SyncConfig{
-		Mode:      app.Preferences.String(config.PrefSourceMode),
-		LocalPath: app.Preferences.String(config.PrefLocalPath),
-		WebURL:    app.Preferences.String(config.PrefCardDAVURL),
-		WebUser:   app.Preferences.String(config.PrefUsername),
+		Mode:            app.Preferences.String(config.PrefSourceMode),
+		LocalPath:       app.Preferences.String(config.PrefLocalPath),
+		ThunderbirdPath: app.Preferences.String(config.PrefThunderbirdPath),
+		ExecCommand:     app.Preferences.String(config.PrefExecCommand),
+		WebURL:          app.Preferences.String(config.PrefCardDAVURL),
+		WebURLs:         app.Preferences.StringList(config.PrefAddressBooks),
+		WebUser:         app.Preferences.String(config.PrefUsername),
+		PhotoMode:       app.Preferences.StringWithFallback(config.PrefPhotoMode, config.PhotoModeNone),
+
+		Categories:     splitList(app.Preferences.StringWithFallback(config.PrefCategories, config.DefaultCategory)),
+		ContactGroups:  app.Preferences.Bool(config.PrefContactGroups),
+		ContactDetails: app.Preferences.Bool(config.PrefContactDetails),
+		PreferNickname: app.Preferences.Bool(config.PrefPreferNickname),
+		NameFormat:     app.Preferences.String(config.PrefNameFormat),
+		Color:          app.Preferences.String(config.PrefEventColor),
+		Class:          app.Preferences.StringWithFallback(config.PrefEventClass, config.DefaultEventClass),
+
+		CalendarName:  app.Preferences.String(config.PrefCalendarName),
+		CalendarDesc:  app.Preferences.String(config.PrefCalendarDesc),
+		CalendarColor: app.Preferences.String(config.PrefCalendarColor),
+		UIDDomain:     app.Preferences.String(config.PrefUIDDomain),
+		TimeZone:      app.Preferences.String(config.PrefTimeZone),
+		EventTime:     app.Preferences.String(config.PrefEventTime),
+		EventDuration: time.Duration(app.Preferences.IntWithFallback(config.PrefEventDuration, config.DefaultEventMins)) * time.Minute,
+	}
+
+	if cfg.PhotoMode == config.PhotoModeLink {
+		cfg.PhotoBaseURL = fmt.Sprintf(config.FormatBaseURL, app.Server.Port())
 	}
 
 	if cfg.WebUser != "" {
-		if p, err := keyring.Get(config.KeyringService, cfg.WebUser); err == nil {
+		if p, err := secrets.Get(cfg.WebUser); err == nil {
 			cfg.WebPass = p
 		} else {
 			slog.Debug(config.MsgPassFail,
@@ -288,32 +854,125 @@ func (app *GoBirthdayApp) loadSyncConfig() engine.SyncConfig {
 		}
 	}
 
-	if app.Preferences.Bool(config.PrefReminderEnabled) {
-		val := app.Preferences.IntWithFallback(config.PrefReminderValue, config.DefaultReminderValue)
-		unit := app.Preferences.StringWithFallback(config.PrefReminderUnit, config.UnitDays)
-		dir := app.Preferences.StringWithFallback(config.PrefReminderDir, config.DirBefore)
+	cfg.Auth = app.loadAuth()
 
-		sign := config.ISOPeriodPrefix
-		if dir == config.DirBefore {
-			sign = config.ISONegativePrefix
+	if proxy := app.Preferences.String(config.PrefProxyURL); proxy != "" {
+		cfg.Transport.ProxyURL = proxy
+		cfg.Transport.ProxyUser = app.Preferences.String(config.PrefProxyUser)
+		if p, err := secrets.Get(config.KeyringProxyPass); err == nil {
+			cfg.Transport.ProxyPass = p
 		}
+	}
 
-		switch unit {
-		case config.UnitHours:
-			cfg.ReminderTrigger = fmt.Sprintf("%s%d%s", sign, val, config.ISOHour)
-		case config.UnitMinutes:
-			cfg.ReminderTrigger = fmt.Sprintf("%s%d%s", sign, val, config.ISOMinute)
-		default:
-			cfg.ReminderTrigger = fmt.Sprintf("%s%d%s", sign, val, config.ISODay)
+	cfg.Transport.CAFile = app.Preferences.String(config.PrefCAFile)
+	cfg.Transport.InsecureSkipVerify = app.Preferences.Bool(config.PrefTLSInsecure)
+	cfg.Transport.ClientCert = app.Preferences.String(config.PrefClientCert)
+	cfg.Transport.ClientKey = app.Preferences.String(config.PrefClientKey)
+
+	if app.Preferences.Bool(config.PrefReminderEnabled) {
+		cfg.ReminderTriggers = app.reminderTriggers()
+	}
+
+	cfg.IncludeCategories = app.Preferences.StringList(config.PrefIncludeCategories)
+	cfg.CustomDates = app.Preferences.Bool(config.PrefCustomDates)
+	cfg.Memorials = app.Preferences.Bool(config.PrefMemorials)
+	cfg.NameDays = app.Preferences.String(config.PrefNameDays)
+	cfg.ZodiacSummary = app.Preferences.Bool(config.PrefZodiacSummary)
+	cfg.Overrides = app.overrides()
+	cfg.Hidden = app.hiddenContacts()
+	cfg.Lunar = app.lunarContacts()
+	cfg.Offsets = parseOffsets(app.Preferences.String(config.PrefOffsets))
+	cfg.ContactOffsets = app.contactOffsets()
+	cfg.Limits = engine.Limits{
+		MaxDownloadBytes: int64(app.Preferences.IntWithFallback(config.PrefMaxDownloadMB, config.DefaultMaxDownloadMB)) * config.BytesPerMB,
+		MaxContacts:      app.Preferences.IntWithFallback(config.PrefMaxContacts, config.DefaultMaxContacts),
+		MaxCardBytes:     int64(app.Preferences.IntWithFallback(config.PrefMaxCardMB, config.DefaultMaxCardMB)) * config.BytesPerMB,
+		RequestSpacing:   time.Duration(app.Preferences.Int(config.PrefRequestSpacing)) * time.Second,
+	}
+
+	if app.Preferences.Bool(config.PrefMilestoneEnabled) {
+		for _, v := range splitList(app.Preferences.StringWithFallback(config.PrefMilestoneAges, config.DefaultMilestoneAges)) {
+			if age, err := strconv.Atoi(v); err == nil && age > 0 {
+				cfg.Milestones = append(cfg.Milestones, age)
+			}
+		}
+		cfg.MilestoneEvery = app.Preferences.IntWithFallback(config.PrefMilestoneEvery, config.DefaultMilestoneEvery)
+		cfg.MilestonePrefix = app.Preferences.StringWithFallback(config.PrefMilestonePrefix, config.DefaultMilestonePrefix)
+		if days := app.Preferences.IntWithFallback(config.PrefMilestoneReminder, config.DefaultMilestoneDays); days > 0 {
+			cfg.MilestoneTrigger = formatTrigger(days, config.UnitDays, config.DirBefore)
 		}
 	}
 
 	return cfg
 }
 
-// buildSummaryFormatter returns a closure that localizes the event summary.
+// splitList converts a comma-separated preference into a list of trimmed, non-empty values.
+func splitList(value string) []string {
+	var out []string
+	for _, v := range strings.Split(value, config.ListSeparator) {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// summaryData is the data exposed to user-defined summary templates.
+type summaryData struct {
+	Name      string
+	Age       int
+	YearKnown bool
+	Birth     bool // True for the birth event itself (age 0 with a known year)
+}
+
+// parseSummaryTemplate compiles a user-defined summary template.
+func parseSummaryTemplate(text string) (*template.Template, error) {
+	return template.New(config.SummaryTmpl).Parse(text)
+}
+
+// renderSummary executes a summary template for one event.
+func renderSummary(tmpl *template.Template, name string, age int, yearKnown bool) (string, error) {
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, summaryData{
+		Name:      name,
+		Age:       age,
+		YearKnown: yearKnown,
+		Birth:     yearKnown && age == 0,
+	})
+	return strings.TrimSpace(buf.String()), err
+}
+
+// buildSummaryFormatter returns a closure that formats the event summary
+// using the user's template from preferences, or the localized defaults.
 func (app *GoBirthdayApp) buildSummaryFormatter() func(name string, age int, yearKnown bool) string {
+	return app.summaryFormatter(app.Preferences.String(config.PrefSummaryTemplate))
+}
+
+// summaryFormatter returns a closure that renders the given template text.
+// An empty or invalid template falls back to the localized summary strings,
+// so a typo in settings never breaks the calendar.
+func (app *GoBirthdayApp) summaryFormatter(text string) func(name string, age int, yearKnown bool) string {
+	var tmpl *template.Template
+	if text != "" {
+		t, err := parseSummaryTemplate(text)
+		if err != nil {
+			slog.Warn(config.ErrSummaryTemplate, config.LogKeyError, err, config.LogKeyComponent, config.CompUI)
+		} else {
+			tmpl = t
+		}
+	}
+
+	hideAge := app.ageDisplay() == config.AgeDisplayNone
 	return func(name string, age int, yearKnown bool) string {
+		if hideAge {
+			yearKnown = false
+		}
+		if tmpl != nil {
+			if msg, err := renderSummary(tmpl, name, age, yearKnown); err == nil && msg != "" {
+				return msg
+			}
+		}
+
 		var msg string
 		var err error
 
@@ -354,3 +1013,89 @@ func (app *GoBirthdayApp) buildSummaryFormatter() func(name string, age int, yea
 		return msg
 	}
 }
+
+// dateSummaryFormatter localizes the summary of custom dates (anniversaries, ...).
+// Apple's built-in labels are translated, user-defined labels are kept as-is.
+func (app *GoBirthdayApp) dateSummaryFormatter(label, name string, years int, yearKnown bool) string {
+	switch label {
+	case config.ABLabelAnniversary:
+		label = app.GetMsg(config.TKeyLabelAnniv)
+	case config.ABLabelOther:
+		label = app.GetMsg(config.TKeyLabelOther)
+	}
+
+	if app.Localizer != nil {
+		lc := &i18n.LocalizeConfig{
+			MessageID:    config.TKeyEvtDate,
+			TemplateData: map[string]interface{}{"Label": label, "Name": name},
+		}
+		if yearKnown {
+			lc.MessageID = config.TKeyEvtDateYears
+			lc.TemplateData = map[string]interface{}{"Label": label, "Name": name, "Years": years}
+		}
+		if msg, err := app.Localizer.Localize(lc); err == nil && msg != "" {
+			return msg
+		}
+	}
+
+	if yearKnown {
+		return fmt.Sprintf(config.FallbackDateSummaryYears, label, name, years)
+	}
+	return fmt.Sprintf(config.FallbackDateSummary, label, name)
+}
+
+// memorialSummaryFormatter localizes the summary of remembrance events.
+func (app *GoBirthdayApp) memorialSummaryFormatter(name string, years int, yearKnown bool) string {
+	if app.Localizer != nil {
+		lc := &i18n.LocalizeConfig{
+			MessageID:    config.TKeyEvtMemorial,
+			TemplateData: map[string]interface{}{"Name": name},
+		}
+		if yearKnown {
+			lc.MessageID = config.TKeyEvtMemorialYears
+			lc.TemplateData = map[string]interface{}{"Name": name, "Years": years}
+		}
+		if msg, err := app.Localizer.Localize(lc); err == nil && msg != "" {
+			return msg
+		}
+	}
+
+	if yearKnown {
+		return fmt.Sprintf(config.FallbackMemorialYears, name, years)
+	}
+	return fmt.Sprintf(config.FallbackMemorial, name)
+}
+
+// nameDaySummaryFormatter localizes the summary of name-day events.
+func (app *GoBirthdayApp) nameDaySummaryFormatter(name string) string {
+	if app.Localizer != nil {
+		msg, err := app.Localizer.Localize(&i18n.LocalizeConfig{
+			MessageID:    config.TKeyEvtNameDay,
+			TemplateData: map[string]interface{}{"Name": name},
+		})
+		if err == nil && msg != "" {
+			return msg
+		}
+	}
+	return fmt.Sprintf(config.FallbackNameDay, name)
+}
+
+// offsetSummaryFormatter localizes the summary of offset anniversaries.
+func (app *GoBirthdayApp) offsetSummaryFormatter(name string, o engine.Offset) string {
+	lc := &i18n.LocalizeConfig{MessageID: config.TKeyEvtOffsetDays, TemplateData: map[string]interface{}{"Name": name, "Days": o.Days}}
+	fallback := fmt.Sprintf(config.FallbackOffsetDays, name, o.Days)
+	switch {
+	case o.Months == config.HalfBirthdayMonths:
+		lc = &i18n.LocalizeConfig{MessageID: config.TKeyEvtHalfBirthday, TemplateData: map[string]interface{}{"Name": name}}
+		fallback = fmt.Sprintf(config.FallbackHalfBirthday, name)
+	case o.Months > 0:
+		lc = &i18n.LocalizeConfig{MessageID: config.TKeyEvtOffsetMonths, TemplateData: map[string]interface{}{"Name": name, "Months": o.Months}}
+		fallback = fmt.Sprintf(config.FallbackOffsetMonths, name, o.Months)
+	}
+	if app.Localizer != nil {
+		if msg, err := app.Localizer.Localize(lc); err == nil && msg != "" {
+			return msg
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/prefs"
+	"github.com/tartampluch/go-birthday/internal/secrets"
+)
+
+// settingsBackup is the file of "Export settings", to migrate to another machine or keep a backup.
+type settingsBackup struct {
+	App         string          `json:"app"`     // config.AppID, to reject other JSON files
+	Version     int             `json:"version"` // config.BackupVersion
+	Preferences map[string]any  `json:"preferences"`
+	Secrets     json.RawMessage `json:"secrets,omitempty"` // Encrypted with a passphrase by secrets.Seal
+}
+
+// secretAccounts lists the accounts of the secrets the application may hold.
+func (app *GoBirthdayApp) secretAccounts() []string {
+	accounts := []string{
+		config.KeyringPushToken, config.KeyringProxyPass, config.KeyringBearerToken,
+		config.KeyringOAuth2Secret, config.KeyringOAuth2Token, config.KeyringCalDAVPass,
+		config.KeyringWebDAVPass, config.KeyringWebDAVToken,
+	}
+	if user := app.Preferences.String(config.PrefUsername); user != "" {
+		accounts = append(accounts, user) // The CardDAV password is saved under the username
+	}
+	return accounts
+}
+
+// exportSettings writes the saved preferences to w, except config.BackupSkipPrefs, and the secrets
+// encrypted with passphrase unless it is empty.
+func (app *GoBirthdayApp) exportSettings(w io.Writer, passphrase string) error {
+	values, ok := prefs.Values(app.Preferences)
+	if !ok {
+		return errors.New(config.ErrBackupPrefs)
+	}
+	for _, key := range config.BackupSkipPrefs {
+		delete(values, key)
+	}
+	backup := settingsBackup{App: config.AppID, Version: config.BackupVersion, Preferences: values}
+
+	if passphrase != "" {
+		all := make(map[string]string)
+		for _, account := range app.secretAccounts() {
+			if secret, err := secrets.Get(account); err == nil {
+				all[account] = secret
+			}
+		}
+		sealed, err := secrets.Seal(passphrase, all)
+		if err != nil {
+			return err
+		}
+		backup.Secrets = sealed
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(backup)
+}
+
+// readSettings parses a file of exportSettings.
+func readSettings(r io.Reader) (settingsBackup, error) {
+	var backup settingsBackup
+	if err := json.NewDecoder(r).Decode(&backup); err != nil || backup.App != config.AppID {
+		return backup, errors.New(config.ErrBackupFile)
+	}
+	if backup.Version < 1 || backup.Version > config.BackupVersion {
+		return backup, fmt.Errorf(config.ErrBackupVersion, backup.Version)
+	}
+	return backup, nil
+}
+
+// restoreSettings saves the preferences of backup, replacing those with the same keys, and its
+// secrets if passphrase is not empty. The secrets are decrypted first, so that a wrong passphrase
+// changes nothing. It returns the number of preferences restored.
+func (app *GoBirthdayApp) restoreSettings(backup settingsBackup, passphrase string) (int, error) {
+	var all map[string]string
+	if passphrase != "" && len(backup.Secrets) > 0 {
+		var err error
+		if all, err = secrets.Unseal(passphrase, backup.Secrets); err != nil {
+			return 0, err
+		}
+	}
+
+	count := 0
+	for key, value := range backup.Preferences {
+		if !slices.Contains(config.BackupSkipPrefs, key) && prefs.Set(app.Preferences, key, value) {
+			count++
+		}
+	}
+	var errs []error
+	for account, secret := range all {
+		errs = append(errs, secrets.Set(account, secret))
+	}
+	return count, errors.Join(errs...)
+}
+
+// showExportSettings asks for an optional passphrase protecting the secrets, then for the file to write.
+func (app *GoBirthdayApp) showExportSettings(w fyne.Window) {
+	passEntry := widget.NewPasswordEntry()
+	item := widget.NewFormItem(app.GetMsg(config.TKeyLblBackupPass), passEntry)
+	item.HintText = app.GetMsg(config.TKeyHelpBackupExport)
+
+	d := dialog.NewForm(app.GetMsg(config.TKeyBtnExportSettings), app.GetMsg(config.TKeyBtnNext), app.GetMsg(config.TKeyBtnCancel), []*widget.FormItem{item}, func(ok bool) {
+		if !ok {
+			return
+		}
+		save := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if wc == nil {
+				return // Cancelled
+			}
+			defer func() { _ = wc.Close() }()
+
+			if err := app.exportSettings(wc, passEntry.Text); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			slog.Info(config.MsgSettingsSaved,
+				config.LogKeyComponent, config.CompUISet,
+				config.LogKeyFile, wc.URI().Path())
+		}, w)
+		save.SetFileName(config.BackupFileName)
+		save.SetFilter(storage.NewExtensionFileFilter([]string{config.ExtJSON}))
+		save.Show()
+	}, w)
+	d.Resize(fyne.NewSize(config.SettingsWindowWidth, d.MinSize().Height))
+	d.Show()
+}
+
+// showImportSettings asks for a settings file, and for its passphrase if it holds secrets, then
+// restores it and reopens the settings window, whose fields show the previous values.
+func (app *GoBirthdayApp) showImportSettings(w fyne.Window) {
+	d := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if r == nil {
+			return // Cancelled
+		}
+		backup, err := readSettings(r)
+		_ = r.Close()
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if len(backup.Secrets) == 0 {
+			app.applySettings(w, backup, "")
+			return
+		}
+
+		passEntry := widget.NewPasswordEntry()
+		item := widget.NewFormItem(app.GetMsg(config.TKeyLblBackupPass), passEntry)
+		item.HintText = app.GetMsg(config.TKeyHelpBackupImport)
+		form := dialog.NewForm(app.GetMsg(config.TKeyBtnImportSettings), app.GetMsg(config.TKeyBtnUse), app.GetMsg(config.TKeyBtnCancel), []*widget.FormItem{item}, func(ok bool) {
+			if ok {
+				app.applySettings(w, backup, passEntry.Text)
+			}
+		}, w)
+		form.Resize(fyne.NewSize(config.SettingsWindowWidth, form.MinSize().Height))
+		form.Show()
+	}, w)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{config.ExtJSON}))
+	d.Show()
+}
+
+// applySettings restores backup, then applies it as saving the settings does.
+func (app *GoBirthdayApp) applySettings(w fyne.Window, backup settingsBackup, passphrase string) {
+	count, err := app.restoreSettings(backup, passphrase)
+	if err != nil && count == 0 {
+		dialog.ShowError(err, w)
+		return
+	}
+	if err != nil {
+		slog.Warn(config.MsgSettingsLoaded, // Some secrets could not be saved
+			config.LogKeyComponent, config.CompUISet,
+			config.LogKeyCount, count,
+			config.LogKeyError, err)
+	} else {
+		slog.Info(config.MsgSettingsLoaded,
+			config.LogKeyComponent, config.CompUISet,
+			config.LogKeyCount, count)
+	}
+
+	app.UpdateLocalizer()
+	app.applyTheme()
+	app.RefreshTrayMenu()
+	go app.performSync(true)
+
+	app.Window = nil // Lets ShowSettingsWindow open a new one at once
+	w.Close()
+	app.ShowSettingsWindow()
+	dialog.ShowInformation(app.GetMsg(config.TKeyBtnImportSettings), fmt.Sprintf(app.GetMsg(config.TKeyMsgSettingsImported), count), app.Window)
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/png"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// badgeIcon draws a round badge holding count in the top-right corner of a PNG icon.
+// Counts above config.BadgeMaxCount are shown as "9+".
+func badgeIcon(base []byte, count int) ([]byte, error) {
+	src, err := png.Decode(bytes.NewReader(base))
+	if err != nil {
+		return nil, err
+	}
+
+	b := src.Bounds()
+	img := image.NewRGBA(b)
+	draw.Draw(img, b, src, b.Min, draw.Src)
+
+	// Badge geometry, relative to the icon size.
+	size := min(b.Dx(), b.Dy())
+	r := int(float64(size) * config.BadgeRadiusRatio)
+	cx, cy := b.Max.X-r-1, b.Min.Y+r+1
+
+	for y := cy - r; y <= cy+r; y++ {
+		for x := cx - r; x <= cx+r; x++ {
+			if dx, dy := x-cx, y-cy; dx*dx+dy*dy <= r*r {
+				img.Set(x, y, config.BadgeColor)
+			}
+		}
+	}
+
+	label := strconv.Itoa(count)
+	if count > config.BadgeMaxCount {
+		label = strconv.Itoa(config.BadgeMaxCount) + "+"
+	}
+
+	// Render the label with the built-in bitmap font, then upscale it into the badge.
+	face := basicfont.Face7x13
+	glyphs := image.NewAlpha(image.Rect(0, 0, face.Advance*len(label), face.Height))
+	d := font.Drawer{
+		Dst:  glyphs,
+		Src:  image.Opaque,
+		Face: face,
+		Dot:  fixed.P(0, face.Ascent),
+	}
+	d.DrawString(label)
+
+	gb := glyphs.Bounds()
+	scale := max(1, min(2*r*config.BadgeTextRatio/100/gb.Dx(), 2*r*config.BadgeTextRatio/100/gb.Dy()))
+	ox, oy := cx-gb.Dx()*scale/2, cy-gb.Dy()*scale/2
+	for y := 0; y < gb.Dy(); y++ {
+		for x := 0; x < gb.Dx(); x++ {
+			if glyphs.AlphaAt(x, y).A == 0 {
+				continue
+			}
+			block := image.Rect(ox+x*scale, oy+y*scale, ox+(x+1)*scale, oy+(y+1)*scale)
+			draw.Draw(img, block, image.NewUniform(config.BadgeTextColor), image.Point{}, draw.Over)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// updateTrayIcon shows today's birthday count on the tray icon.
+// The plain application icon is restored when there is nothing to show (or on error).
+func (app *GoBirthdayApp) updateTrayIcon(count int) {
+	if app.Tray == nil || count == app.trayIconCount {
+		return
+	}
+	app.trayIconCount = count
+
+	if count <= 0 {
+		app.Tray.SetSystemTrayIcon(app.App.Icon())
+		return
+	}
+
+	data, err := badgeIcon(appIconData, count)
+	if err != nil {
+		app.Tray.SetSystemTrayIcon(app.App.Icon())
+		return
+	}
+	app.Tray.SetSystemTrayIcon(fyne.NewStaticResource(config.IconBadgeFile, data))
+}
@@ -0,0 +1,238 @@
+package ui
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+	"github.com/tartampluch/go-birthday/internal/server"
+)
+
+// syncState remembers the last successful synchronization, so that the next one can
+// send a conditional request and keep the current calendar if the address book is unchanged.
+type syncState struct {
+	key        string // See syncKey
+	validators engine.Validators
+	count      int // Birthdays today
+	revisions  engine.Revisions
+}
+
+// syncKey identifies everything besides the address book that shapes the calendar:
+// the sync configuration, the summary language, template and age display, and the current day (ages, countdowns).
+// A conditional request is only worth sending while the key stays the same.
+func (app *GoBirthdayApp) syncKey(cfg engine.SyncConfig) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%+v|%s|%s|%s|%s", cfg,
+		app.Preferences.String(config.PrefLanguage),
+		app.Preferences.String(config.PrefSummaryTemplate),
+		app.ageDisplay(),
+		app.now().Format(config.DateFormatDisplay)))
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheDir returns the directory holding the files kept between runs, creating it if needed.
+// It is the log directory (<user cache dir>/<app ID>) unless CacheDir is set.
+func (app *GoBirthdayApp) cacheDir() (string, error) {
+	dir := app.CacheDir
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", config.ErrCacheDir, err)
+		}
+		dir = filepath.Join(base, config.AppID)
+	}
+	if err := os.MkdirAll(dir, config.DirPermUserRWX); err != nil {
+		return "", fmt.Errorf("%s: %w", config.ErrCreateDir, err)
+	}
+	return dir, nil
+}
+
+// writeCacheFile replaces a cache file atomically, so a crash never leaves a truncated calendar.
+func (app *GoBirthdayApp) writeCacheFile(name string, r io.Reader) error {
+	dir, err := app.cacheDir()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, name+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }() // No-op after a successful rename
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), config.FilePermUserRW); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
+
+// saveSyncCache keeps the calendar, the contacts and the event revisions of a successful
+// synchronization for the next start. Photos are left out of the contacts: they are only
+// needed once the next synchronization runs.
+func (app *GoBirthdayApp) saveSyncCache(ics io.Reader, contacts []engine.BirthdayEntry, revisions engine.Revisions) {
+	stripped := make([]engine.BirthdayEntry, len(contacts))
+	for i, c := range contacts {
+		c.Photo = nil
+		stripped[i] = c
+	}
+	data, err := json.Marshal(stripped)
+	var revData []byte
+	if err == nil {
+		revData, err = json.Marshal(revisions)
+	}
+
+	files := []struct {
+		name string
+		r    io.Reader
+	}{{config.CacheICSFile, ics}, {config.CacheContactsFile, bytes.NewReader(data)}, {config.CacheRevisionsFile, bytes.NewReader(revData)}}
+	for _, file := range files {
+		if err == nil {
+			err = app.writeCacheFile(file.name, file.r)
+		}
+		if err != nil {
+			slog.Warn(config.ErrCacheWrite,
+				config.LogKeyComponent, config.CompUI,
+				config.LogKeyFile, file.name,
+				config.LogKeyError, err)
+			return
+		}
+	}
+}
+
+// openCacheFile opens a cache file and returns its modification time, or ok=false without one.
+// Callers must close the file.
+func (app *GoBirthdayApp) openCacheFile(name string) (f *os.File, modified time.Time, ok bool) {
+	dir, err := app.cacheDir()
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	path := filepath.Join(dir, name)
+	f, err = os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, time.Time{}, false // No cache yet
+	}
+	var info os.FileInfo
+	if err == nil {
+		info, err = f.Stat()
+	}
+	if err == nil && info.Size() == 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		if f != nil {
+			_ = f.Close()
+		}
+		slog.Warn(config.ErrCacheRead,
+			config.LogKeyComponent, config.CompUI,
+			config.LogKeyPath, path,
+			config.LogKeyError, err)
+		return nil, time.Time{}, false
+	}
+	return f, info.ModTime(), true
+}
+
+// loadCalendarCache serves the calendar saved by the previous run, marked stale,
+// so calendar clients do not get a 503 while the first synchronization is pending or failing.
+func (app *GoBirthdayApp) loadCalendarCache() {
+	f, modified, ok := app.openCacheFile(config.CacheICSFile)
+	if !ok {
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	buf := server.NewBuffer()
+	if _, err := io.Copy(buf, f); err != nil {
+		_ = buf.Close()
+		slog.Warn(config.ErrCacheRead,
+			config.LogKeyComponent, config.CompUI,
+			config.LogKeyFile, config.CacheICSFile,
+			config.LogKeyError, err)
+		return
+	}
+	app.Server.UpdateStale(buf, modified)
+	slog.Info(config.MsgCacheLoaded,
+		config.LogKeyComponent, config.CompUI,
+		config.LogKeyModified, modified)
+}
+
+// loadContactsCache shows the contacts of the previous run in the contacts window and the tray
+// until the first synchronization replaces them. Birthdays already past are left out of the tray.
+func (app *GoBirthdayApp) loadContactsCache() {
+	f, _, ok := app.openCacheFile(config.CacheContactsFile)
+	if !ok {
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	var contacts []engine.BirthdayEntry
+	if err := json.NewDecoder(f).Decode(&contacts); err != nil {
+		slog.Warn(config.ErrCacheRead,
+			config.LogKeyComponent, config.CompUI,
+			config.LogKeyFile, config.CacheContactsFile,
+			config.LogKeyError, err)
+		return
+	}
+
+	app.ContactsMut.Lock()
+	app.Contacts = contacts
+	app.ContactsMut.Unlock()
+	app.publishBirthdays(contacts)
+
+	now := app.now()
+	var upcoming []engine.BirthdayEntry
+	count := 0
+	for _, c := range contacts {
+		days := daysUntil(c.NextOccurrence, now)
+		if days < 0 {
+			continue
+		}
+		upcoming = append(upcoming, c)
+		if days == 0 && !c.Hidden {
+			count++
+		}
+	}
+	app.updateTrayStatus(count)
+	app.updateUpcomingMenu(upcoming)
+
+	slog.Info(config.MsgContactsLoaded,
+		config.LogKeyComponent, config.CompUI,
+		config.LogKeyCount, len(contacts))
+}
+
+// loadRevisionsCache restores the revisions of the events of the previous run, so that
+// SEQUENCE keeps increasing across restarts instead of starting over.
+func (app *GoBirthdayApp) loadRevisionsCache() {
+	f, _, ok := app.openCacheFile(config.CacheRevisionsFile)
+	if !ok {
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	var revisions engine.Revisions
+	if err := json.NewDecoder(f).Decode(&revisions); err != nil {
+		slog.Warn(config.ErrCacheRead,
+			config.LogKeyComponent, config.CompUI,
+			config.LogKeyFile, config.CacheRevisionsFile,
+			config.LogKeyError, err)
+		return
+	}
+
+	app.syncMut.Lock()
+	app.lastSync.revisions = revisions
+	app.syncMut.Unlock()
+}
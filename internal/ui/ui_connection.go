@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+	"github.com/tartampluch/go-birthday/internal/secrets"
+)
+
+// modeCodes maps the labels of the source selector back to the config constants.
+func (app *GoBirthdayApp) modeCodes() map[string]string {
+	codes := map[string]string{
+		app.GetMsg(config.TKeyModeCardDAV):     config.SourceModeWeb,
+		app.GetMsg(config.TKeyModeJMAP):        config.SourceModeJMAP,
+		app.GetMsg(config.TKeyModeLocal):       config.SourceModeLocal,
+		app.GetMsg(config.TKeyModeSystem):      config.SourceModeSystem,
+		app.GetMsg(config.TKeyModeThunderbird): config.SourceModeThunderbird,
+		app.GetMsg(config.TKeyModeOutlook):     config.SourceModeOutlook,
+		app.GetMsg(config.TKeyModeExec):        config.SourceModeExec,
+	}
+	for _, mode := range engine.Sources() {
+		codes[mode] = mode
+	}
+	return codes
+}
+
+// secretOr returns entered, or the saved secret of account when nothing was entered.
+func secretOr(entered, account string) string {
+	if entered != "" {
+		return entered
+	}
+	saved, _ := secrets.Get(account)
+	return saved
+}
+
+// sourceConfig returns cfg with the source as edited in the settings window, before it is saved.
+// Empty secret fields keep the saved secrets, as saving does.
+func (app *GoBirthdayApp) sourceConfig(cfg engine.SyncConfig, sw *settingsWidgets) engine.SyncConfig {
+	cfg.Mode = app.modeCodes()[sw.modeSelect.Selected]
+	cfg.WebURL = sw.urlEntry.Text
+	cfg.WebURLs = sw.addressBooks
+	cfg.WebUser = sw.userEntry.Text
+	cfg.WebPass = ""
+	if cfg.WebUser != "" {
+		cfg.WebPass = secretOr(sw.passEntry.Text, cfg.WebUser)
+	}
+	cfg.LocalPath = sw.pathEntry.Text
+	cfg.ThunderbirdPath = sw.tbirdEntry.Text
+	cfg.ExecCommand = strings.TrimSpace(sw.execEntry.Text)
+
+	_, authCodes := app.authTypeOptions()
+	cfg.Auth = engine.Auth{Type: authCodes[sw.authSelect.Selected]}
+	switch cfg.Auth.Type {
+	case config.AuthBearer:
+		cfg.Auth.Token = secretOr(sw.tokenEntry.Text, config.KeyringBearerToken)
+	case config.AuthOAuth2:
+		conf := engine.OAuth2Config{
+			TokenURL:     strings.TrimSpace(sw.tokenURLEntry.Text),
+			ClientID:     strings.TrimSpace(sw.clientIDEntry.Text),
+			ClientSecret: secretOr(sw.secretEntry.Text, config.KeyringOAuth2Secret),
+		}
+		refresh := secretOr(strings.TrimSpace(sw.refreshEntry.Text), config.KeyringOAuth2Token)
+		saved := app.loadAuth()
+		if saved.OAuth2 != nil && saved.OAuth2.Config == conf && saved.OAuth2.RefreshToken() == refresh {
+			// The saved token source persists the refresh tokens the provider rotates.
+			cfg.Auth = saved
+			break
+		}
+		cfg.Auth.OAuth2 = engine.NewTokenSource(conf, refresh)
+		// A rotated refresh token replaces the entered one, so that saving keeps the valid one.
+		cfg.Auth.OAuth2.OnRotate = func(token string) {
+			fyne.Do(func() { sw.refreshEntry.SetText(token) })
+		}
+	}
+
+	cfg.Transport = engine.TransportConfig{
+		CAFile:             strings.TrimSpace(sw.caFileEntry.Text),
+		InsecureSkipVerify: sw.checkInsecure.Checked,
+		ClientCert:         strings.TrimSpace(sw.certEntry.Text),
+		ClientKey:          strings.TrimSpace(sw.keyEntry.Text),
+	}
+	if proxy := strings.TrimSpace(sw.proxyEntry.Text); proxy != "" {
+		cfg.Transport.ProxyURL = proxy
+		cfg.Transport.ProxyUser = sw.proxyUser.Text
+		cfg.Transport.ProxyPass = secretOr(sw.proxyPass.Text, config.KeyringProxyPass)
+	}
+	return cfg
+}
+
+// isTLSError reports whether err comes from the TLS handshake: an untrusted or mismatched
+// certificate, or one refused by the server.
+func isTLSError(err error) bool {
+	var (
+		unknown  x509.UnknownAuthorityError
+		hostname x509.HostnameError
+		invalid  x509.CertificateInvalidError
+		verify   *tls.CertificateVerificationError
+		alert    tls.AlertError
+	)
+	return errors.As(err, &unknown) || errors.As(err, &hostname) || errors.As(err, &invalid) ||
+		errors.As(err, &verify) || errors.As(err, &alert)
+}
+
+// probeMessage explains the result of a connection test: the contacts read, or the likely cause
+// of the failure followed by the error itself. ok is false when the source could not be used.
+func (app *GoBirthdayApp) probeMessage(res engine.ProbeResult, err error) (msg string, ok bool) {
+	if err == nil {
+		switch {
+		case res.Cards+res.Malformed == 0 && res.Bytes == 0:
+			return app.GetMsg(config.TKeyTestConnEmpty), true
+		case res.Cards == 0:
+			return app.GetMsg(config.TKeyTestConnParse), false
+		}
+		msg = fmt.Sprintf(app.GetMsg(config.TKeyTestConnOK), res.Cards, res.Birthdays)
+		if res.Malformed > 0 || res.BadDates > 0 {
+			msg += "\n" + fmt.Sprintf(app.GetMsg(config.TKeyTestConnSkipped), res.Malformed, res.BadDates)
+		}
+		return msg, true
+	}
+
+	var status *engine.StatusError
+	switch {
+	case errors.As(err, &status) && (status.Code == http.StatusUnauthorized || status.Code == http.StatusForbidden):
+		msg = fmt.Sprintf(app.GetMsg(config.TKeyTestConnAuth), status.Code)
+	case errors.As(err, &status) && status.Code == http.StatusNotFound:
+		msg = app.GetMsg(config.TKeyTestConnNotFound)
+	case errors.As(err, &status):
+		msg = fmt.Sprintf(app.GetMsg(config.TKeyTestConnStatus), status.Code)
+	case isTLSError(err):
+		msg = app.GetMsg(config.TKeyTestConnTLS)
+	case errors.Is(err, engine.ErrNetwork), errors.Is(err, context.DeadlineExceeded):
+		msg = app.GetMsg(config.TKeyTestConnNetwork)
+	case errors.Is(err, engine.ErrLimitExceeded):
+		msg = app.GetMsg(config.TKeyNotifLimit)
+	case errors.Is(err, engine.ErrSystemDenied):
+		msg = app.GetMsg(config.TKeyNotifDenied)
+	case errors.Is(err, engine.ErrOutlookUnavailable):
+		msg = app.GetMsg(config.TKeyNotifOutlook)
+	default:
+		msg = app.GetMsg(config.TKeyTestConnFailed)
+	}
+	return msg + "\n\n" + err.Error(), false
+}
+
+// testConnection reads the whole source of cfg, once and without retrying, and reports what it
+// holds or why it could not be read.
+func (app *GoBirthdayApp) testConnection(ctx context.Context, cfg engine.SyncConfig) (string, bool) {
+	fetcher := app.Fetcher
+	if r, ok := fetcher.(*engine.RetryFetcher); ok {
+		fetcher = r.Fetcher
+	}
+
+	gen := &engine.Generator{Clock: app.Clock, Fetcher: fetcher}
+	res, err := gen.Probe(ctx, cfg, 0)
+	if err != nil {
+		slog.Warn(config.ErrConnTest, config.LogKeyMode, cfg.Mode, config.LogKeyError, err, config.LogKeyComponent, config.CompUISet)
+	} else {
+		slog.Info(config.MsgConnTested, config.LogKeyMode, cfg.Mode, config.LogKeyCount, res.Cards, config.LogKeyComponent, config.CompUISet)
+	}
+	return app.probeMessage(res, err)
+}
+
+// showTestConnection tests the source as edited in the settings window, before it is saved,
+// and shows the outcome.
+func (app *GoBirthdayApp) showTestConnection(w fyne.Window, sw *settingsWidgets) {
+	title := app.GetMsg(config.TKeyBtnTestConn)
+	progress := dialog.NewCustomWithoutButtons(title, widget.NewProgressBarInfinite(), w)
+	progress.Show()
+
+	// The settings are read here, on the UI goroutine.
+	cfg := app.sourceConfig(app.loadSyncConfig(), sw)
+	go func() {
+		ctx, cancel := context.WithTimeout(app.Ctx, config.DoctorTimeout)
+		defer cancel()
+		msg, _ := app.testConnection(ctx, cfg)
+		fyne.Do(func() {
+			progress.Hide()
+			dialog.ShowInformation(title, msg, w)
+		})
+	}()
+}
@@ -160,6 +160,28 @@ func TestTableFormatting(t *testing.T) {
 	}
 }
 
+func TestCountdownFormatting(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.Preferences.SetString(config.PrefLanguage, "en")
+	app.UpdateLocalizer()
+
+	now := time.Date(2024, time.March, 5, 18, 30, 0, 0, time.Local)
+	tests := []struct {
+		next time.Time
+		want string
+	}{
+		{time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC), "today"},
+		{time.Date(2024, time.March, 6, 0, 0, 0, 0, time.UTC), "tomorrow"},
+		{time.Date(2024, time.March, 8, 0, 0, 0, 0, time.UTC), "in 3 days"},
+		{time.Date(2024, time.April, 15, 0, 0, 0, 0, time.UTC), "in 41 days"},
+		{time.Date(2024, time.May, 5, 0, 0, 0, 0, time.UTC), "in 2 months"},
+		{time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC), "in 12 months"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, app.countdownLabel(daysUntil(tt.next, now)), tt.next.Format(time.DateOnly))
+	}
+}
+
 // TestContactsWindow_Singleton verifies the logic guarding multiple window instances.
 func TestContactsWindow_Singleton(t *testing.T) {
 	app, _, _ := setupTestApp(t)
@@ -1,18 +1,27 @@
 package ui
 
 import (
-	"fmt"
 	"log/slog"
 	"sort"
 	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/tartampluch/go-birthday/internal/config"
 	"github.com/tartampluch/go-birthday/internal/engine"
 )
 
+// ShowContact opens the contacts window focused on one contact.
+func (app *GoBirthdayApp) ShowContact(uid string) {
+	app.ShowContactsWindow()
+	if app.contactsFocus != nil {
+		app.contactsFocus(uid)
+	}
+}
+
 // ShowContactsWindow displays a window with all birthdays sorted by next occurrence.
 // It implements a singleton pattern: if the window is already open, it requests focus.
 // It uses native Fyne table headers for sorting interaction.
@@ -26,16 +35,31 @@ func (app *GoBirthdayApp) ShowContactsWindow() {
 	app.contactsWindow = app.App.NewWindow(title)
 	app.contactsWindow.Resize(fyne.NewSize(config.ContactsWinWidth, config.ContactsWinHeight))
 
-	// Create a local copy of contacts for sorting/display to avoid race conditions
-	app.ContactsMut.RLock()
-	displayContacts := make([]engine.BirthdayEntry, len(app.Contacts))
-	copy(displayContacts, app.Contacts)
-	app.ContactsMut.RUnlock()
+	// Hidden contacts are only listed on demand, so they can be restored.
+	showHidden := false
+	query := ""
+
+	// visibleContacts creates a local copy of contacts for sorting/display to avoid race conditions
+	visibleContacts := func() []engine.BirthdayEntry {
+		app.ContactsMut.RLock()
+		defer app.ContactsMut.RUnlock()
+		list := make([]engine.BirthdayEntry, 0, len(app.Contacts))
+		for _, c := range app.Contacts {
+			if (showHidden || !c.Hidden) && strings.Contains(strings.ToLower(c.Name), query) {
+				list = append(list, c)
+			}
+		}
+		return list
+	}
+	displayContacts := visibleContacts()
 
 	slog.Info(config.LogMsgOpenWin,
 		config.LogKeyComponent, config.CompUI,
 		config.LogKeyCount, len(displayContacts))
 
+	// Countdowns are computed against the time the window was opened.
+	now := app.now()
+
 	// Internal Sorting State
 	currentSortCol := config.ColIDDate
 	sortAsc := true
@@ -59,6 +83,20 @@ func (app *GoBirthdayApp) ShowContactsWindow() {
 				} else {
 					less = a.AgeNext < b.AgeNext
 				}
+			case config.ColIDSign:
+				sa, sb := engine.ZodiacSign(a.DateOfBirth), engine.ZodiacSign(b.DateOfBirth)
+				if sa == sb {
+					less = a.Name < b.Name
+				} else {
+					less = sa < sb
+				}
+			case config.ColIDDays:
+				da, db := daysUntil(a.NextOccurrence, now), daysUntil(b.NextOccurrence, now)
+				if da == db {
+					less = a.Name < b.Name
+				} else {
+					less = da < db
+				}
 			default: // config.ColIDDate
 				if a.NextOccurrence.Equal(b.NextOccurrence) {
 					// Secondary sort key: Name
@@ -88,7 +126,7 @@ func (app *GoBirthdayApp) ShowContactsWindow() {
 	table := widget.NewTable(
 		// Length callback
 		func() (int, int) {
-			return len(displayContacts), 3
+			return len(displayContacts), config.ColCount
 		},
 		// Create cell callback
 		func() fyne.CanvasObject {
@@ -102,40 +140,42 @@ func (app *GoBirthdayApp) ShowContactsWindow() {
 			}
 			c := displayContacts[id.Row]
 
+			// Highlight milestone birthdays (e.g., 18, 40) across the whole row, grey out hidden ones.
+			label.TextStyle.Bold = c.Milestone
+			switch {
+			case c.Hidden:
+				label.Importance = widget.LowImportance
+			case c.Milestone:
+				label.Importance = widget.HighImportance
+			default:
+				label.Importance = widget.MediumImportance
+			}
+
 			switch id.Col {
 			case config.ColIDName:
-				label.SetText(c.Name)
-			case config.ColIDDate:
-				// Retrieve the localized date format
-				format := app.GetMsg(config.TKeyFormatDate)
-				if format == config.TKeyFormatDate {
-					format = config.DateFormatDisplay
+				name := c.Name
+				if c.Lunar {
+					name += config.LunarMarker
+				}
+				if c.Overridden {
+					name += config.OverrideMarker
 				}
-				label.SetText(c.NextOccurrence.Format(format))
+				label.SetText(name)
+			case config.ColIDDate:
+				label.SetText(app.formatDate(c.NextOccurrence))
 
 			case config.ColIDAge:
-				if c.YearKnown {
-					if c.AgeNext == 0 {
-						// Born this year (very rare case for upcoming list unless date is exact match today for a newborn)
-						label.SetText(config.AgeBirth)
-					} else {
-						// Show transition: "PrevAge -> NextAge"
-						prevAge := c.AgeNext - 1
-						if prevAge == 0 {
-							// Special case: "Birth -> 1"
-							birthText := app.GetMsg(config.TKeyAgeBirth)
-							if birthText == config.TKeyAgeBirth {
-								birthText = "Birth" // Fallback
-							}
-							label.SetText(fmt.Sprintf("%s → %d", birthText, c.AgeNext))
-						} else {
-							// Standard case: "25 -> 26"
-							label.SetText(fmt.Sprintf("%d → %d", prevAge, c.AgeNext))
-						}
-					}
+				if age := app.ageLabel(c); age != "" {
+					label.SetText(age)
 				} else {
 					label.SetText(config.AgeUnknown)
 				}
+
+			case config.ColIDDays:
+				label.SetText(app.countdownLabel(daysUntil(c.NextOccurrence, now)))
+
+			case config.ColIDSign:
+				label.SetText(app.zodiacLabel(c))
 			}
 		},
 	)
@@ -161,6 +201,10 @@ func (app *GoBirthdayApp) ShowContactsWindow() {
 			titleKey = config.TKeyColDate
 		case config.ColIDAge:
 			titleKey = config.TKeyColAge
+		case config.ColIDDays:
+			titleKey = config.TKeyColDays
+		case config.ColIDSign:
+			titleKey = config.TKeyColSign
 		}
 
 		text := app.GetMsg(titleKey)
@@ -192,19 +236,97 @@ func (app *GoBirthdayApp) ShowContactsWindow() {
 	table.SetColumnWidth(config.ColIDName, config.ColWidthName)
 	table.SetColumnWidth(config.ColIDDate, config.ColWidthDate)
 	table.SetColumnWidth(config.ColIDAge, config.ColWidthAge)
+	if app.ageDisplay() == config.AgeDisplayNone {
+		table.SetColumnWidth(config.ColIDAge, 0) // Neither shown nor sortable
+	}
+	table.SetColumnWidth(config.ColIDDays, config.ColWidthDays)
+	table.SetColumnWidth(config.ColIDSign, 0)
+	if app.Preferences.Bool(config.PrefZodiacColumn) {
+		table.SetColumnWidth(config.ColIDSign, config.ColWidthSign)
+	}
 
 	refreshTable = func() {
 		performSort()
 		table.Refresh()
 	}
 
+	// reloadContacts picks up the results of a new synchronization.
+	reloadContacts := func() {
+		displayContacts = visibleContacts()
+		refreshTable()
+	}
+
+	checkHidden := widget.NewCheck(app.GetMsg(config.TKeyLblShowHidden), func(b bool) {
+		showHidden = b
+		reloadContacts()
+	})
+
+	search := widget.NewEntry()
+	search.PlaceHolder = app.GetMsg(config.TKeyPhSearch)
+	search.OnChanged = func(s string) {
+		query = strings.ToLower(strings.TrimSpace(s))
+		reloadContacts()
+	}
+
+	// Selecting a row opens the detail dialog (hide, date correction, copy).
+	table.OnSelected = func(id widget.TableCellID) {
+		table.UnselectAll()
+		if id.Row < 0 || id.Row >= len(displayContacts) {
+			return
+		}
+		app.showContactDetails(displayContacts[id.Row], app.contactsWindow, func() {
+			go func() {
+				app.performSync(false)
+				fyne.Do(reloadContacts)
+			}()
+		})
+	}
+
+	hint := widget.NewLabel(app.GetMsg(config.TKeyLblContactsHint))
+	hint.TextStyle = fyne.TextStyle{Italic: true}
+
+	// Export follows the current sort order.
+	btnExport := widget.NewButtonWithIcon(app.GetMsg(config.TKeyBtnExportCSV), theme.DocumentSaveIcon(), func() {
+		app.showExportDialog(displayContacts, app.contactsWindow)
+	})
+
 	// Layout Assembly
-	content := container.NewBorder(nil, nil, nil, nil, table)
+	footer := container.NewBorder(nil, nil, nil, btnExport, hint)
+	app.contactsThemed = container.NewThemeOverride(table, app.contactsTheme())
+	header := container.NewBorder(nil, nil, nil, checkHidden, search)
+	content := container.NewBorder(header, footer, nil, nil, app.contactsThemed)
 	app.contactsWindow.SetContent(content)
 
+	// Dropping a vCard file offers to use it as the contact source.
+	app.contactsWindow.SetOnDropped(func(_ fyne.Position, uris []fyne.URI) {
+		if path, ok := droppedVCard(uris); ok {
+			app.useDroppedSource(path, app.contactsWindow, reloadContacts)
+		}
+	})
+
+	// focus scrolls to a contact and opens its details (used by the tray submenu).
+	app.contactsFocus = func(uid string) {
+		for row, c := range displayContacts {
+			if c.UID == uid {
+				table.ScrollTo(widget.TableCellID{Row: row, Col: config.ColIDName})
+				table.Select(widget.TableCellID{Row: row, Col: config.ColIDName})
+				return
+			}
+		}
+	}
+
+	// Keyboard: Ctrl+F searches, Esc closes, plus the shared shortcuts.
+	app.addShortcuts(app.contactsWindow)
+	app.contactsWindow.Canvas().AddShortcut(
+		&desktop.CustomShortcut{KeyName: config.ShortcutKeySearch, Modifier: fyne.KeyModifierShortcutDefault},
+		func(fyne.Shortcut) { app.contactsWindow.Canvas().Focus(search) })
+	closeOnEscape(app.contactsWindow)
+
 	// Cleanup on close
 	app.contactsWindow.SetOnClosed(func() {
 		app.contactsWindow = nil
+		app.contactsFocus = nil
+		app.contactsThemed = nil
 	})
 
 	app.contactsWindow.Show()
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/systemd"
+)
+
+// RunDaemon runs the calendar server and the periodic synchronizations without any tray or window
+// (--daemon), until the context ends. Under systemd, readiness is reported once the port listens,
+// and the outcome of every synchronization is shown by "systemctl status".
+func (app *GoBirthdayApp) RunDaemon() error {
+	app.Daemon = true
+	app.SetupI18n()
+	app.ApplyLogLevel()
+	_, _ = app.openSecrets() // Logged; secrets are then unavailable
+	app.watchPreferences()
+	app.loadCalendarCache()
+	app.loadRevisionsCache()
+	app.loadContactsCache()
+
+	slog.Info(config.MsgDaemonStart, config.LogKeyComponent, config.CompUI)
+	go app.backgroundWorker()
+	defer app.sdNotify(config.SdStopping)
+
+	if !app.Preferences.BoolWithFallback(config.PrefServeHTTP, true) {
+		slog.Info(config.MsgServerDisabled, config.LogKeyComponent, config.CompUI)
+		app.sdNotify(config.SdReady)
+		<-app.Ctx.Done()
+		app.Server.Close()
+		return nil
+	}
+	app.Server.Ready = func() { app.sdNotify(config.SdReady) }
+	return app.Server.Start(app.Ctx)
+}
+
+// Reload applies the saved settings again and synchronizes at once (SIGHUP in daemon mode).
+// The preferences file is already watched; this also picks up changes outside of it,
+// such as the contacts of a local source or the secrets.
+func (app *GoBirthdayApp) Reload() {
+	slog.Info(config.MsgDaemonReload, config.LogKeyComponent, config.CompUI)
+	app.sdNotify(config.SdReloading)
+	app.ApplyLogLevel()
+	app.applyPort()
+	_ = app.performSync(false) // Logged
+	app.sdNotify(config.SdReady)
+}
+
+// sdNotify reports a state to systemd in daemon mode.
+func (app *GoBirthdayApp) sdNotify(state string) {
+	if !app.Daemon {
+		return
+	}
+	if err := systemd.Notify(state); err != nil {
+		slog.Warn(config.ErrSdNotify,
+			config.LogKeyComponent, config.CompSystemd,
+			config.LogKeyError, err)
+	}
+}
+
+// sdStatus shows the count of today's birthdays (or the failure) of the last synchronization in systemd.
+func (app *GoBirthdayApp) sdStatus(count int) {
+	status := fmt.Sprintf(config.MsgDaemonStatus, count)
+	if count < 0 {
+		status = config.MsgSyncFailed
+	}
+	app.sdNotify(fmt.Sprintf(config.SdStatus, status))
+}
@@ -0,0 +1,34 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// dateReplacer converts date patterns (e.g., "dd/MM/yyyy") into Go layouts (e.g., "02/01/2006").
+var dateReplacer = strings.NewReplacer(config.DateTokens...)
+
+// goDateLayout translates a user date pattern into a Go time layout.
+func goDateLayout(pattern string) string {
+	return dateReplacer.Replace(pattern)
+}
+
+// dateLayout returns the layout for displayed dates: the user's pattern when set,
+// otherwise the localized format identified by key (or fallback if missing).
+func (app *GoBirthdayApp) dateLayout(key, fallback string) string {
+	if pattern := strings.TrimSpace(app.Preferences.String(config.PrefDateFormat)); pattern != "" {
+		return goDateLayout(pattern)
+	}
+	format := app.GetMsg(key)
+	if format == key {
+		format = fallback
+	}
+	return format
+}
+
+// formatDate renders a date for display with the user's preferred format.
+func (app *GoBirthdayApp) formatDate(t time.Time) string {
+	return t.Format(app.dateLayout(config.TKeyFormatDate, config.DateFormatDisplay))
+}
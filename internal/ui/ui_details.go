@@ -0,0 +1,275 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+)
+
+// hiddenContacts returns the UIDs of the contacts excluded from the calendar.
+func (app *GoBirthdayApp) hiddenContacts() map[string]bool {
+	return app.uidSet(config.PrefHiddenContacts)
+}
+
+// setHidden adds or removes a contact from the hidden list.
+func (app *GoBirthdayApp) setHidden(uid string, hide bool) {
+	app.setUID(config.PrefHiddenContacts, uid, hide)
+}
+
+// lunarContacts returns the UIDs of the contacts celebrating their birthday by the lunar calendar.
+func (app *GoBirthdayApp) lunarContacts() map[string]bool {
+	return app.uidSet(config.PrefLunarContacts)
+}
+
+// uidSet returns the contact UIDs stored in a list preference.
+func (app *GoBirthdayApp) uidSet(pref string) map[string]bool {
+	set := make(map[string]bool)
+	for _, uid := range app.Preferences.StringList(pref) {
+		set[uid] = true
+	}
+	return set
+}
+
+// setUID adds or removes a contact UID from a list preference.
+func (app *GoBirthdayApp) setUID(pref, uid string, add bool) {
+	var uids []string
+	for _, u := range app.Preferences.StringList(pref) {
+		if u != uid {
+			uids = append(uids, u)
+		}
+	}
+	if add {
+		uids = append(uids, uid)
+	}
+	app.Preferences.SetStringList(pref, uids)
+}
+
+// exactAge returns the elapsed years, months and days between birth and now.
+// Days are counted from the last monthly anniversary, which is clamped to the end of
+// shorter months (e.g., born on the 31st: the February anniversary is the 28th or 29th).
+func exactAge(birth, now time.Time) (years, months, days int) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	total := (today.Year()-birth.Year())*12 + int(today.Month()) - int(birth.Month())
+	anchor := addMonthsClamped(birth, total)
+	if anchor.After(today) {
+		total--
+		anchor = addMonthsClamped(birth, total)
+	}
+
+	return total / 12, total % 12, daysUntil(today, anchor)
+}
+
+// addMonthsClamped adds n months to t, keeping the day within the target month.
+func addMonthsClamped(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return time.Date(first.Year(), first.Month(), min(t.Day(), lastDay), 0, 0, 0, 0, time.UTC)
+}
+
+// daysUntil returns the number of calendar days from now to the given date.
+func daysUntil(next, now time.Time) int {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	day := time.Date(next.Year(), next.Month(), next.Day(), 0, 0, 0, 0, time.UTC)
+	return int(day.Sub(today).Hours() / 24)
+}
+
+// ageDisplay returns the age display preference.
+func (app *GoBirthdayApp) ageDisplay() string {
+	return app.Preferences.StringWithFallback(config.PrefAgeDisplay, config.DefaultAgeDisplay)
+}
+
+// ageTurning returns the age c turns at the next birthday, or "" when ages are hidden
+// or the year of birth is unknown. Compact labels (tray, notifications) always use it.
+func (app *GoBirthdayApp) ageTurning(c engine.BirthdayEntry) string {
+	if !c.YearKnown || app.ageDisplay() == config.AgeDisplayNone {
+		return ""
+	}
+	return strconv.Itoa(c.AgeNext)
+}
+
+// ageLabel formats the age of c for the contacts table as the age display preference asks:
+// "29 → 30", "30", or "" when ages are hidden or the year of birth is unknown.
+func (app *GoBirthdayApp) ageLabel(c engine.BirthdayEntry) string {
+	if app.ageDisplay() != config.AgeDisplayTransition || !c.YearKnown {
+		return app.ageTurning(c)
+	}
+
+	switch c.AgeNext {
+	case 0:
+		// Born this year (very rare case for upcoming list unless date is exact match today for a newborn)
+		return config.AgeBirth
+	case 1:
+		// Special case: "Birth -> 1"
+		birthText := app.GetMsg(config.TKeyAgeBirth)
+		if birthText == config.TKeyAgeBirth {
+			birthText = "Birth" // Fallback
+		}
+		return fmt.Sprintf(config.FormatAgeTransition, birthText, c.AgeNext)
+	default:
+		// Standard case: "25 -> 26"
+		return fmt.Sprintf(config.FormatAgeTransition, strconv.Itoa(c.AgeNext-1), c.AgeNext)
+	}
+}
+
+// zodiacLabel returns the zodiac sign of a contact with its emoji (e.g., "♌ Leo").
+func (app *GoBirthdayApp) zodiacLabel(c engine.BirthdayEntry) string {
+	sign := engine.ZodiacSign(c.DateOfBirth)
+	return config.ZodiacEmojis[sign] + " " + app.GetMsg(config.TKeyZodiacSigns[sign])
+}
+
+// countdownLabel describes a delay in days: "today", "in 3 days", then "in 2 months".
+func (app *GoBirthdayApp) countdownLabel(days int) string {
+	switch {
+	case days <= 0:
+		return app.GetMsg(config.TKeyDaysToday)
+	case days < config.CountdownMonthDays:
+		return app.GetMsgCount(config.TKeyCountdownDays, days)
+	default:
+		months := int(math.Round(float64(days) / config.AvgDaysPerMonth))
+		return app.GetMsgCount(config.TKeyCountdownMonths, months)
+	}
+}
+
+// sourceLabel describes the configured contact source without credentials or query strings.
+func (app *GoBirthdayApp) sourceLabel() string {
+	switch app.Preferences.String(config.PrefSourceMode) {
+	case config.SourceModeLocal:
+		return app.Preferences.String(config.PrefLocalPath)
+	case config.SourceModeSystem:
+		return app.GetMsg(config.TKeyModeSystem)
+	case config.SourceModeOutlook:
+		return app.GetMsg(config.TKeyModeOutlook)
+	case config.SourceModeExec:
+		return app.Preferences.String(config.PrefExecCommand)
+	case config.SourceModeThunderbird:
+		if path := app.Preferences.String(config.PrefThunderbirdPath); path != "" {
+			return path
+		}
+		return app.GetMsg(config.TKeyModeThunderbird)
+	}
+	if mode := app.Preferences.String(config.PrefSourceMode); slices.Contains(engine.Sources(), mode) {
+		return mode
+	}
+	raw := app.Preferences.String(config.PrefCardDAVURL)
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		return u.Scheme + "://" + u.Host + u.Path
+	}
+	return raw
+}
+
+// contactDetails returns the label/value pairs shown in the detail dialog.
+func (app *GoBirthdayApp) contactDetails(c engine.BirthdayEntry) [][2]string {
+	now := app.now()
+
+	age := config.AgeUnknown
+	if c.YearKnown {
+		y, m, d := exactAge(c.DateOfBirth, now)
+		age = app.GetMsgData(config.TKeyAgeExact, map[string]interface{}{"Years": y, "Months": m, "Days": d})
+	}
+	// Without ages, the year of birth would give it away.
+	dob := formatOverride(c.DateOfBirth, c.YearKnown)
+	if app.ageDisplay() == config.AgeDisplayNone {
+		age = config.AgeUnknown
+		dob = formatOverride(c.DateOfBirth, false)
+	}
+
+	left := app.GetMsg(config.TKeyDaysToday)
+	if n := daysUntil(c.NextOccurrence, now); n > 0 {
+		left = app.GetMsgData(config.TKeyDaysLeft, map[string]interface{}{"Days": n})
+	}
+
+	rows := [][2]string{
+		{app.GetMsg(config.TKeyLblDOB), dob},
+		{app.GetMsg(config.TKeyLblExactAge), age},
+		{app.GetMsg(config.TKeyLblDaysLeft), left},
+	}
+	// The title shows Name: the other of the full name and the nickname goes below.
+	if c.FullName != "" && c.FullName != c.Name {
+		rows = append(rows, [2]string{app.GetMsg(config.TKeyLblFullName), c.FullName})
+	} else if c.Nickname != "" && c.Nickname != c.Name {
+		rows = append(rows, [2]string{app.GetMsg(config.TKeyLblNickname), c.Nickname})
+	}
+	if c.Note != "" {
+		rows = append(rows, [2]string{app.GetMsg(config.TKeyLblNote), c.Note})
+	}
+	return append(rows,
+		[2]string{app.GetMsg(config.TKeyLblContactSrc), app.sourceLabel()},
+		[2]string{app.GetMsg(config.TKeyLblUID), c.UID},
+	)
+}
+
+// showContactDetails opens the detail dialog of a contact with its quick actions.
+// onChanged is called after an action that requires a new synchronization.
+func (app *GoBirthdayApp) showContactDetails(c engine.BirthdayEntry, parent fyne.Window, onChanged func()) {
+	details := app.contactDetails(c)
+
+	form := widget.NewForm()
+	for _, row := range details {
+		value := widget.NewLabel(row[1])
+		value.Wrapping = fyne.TextWrapBreak
+		value.Selectable = true
+		form.Append(row[0], value)
+	}
+
+	var d dialog.Dialog
+
+	hideKey, hideIcon := config.TKeyBtnHide, theme.VisibilityOffIcon()
+	if c.Hidden {
+		hideKey, hideIcon = config.TKeyBtnUnhide, theme.VisibilityIcon()
+	}
+	btnHide := widget.NewButtonWithIcon(app.GetMsg(hideKey), hideIcon, func() {
+		app.setHidden(c.UID, !c.Hidden)
+		d.Hide()
+		if onChanged != nil {
+			onChanged()
+		}
+	})
+
+	btnEdit := widget.NewButtonWithIcon(app.GetMsg(config.TKeyBtnEditDate), theme.DocumentCreateIcon(), func() {
+		d.Hide()
+		app.showOverrideDialog(c, parent, onChanged)
+	})
+
+	btnOffsets := widget.NewButtonWithIcon(app.GetMsg(config.TKeyBtnOffsets), theme.HistoryIcon(), func() {
+		d.Hide()
+		app.showOffsetsDialog(c, parent, onChanged)
+	})
+
+	btnCopy := widget.NewButtonWithIcon(app.GetMsg(config.TKeyBtnCopy), theme.ContentCopyIcon(), func() {
+		text := c.Name
+		for _, row := range details {
+			text += "\n" + row[0] + " " + row[1]
+		}
+		app.App.Clipboard().SetContent(text)
+	})
+
+	// The lunar date of birth is only known with the year.
+	checkLunar := widget.NewCheck(app.GetMsg(config.TKeyLblLunar), nil)
+	checkLunar.Checked = app.lunarContacts()[c.UID]
+	checkLunar.OnChanged = func(on bool) {
+		app.setUID(config.PrefLunarContacts, c.UID, on)
+		d.Hide()
+		if onChanged != nil {
+			onChanged()
+		}
+	}
+	if !c.YearKnown {
+		checkLunar.Disable()
+	}
+
+	actions := container.NewHBox(btnHide, btnEdit, btnOffsets, btnCopy)
+	d = dialog.NewCustom(c.Name, app.GetMsg(config.TKeyBtnClose), container.NewVBox(form, checkLunar, actions), parent)
+	d.Show()
+}
@@ -0,0 +1,215 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+	"github.com/tartampluch/go-birthday/internal/secrets"
+)
+
+// DoctorCheck is the outcome of one diagnostic.
+type DoctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // config.DoctorOK, config.DoctorWarn or config.DoctorFail
+	Detail string `json:"detail,omitempty"`
+}
+
+// DoctorReport is the outcome of Doctor; OK is false if any check failed.
+type DoctorReport struct {
+	OK     bool          `json:"ok"`
+	Checks []DoctorCheck `json:"checks"`
+}
+
+// Doctor validates the saved configuration without starting the UI: keyring access, source
+// reachability and authentication, parsing of its first cards vCards (all if cards <= 0),
+// availability of the server port and completeness of the translations.
+func (app *GoBirthdayApp) Doctor(ctx context.Context, cards int) DoctorReport {
+	checks := []DoctorCheck{app.checkKeyring()} // Before the source, whose passwords it holds
+	checks = append(checks, app.checkSource(ctx, cards)...)
+	checks = append(checks, app.checkPort(), app.checkLocales())
+
+	report := DoctorReport{OK: true, Checks: checks}
+	for _, c := range checks {
+		if c.Status == config.DoctorFail {
+			report.OK = false
+		}
+	}
+	return report
+}
+
+// checkKeyring opens the secret storage as the application does, and reads from it.
+func (app *GoBirthdayApp) checkKeyring() DoctorCheck {
+	check := DoctorCheck{Name: config.DoctorCheckKeyring, Status: config.DoctorOK}
+	backend, err := app.openSecrets()
+	if err == nil {
+		if _, err = secrets.Get(config.KeyringProbe); errors.Is(err, secrets.ErrNotFound) {
+			err = nil
+		}
+	}
+	if err != nil {
+		check.Status, check.Detail = config.DoctorFail, err.Error()
+		return check
+	}
+	check.Detail = fmt.Sprintf(config.DoctorMsgKeyring, backend)
+	return check
+}
+
+// checkSource reads the first vCards of the source: one check for reaching it, one for its content.
+func (app *GoBirthdayApp) checkSource(ctx context.Context, cards int) []DoctorCheck {
+	ctx, cancel := context.WithTimeout(ctx, config.DoctorTimeout)
+	defer cancel()
+
+	cfg := app.loadSyncConfig()
+	source := DoctorCheck{Name: config.DoctorCheckSource, Status: config.DoctorOK}
+	vcards := DoctorCheck{Name: config.DoctorCheckVCards, Status: config.DoctorOK}
+
+	gen := &engine.Generator{Clock: app.Clock, Fetcher: app.Fetcher}
+	res, err := gen.Probe(ctx, cfg, cards)
+	switch {
+	case err != nil:
+		source.Status, source.Detail = config.DoctorFail, err.Error()
+		vcards.Status, vcards.Detail = config.DoctorWarn, config.DoctorMsgSkipped
+		return []DoctorCheck{source, vcards}
+	case res.Cards+res.Malformed == 0:
+		source.Status, source.Detail = config.DoctorWarn, fmt.Sprintf(config.DoctorMsgNoCards, cfg.Mode)
+		vcards.Status, vcards.Detail = config.DoctorWarn, config.DoctorMsgSkipped
+		return []DoctorCheck{source, vcards}
+	}
+
+	source.Detail = fmt.Sprintf(config.DoctorMsgSource, cfg.Mode, res.Cards+res.Malformed)
+	vcards.Detail = fmt.Sprintf(config.DoctorMsgVCards, res.Birthdays, res.BadDates, res.Malformed)
+	if res.Birthdays == 0 || res.BadDates > 0 || res.Malformed > 0 {
+		vcards.Status = config.DoctorWarn
+	}
+	if res.Birthdays == 0 {
+		vcards.Detail += config.DoctorDetailSep + config.DoctorMsgNoBirthday
+	}
+	return []DoctorCheck{source, vcards}
+}
+
+// checkPort tries to listen where the calendar server would. A busy port is only a warning,
+// since it is also busy while the application runs.
+func (app *GoBirthdayApp) checkPort() DoctorCheck {
+	check := DoctorCheck{Name: config.DoctorCheckPort, Status: config.DoctorOK}
+	if !app.Preferences.BoolWithFallback(config.PrefServeHTTP, true) {
+		check.Detail = config.DoctorMsgServeOff
+		return check
+	}
+
+	if n, err := strconv.Atoi(app.Server.Port()); err != nil || n < config.MinPort || n > config.MaxPort {
+		check.Status, check.Detail = config.DoctorFail, config.ErrPortRange
+		return check
+	}
+	addrs := app.Server.Addrs()
+	for _, addr := range addrs {
+		ln, err := net.Listen(config.NetworkTCP, addr)
+		if err != nil {
+			check.Status, check.Detail = config.DoctorWarn, fmt.Sprintf(config.DoctorMsgPortBusy, addr, err)
+			return check
+		}
+		_ = ln.Close()
+	}
+	check.Detail = fmt.Sprintf(config.DoctorMsgPortFree, strings.Join(addrs, config.ListSeparator+" "))
+	return check
+}
+
+// checkLocales compares the keys of every translation with the English ones. Gaps in the
+// embedded translations fail; those of a language only provided by the user are warnings,
+// since its missing texts fall back to English.
+func (app *GoBirthdayApp) checkLocales() DoctorCheck {
+	check := DoctorCheck{Name: config.DoctorCheckLocales, Status: config.DoctorOK}
+	embedded, err := localeKeys(localeFS, config.LocalesDir)
+	if err != nil {
+		check.Status, check.Detail = config.DoctorFail, err.Error()
+		return check
+	}
+	user := make(map[string]map[string]bool)
+	if dir := app.userLocalesDir(); dir != "" {
+		if user, err = localeKeys(os.DirFS(dir), "."); errors.Is(err, fs.ErrNotExist) {
+			user, err = make(map[string]map[string]bool), nil // No user translations
+		}
+		if err != nil {
+			check.Status, check.Detail = config.DoctorFail, err.Error()
+			return check
+		}
+	}
+
+	langs := make([]string, 0, len(embedded)+len(user))
+	for lang := range embedded {
+		langs = append(langs, lang)
+	}
+	for lang := range user {
+		if embedded[lang] == nil {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs)
+
+	reference := embedded[config.DefaultLanguage]
+	var gaps []string
+	for _, lang := range langs {
+		var missing []string
+		for key := range reference {
+			if !embedded[lang][key] && !user[lang][key] {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		sort.Strings(missing)
+		sample := missing[:min(len(missing), config.DoctorLocaleSample)]
+		gaps = append(gaps, fmt.Sprintf(config.DoctorMsgLocaleGap, lang, len(missing), strings.Join(sample, config.DoctorLocaleKeysJoin)))
+		if embedded[lang] != nil {
+			check.Status = config.DoctorFail
+		} else if check.Status == config.DoctorOK {
+			check.Status = config.DoctorWarn
+		}
+	}
+	if len(gaps) > 0 {
+		check.Detail = strings.Join(gaps, config.DoctorDetailSep)
+		return check
+	}
+	check.Detail = fmt.Sprintf(config.DoctorMsgLocales, len(langs))
+	return check
+}
+
+// localeKeys reads the message IDs of every translation file of dir, by language.
+func localeKeys(fsys fs.FS, dir string) (map[string]map[string]bool, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]map[string]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, config.LocalePrefix) || !strings.HasSuffix(name, config.LocaleSuffix) {
+			continue
+		}
+		lang := strings.TrimSuffix(strings.TrimPrefix(name, config.LocalePrefix), config.LocaleSuffix)
+		data, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		var messages map[string]json.RawMessage
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf(config.DoctorMsgLocaleBad, name, err)
+		}
+		keys[lang] = make(map[string]bool, len(messages))
+		for key := range messages {
+			keys[lang][key] = true
+		}
+	}
+	return keys, nil
+}
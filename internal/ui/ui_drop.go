@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// droppedVCard returns the local path of the first vCard file among dropped items.
+func droppedVCard(uris []fyne.URI) (string, bool) {
+	for _, u := range uris {
+		if u == nil || u.Scheme() != config.SchemeFile {
+			continue
+		}
+		ext := strings.ToLower(u.Extension())
+		if ext == config.ExtVCF || ext == config.ExtVCard {
+			return u.Path(), true
+		}
+	}
+	return "", false
+}
+
+// useDroppedSource makes a dropped vCard file the local source and synchronizes it.
+// The user confirms first, since this replaces the current source.
+func (app *GoBirthdayApp) useDroppedSource(path string, parent fyne.Window, onSynced func()) {
+	msg := fmt.Sprintf(app.GetMsg(config.TKeyConfirmDrop), path)
+	dialog.ShowConfirm(app.GetMsg(config.TKeyTitleDrop), msg, func(ok bool) {
+		if !ok {
+			return
+		}
+		slog.Info(config.MsgSourceDropped,
+			config.LogKeyComponent, config.CompUI,
+			config.LogKeyFile, path)
+
+		app.Preferences.SetString(config.PrefSourceMode, config.SourceModeLocal)
+		app.Preferences.SetString(config.PrefLocalPath, path)
+
+		go func() {
+			app.performSync(true)
+			if onSynced != nil {
+				fyne.Do(onSynced)
+			}
+		}()
+	}, parent)
+}
@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"encoding/csv"
+	"io"
+	"log/slog"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+)
+
+// writeContactsCSV writes the contacts, in the given order, as CSV with a localized header.
+// Dates use ISO 8601; unknown birth years are written as "--MM-DD" and leave the age empty.
+func (app *GoBirthdayApp) writeContactsCSV(w io.Writer, contacts []engine.BirthdayEntry) error {
+	cw := csv.NewWriter(w)
+	header := []string{
+		app.GetMsg(config.TKeyColName),
+		app.GetMsg(config.TKeyColDOB),
+		app.GetMsg(config.TKeyColNext),
+		app.GetMsg(config.TKeyColAge),
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, c := range contacts {
+		age := ""
+		if c.YearKnown {
+			age = strconv.Itoa(c.AgeNext)
+		}
+		record := []string{
+			c.Name,
+			formatOverride(c.DateOfBirth, c.YearKnown),
+			c.NextOccurrence.Format(config.DateFormatFullDash),
+			age,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// showExportDialog asks for a destination file and exports the contacts to it.
+func (app *GoBirthdayApp) showExportDialog(contacts []engine.BirthdayEntry, parent fyne.Window) {
+	d := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, parent)
+			return
+		}
+		if wc == nil {
+			return // Cancelled
+		}
+		defer func() { _ = wc.Close() }()
+
+		if err := app.writeContactsCSV(wc, contacts); err != nil {
+			dialog.ShowError(err, parent)
+			return
+		}
+		slog.Info(config.MsgCSVExported,
+			config.LogKeyComponent, config.CompUI,
+			config.LogKeyFile, wc.URI().Path(),
+			config.LogKeyCount, len(contacts))
+	}, parent)
+	d.SetFileName(config.ExportFileName)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{config.ExtCSV}))
+	d.Show()
+}
+
+// ShowExportCalendar saves the calendar currently served by the local HTTP server to a
+// user-chosen file, for manual import into calendar apps.
+// A tray application has no window, so a temporary one hosts the file dialog.
+func (app *GoBirthdayApp) ShowExportCalendar() {
+	if !app.Server.HasCalendar() {
+		app.App.SendNotification(fyne.NewNotification(config.AppName, app.GetMsg(config.TKeyNotifNoCal)))
+		return
+	}
+
+	w := app.App.NewWindow(app.GetMsg(config.TKeyMenuExport))
+	w.Resize(fyne.NewSize(config.ExportWinWidth, config.ExportWinHeight))
+
+	d := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if wc == nil {
+			return // Cancelled
+		}
+		defer func() { _ = wc.Close() }()
+
+		size, err := app.Server.CopyTo(wc)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		slog.Info(config.MsgICSExported,
+			config.LogKeyComponent, config.CompUI,
+			config.LogKeyFile, wc.URI().Path(),
+			config.LogKeySizeBytes, size)
+		app.App.SendNotification(fyne.NewNotification(config.AppName, app.GetMsg(config.TKeyNotifExported)))
+	}, w)
+	d.SetFileName(config.ExportCalFileName)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{config.ExtICS}))
+	d.SetOnClosed(w.Close)
+	d.Resize(fyne.NewSize(config.ExportWinWidth, config.ExportWinHeight))
+
+	w.Show()
+	d.Show()
+}
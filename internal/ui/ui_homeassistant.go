@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// showHomeAssistantExample shows a Home Assistant configuration reading the JSON endpoint on port,
+// ready to be copied into configuration.yaml.
+func (app *GoBirthdayApp) showHomeAssistantExample(w fyne.Window, port string) {
+	example := fmt.Sprintf(config.FormatHomeAssistantYAML, port)
+
+	help := widget.NewLabel(app.GetMsg(config.TKeyHelpHomeAssistant))
+	help.Wrapping = fyne.TextWrapWord
+	code := widget.NewLabel(example)
+	code.TextStyle = fyne.TextStyle{Monospace: true}
+
+	btnCopy := widget.NewButtonWithIcon(app.GetMsg(config.TKeyBtnCopy), theme.ContentCopyIcon(), func() {
+		app.App.Clipboard().SetContent(example)
+	})
+
+	d := dialog.NewCustom(config.TitleHomeAssistant, app.GetMsg(config.TKeyBtnClose), container.NewVBox(help, code, btnCopy), w)
+	d.Resize(fyne.NewSize(config.SettingsWindowWidth, d.MinSize().Height))
+	d.Show()
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/hook"
+)
+
+// runSyncHook runs the shell command configured for the outcome of a synchronization
+// (a nil syncErr for a success), in the background so that a slow hook never delays the next sync.
+func (app *GoBirthdayApp) runSyncHook(syncErr error, changed bool, contacts, today int) {
+	pref := config.PrefHookSuccess
+	if syncErr != nil {
+		pref = config.PrefHookFailure
+	}
+	command := app.Preferences.String(pref)
+	if command == "" {
+		return
+	}
+
+	env := map[string]string{
+		config.HookEnvEvent: config.HookEventSuccess,
+		config.HookEnvURL:   fmt.Sprintf(config.FormatBaseURL, app.Server.Port()) + config.RouteCalendarFile,
+	}
+	if syncErr != nil {
+		env[config.HookEnvEvent] = config.HookEventFailure
+		env[config.HookEnvError] = syncErr.Error()
+	} else {
+		env[config.HookEnvContacts] = strconv.Itoa(contacts)
+		env[config.HookEnvToday] = strconv.Itoa(today)
+		env[config.HookEnvChanged] = "0"
+		if changed {
+			env[config.HookEnvChanged] = "1"
+		}
+		if dir, err := app.cacheDir(); err == nil {
+			env[config.HookEnvICS] = filepath.Join(dir, config.CacheICSFile)
+		}
+	}
+
+	app.hooks.Add(1)
+	go func() {
+		defer app.hooks.Done()
+		if err := hook.Run(app.Ctx, command, env); err != nil {
+			slog.Warn(config.ErrHook,
+				config.LogKeyComponent, config.CompUI,
+				config.LogKeyError, err)
+		}
+	}()
+}
@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"log/slog"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// ApplyLogLevel sets the log level from the --debug flag and the "Debug logging" preference.
+// It takes effect immediately because the logger reads LogLevel on every record.
+func (app *GoBirthdayApp) ApplyLogLevel() {
+	if app.LogLevel == nil {
+		return
+	}
+
+	level := slog.LevelInfo
+	if app.DebugFlag || app.Preferences.Bool(config.PrefDebugLogging) {
+		level = slog.LevelDebug
+	}
+	if app.LogLevel.Level() == level {
+		return
+	}
+
+	app.LogLevel.Set(level)
+	slog.Info(config.MsgLogLevel,
+		config.LogKeyLevel, level.String(),
+		config.LogKeyComponent, config.CompUI)
+}
@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// ShowLogsWindow displays the end of the current log file, refreshed while the window is open.
+// Users can filter by level and copy the lines to attach them to a bug report.
+func (app *GoBirthdayApp) ShowLogsWindow() {
+	if app.logsWindow != nil {
+		app.logsWindow.RequestFocus()
+		return
+	}
+
+	w := app.App.NewWindow(app.GetMsg(config.TKeyWinLogs))
+	app.logsWindow = w
+	w.Resize(fyne.NewSize(config.LogsWinWidth, config.LogsWinHeight))
+
+	text := widget.NewLabel("")
+	text.TextStyle = fyne.TextStyle{Monospace: true}
+	text.Selectable = true
+	scroll := container.NewScroll(text)
+
+	minLevel := slog.LevelInfo
+	reload := func() {
+		content, err := readLogTail(app.LogPath, minLevel, config.LogViewerMaxLines)
+		if err != nil {
+			slog.Debug(config.ErrLogRead,
+				config.LogKeyComponent, config.CompUI,
+				config.LogKeyError, err)
+			content = app.GetMsg(config.TKeyLogsEmpty)
+		}
+		if content == text.Text {
+			return
+		}
+		text.SetText(content)
+		scroll.ScrollToBottom()
+	}
+
+	levels := widget.NewSelect(config.LogViewerLevels, func(s string) {
+		var l slog.Level
+		if l.UnmarshalText([]byte(s)) == nil {
+			minLevel = l
+			reload()
+		}
+	})
+	levels.SetSelected(minLevel.String())
+
+	btnCopy := widget.NewButtonWithIcon(app.GetMsg(config.TKeyBtnCopy), theme.ContentCopyIcon(), func() {
+		app.App.Clipboard().SetContent(text.Text)
+	})
+
+	header := container.NewHBox(widget.NewLabel(app.GetMsg(config.TKeyLblLogLevel)), levels)
+	w.SetContent(container.NewBorder(container.NewBorder(nil, nil, header, btnCopy), nil, nil, nil, scroll))
+
+	// Tail the file until the window is closed.
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(config.LogViewerRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-app.Ctx.Done():
+				return
+			case <-ticker.C:
+				fyne.Do(reload)
+			}
+		}
+	}()
+
+	closeOnEscape(w)
+	w.SetOnClosed(func() {
+		close(done)
+		app.logsWindow = nil
+	})
+	w.Show()
+}
+
+// readLogTail returns the last lines of the JSON log file at path, keeping records at minLevel or above.
+// Lines that are not JSON records (e.g., panics) are always kept.
+func readLogTail(path string, minLevel slog.Level, maxLines int) (string, error) {
+	if path == "" {
+		return "", os.ErrNotExist
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	offset := info.Size() - config.LogViewerMaxBytes
+	if offset > 0 {
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return "", err
+		}
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	if offset > 0 {
+		// Drop the partial first line.
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+
+	var kept []string
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		if line != "" && logLineLevel(line) >= minLevel {
+			kept = append(kept, line)
+		}
+	}
+	if len(kept) > maxLines {
+		kept = kept[len(kept)-maxLines:]
+	}
+	return strings.Join(kept, "\n"), nil
+}
+
+// logLineLevel reads the level of a JSON log record, treating unknown lines as errors so they stay visible.
+func logLineLevel(line string) slog.Level {
+	var record map[string]any
+	if json.Unmarshal([]byte(line), &record) != nil {
+		return slog.LevelError
+	}
+	s, _ := record[config.LogJSONLevelKey].(string)
+	var l slog.Level
+	if l.UnmarshalText([]byte(s)) != nil {
+		return slog.LevelError
+	}
+	return l
+}
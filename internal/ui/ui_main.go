@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// showMainWindow opens the fallback window exposing the tray actions.
+// It is used with --window or when the driver has no system tray (e.g., some Wayland setups).
+// Closing it quits the application, like the tray "Quit" entry.
+func (app *GoBirthdayApp) showMainWindow() {
+	app.mainWindow = app.App.NewWindow(config.AppName)
+	app.mainStatus = widget.NewLabel(config.FallbackTrayLabel)
+	if app.TrayStatusItem != nil {
+		app.mainStatus.SetText(app.TrayStatusItem.Label)
+	}
+	app.mainStatus.TextStyle = fyne.TextStyle{Bold: true}
+	app.mainStatus.Alignment = fyne.TextAlignCenter
+
+	app.mainWindow.SetContent(app.buildMainContent())
+	app.mainShortcut = app.addShortcuts(app.mainWindow)
+	app.mainWindow.Resize(fyne.NewSize(config.MainWindowWidth, app.mainWindow.Content().MinSize().Height))
+	app.mainWindow.SetMaster()
+	app.mainWindow.Show()
+}
+
+// buildMainContent lays out the status and the action buttons of the main window.
+func (app *GoBirthdayApp) buildMainContent() fyne.CanvasObject {
+	btnContacts := widget.NewButtonWithIcon(app.GetMsg(config.TKeyBtnContacts), theme.AccountIcon(), app.ShowContactsWindow)
+	btnContacts.Importance = widget.HighImportance
+	btnRefresh := widget.NewButtonWithIcon(app.GetMsg(config.TKeyMenuRefresh), theme.ViewRefreshIcon(), func() {
+		go app.performSync(true)
+	})
+	btnExport := widget.NewButtonWithIcon(app.GetMsg(config.TKeyMenuExport), theme.DocumentSaveIcon(), app.ShowExportCalendar)
+	btnSettings := widget.NewButtonWithIcon(app.GetMsg(config.TKeyMenuSettings), theme.SettingsIcon(), app.ShowSettingsWindow)
+	btnLogs := widget.NewButtonWithIcon(app.GetMsg(config.TKeyMenuLogs), theme.ListIcon(), app.ShowLogsWindow)
+
+	hint := widget.NewLabel(app.GetMsg(config.TKeyLblNoTray))
+	hint.TextStyle = fyne.TextStyle{Italic: true}
+	hint.Wrapping = fyne.TextWrapWord
+
+	return container.NewPadded(container.NewVBox(
+		app.mainStatus,
+		btnContacts,
+		container.NewGridWithColumns(config.LayoutColumnsDouble, btnRefresh, btnExport),
+		container.NewGridWithColumns(config.LayoutColumnsDouble, btnSettings, btnLogs),
+		hint,
+	))
+}
+
+// updateMainStatus mirrors the tray status label in the main window, if open.
+func (app *GoBirthdayApp) updateMainStatus(label string) {
+	if app.mainStatus == nil {
+		return
+	}
+	status := app.mainStatus
+	fyne.Do(func() { status.SetText(label) })
+}
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/prefs"
+)
+
+// migrations upgrade the preferences saved by older versions, in version order.
+var migrations = []prefs.Step{
+	{Version: "1.1.0", Name: "reminder_triggers", Apply: migrateReminderTriggers},
+}
+
+// Migrate upgrades the preferences saved by an older version, and records the running one.
+// It returns the names of the migrations applied.
+func (app *GoBirthdayApp) Migrate() []string {
+	return prefs.Migrate(app.Preferences, config.Version, migrations)
+}
+
+// migrateReminderTriggers converts the single reminder of the versions without multiple
+// reminders (value, unit and direction) into the list of triggers, and removes it.
+func migrateReminderTriggers(p fyne.Preferences) {
+	legacy := []string{config.PrefReminderValue, config.PrefReminderUnit, config.PrefReminderDir}
+	found := false
+	for _, key := range legacy {
+		if _, ok := prefs.Lookup(p, key); ok {
+			found = true
+		}
+	}
+	if !found {
+		return
+	}
+	if len(p.StringList(config.PrefReminderTriggers)) == 0 {
+		p.SetStringList(config.PrefReminderTriggers, []string{formatTrigger(
+			p.IntWithFallback(config.PrefReminderValue, config.DefaultReminderValue),
+			p.StringWithFallback(config.PrefReminderUnit, config.UnitDays),
+			p.StringWithFallback(config.PrefReminderDir, config.DirBefore),
+		)})
+	}
+	for _, key := range legacy {
+		p.RemoveValue(key)
+	}
+}
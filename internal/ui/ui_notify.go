@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+)
+
+// clickSender shows a notification and calls onClick when it is clicked. It reports false where
+// notifications cannot be clicked, so that the caller falls back to fyne.App.SendNotification.
+type clickSender func(n *fyne.Notification, onClick func()) bool
+
+// notify shows a desktop notification outside of the quiet hours; clicking it calls onClick, if set.
+func (app *GoBirthdayApp) notify(title, msg string, onClick func()) {
+	app.deliver(func() {
+		app.sendNotification(fyne.NewNotification(title, msg), onClick)
+	})
+}
+
+// sendNotification shows n at once, calling onClick when it is clicked where the desktop allows it.
+// The daemon has no window to open on a click.
+func (app *GoBirthdayApp) sendNotification(n *fyne.Notification, onClick func()) {
+	if onClick != nil && !app.Daemon && app.sendClickable != nil && app.sendClickable(n, onClick) {
+		return
+	}
+	app.App.SendNotification(n)
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !freebsd && !openbsd && !netbsd
+
+package ui
+
+// newClickSender returns nil: clicking a notification only brings the application forward on
+// this system, which Fyne does not report.
+func newClickSender() clickSender {
+	return nil
+}
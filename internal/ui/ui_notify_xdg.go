@@ -0,0 +1,84 @@
+//go:build linux || freebsd || openbsd || netbsd
+
+package ui
+
+import (
+	"log/slog"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"github.com/godbus/dbus/v5"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// xdgNotifier sends notifications with a default action to the freedesktop notification service,
+// which Fyne does not, and runs the action of the notification clicked.
+type xdgNotifier struct {
+	mu      sync.Mutex
+	conn    *dbus.Conn
+	actions map[uint32]func() // By notification ID, until clicked or closed
+}
+
+// newClickSender returns the sender of clickable notifications of the desktop.
+func newClickSender() clickSender {
+	x := &xdgNotifier{actions: make(map[uint32]func())}
+	return x.send
+}
+
+func (x *xdgNotifier) send(n *fyne.Notification, onClick func()) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.conn == nil {
+		conn, err := dbus.SessionBus() // Shared with Fyne, never closed
+		if err != nil {
+			return false
+		}
+		if err := conn.AddMatchSignal(
+			dbus.WithMatchObjectPath(config.DBusNotifyPath),
+			dbus.WithMatchInterface(config.DBusNotifyIface),
+		); err != nil {
+			return false
+		}
+		signals := make(chan *dbus.Signal, config.DBusSignalBuffer)
+		conn.Signal(signals)
+		go x.listen(signals)
+		x.conn = conn
+	}
+
+	var id uint32
+	err := x.conn.Object(config.DBusNotifyIface, config.DBusNotifyPath).Call(config.DBusNotifyMethod, 0,
+		config.AppName, uint32(0), "", n.Title, n.Content,
+		[]string{config.DBusDefaultAction, ""}, map[string]dbus.Variant{}, int32(-1)).Store(&id)
+	if err != nil {
+		slog.Debug(config.ErrNotifyClickable,
+			config.LogKeyComponent, config.CompUI,
+			config.LogKeyError, err)
+		return false
+	}
+	x.actions[id] = onClick
+	return true
+}
+
+// listen runs the action of the notifications clicked, and forgets those closed.
+func (x *xdgNotifier) listen(signals <-chan *dbus.Signal) {
+	for sig := range signals {
+		if sig.Name != config.DBusActionInvoked && sig.Name != config.DBusNotifyClosed || len(sig.Body) == 0 {
+			continue
+		}
+		id, ok := sig.Body[0].(uint32)
+		if !ok {
+			continue
+		}
+
+		x.mu.Lock()
+		action := x.actions[id]
+		delete(x.actions, id)
+		x.mu.Unlock()
+
+		if sig.Name == config.DBusActionInvoked && action != nil {
+			fyne.Do(action)
+		}
+	}
+}
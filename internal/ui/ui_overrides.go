@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+)
+
+// overrides returns the locally stored birthday corrections (vCard UID, or contact UID -> vCard date).
+func (app *GoBirthdayApp) overrides() map[string]string {
+	result := make(map[string]string)
+	for _, entry := range app.Preferences.StringList(config.PrefOverrides) {
+		if uid, date := splitOverride(entry); uid != "" && date != "" {
+			result[uid] = date
+		}
+	}
+	return result
+}
+
+// splitOverride splits a "uid=date" entry. A vCard UID may contain the separator, a date never does.
+func splitOverride(entry string) (uid, date string) {
+	i := strings.LastIndex(entry, config.OverrideSeparator)
+	if i < 0 {
+		return "", ""
+	}
+	return entry[:i], entry[i+len(config.OverrideSeparator):]
+}
+
+// setOverride stores the corrected date of a contact under its override key, dropping any
+// correction saved under its UID by earlier versions. An empty date removes the correction.
+func (app *GoBirthdayApp) setOverride(c engine.BirthdayEntry, date string) {
+	key := c.OverrideKey
+	if key == "" {
+		key = c.UID // Contact listed by an earlier version
+	}
+	var entries []string
+	for _, entry := range app.Preferences.StringList(config.PrefOverrides) {
+		if uid, _ := splitOverride(entry); uid != key && uid != c.UID {
+			entries = append(entries, entry)
+		}
+	}
+	if date != "" {
+		entries = append(entries, key+config.OverrideSeparator+date)
+	}
+	app.Preferences.SetStringList(config.PrefOverrides, entries)
+}
+
+// formatOverride converts a date into the vCard form understood by the engine.
+// Unknown years use the truncated "--MM-DD" form.
+func formatOverride(date time.Time, yearKnown bool) string {
+	if !yearKnown {
+		return date.Format(config.DateFormatNoYearD)
+	}
+	return date.Format(config.DateFormatFullDash)
+}
+
+// showOverrideDialog lets the user correct the birthday of one contact.
+// Clearing the date restores the value from the address book.
+func (app *GoBirthdayApp) showOverrideDialog(c engine.BirthdayEntry, parent fyne.Window, onSaved func()) {
+	dateEntry := widget.NewEntry()
+	dateEntry.PlaceHolder = config.DateFormatFullDash
+	dateEntry.SetText(c.DateOfBirth.Format(config.DateFormatFullDash))
+	dateEntry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if _, err := time.Parse(config.DateFormatFullDash, s); err != nil {
+			return errors.New(app.GetMsg(config.TKeyErrOverride))
+		}
+		return nil
+	}
+
+	checkUnknown := widget.NewCheck(app.GetMsg(config.TKeyLblYearUnknown), nil)
+	checkUnknown.Checked = !c.YearKnown
+
+	itemDate := widget.NewFormItem(app.GetMsg(config.TKeyLblOverrideDate), dateEntry)
+	itemDate.HintText = app.GetMsg(config.TKeyHelpOverride)
+	items := []*widget.FormItem{itemDate, widget.NewFormItem("", checkUnknown)}
+
+	title := app.GetMsg(config.TKeyWinOverride) + config.TitleSeparator + c.Name
+	d := dialog.NewForm(title, app.GetMsg(config.TKeyBtnSave), app.GetMsg(config.TKeyBtnCancel), items, func(ok bool) {
+		if !ok {
+			return
+		}
+		value := ""
+		if date, err := time.Parse(config.DateFormatFullDash, dateEntry.Text); err == nil {
+			value = formatOverride(date, !checkUnknown.Checked)
+		}
+		app.setOverride(c, value)
+		if onSaved != nil {
+			onSaved()
+		}
+	}, parent)
+	d.Show()
+}
+
+// parseOffsets reads a comma-separated list of offset anniversaries, skipping invalid ones.
+func parseOffsets(value string) []engine.Offset {
+	var offsets []engine.Offset
+	for _, v := range splitList(value) {
+		if o, err := engine.ParseOffset(v); err == nil {
+			offsets = append(offsets, o)
+		}
+	}
+	return offsets
+}
+
+// validateOffsets is the validator of the entries of offset anniversaries.
+func (app *GoBirthdayApp) validateOffsets(s string) error {
+	for _, v := range splitList(s) {
+		if _, err := engine.ParseOffset(v); err != nil {
+			return errors.New(app.GetMsg(config.TKeyErrOffsets))
+		}
+	}
+	return nil
+}
+
+// contactOffsets returns the offset anniversaries set on single contacts (contact UID -> offsets).
+func (app *GoBirthdayApp) contactOffsets() map[string][]engine.Offset {
+	result := make(map[string][]engine.Offset)
+	for _, entry := range app.Preferences.StringList(config.PrefContactOffsets) {
+		if uid, value, ok := strings.Cut(entry, config.OverrideSeparator); ok && uid != "" {
+			if offsets := parseOffsets(value); len(offsets) > 0 {
+				result[uid] = offsets
+			}
+		}
+	}
+	return result
+}
+
+// setContactOffsets stores the offset anniversaries of a contact. An empty value removes them.
+func (app *GoBirthdayApp) setContactOffsets(uid string, offsets []engine.Offset) {
+	var entries []string
+	for _, entry := range app.Preferences.StringList(config.PrefContactOffsets) {
+		if !strings.HasPrefix(entry, uid+config.OverrideSeparator) {
+			entries = append(entries, entry)
+		}
+	}
+	if len(offsets) > 0 {
+		values := make([]string, len(offsets))
+		for i, o := range offsets {
+			values[i] = o.String()
+		}
+		entries = append(entries, uid+config.OverrideSeparator+strings.Join(values, config.ListSeparator))
+	}
+	app.Preferences.SetStringList(config.PrefContactOffsets, entries)
+}
+
+// showOffsetsDialog lets the user add offset anniversaries (e.g., 100 days old) to one contact,
+// on top of the ones set for every contact.
+func (app *GoBirthdayApp) showOffsetsDialog(c engine.BirthdayEntry, parent fyne.Window, onSaved func()) {
+	var current []string
+	for _, o := range app.contactOffsets()[c.UID] {
+		current = append(current, o.String())
+	}
+	entry := widget.NewEntry()
+	entry.PlaceHolder = config.OffsetsPlaceholder
+	entry.SetText(strings.Join(current, config.ListSeparator+" "))
+	entry.Validator = app.validateOffsets
+
+	item := widget.NewFormItem(app.GetMsg(config.TKeyLblOffsets), entry)
+	item.HintText = app.GetMsg(config.TKeyHelpContactOffsets)
+
+	title := app.GetMsg(config.TKeyWinOffsets) + config.TitleSeparator + c.Name
+	d := dialog.NewForm(title, app.GetMsg(config.TKeyBtnSave), app.GetMsg(config.TKeyBtnCancel), []*widget.FormItem{item}, func(ok bool) {
+		if !ok {
+			return
+		}
+		app.setContactOffsets(c.UID, parseOffsets(entry.Text))
+		if onSaved != nil {
+			onSaved()
+		}
+	}, parent)
+	d.Show()
+}
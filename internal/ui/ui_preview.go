@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+	"github.com/tartampluch/go-birthday/internal/prefs"
+)
+
+// draftApp returns an application reading the settings of the window over the saved ones,
+// which it leaves untouched, to try them before they are saved.
+func (app *GoBirthdayApp) draftApp(sw *settingsWidgets) *GoBirthdayApp {
+	draft := &GoBirthdayApp{
+		App:                app.App,
+		Preferences:        prefs.NewDraft(app.Preferences),
+		I18nBundle:         app.I18nBundle,
+		Localizer:          app.Localizer, // Reads the labels of the window
+		Ctx:                app.Ctx,
+		Server:             app.Server,
+		Fetcher:            app.Fetcher,
+		Clock:              app.Clock,
+		SupportedLanguages: app.SupportedLanguages,
+	}
+	draft.storeSettings(sw)
+	draft.UpdateLocalizer()
+	return draft
+}
+
+// previewCalendar generates the calendar of cfg with the summaries of the application, and
+// returns it with its number of events and contacts.
+func (app *GoBirthdayApp) previewCalendar(ctx context.Context, cfg engine.SyncConfig) (ics string, events, contacts int, err error) {
+	var buf bytes.Buffer
+	entries, _, err := app.generator().RunSyncTo(ctx, cfg, &buf)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	ics = buf.String()
+	return ics, strings.Count(ics, config.ICalEventBegin), len(entries), nil
+}
+
+// firstLines returns the first n lines of a calendar, unfolded as they are written.
+func firstLines(ics string, n int) string {
+	lines := strings.SplitN(ics, config.ICalLineBreak, n+1)
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// showPreviewCalendar generates the calendar with the settings of the window, before they
+// are saved, and shows its first lines, to check the summaries and reminders.
+func (app *GoBirthdayApp) showPreviewCalendar(w fyne.Window, sw *settingsWidgets) {
+	title := app.GetMsg(config.TKeyWinPreviewCalendar)
+	progress := dialog.NewCustomWithoutButtons(title, widget.NewProgressBarInfinite(), w)
+	progress.Show()
+
+	// The settings are read here, on the UI goroutine.
+	draft := app.draftApp(sw)
+	cfg := app.sourceConfig(draft.loadSyncConfig(), sw)
+	go func() {
+		ctx, cancel := context.WithTimeout(app.Ctx, config.DoctorTimeout)
+		defer cancel()
+		ics, events, contacts, err := draft.previewCalendar(ctx, cfg)
+		fyne.Do(func() {
+			progress.Hide()
+			if err != nil {
+				slog.Warn(config.MsgSyncFailed, config.LogKeyError, err, config.LogKeyComponent, config.CompUISet)
+				dialog.ShowError(err, w)
+				return
+			}
+			app.showCalendarLines(w, title, ics, events, contacts)
+		})
+	}()
+}
+
+// showCalendarLines shows the counts and the first lines of a generated calendar.
+func (app *GoBirthdayApp) showCalendarLines(w fyne.Window, title, ics string, events, contacts int) {
+	summary := widget.NewLabel(fmt.Sprintf(app.GetMsg(config.TKeyMsgPreviewCalendar), events, contacts, config.PreviewMaxLines))
+	summary.Wrapping = fyne.TextWrapWord
+	lines := firstLines(ics, config.PreviewMaxLines)
+	code := widget.NewLabel(lines)
+	code.TextStyle = fyne.TextStyle{Monospace: true}
+	code.Selectable = true
+
+	btnCopy := widget.NewButtonWithIcon(app.GetMsg(config.TKeyBtnCopy), theme.ContentCopyIcon(), func() {
+		app.App.Clipboard().SetContent(lines)
+	})
+
+	content := container.NewBorder(summary, btnCopy, nil, nil, container.NewScroll(code))
+	d := dialog.NewCustom(title, app.GetMsg(config.TKeyBtnClose), content, w)
+	d.Resize(fyne.NewSize(config.SettingsWindowWidth, config.PreviewWinHeight))
+	d.Show()
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/publish"
+	"github.com/tartampluch/go-birthday/internal/secrets"
+)
+
+// publishTargets assembles the publishing targets from UI preferences and Keyring.
+// The file target comes first, being the cheapest. The CalDAV and WebDAV targets are kept
+// between synchronizations, for the events already published and the ETag of the last upload.
+func (app *GoBirthdayApp) publishTargets() []publish.Target {
+	var targets []publish.Target
+
+	if path := app.Preferences.String(config.PrefOutputFile); path != "" {
+		targets = append(targets, &publish.File{Path: path})
+	}
+
+	if app.Preferences.Bool(config.PrefCalDAVEnabled) {
+		url := app.Preferences.String(config.PrefCalDAVURL)
+		creds := publish.Credentials{User: app.Preferences.String(config.PrefCalDAVUser)}
+		creds.Pass, _ = secrets.Get(config.KeyringCalDAVPass)
+		domain := app.Preferences.String(config.PrefUIDDomain)
+
+		app.syncMut.Lock()
+		if app.caldav == nil || app.caldav.URL != url || app.caldav.Credentials != creds || app.caldav.UIDDomain != domain {
+			app.caldav = &publish.CalDAV{URL: url, Credentials: creds, UIDDomain: domain}
+		}
+		targets = append(targets, app.caldav)
+		app.syncMut.Unlock()
+	}
+
+	if app.Preferences.Bool(config.PrefWebDAVEnabled) {
+		url := app.Preferences.String(config.PrefWebDAVURL)
+		creds := publish.Credentials{User: app.Preferences.String(config.PrefWebDAVUser)}
+		creds.Pass, _ = secrets.Get(config.KeyringWebDAVPass)
+		creds.Token, _ = secrets.Get(config.KeyringWebDAVToken)
+
+		app.syncMut.Lock()
+		if app.webdav == nil || app.webdav.URL != url || app.webdav.Credentials != creds {
+			app.webdav = &publish.WebDAV{URL: url, Credentials: creds}
+		}
+		targets = append(targets, app.webdav)
+		app.syncMut.Unlock()
+	}
+
+	return targets
+}
+
+// publishCalendar sends the calendar served by the local server to every publishing target.
+// Failures are logged, and returned together.
+func (app *GoBirthdayApp) publishCalendar() error {
+	var errs []error
+	for _, target := range app.publishTargets() {
+		pr, pw := io.Pipe()
+		go func() {
+			_, err := app.Server.CopyTo(pw)
+			_ = pw.CloseWithError(err)
+		}()
+		err := target.Publish(app.Ctx, pr)
+		_ = pr.Close() // Unblocks the copy if the target stopped reading early
+		if err != nil {
+			slog.Error(config.ErrPublish,
+				config.LogKeyError, err,
+				config.LogKeyComponent, config.CompUI)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+	"github.com/tartampluch/go-birthday/internal/notify"
+	"github.com/tartampluch/go-birthday/internal/secrets"
+)
+
+// loadPushConfig assembles the push backend configuration from UI preferences and Keyring.
+func (app *GoBirthdayApp) loadPushConfig() notify.Config {
+	cfg := notify.Config{
+		Backend: app.Preferences.StringWithFallback(config.PrefPushBackend, config.PushBackendNone),
+		Server:  app.Preferences.String(config.PrefPushServer),
+		Topic:   app.Preferences.String(config.PrefPushTopic),
+	}
+
+	if cfg.Backend != config.PushBackendNone {
+		if t, err := secrets.Get(config.KeyringPushToken); err == nil {
+			cfg.Token = t
+		} else {
+			slog.Debug(config.MsgPassFail,
+				config.LogKeyBackend, cfg.Backend,
+				config.LogKeyError, err,
+				config.LogKeyComponent, config.CompUI)
+		}
+	}
+
+	return cfg
+}
+
+// sendBirthdayPush forwards today's birthdays to the configured push backend.
+// It pushes at most once per day so that periodic syncs do not spam the user's phone,
+// and not before the end of the quiet hours.
+func (app *GoBirthdayApp) sendBirthdayPush(contacts []engine.BirthdayEntry) {
+	today, names, _ := app.todaysBirthdays(contacts)
+	if len(names) == 0 || app.Preferences.String(config.PrefPushLastDate) == today {
+		return
+	}
+
+	// Held during the quiet hours; dropped if the day is over by then, the next synchronization pushing the new one.
+	app.deliver(func() {
+		if app.now().Format(config.DateFormatFullDash) == today && app.Preferences.String(config.PrefPushLastDate) != today {
+			app.pushBirthdays(today, names)
+		}
+	})
+}
+
+// todaysBirthdays returns today's date (YYYY-MM-DD), and the names, with the age they turn,
+// and the UIDs of the contacts born on that day.
+func (app *GoBirthdayApp) todaysBirthdays(contacts []engine.BirthdayEntry) (string, []string, []string) {
+	today := app.now().Format(config.DateFormatFullDash)
+	var names, uids []string
+	for _, c := range contacts {
+		if !c.Hidden && !c.Deceased && c.NextOccurrence.Format(config.DateFormatFullDash) == today {
+			name := c.Name
+			if age := app.ageTurning(c); age != "" {
+				name += fmt.Sprintf(config.FormatUpcomingAge, age)
+			}
+			names = append(names, name)
+			uids = append(uids, c.UID)
+		}
+	}
+	return today, names, uids
+}
+
+// pushBirthdays sends the names of today's birthdays to the push backend, and records the day.
+func (app *GoBirthdayApp) pushBirthdays(today string, names []string) {
+	notifier, err := notify.New(app.loadPushConfig(), nil)
+	if err != nil {
+		slog.Warn(config.ErrPushFailed, config.LogKeyError, err, config.LogKeyComponent, config.CompUI)
+		return
+	}
+	if notifier == nil {
+		return // Push notifications are disabled.
+	}
+
+	title, body := app.buildPushMessage(names)
+
+	ctx, cancel := context.WithTimeout(app.Ctx, config.HTTPTimeout)
+	defer cancel()
+
+	if err := notifier.Send(ctx, title, body); err != nil {
+		slog.Error(config.ErrPushFailed, config.LogKeyError, err, config.LogKeyComponent, config.CompUI)
+		return
+	}
+
+	app.Preferences.SetString(config.PrefPushLastDate, today)
+}
+
+// buildPushMessage returns the localized title and body announcing today's birthdays.
+func (app *GoBirthdayApp) buildPushMessage(names []string) (string, string) {
+	joined := strings.Join(names, config.PushNameSeparator)
+	count := len(names)
+
+	var title, body string
+	if app.Localizer != nil {
+		if msg, err := app.Localizer.Localize(&i18n.LocalizeConfig{
+			MessageID:    config.TKeyPushTitle,
+			TemplateData: map[string]interface{}{"Count": count},
+			PluralCount:  count,
+		}); err == nil {
+			title = msg
+		}
+		if msg, err := app.Localizer.Localize(&i18n.LocalizeConfig{
+			MessageID:    config.TKeyPushBody,
+			TemplateData: map[string]interface{}{"Names": joined, "Date": app.formatDate(app.now())},
+		}); err == nil {
+			body = msg
+		}
+	}
+
+	if title == "" {
+		title = fmt.Sprintf(config.FallbackPushTitle, count)
+	}
+	if body == "" {
+		body = joined
+	}
+	return title, body
+}
@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// quietHours is a daily do-not-disturb window, possibly spanning midnight (e.g., 22:00–08:00).
+type quietHours struct {
+	start, end time.Time // Only the hour and minute are used
+}
+
+// parseQuietHours reads a window from two "15:04" times. It is disabled (false) when either is empty
+// or invalid, or when both are equal.
+func parseQuietHours(start, end string) (quietHours, bool) {
+	s, err1 := time.Parse(config.QuietTimeLayout, strings.TrimSpace(start))
+	e, err2 := time.Parse(config.QuietTimeLayout, strings.TrimSpace(end))
+	if err1 != nil || err2 != nil || s.Equal(e) {
+		return quietHours{}, false
+	}
+	return quietHours{start: s, end: e}, true
+}
+
+// until reports whether now falls within the quiet hours, and when they end.
+func (q quietHours) until(now time.Time) (time.Time, bool) {
+	minutes := func(t time.Time) int { return t.Hour()*60 + t.Minute() }
+	m, start, end := minutes(now), minutes(q.start), minutes(q.end)
+	endToday := time.Date(now.Year(), now.Month(), now.Day(), q.end.Hour(), q.end.Minute(), 0, 0, now.Location())
+
+	switch {
+	case start < end && m >= start && m < end:
+		return endToday, true
+	case start > end && m < end:
+		return endToday, true
+	case start > end && m >= start:
+		return endToday.AddDate(0, 0, 1), true
+	}
+	return time.Time{}, false
+}
+
+// validateQuietTime accepts an empty time (no quiet hours) or a time such as "22:00".
+func (app *GoBirthdayApp) validateQuietTime(s string) error {
+	if s = strings.TrimSpace(s); s == "" {
+		return nil
+	}
+	if _, err := time.Parse(config.QuietTimeLayout, s); err != nil {
+		return errors.New(app.GetMsg(config.TKeyErrQuietHours))
+	}
+	return nil
+}
+
+// deliver runs send at once, or holds it until the end of the quiet hours set in the preferences.
+func (app *GoBirthdayApp) deliver(send func()) {
+	q, ok := parseQuietHours(app.Preferences.String(config.PrefQuietStart), app.Preferences.String(config.PrefQuietEnd))
+	now := app.now()
+	end, quiet := q.until(now)
+	if !ok || !quiet {
+		send()
+		return
+	}
+
+	app.quietMu.Lock()
+	defer app.quietMu.Unlock()
+	app.quietQueue = append(app.quietQueue, send)
+	if app.quietTimer == nil {
+		app.quietTimer = time.AfterFunc(end.Sub(now), app.flushQuiet)
+	}
+	slog.Info(config.MsgNotifQueued,
+		config.LogKeyComponent, config.CompUI,
+		config.LogKeyUntil, end.Format(time.RFC3339))
+}
+
+// flushQuiet delivers the notifications held during the quiet hours, once they are over.
+// Those whose hours were meanwhile extended in the settings are held again.
+func (app *GoBirthdayApp) flushQuiet() {
+	app.quietMu.Lock()
+	queue := app.quietQueue
+	app.quietQueue, app.quietTimer = nil, nil
+	app.quietMu.Unlock()
+
+	if app.Ctx.Err() != nil {
+		return
+	}
+	for _, send := range queue {
+		app.deliver(send)
+	}
+}
@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// reminderRow holds the widgets of one reminder line in the settings window.
+type reminderRow struct {
+	value *NumericalEntry
+	unit  *widget.Select
+	dir   *widget.Select
+	box   *fyne.Container
+}
+
+// formatTrigger converts a reminder (value, unit, direction) into an ISO8601 duration.
+func formatTrigger(val int, unit, dir string) string {
+	sign := config.ISOPeriodPrefix
+	if dir == config.DirBefore {
+		sign = config.ISONegativePrefix
+	}
+
+	switch unit {
+	case config.UnitHours:
+		return fmt.Sprintf("%s%d%s", sign, val, config.ISOHour)
+	case config.UnitMinutes:
+		return fmt.Sprintf("%s%d%s", sign, val, config.ISOMinute)
+	default:
+		return fmt.Sprintf("%s%d%s", sign, val, config.ISODay)
+	}
+}
+
+// parseTrigger is the inverse of formatTrigger.
+// It only understands the simple durations produced by formatTrigger.
+func parseTrigger(trigger string) (val int, unit, dir string, ok bool) {
+	dir = config.DirAfter
+	rest := trigger
+	if strings.HasPrefix(rest, config.ISONegativePrefix) {
+		dir = config.DirBefore
+		rest = strings.TrimPrefix(rest, config.ISONegativePrefix)
+	} else if strings.HasPrefix(rest, config.ISOPeriodPrefix) {
+		rest = strings.TrimPrefix(rest, config.ISOPeriodPrefix)
+	} else {
+		return 0, "", "", false
+	}
+
+	switch {
+	case strings.HasSuffix(rest, config.ISOHour):
+		unit = config.UnitHours
+	case strings.HasSuffix(rest, config.ISOMinute):
+		unit = config.UnitMinutes
+	case strings.HasSuffix(rest, config.ISODay):
+		unit = config.UnitDays
+	default:
+		return 0, "", "", false
+	}
+
+	val, err := strconv.Atoi(rest[:len(rest)-1])
+	if err != nil || val < 0 {
+		return 0, "", "", false
+	}
+	return val, unit, dir, true
+}
+
+// reminderTriggers returns the configured reminder triggers.
+// Settings saved before multiple reminders existed only hold a single
+// value/unit/direction triple, which is converted on the fly until
+// migrateReminderTriggers has run.
+func (app *GoBirthdayApp) reminderTriggers() []string {
+	if triggers := app.Preferences.StringList(config.PrefReminderTriggers); len(triggers) > 0 {
+		return triggers
+	}
+
+	val := app.Preferences.IntWithFallback(config.PrefReminderValue, config.DefaultReminderValue)
+	unit := app.Preferences.StringWithFallback(config.PrefReminderUnit, config.UnitDays)
+	dir := app.Preferences.StringWithFallback(config.PrefReminderDir, config.DirBefore)
+	return []string{formatTrigger(val, unit, dir)}
+}
+
+// newReminderRow builds the widgets for one reminder, initialized from an ISO8601 trigger.
+func (app *GoBirthdayApp) newReminderRow(trigger string, onRemove func(*reminderRow)) *reminderRow {
+	val, unit, dir, ok := parseTrigger(trigger)
+	if !ok {
+		val, unit, dir = config.DefaultReminderValue, config.UnitDays, config.DirBefore
+	}
+
+	r := &reminderRow{}
+
+	// Reminder Value: Numerical only. An empty value skips the row in save logic.
+	r.value = NewNumericalEntry()
+	r.value.SetText(strconv.Itoa(val))
+
+	r.unit = widget.NewSelect([]string{
+		app.GetMsg(config.TKeyUnitDays),
+		app.GetMsg(config.TKeyUnitHours),
+		app.GetMsg(config.TKeyUnitMinutes),
+	}, nil)
+	switch unit {
+	case config.UnitHours:
+		r.unit.SetSelected(app.GetMsg(config.TKeyUnitHours))
+	case config.UnitMinutes:
+		r.unit.SetSelected(app.GetMsg(config.TKeyUnitMinutes))
+	default:
+		r.unit.SetSelected(app.GetMsg(config.TKeyUnitDays))
+	}
+
+	r.dir = widget.NewSelect([]string{
+		app.GetMsg(config.TKeyDirBefore),
+		app.GetMsg(config.TKeyDirAfter),
+	}, nil)
+	if dir == config.DirAfter {
+		r.dir.SetSelected(app.GetMsg(config.TKeyDirAfter))
+	} else {
+		r.dir.SetSelected(app.GetMsg(config.TKeyDirBefore))
+	}
+
+	btnRemove := widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), func() {
+		if onRemove != nil {
+			onRemove(r)
+		}
+	})
+
+	// Controls: Value | Unit | Direction | "Start of day" | Remove
+	controls := container.NewHBox(r.unit, r.dir, widget.NewLabel(app.GetMsg(config.TKeyLblStartDay)), btnRemove)
+	r.box = container.NewBorder(nil, nil, nil, controls, r.value)
+	return r
+}
+
+// rowTrigger converts the row back into an ISO8601 duration.
+// It returns false if the value field is empty or invalid.
+func (app *GoBirthdayApp) rowTrigger(r *reminderRow) (string, bool) {
+	v, err := strconv.Atoi(r.value.Text)
+	if r.value.Text == "" || err != nil {
+		return "", false
+	}
+
+	// Map Unit UI String -> Config Code (d, h, m)
+	unit := config.UnitDays
+	switch r.unit.Selected {
+	case app.GetMsg(config.TKeyUnitHours):
+		unit = config.UnitHours
+	case app.GetMsg(config.TKeyUnitMinutes):
+		unit = config.UnitMinutes
+	}
+
+	// Map Direction UI String -> Config Code (before, after)
+	dir := config.DirBefore
+	if r.dir.Selected == app.GetMsg(config.TKeyDirAfter) {
+		dir = config.DirAfter
+	}
+
+	return formatTrigger(v, unit, dir), true
+}
@@ -4,7 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
+	"os"
+	"runtime"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -12,24 +18,106 @@ import (
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/tartampluch/go-birthday/internal/autostart"
 	"github.com/tartampluch/go-birthday/internal/config"
-	"github.com/zalando/go-keyring"
+	"github.com/tartampluch/go-birthday/internal/engine"
+	"github.com/tartampluch/go-birthday/internal/secrets"
+	"github.com/tartampluch/go-birthday/internal/server"
 )
 
 // settingsWidgets holds references to UI elements to simplify data retrieval during save.
 type settingsWidgets struct {
-	langSelect    *widget.Select
-	modeSelect    *widget.Select
-	urlEntry      *widget.Entry
-	userEntry     *widget.Entry
-	passEntry     *widget.Entry
-	pathEntry     *widget.Entry
-	entryInterval *NumericalEntry
-	entryPort     *NumericalEntry
-	checkReminder *widget.Check
-	entryRemValue *NumericalEntry
-	selectRemUnit *widget.Select
-	selectRemDir  *widget.Select
+	langSelect     *widget.Select
+	themeSelect    *widget.Select
+	dateFormat     *widget.SelectEntry
+	timeZone       *widget.SelectEntry
+	checkStartup   *widget.Check
+	checkDebug     *widget.Check
+	checkAccessLog *widget.Check
+	checkToday     *widget.Check
+	shortcutEntry  *widget.Entry
+	modeSelect     *widget.Select
+	urlEntry       *widget.Entry
+	addressBooks   []string      // Address books merged by the web source, empty for the URL alone
+	booksLabel     *widget.Label // Notes the merged address books
+	userEntry      *widget.Entry
+	passEntry      *widget.Entry
+	authSelect     *widget.Select
+	tokenEntry     *widget.Entry
+	tokenURLEntry  *widget.Entry
+	clientIDEntry  *widget.Entry
+	secretEntry    *widget.Entry
+	refreshEntry   *widget.Entry
+	proxyEntry     *widget.Entry
+	proxyUser      *widget.Entry
+	proxyPass      *widget.Entry
+	caFileEntry    *widget.Entry
+	certEntry      *widget.Entry
+	keyEntry       *widget.Entry
+	checkInsecure  *widget.Check
+	pathEntry      *widget.Entry
+	tbirdEntry     *widget.Entry // Thunderbird profile, empty to detect it
+	execEntry      *widget.Entry // Program of the program source
+	filterGroup    *widget.CheckGroup
+	entryInterval  *NumericalEntry   // Interval of intervalMode
+	intervalMode   string            // Source mode whose interval is shown
+	intervals      map[string]string // Intervals edited, by source mode
+	entryPort      *NumericalEntry
+	photoSelect    *widget.Select
+	catEntry       *widget.Entry
+	checkGroups    *widget.Check
+	checkDetails   *widget.Check
+	checkNickname  *widget.Check
+	checkDates     *widget.Check
+	checkMemorials *widget.Check
+	checkZodiacCol *widget.Check
+	checkZodiacSum *widget.Check
+	colorSelect    *widget.Select
+	classSelect    *widget.Select
+	nameSelect     *widget.Select
+	eventTimeEntry *widget.Entry
+	eventDurEntry  *NumericalEntry
+	calNameEntry   *widget.Entry
+	calDescEntry   *widget.Entry
+	calColorSelect *widget.Select
+	todoDaysEntry  *NumericalEntry
+	uidDomainEntry *widget.Entry
+	summaryEntry   *widget.Entry
+	ageSelect      *widget.Select
+	nameDaySelect  *widget.Select
+	offsetsEntry   *widget.Entry
+	checkMilestone *widget.Check
+	msAgesEntry    *widget.Entry
+	msEveryEntry   *NumericalEntry
+	msPrefixEntry  *widget.Entry
+	msRemEntry     *NumericalEntry
+	checkReminder  *widget.Check
+	reminderRows   []*reminderRow
+	pushBackend    *widget.Select
+	pushServer     *widget.Entry
+	pushTopic      *widget.Entry
+	pushToken      *widget.Entry
+	quietStart     *widget.Entry
+	quietEnd       *widget.Entry
+	checkServe     *widget.Check
+	corsEntry      *widget.Entry
+	networksEntry  *widget.Entry
+	outputFile     *widget.Entry
+	hookSuccess    *widget.Entry
+	hookFailure    *widget.Entry
+	checkCalDAV    *widget.Check
+	caldavURL      *widget.Entry
+	caldavUser     *widget.Entry
+	caldavPass     *widget.Entry
+	checkWebDAV    *widget.Check
+	webdavURL      *widget.Entry
+	webdavUser     *widget.Entry
+	webdavPass     *widget.Entry
+	webdavToken    *widget.Entry
+	maxDownload    *NumericalEntry
+	spacing        *NumericalEntry // Seconds between two requests to the source
+	maxContacts    *NumericalEntry
+	maxCard        *NumericalEntry
 }
 
 // ShowSettingsWindow displays the configuration dialog allowing users to manage settings.
@@ -64,14 +152,26 @@ func (app *GoBirthdayApp) ShowSettingsWindow() {
 
 	// --- 2. Source Section ---
 	// Map translated strings to values is handled later.
-	sw.modeSelect = widget.NewSelect([]string{
+	modes := []string{
 		app.GetMsg(config.TKeyModeCardDAV),
+		app.GetMsg(config.TKeyModeJMAP),
 		app.GetMsg(config.TKeyModeLocal),
-	}, nil)
+		app.GetMsg(config.TKeyModeThunderbird),
+	}
+	switch runtime.GOOS {
+	case config.GOOSMac:
+		modes = append(modes, app.GetMsg(config.TKeyModeSystem))
+	case config.GOOSWindows:
+		modes = append(modes, app.GetMsg(config.TKeyModeOutlook))
+	}
+	// Sources compiled in by third parties are listed under their mode.
+	modes = append(append(modes, app.GetMsg(config.TKeyModeExec)), engine.Sources()...)
+	sw.modeSelect = widget.NewSelect(modes, nil)
 
 	sw.urlEntry = widget.NewEntry()
 	sw.urlEntry.SetText(app.Preferences.String(config.PrefCardDAVURL))
 	sw.urlEntry.PlaceHolder = config.PlaceholderURL
+	sw.addressBooks = app.Preferences.StringList(config.PrefAddressBooks)
 
 	sw.userEntry = widget.NewEntry()
 	sw.userEntry.SetText(app.Preferences.String(config.PrefUsername))
@@ -79,21 +179,75 @@ func (app *GoBirthdayApp) ShowSettingsWindow() {
 	sw.passEntry = widget.NewPasswordEntry()
 	// Attempt to pre-fill password from secure storage
 	if user := sw.userEntry.Text; user != "" {
-		if pwd, err := keyring.Get(config.KeyringService, user); err == nil {
+		if pwd, err := secrets.Get(user); err == nil {
 			sw.passEntry.SetText(pwd)
 		}
 	}
 
+	sw.tokenEntry = widget.NewPasswordEntry()
+	if tok, err := secrets.Get(config.KeyringBearerToken); err == nil {
+		sw.tokenEntry.SetText(tok)
+	}
+	sw.tokenURLEntry = widget.NewEntry()
+	sw.tokenURLEntry.SetText(app.Preferences.String(config.PrefOAuth2TokenURL))
+	sw.tokenURLEntry.PlaceHolder = config.PlaceholderTokenURL
+	sw.clientIDEntry = widget.NewEntry()
+	sw.clientIDEntry.SetText(app.Preferences.String(config.PrefOAuth2ClientID))
+	sw.secretEntry = widget.NewPasswordEntry()
+	if s, err := secrets.Get(config.KeyringOAuth2Secret); err == nil {
+		sw.secretEntry.SetText(s)
+	}
+	sw.refreshEntry = widget.NewPasswordEntry()
+	if tok, err := secrets.Get(config.KeyringOAuth2Token); err == nil {
+		sw.refreshEntry.SetText(tok)
+	}
+
+	sw.proxyEntry = widget.NewEntry()
+	sw.proxyEntry.SetText(app.Preferences.String(config.PrefProxyURL))
+	sw.proxyEntry.PlaceHolder = config.PlaceholderProxy
+
+	sw.proxyUser = widget.NewEntry()
+	sw.proxyUser.SetText(app.Preferences.String(config.PrefProxyUser))
+
+	sw.proxyPass = widget.NewPasswordEntry()
+	if pwd, err := secrets.Get(config.KeyringProxyPass); err == nil {
+		sw.proxyPass.SetText(pwd)
+	}
+
+	sw.caFileEntry = widget.NewEntry()
+	sw.caFileEntry.SetText(app.Preferences.String(config.PrefCAFile))
+
+	sw.certEntry = widget.NewEntry()
+	sw.certEntry.SetText(app.Preferences.String(config.PrefClientCert))
+	sw.keyEntry = widget.NewEntry()
+	sw.keyEntry.SetText(app.Preferences.String(config.PrefClientKey))
+
+	sw.checkInsecure = widget.NewCheck(app.GetMsg(config.TKeyLblTLSInsecure), nil)
+	sw.checkInsecure.Checked = app.Preferences.Bool(config.PrefTLSInsecure)
+
 	sw.pathEntry = widget.NewEntry()
 	sw.pathEntry.SetText(app.Preferences.String(config.PrefLocalPath))
 
-	sourceCard := app.buildSourceCard(w, sw, onLayoutChange)
+	sw.tbirdEntry = widget.NewEntry()
+	sw.tbirdEntry.SetText(app.Preferences.String(config.PrefThunderbirdPath))
+	sw.tbirdEntry.SetPlaceHolder(app.GetMsg(config.TKeyPlaceholderDetected))
 
-	// --- 3. General Section (Interval & Port) ---
+	sw.execEntry = widget.NewEntry()
+	sw.execEntry.SetText(app.Preferences.String(config.PrefExecCommand))
+	if home, err := os.UserHomeDir(); err == nil {
+		if profile, err := engine.FindThunderbirdProfile(home); err == nil {
+			sw.tbirdEntry.SetPlaceHolder(profile)
+		}
+	}
 
 	// Interval: Numerical only. No specific validator needed as "0" or "empty" are handled in save logic.
+	// Each source mode has its own, shown when it is selected (see switchInterval).
 	sw.entryInterval = NewNumericalEntry()
-	sw.entryInterval.SetText(strconv.Itoa(app.Preferences.IntWithFallback(config.PrefInterval, config.DefaultRefreshMin)))
+	sw.intervals = map[string]string{}
+
+	sourceCard := app.buildSourceCard(w, sw, onLayoutChange)
+
+	// --- 3. General Section (Interval & Port) ---
 
 	// Port: Numerical only, but requires strict Validation (Range 1-65535).
 	sw.entryPort = NewNumericalEntry()
@@ -120,51 +274,122 @@ func (app *GoBirthdayApp) ShowSettingsWindow() {
 	itemInterval := widget.NewFormItem(app.GetMsg(config.TKeyLblRefresh), widInterval)
 	itemInterval.HintText = app.GetMsg(config.TKeyHelpInterval)
 
-	itemPort := widget.NewFormItem(app.GetMsg(config.TKeyLblPort), sw.entryPort)
+	btnHA := widget.NewButton(config.TitleHomeAssistant+"…", func() { app.showHomeAssistantExample(w, sw.entryPort.Text) })
+	itemPort := widget.NewFormItem(app.GetMsg(config.TKeyLblPort), container.NewBorder(nil, nil, nil, btnHA, sw.entryPort))
 	itemPort.HintText = app.GetMsg(config.TKeyHelpPort)
 
-	generalForm := widget.NewForm(itemLang, itemInterval, itemPort)
-	generalCard := widget.NewCard(app.GetMsg(config.TKeyLblGeneral), "", generalForm)
+	photoLabels, photoCodes := app.photoModeOptions()
+	sw.photoSelect = widget.NewSelect(photoLabels, nil)
+	sw.photoSelect.SetSelected(photoLabels[0])
+	currentPhoto := app.Preferences.StringWithFallback(config.PrefPhotoMode, config.PhotoModeNone)
+	for label, code := range photoCodes {
+		if code == currentPhoto {
+			sw.photoSelect.SetSelected(label)
+		}
+	}
+	itemPhotos := widget.NewFormItem(app.GetMsg(config.TKeyLblPhotos), sw.photoSelect)
+	itemPhotos.HintText = app.GetMsg(config.TKeyHelpPhotos)
+
+	themeLabels, themeCodes := app.themeOptions()
+	sw.themeSelect = widget.NewSelect(themeLabels, nil)
+	sw.themeSelect.SetSelected(themeLabels[0])
+	currentTheme := app.Preferences.StringWithFallback(config.PrefTheme, config.ThemeSystem)
+	for label, code := range themeCodes {
+		if code == currentTheme {
+			sw.themeSelect.SetSelected(label)
+		}
+	}
+	itemTheme := widget.NewFormItem(app.GetMsg(config.TKeyLblTheme), sw.themeSelect)
+	itemTheme.HintText = app.GetMsg(config.TKeyHelpTheme)
+
+	// Date format with a preview on today's date; empty keeps the language default.
+	sw.dateFormat = widget.NewSelectEntry(config.DateFormatPresets)
+	sw.dateFormat.SetText(app.Preferences.String(config.PrefDateFormat))
+	datePreview := widget.NewLabel("")
+	datePreview.TextStyle = fyne.TextStyle{Italic: true}
+	updateDatePreview := func(text string) {
+		layout := app.GetMsg(config.TKeyFormatDate)
+		if layout == config.TKeyFormatDate {
+			layout = config.DateFormatDisplay
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			layout = goDateLayout(text)
+		}
+		datePreview.SetText(app.GetMsg(config.TKeyLblPreview) + " " + app.now().Format(layout))
+	}
+	updateDatePreview(sw.dateFormat.Text)
+	sw.dateFormat.OnChanged = updateDatePreview
+	itemDate := widget.NewFormItem(app.GetMsg(config.TKeyLblDateFormat), container.NewVBox(sw.dateFormat, datePreview))
+	itemDate.HintText = app.GetMsg(config.TKeyHelpDateFormat)
+
+	// Time zone of "today" and of the events; empty follows the system.
+	sw.timeZone = widget.NewSelectEntry(config.TimeZonePresets)
+	sw.timeZone.SetText(app.Preferences.String(config.PrefTimeZone))
+	sw.timeZone.Validator = app.validateTimeZone
+	itemTimeZone := widget.NewFormItem(app.GetMsg(config.TKeyLblTimeZone), sw.timeZone)
+	itemTimeZone.HintText = app.GetMsg(config.TKeyHelpTimeZone)
+
+	sw.shortcutEntry = widget.NewEntry()
+	sw.shortcutEntry.SetText(app.Preferences.StringWithFallback(config.PrefContactsShortcut, config.DefaultContactsShortcut))
+	sw.shortcutEntry.Validator = func(s string) error {
+		if _, ok := parseShortcut(s); !ok {
+			return errors.New(app.GetMsg(config.TKeyErrShortcut))
+		}
+		return nil
+	}
+	itemShortcut := widget.NewFormItem(app.GetMsg(config.TKeyLblShortcut), sw.shortcutEntry)
+	itemShortcut.HintText = app.GetMsg(config.TKeyHelpShortcut)
+
+	generalForm := widget.NewForm(itemLang, itemTheme, itemDate, itemTimeZone, itemInterval, itemPort, itemPhotos, itemShortcut)
+	// The OS entry itself is the source of truth, not a preference.
+	sw.checkStartup = widget.NewCheck(app.GetMsg(config.TKeyLblAutostart), nil)
+	sw.checkStartup.Checked = autostart.IsEnabled()
+
+	sw.checkDebug = widget.NewCheck(app.GetMsg(config.TKeyLblDebugLog), nil)
+	sw.checkDebug.Checked = app.Preferences.Bool(config.PrefDebugLogging)
+
+	sw.checkAccessLog = widget.NewCheck(app.GetMsg(config.TKeyLblAccessLog), nil)
+	sw.checkAccessLog.Checked = app.Preferences.Bool(config.PrefAccessLog)
+
+	sw.checkToday = widget.NewCheck(app.GetMsg(config.TKeyLblTodayWindow), nil)
+	sw.checkToday.Checked = app.Preferences.Bool(config.PrefTodayWindow)
+
+	generalCard := widget.NewCard(app.GetMsg(config.TKeyLblGeneral), "", container.NewVBox(generalForm, sw.checkStartup, sw.checkToday, sw.checkDebug, sw.checkAccessLog))
 
 	// --- 4. Reminder Section ---
 	sw.checkReminder = widget.NewCheck(app.GetMsg(config.TKeyLblEnableRem), nil)
 	sw.checkReminder.Checked = app.Preferences.Bool(config.PrefReminderEnabled)
 
-	// Reminder Value: Numerical only. No validator needed; empty disables the feature in save logic.
-	sw.entryRemValue = NewNumericalEntry()
-	// Fallback uses constant DefaultReminderValue instead of literal "1"
-	sw.entryRemValue.SetText(strconv.Itoa(app.Preferences.IntWithFallback(config.PrefReminderValue, config.DefaultReminderValue)))
-
-	sw.selectRemUnit = widget.NewSelect([]string{
-		app.GetMsg(config.TKeyUnitDays),
-		app.GetMsg(config.TKeyUnitHours),
-		app.GetMsg(config.TKeyUnitMinutes),
-	}, nil)
-
-	// Determine initial selection for Unit based on preferences
-	currentUnit := app.Preferences.StringWithFallback(config.PrefReminderUnit, config.UnitDays)
-	switch currentUnit {
-	case config.UnitHours:
-		sw.selectRemUnit.SetSelected(app.GetMsg(config.TKeyUnitHours))
-	case config.UnitMinutes:
-		sw.selectRemUnit.SetSelected(app.GetMsg(config.TKeyUnitMinutes))
-	default:
-		sw.selectRemUnit.SetSelected(app.GetMsg(config.TKeyUnitDays))
-	}
+	notifCard := app.buildNotifCard(sw, onLayoutChange)
 
-	sw.selectRemDir = widget.NewSelect([]string{
-		app.GetMsg(config.TKeyDirBefore),
-		app.GetMsg(config.TKeyDirAfter),
-	}, nil)
-	// Determine initial selection for Direction
-	currentDir := app.Preferences.StringWithFallback(config.PrefReminderDir, config.DirBefore)
-	if currentDir == config.DirAfter {
-		sw.selectRemDir.SetSelected(app.GetMsg(config.TKeyDirAfter))
-	} else {
-		sw.selectRemDir.SetSelected(app.GetMsg(config.TKeyDirBefore))
+	// --- 5. Event Properties Section ---
+	eventsCard := app.buildEventsCard(sw)
+
+	calendarCard := app.buildCalendarCard(sw)
+
+	// --- 6. Milestone Section ---
+	milestoneCard := app.buildMilestoneCard(sw, onLayoutChange)
+
+	// --- 7. Push Notification Section ---
+	sw.pushServer = widget.NewEntry()
+	sw.pushServer.SetText(app.Preferences.String(config.PrefPushServer))
+	sw.pushServer.PlaceHolder = config.PlaceholderURL
+
+	sw.pushTopic = widget.NewEntry()
+	sw.pushTopic.SetText(app.Preferences.String(config.PrefPushTopic))
+
+	sw.pushToken = widget.NewPasswordEntry()
+	if tok, err := secrets.Get(config.KeyringPushToken); err == nil {
+		sw.pushToken.SetText(tok)
 	}
 
-	notifCard := app.buildNotifCard(sw, onLayoutChange)
+	pushCard := app.buildPushCard(sw, onLayoutChange)
+
+	// --- 8. Publishing Section ---
+	publishCard := app.buildPublishCard(w, sw, onLayoutChange)
+
+	// --- 9. Limits Section ---
+	limitsCard := app.buildLimitsCard(sw)
 
 	// --- Actions ---
 	saveAction := func() {
@@ -179,6 +404,11 @@ func (app *GoBirthdayApp) ShowSettingsWindow() {
 	btnSave = widget.NewButtonWithIcon(app.GetMsg(config.TKeyBtnSave), theme.DocumentSaveIcon(), saveAction)
 	btnSave.Importance = widget.HighImportance
 	btnCancel := widget.NewButtonWithIcon(app.GetMsg(config.TKeyBtnCancel), theme.CancelIcon(), func() { w.Close() })
+	btnPreview := widget.NewButtonWithIcon(app.GetMsg(config.TKeyBtnPreviewCalendar), theme.VisibilityIcon(), func() { app.showPreviewCalendar(w, sw) })
+	btnExport := widget.NewButtonWithIcon(app.GetMsg(config.TKeyBtnExportSettings), theme.UploadIcon(), func() { app.showExportSettings(w) })
+	btnExport.Importance = widget.LowImportance
+	btnImport := widget.NewButtonWithIcon(app.GetMsg(config.TKeyBtnImportSettings), theme.DownloadIcon(), func() { app.showImportSettings(w) })
+	btnImport.Importance = widget.LowImportance
 
 	// --- Footer ---
 	footerText := fmt.Sprintf(app.GetMsg(config.TKeyLblFooter), config.Version)
@@ -191,8 +421,15 @@ func (app *GoBirthdayApp) ShowSettingsWindow() {
 		sourceCard,
 		generalCard,
 		notifCard,
+		eventsCard,
+		calendarCard,
+		milestoneCard,
+		pushCard,
+		publishCard,
+		limitsCard,
 		// Using constant for columns
-		container.NewGridWithColumns(config.LayoutColumnsDouble, btnCancel, btnSave),
+		container.NewGridWithColumns(config.LayoutColumnsDouble, btnExport, btnImport),
+		container.NewGridWithColumns(config.LayoutColumnsTriple, btnCancel, btnPreview, btnSave),
 		footerLabel,
 	))
 
@@ -203,15 +440,45 @@ func (app *GoBirthdayApp) ShowSettingsWindow() {
 		w.Resize(fyne.NewSize(config.SettingsWindowWidth, minSize.Height))
 	}
 
+	// Dropping a vCard file selects it as the local source (applied on save).
+	w.SetOnDropped(func(_ fyne.Position, uris []fyne.URI) {
+		if path, ok := droppedVCard(uris); ok {
+			sw.modeSelect.SetSelected(app.GetMsg(config.TKeyModeLocal))
+			sw.pathEntry.SetText(path)
+		}
+	})
+
+	app.addShortcuts(w)
+	closeOnEscape(w)
+
 	w.SetContent(paddedContent)
 	w.SetFixedSize(true)
-	w.SetOnClosed(func() { app.Window = nil })
+	w.SetOnClosed(func() {
+		if app.Window == w {
+			app.Window = nil
+		}
+	})
 
 	// Initial layout calculation
 	refreshLayout()
 	w.Show()
 }
 
+// switchInterval shows the refresh interval of the source selected as label, keeping the one
+// edited for the previous source until the settings are saved.
+func (app *GoBirthdayApp) switchInterval(sw *settingsWidgets, label string) {
+	mode := app.modeCodes()[label]
+	if sw.intervalMode != "" {
+		sw.intervals[sw.intervalMode] = sw.entryInterval.Text
+	}
+	sw.intervalMode = mode
+	text, ok := sw.intervals[mode]
+	if !ok {
+		text = strconv.Itoa(app.refreshInterval(mode))
+	}
+	sw.entryInterval.SetText(text)
+}
+
 // buildSourceCard constructs the source selection UI.
 func (app *GoBirthdayApp) buildSourceCard(w fyne.Window, sw *settingsWidgets, onLayoutChange func()) *widget.Card {
 	browseBtn := widget.NewButton(app.GetMsg(config.TKeyBtnBrowse), func() {
@@ -225,114 +492,911 @@ func (app *GoBirthdayApp) buildSourceCard(w fyne.Window, sw *settingsWidgets, on
 		d.Show()
 	})
 
-	// Web Form
-	itemURL := widget.NewFormItem(app.GetMsg(config.TKeyLblURL), sw.urlEntry)
+	// A directory holds one or more vCard files (e.g., one per contact).
+	folderBtn := widget.NewButton(app.GetMsg(config.TKeyBtnFolder), func() {
+		dialog.NewFolderOpen(func(u fyne.ListableURI, err error) {
+			if err == nil && u != nil {
+				sw.pathEntry.SetText(u.Path())
+			}
+		}, w).Show()
+	})
+
+	// Web Form, with the Nextcloud setup and the address books next to the URL they fill in
+	nextcloudBtn := widget.NewButton(app.GetMsg(config.TKeyBtnNextcloud), func() { app.showNextcloudWizard(w, sw) })
+	booksBtn := widget.NewButton(app.GetMsg(config.TKeyBtnAddressBooks), func() { app.showAddressBooks(w, sw) })
+	davBtns := container.NewHBox(booksBtn, nextcloudBtn)
+	itemURL := widget.NewFormItem(app.GetMsg(config.TKeyLblURL), container.NewBorder(nil, nil, nil, davBtns, sw.urlEntry))
 	itemURL.HintText = app.GetMsg(config.TKeyHelpURL)
+	urlForm := widget.NewForm(itemURL)
+
+	itemProxy := widget.NewFormItem(app.GetMsg(config.TKeyLblProxy), sw.proxyEntry)
+	itemProxy.HintText = app.GetMsg(config.TKeyHelpProxy)
+	itemProxyUser := widget.NewFormItem(app.GetMsg(config.TKeyLblProxyUser), sw.proxyUser)
+	itemProxyPass := widget.NewFormItem(app.GetMsg(config.TKeyLblProxyPass), sw.proxyPass)
 
-	itemUser := widget.NewFormItem(app.GetMsg(config.TKeyLblUser), sw.userEntry)
-	itemPass := widget.NewFormItem(app.GetMsg(config.TKeyLblPass), sw.passEntry)
+	itemCAFile := widget.NewFormItem(app.GetMsg(config.TKeyLblCAFile), app.fileEntryRow(w, sw.caFileEntry, config.ExtPEM, config.ExtCRT))
+	itemCAFile.HintText = app.GetMsg(config.TKeyHelpCAFile)
+	itemCert := widget.NewFormItem(app.GetMsg(config.TKeyLblClientCert), app.fileEntryRow(w, sw.certEntry, config.ExtPEM, config.ExtCRT))
+	itemKey := widget.NewFormItem(app.GetMsg(config.TKeyLblClientKey), app.fileEntryRow(w, sw.keyEntry, config.ExtPEM, config.ExtKey))
+	itemKey.HintText = app.GetMsg(config.TKeyHelpClientCert)
 
-	webForm := widget.NewForm(itemURL, itemUser, itemPass)
+	// Editing the URL goes back to a single address book.
+	sw.booksLabel = widget.NewLabel("")
+	sw.booksLabel.Wrapping = fyne.TextWrapWord
+	app.setAddressBooks(sw, sw.addressBooks)
+	sw.urlEntry.OnChanged = func(string) { app.setAddressBooks(sw, nil) }
+
+	webForm := container.NewVBox(
+		urlForm,
+		sw.booksLabel,
+		app.buildAuthBox(sw, onLayoutChange),
+		widget.NewForm(itemProxy, itemProxyUser, itemProxyPass, itemCAFile, itemCert, itemKey),
+		sw.checkInsecure,
+	)
 
 	// Local Form
-	localForm := container.NewBorder(nil, nil, nil, browseBtn, sw.pathEntry)
+	localForm := container.NewBorder(nil, nil, nil, container.NewHBox(browseBtn, folderBtn), sw.pathEntry)
+
+	// Thunderbird Form: a profile directory, one of its address books or an LDIF export
+	tbirdFolderBtn := widget.NewButton(app.GetMsg(config.TKeyBtnFolder), func() {
+		dialog.NewFolderOpen(func(u fyne.ListableURI, err error) {
+			if err == nil && u != nil {
+				sw.tbirdEntry.SetText(u.Path())
+			}
+		}, w).Show()
+	})
+	itemProfile := widget.NewFormItem(app.GetMsg(config.TKeyLblThunderbird),
+		container.NewBorder(nil, nil, nil, tbirdFolderBtn, app.fileEntryRow(w, sw.tbirdEntry, config.ExtSQLite, config.ExtLDIF)))
+	itemProfile.HintText = app.GetMsg(config.TKeyHelpThunderbird)
+	tbirdForm := widget.NewForm(itemProfile)
+
+	// Program Form: any executable printing the contacts as JSON
+	itemExec := widget.NewFormItem(app.GetMsg(config.TKeyLblExec), app.fileEntryRow(w, sw.execEntry))
+	itemExec.HintText = app.GetMsg(config.TKeyHelpExec)
+	execForm := widget.NewForm(itemExec)
 
 	// Dynamic visibility based on mode
 	updateVis := func(mode string) {
-		if mode == app.GetMsg(config.TKeyModeLocal) {
+		switch mode {
+		case app.GetMsg(config.TKeyModeLocal):
 			webForm.Hide()
 			localForm.Show()
-		} else {
+			tbirdForm.Hide()
+			execForm.Hide()
+		case app.GetMsg(config.TKeyModeThunderbird):
+			webForm.Hide()
+			localForm.Hide()
+			tbirdForm.Show()
+			execForm.Hide()
+		case app.GetMsg(config.TKeyModeExec):
+			webForm.Hide()
+			localForm.Hide()
+			tbirdForm.Hide()
+			execForm.Show()
+		case app.GetMsg(config.TKeyModeCardDAV), app.GetMsg(config.TKeyModeJMAP):
 			webForm.Show()
 			localForm.Hide()
+			tbirdForm.Hide()
+			execForm.Hide()
+		default:
+			// System, Outlook and registered sources need no settings.
+			webForm.Hide()
+			localForm.Hide()
+			tbirdForm.Hide()
+			execForm.Hide()
+		}
+		// JMAP takes a session URL; the Nextcloud setup only finds CardDAV address books.
+		if mode == app.GetMsg(config.TKeyModeJMAP) {
+			itemURL.HintText = app.GetMsg(config.TKeyHelpJMAPURL)
+			davBtns.Hide()
+		} else {
+			itemURL.HintText = app.GetMsg(config.TKeyHelpURL)
+			davBtns.Show()
 		}
+		urlForm.Refresh()
 		if onLayoutChange != nil {
 			onLayoutChange()
 		}
 	}
-	sw.modeSelect.OnChanged = updateVis
+	sw.modeSelect.OnChanged = func(mode string) {
+		app.switchInterval(sw, mode)
+		updateVis(mode)
+	}
 
 	// Set initial state
-	currentMode := app.Preferences.String(config.PrefSourceMode)
-	if currentMode == config.SourceModeLocal {
+	switch app.Preferences.String(config.PrefSourceMode) {
+	case config.SourceModeLocal:
 		sw.modeSelect.SetSelected(app.GetMsg(config.TKeyModeLocal))
-	} else {
+	case config.SourceModeJMAP:
+		sw.modeSelect.SetSelected(app.GetMsg(config.TKeyModeJMAP))
+	case config.SourceModeSystem:
+		sw.modeSelect.SetSelected(app.GetMsg(config.TKeyModeSystem))
+	case config.SourceModeThunderbird:
+		sw.modeSelect.SetSelected(app.GetMsg(config.TKeyModeThunderbird))
+	case config.SourceModeOutlook:
+		sw.modeSelect.SetSelected(app.GetMsg(config.TKeyModeOutlook))
+	case config.SourceModeExec:
+		sw.modeSelect.SetSelected(app.GetMsg(config.TKeyModeExec))
+	default:
 		sw.modeSelect.SetSelected(app.GetMsg(config.TKeyModeCardDAV))
+		if mode := app.Preferences.String(config.PrefSourceMode); slices.Contains(engine.Sources(), mode) {
+			sw.modeSelect.SetSelected(mode)
+		}
 	}
 
 	// Apply initial visibility
-	if sw.modeSelect.Selected == app.GetMsg(config.TKeyModeLocal) {
-		webForm.Hide()
-		localForm.Show()
-	} else {
-		webForm.Show()
-		localForm.Hide()
+	updateVis(sw.modeSelect.Selected)
+
+	// Tries the source as entered, before it is saved.
+	testBtn := widget.NewButton(app.GetMsg(config.TKeyBtnTestConn), func() { app.showTestConnection(w, sw) })
+	modeRow := container.NewBorder(nil, nil, nil, testBtn, sw.modeSelect)
+
+	return widget.NewCard(app.GetMsg(config.TKeyLblSource), "", container.NewVBox(modeRow, webForm, localForm, tbirdForm, execForm, app.buildFilterBox(sw)))
+}
+
+// authTypeOptions returns the translated labels of the authentication types of the web source
+// and the mapping back to the config constants.
+func (app *GoBirthdayApp) authTypeOptions() ([]string, map[string]string) {
+	labels := []string{app.GetMsg(config.TKeyAuthBasic), app.GetMsg(config.TKeyAuthBearer), config.AuthLabelOAuth2}
+	codes := map[string]string{
+		labels[0]:              config.AuthBasic,
+		labels[1]:              config.AuthBearer,
+		config.AuthLabelOAuth2: config.AuthOAuth2,
+	}
+	return labels, codes
+}
+
+// buildAuthBox constructs the authentication selector of the web source and the fields of each type.
+func (app *GoBirthdayApp) buildAuthBox(sw *settingsWidgets, onLayoutChange func()) fyne.CanvasObject {
+	labels, codes := app.authTypeOptions()
+	sw.authSelect = widget.NewSelect(labels, nil)
+
+	basicForm := widget.NewForm(
+		widget.NewFormItem(app.GetMsg(config.TKeyLblUser), sw.userEntry),
+		widget.NewFormItem(app.GetMsg(config.TKeyLblPass), sw.passEntry),
+	)
+	bearerForm := widget.NewForm(widget.NewFormItem(app.GetMsg(config.TKeyLblToken), sw.tokenEntry))
+
+	itemTokenURL := widget.NewFormItem(app.GetMsg(config.TKeyLblTokenURL), sw.tokenURLEntry)
+	itemTokenURL.HintText = app.GetMsg(config.TKeyHelpTokenURL)
+	itemRefresh := widget.NewFormItem(app.GetMsg(config.TKeyLblRefreshToken), sw.refreshEntry)
+	itemRefresh.HintText = app.GetMsg(config.TKeyHelpRefreshToken)
+	oauthForm := widget.NewForm(
+		itemTokenURL,
+		widget.NewFormItem(app.GetMsg(config.TKeyLblClientID), sw.clientIDEntry),
+		widget.NewFormItem(app.GetMsg(config.TKeyLblClientSecret), sw.secretEntry),
+		itemRefresh,
+	)
+
+	updateVis := func(label string) {
+		basicForm.Hide()
+		bearerForm.Hide()
+		oauthForm.Hide()
+		switch codes[label] {
+		case config.AuthBearer:
+			bearerForm.Show()
+		case config.AuthOAuth2:
+			oauthForm.Show()
+		default:
+			basicForm.Show()
+		}
+		if onLayoutChange != nil {
+			onLayoutChange()
+		}
+	}
+
+	// Set initial state before wiring the callback to avoid a premature resize.
+	current := app.Preferences.StringWithFallback(config.PrefAuthType, config.AuthBasic)
+	sw.authSelect.SetSelected(labels[0])
+	for label, code := range codes {
+		if code == current {
+			sw.authSelect.SetSelected(label)
+		}
+	}
+	updateVis(sw.authSelect.Selected)
+	sw.authSelect.OnChanged = updateVis
+
+	itemAuth := widget.NewFormItem(app.GetMsg(config.TKeyLblAuth), sw.authSelect)
+	return container.NewVBox(widget.NewForm(itemAuth), basicForm, bearerForm, oauthForm)
+}
+
+// fileEntryRow places a Browse button next to entry, filling it with the path of the chosen file.
+func (app *GoBirthdayApp) fileEntryRow(w fyne.Window, entry *widget.Entry, exts ...string) fyne.CanvasObject {
+	btn := widget.NewButton(app.GetMsg(config.TKeyBtnBrowse), func() {
+		d := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err == nil && r != nil {
+				entry.SetText(r.URI().Path())
+				_ = r.Close()
+			}
+		}, w)
+		if len(exts) > 0 {
+			d.SetFilter(storage.NewExtensionFileFilter(exts))
+		}
+		d.Show()
+	})
+	return container.NewBorder(nil, nil, nil, btn, entry)
+}
+
+// buildFilterBox constructs the include-only group filter.
+// Options come from the last sync; selected groups that have since disappeared are kept visible.
+func (app *GoBirthdayApp) buildFilterBox(sw *settingsWidgets) fyne.CanvasObject {
+	selected := app.Preferences.StringList(config.PrefIncludeCategories)
+	options := append([]string{}, app.Preferences.StringList(config.PrefKnownCategories)...)
+	for _, s := range selected {
+		if !slices.Contains(options, s) {
+			options = append(options, s)
+		}
 	}
 
-	return widget.NewCard(app.GetMsg(config.TKeyLblSource), "", container.NewVBox(sw.modeSelect, webForm, localForm))
+	sw.filterGroup = widget.NewCheckGroup(options, nil)
+	sw.filterGroup.Horizontal = true
+	sw.filterGroup.SetSelected(selected)
+
+	title := widget.NewLabel(app.GetMsg(config.TKeyLblFilter))
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	hint := widget.NewLabel(app.GetMsg(config.TKeyHelpFilter))
+	if len(options) == 0 {
+		hint.SetText(app.GetMsg(config.TKeyFilterEmpty))
+	}
+	hint.TextStyle = fyne.TextStyle{Italic: true}
+	hint.Wrapping = fyne.TextWrapWord
+
+	return container.NewVBox(title, sw.filterGroup, hint)
 }
 
 // buildNotifCard constructs the notification/reminder UI.
+// Each reminder is one row; rows can be added and removed freely.
 func (app *GoBirthdayApp) buildNotifCard(sw *settingsWidgets, onLayoutChange func()) *widget.Card {
-	lblStart := widget.NewLabel(app.GetMsg(config.TKeyLblStartDay))
+	rowsBox := container.NewVBox()
+
+	layoutChanged := func() {
+		if onLayoutChange != nil {
+			onLayoutChange()
+		}
+	}
+
+	removeRow := func(r *reminderRow) {
+		for i, row := range sw.reminderRows {
+			if row == r {
+				sw.reminderRows = append(sw.reminderRows[:i], sw.reminderRows[i+1:]...)
+				break
+			}
+		}
+		rowsBox.Remove(r.box)
+		layoutChanged()
+	}
+
+	addRow := func(trigger string) {
+		r := app.newReminderRow(trigger, removeRow)
+		sw.reminderRows = append(sw.reminderRows, r)
+		rowsBox.Add(r.box)
+	}
+
+	for _, t := range app.reminderTriggers() {
+		addRow(t)
+	}
 
-	// Controls: Value | Unit | Direction | "Start of day"
-	controls := container.NewHBox(sw.selectRemUnit, sw.selectRemDir, lblStart)
-	row := container.NewBorder(nil, nil, nil, controls, sw.entryRemValue)
+	btnAdd := widget.NewButtonWithIcon(app.GetMsg(config.TKeyBtnAddRem), theme.ContentAddIcon(), func() {
+		addRow(formatTrigger(config.DefaultReminderValue, config.UnitDays, config.DirBefore))
+		layoutChanged()
+	})
+
+	section := container.NewVBox(rowsBox, btnAdd)
 
 	sw.checkReminder.OnChanged = func(b bool) {
 		if b {
-			row.Show()
+			section.Show()
 		} else {
-			row.Hide()
+			section.Hide()
+		}
+		layoutChanged()
+	}
+
+	if sw.checkReminder.Checked {
+		section.Show()
+	} else {
+		section.Hide()
+	}
+
+	return widget.NewCard(app.GetMsg(config.TKeyLblNotif), "", container.NewVBox(sw.checkReminder, section))
+}
+
+// buildEventsCard constructs the UI for the CATEGORIES and COLOR event properties.
+func (app *GoBirthdayApp) buildEventsCard(sw *settingsWidgets) *widget.Card {
+	sw.catEntry = widget.NewEntry()
+	sw.catEntry.SetText(app.Preferences.StringWithFallback(config.PrefCategories, config.DefaultCategory))
+	sw.catEntry.PlaceHolder = config.DefaultCategory
+
+	sw.checkGroups = widget.NewCheck(app.GetMsg(config.TKeyLblGroups), nil)
+	sw.checkGroups.Checked = app.Preferences.Bool(config.PrefContactGroups)
+	sw.checkDetails = widget.NewCheck(app.GetMsg(config.TKeyLblDetails), nil)
+	sw.checkDetails.Checked = app.Preferences.Bool(config.PrefContactDetails)
+	sw.checkNickname = widget.NewCheck(app.GetMsg(config.TKeyLblPreferNick), nil)
+	sw.checkNickname.Checked = app.Preferences.Bool(config.PrefPreferNickname)
+
+	sw.checkDates = widget.NewCheck(app.GetMsg(config.TKeyLblCustomDates), nil)
+	sw.checkDates.Checked = app.Preferences.Bool(config.PrefCustomDates)
+	sw.checkMemorials = widget.NewCheck(app.GetMsg(config.TKeyLblMemorials), nil)
+	sw.checkMemorials.Checked = app.Preferences.Bool(config.PrefMemorials)
+	sw.checkZodiacCol = widget.NewCheck(app.GetMsg(config.TKeyLblZodiacCol), nil)
+	sw.checkZodiacCol.Checked = app.Preferences.Bool(config.PrefZodiacColumn)
+	sw.checkZodiacSum = widget.NewCheck(app.GetMsg(config.TKeyLblZodiacSum), nil)
+	sw.checkZodiacSum.Checked = app.Preferences.Bool(config.PrefZodiacSummary)
+
+	// The first entry (localized "None") omits the COLOR property.
+	sw.colorSelect = widget.NewSelect(append([]string{app.GetMsg(config.TKeyColorNone)}, config.EventColors...), nil)
+	sw.colorSelect.SetSelected(app.GetMsg(config.TKeyColorNone))
+	if c := app.Preferences.String(config.PrefEventColor); c != "" {
+		sw.colorSelect.SetSelected(c)
+	}
+
+	itemCat := widget.NewFormItem(app.GetMsg(config.TKeyLblCategories), sw.catEntry)
+	itemCat.HintText = app.GetMsg(config.TKeyHelpCategories)
+	itemColor := widget.NewFormItem(app.GetMsg(config.TKeyLblColor), sw.colorSelect)
+	itemColor.HintText = app.GetMsg(config.TKeyHelpColor)
+
+	classLabels, classCodes := app.eventClassOptions()
+	sw.classSelect = widget.NewSelect(classLabels, nil)
+	for label, code := range classCodes {
+		if code == app.Preferences.StringWithFallback(config.PrefEventClass, config.DefaultEventClass) {
+			sw.classSelect.SetSelected(label)
+		}
+	}
+	itemClass := widget.NewFormItem(app.GetMsg(config.TKeyLblClass), sw.classSelect)
+	itemClass.HintText = app.GetMsg(config.TKeyHelpClass)
+
+	// Empty for all-day events, the duration only matters for timed ones.
+	sw.eventTimeEntry = widget.NewEntry()
+	sw.eventTimeEntry.SetText(app.Preferences.String(config.PrefEventTime))
+	sw.eventTimeEntry.PlaceHolder = app.GetMsg(config.TKeyPhAllDay)
+	sw.eventTimeEntry.Validator = app.validateEventTime
+	sw.eventDurEntry = NewNumericalEntry()
+	sw.eventDurEntry.SetText(strconv.Itoa(app.Preferences.IntWithFallback(config.PrefEventDuration, config.DefaultEventMins)))
+	durRow := container.NewBorder(nil, nil, widget.NewLabel(app.GetMsg(config.TKeyLblEventDur)), nil, sw.eventDurEntry)
+	itemTime := widget.NewFormItem(app.GetMsg(config.TKeyLblEventTime), container.NewGridWithColumns(config.LayoutColumnsDouble, sw.eventTimeEntry, durRow))
+	itemTime.HintText = app.GetMsg(config.TKeyHelpEventTime)
+
+	// Summary template with live preview on a sample contact.
+	sw.summaryEntry = widget.NewEntry()
+	sw.summaryEntry.SetText(app.Preferences.String(config.PrefSummaryTemplate))
+	sw.summaryEntry.PlaceHolder = config.SummaryPlaceholder
+	preview := widget.NewLabel("")
+	preview.TextStyle = fyne.TextStyle{Italic: true}
+	updatePreview := func(text string) {
+		if _, err := parseSummaryTemplate(text); err != nil {
+			preview.SetText(app.GetMsg(config.TKeyErrTemplate))
+			return
+		}
+		sample := app.summaryFormatter(text)(config.PreviewName, config.PreviewAge, true)
+		preview.SetText(app.GetMsg(config.TKeyLblPreview) + " " + sample)
+	}
+	updatePreview(sw.summaryEntry.Text)
+	sw.summaryEntry.OnChanged = updatePreview
+
+	itemSummary := widget.NewFormItem(app.GetMsg(config.TKeyLblSummary), sw.summaryEntry)
+	itemSummary.HintText = app.GetMsg(config.TKeyHelpSummary)
+
+	labels, codes := app.ageDisplayOptions()
+	sw.ageSelect = widget.NewSelect(labels, nil)
+	for label, code := range codes {
+		if code == app.ageDisplay() {
+			sw.ageSelect.SetSelected(label)
+		}
+	}
+	itemAge := widget.NewFormItem(app.GetMsg(config.TKeyLblAgeDisplay), sw.ageSelect)
+	itemAge.HintText = app.GetMsg(config.TKeyHelpAgeDisplay)
+
+	nameLabels, nameCodes := app.nameFormatOptions()
+	sw.nameSelect = widget.NewSelect(nameLabels, nil)
+	for label, code := range nameCodes {
+		if code == app.Preferences.StringWithFallback(config.PrefNameFormat, config.NameFormatFN) {
+			sw.nameSelect.SetSelected(label)
+		}
+	}
+	itemName := widget.NewFormItem(app.GetMsg(config.TKeyLblNameFormat), sw.nameSelect)
+	itemName.HintText = app.GetMsg(config.TKeyHelpNameFormat)
+
+	// The first entry (localized "None") disables name days, the others are the bundled tables.
+	sw.nameDaySelect = widget.NewSelect(append([]string{app.GetMsg(config.TKeyNameDaysNone)}, engine.NameDayLocales()...), nil)
+	sw.nameDaySelect.SetSelected(app.GetMsg(config.TKeyNameDaysNone))
+	if l := app.Preferences.String(config.PrefNameDays); l != "" {
+		sw.nameDaySelect.SetSelected(l)
+	}
+	itemNameDays := widget.NewFormItem(app.GetMsg(config.TKeyLblNameDays), sw.nameDaySelect)
+	itemNameDays.HintText = app.GetMsg(config.TKeyHelpNameDays)
+
+	sw.offsetsEntry = widget.NewEntry()
+	sw.offsetsEntry.SetText(app.Preferences.String(config.PrefOffsets))
+	sw.offsetsEntry.PlaceHolder = config.OffsetsPlaceholder
+	sw.offsetsEntry.Validator = app.validateOffsets
+	itemOffsets := widget.NewFormItem(app.GetMsg(config.TKeyLblOffsets), sw.offsetsEntry)
+	itemOffsets.HintText = app.GetMsg(config.TKeyHelpOffsets)
+
+	form := widget.NewForm(itemSummary, itemAge, itemName, itemCat, itemColor, itemClass, itemTime, itemNameDays, itemOffsets)
+	return widget.NewCard(app.GetMsg(config.TKeyLblEvents), "", container.NewVBox(form, preview, sw.checkGroups, sw.checkDetails, sw.checkNickname, sw.checkDates, sw.checkMemorials, sw.checkZodiacCol, sw.checkZodiacSum))
+}
+
+// ageDisplayOptions returns the translated labels of the age display modes
+// and the mapping back to the config constants.
+func (app *GoBirthdayApp) ageDisplayOptions() ([]string, map[string]string) {
+	labels := []string{app.GetMsg(config.TKeyAgeTransition), app.GetMsg(config.TKeyAgeTurning), app.GetMsg(config.TKeyAgeNone)}
+	codes := map[string]string{
+		labels[0]: config.AgeDisplayTransition,
+		labels[1]: config.AgeDisplayTurning,
+		labels[2]: config.AgeDisplayNone,
+	}
+	return labels, codes
+}
+
+// buildCalendarCard constructs the UI for the calendar metadata, which tells apart
+// the calendars of several instances (e.g., family and work) in calendar clients.
+func (app *GoBirthdayApp) buildCalendarCard(sw *settingsWidgets) *widget.Card {
+	sw.calNameEntry = widget.NewEntry()
+	sw.calNameEntry.SetText(app.Preferences.String(config.PrefCalendarName))
+	sw.calNameEntry.PlaceHolder = config.ICalCalName
+
+	sw.calDescEntry = widget.NewEntry()
+	sw.calDescEntry.SetText(app.Preferences.String(config.PrefCalendarDesc))
+
+	// The first entry (localized "None") omits the COLOR property.
+	sw.calColorSelect = widget.NewSelect(append([]string{app.GetMsg(config.TKeyColorNone)}, config.EventColors...), nil)
+	sw.calColorSelect.SetSelected(app.GetMsg(config.TKeyColorNone))
+	if c := app.Preferences.String(config.PrefCalendarColor); c != "" {
+		sw.calColorSelect.SetSelected(c)
+	}
+
+	sw.uidDomainEntry = widget.NewEntry()
+	sw.uidDomainEntry.SetText(app.Preferences.String(config.PrefUIDDomain))
+	sw.uidDomainEntry.PlaceHolder = config.ICalDomain
+	sw.uidDomainEntry.Validator = app.validateUIDDomain
+
+	itemName := widget.NewFormItem(app.GetMsg(config.TKeyLblCalName), sw.calNameEntry)
+	itemDesc := widget.NewFormItem(app.GetMsg(config.TKeyLblCalDesc), sw.calDescEntry)
+	itemColor := widget.NewFormItem(app.GetMsg(config.TKeyLblCalColor), sw.calColorSelect)
+	itemColor.HintText = app.GetMsg(config.TKeyHelpCalendar)
+	itemDomain := widget.NewFormItem(app.GetMsg(config.TKeyLblUIDDomain), sw.uidDomainEntry)
+	itemDomain.HintText = app.GetMsg(config.TKeyHelpUIDDomain)
+
+	// Birthdays of the coming days are also served as to-dos; 0 disables them.
+	sw.todoDaysEntry = NewNumericalEntry()
+	sw.todoDaysEntry.SetText(strconv.Itoa(app.Preferences.IntWithFallback(config.PrefTodoDays, config.DefaultTodoDays)))
+	todoRow := container.NewBorder(nil, nil, nil, widget.NewLabel(app.GetMsg(config.TKeyUnitDays)), sw.todoDaysEntry)
+	itemTodos := widget.NewFormItem(app.GetMsg(config.TKeyLblTodoDays), todoRow)
+	itemTodos.HintText = app.GetMsg(config.TKeyHelpTodoDays)
+
+	return widget.NewCard(app.GetMsg(config.TKeyLblCalendar), "", widget.NewForm(itemName, itemDesc, itemColor, itemDomain, itemTodos))
+}
+
+// validateEventTime accepts an empty time (all-day events) or a time of day (e.g., "09:00").
+func (app *GoBirthdayApp) validateEventTime(s string) error {
+	if s = strings.TrimSpace(s); s != "" {
+		if _, err := time.Parse(config.EventTimeFormat, s); err != nil {
+			return errors.New(app.GetMsg(config.TKeyErrEventTime))
+		}
+	}
+	return nil
+}
+
+// validateTimeZone accepts an empty time zone (the system one) or an IANA name (e.g., "Europe/Paris").
+func (app *GoBirthdayApp) validateTimeZone(s string) error {
+	if s = strings.TrimSpace(s); s != "" {
+		if _, err := time.LoadLocation(s); err != nil {
+			return errors.New(app.GetMsg(config.TKeyErrTimeZone))
+		}
+	}
+	return nil
+}
+
+// validateUIDDomain accepts an empty domain (the default) or letters, digits, dots and hyphens.
+func (app *GoBirthdayApp) validateUIDDomain(s string) error {
+	if s = strings.TrimSpace(s); s != "" && !engine.ValidUIDDomain(s) {
+		return errors.New(app.GetMsg(config.TKeyErrUIDDomain))
+	}
+	return nil
+}
+
+// validateNetworks accepts an empty allowlist (any client) or comma-separated networks
+// in CIDR notation or addresses (e.g., "192.168.1.0/24, fd00::/8").
+func (app *GoBirthdayApp) validateNetworks(s string) error {
+	if _, err := server.ParseNetworks(splitList(s)); err != nil {
+		return errors.New(app.GetMsg(config.TKeyErrAllowedNets))
+	}
+	return nil
+}
+
+// validateCORSOrigin accepts an empty origin (disabled), CORSAnyOrigin, or the scheme, host
+// and optional port of a web app (e.g., "https://dash.example.org").
+func (app *GoBirthdayApp) validateCORSOrigin(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" || s == config.CORSAnyOrigin {
+		return nil
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != config.SchemeHTTP && u.Scheme != config.SchemeHTTPS) ||
+		u.Host == "" || u.User != nil || u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+		return errors.New(app.GetMsg(config.TKeyErrCORSOrigin))
+	}
+	return nil
+}
+
+// eventClassOptions returns the translated labels of the event access classes
+// and the mapping back to the config constants.
+func (app *GoBirthdayApp) eventClassOptions() ([]string, map[string]string) {
+	labels := []string{app.GetMsg(config.TKeyClassPublic), app.GetMsg(config.TKeyClassPrivate), app.GetMsg(config.TKeyClassConf)}
+	codes := map[string]string{
+		labels[0]: config.ICalClassPublic,
+		labels[1]: config.ICalClassPrivate,
+		labels[2]: config.ICalClassConf,
+	}
+	return labels, codes
+}
+
+// nameFormatOptions returns the translated labels of the display name formats
+// and the mapping back to the config constants.
+func (app *GoBirthdayApp) nameFormatOptions() ([]string, map[string]string) {
+	labels := []string{app.GetMsg(config.TKeyNameFormatFN), app.GetMsg(config.TKeyNameFormatGF), app.GetMsg(config.TKeyNameFormatFG)}
+	codes := map[string]string{
+		labels[0]: config.NameFormatFN,
+		labels[1]: config.NameFormatGivenFamily,
+		labels[2]: config.NameFormatFamilyGiven,
+	}
+	return labels, codes
+}
+
+// buildMilestoneCard constructs the milestone birthday UI (ages, prefix, extra reminder).
+func (app *GoBirthdayApp) buildMilestoneCard(sw *settingsWidgets, onLayoutChange func()) *widget.Card {
+	sw.checkMilestone = widget.NewCheck(app.GetMsg(config.TKeyLblEnableMilestone), nil)
+	sw.checkMilestone.Checked = app.Preferences.Bool(config.PrefMilestoneEnabled)
+
+	sw.msAgesEntry = widget.NewEntry()
+	sw.msAgesEntry.SetText(app.Preferences.StringWithFallback(config.PrefMilestoneAges, config.DefaultMilestoneAges))
+
+	sw.msEveryEntry = NewNumericalEntry()
+	sw.msEveryEntry.SetText(strconv.Itoa(app.Preferences.IntWithFallback(config.PrefMilestoneEvery, config.DefaultMilestoneEvery)))
+
+	sw.msPrefixEntry = widget.NewEntry()
+	sw.msPrefixEntry.SetText(app.Preferences.StringWithFallback(config.PrefMilestonePrefix, config.DefaultMilestonePrefix))
+
+	sw.msRemEntry = NewNumericalEntry()
+	sw.msRemEntry.SetText(strconv.Itoa(app.Preferences.IntWithFallback(config.PrefMilestoneReminder, config.DefaultMilestoneDays)))
+
+	itemAges := widget.NewFormItem(app.GetMsg(config.TKeyLblMilestoneAges), sw.msAgesEntry)
+	itemAges.HintText = app.GetMsg(config.TKeyHelpMilestoneAges)
+	itemEvery := widget.NewFormItem(app.GetMsg(config.TKeyLblMilestoneEvery), sw.msEveryEntry)
+	itemEvery.HintText = app.GetMsg(config.TKeyHelpMilestoneEvery)
+	itemPrefix := widget.NewFormItem(app.GetMsg(config.TKeyLblMilestonePrefix), sw.msPrefixEntry)
+	widRem := container.NewBorder(nil, nil, nil, widget.NewLabel(app.GetMsg(config.TKeyUnitDays)), sw.msRemEntry)
+	itemRem := widget.NewFormItem(app.GetMsg(config.TKeyLblMilestoneRem), widRem)
+	itemRem.HintText = app.GetMsg(config.TKeyHelpMilestoneRem)
+
+	form := widget.NewForm(itemAges, itemEvery, itemPrefix, itemRem)
+
+	sw.checkMilestone.OnChanged = func(b bool) {
+		if b {
+			form.Show()
+		} else {
+			form.Hide()
 		}
 		if onLayoutChange != nil {
 			onLayoutChange()
 		}
 	}
 
-	if sw.checkReminder.Checked {
-		row.Show()
-	} else {
-		row.Hide()
+	if !sw.checkMilestone.Checked {
+		form.Hide()
+	}
+
+	return widget.NewCard(app.GetMsg(config.TKeyLblMilestones), "", container.NewVBox(sw.checkMilestone, form))
+}
+
+// buildPublishCard constructs the UI choosing where the calendar goes: the local HTTP server,
+// a file, a CalDAV calendar and a WebDAV server.
+func (app *GoBirthdayApp) buildPublishCard(w fyne.Window, sw *settingsWidgets, onLayoutChange func()) *widget.Card {
+	sw.checkServe = widget.NewCheck(app.GetMsg(config.TKeyLblServeHTTP), nil)
+	sw.checkServe.Checked = app.Preferences.BoolWithFallback(config.PrefServeHTTP, true)
+
+	sw.outputFile = widget.NewEntry()
+	sw.outputFile.SetText(app.Preferences.String(config.PrefOutputFile))
+	btnFile := widget.NewButton(app.GetMsg(config.TKeyBtnBrowse), func() {
+		d := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+			if err == nil && wc != nil {
+				sw.outputFile.SetText(wc.URI().Path())
+				_ = wc.Close() // Written on the next synchronization
+			}
+		}, w)
+		d.SetFileName(config.ExportCalFileName)
+		d.SetFilter(storage.NewExtensionFileFilter([]string{config.ExtICS}))
+		d.Show()
+	})
+	itemFile := widget.NewFormItem(app.GetMsg(config.TKeyLblOutputFile), container.NewBorder(nil, nil, nil, btnFile, sw.outputFile))
+	itemFile.HintText = app.GetMsg(config.TKeyHelpOutputFile)
+
+	// Browser-based clients (e.g., a dashboard) may read the local server only from this origin.
+	sw.corsEntry = widget.NewEntry()
+	sw.corsEntry.SetText(app.Preferences.String(config.PrefCORSOrigin))
+	sw.corsEntry.Validator = app.validateCORSOrigin
+	itemCORS := widget.NewFormItem(app.GetMsg(config.TKeyLblCORSOrigin), sw.corsEntry)
+	itemCORS.HintText = app.GetMsg(config.TKeyHelpCORSOrigin)
+
+	// Beyond the loopback, only these networks may read the local server.
+	sw.networksEntry = widget.NewEntry()
+	sw.networksEntry.SetText(app.Preferences.String(config.PrefAllowedNetworks))
+	sw.networksEntry.PlaceHolder = config.PlaceholderNetworks
+	sw.networksEntry.Validator = app.validateNetworks
+	itemNetworks := widget.NewFormItem(app.GetMsg(config.TKeyLblAllowedNets), sw.networksEntry)
+	itemNetworks.HintText = app.GetMsg(config.TKeyHelpAllowedNets)
+
+	sw.checkCalDAV = widget.NewCheck(app.GetMsg(config.TKeyLblCalDAVEnable), nil)
+	sw.checkCalDAV.Checked = app.Preferences.Bool(config.PrefCalDAVEnabled)
+
+	sw.caldavURL = widget.NewEntry()
+	sw.caldavURL.SetText(app.Preferences.String(config.PrefCalDAVURL))
+	sw.caldavURL.PlaceHolder = config.PlaceholderURL
+	sw.caldavUser = widget.NewEntry()
+	sw.caldavUser.SetText(app.Preferences.String(config.PrefCalDAVUser))
+	sw.caldavPass = widget.NewPasswordEntry()
+	if pwd, err := secrets.Get(config.KeyringCalDAVPass); err == nil {
+		sw.caldavPass.SetText(pwd)
+	}
+
+	itemURL := widget.NewFormItem(app.GetMsg(config.TKeyLblCalDAVURL), sw.caldavURL)
+	itemURL.HintText = app.GetMsg(config.TKeyHelpCalDAVURL)
+	form := widget.NewForm(
+		itemURL,
+		widget.NewFormItem(app.GetMsg(config.TKeyLblUser), sw.caldavUser),
+		widget.NewFormItem(app.GetMsg(config.TKeyLblPass), sw.caldavPass),
+	)
+
+	sw.checkWebDAV = widget.NewCheck(app.GetMsg(config.TKeyLblWebDAVEnable), nil)
+	sw.checkWebDAV.Checked = app.Preferences.Bool(config.PrefWebDAVEnabled)
+
+	sw.webdavURL = widget.NewEntry()
+	sw.webdavURL.SetText(app.Preferences.String(config.PrefWebDAVURL))
+	sw.webdavURL.PlaceHolder = config.PlaceholderURL
+	sw.webdavUser = widget.NewEntry()
+	sw.webdavUser.SetText(app.Preferences.String(config.PrefWebDAVUser))
+	sw.webdavPass = widget.NewPasswordEntry()
+	if pwd, err := secrets.Get(config.KeyringWebDAVPass); err == nil {
+		sw.webdavPass.SetText(pwd)
+	}
+	sw.webdavToken = widget.NewPasswordEntry()
+	if tok, err := secrets.Get(config.KeyringWebDAVToken); err == nil {
+		sw.webdavToken.SetText(tok)
+	}
+
+	itemWebDAVURL := widget.NewFormItem(app.GetMsg(config.TKeyLblWebDAVURL), sw.webdavURL)
+	itemWebDAVURL.HintText = app.GetMsg(config.TKeyHelpWebDAVURL)
+	itemWebDAVToken := widget.NewFormItem(app.GetMsg(config.TKeyLblToken), sw.webdavToken)
+	itemWebDAVToken.HintText = app.GetMsg(config.TKeyHelpWebDAVToken)
+	webdavForm := widget.NewForm(
+		itemWebDAVURL,
+		widget.NewFormItem(app.GetMsg(config.TKeyLblUser), sw.webdavUser),
+		widget.NewFormItem(app.GetMsg(config.TKeyLblPass), sw.webdavPass),
+		itemWebDAVToken,
+	)
+
+	// Each target shows its form only when enabled.
+	toggle := func(check *widget.Check, form *widget.Form) {
+		check.OnChanged = func(b bool) {
+			if b {
+				form.Show()
+			} else {
+				form.Hide()
+			}
+			if onLayoutChange != nil {
+				onLayoutChange()
+			}
+		}
+		if !check.Checked {
+			form.Hide()
+		}
 	}
+	toggle(sw.checkCalDAV, form)
+	toggle(sw.checkWebDAV, webdavForm)
 
-	return widget.NewCard(app.GetMsg(config.TKeyLblNotif), "", container.NewVBox(sw.checkReminder, row))
+	// Shell commands run after each synchronization, with its results in the environment.
+	sw.hookSuccess = widget.NewEntry()
+	sw.hookSuccess.SetText(app.Preferences.String(config.PrefHookSuccess))
+	sw.hookFailure = widget.NewEntry()
+	sw.hookFailure.SetText(app.Preferences.String(config.PrefHookFailure))
+	itemHookSuccess := widget.NewFormItem(app.GetMsg(config.TKeyLblHookSuccess), sw.hookSuccess)
+	itemHookFailure := widget.NewFormItem(app.GetMsg(config.TKeyLblHookFailure), sw.hookFailure)
+	itemHookFailure.HintText = app.GetMsg(config.TKeyHelpHooks)
+
+	return widget.NewCard(app.GetMsg(config.TKeyLblPublish), "", container.NewVBox(
+		sw.checkServe, widget.NewForm(itemNetworks, itemCORS, itemFile),
+		sw.checkCalDAV, form,
+		sw.checkWebDAV, webdavForm,
+		widget.NewForm(itemHookSuccess, itemHookFailure),
+	))
+}
+
+// buildLimitsCard constructs the synchronization limits UI (address book size, contacts, contact size).
+func (app *GoBirthdayApp) buildLimitsCard(sw *settingsWidgets) *widget.Card {
+	newLimitEntry := func(pref string, def int) *NumericalEntry {
+		e := NewNumericalEntry()
+		e.SetText(strconv.Itoa(app.Preferences.IntWithFallback(pref, def)))
+		return e
+	}
+	sw.maxDownload = newLimitEntry(config.PrefMaxDownloadMB, config.DefaultMaxDownloadMB)
+	sw.maxContacts = newLimitEntry(config.PrefMaxContacts, config.DefaultMaxContacts)
+	sw.maxCard = newLimitEntry(config.PrefMaxCardMB, config.DefaultMaxCardMB)
+
+	unitMB := func(e *NumericalEntry) fyne.CanvasObject {
+		return container.NewBorder(nil, nil, nil, widget.NewLabel(app.GetMsg(config.TKeyUnitMB)), e)
+	}
+	itemDownload := widget.NewFormItem(app.GetMsg(config.TKeyLblMaxDownload), unitMB(sw.maxDownload))
+	itemContacts := widget.NewFormItem(app.GetMsg(config.TKeyLblMaxContacts), sw.maxContacts)
+	itemCard := widget.NewFormItem(app.GetMsg(config.TKeyLblMaxCard), unitMB(sw.maxCard))
+	itemCard.HintText = app.GetMsg(config.TKeyHelpLimits)
+
+	sw.spacing = newLimitEntry(config.PrefRequestSpacing, 0)
+	itemSpacing := widget.NewFormItem(app.GetMsg(config.TKeyLblSpacing),
+		container.NewBorder(nil, nil, nil, widget.NewLabel(app.GetMsg(config.TKeyUnitSeconds)), sw.spacing))
+	itemSpacing.HintText = app.GetMsg(config.TKeyHelpSpacing)
+
+	return widget.NewCard(app.GetMsg(config.TKeyLblLimits), "", widget.NewForm(itemDownload, itemContacts, itemCard, itemSpacing))
+}
+
+// photoModeOptions maps the labels displayed in the photo selector to photo modes.
+func (app *GoBirthdayApp) photoModeOptions() ([]string, map[string]string) {
+	labels := []string{
+		app.GetMsg(config.TKeyPhotoNone),
+		app.GetMsg(config.TKeyPhotoInline),
+		app.GetMsg(config.TKeyPhotoLink),
+	}
+	codes := map[string]string{
+		labels[0]: config.PhotoModeNone,
+		labels[1]: config.PhotoModeInline,
+		labels[2]: config.PhotoModeLink,
+	}
+	return labels, codes
+}
+
+// pushBackendOptions maps the labels displayed in the push selector to backend codes.
+func (app *GoBirthdayApp) pushBackendOptions() ([]string, map[string]string) {
+	labels := []string{app.GetMsg(config.TKeyPushNone), config.PushLabelNtfy, config.PushLabelGotify}
+	codes := map[string]string{
+		labels[0]:              config.PushBackendNone,
+		config.PushLabelNtfy:   config.PushBackendNtfy,
+		config.PushLabelGotify: config.PushBackendGotify,
+	}
+	return labels, codes
+}
+
+// buildPushCard constructs the remote push notification UI (ntfy / Gotify).
+func (app *GoBirthdayApp) buildPushCard(sw *settingsWidgets, onLayoutChange func()) *widget.Card {
+	labels, codes := app.pushBackendOptions()
+	sw.pushBackend = widget.NewSelect(labels, nil)
+
+	itemServer := widget.NewFormItem(app.GetMsg(config.TKeyLblPushServer), sw.pushServer)
+	itemServer.HintText = app.GetMsg(config.TKeyHelpPushServer)
+	itemToken := widget.NewFormItem(app.GetMsg(config.TKeyLblPushToken), sw.pushToken)
+
+	serverForm := widget.NewForm(itemServer, itemToken)
+	topicForm := widget.NewForm(widget.NewFormItem(app.GetMsg(config.TKeyLblPushTopic), sw.pushTopic))
+
+	// Dynamic visibility: the topic only exists for ntfy, nothing is shown when disabled.
+	updateVis := func(label string) {
+		switch codes[label] {
+		case config.PushBackendNtfy:
+			serverForm.Show()
+			topicForm.Show()
+		case config.PushBackendGotify:
+			serverForm.Show()
+			topicForm.Hide()
+		default:
+			serverForm.Hide()
+			topicForm.Hide()
+		}
+		if onLayoutChange != nil {
+			onLayoutChange()
+		}
+	}
+
+	// Set initial state before wiring the callback to avoid a premature resize.
+	current := app.Preferences.StringWithFallback(config.PrefPushBackend, config.PushBackendNone)
+	sw.pushBackend.SetSelected(labels[0])
+	for label, code := range codes {
+		if code == current {
+			sw.pushBackend.SetSelected(label)
+		}
+	}
+	updateVis(sw.pushBackend.Selected)
+	sw.pushBackend.OnChanged = updateVis
+
+	itemBackend := widget.NewFormItem(app.GetMsg(config.TKeyLblPushBackend), sw.pushBackend)
+
+	// Quiet hours hold the birthday pushes and the desktop notifications alike.
+	sw.quietStart = widget.NewEntry()
+	sw.quietStart.SetText(app.Preferences.String(config.PrefQuietStart))
+	sw.quietStart.PlaceHolder = config.PlaceholderQuiet
+	sw.quietStart.Validator = app.validateQuietTime
+	sw.quietEnd = widget.NewEntry()
+	sw.quietEnd.SetText(app.Preferences.String(config.PrefQuietEnd))
+	sw.quietEnd.PlaceHolder = config.PlaceholderQuietEnd
+	sw.quietEnd.Validator = app.validateQuietTime
+	itemQuiet := widget.NewFormItem(app.GetMsg(config.TKeyLblQuietHours),
+		container.NewGridWithColumns(2, sw.quietStart, sw.quietEnd))
+	itemQuiet.HintText = app.GetMsg(config.TKeyHelpQuietHours)
+
+	return widget.NewCard(app.GetMsg(config.TKeyLblPush), "", container.NewVBox(
+		widget.NewForm(itemBackend),
+		topicForm,
+		serverForm,
+		widget.NewForm(itemQuiet),
+	))
 }
 
 // saveSettings persists the data and triggers a sync.
-// It handles logic for disabling features if numeric fields are empty.
 func (app *GoBirthdayApp) saveSettings(sw *settingsWidgets, w fyne.Window) {
 	slog.Info("Saving preferences", config.LogKeyComponent, config.CompUISet)
+	app.storeSettings(sw)
+	app.storeSecrets(sw)
+
+	// Appearance, diagnostics and the port are applied right away, no restart needed.
+	app.applyTheme()
+	app.ApplyLogLevel()
+	app.applyPort()
 
-	// Helper to map UI strings back to config constants
-	modeMap := map[string]string{
-		app.GetMsg(config.TKeyModeCardDAV): config.SourceModeWeb,
-		app.GetMsg(config.TKeyModeLocal):   config.SourceModeLocal,
+	// Autostart: only touch the OS entry when the choice changed.
+	if sw.checkStartup.Checked != autostart.IsEnabled() {
+		update := autostart.Disable
+		if sw.checkStartup.Checked {
+			update = autostart.Enable
+		}
+		if err := update(); err != nil {
+			slog.Error(config.ErrAutostart, config.LogKeyError, err, config.LogKeyComponent, config.CompStartup)
+		}
 	}
 
+	// Trigger system-wide updates
+	app.UpdateLocalizer()
+	app.applyServerSettings()
+	app.RefreshTrayMenu()
+	app.performSync(true) // Force immediate sync with new settings
+
+	w.Close()
+}
+
+// storeSettings writes the settings of the window to the preferences, without the secrets.
+// It handles logic for disabling features if numeric fields are empty.
+func (app *GoBirthdayApp) storeSettings(sw *settingsWidgets) {
 	app.Preferences.SetString(config.PrefLanguage, sw.langSelect.Selected)
-	app.Preferences.SetString(config.PrefSourceMode, modeMap[sw.modeSelect.Selected])
+	app.Preferences.SetString(config.PrefSourceMode, app.modeCodes()[sw.modeSelect.Selected])
 	app.Preferences.SetString(config.PrefCardDAVURL, sw.urlEntry.Text)
+	app.Preferences.SetStringList(config.PrefAddressBooks, sw.addressBooks)
 	app.Preferences.SetString(config.PrefUsername, sw.userEntry.Text)
 	app.Preferences.SetString(config.PrefLocalPath, sw.pathEntry.Text)
+	app.Preferences.SetString(config.PrefThunderbirdPath, sw.tbirdEntry.Text)
+	app.Preferences.SetString(config.PrefExecCommand, strings.TrimSpace(sw.execEntry.Text))
 
-	// Save password to Keyring only if provided
-	if sw.userEntry.Text != "" && sw.passEntry.Text != "" {
-		if err := keyring.Set(config.KeyringService, sw.userEntry.Text, sw.passEntry.Text); err != nil {
-			slog.Error("Failed to save credentials to keyring", config.LogKeyError, err, config.LogKeyComponent, config.CompUISet)
-		}
-	}
+	// Bearer and OAuth2 authentication
+	_, authCodes := app.authTypeOptions()
+	app.Preferences.SetString(config.PrefAuthType, authCodes[sw.authSelect.Selected])
+	app.Preferences.SetString(config.PrefOAuth2TokenURL, strings.TrimSpace(sw.tokenURLEntry.Text))
+	app.Preferences.SetString(config.PrefOAuth2ClientID, strings.TrimSpace(sw.clientIDEntry.Text))
+
+	// Proxy
+	app.Preferences.SetString(config.PrefProxyURL, strings.TrimSpace(sw.proxyEntry.Text))
+	app.Preferences.SetString(config.PrefProxyUser, sw.proxyUser.Text)
 
-	// Logic: Interval
+	// TLS
+	app.Preferences.SetString(config.PrefCAFile, strings.TrimSpace(sw.caFileEntry.Text))
+	app.Preferences.SetBool(config.PrefTLSInsecure, sw.checkInsecure.Checked)
+	app.Preferences.SetString(config.PrefClientCert, strings.TrimSpace(sw.certEntry.Text))
+	app.Preferences.SetString(config.PrefClientKey, strings.TrimSpace(sw.keyEntry.Text))
+
+	// Logic: Interval, of every source mode edited.
 	// If empty or 0, we treat it as disabled (0).
-	intervalText := sw.entryInterval.Text
-	if intervalText == "" || intervalText == "0" {
-		app.Preferences.SetInt(config.PrefInterval, config.DisabledInterval)
-		slog.Info("Auto-refresh disabled via settings", config.LogKeyComponent, config.CompUISet)
-	} else {
-		if i, err := strconv.Atoi(intervalText); err == nil {
-			app.Preferences.SetInt(config.PrefInterval, i)
+	app.switchInterval(sw, sw.modeSelect.Selected)
+	for mode, intervalText := range sw.intervals {
+		key := fmt.Sprintf(config.PrefIntervalOf, mode)
+		if intervalText == "" || intervalText == "0" {
+			app.Preferences.SetInt(key, config.DisabledInterval)
+			slog.Info("Auto-refresh disabled via settings", config.LogKeyMode, mode, config.LogKeyComponent, config.CompUISet)
+		} else if i, err := strconv.Atoi(intervalText); err == nil {
+			app.Preferences.SetInt(key, i)
 		}
 	}
 
@@ -341,41 +1405,230 @@ func (app *GoBirthdayApp) saveSettings(sw *settingsWidgets, w fyne.Window) {
 		app.Preferences.SetString(config.PrefServerPort, sw.entryPort.Text)
 	}
 
-	// Logic: Reminder
-	// If the value field is empty, we force disable reminders, even if the checkbox is checked.
-	remValueText := sw.entryRemValue.Text
-	if remValueText == "" {
+	// Appearance
+	_, themeCodes := app.themeOptions()
+	app.Preferences.SetString(config.PrefTheme, themeCodes[sw.themeSelect.Selected])
+
+	// Date Display
+	app.Preferences.SetString(config.PrefDateFormat, strings.TrimSpace(sw.dateFormat.Text))
+	// An unknown time zone keeps the previous one.
+	if sw.timeZone.Validate() == nil {
+		app.Preferences.SetString(config.PrefTimeZone, strings.TrimSpace(sw.timeZone.Text))
+	}
+
+	// Keyboard Shortcut (invalid input keeps the previous one)
+	if _, ok := parseShortcut(sw.shortcutEntry.Text); ok {
+		app.Preferences.SetString(config.PrefContactsShortcut, strings.TrimSpace(sw.shortcutEntry.Text))
+	}
+
+	// Diagnostics
+	app.Preferences.SetBool(config.PrefDebugLogging, sw.checkDebug.Checked)
+	app.Preferences.SetBool(config.PrefAccessLog, sw.checkAccessLog.Checked)
+	app.Preferences.SetBool(config.PrefTodayWindow, sw.checkToday.Checked)
+
+	// Contact Photos
+	_, photoCodes := app.photoModeOptions()
+	app.Preferences.SetString(config.PrefPhotoMode, photoCodes[sw.photoSelect.Selected])
+
+	// Event Properties
+	app.Preferences.SetString(config.PrefSummaryTemplate, strings.TrimSpace(sw.summaryEntry.Text))
+	if _, codes := app.ageDisplayOptions(); codes[sw.ageSelect.Selected] != "" {
+		app.Preferences.SetString(config.PrefAgeDisplay, codes[sw.ageSelect.Selected])
+	}
+	app.Preferences.SetString(config.PrefCategories, strings.Join(splitList(sw.catEntry.Text), config.ListSeparator))
+	app.Preferences.SetBool(config.PrefContactGroups, sw.checkGroups.Checked)
+	app.Preferences.SetBool(config.PrefContactDetails, sw.checkDetails.Checked)
+	app.Preferences.SetBool(config.PrefPreferNickname, sw.checkNickname.Checked)
+	app.Preferences.SetBool(config.PrefCustomDates, sw.checkDates.Checked)
+	app.Preferences.SetBool(config.PrefMemorials, sw.checkMemorials.Checked)
+	app.Preferences.SetBool(config.PrefZodiacColumn, sw.checkZodiacCol.Checked)
+	app.Preferences.SetBool(config.PrefZodiacSummary, sw.checkZodiacSum.Checked)
+	app.Preferences.SetStringList(config.PrefIncludeCategories, sw.filterGroup.Selected)
+	color := ""
+	if sw.colorSelect.Selected != app.GetMsg(config.TKeyColorNone) {
+		color = sw.colorSelect.Selected
+	}
+	app.Preferences.SetString(config.PrefEventColor, color)
+	if _, codes := app.nameFormatOptions(); codes[sw.nameSelect.Selected] != "" {
+		app.Preferences.SetString(config.PrefNameFormat, codes[sw.nameSelect.Selected])
+	}
+	if _, codes := app.eventClassOptions(); codes[sw.classSelect.Selected] != "" {
+		app.Preferences.SetString(config.PrefEventClass, codes[sw.classSelect.Selected])
+	}
+
+	// An invalid time keeps the previous one, an empty or zero duration restores the default.
+	if sw.eventTimeEntry.Validate() == nil {
+		app.Preferences.SetString(config.PrefEventTime, strings.TrimSpace(sw.eventTimeEntry.Text))
+	}
+	if mins, _ := strconv.Atoi(sw.eventDurEntry.Text); mins > 0 {
+		app.Preferences.SetInt(config.PrefEventDuration, mins)
+	} else {
+		app.Preferences.RemoveValue(config.PrefEventDuration)
+	}
+
+	// Calendar
+	app.Preferences.SetString(config.PrefCalendarName, strings.TrimSpace(sw.calNameEntry.Text))
+	app.Preferences.SetString(config.PrefCalendarDesc, strings.TrimSpace(sw.calDescEntry.Text))
+	calColor := ""
+	if sw.calColorSelect.Selected != app.GetMsg(config.TKeyColorNone) {
+		calColor = sw.calColorSelect.Selected
+	}
+	app.Preferences.SetString(config.PrefCalendarColor, calColor)
+	// An invalid domain keeps the previous one.
+	if sw.uidDomainEntry.Validate() == nil {
+		app.Preferences.SetString(config.PrefUIDDomain, strings.TrimSpace(sw.uidDomainEntry.Text))
+	}
+	if days, err := strconv.Atoi(sw.todoDaysEntry.Text); err == nil {
+		app.Preferences.SetInt(config.PrefTodoDays, days)
+	} else {
+		app.Preferences.RemoveValue(config.PrefTodoDays)
+	}
+	nameDays := ""
+	if sw.nameDaySelect.Selected != app.GetMsg(config.TKeyNameDaysNone) {
+		nameDays = sw.nameDaySelect.Selected
+	}
+	app.Preferences.SetString(config.PrefNameDays, nameDays)
+	var offsets []string
+	for _, o := range parseOffsets(sw.offsetsEntry.Text) {
+		offsets = append(offsets, o.String())
+	}
+	app.Preferences.SetString(config.PrefOffsets, strings.Join(offsets, config.ListSeparator))
+
+	// Milestones
+	// Empty numeric fields disable the corresponding behavior (0).
+	app.Preferences.SetBool(config.PrefMilestoneEnabled, sw.checkMilestone.Checked)
+	app.Preferences.SetString(config.PrefMilestoneAges, strings.Join(splitList(sw.msAgesEntry.Text), config.ListSeparator))
+	app.Preferences.SetString(config.PrefMilestonePrefix, strings.TrimSpace(sw.msPrefixEntry.Text))
+	msEvery, _ := strconv.Atoi(sw.msEveryEntry.Text)
+	app.Preferences.SetInt(config.PrefMilestoneEvery, msEvery)
+	msDays, _ := strconv.Atoi(sw.msRemEntry.Text)
+	app.Preferences.SetInt(config.PrefMilestoneReminder, msDays)
+
+	// Limits: empty or 0 restores the default.
+	limits := []struct {
+		pref  string
+		entry *NumericalEntry
+	}{
+		{config.PrefMaxDownloadMB, sw.maxDownload},
+		{config.PrefMaxContacts, sw.maxContacts},
+		{config.PrefMaxCardMB, sw.maxCard},
+	}
+	for _, l := range limits {
+		if v, err := strconv.Atoi(l.entry.Text); err == nil && v > 0 {
+			app.Preferences.SetInt(l.pref, v)
+		} else {
+			app.Preferences.RemoveValue(l.pref)
+		}
+	}
+	spacing, _ := strconv.Atoi(sw.spacing.Text)
+	app.Preferences.SetInt(config.PrefRequestSpacing, max(spacing, 0))
+
+	// Logic: Reminders
+	// Rows with an empty value are skipped. If no valid row remains, we force disable
+	// reminders, even if the checkbox is checked.
+	var triggers []string
+	seen := make(map[string]bool)
+	for _, r := range sw.reminderRows {
+		if t, ok := app.rowTrigger(r); ok && !seen[t] {
+			seen[t] = true
+			triggers = append(triggers, t)
+		}
+	}
+	if len(triggers) == 0 {
 		app.Preferences.SetBool(config.PrefReminderEnabled, false)
-		slog.Info("Reminders disabled via settings (value is empty)", config.LogKeyComponent, config.CompUISet)
+		slog.Info("Reminders disabled via settings (no valid reminder)", config.LogKeyComponent, config.CompUISet)
 	} else {
 		// Otherwise, respect the checkbox state
 		app.Preferences.SetBool(config.PrefReminderEnabled, sw.checkReminder.Checked)
-		if v, err := strconv.Atoi(remValueText); err == nil {
-			app.Preferences.SetInt(config.PrefReminderValue, v)
+	}
+	app.Preferences.SetStringList(config.PrefReminderTriggers, triggers)
+
+	// The single-reminder keys are superseded by the trigger list.
+	app.Preferences.RemoveValue(config.PrefReminderValue)
+	app.Preferences.RemoveValue(config.PrefReminderUnit)
+	app.Preferences.RemoveValue(config.PrefReminderDir)
+
+	// Push Notifications
+	_, pushCodes := app.pushBackendOptions()
+	app.Preferences.SetString(config.PrefPushBackend, pushCodes[sw.pushBackend.Selected])
+	app.Preferences.SetString(config.PrefPushServer, sw.pushServer.Text)
+	app.Preferences.SetString(config.PrefPushTopic, sw.pushTopic.Text)
+	if sw.quietStart.Validate() == nil && sw.quietEnd.Validate() == nil {
+		app.Preferences.SetString(config.PrefQuietStart, strings.TrimSpace(sw.quietStart.Text))
+		app.Preferences.SetString(config.PrefQuietEnd, strings.TrimSpace(sw.quietEnd.Text))
+	}
+
+	// Publishing
+	app.Preferences.SetBool(config.PrefServeHTTP, sw.checkServe.Checked)
+	// An invalid allowlist or origin keeps the previous one.
+	if sw.networksEntry.Validate() == nil {
+		app.Preferences.SetString(config.PrefAllowedNetworks, strings.Join(splitList(sw.networksEntry.Text), config.ListSeparator))
+	}
+	if sw.corsEntry.Validate() == nil {
+		app.Preferences.SetString(config.PrefCORSOrigin, strings.TrimSpace(sw.corsEntry.Text))
+	}
+	app.Preferences.SetString(config.PrefOutputFile, strings.TrimSpace(sw.outputFile.Text))
+	app.Preferences.SetString(config.PrefHookSuccess, strings.TrimSpace(sw.hookSuccess.Text))
+	app.Preferences.SetString(config.PrefHookFailure, strings.TrimSpace(sw.hookFailure.Text))
+	app.Preferences.SetBool(config.PrefCalDAVEnabled, sw.checkCalDAV.Checked)
+	app.Preferences.SetString(config.PrefCalDAVURL, strings.TrimSpace(sw.caldavURL.Text))
+	app.Preferences.SetString(config.PrefCalDAVUser, sw.caldavUser.Text)
+	app.Preferences.SetBool(config.PrefWebDAVEnabled, sw.checkWebDAV.Checked)
+	app.Preferences.SetString(config.PrefWebDAVURL, strings.TrimSpace(sw.webdavURL.Text))
+	app.Preferences.SetString(config.PrefWebDAVUser, sw.webdavUser.Text)
+}
+
+// storeSecrets saves the passwords and tokens of the window, only replaced when provided.
+func (app *GoBirthdayApp) storeSecrets(sw *settingsWidgets) {
+	// Web source
+	if sw.userEntry.Text != "" && sw.passEntry.Text != "" {
+		if err := secrets.Set(sw.userEntry.Text, sw.passEntry.Text); err != nil {
+			slog.Error("Failed to save credentials to secure storage", config.LogKeyError, err, config.LogKeyComponent, config.CompUISet)
 		}
 	}
 
-	// Map Unit UI String -> Config Code (d, h, m)
-	unit := config.UnitDays // default
-	switch sw.selectRemUnit.Selected {
-	case app.GetMsg(config.TKeyUnitHours):
-		unit = config.UnitHours
-	case app.GetMsg(config.TKeyUnitMinutes):
-		unit = config.UnitMinutes
+	// Bearer and OAuth2 authentication
+	for account, secret := range map[string]string{
+		config.KeyringBearerToken:  sw.tokenEntry.Text,
+		config.KeyringOAuth2Secret: sw.secretEntry.Text,
+		config.KeyringOAuth2Token:  strings.TrimSpace(sw.refreshEntry.Text),
+	} {
+		if secret == "" {
+			continue
+		}
+		if err := secrets.Set(account, secret); err != nil {
+			slog.Error("Failed to save token to secure storage", config.LogKeyKey, account, config.LogKeyError, err, config.LogKeyComponent, config.CompUISet)
+		}
 	}
-	app.Preferences.SetString(config.PrefReminderUnit, unit)
 
-	// Map Direction UI String -> Config Code (before, after)
-	dir := config.DirBefore // default
-	if sw.selectRemDir.Selected == app.GetMsg(config.TKeyDirAfter) {
-		dir = config.DirAfter
+	// Proxy
+	if sw.proxyPass.Text != "" {
+		if err := secrets.Set(config.KeyringProxyPass, sw.proxyPass.Text); err != nil {
+			slog.Error("Failed to save proxy password to secure storage", config.LogKeyError, err, config.LogKeyComponent, config.CompUISet)
+		}
 	}
-	app.Preferences.SetString(config.PrefReminderDir, dir)
 
-	// Trigger system-wide updates
-	app.UpdateLocalizer()
-	app.RefreshTrayMenu()
-	app.performSync(true) // Force immediate sync with new settings
+	// Push Notifications
+	if sw.pushToken.Text != "" {
+		if err := secrets.Set(config.KeyringPushToken, sw.pushToken.Text); err != nil {
+			slog.Error("Failed to save push token to secure storage", config.LogKeyError, err, config.LogKeyComponent, config.CompUISet)
+		}
+	}
 
-	w.Close()
+	// Publishing
+	if sw.caldavPass.Text != "" {
+		if err := secrets.Set(config.KeyringCalDAVPass, sw.caldavPass.Text); err != nil {
+			slog.Error("Failed to save CalDAV password to secure storage", config.LogKeyError, err, config.LogKeyComponent, config.CompUISet)
+		}
+	}
+	if sw.webdavPass.Text != "" {
+		if err := secrets.Set(config.KeyringWebDAVPass, sw.webdavPass.Text); err != nil {
+			slog.Error("Failed to save WebDAV password to secure storage", config.LogKeyError, err, config.LogKeyComponent, config.CompUISet)
+		}
+	}
+	if sw.webdavToken.Text != "" {
+		if err := secrets.Set(config.KeyringWebDAVToken, sw.webdavToken.Text); err != nil {
+			slog.Error("Failed to save WebDAV token to secure storage", config.LogKeyError, err, config.LogKeyComponent, config.CompUISet)
+		}
+	}
 }
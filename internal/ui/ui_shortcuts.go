@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// parseShortcut reads a shortcut such as "Ctrl+Shift+B". At least one modifier is required,
+// so that the shortcut does not swallow regular typing. "Ctrl" maps to Cmd on macOS.
+func parseShortcut(s string) (*desktop.CustomShortcut, bool) {
+	parts := strings.Split(s, config.ShortcutSeparator)
+	if len(parts) < 2 {
+		return nil, false
+	}
+
+	var mod fyne.KeyModifier
+	for _, p := range parts[:len(parts)-1] {
+		switch strings.ToLower(strings.TrimSpace(p)) {
+		case "ctrl", "control", "cmd", "command":
+			mod |= fyne.KeyModifierShortcutDefault
+		case "alt", "option":
+			mod |= fyne.KeyModifierAlt
+		case "shift":
+			mod |= fyne.KeyModifierShift
+		case "super", "win", "meta":
+			mod |= fyne.KeyModifierSuper
+		default:
+			return nil, false
+		}
+	}
+
+	key := strings.ToUpper(strings.TrimSpace(parts[len(parts)-1]))
+	if len(key) != 1 && !isFunctionKey(key) {
+		return nil, false
+	}
+	return &desktop.CustomShortcut{KeyName: fyne.KeyName(key), Modifier: mod}, true
+}
+
+// isFunctionKey reports whether key names F1 to F12.
+func isFunctionKey(key string) bool {
+	switch key {
+	case "F1", "F2", "F3", "F4", "F5", "F6", "F7", "F8", "F9", "F10", "F11", "F12":
+		return true
+	}
+	return false
+}
+
+// contactsShortcut returns the configured shortcut opening the contacts window.
+func (app *GoBirthdayApp) contactsShortcut() *desktop.CustomShortcut {
+	if s, ok := parseShortcut(app.Preferences.StringWithFallback(config.PrefContactsShortcut, config.DefaultContactsShortcut)); ok {
+		return s
+	}
+	s, _ := parseShortcut(config.DefaultContactsShortcut)
+	return s
+}
+
+// addShortcuts registers the application shortcuts on a window:
+// refresh, settings and the configurable shortcut opening the contacts window, which is returned.
+func (app *GoBirthdayApp) addShortcuts(w fyne.Window) *desktop.CustomShortcut {
+	c := w.Canvas()
+	c.AddShortcut(&desktop.CustomShortcut{KeyName: config.ShortcutKeyRefresh, Modifier: fyne.KeyModifierShortcutDefault},
+		func(fyne.Shortcut) { go app.performSync(true) })
+	c.AddShortcut(&desktop.CustomShortcut{KeyName: config.ShortcutKeySettings, Modifier: fyne.KeyModifierShortcutDefault},
+		func(fyne.Shortcut) { app.ShowSettingsWindow() })
+	contacts := app.contactsShortcut()
+	c.AddShortcut(contacts, func(fyne.Shortcut) { app.ShowContactsWindow() })
+	return contacts
+}
+
+// updateContactsShortcut replaces the contacts shortcut of the main window after a settings change.
+func (app *GoBirthdayApp) updateContactsShortcut() {
+	if app.mainWindow == nil {
+		return
+	}
+	c := app.mainWindow.Canvas()
+	if app.mainShortcut != nil {
+		c.RemoveShortcut(app.mainShortcut)
+	}
+	app.mainShortcut = app.contactsShortcut()
+	c.AddShortcut(app.mainShortcut, func(fyne.Shortcut) { app.ShowContactsWindow() })
+}
+
+// closeOnEscape closes a secondary window when Esc is pressed outside of an input field.
+func closeOnEscape(w fyne.Window) {
+	w.Canvas().SetOnTypedKey(func(ev *fyne.KeyEvent) {
+		if ev.Name == fyne.KeyEscape {
+			w.Close()
+		}
+	})
+}
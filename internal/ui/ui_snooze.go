@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"log/slog"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+)
+
+// notifyBirthdays shows today's birthdays as a desktop notification, once a day, then offers to
+// snooze it from the tray since desktop notifications are easily dismissed by accident.
+// Without a tray or main window (daemon, --sync-once), only the push is sent.
+func (app *GoBirthdayApp) notifyBirthdays(contacts []engine.BirthdayEntry) {
+	if app.Menu == nil || app.TraySnoozeItem == nil {
+		return
+	}
+	today, names, uids := app.todaysBirthdays(contacts)
+	if len(names) == 0 || app.Preferences.String(config.PrefNotifLastDate) == today {
+		return
+	}
+	app.Preferences.SetString(config.PrefNotifLastDate, today)
+	app.showBirthdays(today, names, uids[0])
+}
+
+// showBirthdays notifies the birthdays of the day, outside of the quiet hours, and makes them snoozable.
+// They are dropped if the day is over by then. Clicking the notification opens the contacts window
+// on the contact of uid, the first one.
+func (app *GoBirthdayApp) showBirthdays(today string, names []string, uid string) {
+	title, body := app.buildPushMessage(names)
+	app.deliver(func() {
+		if app.now().Format(config.DateFormatFullDash) != today {
+			return
+		}
+		app.sendNotification(fyne.NewNotification(title, body), func() { app.ShowContact(uid) })
+
+		app.snoozeMu.Lock()
+		app.snoozeDay, app.snoozeNames, app.snoozeUID = today, names, uid
+		app.snoozeMu.Unlock()
+		app.TraySnoozeItem.Disabled = false
+		app.Menu.Refresh()
+	})
+}
+
+// snoozeDelay returns how long a snooze choice (config.Snooze*) waits; tomorrow is the same time
+// on the next day, for belated wishes.
+func snoozeDelay(choice string, now time.Time) time.Duration {
+	switch choice {
+	case config.SnoozeHour:
+		return time.Hour
+	case config.SnoozeHours:
+		return config.SnoozeHoursDelay
+	default:
+		return now.AddDate(0, 0, 1).Sub(now)
+	}
+}
+
+// snooze shows the last birthday notification again later. A new snooze replaces the previous one.
+func (app *GoBirthdayApp) snooze(choice string) {
+	now := app.now()
+	delay := snoozeDelay(choice, now)
+
+	app.snoozeMu.Lock()
+	day, names, uid := app.snoozeDay, app.snoozeNames, app.snoozeUID
+	if app.snoozeTimer != nil {
+		app.snoozeTimer.Stop()
+	}
+	app.snoozeTimer = time.AfterFunc(delay, func() {
+		if app.Ctx.Err() != nil {
+			return
+		}
+		if choice == config.SnoozeTomorrow {
+			day = app.now().Format(config.DateFormatFullDash) // Belated wishes, on the next day
+		}
+		app.showBirthdays(day, names, uid)
+	})
+	app.snoozeMu.Unlock()
+
+	app.TraySnoozeItem.Disabled = true
+	app.Menu.Refresh()
+	slog.Info(config.MsgNotifSnoozed,
+		config.LogKeyComponent, config.CompUI,
+		config.LogKeyUntil, now.Add(delay).Format(time.RFC3339))
+}
+
+// newSnoozeItem builds the tray entry snoozing the birthday notification, disabled until one is shown.
+func (app *GoBirthdayApp) newSnoozeItem() *fyne.MenuItem {
+	item := fyne.NewMenuItem(app.GetMsg(config.TKeyMenuSnooze), nil)
+	item.ChildMenu = fyne.NewMenu("",
+		fyne.NewMenuItem(app.GetMsg(config.TKeySnoozeHour), func() { app.snooze(config.SnoozeHour) }),
+		fyne.NewMenuItem(app.GetMsg(config.TKeySnoozeHours), func() { app.snooze(config.SnoozeHours) }),
+		fyne.NewMenuItem(app.GetMsg(config.TKeySnoozeTomorrow), func() { app.snooze(config.SnoozeTomorrow) }),
+	)
+	item.Disabled = true
+	return item
+}
+
+// refreshSnoozeLabels updates the localized labels of the snooze entry.
+func (app *GoBirthdayApp) refreshSnoozeLabels() {
+	app.TraySnoozeItem.Label = app.GetMsg(config.TKeyMenuSnooze)
+	items := app.TraySnoozeItem.ChildMenu.Items
+	items[0].Label = app.GetMsg(config.TKeySnoozeHour)
+	items[1].Label = app.GetMsg(config.TKeySnoozeHours)
+	items[2].Label = app.GetMsg(config.TKeySnoozeTomorrow)
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"log/slog"
+	"net"
+	"net/netip"
+	"net/url"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// subscriptionURL returns the webcal:// address of the calendar served on addr ("host:port").
+// A server listening on every interface is reached through the loopback.
+func subscriptionURL(addr string) *url.URL {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = config.LocalhostBindAddr, addr
+	}
+	if ip, err := netip.ParseAddr(host); err != nil || ip.IsUnspecified() {
+		host = config.LocalhostBindAddr
+	}
+	return &url.URL{Scheme: config.SchemeWebcal, Host: net.JoinHostPort(host, port), Path: config.RouteCalendarFile}
+}
+
+// calendarURL returns the subscription address of the calendar, on the first interface of the server.
+func (app *GoBirthdayApp) calendarURL() *url.URL {
+	addrs := app.Server.Addrs()
+	if len(addrs) == 0 {
+		return subscriptionURL(app.Server.Port())
+	}
+	return subscriptionURL(addrs[0])
+}
+
+// CopyCalendarURL puts the subscription address of the calendar on the clipboard, so that the port
+// need not be remembered.
+func (app *GoBirthdayApp) CopyCalendarURL() {
+	app.App.Clipboard().SetContent(app.calendarURL().String())
+	app.App.SendNotification(fyne.NewNotification(config.AppName, app.GetMsg(config.TKeyNotifURLCopied)))
+}
+
+// OpenCalendarApp hands the subscription address to the application registered for webcal://,
+// which usually offers to subscribe to the calendar.
+func (app *GoBirthdayApp) OpenCalendarApp() {
+	u := app.calendarURL()
+	if err := app.App.OpenURL(u); err != nil {
+		slog.Warn(config.ErrOpenURL,
+			config.LogKeyComponent, config.CompUI,
+			config.LogKeyURL, u.String(),
+			config.LogKeyError, err,
+		)
+		app.App.SendNotification(fyne.NewNotification(config.AppName, app.GetMsg(config.TKeyNotifNoCalApp)))
+	}
+}
@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+)
+
+// syncRun is a synchronization in progress.
+type syncRun struct {
+	cancel   context.CancelFunc
+	progress engine.Progress
+}
+
+// startSync registers a synchronization, replacing as the one canceled from the tray any
+// other still in progress. It returns its context, canceled by CancelSync or on shutdown.
+func (app *GoBirthdayApp) startSync() (context.Context, *syncRun) {
+	ctx, cancel := context.WithCancel(app.Ctx)
+	run := &syncRun{cancel: cancel}
+	app.syncMut.Lock()
+	app.running = run
+	app.syncMut.Unlock()
+	app.updateSyncItems()
+	return ctx, run
+}
+
+// endSync releases the context of run once it has finished.
+func (app *GoBirthdayApp) endSync(run *syncRun) {
+	run.cancel()
+	app.syncMut.Lock()
+	if app.running == run {
+		app.running = nil
+	}
+	app.syncMut.Unlock()
+	app.updateSyncItems()
+}
+
+// syncProgress shows how far run has read the source.
+func (app *GoBirthdayApp) syncProgress(run *syncRun, p engine.Progress) {
+	app.syncMut.Lock()
+	run.progress = p
+	app.syncMut.Unlock()
+	app.updateSyncItems()
+}
+
+// CancelSync stops the synchronization in progress, if any; the previous calendar is kept.
+func (app *GoBirthdayApp) CancelSync() {
+	app.syncMut.Lock()
+	run := app.running
+	app.syncMut.Unlock()
+	if run != nil {
+		run.cancel()
+	}
+}
+
+// recordSync notes the end of a synchronization and its error, shown in the tray.
+func (app *GoBirthdayApp) recordSync(err error) {
+	app.syncMut.Lock()
+	app.syncedAt, app.syncOK, app.offline = app.now(), err == nil, engine.IsOffline(err)
+	app.syncMut.Unlock()
+	app.updateSyncItems()
+}
+
+// isOffline reports whether the last synchronization failed for lack of network.
+func (app *GoBirthdayApp) isOffline() bool {
+	app.syncMut.Lock()
+	defer app.syncMut.Unlock()
+	return app.offline
+}
+
+// scheduleSync notes that the worker synchronizes again in d, shown in the tray.
+func (app *GoBirthdayApp) scheduleSync(d time.Duration) {
+	app.syncMut.Lock()
+	app.nextSync = app.Clock.Now().Add(d)
+	app.syncMut.Unlock()
+	app.updateSyncItems()
+}
+
+// syncLabels returns the labels of the last synchronization, e.g., "Last sync: 12:04 (ok)",
+// or of the one in progress, and of the next one, e.g., "Next sync in 37 min".
+func (app *GoBirthdayApp) syncLabels() (last, next string) {
+	app.syncMut.Lock()
+	syncedAt, ok, offline, nextSync := app.syncedAt, app.syncOK, app.offline, app.nextSync
+	var progress *engine.Progress
+	if app.running != nil {
+		p := app.running.progress
+		progress = &p
+	}
+	app.syncMut.Unlock()
+
+	switch {
+	case progress != nil && progress.Cards == 0:
+		last = app.GetMsg(config.TKeyMenuSyncing)
+	case progress != nil:
+		size := fmt.Sprintf(config.FormatMegabytes, float64(progress.Bytes)/config.BytesPerMB)
+		last = fmt.Sprintf(app.GetMsg(config.TKeyMenuSyncProgress), progress.Cards, size)
+	case syncedAt.IsZero():
+		last = app.GetMsg(config.TKeyMenuLastSyncNever)
+	case ok:
+		last = fmt.Sprintf(app.GetMsg(config.TKeyMenuLastSync), syncedAt.Format(config.EventTimeFormat))
+	case offline:
+		last = fmt.Sprintf(app.GetMsg(config.TKeyMenuLastSyncOffline), syncedAt.Format(config.EventTimeFormat))
+	default:
+		last = fmt.Sprintf(app.GetMsg(config.TKeyMenuLastSyncFailed), syncedAt.Format(config.EventTimeFormat))
+	}
+
+	// Rounded up: the last minute is "less than a minute".
+	minutes := int((nextSync.Sub(app.Clock.Now()) + time.Minute - 1) / time.Minute)
+	if nextSync.IsZero() || minutes <= 1 {
+		next = app.GetMsg(config.TKeyMenuNextSyncSoon)
+	} else {
+		next = fmt.Sprintf(app.GetMsg(config.TKeyMenuNextSync), minutes)
+	}
+	return last, next
+}
+
+// updateSyncItems refreshes the tray entries telling when the contacts were last and will next
+// be synchronized, or how far the synchronization in progress is, which can then be canceled.
+func (app *GoBirthdayApp) updateSyncItems() {
+	if app.Menu == nil || app.TrayLastSyncItem == nil {
+		return
+	}
+	app.TrayLastSyncItem.Label, app.TrayNextSyncItem.Label = app.syncLabels()
+	app.syncMut.Lock()
+	app.TrayCancelItem.Disabled = app.running == nil
+	app.syncMut.Unlock()
+	app.Menu.Refresh()
+}
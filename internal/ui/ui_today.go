@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+)
+
+// showTodayWindow lists today's birthdays on launch, when enabled in the settings, for those who
+// start their machine each morning. It is shown once per run, with the cached contacts or after
+// the first synchronization, and not again on a day it was dismissed for.
+func (app *GoBirthdayApp) showTodayWindow(contacts []engine.BirthdayEntry) {
+	if app.Menu == nil || !app.Preferences.Bool(config.PrefTodayWindow) {
+		return
+	}
+	today, names, uids := app.todaysBirthdays(contacts)
+	if len(names) == 0 || app.Preferences.String(config.PrefTodayDismissed) == today {
+		return
+	}
+	if !app.todayShown.CompareAndSwap(false, true) {
+		return
+	}
+
+	fyne.Do(func() {
+		w := app.App.NewWindow(app.GetMsg(config.TKeyWinToday))
+
+		list := container.NewVBox()
+		for i, name := range names {
+			uid := uids[i]
+			btn := widget.NewButtonWithIcon(name, theme.AccountIcon(), func() { app.ShowContact(uid) })
+			btn.Alignment = widget.ButtonAlignLeading
+			btn.Importance = widget.LowImportance
+			list.Add(btn)
+		}
+
+		checkDismiss := widget.NewCheck(app.GetMsg(config.TKeyLblTodayDismiss), nil)
+		btnClose := widget.NewButtonWithIcon(app.GetMsg(config.TKeyBtnClose), theme.CancelIcon(), w.Close)
+
+		w.SetContent(container.NewBorder(nil, container.NewBorder(nil, nil, checkDismiss, btnClose), nil, nil,
+			container.NewVScroll(list)))
+		w.Resize(fyne.NewSize(config.TodayWinWidth, config.TodayWinHeight))
+
+		closeOnEscape(w)
+		w.SetOnClosed(func() {
+			if checkDismiss.Checked {
+				app.Preferences.SetString(config.PrefTodayDismissed, today)
+			}
+		})
+		w.Show()
+	})
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+)
+
+// upcomingContacts returns the next birthdays, soonest first, skipping hidden contacts.
+func upcomingContacts(contacts []engine.BirthdayEntry, limit int) []engine.BirthdayEntry {
+	list := make([]engine.BirthdayEntry, 0, len(contacts))
+	for _, c := range contacts {
+		if !c.Hidden && !c.Deceased {
+			list = append(list, c)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].NextOccurrence.Equal(list[j].NextOccurrence) {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].NextOccurrence.Before(list[j].NextOccurrence)
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list
+}
+
+// upcomingLabel formats a submenu entry, e.g., "Mar 3 – Alice (30)".
+func (app *GoBirthdayApp) upcomingLabel(c engine.BirthdayEntry) string {
+	format := app.dateLayout(config.TKeyFormatDay, config.DateFormatDay)
+	label := fmt.Sprintf(config.FormatUpcoming, c.NextOccurrence.Format(format), c.Name)
+	if age := app.ageTurning(c); age != "" {
+		label += fmt.Sprintf(config.FormatUpcomingAge, age)
+	}
+	return label
+}
+
+// updateUpcomingMenu rebuilds the "Upcoming birthdays" submenu after a synchronization.
+// Each entry opens the contacts window on that person.
+func (app *GoBirthdayApp) updateUpcomingMenu(contacts []engine.BirthdayEntry) {
+	if app.Menu == nil || app.TrayUpcomingItem == nil {
+		return
+	}
+
+	var items []*fyne.MenuItem
+	for _, c := range upcomingContacts(contacts, config.UpcomingCount) {
+		uid := c.UID
+		items = append(items, fyne.NewMenuItem(app.upcomingLabel(c), func() {
+			app.ShowContact(uid)
+		}))
+	}
+
+	app.TrayUpcomingItem.ChildMenu = fyne.NewMenu("", items...)
+	app.TrayUpcomingItem.Disabled = len(items) == 0
+	app.Menu.Refresh()
+}
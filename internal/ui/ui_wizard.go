@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+)
+
+// showNextcloudWizard asks for the address of a Nextcloud and the credentials of the user,
+// discovers their address books and fills the web source with the chosen ones (applied on save).
+func (app *GoBirthdayApp) showNextcloudWizard(w fyne.Window, sw *settingsWidgets) {
+	if _, ok := app.Fetcher.(engine.Discoverer); !ok {
+		dialog.ShowError(errors.New(config.ErrNoDiscovery), w)
+		return
+	}
+
+	urlEntry := widget.NewEntry()
+	urlEntry.PlaceHolder = config.PlaceholderCloud
+	userEntry := widget.NewEntry()
+	userEntry.SetText(sw.userEntry.Text)
+	passEntry := widget.NewPasswordEntry()
+
+	itemURL := widget.NewFormItem(app.GetMsg(config.TKeyLblNextcloudURL), urlEntry)
+	itemURL.HintText = app.GetMsg(config.TKeyHelpNextcloudURL)
+	itemPass := widget.NewFormItem(app.GetMsg(config.TKeyLblPass), passEntry)
+	itemPass.HintText = app.GetMsg(config.TKeyHelpAppPassword)
+	items := []*widget.FormItem{itemURL, widget.NewFormItem(app.GetMsg(config.TKeyLblUser), userEntry), itemPass}
+
+	title := app.GetMsg(config.TKeyWinNextcloud)
+	d := dialog.NewForm(title, app.GetMsg(config.TKeyBtnNext), app.GetMsg(config.TKeyBtnCancel), items, func(ok bool) {
+		if ok {
+			app.discoverAddressBooks(w, sw, title, urlEntry.Text, config.NextcloudExportQuery, userEntry.Text, passEntry.Text)
+		}
+	}, w)
+	d.Resize(fyne.NewSize(config.SettingsWindowWidth, d.MinSize().Height))
+	d.Show()
+}
+
+// showAddressBooks lists the address books of the account of the web source, found from its
+// URL (e.g., an address book home), so that several of them can be synchronized.
+func (app *GoBirthdayApp) showAddressBooks(w fyne.Window, sw *settingsWidgets) {
+	u, err := url.Parse(strings.TrimSpace(sw.urlEntry.Text))
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("%s: %w", config.ErrInvalidURL, err), w)
+		return
+	}
+	// The chosen address books are downloaded as the URL is (e.g., a Nextcloud export).
+	query := u.RawQuery
+	u.RawQuery = ""
+
+	user, pass := sw.userEntry.Text, ""
+	if user != "" {
+		pass = secretOr(sw.passEntry.Text, user)
+	}
+	app.discoverAddressBooks(w, sw, app.GetMsg(config.TKeyWinAddressBooks), u.String(), query, user, pass)
+}
+
+// discoverAddressBooks finds the address books of user at baseURL in the background, then
+// lets the user choose among them.
+func (app *GoBirthdayApp) discoverAddressBooks(w fyne.Window, sw *settingsWidgets, title, baseURL, query, user, pass string) {
+	discoverer, ok := app.Fetcher.(engine.Discoverer)
+	if !ok {
+		dialog.ShowError(errors.New(config.ErrNoDiscovery), w)
+		return
+	}
+	progress := dialog.NewCustomWithoutButtons(title, widget.NewProgressBarInfinite(), w)
+	progress.Show()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(app.Ctx, config.HTTPTimeout)
+		defer cancel()
+		books, err := discoverer.Discover(ctx, baseURL, user, pass)
+		fyne.Do(func() {
+			progress.Hide()
+			if err != nil {
+				slog.Warn(config.ErrDiscovery, config.LogKeyError, err, config.LogKeyComponent, config.CompUISet)
+				dialog.ShowError(err, w)
+				return
+			}
+			slog.Info(config.MsgDiscovered, config.LogKeyCount, len(books), config.LogKeyComponent, config.CompUISet)
+			app.chooseAddressBooks(w, sw, title, books, query, user, pass)
+		})
+	}()
+}
+
+// bookURL returns the URL downloading book, with query if not empty.
+func bookURL(book engine.AddressBook, query string) string {
+	if query == "" {
+		return book.URL
+	}
+	return book.URL + "?" + query
+}
+
+// chooseAddressBooks lets the user tick the discovered address books to synchronize, with their
+// number of contacts. Those of the web source are preselected, or else the default one.
+func (app *GoBirthdayApp) chooseAddressBooks(w fyne.Window, sw *settingsWidgets, title string, books []engine.AddressBook, query, user, pass string) {
+	current := sw.addressBooks
+	if len(current) == 0 {
+		current = []string{strings.TrimSpace(sw.urlEntry.Text)}
+	}
+
+	labels := make([]string, len(books))
+	var selected, fallback []string
+	for i, b := range books {
+		labels[i] = b.Name
+		if b.Contacts >= 0 {
+			labels[i] = fmt.Sprintf(config.FormatAddressBook, b.Name, b.Contacts)
+		}
+		if slices.Contains(current, bookURL(b, query)) {
+			selected = append(selected, labels[i])
+		}
+		if strings.HasSuffix(b.URL, "/"+config.NextcloudDefaultBook+"/") {
+			fallback = []string{labels[i]}
+		}
+	}
+	if len(selected) == 0 {
+		selected = fallback
+	}
+	if len(selected) == 0 {
+		selected = labels[:1]
+	}
+	choice := widget.NewCheckGroup(labels, nil)
+	choice.SetSelected(selected)
+
+	item := widget.NewFormItem(app.GetMsg(config.TKeyLblAddressBook), choice)
+	item.HintText = app.GetMsg(config.TKeyHelpAddressBooks)
+	dialog.ShowForm(title, app.GetMsg(config.TKeyBtnUse), app.GetMsg(config.TKeyBtnCancel), []*widget.FormItem{item}, func(ok bool) {
+		var chosen []engine.AddressBook
+		for i, b := range books {
+			if slices.Contains(choice.Selected, labels[i]) {
+				chosen = append(chosen, b)
+			}
+		}
+		if ok && len(chosen) > 0 {
+			app.applyAddressBooks(sw, chosen, query, user, pass)
+		}
+	}, w)
+}
+
+// applyAddressBooks fills the web source with the chosen address books, downloaded with query,
+// and Basic authentication. The first one is the URL; several are merged.
+func (app *GoBirthdayApp) applyAddressBooks(sw *settingsWidgets, books []engine.AddressBook, query, user, pass string) {
+	urls := make([]string, len(books))
+	for i, b := range books {
+		urls[i] = bookURL(b, query)
+	}
+	sw.modeSelect.SetSelected(app.GetMsg(config.TKeyModeCardDAV))
+	sw.urlEntry.SetText(urls[0]) // Resets the address books
+	labels, _ := app.authTypeOptions()
+	sw.authSelect.SetSelected(labels[0])
+	sw.userEntry.SetText(user)
+	sw.passEntry.SetText(pass)
+	if len(urls) > 1 {
+		app.setAddressBooks(sw, urls)
+	}
+}
+
+// setAddressBooks sets the address books merged by the web source, noted under its URL.
+func (app *GoBirthdayApp) setAddressBooks(sw *settingsWidgets, urls []string) {
+	sw.addressBooks = urls
+	if len(urls) == 0 {
+		sw.booksLabel.Hide()
+		return
+	}
+	sw.booksLabel.SetText(fmt.Sprintf(app.GetMsg(config.TKeyLblBooksMerged), len(urls)))
+	sw.booksLabel.Show()
+}
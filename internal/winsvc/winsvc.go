@@ -0,0 +1,4 @@
+// Package winsvc runs the daemon mode as a Windows service: installation in the service control
+// manager, so that the server starts at boot without a logged-in user, the service lifecycle,
+// and logging to the Windows event log. Elsewhere, its functions report config.ErrWinServiceOS.
+package winsvc
@@ -0,0 +1,36 @@
+//go:build !windows
+
+package winsvc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+)
+
+// IsService reports whether the process was started by the service control manager.
+func IsService() bool {
+	return false
+}
+
+// Install registers the running executable as a Windows service.
+func Install(_, _ string) error {
+	return errors.New(config.ErrWinServiceOS)
+}
+
+// Uninstall removes the Windows service.
+func Uninstall() error {
+	return errors.New(config.ErrWinServiceOS)
+}
+
+// Run calls run directly: there is no service control manager.
+func Run(ctx context.Context, run func(ctx context.Context) error) error {
+	return run(ctx)
+}
+
+// EventLogHandler returns a slog handler writing to the Windows event log.
+func EventLogHandler(_ slog.Leveler) (slog.Handler, error) {
+	return nil, errors.New(config.ErrWinServiceOS)
+}
@@ -0,0 +1,30 @@
+package winsvc_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/winsvc"
+)
+
+// TestOutsideService verifies the behavior of a process that the service control manager did not start.
+func TestOutsideService(t *testing.T) {
+	assert.False(t, winsvc.IsService())
+
+	called := false
+	err := winsvc.Run(context.Background(), func(context.Context) error {
+		called = true
+		return nil
+	})
+	if runtime.GOOS == config.GOOSWindows {
+		assert.Error(t, err, "Only the service control manager can run the service")
+		return
+	}
+	assert.NoError(t, err)
+	assert.True(t, called, "Without a service control manager, run is called directly")
+	assert.ErrorContains(t, winsvc.Install("", ""), config.ErrWinServiceOS)
+	assert.ErrorContains(t, winsvc.Uninstall(), config.ErrWinServiceOS)
+}
@@ -0,0 +1,222 @@
+//go:build windows
+
+package winsvc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// IsService reports whether the process was started by the service control manager.
+func IsService() bool {
+	ok, err := svc.IsWindowsService()
+	return err == nil && ok
+}
+
+// Install registers the running executable as an automatic service started with --daemon,
+// and its event log source. An existing service is updated, which also repairs it after the
+// binary has moved. An empty user runs the service as LocalSystem.
+func Install(user, password string) error {
+	exe, err := executable()
+	if err != nil {
+		return err
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+
+	conf := mgr.Config{
+		DisplayName:      config.AppName,
+		Description:      config.WinServiceDesc,
+		StartType:        mgr.StartAutomatic,
+		ServiceStartName: user,
+		Password:         password,
+	}
+	s, err := m.OpenService(config.WinServiceName)
+	if err == nil {
+		conf.BinaryPathName = syscall.EscapeArg(exe) + " --" + config.FlagDaemon
+		err = s.UpdateConfig(conf)
+	} else {
+		s, err = m.CreateService(config.WinServiceName, exe, conf, "--"+config.FlagDaemon)
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+
+	// Restart after a crash or a failure, as Restart=on-failure does with systemd.
+	restart := []mgr.RecoveryAction{{Type: mgr.ServiceRestart, Delay: config.WinServiceRestart}}
+	if err := s.SetRecoveryActions(restart, config.WinServiceResetPeriod); err != nil {
+		return err
+	}
+	if err := s.SetRecoveryActionsOnNonCrashFailures(true); err != nil {
+		return err
+	}
+
+	_ = eventlog.Remove(config.WinServiceName) // Registered again, for the current executable
+	return eventlog.InstallAsEventCreate(config.WinServiceName, eventlog.Error|eventlog.Warning|eventlog.Info)
+}
+
+// Uninstall stops and removes the service and its event log source.
+func Uninstall() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+
+	s, err := m.OpenService(config.WinServiceName)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+
+	if status, err := s.Control(svc.Stop); err == nil {
+		deadline := time.Now().Add(config.WinServiceStopWait)
+		for status.State != svc.Stopped && time.Now().Before(deadline) {
+			time.Sleep(time.Second)
+			if status, err = s.Query(); err != nil {
+				break
+			}
+		}
+	}
+	if err := s.Delete(); err != nil {
+		return err
+	}
+	if err := eventlog.Remove(config.WinServiceName); err != nil && !errors.Is(err, windows.ERROR_FILE_NOT_FOUND) {
+		return err
+	}
+	return nil
+}
+
+// Run runs the service: run is called with a context cancelled when the service control manager
+// stops the service (or the system shuts down), and the service stops when run returns.
+func Run(ctx context.Context, run func(ctx context.Context) error) error {
+	h := &handler{ctx: ctx, run: run}
+	if err := svc.Run(config.WinServiceName, h); err != nil {
+		return err
+	}
+	return h.err
+}
+
+// handler implements svc.Handler around the run function.
+type handler struct {
+	ctx context.Context
+	run func(ctx context.Context) error
+	err error
+}
+
+func (h *handler) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	ctx, cancel := context.WithCancel(h.ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- h.run(ctx) }()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case h.err = <-done:
+			if h.err != nil {
+				return false, 1 // Reported as a failure, so that the recovery actions restart the service
+			}
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				cancel()
+			}
+		}
+	}
+}
+
+// EventLogHandler returns a slog handler writing to the event log of the service, filtered by level.
+// Records are formatted as text without their time, which the event log already records.
+func EventLogHandler(level slog.Leveler) (slog.Handler, error) {
+	elog, err := eventlog.Open(config.WinServiceName)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	text := slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	return &eventHandler{elog: elog, mu: new(sync.Mutex), buf: buf, text: text}, nil
+}
+
+// eventHandler formats records with a text handler writing to buf, then sends them to the event log.
+// Handlers derived by WithAttrs and WithGroup share the buffer and its lock.
+type eventHandler struct {
+	elog *eventlog.Log
+	mu   *sync.Mutex
+	buf  *bytes.Buffer
+	text slog.Handler
+}
+
+func (h *eventHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.text.Enabled(ctx, level)
+}
+
+func (h *eventHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.buf.Reset()
+	if err := h.text.Handle(ctx, r); err != nil {
+		return err
+	}
+	msg := strings.TrimSpace(h.buf.String())
+	switch {
+	case r.Level >= slog.LevelError:
+		return h.elog.Error(config.WinEventID, msg)
+	case r.Level >= slog.LevelWarn:
+		return h.elog.Warning(config.WinEventID, msg)
+	default:
+		return h.elog.Info(config.WinEventID, msg)
+	}
+}
+
+func (h *eventHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &eventHandler{elog: h.elog, mu: h.mu, buf: h.buf, text: h.text.WithAttrs(attrs)}
+}
+
+func (h *eventHandler) WithGroup(name string) slog.Handler {
+	return &eventHandler{elog: h.elog, mu: h.mu, buf: h.buf, text: h.text.WithGroup(name)}
+}
+
+// executable returns the absolute path of the running binary, with symlinks resolved.
+func executable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return exe, nil
+}
@@ -0,0 +1,219 @@
+// Package birthday converts vCard address books into iCalendar (RFC 5545) birthday calendars.
+//
+// It is the importable API of the Go Birthday engine, for Go programs that need the
+// conversion without the desktop application. Its types are its own, converted to those of
+// the engine, so that the engine can change without breaking the programs using them.
+package birthday
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/engine"
+)
+
+// Config holds the generation settings; its zero value produces plain all-day birthdays.
+type Config struct {
+	Now time.Time // Day of the calendar, the current time if zero
+
+	ReminderTriggers []string      // ISO 8601 durations (e.g., "-P1D", "-PT9H"), one reminder each
+	Categories       []string      // CATEGORIES of every event (e.g., "Birthday")
+	ContactGroups    bool          // Also copy the vCard CATEGORIES of each contact onto its events
+	ContactDetails   bool          // Email, phone and NOTE of the contact in the event description
+	PhotoMode        string        // PhotoNone (default), PhotoInline or PhotoLink
+	PhotoBaseURL     string        // Base URL of the pictures with PhotoLink
+	Color            string        // RFC 7986 COLOR of the events (CSS3 color name), empty to omit
+	Class            string        // CLASS of the events ("PUBLIC", "PRIVATE" or "CONFIDENTIAL"), "PRIVATE" if empty
+	CalendarName     string        // Name of the calendar, "Birthdays" if empty
+	CalendarDesc     string        // Description of the calendar, empty to omit
+	CalendarColor    string        // RFC 7986 COLOR of the calendar, empty to omit
+	UIDDomain        string        // Domain of the event UIDs, "gobirthday" if empty
+	TimeZone         string        // IANA time zone of the event dates, the one of Now if empty
+	EventTime        string        // Start of timed events ("09:00"), empty for all-day events
+	EventDuration    time.Duration // Duration of timed events, 30 minutes if zero
+
+	Milestones       []int  // Milestone ages (e.g., 18, 21)
+	MilestoneEvery   int    // Every multiple of it is a milestone age (e.g., 10), 0 to disable
+	MilestonePrefix  string // Prepended to the summary of milestone birthdays (e.g., "🎉")
+	MilestoneTrigger string // Extra ISO 8601 reminder of milestone birthdays
+
+	IncludeCategories []string // Only convert the contacts in one of these vCard CATEGORIES, all if empty
+	CustomDates       bool     // Also convert the Apple X-ABDATE dates (anniversaries...)
+	Memorials         bool     // Remembrance events on the date of death, with no birthday after it
+	ZodiacSummary     bool     // Append the zodiac sign to the birthday summaries
+	NameDays          string   // Language of the name days (e.g., "fr"), empty to disable
+	PreferNickname    bool     // Name the contacts after their vCard NICKNAME, when set
+	NameFormat        string   // NameFN (default), NameGivenFamily or NameFamilyGiven
+	Offsets           []Offset // Extra anniversaries of every contact (half birthdays...)
+	Limits            Limits   // Resource limits, zero values for the defaults
+}
+
+// Limits bounds the resources of a conversion; zero values are the defaults.
+type Limits struct {
+	MaxBytes     int64 // Size of the vCard stream
+	MaxContacts  int   // vCards in the stream
+	MaxCardBytes int64 // Size of a single vCard; larger ones are skipped
+}
+
+// Offset is an anniversary after the birthday: Months after every birthday (e.g., 6 for
+// half birthdays), or Days after the birth, once (e.g., 100).
+type Offset struct {
+	Months int
+	Days   int
+}
+
+// Contact is a contact with a birthday, as found by the conversion.
+type Contact struct {
+	UID            string    // Stable identifier of the contact, in the UIDs of its events
+	Name           string    // Name of the events, after Config.NameFormat and Config.PreferNickname
+	FullName       string    // Formatted name (FN) as written
+	Nickname       string    // First vCard NICKNAME, if any
+	Note           string    // vCard NOTE, if any
+	DateOfBirth    time.Time // The year is only meaningful if YearKnown
+	YearKnown      bool
+	NextOccurrence time.Time // Next birthday, on or after Config.Now
+	AgeNext        int       // Age turned on NextOccurrence, if YearKnown
+	Categories     []string  // vCard CATEGORIES
+	Milestone      bool      // AgeNext is a milestone age
+	Deceased       bool      // The calendar has no birthday after the year of death (Config.Memorials)
+}
+
+// Issue is an RFC 5545 violation found by Validate.
+type Issue struct {
+	Line   int    // Line (1-based) where the faulty content line starts
+	Reason string // What is wrong
+}
+
+func (i Issue) String() string {
+	return engine.ICSIssue{Line: i.Line, Reason: i.Reason}.String()
+}
+
+// Contact pictures (Config.PhotoMode).
+const (
+	PhotoNone   = config.PhotoModeNone
+	PhotoInline = config.PhotoModeInline
+	PhotoLink   = config.PhotoModeLink // Config.PhotoBaseURL + "/photos/{uid}.jpg"
+)
+
+// Names of the contacts (Config.NameFormat).
+const (
+	NameFN          = config.NameFormatFN          // vCard FN as written
+	NameGivenFamily = config.NameFormatGivenFamily // "Jane Doe", from the structured N
+	NameFamilyGiven = config.NameFormatFamilyGiven // "Doe, Jane", from the structured N
+)
+
+// ErrLimitExceeded is returned (wrapped) when the vCards exceed Config.Limits.
+var ErrLimitExceeded = engine.ErrLimitExceeded
+
+// fixedClock is the clock of a Config with Now set.
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// syncConfig converts c to the settings of the engine.
+func (c Config) syncConfig() engine.SyncConfig {
+	offsets := make([]engine.Offset, 0, len(c.Offsets))
+	for _, o := range c.Offsets {
+		offsets = append(offsets, engine.Offset{Months: o.Months, Days: o.Days})
+	}
+	return engine.SyncConfig{
+		ReminderTriggers:  c.ReminderTriggers,
+		Categories:        c.Categories,
+		ContactGroups:     c.ContactGroups,
+		ContactDetails:    c.ContactDetails,
+		PhotoMode:         c.PhotoMode,
+		PhotoBaseURL:      c.PhotoBaseURL,
+		Color:             c.Color,
+		Class:             c.Class,
+		CalendarName:      c.CalendarName,
+		CalendarDesc:      c.CalendarDesc,
+		CalendarColor:     c.CalendarColor,
+		UIDDomain:         c.UIDDomain,
+		TimeZone:          c.TimeZone,
+		EventTime:         c.EventTime,
+		EventDuration:     c.EventDuration,
+		Milestones:        c.Milestones,
+		MilestoneEvery:    c.MilestoneEvery,
+		MilestonePrefix:   c.MilestonePrefix,
+		MilestoneTrigger:  c.MilestoneTrigger,
+		IncludeCategories: c.IncludeCategories,
+		CustomDates:       c.CustomDates,
+		Memorials:         c.Memorials,
+		ZodiacSummary:     c.ZodiacSummary,
+		NameDays:          c.NameDays,
+		PreferNickname:    c.PreferNickname,
+		NameFormat:        c.NameFormat,
+		Offsets:           offsets,
+		Limits: engine.Limits{
+			MaxDownloadBytes: c.Limits.MaxBytes,
+			MaxContacts:      c.Limits.MaxContacts,
+			MaxCardBytes:     c.Limits.MaxCardBytes,
+		},
+	}
+}
+
+// newContact converts a contact of the engine.
+func newContact(e engine.BirthdayEntry) Contact {
+	return Contact{
+		UID:            e.UID,
+		Name:           e.Name,
+		FullName:       e.FullName,
+		Nickname:       e.Nickname,
+		Note:           e.Note,
+		DateOfBirth:    e.DateOfBirth,
+		YearKnown:      e.YearKnown,
+		NextOccurrence: e.NextOccurrence,
+		AgeNext:        e.AgeNext,
+		Categories:     e.Categories,
+		Milestone:      e.Milestone,
+		Deceased:       e.Deceased,
+	}
+}
+
+// Convert reads vCards from r and writes their birthday calendar to w, event by event.
+// It returns the contacts with a birthday, sorted as read. On error, w may hold a partial calendar.
+func Convert(ctx context.Context, r io.Reader, w io.Writer, cfg Config) ([]Contact, error) {
+	var clock engine.Clock = engine.RealClock{}
+	if !cfg.Now.IsZero() {
+		clock = fixedClock(cfg.Now)
+	}
+	g := &engine.Generator{Clock: clock}
+	entries, _, err := g.Generate(ctx, r, cfg.syncConfig(), w)
+	contacts := make([]Contact, 0, len(entries))
+	for _, e := range entries {
+		contacts = append(contacts, newContact(e))
+	}
+	return contacts, err
+}
+
+// ParseDate parses a vCard date ("1990-06-11", "19900611", "--06-11"...),
+// reporting whether the year is known.
+func ParseDate(value string) (date time.Time, yearKnown bool, err error) {
+	return engine.ParseDate(value)
+}
+
+// ParseOffset reads an Offset written as "6m" (months after every birthday) or "100d" (days after the birth).
+func ParseOffset(s string) (Offset, error) {
+	o, err := engine.ParseOffset(s)
+	return Offset{Months: o.Months, Days: o.Days}, err
+}
+
+// NextOccurrence returns the next birthday on or after the day of now, and the age turned then
+// (meaningless if the year is unknown). February 29 falls on March 1 outside leap years.
+func NextOccurrence(now, birth time.Time, yearKnown bool) (next time.Time, age int) {
+	return engine.CalculateNextOccurrence(now, birth, yearKnown)
+}
+
+// Validate checks an iCalendar stream against the RFC 5545 rules the generated calendars follow.
+func Validate(r io.Reader) ([]Issue, error) {
+	found, err := engine.ValidateICS(r)
+	issues := make([]Issue, 0, len(found))
+	for _, i := range found {
+		issues = append(issues, Issue{Line: i.Line, Reason: i.Reason})
+	}
+	return issues, err
+}
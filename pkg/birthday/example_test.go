@@ -0,0 +1,68 @@
+package birthday_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tartampluch/go-birthday/pkg/birthday"
+)
+
+func ExampleConvert() {
+	vcf := "BEGIN:VCARD\nVERSION:3.0\nFN:Ada Lovelace\nBDAY:1815-12-10\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:No Year\nBDAY:--02-29\nEND:VCARD\n"
+
+	var ics bytes.Buffer
+	contacts, err := birthday.Convert(context.Background(), strings.NewReader(vcf), &ics, birthday.Config{
+		ReminderTriggers: []string{"-P1D"},
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	for _, c := range contacts {
+		fmt.Println(c.Name, c.DateOfBirth.Format("01-02"), c.YearKnown)
+	}
+	issues, _ := birthday.Validate(&ics)
+	fmt.Println(len(issues), "issues")
+	// Output:
+	// Ada Lovelace 12-10 true
+	// No Year 02-29 false
+	// 0 issues
+}
+
+func ExampleParseDate() {
+	date, yearKnown, _ := birthday.ParseDate("--06-11")
+	fmt.Println(date.Month(), date.Day(), yearKnown)
+	// Output: June 11 false
+}
+
+func ExampleNextOccurrence() {
+	now := time.Date(2025, 2, 27, 12, 0, 0, 0, time.UTC)
+	birth := time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC)
+	next, age := birthday.NextOccurrence(now, birth, true)
+	fmt.Println(next.Format("2006-01-02"), age)
+	// Output: 2025-03-01 25
+}
+
+func ExampleConfig() {
+	vcf := "BEGIN:VCARD\nVERSION:3.0\nFN:Jane Doe\nN:Doe;Jane;;;\nBDAY:1990-06-11\nEND:VCARD\n"
+	half, _ := birthday.ParseOffset("6m")
+
+	var ics bytes.Buffer
+	contacts, err := birthday.Convert(context.Background(), strings.NewReader(vcf), &ics, birthday.Config{
+		Now:        time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		NameFormat: birthday.NameFamilyGiven,
+		Offsets:    []birthday.Offset{half},
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	c := contacts[0]
+	fmt.Println(c.Name, "/", c.FullName, c.NextOccurrence.Format("2006-01-02"), c.AgeNext)
+	// Output: Doe, Jane / Jane Doe 2025-06-11 35
+}
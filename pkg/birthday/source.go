@@ -0,0 +1,67 @@
+package birthday
+
+import (
+	"context"
+
+	"github.com/tartampluch/go-birthday/internal/engine"
+)
+
+// SourceContact is a contact listed by a Source.
+type SourceContact struct {
+	UID        string   `json:"uid,omitempty"`
+	Name       string   `json:"name"`
+	Birthday   string   `json:"birthday"` // vCard date: "1990-06-11", "19900611" or "--06-11" without the year
+	Categories []string `json:"categories,omitempty"`
+}
+
+// Source is a third-party contact source (e.g., a company HR system), made available by RegisterSource.
+type Source interface {
+	List(ctx context.Context) ([]SourceContact, error)
+}
+
+// ExecSource is a Source running a program that prints its contacts as a JSON array of SourceContact.
+type ExecSource struct {
+	Command string   // Path of the program
+	Args    []string // Arguments of the program
+}
+
+// List runs the program and decodes its output.
+func (s ExecSource) List(ctx context.Context) ([]SourceContact, error) {
+	found, err := engine.ExecSource{Command: s.Command, Args: s.Args}.List(ctx, engine.SyncConfig{})
+	if err != nil {
+		return nil, err
+	}
+	contacts := make([]SourceContact, 0, len(found))
+	for _, c := range found {
+		contacts = append(contacts, SourceContact(c))
+	}
+	return contacts, nil
+}
+
+// engineSource adapts a Source to the engine.
+type engineSource struct {
+	s Source
+}
+
+func (e engineSource) List(ctx context.Context, _ engine.SyncConfig) ([]engine.SourceContact, error) {
+	found, err := e.s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contacts := make([]engine.SourceContact, 0, len(found))
+	for _, c := range found {
+		contacts = append(contacts, engine.SourceContact(c))
+	}
+	return contacts, nil
+}
+
+// RegisterSource makes a Source available as a contact source of the application built with it,
+// under mode (its settings list the mode), typically from an init function. It panics on an
+// empty, built-in or duplicate mode, or a nil Source.
+func RegisterSource(mode string, s Source) {
+	if s == nil {
+		engine.RegisterSource(mode, nil)
+		return
+	}
+	engine.RegisterSource(mode, engineSource{s: s})
+}
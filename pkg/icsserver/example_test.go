@@ -0,0 +1,31 @@
+package icsserver_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tartampluch/go-birthday/pkg/birthday"
+	"github.com/tartampluch/go-birthday/pkg/icsserver"
+)
+
+func Example() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Generate into a Buffer, which moves to a temporary file past a few megabytes.
+	buf := icsserver.NewBuffer()
+	vcf := "BEGIN:VCARD\nVERSION:3.0\nFN:Ada Lovelace\nBDAY:1815-12-10\nEND:VCARD\n"
+	if _, err := birthday.Convert(ctx, strings.NewReader(vcf), buf, birthday.Config{}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	srv := icsserver.New("18080")
+	srv.UpdateBuffer(buf)
+	// Serves http://127.0.0.1:18080/ until ctx is cancelled.
+	if err := srv.Start(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
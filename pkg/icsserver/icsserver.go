@@ -0,0 +1,107 @@
+// Package icsserver serves a birthday calendar over HTTP on the loopback interface,
+// with ETag and Last-Modified caching, contact pictures, a Home Assistant sensor and to-dos.
+//
+// It is the importable API of the Go Birthday server. Its types are its own, wrapping those of
+// the application, so that the server can change without breaking the programs using them.
+package icsserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/tartampluch/go-birthday/internal/config"
+	"github.com/tartampluch/go-birthday/internal/server"
+)
+
+// Routes of the server, under http://127.0.0.1:{port}.
+const (
+	RouteCalendar      = config.RouteRoot // Any other path serves the calendar too
+	RouteCalendarFile  = config.RouteCalendarFile
+	RoutePhotos        = config.RoutePhotos
+	RouteHomeAssistant = config.RouteHomeAssistant
+	RouteTodos         = config.RouteTodos
+)
+
+// Server serves the calendar given to Update (or UpdateBuffer) until the context of Start ends.
+type Server struct {
+	s *server.CalendarServer
+}
+
+// Buffer holds a large calendar in a temporary file rather than in memory.
+// It is written once, by a single goroutine, before it is handed over to Server.UpdateBuffer.
+type Buffer struct {
+	b *server.Buffer
+}
+
+// Birthday is a contact listed by the Home Assistant and to-do endpoints (UpdateBirthdays).
+type Birthday struct {
+	UID       string    // Contact UID, for the to-do UIDs
+	Name      string    // Name of the contact
+	Date      time.Time // Date of birth; the year is only meaningful if YearKnown
+	YearKnown bool
+}
+
+// New creates a server listening on 127.0.0.1:port once started.
+func New(port string) *Server {
+	return &Server{s: server.NewCalendarServer(port)}
+}
+
+// Port returns the port the server listens on, or will once started.
+func (s *Server) Port() string {
+	return s.s.Port()
+}
+
+// Start listens and serves until ctx ends. It returns an error if the port is unavailable.
+func (s *Server) Start(ctx context.Context) error {
+	return s.s.Start(ctx)
+}
+
+// Update replaces the served calendar.
+func (s *Server) Update(ics []byte) {
+	s.s.Update(ics)
+}
+
+// UpdateBuffer replaces the served calendar with the one written into b.
+// The server takes ownership of b and closes it once it is replaced and no longer read.
+func (s *Server) UpdateBuffer(b *Buffer) {
+	s.s.UpdateBuffer(b.b)
+}
+
+// UpdatePhotos replaces the contact pictures served under RoutePhotos, keyed by contact UID.
+func (s *Server) UpdatePhotos(photos map[string][]byte) {
+	s.s.UpdatePhotos(photos)
+}
+
+// UpdateBirthdays replaces the contacts of the Home Assistant and to-do endpoints.
+func (s *Server) UpdateBirthdays(birthdays []Birthday) {
+	list := make([]server.Birthday, 0, len(birthdays))
+	for _, b := range birthdays {
+		list = append(list, server.Birthday(b))
+	}
+	s.s.UpdateBirthdays(list)
+}
+
+// UpdateTodos serves the birthdays of the coming days as to-dos under RouteTodos; 0 disables it.
+func (s *Server) UpdateTodos(days int) {
+	s.s.UpdateTodos(days, nil)
+}
+
+// NewBuffer creates an empty Buffer.
+func NewBuffer() *Buffer {
+	return &Buffer{b: server.NewBuffer()}
+}
+
+// Write appends p to the calendar.
+func (b *Buffer) Write(p []byte) (int, error) {
+	return b.b.Write(p)
+}
+
+// Size returns the number of bytes written.
+func (b *Buffer) Size() int64 {
+	return b.b.Size()
+}
+
+// Close releases a Buffer that is not handed over to a Server, removing its temporary file if any.
+func (b *Buffer) Close() error {
+	return b.b.Close()
+}